@@ -28,6 +28,7 @@ import (
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
+	scdoCommon "github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/crypto"
 )
 
@@ -713,3 +714,140 @@ func TestABI_MethodById(t *testing.T) {
 	}
 
 }
+
+func TestTupleType(t *testing.T) {
+	const abiJSON = `[{"type":"function","name":"register","constant":false,"inputs":[{"name":"info","type":"tuple","components":[{"name":"name","type":"string"},{"name":"owner","type":"address"},{"name":"ids","type":"uint256[]"}]}],"outputs":[{"name":"info","type":"tuple","components":[{"name":"name","type":"string"},{"name":"owner","type":"address"},{"name":"ids","type":"uint256[]"}]}]},
+		{"type":"function","name":"batch","constant":false,"inputs":[{"name":"points","type":"tuple[]","components":[{"name":"x","type":"uint64"},{"name":"y","type":"uint64"}]},{"name":"tag","type":"bytes32"}]}]`
+	abi, err := JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	register := abi.Methods["register"]
+	if sig := register.Sig(); sig != "register((string,address,uint256[]))" {
+		t.Fatalf("invalid signature %v", sig)
+	}
+	if sig := abi.Methods["batch"].Sig(); sig != "batch((uint64,uint64)[],bytes32)" {
+		t.Fatalf("invalid signature %v", sig)
+	}
+
+	type Info struct {
+		Name  string
+		Owner scdoCommon.Address
+		Ids   []*big.Int
+	}
+	info := Info{
+		Name:  "scdo",
+		Owner: scdoCommon.BytesToAddress([]byte{1, 2, 3}),
+		Ids:   []*big.Int{big.NewInt(1), big.NewInt(2)},
+	}
+
+	packed, err := abi.Pack("register", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// offset of tuple, then tuple head: offset of name, owner, offset of ids,
+	// then name (length + data), ids (length + 2 elements).
+	if len(packed) != 4+32*9 {
+		t.Fatalf("invalid packed length %d", len(packed))
+	}
+
+	var out Info
+	if err := abi.Unpack(&out, "register", packed[4:]); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info, out) {
+		t.Fatalf("unpacked tuple mismatch, want %+v, got %+v", info, out)
+	}
+
+	type Point struct {
+		X uint64
+		Y uint64
+	}
+	points := []Point{{1, 2}, {3, 4}}
+	var tag [32]byte
+	tag[0] = 9
+	packed, err = abi.Pack("batch", points, tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := abi.Methods["batch"].Inputs.UnpackValues(packed[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || reflect.ValueOf(values[0]).Len() != 2 || values[1].([32]byte) != tag {
+		t.Fatalf("invalid unpacked values %v", values)
+	}
+	if y := reflect.ValueOf(values[0]).Index(1).FieldByName("Y").Uint(); y != 4 {
+		t.Fatalf("invalid unpacked tuple field, want 4, got %d", y)
+	}
+}
+
+func TestUnpackEventTopics(t *testing.T) {
+	const abiJSON = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":true,"name":"memo","type":"string"}],"name":"Sent","type":"event"}]`
+	abi, err := JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := abi.Events["Sent"]
+	from := scdoCommon.BytesToAddress([]byte{1, 2, 3})
+	memoHash := scdoCommon.StringToHash("memo")
+	topics := []scdoCommon.Hash{event.Id(), scdoCommon.BytesToHash(from.Bytes()), memoHash}
+	data := U256(big.NewInt(100))
+
+	values, err := event.UnpackLog(topics, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{from, big.NewInt(100), memoHash}
+	if !reflect.DeepEqual(expected, values) {
+		t.Fatalf("unpacked event mismatch, want %v, got %v", expected, values)
+	}
+
+	if _, err = event.UnpackLog(topics[1:], data); err == nil {
+		t.Fatal("expected error for mismatched event id")
+	}
+
+	if _, err = event.UnpackLog(topics[:2], data); err == nil {
+		t.Fatal("expected error for missing topics")
+	}
+}
+
+func TestPackEventLog(t *testing.T) {
+	const abiJSON = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":true,"name":"memo","type":"string"}],"name":"Sent","type":"event"}]`
+	abi, err := JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := abi.Events["Sent"]
+	from := scdoCommon.BytesToAddress([]byte{1, 2, 3})
+	topics, data, err := event.PackLog(from, big.NewInt(100), "memo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memoHash := crypto.Keccak256Hash([]byte("memo"))
+	expectedTopics := []scdoCommon.Hash{event.Id(), scdoCommon.BytesToHash(from.Bytes()), memoHash}
+	if !reflect.DeepEqual(expectedTopics, topics) {
+		t.Fatalf("packed topics mismatch, want %v, got %v", expectedTopics, topics)
+	}
+
+	values, err := event.UnpackLog(topics, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{from, big.NewInt(100), memoHash}
+	if !reflect.DeepEqual(expected, values) {
+		t.Fatalf("unpacked event mismatch, want %v, got %v", expected, values)
+	}
+
+	if _, _, err = event.PackLog(from, big.NewInt(100)); err == nil {
+		t.Fatal("expected error for missing arguments")
+	}
+}
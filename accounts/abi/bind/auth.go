@@ -0,0 +1,57 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package bind
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+var (
+	// DefaultGasPrice is the gas price in Wen used when TransactOpts.GasPrice is not set
+	DefaultGasPrice = big.NewInt(10)
+
+	// DefaultGasLimit is the gas limit used when TransactOpts.GasLimit is not set
+	DefaultGasLimit uint64 = 200000
+)
+
+// CallOpts is the collection of options to fine tune a contract call request
+type CallOpts struct {
+	Height *big.Int // block height to call against, nil means the current block
+}
+
+// TransactOpts is the collection of authorization data required to create a valid transaction
+type TransactOpts struct {
+	From       common.Address    // sender of the transaction
+	PrivateKey *ecdsa.PrivateKey // key used to sign the transaction
+
+	Nonce    *big.Int // nonce to use for the transaction, nil means the pending nonce of sender
+	Value    *big.Int // amount in Wen transferred along with the transaction, nil means zero
+	GasPrice *big.Int // gas price in Wen, nil means DefaultGasPrice
+	GasLimit uint64   // gas limit, zero means DefaultGasLimit
+}
+
+// NewKeyedTransactor creates a transactor which signs transactions with the given private key
+func NewKeyedTransactor(key *ecdsa.PrivateKey) *TransactOpts {
+	return &TransactOpts{
+		From:       *crypto.GetAddress(&key.PublicKey),
+		PrivateKey: key,
+	}
+}
+
+// FilterOpts is the collection of options to fine tune filtering of contract events
+type FilterOpts struct {
+	Start uint64  // start block height of the filter
+	End   *uint64 // end block height of the filter, nil means the current block
+}
+
+// WatchOpts is the collection of options to fine tune watching of contract events
+type WatchOpts struct {
+	Start *uint64 // start block height of the watch, nil means the next block
+}
@@ -0,0 +1,462 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// watchInterval is the interval to poll new blocks when watching contract events
+var watchInterval = 5 * time.Second
+
+var (
+	errNoKey       = errors.New("no private key to sign the transaction")
+	errNoCode      = errors.New("no contract code at the given address")
+	errEmptyResult = errors.New("empty result of contract call")
+)
+
+// ContractBackend is the RPC backend used by contract bindings, which is satisfied by *rpc.Client
+type ContractBackend interface {
+	Call(result interface{}, method string, args ...interface{}) error
+}
+
+// pendingNonces tracks the nonces of transactions sent by the bindings but not packed yet,
+// so that several transactions from the same account could be sent without waiting for blocks.
+var pendingNonces = struct {
+	nonces map[common.Address]uint64
+	lock   sync.Mutex
+}{nonces: make(map[common.Address]uint64)}
+
+// BoundContract is the base wrapper object that reflects a contract on the Scdo network.
+// It contains a collection of methods that are used by the higher level contract bindings.
+type BoundContract struct {
+	address common.Address
+	abi     abi.ABI
+	abiJSON string
+	backend ContractBackend
+}
+
+// NewBoundContract creates a low level contract interface through which calls and
+// transactions may be made through.
+func NewBoundContract(address common.Address, abiJSON string, backend ContractBackend) (*BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	return &BoundContract{
+		address: address,
+		abi:     parsed,
+		abiJSON: abiJSON,
+		backend: backend,
+	}, nil
+}
+
+// DeployContract deploys a contract onto the Scdo network and binds the deployment
+// address with a Go wrapper.
+func DeployContract(opts *TransactOpts, abiJSON string, bytecode []byte, backend ContractBackend, params ...interface{}) (common.Address, *types.Transaction, *BoundContract, error) {
+	c, err := NewBoundContract(common.EmptyAddress, abiJSON, backend)
+	if err != nil {
+		return common.EmptyAddress, nil, nil, err
+	}
+
+	input, err := c.abi.Pack("", params...)
+	if err != nil {
+		return common.EmptyAddress, nil, nil, err
+	}
+
+	tx, err := c.transact(opts, common.EmptyAddress, append(bytecode, input...))
+	if err != nil {
+		return common.EmptyAddress, nil, nil, err
+	}
+
+	c.address = crypto.CreateAddress(tx.Data.From, tx.Data.AccountNonce)
+	return c.address, tx, c, nil
+}
+
+// Address returns the address of the bound contract
+func (c *BoundContract) Address() common.Address {
+	return c.address
+}
+
+// Call invokes the (constant) contract method with params as input values and sets
+// the output to result. The result type might be a single field for simple returns,
+// a slice of interfaces for anonymous returns and a struct for named returns.
+func (c *BoundContract) Call(opts *CallOpts, result interface{}, method string, params ...interface{}) error {
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return err
+	}
+
+	height := int64(-1)
+	if opts != nil && opts.Height != nil {
+		height = opts.Height.Int64()
+	}
+
+	var receipt map[string]interface{}
+	if err = c.backend.Call(&receipt, "scdo_call", c.address.Hex(), hexutil.BytesToHex(input), height); err != nil {
+		return err
+	}
+
+	output, _ := receipt["result"].(string)
+	if failed, _ := receipt["failed"].(bool); failed {
+		return fmt.Errorf("contract call failed: %s", output)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if output == "" || output == "0x" {
+		return errNoCode
+	}
+
+	data, err := hexutil.HexToBytes(output)
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return errEmptyResult
+	}
+
+	return c.abi.Unpack(result, method, data)
+}
+
+// Transact invokes the (paid) contract method with params as input values
+func (c *BoundContract) Transact(opts *TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.transact(opts, c.address, input)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (c *BoundContract) Transfer(opts *TransactOpts) (*types.Transaction, error) {
+	return c.transact(opts, c.address, nil)
+}
+
+// transact executes an actual transaction invocation, first deriving any missing
+// authorization fields, and then scheduling the transaction for execution.
+func (c *BoundContract) transact(opts *TransactOpts, to common.Address, input []byte) (*types.Transaction, error) {
+	if opts.PrivateKey == nil {
+		return nil, errNoKey
+	}
+
+	// the transaction could only be added into the node in the same shard of sender,
+	// and the nonce is only known by the nodes of that shard.
+	var info struct{ Shard uint }
+	if err := c.backend.Call(&info, "scdo_getInfo"); err != nil {
+		return nil, err
+	}
+
+	if info.Shard != opts.From.Shard() {
+		return nil, fmt.Errorf("sender shard %d does not match the node shard %d", opts.From.Shard(), info.Shard)
+	}
+
+	value := opts.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	price := opts.GasPrice
+	if price == nil {
+		price = DefaultGasPrice
+	}
+
+	gasLimit := opts.GasLimit
+	if gasLimit == 0 {
+		gasLimit = DefaultGasLimit
+	}
+
+	var nonce uint64
+	if opts.Nonce != nil {
+		nonce = opts.Nonce.Uint64()
+	} else {
+		var err error
+		if nonce, err = c.pendingNonce(opts.From); err != nil {
+			return nil, err
+		}
+	}
+
+	var tx *types.Transaction
+	var err error
+	if to.IsEmpty() {
+		tx, err = types.NewContractTransaction(opts.From, value, price, gasLimit, nonce, input)
+	} else {
+		tx, err = types.NewMessageTransaction(opts.From, to, value, price, gasLimit, nonce, input)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Sign(opts.PrivateKey)
+
+	var ok bool
+	if err = c.backend.Call(&ok, "scdo_addTx", *tx); err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("failed to add transaction %v", tx.Hash.Hex())
+	}
+
+	pendingNonces.lock.Lock()
+	if nonce+1 > pendingNonces.nonces[opts.From] {
+		pendingNonces.nonces[opts.From] = nonce + 1
+	}
+	pendingNonces.lock.Unlock()
+
+	return tx, nil
+}
+
+// pendingNonce returns the nonce for the next transaction of the account, which is the
+// larger one of the account nonce in state and the nonce tracked locally.
+func (c *BoundContract) pendingNonce(account common.Address) (uint64, error) {
+	var nonce uint64
+	if err := c.backend.Call(&nonce, "scdo_getAccountNonce", account, "", int64(-1)); err != nil {
+		return 0, err
+	}
+
+	pendingNonces.lock.Lock()
+	defer pendingNonces.lock.Unlock()
+
+	if pending := pendingNonces.nonces[account]; pending > nonce {
+		return pending, nil
+	}
+
+	return nonce, nil
+}
+
+// rpcLog is the json format of log returned by scdo_getLogs
+type rpcLog struct {
+	Address     common.Address `json:"address"`
+	Topics      []common.Hash  `json:"topics"`
+	Data        string         `json:"data"`
+	BlockNumber uint64         `json:"blockNumber"`
+	TxIndex     uint           `json:"transactionIndex"`
+}
+
+// logsAt returns the logs of the specified event emitted by the contract in the block of the given height
+func (c *BoundContract) logsAt(height uint64, name string) ([]types.Log, error) {
+	var result []rpcLog
+	if err := c.backend.Call(&result, "scdo_getLogs", int64(height), c.address, c.abiJSON, name); err != nil {
+		return nil, err
+	}
+
+	logs := make([]types.Log, 0, len(result))
+	for _, l := range result {
+		data, err := hexutil.HexToBytes(l.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		logs = append(logs, types.Log{
+			Address:     l.Address,
+			Topics:      l.Topics,
+			Data:        data,
+			BlockNumber: l.BlockNumber,
+			TxIndex:     l.TxIndex,
+		})
+	}
+
+	return logs, nil
+}
+
+// currentHeight returns the height of the current block of the backend
+func (c *BoundContract) currentHeight() (uint64, error) {
+	var height int64
+	if err := c.backend.Call(&height, "scdo_getHeight"); err != nil {
+		return 0, err
+	}
+
+	return uint64(height), nil
+}
+
+// FilterLogs returns the logs of the specified event emitted by the contract in the range of blocks
+func (c *BoundContract) FilterLogs(opts *FilterOpts, name string) ([]types.Log, error) {
+	if opts == nil {
+		opts = new(FilterOpts)
+	}
+
+	if _, ok := c.abi.Events[name]; !ok {
+		return nil, fmt.Errorf("event %v not found in ABI", name)
+	}
+
+	end := opts.End
+	if end == nil {
+		height, err := c.currentHeight()
+		if err != nil {
+			return nil, err
+		}
+		end = &height
+	}
+
+	var logs []types.Log
+	for height := opts.Start; height <= *end; height++ {
+		result, err := c.logsAt(height, name)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, result...)
+	}
+
+	return logs, nil
+}
+
+// WatchLogs polls the new blocks and delivers the logs of the specified event emitted
+// by the contract to the returned channel, until the subscription is unsubscribed.
+func (c *BoundContract) WatchLogs(opts *WatchOpts, name string) (chan types.Log, *Subscription, error) {
+	if _, ok := c.abi.Events[name]; !ok {
+		return nil, nil, fmt.Errorf("event %v not found in ABI", name)
+	}
+
+	var next uint64
+	if opts != nil && opts.Start != nil {
+		next = *opts.Start
+	} else {
+		height, err := c.currentHeight()
+		if err != nil {
+			return nil, nil, err
+		}
+		next = height + 1
+	}
+
+	logs := make(chan types.Log, 128)
+	sub := newSubscription()
+	go func() {
+		defer close(logs)
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			height, err := c.currentHeight()
+			if err != nil {
+				sub.fail(err)
+				return
+			}
+
+			for ; next <= height; next++ {
+				result, err := c.logsAt(next, name)
+				if err != nil {
+					sub.fail(err)
+					return
+				}
+
+				for _, log := range result {
+					select {
+					case logs <- log:
+					case <-sub.quit:
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-sub.quit:
+				return
+			}
+		}
+	}()
+
+	return logs, sub, nil
+}
+
+// UnpackLog unpacks a retrieved log into the provided output structure. The fields of
+// output are assigned with the event arguments in order, and the log itself is assigned
+// to the field named Raw if any.
+func (c *BoundContract) UnpackLog(out interface{}, name string, log types.Log) error {
+	event, ok := c.abi.Events[name]
+	if !ok {
+		return fmt.Errorf("event %v not found in ABI", name)
+	}
+
+	values, err := event.UnpackLog(log.Topics, log.Data)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(out)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unpack log into %T", out)
+	}
+
+	value = value.Elem()
+	if value.NumField() < len(values) {
+		return fmt.Errorf("insufficient fields to unpack event %v, want %d, got %d", name, len(values), value.NumField())
+	}
+
+	for i, v := range values {
+		field, src := value.Field(i), reflect.ValueOf(v)
+		switch {
+		case src.Type().AssignableTo(field.Type()):
+			field.Set(src)
+		case src.Type().ConvertibleTo(field.Type()):
+			field.Set(src.Convert(field.Type()))
+		default:
+			return fmt.Errorf("cannot assign %v to field %v of type %v", src.Type(), value.Type().Field(i).Name, field.Type())
+		}
+	}
+
+	if raw := value.FieldByName("Raw"); raw.IsValid() && raw.Type() == reflect.TypeOf(log) {
+		raw.Set(reflect.ValueOf(log))
+	}
+
+	return nil
+}
+
+// Subscription represents an event subscription of contract logs
+type Subscription struct {
+	quit chan struct{}
+	err  chan error
+	once sync.Once
+}
+
+func newSubscription() *Subscription {
+	return &Subscription{
+		quit: make(chan struct{}),
+		err:  make(chan error, 1),
+	}
+}
+
+// Unsubscribe stops delivering logs
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		close(s.quit)
+	})
+}
+
+// Done returns the channel which is closed when the subscription is unsubscribed
+func (s *Subscription) Done() <-chan struct{} {
+	return s.quit
+}
+
+// Err returns the channel to receive the error that terminates the subscription
+func (s *Subscription) Err() <-chan error {
+	return s.err
+}
+
+func (s *Subscription) fail(err error) {
+	s.err <- err
+}
@@ -0,0 +1,159 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package bind
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testABI = `[
+{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":true,"inputs":[],"name":"info","outputs":[{"name":"name","type":"string"},{"name":"decimals","type":"uint8"}],"type":"function"},
+{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+// mockBackend replies the RPC requests with the registered handlers, and the
+// results are marshalled into json the same as the real rpc client.
+type mockBackend struct {
+	handlers map[string]func(args ...interface{}) interface{}
+	calls    map[string][]interface{}
+}
+
+func newMockBackend() *mockBackend {
+	return &mockBackend{
+		handlers: make(map[string]func(args ...interface{}) interface{}),
+		calls:    make(map[string][]interface{}),
+	}
+}
+
+func (b *mockBackend) Call(result interface{}, method string, args ...interface{}) error {
+	b.calls[method] = args
+
+	encoded, err := json.Marshal(b.handlers[method](args...))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, result)
+}
+
+func Test_BoundContract_Call(t *testing.T) {
+	backend := newMockBackend()
+	contract, err := NewBoundContract(*crypto.MustGenerateRandomAddress(), testABI, backend)
+	assert.Nil(t, err)
+
+	parsed, _ := abi.JSON(strings.NewReader(testABI))
+	backend.handlers["scdo_call"] = func(args ...interface{}) interface{} {
+		output, _ := parsed.Methods["info"].Outputs.Pack("token", uint8(18))
+		return map[string]interface{}{"result": hexutil.BytesToHex(output), "failed": false}
+	}
+
+	var name string
+	var decimals uint8
+	out := []interface{}{&name, &decimals}
+	assert.Nil(t, contract.Call(nil, &out, "info"))
+	assert.Equal(t, "token", name)
+	assert.Equal(t, uint8(18), decimals)
+	assert.Equal(t, int64(-1), backend.calls["scdo_call"][2])
+
+	backend.handlers["scdo_call"] = func(args ...interface{}) interface{} {
+		return map[string]interface{}{"result": "execution reverted", "failed": true}
+	}
+
+	var balance *big.Int
+	err = contract.Call(&CallOpts{Height: big.NewInt(10)}, &balance, "balanceOf", common.EmptyAddress)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(10), backend.calls["scdo_call"][2])
+}
+
+func Test_BoundContract_Transact(t *testing.T) {
+	from, key := crypto.MustGenerateShardKeyPair(1)
+	backend := newMockBackend()
+	backend.handlers["scdo_getInfo"] = func(args ...interface{}) interface{} {
+		return map[string]interface{}{"Shard": 1}
+	}
+	backend.handlers["scdo_getAccountNonce"] = func(args ...interface{}) interface{} {
+		return 5
+	}
+	backend.handlers["scdo_addTx"] = func(args ...interface{}) interface{} {
+		return true
+	}
+
+	address, tx, contract, err := DeployContract(NewKeyedTransactor(key), testABI, []byte{0x60, 0x80}, backend)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), tx.Data.AccountNonce)
+	assert.Equal(t, crypto.CreateAddress(*from, 5), address)
+	assert.Equal(t, address, contract.Address())
+	assert.Equal(t, from.Shard(), address.Shard())
+
+	// the nonce of pending transaction is tracked locally
+	tx, err = contract.Transact(NewKeyedTransactor(key), "transfer", common.EmptyAddress, big.NewInt(1))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(6), tx.Data.AccountNonce)
+	assert.Equal(t, address, tx.Data.To)
+
+	// the transaction must be sent to the node in the same shard
+	backend.handlers["scdo_getInfo"] = func(args ...interface{}) interface{} {
+		return map[string]interface{}{"Shard": 2}
+	}
+	_, err = contract.Transact(NewKeyedTransactor(key), "transfer", common.EmptyAddress, big.NewInt(1))
+	assert.NotNil(t, err)
+}
+
+func Test_BoundContract_FilterLogs(t *testing.T) {
+	backend := newMockBackend()
+	contract, err := NewBoundContract(*crypto.MustGenerateRandomAddress(), testABI, backend)
+	assert.Nil(t, err)
+
+	from := *crypto.MustGenerateRandomAddress()
+	event := contract.abi.Events["Transfer"]
+	data, _ := event.Inputs.NonIndexed().Pack(big.NewInt(100))
+	log := &types.Log{
+		Address:     contract.Address(),
+		Topics:      []common.Hash{event.Id(), common.BytesToHash(from.Bytes())},
+		Data:        data,
+		BlockNumber: 2,
+	}
+
+	backend.handlers["scdo_getHeight"] = func(args ...interface{}) interface{} {
+		return 3
+	}
+	backend.handlers["scdo_getLogs"] = func(args ...interface{}) interface{} {
+		if args[0].(int64) == 2 {
+			return []*types.Log{log}
+		}
+		return []*types.Log{}
+	}
+
+	logs, err := contract.FilterLogs(&FilterOpts{Start: 1}, "Transfer")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, *log, logs[0])
+
+	var transfer struct {
+		From  common.Address
+		Value *big.Int
+		Raw   types.Log
+	}
+	assert.Nil(t, contract.UnpackLog(&transfer, "Transfer", logs[0]))
+	assert.Equal(t, from, transfer.From)
+	assert.Equal(t, big.NewInt(100), transfer.Value)
+	assert.Equal(t, uint64(2), transfer.Raw.BlockNumber)
+
+	_, err = contract.FilterLogs(nil, "Approval")
+	assert.NotNil(t, err)
+}
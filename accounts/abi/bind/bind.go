@@ -16,13 +16,187 @@
 package bind
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"go/format"
+	"go/token"
 	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/scdoproject/go-stem/accounts/abi"
 )
 
+// Bind generates a Go wrapper around a contract ABI. This wrapper isn't meant
+// to be used as is in client code, but rather as an intermediate struct which
+// enforces compile time type safety and naming convention opposed to having to
+// manually maintain hard coded strings that break on runtime.
+func Bind(types []string, abis []string, bytecodes []string, pkg string) (string, error) {
+	if len(types) != len(abis) || len(types) != len(bytecodes) {
+		return "", fmt.Errorf("mismatched number of types, ABIs and bytecodes: %d, %d, %d", len(types), len(abis), len(bytecodes))
+	}
+
+	contracts := make(map[string]*tmplContract)
+	for i := 0; i < len(types); i++ {
+		// parse the actual ABI to generate the binding for
+		evmABI, err := abi.JSON(strings.NewReader(abis[i]))
+		if err != nil {
+			return "", err
+		}
+
+		// strip any whitespace from the JSON ABI
+		var compacted bytes.Buffer
+		if err = json.Compact(&compacted, []byte(abis[i])); err != nil {
+			return "", err
+		}
+
+		typeName := abi.ToCamelCase(types[i])
+		if _, exist := contracts[typeName]; exist {
+			return "", fmt.Errorf("duplicated contract type %s", typeName)
+		}
+
+		contract := &tmplContract{
+			Type:        typeName,
+			InputABI:    strconv.Quote(compacted.String()),
+			InputBin:    strings.TrimPrefix(strings.TrimSpace(bytecodes[i]), "0x"),
+			Constructor: normalizeMethod(evmABI.Constructor),
+			Calls:       make(map[string]*tmplMethod),
+			Transacts:   make(map[string]*tmplMethod),
+			Events:      make(map[string]*tmplEvent),
+		}
+
+		for _, original := range evmABI.Methods {
+			normalized := normalizeMethod(original)
+			normalized.Name = abi.ToCamelCase(original.Name)
+
+			if original.Const {
+				contract.Calls[original.Name] = &tmplMethod{
+					Original:   original,
+					Normalized: normalized,
+					Structured: len(normalized.Outputs) > 1,
+				}
+			} else {
+				contract.Transacts[original.Name] = &tmplMethod{Original: original, Normalized: normalized}
+			}
+		}
+
+		for _, original := range evmABI.Events {
+			normalized := original
+			normalized.Name = abi.ToCamelCase(original.Name)
+			normalized.Inputs = make(abi.Arguments, len(original.Inputs))
+			copy(normalized.Inputs, original.Inputs)
+			for j, input := range normalized.Inputs {
+				normalized.Inputs[j].Name = fieldName(input.Name, j)
+			}
+
+			contract.Events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
+		}
+
+		contracts[typeName] = contract
+	}
+
+	data := &tmplData{
+		Package:   pkg,
+		Contracts: contracts,
+	}
+
+	funcs := map[string]interface{}{
+		"bindtype":      bindType,
+		"bindtopictype": bindTopicType,
+	}
+
+	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource))
+	buffer := new(bytes.Buffer)
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return "", err
+	}
+
+	code, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("%v\n%s", err, buffer)
+	}
+
+	return string(code), nil
+}
+
+// normalizeMethod names the anonymous arguments and returns of method, so they
+// could be used as parameters and struct fields in Go code.
+func normalizeMethod(original abi.Method) abi.Method {
+	normalized := original
+	normalized.Inputs = make(abi.Arguments, len(original.Inputs))
+	copy(normalized.Inputs, original.Inputs)
+	for i, input := range normalized.Inputs {
+		normalized.Inputs[i].Name = paramName(input.Name, i)
+	}
+
+	normalized.Outputs = make(abi.Arguments, len(original.Outputs))
+	copy(normalized.Outputs, original.Outputs)
+	for i, output := range normalized.Outputs {
+		normalized.Outputs[i].Name = fieldName(output.Name, i)
+	}
+
+	return normalized
+}
+
+// reservedNames are the identifiers declared in the scope of method arguments by the template,
+// including the imported packages, which could not be used as parameter names.
+var reservedNames = map[string]bool{
+	"opts": true, "auth": true, "backend": true,
+	"bytecode": true, "address": true, "tx": true, "contract": true, "err": true,
+	"ret": true, "out": true, "ret0": true,
+	"big": true, "strings": true, "abi": true, "bind": true, "common": true, "hexutil": true, "types": true,
+}
+
+// paramName returns a valid Go parameter name for the method argument
+func paramName(name string, index int) string {
+	if name == "" || reservedNames[name] || token.Lookup(name).IsKeyword() {
+		return fmt.Sprintf("arg%d", index)
+	}
+
+	return name
+}
+
+// fieldName returns an exported Go struct field name for the method return or event argument
+func fieldName(name string, index int) string {
+	if name = abi.ToCamelCase(name); name == "" || name == "Raw" {
+		return fmt.Sprintf("Arg%d", index)
+	}
+
+	return name
+}
+
+// bindType converts an ABI type to a Go one, tuples are converted to anonymous
+// structs whose fields are named after the camel-case tuple components.
+func bindType(kind abi.Type) string {
+	switch kind.T {
+	case abi.TupleTy:
+		fields := make([]string, len(kind.TupleElems))
+		for i, elem := range kind.TupleElems {
+			fields[i] = fmt.Sprintf("%s %s", abi.ToCamelCase(kind.TupleRawNames[i]), bindType(*elem))
+		}
+		return fmt.Sprintf("struct{ %s }", strings.Join(fields, "; "))
+	case abi.SliceTy:
+		return "[]" + bindType(*kind.Elem)
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]%s", kind.Size, bindType(*kind.Elem))
+	default:
+		return bindTypeGo(kind)
+	}
+}
+
+// bindTopicType converts an indexed event argument type to a Go one. Dynamic types,
+// arrays and tuples are stored as hash in log topics.
+func bindTopicType(kind abi.Type) string {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return "common.Hash"
+	default:
+		return bindType(kind)
+	}
+}
+
 // bindTypeGo converts a Solidity type to a Go one. Since there is no clear mapping
 // from all Solidity types to Go ones (e.g. uint17), those that cannot be exactly
 // mapped will use an upscaled type (e.g. *big.Int).
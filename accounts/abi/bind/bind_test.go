@@ -3,7 +3,10 @@ package bind
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_bindUnnestedTypeGo(t *testing.T) {
@@ -44,3 +47,46 @@ func Test_bindUnnestedTypeGo(t *testing.T) {
 	fmt.Println("s:", s)
 
 }
+
+func Test_Bind(t *testing.T) {
+	tupleABI := `[{"constant":false,"inputs":[{"name":"p","type":"tuple","components":[{"name":"amount","type":"uint256"},{"name":"memo","type":"string"}]},{"name":"type","type":"bytes32[]"}],"name":"submit","outputs":[],"type":"function"}]`
+
+	code, err := Bind([]string{"token", "order"}, []string{testABI, tupleABI}, []string{"0x6080", ""}, "contracts")
+	assert.Nil(t, err)
+
+	for _, expected := range []string{
+		"package contracts",
+		"func DeployToken(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *Token, error)",
+		"func NewToken(address common.Address, backend bind.ContractBackend) (*Token, error)",
+		"func (_Token *Token) BalanceOf(opts *bind.CallOpts, owner common.Address) (*big.Int, error)",
+		"func (_Token *Token) Info(opts *bind.CallOpts) (TokenInfoResult, error)",
+		"func (_Token *Token) Transfer(opts *bind.TransactOpts, to common.Address, value *big.Int) (*types.Transaction, error)",
+		"func (_Token *Token) FilterTransfer(opts *bind.FilterOpts) ([]*TokenTransfer, error)",
+		"func (_Token *Token) WatchTransfer(opts *bind.WatchOpts, sink chan<- *TokenTransfer) (*bind.Subscription, error)",
+		"func (_Order *Order) Submit(opts *bind.TransactOpts, p struct {",
+		"arg1 [][32]byte) (*types.Transaction, error)",
+	} {
+		assert.True(t, strings.Contains(code, expected), expected)
+	}
+
+	// no deploy method without bytecode
+	assert.False(t, strings.Contains(code, "func DeployOrder"))
+
+	_, err = Bind([]string{"token"}, []string{"invalid"}, []string{""}, "contracts")
+	assert.NotNil(t, err)
+}
+
+func Test_BindReservedNames(t *testing.T) {
+	reservedABI := `[{"inputs":[{"name":"bytecode","type":"bytes"},{"name":"address","type":"address"}],"type":"constructor"},{"constant":true,"inputs":[{"name":"out","type":"uint256"},{"name":"ret","type":"uint256"},{"name":"common","type":"uint256"}],"name":"get","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":true,"inputs":[{"name":"err","type":"uint256"}],"name":"info","outputs":[{"name":"a","type":"uint256"},{"name":"b","type":"uint256"}],"type":"function"}]`
+
+	code, err := Bind([]string{"reserved"}, []string{reservedABI}, []string{"0x6080"}, "contracts")
+	assert.Nil(t, err)
+
+	for _, expected := range []string{
+		"func DeployReserved(auth *bind.TransactOpts, backend bind.ContractBackend, arg0 []byte, arg1 common.Address)",
+		"func (_Reserved *Reserved) Get(opts *bind.CallOpts, arg0 *big.Int, arg1 *big.Int, arg2 *big.Int) (*big.Int, error)",
+		"func (_Reserved *Reserved) Info(opts *bind.CallOpts, arg0 *big.Int) (ReservedInfoResult, error)",
+	} {
+		assert.True(t, strings.Contains(code, expected), expected)
+	}
+}
@@ -6,8 +6,10 @@
 package bind
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -25,7 +27,13 @@ func ParseArgs(abiArgs abi.Arguments, args []string) ([]interface{}, error) {
 
 	data := make([]interface{}, 0)
 	for i, input := range abiArgs {
-		arg, err := parseArg(bindTypeGo(input.Type), args[i])
+		var arg interface{}
+		var err error
+		if requiresJSON(input.Type) {
+			arg, err = parseJSONArg(input.Type, args[i])
+		} else {
+			arg, err = parseArg(bindTypeGo(input.Type), args[i])
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -134,3 +142,110 @@ func parseArg(abiType string, arg string) (interface{}, error) {
 		return arg, nil
 	}
 }
+
+// requiresJSON returns whether the argument of this type should be given in JSON,
+// e.g. {"name":"scdo","owners":["0x..."]} for tuples and [1,2,3] for arrays.
+func requiresJSON(t abi.Type) bool {
+	return t.T == abi.TupleTy || t.T == abi.SliceTy || t.T == abi.ArrayTy
+}
+
+// parseJSONArg parses the JSON argument to the go value of the abi type
+func parseJSONArg(t abi.Type, arg string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(arg))
+	decoder.UseNumber()
+
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON argument %v for type %v, %s", arg, t, err)
+	}
+
+	value, err := convertJSONArg(t, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Interface(), nil
+}
+
+func convertJSONArg(t abi.Type, raw interface{}) (reflect.Value, error) {
+	switch t.T {
+	case abi.TupleTy:
+		value := reflect.New(t.Type).Elem()
+		switch fields := raw.(type) {
+		case map[string]interface{}:
+			for i, name := range t.TupleRawNames {
+				field, ok := fields[name]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("field %v of tuple %v not found", name, t)
+				}
+
+				v, err := convertJSONArg(*t.TupleElems[i], field)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				value.Field(i).Set(v)
+			}
+		case []interface{}:
+			if len(fields) != len(t.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("field count mismatch: %d for tuple %v", len(fields), t)
+			}
+
+			for i, field := range fields {
+				v, err := convertJSONArg(*t.TupleElems[i], field)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				value.Field(i).Set(v)
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("tuple %v requires a JSON object or array, got %v", t, raw)
+		}
+		return value, nil
+	case abi.SliceTy, abi.ArrayTy:
+		elems, ok := raw.([]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%v requires a JSON array, got %v", t, raw)
+		}
+
+		var value reflect.Value
+		if t.T == abi.SliceTy {
+			value = reflect.MakeSlice(t.Type, len(elems), len(elems))
+		} else if len(elems) != t.Size {
+			return reflect.Value{}, fmt.Errorf("element count mismatch: %d for %v", len(elems), t)
+		} else {
+			value = reflect.New(t.Type).Elem()
+		}
+
+		for i, elem := range elems {
+			v, err := convertJSONArg(*t.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Index(i).Set(v)
+		}
+		return value, nil
+	default:
+		var arg string
+		switch v := raw.(type) {
+		case json.Number:
+			arg = v.String()
+		case string:
+			arg = v
+		case bool:
+			arg = strconv.FormatBool(v)
+		default:
+			return reflect.Value{}, fmt.Errorf("invalid JSON value %v for type %v", raw, t)
+		}
+
+		parsed, err := parseArg(bindTypeGo(t), arg)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		value := reflect.ValueOf(parsed)
+		if !value.Type().AssignableTo(t.Type) {
+			return reflect.Value{}, fmt.Errorf("cannot use %v as type %v", arg, t)
+		}
+		return value, nil
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"math"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/scdoproject/go-stem/accounts/abi"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/stretchr/testify/assert"
 )
@@ -85,3 +87,32 @@ func Test_parseArg(t *testing.T) {
 	_, err = parseArg("[32]byte", "0x000001c42c79f769a00bcdde948d09279f8385b3ca5e8593e3abc13e15635b38000001c42c79f769a00bcdde948d09279f8385b3ca5e8593e3abc13e15635b38")
 	assert.Error(t, err)
 }
+
+func Test_ParseArgs_JSON(t *testing.T) {
+	const abiJSON = `[{"type":"function","name":"register","constant":false,"inputs":[{"name":"info","type":"tuple","components":[{"name":"name","type":"string"},{"name":"owner","type":"address"},{"name":"ids","type":"uint64[]"}]},{"name":"amounts","type":"uint256[2]"}]}]`
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	assert.NoError(t, err)
+
+	inputs := parsed.Methods["register"].Inputs
+	args, err := ParseArgs(inputs, []string{`{"name":"scdo","owner":"0x6d4fca4dc6c49ce8df30e7b2887a08cd4d5a1451","ids":[1,2]}`, `["100","200"]`})
+	assert.NoError(t, err)
+
+	info := reflect.ValueOf(args[0])
+	assert.Equal(t, "scdo", info.FieldByName("Name").String())
+	assert.Equal(t, []uint64{1, 2}, info.FieldByName("Ids").Interface())
+	assert.Equal(t, [2]*big.Int{big.NewInt(100), big.NewInt(200)}, args[1])
+
+	// tuple in positional JSON array
+	args, err = ParseArgs(inputs, []string{`["scdo","0x6d4fca4dc6c49ce8df30e7b2887a08cd4d5a1451",[]]`, `[1,2]`})
+	assert.NoError(t, err)
+	_, err = parsed.Pack("register", args...)
+	assert.NoError(t, err)
+
+	// missing field
+	_, err = ParseArgs(inputs, []string{`{"name":"scdo"}`, `[1,2]`})
+	assert.Error(t, err)
+
+	// wrong array size
+	_, err = ParseArgs(inputs, []string{`["scdo","0x6d4fca4dc6c49ce8df30e7b2887a08cd4d5a1451",[]]`, `[1]`})
+	assert.Error(t, err)
+}
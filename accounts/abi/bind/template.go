@@ -0,0 +1,203 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package bind
+
+import "github.com/scdoproject/go-stem/accounts/abi"
+
+// tmplData is the data structure required to fill the binding template
+type tmplData struct {
+	Package   string                   // name of the package to place the generated file in
+	Contracts map[string]*tmplContract // list of contracts to generate into this file
+}
+
+// tmplContract contains the data needed to generate an individual contract binding
+type tmplContract struct {
+	Type        string                 // type name of the main contract binding
+	InputABI    string                 // quoted JSON ABI used as the input to generate the binding from
+	InputBin    string                 // optional hex bytecode used to deploy the contract
+	Constructor abi.Method             // contract constructor for deploy parametrization
+	Calls       map[string]*tmplMethod // contract calls that only read state data
+	Transacts   map[string]*tmplMethod // contract calls that write state data
+	Events      map[string]*tmplEvent  // contract events accessors
+}
+
+// tmplMethod is a wrapper around an abi.Method that contains a few preprocessed
+// and cached data fields.
+type tmplMethod struct {
+	Original   abi.Method // original method as parsed by the abi package
+	Normalized abi.Method // normalized version of the parsed method (capitalized names, non-anonymous args/returns)
+	Structured bool       // whether the returns should be accumulated into a struct
+}
+
+// tmplEvent is a wrapper around an abi.Event that contains a few preprocessed
+// and cached data fields.
+type tmplEvent struct {
+	Original   abi.Event // original event as parsed by the abi package
+	Normalized abi.Event // normalized version of the parsed fields
+}
+
+// tmplSource is the Go source template used to generate the contract binding
+const tmplSource = `
+// Code generated by abigen. DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/accounts/abi/bind"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = abi.JSON
+	_ = common.EmptyAddress
+	_ = hexutil.MustHexToBytes
+	_ = types.Log{}
+)
+
+{{range $contract := .Contracts}}
+	// {{.Type}}ABI is the input ABI used to generate the binding from.
+	const {{.Type}}ABI = {{.InputABI}}
+
+	{{if .InputBin}}
+		// {{.Type}}Bin is the compiled bytecode used for deploying new contracts.
+		const {{.Type}}Bin = ` + "`" + `{{.InputBin}}` + "`" + `
+
+		// Deploy{{.Type}} deploys a new Scdo contract, binding an instance of {{.Type}} to it.
+		func Deploy{{.Type}}(auth *bind.TransactOpts, backend bind.ContractBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+			bytecode, err := hexutil.HexToBytes({{.Type}}Bin)
+			if err != nil {
+				return common.EmptyAddress, nil, nil, err
+			}
+
+			address, tx, contract, err := bind.DeployContract(auth, {{.Type}}ABI, bytecode, backend {{range .Constructor.Inputs}}, {{.Name}}{{end}})
+			if err != nil {
+				return common.EmptyAddress, nil, nil, err
+			}
+
+			return address, tx, &{{.Type}}{contract: contract}, nil
+		}
+	{{end}}
+
+	// {{.Type}} is an auto generated Go binding around a Scdo contract.
+	type {{.Type}} struct {
+		contract *bind.BoundContract // generic contract binding to access the raw methods on
+	}
+
+	// New{{.Type}} creates a new instance of {{.Type}}, bound to a specific deployed contract.
+	func New{{.Type}}(address common.Address, backend bind.ContractBackend) (*{{.Type}}, error) {
+		contract, err := bind.NewBoundContract(address, {{.Type}}ABI, backend)
+		if err != nil {
+			return nil, err
+		}
+
+		return &{{.Type}}{contract: contract}, nil
+	}
+
+	{{range .Calls}}
+		{{if .Structured}}
+			// {{$contract.Type}}{{.Normalized.Name}}Result is the returns of method {{.Original.Name}}.
+			type {{$contract.Type}}{{.Normalized.Name}}Result struct {
+				{{range .Normalized.Outputs}}{{.Name}} {{bindtype .Type}}
+				{{end}}
+			}
+		{{end}}
+
+		// {{.Normalized.Name}} is a free data retrieval call binding the contract method {{printf "0x%x" .Original.Id}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(opts *bind.CallOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type}} {{end}}) ({{if .Structured}}{{$contract.Type}}{{.Normalized.Name}}Result,{{else}}{{range .Normalized.Outputs}}{{bindtype .Type}},{{end}}{{end}} error) {
+			{{- if .Structured}}
+				var ret {{$contract.Type}}{{.Normalized.Name}}Result
+				out := []interface{}{ {{range .Normalized.Outputs}}&ret.{{.Name}},{{end}} }
+				err := _{{$contract.Type}}.contract.Call(opts, &out, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+				return ret, err
+			{{- else if .Normalized.Outputs}}
+				var ret0 {{bindtype (index .Normalized.Outputs 0).Type}}
+				err := _{{$contract.Type}}.contract.Call(opts, &ret0, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+				return ret0, err
+			{{- else}}
+				return _{{$contract.Type}}.contract.Call(opts, nil, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+			{{- end}}
+		}
+	{{end}}
+
+	{{range .Transacts}}
+		// {{.Normalized.Name}} is a paid mutator transaction binding the contract method {{printf "0x%x" .Original.Id}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}(opts *bind.TransactOpts {{range .Normalized.Inputs}}, {{.Name}} {{bindtype .Type}} {{end}}) (*types.Transaction, error) {
+			return _{{$contract.Type}}.contract.Transact(opts, "{{.Original.Name}}" {{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		}
+	{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct {
+			{{range .Normalized.Inputs}}{{.Name}} {{if .Indexed}}{{bindtopictype .Type}}{{else}}{{bindtype .Type}}{{end}}
+			{{end}}
+			Raw types.Log // blockchain specific contextual infos
+		}
+
+		// Filter{{.Normalized.Name}} is a log retrieval operation binding the contract event {{.Original.Id.Hex}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Filter{{.Normalized.Name}}(opts *bind.FilterOpts) ([]*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			logs, err := _{{$contract.Type}}.contract.FilterLogs(opts, "{{.Original.Name}}")
+			if err != nil {
+				return nil, err
+			}
+
+			events := make([]*{{$contract.Type}}{{.Normalized.Name}}, 0, len(logs))
+			for _, log := range logs {
+				event := new({{$contract.Type}}{{.Normalized.Name}})
+				if err := _{{$contract.Type}}.contract.UnpackLog(event, "{{.Original.Name}}", log); err != nil {
+					return nil, err
+				}
+				events = append(events, event)
+			}
+
+			return events, nil
+		}
+
+		// Watch{{.Normalized.Name}} is a free log subscription operation binding the contract event {{.Original.Id.Hex}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Watch{{.Normalized.Name}}(opts *bind.WatchOpts, sink chan<- *{{$contract.Type}}{{.Normalized.Name}}) (*bind.Subscription, error) {
+			logs, sub, err := _{{$contract.Type}}.contract.WatchLogs(opts, "{{.Original.Name}}")
+			if err != nil {
+				return nil, err
+			}
+
+			go func() {
+				for log := range logs {
+					event := new({{$contract.Type}}{{.Normalized.Name}})
+					if err := _{{$contract.Type}}.contract.UnpackLog(event, "{{.Original.Name}}", log); err != nil {
+						continue
+					}
+
+					select {
+					case sink <- event:
+					case <-sub.Done():
+						return
+					}
+				}
+			}()
+
+			return sub, nil
+		}
+	{{end}}
+{{end}}
+`
@@ -0,0 +1,136 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package abi
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// Indexed returns the indexed arguments, which are stored in log topics
+func (arguments Arguments) Indexed() Arguments {
+	var ret []Argument
+	for _, arg := range arguments {
+		if arg.Indexed {
+			ret = append(ret, arg)
+		}
+	}
+	return ret
+}
+
+// UnpackTopics unpacks the indexed arguments from the log topics, which must not include
+// the event id. The indexed dynamic arguments (string, bytes, slices, dynamic arrays and
+// tuples) are stored as keccak256 hash of the encoded value, so they are returned as common.Hash.
+func (arguments Arguments) UnpackTopics(topics []common.Hash) ([]interface{}, error) {
+	indexed := arguments.Indexed()
+	if len(topics) != len(indexed) {
+		return nil, fmt.Errorf("abi: topic count mismatch, want %d, got %d", len(indexed), len(topics))
+	}
+
+	retval := make([]interface{}, 0, len(indexed))
+	for i, arg := range indexed {
+		if isDynamicType(arg.Type) || arg.Type.T == ArrayTy || arg.Type.T == TupleTy {
+			retval = append(retval, topics[i])
+			continue
+		}
+
+		value, err := toGoType(0, arg.Type, topics[i].Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		retval = append(retval, value)
+	}
+
+	return retval, nil
+}
+
+// UnpackLog unpacks all the event arguments from log topics and data, and returns
+// them in the order they are declared in the event.
+func (e Event) UnpackLog(topics []common.Hash, data []byte) ([]interface{}, error) {
+	if !e.Anonymous {
+		if len(topics) == 0 || !topics[0].Equal(e.Id()) {
+			return nil, fmt.Errorf("abi: log topic does not match event %s", e.Name)
+		}
+		topics = topics[1:]
+	}
+
+	indexedValues, err := e.Inputs.UnpackTopics(topics)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonIndexedValues []interface{}
+	if e.Inputs.LengthNonIndexed() > 0 {
+		if nonIndexedValues, err = e.Inputs.UnpackValues(data); err != nil {
+			return nil, err
+		}
+	}
+
+	retval := make([]interface{}, 0, len(e.Inputs))
+	for _, arg := range e.Inputs {
+		if arg.Indexed {
+			retval, indexedValues = append(retval, indexedValues[0]), indexedValues[1:]
+		} else {
+			retval, nonIndexedValues = append(retval, nonIndexedValues[0]), nonIndexedValues[1:]
+		}
+	}
+
+	return retval, nil
+}
+
+// PackLog packs the event arguments in the order they are declared in the event into
+// log topics and data, the reverse of UnpackLog. The indexed string and bytes arguments
+// are stored as keccak256 hash of the value, and other indexed dynamic arguments are not supported.
+func (e Event) PackLog(args ...interface{}) ([]common.Hash, []byte, error) {
+	if len(args) != len(e.Inputs) {
+		return nil, nil, fmt.Errorf("abi: argument count mismatch, want %d, got %d", len(e.Inputs), len(args))
+	}
+
+	var topics []common.Hash
+	if !e.Anonymous {
+		topics = append(topics, e.Id())
+	}
+
+	var nonIndexed []interface{}
+	for i, arg := range e.Inputs {
+		if !arg.Indexed {
+			nonIndexed = append(nonIndexed, args[i])
+			continue
+		}
+
+		switch {
+		case arg.Type.T == StringTy || arg.Type.T == BytesTy:
+			var value []byte
+			switch v := args[i].(type) {
+			case string:
+				value = []byte(v)
+			case []byte:
+				value = v
+			default:
+				return nil, nil, fmt.Errorf("abi: cannot use %T as indexed %v argument", args[i], arg.Type)
+			}
+			topics = append(topics, crypto.Keccak256Hash(value))
+		case isDynamicType(arg.Type) || arg.Type.T == ArrayTy || arg.Type.T == TupleTy:
+			return nil, nil, fmt.Errorf("abi: indexed %v argument not supported", arg.Type)
+		default:
+			packed, err := Arguments{arg}.Pack(args[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			topics = append(topics, common.BytesToHash(packed))
+		}
+	}
+
+	data, err := e.Inputs.NonIndexed().Pack(nonIndexed...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return topics, data, nil
+}
@@ -69,10 +69,12 @@ func printLogByABI(log *types.Log, parsed abi.ABI) (string, error) {
 		}
 	}
 
-	var err error
-	scdolog.Args, err = parsed.Events[scdolog.Event].Inputs.UnpackValues(log.Data)
-	if err != nil {
-		return "", err
+	if event, ok := parsed.Events[scdolog.Event]; ok {
+		var err error
+		scdolog.Args, err = event.UnpackLog(log.Topics, log.Data)
+		if err != nil {
+			return "", err
+		}
 	}
 
 	encoded, err := json.Marshal(scdolog)
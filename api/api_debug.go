@@ -5,6 +5,10 @@
 
 package api
 
+import (
+	"github.com/scdoproject/go-stem/log"
+)
+
 // PrivateDebugAPI provides an API to access full node-related information for debugging.
 type PrivateDebugAPI struct {
 	s Backend
@@ -45,3 +49,18 @@ func (api *PrivateDebugAPI) GetPendingTransactions() ([]map[string]interface{},
 
 	return transactions, nil
 }
+
+// SetLogLevel changes the log level of module at runtime, or the level of all modules if
+// module is "*". The level is one of panic, fatal, error, warn, info and debug.
+func (api *PrivateDebugAPI) SetLogLevel(module string, level string) (bool, error) {
+	if err := log.SetModuleLevel(module, level); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetLogLevels returns the log levels of all modules
+func (api *PrivateDebugAPI) GetLogLevels() (map[string]string, error) {
+	return log.GetModuleLevels(), nil
+}
@@ -0,0 +1,178 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package api
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+const (
+	// gasPriceBlocks is the number of recent blocks to suggest the gas price
+	gasPriceBlocks = 20
+
+	// gasPricePercentile is the percentile of the included gas prices to suggest
+	gasPricePercentile = 60
+
+	// maxFeeHistoryBlocks is the max number of blocks to query the fee history
+	maxFeeHistoryBlocks = 1024
+)
+
+// DefaultGasPrice is the suggested gas price if no tx is included in the recent blocks
+var DefaultGasPrice = big.NewInt(1)
+
+// FeeHistory is the gas prices of the recent blocks and the tx pool
+type FeeHistory struct {
+	OldestBlock   uint64       // height of the oldest block
+	GasPrices     [][]*big.Int // percentiles of the included gas prices in each block, empty if no tx
+	TxCounts      []int        // number of txs in each block, except the reward tx
+	PendingPrices []*big.Int   // percentiles of the gas prices in the tx pool
+	PendingCount  int          // number of pending txs in the tx pool
+}
+
+// GasPrice suggests the gas price to get a tx into the next blocks. It is the percentile of the
+// gas prices included in the recent blocks, and raised to the lowest price that could be packed
+// in the next block if the tx pool is congested.
+func (api *PublicScdoAPI) GasPrice() (*big.Int, error) {
+	included, blocks, err := api.includedGasPrices(gasPriceBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestGasPrice(included, blocks, api.pendingGasPrices()), nil
+}
+
+// FeeHistory returns the percentiles of the included gas prices in the recent blockCount blocks,
+// and the percentiles of the gas prices in the tx pool. The percentiles are in the range [0, 100].
+func (api *PublicScdoAPI) FeeHistory(blockCount uint64, percentiles []float64) (*FeeHistory, error) {
+	if blockCount == 0 || blockCount > maxFeeHistoryBlocks {
+		return nil, fmt.Errorf("invalid block count %v, should be in range [1, %v]", blockCount, maxFeeHistoryBlocks)
+	}
+
+	for _, p := range percentiles {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %v, should be in range [0, 100]", p)
+		}
+	}
+
+	current := api.s.ChainBackend().CurrentHeader().Height
+	if blockCount > current+1 {
+		blockCount = current + 1
+	}
+
+	history := &FeeHistory{OldestBlock: current + 1 - blockCount}
+	for height := history.OldestBlock; height <= current; height++ {
+		prices, err := api.blockGasPrices(height)
+		if err != nil {
+			return nil, err
+		}
+
+		history.TxCounts = append(history.TxCounts, len(prices))
+		history.GasPrices = append(history.GasPrices, percentileGasPrices(prices, percentiles))
+	}
+
+	pending := api.pendingGasPrices()
+	history.PendingCount = len(pending)
+	history.PendingPrices = percentileGasPrices(pending, percentiles)
+
+	return history, nil
+}
+
+// blockGasPrices returns the gas prices of txs in block at height, except the reward tx
+func (api *PublicScdoAPI) blockGasPrices(height uint64) ([]*big.Int, error) {
+	block, err := api.s.ChainBackend().GetStore().GetBlockByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block by height %v, %s", height, err)
+	}
+
+	var prices []*big.Int
+	for _, tx := range block.GetExcludeRewardTransactions() {
+		prices = append(prices, tx.Data.GasPrice)
+	}
+
+	return prices, nil
+}
+
+// includedGasPrices returns the gas prices of txs in the recent blocks and the number of blocks
+func (api *PublicScdoAPI) includedGasPrices(count uint64) ([]*big.Int, int, error) {
+	current := api.s.ChainBackend().CurrentHeader().Height
+
+	var prices []*big.Int
+	blocks := 0
+	for height := current; height > 0 && uint64(blocks) < count; height-- {
+		blockPrices, err := api.blockGasPrices(height)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		prices = append(prices, blockPrices...)
+		blocks++
+	}
+
+	return prices, blocks, nil
+}
+
+// pendingGasPrices returns the gas prices of pending txs in the tx pool
+func (api *PublicScdoAPI) pendingGasPrices() []*big.Int {
+	var prices []*big.Int
+	for _, tx := range api.s.TxPoolBackend().GetTransactions(false, true) {
+		prices = append(prices, tx.Data.GasPrice)
+	}
+
+	return prices
+}
+
+// suggestGasPrice returns the percentile of the included gas prices, which is raised to the lowest
+// price packed in the next block if the pending txs are more than the average txs per block.
+func suggestGasPrice(included []*big.Int, blocks int, pending []*big.Int) *big.Int {
+	price := new(big.Int).Set(DefaultGasPrice)
+	if len(included) > 0 {
+		price = percentileGasPrices(included, []float64{gasPricePercentile})[0]
+	}
+
+	capacity := 1
+	if blocks > 0 && len(included)/blocks > capacity {
+		capacity = len(included) / blocks
+	}
+
+	if len(pending) > capacity {
+		sorted := sortGasPrices(pending)
+		// the pool packs txs in the descending order of gas price
+		if marginal := sorted[len(sorted)-capacity]; marginal.Cmp(price) > 0 {
+			price = new(big.Int).Set(marginal)
+		}
+	}
+
+	return price
+}
+
+// percentileGasPrices returns the gas prices at the percentiles, or nil if prices is empty
+func percentileGasPrices(prices []*big.Int, percentiles []float64) []*big.Int {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	sorted := sortGasPrices(prices)
+	result := make([]*big.Int, len(percentiles))
+	for i, p := range percentiles {
+		index := int(p / 100 * float64(len(sorted)-1))
+		result[i] = new(big.Int).Set(sorted[index])
+	}
+
+	return result
+}
+
+// sortGasPrices returns the gas prices in ascending order
+func sortGasPrices(prices []*big.Int) []*big.Int {
+	sorted := make([]*big.Int, len(prices))
+	copy(sorted, prices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	return sorted
+}
@@ -0,0 +1,51 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newGasPrices(prices ...int64) []*big.Int {
+	result := make([]*big.Int, len(prices))
+	for i, p := range prices {
+		result[i] = big.NewInt(p)
+	}
+
+	return result
+}
+
+func Test_PercentileGasPrices(t *testing.T) {
+	assert.Equal(t, len(percentileGasPrices(nil, []float64{50})), 0)
+
+	prices := newGasPrices(5, 1, 4, 2, 3)
+	result := percentileGasPrices(prices, []float64{0, 50, 100})
+	assert.Equal(t, result, newGasPrices(1, 3, 5))
+
+	// prices are not changed
+	assert.Equal(t, prices, newGasPrices(5, 1, 4, 2, 3))
+}
+
+func Test_SuggestGasPrice(t *testing.T) {
+	// no tx included
+	assert.Equal(t, suggestGasPrice(nil, 10, nil), DefaultGasPrice)
+
+	// 60th percentile of included prices
+	included := newGasPrices(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	assert.Equal(t, suggestGasPrice(included, 5, nil), big.NewInt(6))
+
+	// pool is not congested, 2 txs per block
+	assert.Equal(t, suggestGasPrice(included, 5, newGasPrices(20, 30)), big.NewInt(6))
+
+	// pool is congested, the lowest price of the 2 txs packed in the next block
+	assert.Equal(t, suggestGasPrice(included, 5, newGasPrices(20, 30, 40, 1)), big.NewInt(30))
+
+	// congested with lower prices
+	assert.Equal(t, suggestGasPrice(included, 5, newGasPrices(2, 3, 1)), big.NewInt(6))
+}
@@ -0,0 +1,40 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package api
+
+import (
+	"errors"
+
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+const (
+	// PendingBlockTag is the block hash param to query the pending state
+	PendingBlockTag = "pending"
+
+	// PendingBlockHeight is the block height param to query the pending state
+	PendingBlockHeight int64 = -2
+)
+
+// ErrPendingNotSupported is returned when the chain could not build the pending state, e.g. light chain
+var ErrPendingNotSupported = errors.New("pending state is not supported")
+
+// pendingChain is the chain that applies the pool txs on the current block
+type pendingChain interface {
+	PendingState(txs []*types.Transaction) (*types.BlockHeader, *state.Statedb, error)
+}
+
+// PendingState returns the header and statedb of the pending block, which applies the
+// processing and pending txs in the tx pool on top of the current block.
+func PendingState(s Backend) (*types.BlockHeader, *state.Statedb, error) {
+	chain, ok := s.ChainBackend().(pendingChain)
+	if !ok {
+		return nil, nil, ErrPendingNotSupported
+	}
+
+	return chain.PendingState(s.TxPoolBackend().GetTransactions(true, true))
+}
@@ -0,0 +1,148 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package api
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/common/keystore"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// SendTxArgs represents the arguments to sign and send a transaction with keystore accounts
+type SendTxArgs struct {
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"` // empty to create a contract
+	Amount   *big.Int       `json:"amount"`
+	GasPrice *big.Int       `json:"gasPrice"`
+	GasLimit uint64         `json:"gasLimit"`
+	Payload  string         `json:"payload"` // hex encoded payload
+	Nonce    *uint64        `json:"nonce"`   // optional, the nonce in current state is used if not specified
+}
+
+// PrivatePersonalAPI provides an API to manage the accounts in keystore directory,
+// and sign transactions with the unlocked accounts.
+type PrivatePersonalAPI struct {
+	s  Backend
+	ks *keystore.Manager
+}
+
+// NewPrivatePersonalAPI creates a new PrivatePersonalAPI object for rpc service.
+func NewPrivatePersonalAPI(s Backend, ks *keystore.Manager) *PrivatePersonalAPI {
+	return &PrivatePersonalAPI{s, ks}
+}
+
+// ListAccounts returns the accounts in keystore directory
+func (api *PrivatePersonalAPI) ListAccounts() ([]keystore.Account, error) {
+	return api.ks.Accounts()
+}
+
+// NewAccount creates a random account in the specified shard, and stores the key encrypted with the passphrase
+func (api *PrivatePersonalAPI) NewAccount(shard uint, passphrase string) (common.Address, error) {
+	account, err := api.ks.NewAccount(shard, passphrase)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+
+	return account.Address, nil
+}
+
+// ImportRawKey stores the hex encoded private key encrypted with the passphrase
+func (api *PrivatePersonalAPI) ImportRawKey(privateKey, passphrase string) (common.Address, error) {
+	key, err := crypto.LoadECDSAFromString(privateKey)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+
+	account, err := api.ks.Import(&keystore.Key{Address: *crypto.GetAddress(&key.PublicKey), PrivateKey: key}, passphrase)
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+
+	return account.Address, nil
+}
+
+// UnlockAccount unlocks the account for the duration in seconds, or until locked if duration is 0
+func (api *PrivatePersonalAPI) UnlockAccount(account common.Address, passphrase string, duration uint64) (bool, error) {
+	if err := api.ks.Unlock(account, passphrase, time.Duration(duration)*time.Second); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// LockAccount locks the account
+func (api *PrivatePersonalAPI) LockAccount(account common.Address) bool {
+	api.ks.Lock(account)
+	return true
+}
+
+// SignTransaction creates the transaction and signs it with the key of sender. The key is
+// decrypted with the passphrase if specified, otherwise the sender must be unlocked.
+func (api *PrivatePersonalAPI) SignTransaction(args SendTxArgs, passphrase string) (*types.Transaction, error) {
+	if args.Amount == nil || args.GasPrice == nil {
+		return nil, errors.New("amount and gas price are required")
+	}
+
+	payload, err := hexutil.HexToBytes(args.Payload)
+	if err != nil && len(args.Payload) > 0 {
+		return nil, err
+	}
+
+	var nonce uint64
+	if args.Nonce != nil {
+		nonce = *args.Nonce
+	} else {
+		statedb, err := api.s.ChainBackend().GetCurrentState()
+		if err != nil {
+			return nil, err
+		}
+
+		nonce = statedb.GetNonce(args.From)
+	}
+
+	var tx *types.Transaction
+	if args.To.IsEmpty() {
+		tx, err = types.NewContractTransaction(args.From, args.Amount, args.GasPrice, args.GasLimit, nonce, payload)
+	} else {
+		tx, err = types.NewMessageTransaction(args.From, args.To, args.Amount, args.GasPrice, args.GasLimit, nonce, payload)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) > 0 {
+		err = api.ks.SignTxWithPassphrase(tx, passphrase)
+	} else {
+		err = api.ks.SignTx(tx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// SendTransaction signs the transaction the same as SignTransaction, and adds it to the node
+func (api *PrivatePersonalAPI) SendTransaction(args SendTxArgs, passphrase string) (common.Hash, error) {
+	tx, err := api.SignTransaction(args, passphrase)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	if _, err = NewPublicScdoAPI(api.s).AddTx(*tx); err != nil {
+		return common.EmptyHash, err
+	}
+
+	return tx.Hash, nil
+}
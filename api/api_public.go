@@ -12,6 +12,7 @@ import (
 
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/contract/system"
 	"github.com/scdoproject/go-stem/core/state"
 	"github.com/scdoproject/go-stem/core/types"
 )
@@ -59,10 +60,17 @@ func (api *PublicScdoAPI) GetBalance(account common.Address, hexHash string, hei
 	return &info, nil
 }
 
+// getStatedb returns the statedb of block hash or height. The pending statedb is returned
+// if hexHash is PendingBlockTag or height is PendingBlockHeight.
 func (api *PublicScdoAPI) getStatedb(hexHash string, height int64) (*state.Statedb, error) {
 	var blockHash common.Hash
 	var err error
 
+	if hexHash == PendingBlockTag || (len(hexHash) == 0 && height == PendingBlockHeight) {
+		_, statedb, err := PendingState(api.s)
+		return statedb, err
+	}
+
 	if len(hexHash) > 0 {
 		if blockHash, err = common.HexToHash(hexHash); err != nil {
 			return nil, errors.NewStackedError(err, "failed to convert HEX to hash")
@@ -115,6 +123,39 @@ func (api *PublicScdoAPI) GetAccountTxCount(account common.Address, hexHash stri
 	return count, nil
 }
 
+// GetMasternodes returns the active masternodes in the registry with their endpoints.
+func (api *PublicScdoAPI) GetMasternodes(hexHash string, height int64) ([]*system.Masternode, error) {
+	state, err := api.getStatedb(hexHash, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return system.GetMasternodes(state)
+}
+
+// GetMasternode returns the masternode info of the account.
+func (api *PublicScdoAPI) GetMasternode(account common.Address, hexHash string, height int64) (*system.Masternode, error) {
+	if account.Equal(common.EmptyAddress) {
+		return nil, ErrInvalidAccount
+	}
+
+	state, err := api.getStatedb(hexHash, height)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := system.GetMasternode(account, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil {
+		return nil, system.ErrNotExist
+	}
+
+	return node, nil
+}
+
 // GetBlockHeight get the block height of the chain head
 func (api *PublicScdoAPI) GetBlockHeight() (uint64, error) {
 	header := api.s.ChainBackend().CurrentHeader()
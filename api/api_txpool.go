@@ -63,6 +63,10 @@ func PrintableReceipt(re *types.Receipt) (map[string]interface{}, error) {
 		outMap["logs"] = re.Logs
 	}
 
+	if len(re.Debts) > 0 {
+		outMap["debts"] = re.Debts
+	}
+
 	return outMap, nil
 }
 
@@ -26,3 +26,22 @@ func PedersonCommit(value []*big.Int) (ECPoint, []*big.Int) {
 	}
 	return commitment, R
 }
+
+/*
+TwoVectorPCommitWithGens commits the two vectors a and b with the generators G and H,
+which is <a,G> + <b,H>
+*/
+func TwoVectorPCommitWithGens(G, H []ECPoint, a, b []*big.Int) ECPoint {
+	if len(G) != len(H) || len(G) != len(a) || len(a) != len(b) {
+		panic("TwoVectorPCommitWithGens: Arrays not of the same length")
+	}
+
+	commitment := EC.Zero()
+	for i := range G {
+		modA := new(big.Int).Mod(a[i], EC.N)
+		modB := new(big.Int).Mod(b[i], EC.N)
+		commitment = commitment.Add(G[i].Mult(modA)).Add(H[i].Mult(modB))
+	}
+
+	return commitment
+}
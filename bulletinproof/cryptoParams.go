@@ -10,10 +10,15 @@ import (
 	"math/big"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/scdoproject/go-stem/log"
 )
 
 var VecLength = 64
 
+// logger prints the failures of proving and verification at debug level, because the
+// verification is on the path of block validation and could be triggered by any peer.
+var logger = log.GetLogger("bulletproof")
+
 type CryptoParams struct {
 	C   elliptic.Curve      // curve
 	KC  *btcec.KoblitzCurve // curve
@@ -0,0 +1,265 @@
+package bp
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+/**
+Deterministic encoding of the range proofs
+The points are encoded in the 33 bytes compressed form, and the scalars in 32 bytes big-endian.
+The fields are concatenated in the order of the struct, and the vector lengths are implied by
+the vector length EC.V, except that the number of commitments of the multi range proof is
+prefixed in 1 byte. Every proof has exactly one encoding, and the decoded points are on curve.
+*/
+
+const (
+	// PointSize is the size of encoded EC point
+	PointSize = 33
+	// ScalarSize is the size of encoded scalar
+	ScalarSize = 32
+)
+
+var (
+	// ErrInvalidPoint is returned when the bytes is not a point on curve
+	ErrInvalidPoint = errors.New("invalid EC point")
+	// ErrInvalidScalar is returned when the scalar is not less than the group order
+	ErrInvalidScalar = errors.New("invalid scalar")
+	// ErrInvalidProofSize is returned when the size of encoded proof is wrong
+	ErrInvalidProofSize = errors.New("invalid range proof size")
+	// ErrInvalidCommitments is returned when the number of commitments is not a power of two
+	ErrInvalidCommitments = errors.New("invalid number of commitments")
+)
+
+// IsZero returns true if p is the point at infinity
+func (p ECPoint) IsZero() bool {
+	return p.X == nil || p.Y == nil || (p.X.Sign() == 0 && p.Y.Sign() == 0)
+}
+
+// Bytes returns the compressed encoding of point p, which is all zero for the point at infinity
+func (p ECPoint) Bytes() []byte {
+	if p.IsZero() {
+		return make([]byte, PointSize)
+	}
+
+	return (&btcec.PublicKey{Curve: EC.KC, X: p.X, Y: p.Y}).SerializeCompressed()
+}
+
+// ECPointFromBytes decodes the compressed point, which should be on curve
+func ECPointFromBytes(b []byte) (ECPoint, error) {
+	if len(b) != PointSize {
+		return ECPoint{}, ErrInvalidPoint
+	}
+
+	pub, err := btcec.ParsePubKey(b, EC.KC)
+	if err != nil {
+		return ECPoint{}, ErrInvalidPoint
+	}
+
+	return ECPoint{pub.X, pub.Y}, nil
+}
+
+// Bytes returns the deterministic encoding of the range proof
+func (rp RangeProof) Bytes() []byte {
+	w := &proofWriter{}
+	w.point(rp.Comm)
+	w.proof(rp.A, rp.S, rp.T1, rp.T2, rp.Tau, rp.Th, rp.Mu, rp.IPP, rp.Cy, rp.Cz, rp.Cx)
+
+	return w.buf
+}
+
+// RangeProofFromBytes decodes the range proof encoded by RangeProof.Bytes
+func RangeProofFromBytes(b []byte) (RangeProof, error) {
+	r := &proofReader{buf: b}
+	rp := RangeProof{Comm: r.point()}
+	rp.A, rp.S, rp.T1, rp.T2, rp.Tau, rp.Th, rp.Mu, rp.IPP, rp.Cy, rp.Cz, rp.Cx = r.proof()
+
+	if err := r.finish(); err != nil {
+		return RangeProof{}, err
+	}
+
+	return rp, nil
+}
+
+// Bytes returns the deterministic encoding of the multi range proof
+func (mrp MultiRangeProof) Bytes() []byte {
+	w := &proofWriter{}
+	w.buf = append(w.buf, byte(len(mrp.Comms)))
+	for _, comm := range mrp.Comms {
+		w.point(comm)
+	}
+
+	w.proof(mrp.A, mrp.S, mrp.T1, mrp.T2, mrp.Tau, mrp.Th, mrp.Mu, mrp.IPP, mrp.Cy, mrp.Cz, mrp.Cx)
+
+	return w.buf
+}
+
+// MultiRangeProofFromBytes decodes the multi range proof encoded by MultiRangeProof.Bytes.
+// The number of commitments should be a power of two, and at most EC.V.
+func MultiRangeProofFromBytes(b []byte) (MultiRangeProof, error) {
+	if len(b) == 0 {
+		return MultiRangeProof{}, ErrInvalidProofSize
+	}
+
+	m := int(b[0])
+	if m == 0 || m > EC.V || m&(m-1) != 0 {
+		return MultiRangeProof{}, ErrInvalidCommitments
+	}
+
+	r := &proofReader{buf: b[1:]}
+	mrp := MultiRangeProof{Comms: make([]ECPoint, m)}
+	for i := range mrp.Comms {
+		mrp.Comms[i] = r.point()
+	}
+
+	mrp.A, mrp.S, mrp.T1, mrp.T2, mrp.Tau, mrp.Th, mrp.Mu, mrp.IPP, mrp.Cy, mrp.Cz, mrp.Cx = r.proof()
+
+	if err := r.finish(); err != nil {
+		return MultiRangeProof{}, err
+	}
+
+	return mrp, nil
+}
+
+// innerProdRounds returns the number of rounds of inner product argument for EC.V
+func innerProdRounds() int {
+	return bits.Len(uint(EC.V)) - 1
+}
+
+type proofWriter struct {
+	buf []byte
+}
+
+func (w *proofWriter) point(p ECPoint) {
+	w.buf = append(w.buf, p.Bytes()...)
+}
+
+func (w *proofWriter) scalar(s *big.Int) {
+	b := make([]byte, ScalarSize)
+	if s != nil {
+		s.FillBytes(b)
+	}
+
+	w.buf = append(w.buf, b...)
+}
+
+// proof writes the fields shared by the range proof and multi range proof
+func (w *proofWriter) proof(A, S, T1, T2 ECPoint, tau, th, mu *big.Int, ipp InnerProdArg, cy, cz, cx *big.Int) {
+	for _, p := range []ECPoint{A, S, T1, T2} {
+		w.point(p)
+	}
+
+	for _, s := range []*big.Int{tau, th, mu} {
+		w.scalar(s)
+	}
+
+	for _, p := range ipp.L {
+		w.point(p)
+	}
+
+	for _, p := range ipp.R {
+		w.point(p)
+	}
+
+	w.scalar(ipp.A)
+	w.scalar(ipp.B)
+	for _, c := range ipp.Challenges {
+		w.scalar(c)
+	}
+
+	for _, c := range []*big.Int{cy, cz, cx} {
+		w.scalar(c)
+	}
+}
+
+type proofReader struct {
+	buf []byte
+	err error
+}
+
+func (r *proofReader) next(size int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	if len(r.buf) < size {
+		r.err = ErrInvalidProofSize
+		return nil
+	}
+
+	b := r.buf[:size]
+	r.buf = r.buf[size:]
+	return b
+}
+
+func (r *proofReader) point() ECPoint {
+	b := r.next(PointSize)
+	if r.err != nil {
+		return ECPoint{}
+	}
+
+	p, err := ECPointFromBytes(b)
+	if err != nil {
+		r.err = err
+	}
+
+	return p
+}
+
+// scalar reads a scalar less than the group order
+func (r *proofReader) scalar() *big.Int {
+	s := r.challenge()
+	if r.err == nil && s.Cmp(EC.N) >= 0 {
+		r.err = ErrInvalidScalar
+	}
+
+	return s
+}
+
+// challenge reads a Fiat-Shamir challenge, which is the full hash and may exceed the group
+// order, and is checked against the hash of proof in verification.
+func (r *proofReader) challenge() *big.Int {
+	b := r.next(ScalarSize)
+	if r.err != nil {
+		return nil
+	}
+
+	return new(big.Int).SetBytes(b)
+}
+
+// proof reads the fields shared by the range proof and multi range proof
+func (r *proofReader) proof() (A, S, T1, T2 ECPoint, tau, th, mu *big.Int, ipp InnerProdArg, cy, cz, cx *big.Int) {
+	A, S, T1, T2 = r.point(), r.point(), r.point(), r.point()
+	tau, th, mu = r.scalar(), r.scalar(), r.scalar()
+
+	n := innerProdRounds()
+	ipp.L = make([]ECPoint, n)
+	ipp.R = make([]ECPoint, n)
+	for i := range ipp.L {
+		ipp.L[i] = r.point()
+	}
+
+	for i := range ipp.R {
+		ipp.R[i] = r.point()
+	}
+
+	ipp.A, ipp.B = r.scalar(), r.scalar()
+	ipp.Challenges = make([]*big.Int, n+1)
+	for i := range ipp.Challenges {
+		ipp.Challenges[i] = r.challenge()
+	}
+
+	cy, cz, cx = r.challenge(), r.challenge(), r.challenge()
+	return
+}
+
+func (r *proofReader) finish() error {
+	if r.err == nil && len(r.buf) != 0 {
+		r.err = ErrInvalidProofSize
+	}
+
+	return r.err
+}
@@ -2,10 +2,8 @@ package bp
 
 import (
 	"crypto/sha256"
-	"fmt"
 	"math"
 	"math/big"
-	"strconv"
 )
 
 /*
@@ -138,7 +136,7 @@ func InnerProductVerify(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdA
 	curIt := len(ipp.Challenges) - 1
 
 	if ipp.Challenges[curIt].Cmp(chal1) != 0 {
-		fmt.Println("IPVerify - Initial Challenge Failed")
+		logger.Debug("IPVerify - Initial Challenge Failed")
 		return false
 	}
 
@@ -161,7 +159,7 @@ func InnerProductVerify(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdA
 		chal2 := new(big.Int).SetBytes(s256[:])
 
 		if ipp.Challenges[curIt].Cmp(chal2) != 0 {
-			fmt.Println("IPVerify - Challenge verification failed at index " + strconv.Itoa(curIt))
+			logger.Debug("IPVerify - Challenge verification failed at index %d", curIt)
 			return false
 		}
 
@@ -176,9 +174,9 @@ func InnerProductVerify(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerProdA
 	Pcalc := Pcalc1.Add(Pcalc2).Add(Pcalc3)
 
 	if !Pprime.Equal(Pcalc) {
-		fmt.Println("IPVerify - Final Commitment checking failed")
-		fmt.Printf("Final Pprime value: %s \n", Pprime)
-		fmt.Printf("Calculated Pprime value to check against: %s \n", Pcalc)
+		logger.Debug("IPVerify - Final Commitment checking failed")
+		logger.Debug("Final Pprime value: %s", Pprime)
+		logger.Debug("Calculated Pprime value to check against: %s", Pcalc)
 		return false
 	}
 
@@ -200,7 +198,7 @@ func InnerProductVerifyFast(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerP
 
 	// check all challenges
 	if ipp.Challenges[curIt].Cmp(chal1) != 0 {
-		fmt.Println("IPVerify - Initial Challenge Failed")
+		logger.Debug("IPVerify - Initial Challenge Failed")
 		return false
 	}
 
@@ -216,7 +214,7 @@ func InnerProductVerifyFast(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerP
 		chal2 := new(big.Int).SetBytes(s256[:])
 
 		if ipp.Challenges[j].Cmp(chal2) != 0 {
-			fmt.Println("IPVerify - Challenge verification failed at index " + strconv.Itoa(j))
+			logger.Debug("IPVerify - Challenge verification failed at index %d", j)
 			return false
 		}
 	}
@@ -258,9 +256,9 @@ func InnerProductVerifyFast(c *big.Int, P, U ECPoint, G, H []ECPoint, ipp InnerP
 	lhs := TwoVectorPCommitWithGens(G, H, ScalarVectorMul(sScalars, ipp.A), ScalarVectorMul(invsScalars, ipp.B)).Add(ux.Mult(ccalc))
 
 	if !rhs.Equal(lhs) {
-		fmt.Println("IPVerify - Final Commitment checking failed")
-		fmt.Printf("Final rhs value: %s \n", rhs)
-		fmt.Printf("Final lhs value: %s \n", lhs)
+		logger.Debug("IPVerify - Final Commitment checking failed")
+		logger.Debug("Final rhs value: %s", rhs)
+		logger.Debug("Final lhs value: %s", lhs)
 		return false
 	}
 
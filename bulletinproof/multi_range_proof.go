@@ -38,11 +38,11 @@ func CalculateLMRP(aL, sL []*big.Int, z, x *big.Int) []*big.Int {
 
 func CalculateRMRP(aR, sR, y, zTimesTwo []*big.Int, z, x *big.Int) []*big.Int {
 	if len(aR) != len(sR) || len(aR) != len(y) || len(y) != len(zTimesTwo) {
-		fmt.Println("CalculateR: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(aR): %d\n", len(aR))
-		fmt.Printf("len(sR): %d\n", len(sR))
-		fmt.Printf("len(y): %d\n", len(y))
-		fmt.Printf("len(po2): %d\n", len(zTimesTwo))
+		logger.Debug("CalculateR: Uh oh! Arrays not of the same length")
+		logger.Debug("len(aR): %d", len(aR))
+		logger.Debug("len(sR): %d", len(sR))
+		logger.Debug("len(y): %d", len(y))
+		logger.Debug("len(po2): %d", len(zTimesTwo))
 	}
 
 	result := make([]*big.Int, len(aR))
@@ -120,7 +120,7 @@ func MRPProve(values []*big.Int) MultiRangeProof {
 			panic("Value is below range! Not proving")
 		}
 
-		if v.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(bitsPerValue)), EC.N)) == 1 {
+		if v.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(bitsPerValue)), EC.N)) != -1 {
 			panic("Value is above range! Not proving.")
 		}
 
@@ -156,8 +156,7 @@ func MRPProve(values []*big.Int) MultiRangeProof {
 	S := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, sL, sR).Add(EC.H.Mult(rho))
 	MRPResult.S = S
 
-	chal1s256 := sha256.Sum256([]byte(A.X.String() + A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	cy := ChallengeY(Comms, A)
 	MRPResult.Cy = cy
 
 	chal2s256 := sha256.Sum256([]byte(S.X.String() + S.Y.String()))
@@ -249,9 +248,9 @@ func MRPProve(values []*big.Int) MultiRangeProof {
 
 	// thatPrime and that should be equal
 	if thatPrime.Cmp(that) != 0 {
-		fmt.Println("Proving -- Uh oh! Two diff ways to compute same value not working")
-		fmt.Printf("\tthatPrime = %s\n", thatPrime.String())
-		fmt.Printf("\tthat = %s \n", that.String())
+		logger.Debug("Proving -- Uh oh! Two diff ways to compute same value not working")
+		logger.Debug("thatPrime = %s", thatPrime.String())
+		logger.Debug("that = %s", that.String())
 	}
 
 	MRPResult.Th = that
@@ -299,22 +298,21 @@ func MRPVerify(mrp MultiRangeProof) bool {
 	// check 2 commitment generation is also different
 
 	// verify the challenges
-	chal1s256 := sha256.Sum256([]byte(mrp.A.X.String() + mrp.A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	cy := ChallengeY(mrp.Comms, mrp.A)
 	if cy.Cmp(mrp.Cy) != 0 {
-		fmt.Println("MRPVerify - Challenge Cy failing!")
+		logger.Debug("MRPVerify - Challenge Cy failing!")
 		return false
 	}
 	chal2s256 := sha256.Sum256([]byte(mrp.S.X.String() + mrp.S.Y.String()))
 	cz := new(big.Int).SetBytes(chal2s256[:])
 	if cz.Cmp(mrp.Cz) != 0 {
-		fmt.Println("MRPVerify - Challenge Cz failing!")
+		logger.Debug("MRPVerify - Challenge Cz failing!")
 		return false
 	}
 	chal3s256 := sha256.Sum256([]byte(mrp.T1.X.String() + mrp.T1.Y.String() + mrp.T2.X.String() + mrp.T2.Y.String()))
 	cx := new(big.Int).SetBytes(chal3s256[:])
 	if cx.Cmp(mrp.Cx) != 0 {
-		fmt.Println("RPVerify - Challenge Cx failing!")
+		logger.Debug("RPVerify - Challenge Cx failing!")
 		return false
 	}
 
@@ -338,9 +336,9 @@ func MRPVerify(mrp MultiRangeProof) bool {
 		mrp.T2.Mult(new(big.Int).Mul(cx, cx))).Add(CommPowers)
 
 	if !lhs.Equal(rhs) {
-		fmt.Println("MRPVerify - Uh oh! Check line (63) of verification")
-		fmt.Println(rhs)
-		fmt.Println(lhs)
+		logger.Debug("MRPVerify - Uh oh! Check line (63) of verification")
+		logger.Debug("%v", rhs)
+		logger.Debug("%v", lhs)
 		return false
 	}
 
@@ -375,7 +373,7 @@ func MRPVerify(mrp MultiRangeProof) bool {
 	//fmt.Println(P)
 
 	if !InnerProductVerifyFast(mrp.Th, P, EC.U, EC.BPG, HPrime, mrp.IPP) {
-		fmt.Println("MRPVerify - Uh oh! Check line (65) of verification!")
+		logger.Debug("MRPVerify - Uh oh! Check line (65) of verification!")
 		return false
 	}
 
@@ -61,11 +61,11 @@ func CalculateL(aL, sL []*big.Int, z, x *big.Int) []*big.Int {
 
 func CalculateR(aR, sR, y, po2 []*big.Int, z, x *big.Int) []*big.Int {
 	if len(aR) != len(sR) || len(aR) != len(y) || len(y) != len(po2) {
-		fmt.Println("CalculateR: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(aR): %d\n", len(aR))
-		fmt.Printf("len(sR): %d\n", len(sR))
-		fmt.Printf("len(y): %d\n", len(y))
-		fmt.Printf("len(po2): %d\n", len(po2))
+		logger.Debug("CalculateR: Uh oh! Arrays not of the same length")
+		logger.Debug("len(aR): %d", len(aR))
+		logger.Debug("len(sR): %d", len(sR))
+		logger.Debug("len(y): %d", len(y))
+		logger.Debug("len(po2): %d", len(po2))
 	}
 
 	result := make([]*big.Int, len(aR))
@@ -81,11 +81,37 @@ func CalculateR(aR, sR, y, po2 []*big.Int, z, x *big.Int) []*big.Int {
 	return result
 }
 
+/*
+ChallengeY derives the first challenge of range proof from the value commitments and A,
+so that the proof is bound to the commitments and could not be reused for other ones.
+*/
+func ChallengeY(comms []ECPoint, A ECPoint) *big.Int {
+	str := ""
+	for _, comm := range comms {
+		str += comm.X.String() + comm.Y.String()
+	}
+
+	chal1s256 := sha256.Sum256([]byte(str + A.X.String() + A.Y.String()))
+	return new(big.Int).SetBytes(chal1s256[:])
+}
+
 /*
 RPProver : Range Proof Prove
 Given a value v, provides a range proof that v is inside 0 to 2^64-1
 */
 func RPProve(v *big.Int) RangeProof {
+	gamma, err := rand.Int(rand.Reader, EC.N)
+	check(err)
+
+	return RPProveWithGamma(v, gamma)
+}
+
+/*
+RPProveWithGamma : Range Proof Prove with the blinding factor
+Given a value v and the blinding factor gamma, provides a range proof that v is inside
+0 to 2^64-1 for the commitment v*G + gamma*H
+*/
+func RPProveWithGamma(v, gamma *big.Int) RangeProof {
 
 	rpresult := RangeProof{}
 
@@ -95,12 +121,10 @@ func RPProve(v *big.Int) RangeProof {
 		panic("Value is below range! Not proving")
 	}
 
-	if v.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(EC.V)), EC.N)) == 1 {
+	if v.Cmp(new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(EC.V)), EC.N)) != -1 {
 		panic("Value is above range! Not proving.")
 	}
 
-	gamma, err := rand.Int(rand.Reader, EC.N)
-	check(err)
 	comm := EC.G.Mult(v).Add(EC.H.Mult(gamma))
 	rpresult.Comm = comm
 
@@ -124,8 +148,7 @@ func RPProve(v *big.Int) RangeProof {
 	S := TwoVectorPCommitWithGens(EC.BPG, EC.BPH, sL, sR).Add(EC.H.Mult(rho))
 	rpresult.S = S
 
-	chal1s256 := sha256.Sum256([]byte(A.X.String() + A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	cy := ChallengeY([]ECPoint{comm}, A)
 
 	rpresult.Cy = cy
 
@@ -203,9 +226,9 @@ func RPProve(v *big.Int) RangeProof {
 
 	// thatPrime and that should be equal
 	if thatPrime.Cmp(that) != 0 {
-		fmt.Println("Proving -- Uh oh! Two diff ways to compute same value not working")
-		fmt.Printf("\tthatPrime = %s\n", thatPrime.String())
-		fmt.Printf("\tthat = %s \n", that.String())
+		logger.Debug("Proving -- Uh oh! Two diff ways to compute same value not working")
+		logger.Debug("thatPrime = %s", thatPrime.String())
+		logger.Debug("that = %s", that.String())
 	}
 
 	rpresult.Th = thatPrime
@@ -253,22 +276,21 @@ func RPProve(v *big.Int) RangeProof {
 
 func RPVerify(rp RangeProof) bool {
 	// verify the challenges
-	chal1s256 := sha256.Sum256([]byte(rp.A.X.String() + rp.A.Y.String()))
-	cy := new(big.Int).SetBytes(chal1s256[:])
+	cy := ChallengeY([]ECPoint{rp.Comm}, rp.A)
 	if cy.Cmp(rp.Cy) != 0 {
-		fmt.Println("RPVerify - Challenge Cy failing!")
+		logger.Debug("RPVerify - Challenge Cy failing!")
 		return false
 	}
 	chal2s256 := sha256.Sum256([]byte(rp.S.X.String() + rp.S.Y.String()))
 	cz := new(big.Int).SetBytes(chal2s256[:])
 	if cz.Cmp(rp.Cz) != 0 {
-		fmt.Println("RPVerify - Challenge Cz failing!")
+		logger.Debug("RPVerify - Challenge Cz failing!")
 		return false
 	}
 	chal3s256 := sha256.Sum256([]byte(rp.T1.X.String() + rp.T1.Y.String() + rp.T2.X.String() + rp.T2.Y.String()))
 	cx := new(big.Int).SetBytes(chal3s256[:])
 	if cx.Cmp(rp.Cx) != 0 {
-		fmt.Println("RPVerify - Challenge Cx failing!")
+		logger.Debug("RPVerify - Challenge Cx failing!")
 		return false
 	}
 
@@ -285,9 +307,9 @@ func RPVerify(rp RangeProof) bool {
 		rp.T2.Mult(new(big.Int).Mul(cx, cx)))
 
 	if !lhs.Equal(rhs) {
-		fmt.Println("RPVerify - Uh oh! Check line (63) of verification")
-		fmt.Println(rhs)
-		fmt.Println(lhs)
+		logger.Debug("RPVerify - Uh oh! Check line (63) of verification")
+		logger.Debug("%v", rhs)
+		logger.Debug("%v", lhs)
 		return false
 	}
 
@@ -319,7 +341,7 @@ func RPVerify(rp RangeProof) bool {
 	//fmt.Println(P)
 
 	if !InnerProductVerifyFast(rp.Th, P, EC.U, EC.BPG, HPrime, rp.IPP) {
-		fmt.Println("RPVerify - Uh oh! Check line (65) of verification!")
+		logger.Debug("RPVerify - Uh oh! Check line (65) of verification!")
 		return false
 	}
 
@@ -2,16 +2,15 @@ package bp
 
 import (
 	"crypto/rand"
-	"fmt"
 	"math/big"
 )
 
 // The length here always has to be a power of two
 func InnerProduct(a []*big.Int, b []*big.Int) *big.Int {
 	if len(a) != len(b) {
-		fmt.Println("InnerProduct: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(a): %d\n", len(a))
-		fmt.Printf("len(b): %d\n", len(b))
+		logger.Debug("InnerProduct: Uh oh! Arrays not of the same length")
+		logger.Debug("len(a): %d", len(a))
+		logger.Debug("len(b): %d", len(b))
 	}
 
 	c := big.NewInt(0)
@@ -26,9 +25,9 @@ func InnerProduct(a []*big.Int, b []*big.Int) *big.Int {
 
 func VectorAdd(v []*big.Int, w []*big.Int) []*big.Int {
 	if len(v) != len(w) {
-		fmt.Println("VectorAdd: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(v): %d\n", len(v))
-		fmt.Printf("len(w): %d\n", len(w))
+		logger.Debug("VectorAdd: Uh oh! Arrays not of the same length")
+		logger.Debug("len(v): %d", len(v))
+		logger.Debug("len(w): %d", len(w))
 	}
 	result := make([]*big.Int, len(v))
 
@@ -41,9 +40,9 @@ func VectorAdd(v []*big.Int, w []*big.Int) []*big.Int {
 
 func VectorHadamard(v, w []*big.Int) []*big.Int {
 	if len(v) != len(w) {
-		fmt.Println("VectorHadamard: Uh oh! Arrays not of the same length")
-		fmt.Printf("len(v): %d\n", len(w))
-		fmt.Printf("len(w): %d\n", len(v))
+		logger.Debug("VectorHadamard: Uh oh! Arrays not of the same length")
+		logger.Debug("len(v): %d", len(w))
+		logger.Debug("len(w): %d", len(v))
 	}
 
 	result := make([]*big.Int, len(v))
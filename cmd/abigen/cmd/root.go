@@ -0,0 +1,101 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/scdoproject/go-stem/accounts/abi/bind"
+	"github.com/spf13/cobra"
+)
+
+var (
+	abiFile  string // path to the contract ABI json file
+	binFile  string // path to the contract bytecode file, optional
+	typeName string // Go struct name for the binding
+	pkgName  string // Go package name to generate the binding into
+	outFile  string // output file for the generated binding
+)
+
+// rootCmd represents the base command called without any subcommands
+var rootCmd = &cobra.Command{
+	Use:   "abigen",
+	Short: "abigen command for generating Go bindings of Scdo contracts",
+	Long: `abigen reads the contract ABI and the optional bytecode, and generates a Go package
+with typed call, transact, deploy and event filter/watch methods.
+For example:
+	abigen --abi token.abi --bin token.bin --pkg token --type Token --out token.go`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := generate(); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().StringVarP(&abiFile, "abi", "a", "", "path to the contract ABI json file (required)")
+	rootCmd.Flags().StringVarP(&binFile, "bin", "b", "", "path to the contract bytecode file, deploy method is generated if specified")
+	rootCmd.Flags().StringVarP(&typeName, "type", "t", "", "Go struct name for the binding (default to package name)")
+	rootCmd.Flags().StringVarP(&pkgName, "pkg", "p", "", "Go package name to generate the binding into (required)")
+	rootCmd.Flags().StringVarP(&outFile, "out", "o", "", "output file for the generated binding (default to stdout)")
+}
+
+// generate generates the binding according to the command line flags
+func generate() error {
+	if abiFile == "" {
+		return errors.New("contract ABI file is required")
+	}
+
+	if pkgName == "" {
+		return errors.New("Go package name is required")
+	}
+
+	abiJSON, err := ioutil.ReadFile(abiFile)
+	if err != nil {
+		return fmt.Errorf("failed to read ABI file, %s", err)
+	}
+
+	var bytecode []byte
+	if binFile != "" {
+		if bytecode, err = ioutil.ReadFile(binFile); err != nil {
+			return fmt.Errorf("failed to read bytecode file, %s", err)
+		}
+	}
+
+	if typeName == "" {
+		typeName = pkgName
+	}
+
+	code, err := bind.Bind([]string{typeName}, []string{string(abiJSON)}, []string{string(bytecode)}, pkgName)
+	if err != nil {
+		return fmt.Errorf("failed to generate binding, %s", err)
+	}
+
+	if outFile == "" {
+		fmt.Print(code)
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(outFile), os.ModePerm); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(outFile, []byte(code), 0644)
+}
@@ -0,0 +1,12 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package main
+
+import "github.com/scdoproject/go-stem/cmd/abigen/cmd"
+
+func main() {
+	cmd.Execute()
+}
@@ -0,0 +1,52 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scdoproject/go-stem/contract/system"
+)
+
+func Test_readBTCHeaders(t *testing.T) {
+	genesis := system.BTCMainNetParams.CheckpointHeader
+	block1 := "010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"
+	genesisHash, err := system.NewBTCHashFromString("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+	assert.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "btc-headers")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	// hex encoded line by line, and starts from the relay tip
+	assert.NoError(t, ioutil.WriteFile(file.Name(), []byte(genesis+"\n"+block1+"\n"), 0600))
+	headers, err := readBTCHeaders(file.Name(), genesisHash)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(headers))
+	assert.Equal(t, "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048", headers[0].Hash().String())
+
+	// concatenated raw headers, and starts after the relay tip
+	raw, err := hex.DecodeString(block1)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(file.Name(), raw, 0600))
+	headers, err = readBTCHeaders(file.Name(), genesisHash)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(headers))
+
+	// up to date
+	headers, err = readBTCHeaders(file.Name(), headers[0].Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(headers))
+
+	// not extends the tip
+	_, err = readBTCHeaders(file.Name(), system.BTCHash{})
+	assert.Error(t, err)
+}
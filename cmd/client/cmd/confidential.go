@@ -0,0 +1,264 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/common/keystore"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/rpc"
+	"github.com/urfave/cli"
+)
+
+// createConfidentialAccount creates the confidential account of sender with its public key
+func createConfidentialAccount(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	key, txd, err := makeTransactionData(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload := crypto.FromECDSAPub(&key.PrivateKey.PublicKey)
+	tx, err := newSystemContractTx(client, key, txd, system.ConfidentialContractAddress, system.CmdConfidentialCreate, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// depositConfidential deposits the amount into the confidential balance of sender
+func depositConfidential(client *rpc.Client) (interface{}, interface{}, error) {
+	key, txd, err := makeTransactionData(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !txd.Amount.IsUint64() {
+		return nil, nil, fmt.Errorf("invalid amount value")
+	}
+
+	account, err := callConfidentialAccount(client, txd.From)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	note, err := system.NewConfidentialDeposit(key.PrivateKey, account, txd.Amount.Uint64())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := newSystemContractTx(client, key, txd, system.ConfidentialContractAddress, system.CmdConfidentialDeposit, note)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// transferConfidential transfers the hidden amount to the confidential account of receiver
+func transferConfidential(client *rpc.Client) (interface{}, interface{}, error) {
+	amount, err := confidentialAmount()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, txd, err := makeTransactionData(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account, err := callConfidentialAccount(client, txd.From)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipient, err := callConfidentialAccount(client, txd.To)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the confidential account of receiver, %s", err)
+	}
+
+	transfer, err := system.NewConfidentialTransfer(key.PrivateKey, account, txd.To, recipient, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sendConfidentialTx(client, key, txd, system.CmdConfidentialTransfer, transfer)
+}
+
+// withdrawConfidential withdraws the amount from the confidential balance of sender
+func withdrawConfidential(client *rpc.Client) (interface{}, interface{}, error) {
+	amount, err := confidentialAmount()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, txd, err := makeTransactionData(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account, err := callConfidentialAccount(client, txd.From)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	withdraw, err := system.NewConfidentialWithdraw(key.PrivateKey, account, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sendConfidentialTx(client, key, txd, system.CmdConfidentialWithdraw, withdraw)
+}
+
+// applyConfidential applies the pending transfers that could be opened by sender to its
+// confidential balance, and discards the others.
+func applyConfidential(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	key, txd, err := makeTransactionData(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account, err := callConfidentialAccount(client, txd.From)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apply, err := system.NewConfidentialApply(key.PrivateKey, account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sendConfidentialTx(client, key, txd, system.CmdConfidentialApply, apply)
+}
+
+// openConfidentialAccount prints the confidential balance and pending transfers of sender,
+// which are opened with the sender key.
+func openConfidentialAccount(c *cli.Context) error {
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	pass, err := common.GetPassword()
+	if err != nil {
+		return fmt.Errorf("failed to get password %s", err)
+	}
+
+	key, err := loadSenderKey(pass)
+	if err != nil {
+		return fmt.Errorf("invalid sender key file. it should be a private key: %s", err)
+	}
+
+	address := crypto.GetAddress(&key.PrivateKey.PublicKey)
+	account, err := callConfidentialAccount(client, *address)
+	if err != nil {
+		return err
+	}
+
+	opened, err := system.OpenConfidentialAccount(key.PrivateKey, account)
+	if err != nil {
+		return err
+	}
+
+	pending := make([]interface{}, len(opened.Pending))
+	for i, p := range opened.Pending {
+		pending[i] = "invalid note, discarded when applied"
+		if p != nil {
+			pending[i] = p.Amount
+		}
+	}
+
+	output := map[string]interface{}{
+		"Address": address.Hex(),
+		"Balance": opened.Balance.Amount,
+		"Pending": pending,
+	}
+
+	encoded, err := json.MarshalIndent(output, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(encoded))
+	return nil
+}
+
+// confidentialAmount returns the hidden amount of amount flag, and the tx amount is zero
+func confidentialAmount() (uint64, error) {
+	amount, err := strconv.ParseUint(amountValue, 10, 64)
+	if err != nil || amount == 0 {
+		return 0, fmt.Errorf("invalid amount value")
+	}
+
+	amountValue = "0"
+	return amount, nil
+}
+
+// sendConfidentialTx generates the confidential contract tx with the JSON encoded input
+func sendConfidentialTx(client *rpc.Client, key *keystore.Key, txd *types.TransactionData, cmd byte, input interface{}) (interface{}, interface{}, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txd.GasLimit = confidentialGasLimit(append([]byte{cmd}, payload...))
+	tx, err := newSystemContractTx(client, key, txd, system.ConfidentialContractAddress, cmd, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// confidentialGasLimit returns the gas limit flag, or the required gas of payload if higher
+func confidentialGasLimit(payload []byte) uint64 {
+	tx := &types.Transaction{Data: types.TransactionData{Payload: payload}}
+	required := tx.IntrinsicGas() + system.GetContractByAddress(system.ConfidentialContractAddress, common.ConfidentialTransferForkHeight).RequiredGas(payload)
+	if gasLimitValue > required {
+		return gasLimitValue
+	}
+
+	return required
+}
+
+// callConfidentialAccount gets the confidential account of address
+func callConfidentialAccount(client *rpc.Client, address common.Address) (*system.ConfidentialAccount, error) {
+	payload := append([]byte{system.CmdConfidentialGet}, address.Bytes()...)
+
+	var result map[string]interface{}
+	if err := client.Call(&result, "scdo_call", system.ConfidentialContractAddress.Hex(), hexutil.BytesToHex(payload), -1); err != nil {
+		return nil, fmt.Errorf("Failed to call confidential contract, %s", err)
+	}
+
+	if failed, _ := result["failed"].(bool); failed {
+		return nil, fmt.Errorf("Failed to get confidential account of %v, %v", address.Hex(), result["result"])
+	}
+
+	resultHex, _ := result["result"].(string)
+	data, err := hexutil.HexToBytes(resultHex)
+	if err != nil {
+		return nil, err
+	}
+
+	account := new(system.ConfidentialAccount)
+	if err = json.Unmarshal(data, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
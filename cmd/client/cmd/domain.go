@@ -6,13 +6,17 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
 	"github.com/scdoproject/go-stem/contract/system"
 	"github.com/scdoproject/go-stem/rpc"
 )
 
 // createDomainName create a domain name
 func createDomainName(client *rpc.Client) (interface{}, interface{}, error) {
-	amountValue = "0"
 	if err := system.ValidateDomainName([]byte(nameValue)); err != nil {
 		return nil, nil, err
 	}
@@ -27,16 +31,151 @@ func createDomainName(client *rpc.Client) (interface{}, interface{}, error) {
 
 // getDomainNameOwner get domain name owner
 func getDomainNameOwner(client *rpc.Client) (interface{}, interface{}, error) {
-	amountValue = "0"
+	return sendDomainNameTx(client, system.CmdGetDomainNameOwner, []byte(nameValue))
+}
 
+// renewDomainName renew the registration of domain name with the fee of amount
+func renewDomainName(client *rpc.Client) (interface{}, interface{}, error) {
 	if err := system.ValidateDomainName([]byte(nameValue)); err != nil {
 		return nil, nil, err
 	}
 
-	tx, err := sendSystemContractTx(client, system.DomainNameContractAddress, system.CmdGetDomainNameOwner, []byte(nameValue))
+	tx, err := sendSystemContractTx(client, system.DomainNameContractAddress, system.CmdRenewDomainName, []byte(nameValue))
 	if err != nil {
 		return nil, nil, err
 	}
 
 	return tx, tx, err
 }
+
+// transferDomainName transfer the domain name to the receiver
+func transferDomainName(client *rpc.Client) (interface{}, interface{}, error) {
+	return setDomainNameOwner(client, system.CmdTransferDomainName)
+}
+
+// setSubdomainOwner create the subdomain or change its owner
+func setSubdomainOwner(client *rpc.Client) (interface{}, interface{}, error) {
+	return setDomainNameOwner(client, system.CmdSetSubdomainOwner)
+}
+
+func setDomainNameOwner(client *rpc.Client, cmd byte) (interface{}, interface{}, error) {
+	owner, err := resolveAddress(client, toValue, shardValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sendDomainNameTx(client, cmd, system.DomainNameOwner{Name: nameValue, Owner: owner})
+}
+
+// setDomainAddress set the address that the domain name resolves to
+func setDomainAddress(client *rpc.Client) (interface{}, interface{}, error) {
+	address, err := common.HexToAddress(accountValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid account address, %s", err)
+	}
+
+	return sendDomainNameTx(client, system.CmdSetDomainAddress, system.DomainNameAddress{Name: nameValue, Address: address})
+}
+
+// setDomainABIHash set the contract ABI hash of domain name
+func setDomainABIHash(client *rpc.Client) (interface{}, interface{}, error) {
+	abiHash, err := common.HexToHash(hashValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid ABI hash, %s", err)
+	}
+
+	return sendDomainNameTx(client, system.CmdSetDomainABIHash, system.DomainNameABIHash{Name: nameValue, ABIHash: abiHash})
+}
+
+// setDomainText set the text record of domain name
+func setDomainText(client *rpc.Client) (interface{}, interface{}, error) {
+	return sendDomainNameTx(client, system.CmdSetDomainText, system.DomainNameText{Name: nameValue, Key: textKeyValue, Value: textValue})
+}
+
+// setReverseName set the domain name that the sender resolves to reversely
+func setReverseName(client *rpc.Client) (interface{}, interface{}, error) {
+	return sendDomainNameTx(client, system.CmdSetReverseName, []byte(nameValue))
+}
+
+// resolveDomainName resolve the domain name to address in the shard
+func resolveDomainName(client *rpc.Client) (interface{}, interface{}, error) {
+	return sendDomainNameTx(client, system.CmdResolveDomainName, system.DomainNameQuery{Name: nameValue, Shard: shardValue})
+}
+
+// reverseResolve resolve the account address to domain name
+func reverseResolve(client *rpc.Client) (interface{}, interface{}, error) {
+	address, err := common.HexToAddress(accountValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid account address, %s", err)
+	}
+
+	return sendDomainNameTx(client, system.CmdReverseResolve, address.Bytes())
+}
+
+// getDomainRecord get the registration and resolver records of domain name
+func getDomainRecord(client *rpc.Client) (interface{}, interface{}, error) {
+	return sendDomainNameTx(client, system.CmdGetDomainRecord, []byte(nameValue))
+}
+
+// sendDomainNameTx sends the domain name command without amount, and the parameter
+// is encoded in JSON unless raw bytes.
+func sendDomainNameTx(client *rpc.Client, cmd byte, param interface{}) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	payload, ok := param.([]byte)
+	if !ok {
+		var err error
+		if payload, err = json.Marshal(param); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tx, err := sendSystemContractTx(client, system.DomainNameContractAddress, cmd, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// resolveAddress parses the hex address, or resolves the domain name to the address
+// in the shard by calling the domain name system contract.
+func resolveAddress(client *rpc.Client, value string, shard uint) (common.Address, error) {
+	address, err := common.HexToAddress(value)
+	if err == nil {
+		return address, nil
+	}
+
+	if client == nil || system.ValidateFullDomainName([]byte(value)) != nil {
+		return common.EmptyAddress, fmt.Errorf("invalid address or domain name %v, %s", value, err)
+	}
+
+	param, err := json.Marshal(system.DomainNameQuery{Name: value, Shard: shard})
+	if err != nil {
+		return common.EmptyAddress, err
+	}
+
+	payload := append([]byte{system.CmdResolveDomainName}, param...)
+	var result map[string]interface{}
+	if err = client.Call(&result, "scdo_call", system.DomainNameContractAddress.Hex(), hexutil.BytesToHex(payload), -1); err != nil {
+		return common.EmptyAddress, fmt.Errorf("Failed to resolve domain name %v, %s", value, err)
+	}
+
+	if failed, _ := result["failed"].(bool); failed {
+		return common.EmptyAddress, fmt.Errorf("Failed to resolve domain name %v, %v", value, result["result"])
+	}
+
+	resolved, _ := result["result"].(string)
+	bytes, err := hexutil.HexToBytes(resolved)
+	if err != nil {
+		return common.EmptyAddress, fmt.Errorf("Failed to resolve domain name %v, %s", value, err)
+	}
+
+	address, err = common.NewAddress(bytes)
+	if err != nil {
+		return common.EmptyAddress, fmt.Errorf("Failed to resolve domain name %v, %s", value, err)
+	}
+
+	fmt.Printf("domain name %v resolved to %v\n", value, address.Hex())
+	return address, nil
+}
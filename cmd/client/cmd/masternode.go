@@ -0,0 +1,149 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+// depositMasternode deposits 20000 scdo and registers the sender as masternode with the endpoint
+func depositMasternode(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = system.MasternodeDepositAmount().String()
+
+	param, err := json.Marshal(system.MasternodeDeposit{Endpoint: endpointValue})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := sendSystemContractTx(client, system.MasternodeContractAddress, system.CmdDeposit, param)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// updateMasternode updates the endpoint of the sender masternode
+func updateMasternode(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	tx, err := sendSystemContractTx(client, system.MasternodeContractAddress, system.CmdUpdateEndpoint, []byte(endpointValue))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// quitMasternode quits the masternode, which should be the sender
+func quitMasternode(client *rpc.Client) (interface{}, interface{}, error) {
+	return sendMasternodeAddressTx(client, system.CmdQuit)
+}
+
+// recallMasternode returns the deposit to the masternode about one day after quit
+func recallMasternode(client *rpc.Client) (interface{}, interface{}, error) {
+	return sendMasternodeAddressTx(client, system.CmdRecall)
+}
+
+func sendMasternodeAddressTx(client *rpc.Client, cmd byte) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	node, err := common.HexToAddress(accountValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid masternode address, %s", err)
+	}
+
+	tx, err := sendSystemContractTx(client, system.MasternodeContractAddress, cmd, node.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
+
+// proveMasternodeLiveness signs the liveness proof of current epoch with the sender key,
+// which attests the first block of the epoch, and submits the proof.
+func proveMasternodeLiveness(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	key, txd, err := makeTransactionData(client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var height uint64
+	if err = client.Call(&height, "scdo_getBlockHeight"); err != nil {
+		return nil, nil, fmt.Errorf("Failed to get block height, %s", err)
+	}
+
+	// the proof is submitted in the next block
+	epoch := system.MasternodeEpoch(height + 1)
+	var block map[string]interface{}
+	if err = client.Call(&block, "scdo_getBlockByHeight", int64(epoch*system.MasternodeEpochLength), false); err != nil {
+		return nil, nil, fmt.Errorf("Failed to get the first block of epoch %v, %s", epoch, err)
+	}
+
+	hashHex, _ := block["hash"].(string)
+	blockHash, err := common.HexToHash(hashHex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid block hash, %s", err)
+	}
+
+	proof, err := system.NewMasternodeProof(key.PrivateKey, epoch, blockHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	param, err := json.Marshal(proof)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := newSystemContractTx(client, key, txd, system.MasternodeContractAddress, system.CmdProveLiveness, param)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output := map[string]interface{}{
+		"Tx":    *tx,
+		"Proof": proof,
+	}
+
+	return output, tx, err
+}
+
+// slashMasternode reports the evidence of masternode misbehaviour in the JSON file
+func slashMasternode(client *rpc.Client) (interface{}, interface{}, error) {
+	amountValue = "0"
+
+	buff, err := ioutil.ReadFile(evidenceFileValue)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to read evidence file, %s", err)
+	}
+
+	var evidence system.MasternodeEvidence
+	if err = json.Unmarshal(buff, &evidence); err != nil {
+		return nil, nil, fmt.Errorf("invalid evidence, %s", err)
+	}
+
+	param, err := json.Marshal(evidence)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := sendSystemContractTx(client, system.MasternodeContractAddress, system.CmdSlash, param)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tx, tx, err
+}
@@ -0,0 +1,39 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/rpc"
+	"github.com/urfave/cli"
+)
+
+// makeNewAccountArgs gets the args of personal_newAccount, the password is read from stdin
+func makeNewAccountArgs(c *cli.Context, client *rpc.Client) ([]interface{}, error) {
+	pass, err := common.SetPassword()
+	if err != nil {
+		return nil, fmt.Errorf("get password err %s", err)
+	}
+
+	return []interface{}{shardValue, pass}, nil
+}
+
+// makeUnlockAccountArgs gets the args of personal_unlockAccount, the password is read from stdin
+func makeUnlockAccountArgs(c *cli.Context, client *rpc.Client) ([]interface{}, error) {
+	account, err := common.HexToAddress(accountValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account: %s", err)
+	}
+
+	pass, err := common.GetPassword()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password %s", err)
+	}
+
+	return []interface{}{account, pass, unlockDurationValue}, nil
+}
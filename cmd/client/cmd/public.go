@@ -8,7 +8,6 @@ package cmd
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
 
@@ -165,54 +164,11 @@ func rpcActionSystemContract(namespace string, method string, resultHandler call
 	}
 }
 
-func checkTxCount(client *rpc.Client, txd *types.TransactionData) error {
-	curHeight, err := util.Height(client)
-	if err != nil {
-		return err
-	}
-	lastEpoch := curHeight / common.RelayRange
-	if lastEpoch <= 0 {
-		lastEpoch = 0
-	}
-	height := lastEpoch * common.RelayRange
-
-	lastCountFrom, err := util.GetAccountTxCount(client, txd.From, "", height)
-	if err != nil {
-		return err
-	}
-	curCountFrom, err := util.GetAccountTxCount(client, txd.From, "", curHeight)
-	if err != nil {
-		return err
-	}
-	if curCountFrom-lastCountFrom > common.TxLimitPerRelay {
-		return errors.New("FromAccount transaction count beyond limitation")
-	}
-
-	if txd.To != common.EmptyAddress {
-		lastCountTo, err := util.GetAccountTxCount(client, txd.To, "", height)
-		if err != nil {
-			return err
-		}
-		curCountTo, err := util.GetAccountTxCount(client, txd.To, "", curHeight)
-		if err != nil {
-			return err
-		}
-		if curCountTo-lastCountTo > common.TxLimitPerRelay {
-			return errors.New("ToAccount transaction count beyond limitation")
-		}
-	}
-	return nil
-}
-
 func makeTransaction(context *cli.Context, client *rpc.Client) ([]interface{}, error) {
 	key, txd, err := makeTransactionData(client)
 	if err != nil {
 		return nil, err
 	}
-	err = checkTxCount(client, txd)
-	if err != nil {
-		return nil, err
-	}
 	tx, err := util.GenerateTx(key.PrivateKey, txd.To, txd.Amount, txd.GasPrice, txd.GasLimit, txd.AccountNonce, txd.Payload)
 	if err != nil {
 		return nil, err
@@ -227,7 +183,7 @@ func makeTransactionData(client *rpc.Client) (*keystore.Key, *types.TransactionD
 		return nil, nil, fmt.Errorf("failed to get password %s", err)
 	}
 
-	key, err := keystore.GetKey(fromValue, pass)
+	key, err := loadSenderKey(pass)
 	if err != nil {
 		return nil, nil, fmt.Errorf("invalid sender key file. it should be a private key: %s", err)
 	}
@@ -240,6 +196,21 @@ func makeTransactionData(client *rpc.Client) (*keystore.Key, *types.TransactionD
 	return key, txd, nil
 }
 
+// loadSenderKey loads the key of sender from the key file, or from the keystore directory
+// if specified, where the sender is given by address.
+func loadSenderKey(pass string) (*keystore.Key, error) {
+	if len(keystoreValue) == 0 {
+		return keystore.GetKey(fromValue, pass)
+	}
+
+	address, err := common.HexToAddress(fromValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.NewManager(keystoreValue).GetKey(address, pass)
+}
+
 func onTxAdded(inputs []interface{}, result interface{}) error {
 	if !result.(bool) {
 		fmt.Println("failed to send transaction")
@@ -23,7 +23,7 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		{
 			Name:   "sendtx",
 			Usage:  "send transaction to node",
-			Flags:  rpcFlags(fromFlag, toFlag, amountFlag, priceFlag, gasLimitFlag, payloadFlag, nonceFlag),
+			Flags:  rpcFlags(fromFlag, keystoreFlag, toFlag, amountFlag, priceFlag, gasLimitFlag, payloadFlag, nonceFlag),
 			Action: rpcActionEx("scdo", "addTx", makeTransaction, onTxAdded),
 		},
 		{
@@ -92,6 +92,18 @@ func AddCommands(app *cli.App, isFullNode bool) {
 			Flags:  rpcFlags(),
 			Action: rpcAction("debug", "getPendingTransactions"),
 		},
+		{
+			Name:   "setloglevel",
+			Usage:  "set the log level of module at runtime",
+			Flags:  rpcFlags(logModuleFlag, logLevelFlag),
+			Action: rpcAction("debug", "setLogLevel"),
+		},
+		{
+			Name:   "getloglevels",
+			Usage:  "get the log levels of all modules",
+			Flags:  rpcFlags(),
+			Action: rpcAction("debug", "getLogLevels"),
+		},
 		{
 			Name:  "getshardnum",
 			Usage: "get account shard number",
@@ -125,6 +137,35 @@ func AddCommands(app *cli.App, isFullNode bool) {
 			},
 			Action: SignTxAction,
 		},
+		{
+			Name:  "mnemonic",
+			Usage: "generate mnemonic to derive keys",
+			Flags: []cli.Flag{
+				entropyBitsFlag,
+			},
+			Action: GenerateMnemonicAction,
+		},
+		{
+			Name:  "hdkey",
+			Usage: "derive key from mnemonic in the shard, and store it into keystore directory if specified",
+			Flags: []cli.Flag{
+				mnemonicFlag,
+				shardFlag,
+				hdAccountFlag,
+				hdIndexFlag,
+				hdPathFlag,
+				keystoreFlag,
+			},
+			Action: DeriveHDKeyAction,
+		},
+		{
+			Name:  "listaccount",
+			Usage: "list accounts in keystore directory",
+			Flags: []cli.Flag{
+				keystoreFlag,
+			},
+			Action: ListAccountAction,
+		},
 		{
 			Name:  "key",
 			Usage: "generate key with or without shard number",
@@ -209,16 +250,254 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		Subcommands: []cli.Command{
 			{
 				Name:   "register",
-				Usage:  "register a domain name",
-				Flags:  rpcFlags(fromFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Usage:  "register a domain name, the amount is the fee of registration periods",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, amountFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
 				Action: rpcActionSystemContract("domain", "create", handleCallResult),
 			},
 			{
 				Name:   "owner",
 				Usage:  "get the domain name owner",
-				Flags:  rpcFlags(fromFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
 				Action: rpcActionSystemContract("domain", "getOwner", handleCallResult),
 			},
+			{
+				Name:   "renew",
+				Usage:  "renew a domain name, the amount is the fee of registration periods",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, amountFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "renew", handleCallResult),
+			},
+			{
+				Name:   "transfer",
+				Usage:  "transfer a domain name to the new owner",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, toFlag, shardFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "transfer", handleCallResult),
+			},
+			{
+				Name:   "subdomain",
+				Usage:  "create a subdomain, e.g. pay.alice, or change its owner by the parent domain owner",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, toFlag, shardFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "subdomain", handleCallResult),
+			},
+			{
+				Name:   "setaddr",
+				Usage:  "set the account address that the domain name resolves to in the shard of account",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, accountFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "setAddress", handleCallResult),
+			},
+			{
+				Name:   "setabi",
+				Usage:  "set the contract ABI hash of domain name",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, hashFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "setABIHash", handleCallResult),
+			},
+			{
+				Name:   "settext",
+				Usage:  "set the text record of domain name",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, textKeyFlag, textFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "setText", handleCallResult),
+			},
+			{
+				Name:   "setreverse",
+				Usage:  "set the domain name that the sender resolves to reversely, which should resolve to the sender",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "setReverse", handleCallResult),
+			},
+			{
+				Name:   "resolve",
+				Usage:  "resolve the domain name to the address in the shard",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, shardFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "resolve", handleCallResult),
+			},
+			{
+				Name:   "reverse",
+				Usage:  "resolve the account address to the domain name",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, accountFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "reverse", handleCallResult),
+			},
+			{
+				Name:   "record",
+				Usage:  "get the registration and resolver records of domain name",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nameFlag, nonceFlag),
+				Action: rpcActionSystemContract("domain", "getRecord", handleCallResult),
+			},
+		},
+	}
+
+	masternodeCommands := cli.Command{
+		Name:  "masternode",
+		Usage: "system masternode commands",
+		Subcommands: []cli.Command{
+			{
+				Name:   "deposit",
+				Usage:  "deposit 20000 scdo and register as masternode with the network endpoint",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, endpointFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("masternode", "deposit", handleCallResult),
+			},
+			{
+				Name:   "update",
+				Usage:  "update the network endpoint of masternode",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, endpointFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("masternode", "update", handleCallResult),
+			},
+			{
+				Name:   "quit",
+				Usage:  "quit the masternode, which should be the sender",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, accountFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("masternode", "quit", handleCallResult),
+			},
+			{
+				Name:   "recall",
+				Usage:  "return the deposit to the masternode about one day after quit",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, accountFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("masternode", "recall", handleCallResult),
+			},
+			{
+				Name:   "prove",
+				Usage:  "sign and submit the liveness proof of current epoch with the masternode key",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("masternode", "prove", handleCallResult),
+			},
+			{
+				Name:   "slash",
+				Usage:  "slash the deposit of masternode with the evidence of conflicting liveness proofs",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, evidenceFileFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("masternode", "slash", handleCallResult),
+			},
+			{
+				Name:   "list",
+				Usage:  "list the active masternodes with their endpoints",
+				Flags:  rpcFlags(hashFlag, heightFlag),
+				Action: rpcAction("scdo", "getMasternodes"),
+			},
+			{
+				Name:   "info",
+				Usage:  "get the masternode info",
+				Flags:  rpcFlags(accountFlag, hashFlag, heightFlag),
+				Action: rpcAction("scdo", "getMasternode"),
+			},
+		},
+	}
+
+	btcRelayCommands := cli.Command{
+		Name:  "btcrelay",
+		Usage: "system btc relay commands",
+		Subcommands: []cli.Command{
+			{
+				Name:   "relay",
+				Usage:  "relay the btc block headers after the relay tip from the header file or bitcoind",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, btcHeaderFileFlag, btcRPCFlag, btcMaxHeadersFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: relayBTCHeaders,
+			},
+			{
+				Name:   "verify",
+				Usage:  "verify the btc tx with the SPV proof, and the fee of amount is paid to the relayer",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, amountFlag, btcProofFileFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("btcrelay", "verify", handleCallResult),
+			},
+			{
+				Name:   "best",
+				Usage:  "get the tip of btc main chain in the relay",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("btcrelay", "best", handleCallResult),
+			},
+			{
+				Name:   "header",
+				Usage:  "get the btc block header in the relay by hash",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, hashFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("btcrelay", "header", handleCallResult),
+			},
+		},
+	}
+
+	confidentialCommands := cli.Command{
+		Name:  "confidential",
+		Usage: "system confidential transfer commands, whose amounts are hidden in commitments",
+		Subcommands: []cli.Command{
+			{
+				Name:   "create",
+				Usage:  "create the confidential account of sender",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("confidential", "create", handleCallResult),
+			},
+			{
+				Name:   "deposit",
+				Usage:  "deposit the amount into the confidential balance of sender",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, amountFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("confidential", "deposit", handleCallResult),
+			},
+			{
+				Name:   "transfer",
+				Usage:  "transfer the hidden amount to the confidential account of receiver",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, toFlag, amountFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("confidential", "transfer", handleCallResult),
+			},
+			{
+				Name:   "withdraw",
+				Usage:  "withdraw the amount from the confidential balance of sender",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, amountFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("confidential", "withdraw", handleCallResult),
+			},
+			{
+				Name:   "apply",
+				Usage:  "apply the received transfers to the confidential balance of sender",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: rpcActionSystemContract("confidential", "apply", handleCallResult),
+			},
+			{
+				Name:   "open",
+				Usage:  "open the confidential balance and received transfers of sender with its key",
+				Flags:  rpcFlags(fromFlag, keystoreFlag),
+				Action: openConfidentialAccount,
+			},
+		},
+	}
+
+	swapCommands := cli.Command{
+		Name:  "swap",
+		Usage: "atomic swap commands between Scdo HTLC and btc contract",
+		Subcommands: []cli.Command{
+			{
+				Name:   "initiate",
+				Usage:  "generate the secret and lock the fund of initiator, the HTLC of Scdo or the contract script of btc",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, swapChainFlag, toFlag, amountFlag, timeLockFlag, btcNetFlag, btcRecipientFlag, btcRefundAddrFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: initiateSwap,
+			},
+			{
+				Name:   "participate",
+				Usage:  "lock the fund of participant with the secret hash of initiator and the shorter lock time",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, swapChainFlag, secretHashFlag, toFlag, amountFlag, timeLockFlag, swapInitiatorLockFlag, btcNetFlag, btcRecipientFlag, btcRefundAddrFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: participateSwap,
+			},
+			{
+				Name:   "audit",
+				Usage:  "show the contract of counterparty, and check it with the secret hash and recipient",
+				Flags:  rpcFlags(swapChainFlag, hashFlag, secretHashFlag, toFlag, btcNetFlag, btcScriptFlag, btcContractTxFlag, btcRecipientFlag),
+				Action: auditSwap,
+			},
+			{
+				Name:   "redeem",
+				Usage:  "redeem the contract of counterparty with the secret",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, swapChainFlag, hashFlag, preimageFlag, btcNetFlag, btcScriptFlag, btcContractTxFlag, btcKeyFlag, btcFeeFlag, btcRPCFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: redeemSwap,
+			},
+			{
+				Name:   "refund",
+				Usage:  "refund the own contract after the lock time",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, swapChainFlag, hashFlag, btcNetFlag, btcScriptFlag, btcContractTxFlag, btcKeyFlag, btcFeeFlag, btcRPCFlag, priceFlag, gasLimitFlag, nonceFlag),
+				Action: refundSwap,
+			},
+			{
+				Name:   "extract",
+				Usage:  "extract the secret from the btc redeem tx or the withdrawn Scdo HTLC",
+				Flags:  rpcFlags(swapChainFlag, hashFlag, btcScriptFlag, btcRedeemTxFlag),
+				Action: extractSwapSecret,
+			},
+			{
+				Name:   "watch",
+				Usage:  "watch the Scdo HTLC for the secret to redeem the btc contract, and refund the HTLC after the lock time",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, hashFlag, swapIntervalFlag, btcNetFlag, btcScriptFlag, btcContractTxFlag, btcKeyFlag, btcFeeFlag, btcRPCFlag, priceFlag, gasLimitFlag),
+				Action: watchSwap,
+			},
 		},
 	}
 
@@ -250,6 +529,30 @@ func AddCommands(app *cli.App, isFullNode bool) {
 				Flags:  []cli.Flag{nameFlag, subChainJSONFileFlag},
 				Action: generateTemplate,
 			},
+			{
+				Name:   "relay",
+				Usage:  "submit the sub chain checkpoints to the root chain contract every relay range blocks",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, subChainRPCFlag, rootChainFlag, relayRangeFlag, swapIntervalFlag, priceFlag, gasLimitFlag),
+				Action: relayCheckpoints,
+			},
+			{
+				Name:   "exit",
+				Usage:  "start to exit the sub chain balance with the merkle proof at the checkpoint",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, subChainRPCFlag, rootChainFlag, relayRangeFlag, checkpointFlag, priceFlag, gasLimitFlag),
+				Action: startExit,
+			},
+			{
+				Name:   "challenge",
+				Usage:  "challenge the user exit with the merkle proof at a later checkpoint",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, subChainRPCFlag, rootChainFlag, relayRangeFlag, exitIDFlag, priceFlag, gasLimitFlag),
+				Action: challengeExit,
+			},
+			{
+				Name:   "finalize",
+				Usage:  "finalize the user exits out of the challenge period",
+				Flags:  rpcFlags(fromFlag, keystoreFlag, rootChainFlag, priceFlag, gasLimitFlag),
+				Action: finalizeExits,
+			},
 		},
 	}
 
@@ -290,6 +593,37 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		},
 	}
 
+	personalCommands := cli.Command{
+		Name:  "personal",
+		Usage: "keystore account commands of node",
+		Subcommands: []cli.Command{
+			{
+				Name:   "listaccounts",
+				Usage:  "list accounts in keystore directory of node",
+				Flags:  rpcFlags(),
+				Action: rpcAction("personal", "listAccounts"),
+			},
+			{
+				Name:   "newaccount",
+				Usage:  "create account in keystore directory of node",
+				Flags:  rpcFlags(shardFlag),
+				Action: rpcActionEx("personal", "newAccount", makeNewAccountArgs, handleCallResult),
+			},
+			{
+				Name:   "unlock",
+				Usage:  "unlock account to sign transactions by node",
+				Flags:  rpcFlags(accountFlag, unlockDurationFlag),
+				Action: rpcActionEx("personal", "unlockAccount", makeUnlockAccountArgs, handleCallResult),
+			},
+			{
+				Name:   "lock",
+				Usage:  "lock account",
+				Flags:  rpcFlags(accountFlag),
+				Action: rpcAction("personal", "lockAccount"),
+			},
+		},
+	}
+
 	minerCommands := cli.Command{
 		Name:  "miner",
 		Usage: "miner commands",
@@ -395,7 +729,12 @@ func AddCommands(app *cli.App, isFullNode bool) {
 		baseCommands = append(baseCommands,
 			htlcCommands,
 			domainCommands,
+			masternodeCommands,
+			btcRelayCommands,
+			confidentialCommands,
+			swapCommands,
 			subChainCommands,
+			personalCommands,
 			minerCommands)
 	}
 
@@ -0,0 +1,252 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/subchain"
+	"github.com/scdoproject/go-stem/rpc"
+	"github.com/urfave/cli"
+)
+
+// dialRootChain connects to the main chain node with the sender key
+func dialRootChain() (*subchain.RootChain, error) {
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := common.HexToAddress(rootChainValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root chain contract address, %s", err)
+	}
+
+	pass, err := common.GetPassword()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password %s", err)
+	}
+
+	key, err := loadSenderKey(pass)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender key file. it should be a private key: %s", err)
+	}
+
+	rootchain := subchain.NewRootChain(client, contract, key.PrivateKey)
+	if len(priceValue) > 0 {
+		price, ok := new(big.Int).SetString(priceValue, 10)
+		if !ok || price.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid gas price %v", priceValue)
+		}
+		rootchain.GasPrice = price
+	}
+
+	if gasLimitValue > 0 {
+		rootchain.GasLimit = gasLimitValue
+	}
+
+	return rootchain, nil
+}
+
+// dialSubChain connects to the subchain node, and uses the relay range of subchain if not specified
+func dialSubChain() (subchain.ChainReader, error) {
+	client, err := rpc.DialTCP(context.Background(), subChainRPCValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the subchain node, %s", err)
+	}
+
+	reader := subchain.NewRPCChainReader(client)
+	if relayRangeValue == 0 {
+		if relayRangeValue, err = reader.RelayRange(); err != nil {
+			return nil, err
+		}
+	}
+
+	return reader, nil
+}
+
+// relayCheckpoints submits the subchain checkpoints to the root chain until interrupted
+func relayCheckpoints(c *cli.Context) error {
+	rootchain, err := dialRootChain()
+	if err != nil {
+		return err
+	}
+
+	reader, err := dialSubChain()
+	if err != nil {
+		return err
+	}
+
+	config := subchain.DefaultRelayerConfig
+	config.Range = relayRangeValue
+	config.Interval = time.Duration(swapIntervalValue) * time.Second
+
+	relayer, err := subchain.NewRelayer(config, reader, rootchain)
+	if err != nil {
+		return err
+	}
+
+	relayer.Start()
+	fmt.Printf("relaying checkpoints every %d blocks, press Ctrl+C to stop\n", config.Range)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	<-sig
+
+	relayer.Stop()
+	return nil
+}
+
+// buildExitProof builds the proof of account at the checkpoint height, and checks it with
+// the exit root accepted by the root chain.
+func buildExitProof(rootchain *subchain.RootChain, reader subchain.ChainReader, height uint64, account common.Address) (*subchain.ExitProof, error) {
+	if height < relayRangeValue {
+		return nil, fmt.Errorf("invalid checkpoint height %d", height)
+	}
+
+	info, err := rootchain.Checkpoint(height)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Timestamp.Sign() == 0 {
+		return nil, fmt.Errorf("the checkpoint %d is not accepted by the root chain", height)
+	}
+
+	checkpoint, tree, err := subchain.BuildCheckpoint(reader, height-relayRangeValue, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint.ExitRoot != common.Hash(info.ExitRoot) {
+		return nil, fmt.Errorf("the exit root %v mismatch with the root chain %v", checkpoint.ExitRoot.Hex(), common.Hash(info.ExitRoot).Hex())
+	}
+
+	proof, err := tree.Proof(account)
+	if err != nil {
+		return nil, err
+	}
+
+	proof.Height = height
+	return proof, nil
+}
+
+// startExit starts to exit the balance of sender at the checkpoint
+func startExit(c *cli.Context) error {
+	rootchain, err := dialRootChain()
+	if err != nil {
+		return err
+	}
+
+	reader, err := dialSubChain()
+	if err != nil {
+		return err
+	}
+
+	height := checkpointValue
+	if height == 0 {
+		if height, err = rootchain.CurrentCheckpointHeight(); err != nil {
+			return err
+		}
+	}
+
+	proof, err := buildExitProof(rootchain, reader, height, rootchain.From())
+	if err == subchain.ErrAccountNotFound {
+		return fmt.Errorf("the account is not changed in the checkpoint %d, exit at the checkpoint it was last changed", height)
+	} else if err != nil {
+		return err
+	}
+
+	bond, err := rootchain.ExitBond()
+	if err != nil {
+		return err
+	}
+
+	tx, err := rootchain.StartExit(proof, bond)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("exit balance %v with nonce %d at checkpoint %d\n", proof.Balance, proof.Nonce, height)
+	fmt.Println("tx hash:", tx.Hash.Hex())
+	return nil
+}
+
+// challengeExit challenges the exit with the proof that the user has spent at a later checkpoint
+func challengeExit(c *cli.Context) error {
+	rootchain, err := dialRootChain()
+	if err != nil {
+		return err
+	}
+
+	reader, err := dialSubChain()
+	if err != nil {
+		return err
+	}
+
+	exit, err := rootchain.Exit(exitIDValue)
+	if err != nil {
+		return err
+	}
+
+	if exit.User.IsEmpty() {
+		return fmt.Errorf("the exit %d is not found or finalized", exitIDValue)
+	}
+
+	current, err := rootchain.CurrentCheckpointHeight()
+	if err != nil {
+		return err
+	}
+
+	// find the latest checkpoint where the user has spent after the exit
+	for height := current; height > exit.Height.Uint64(); height -= relayRangeValue {
+		proof, err := buildExitProof(rootchain, reader, height, exit.User)
+		if err == subchain.ErrAccountNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if proof.Nonce <= exit.Nonce.Uint64() {
+			break
+		}
+
+		tx, err := rootchain.ChallengeExit(exitIDValue, proof)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("challenge exit %d with nonce %d at checkpoint %d\n", exitIDValue, proof.Nonce, height)
+		fmt.Println("tx hash:", tx.Hash.Hex())
+		return nil
+	}
+
+	return errors.New("no later checkpoint found to challenge the exit")
+}
+
+// finalizeExits finalizes the exits out of the challenge period
+func finalizeExits(c *cli.Context) error {
+	rootchain, err := dialRootChain()
+	if err != nil {
+		return err
+	}
+
+	tx, err := rootchain.FinalizeExits()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("tx hash:", tx.Hash.Hex())
+	return nil
+}
@@ -298,6 +298,8 @@ func getConfigFromSubChain(networkID string, subChainInfo *system.SubChainInfo)
 		Difficult:       int64(subChainInfo.GenesisDifficulty),
 		ShardNumber:     shardValue,
 		CreateTimestamp: subChainInfo.CreateTimestamp,
+		TxLimitPerRelay: subChainInfo.TxLimitPerRelay,
+		RelayRange:      subChainInfo.RelayRange,
 	}
 
 	return config, nil
@@ -317,6 +319,8 @@ func generateTemplate(c *cli.Context) error {
 		TokenAmount:       0,
 		GenesisDifficulty: 8000000,
 		GenesisAccounts:   map[common.Address]*big.Int{},
+		TxLimitPerRelay:   common.DefaultTxLimitPerRelay,
+		RelayRange:        common.RelayRange,
 	}
 
 	byteSubChainInfo, err := json.MarshalIndent(subChainInfo, "", "\t")
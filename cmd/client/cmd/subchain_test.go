@@ -54,7 +54,7 @@ func Test_getConfigFromSubChain(t *testing.T) {
 	assert.Equalf(t, 5, reflectP2p.NumField(), errFormat, "p2p.Config")
 
 	reflectLog := reflect.TypeOf(config.LogConfig)
-	assert.Equalf(t, 3, reflectLog.NumField(), errFormat, "comm.LogConfig")
+	assert.Equalf(t, 5, reflectLog.NumField(), errFormat, "comm.LogConfig")
 
 	reflectHTTPServer := reflect.TypeOf(config.HTTPServer)
 	assert.Equalf(t, 3, reflectHTTPServer.NumField(), errFormat, "node.HTTPServer")
@@ -0,0 +1,624 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/common/keystore"
+	"github.com/scdoproject/go-stem/contract/swap"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/rpc"
+	"github.com/urfave/cli"
+)
+
+const (
+	swapChainScdo = "scdo"
+	swapChainBTC  = "btc"
+)
+
+// initiateSwap generates the secret and locks the fund with the initiator lock time
+func initiateSwap(c *cli.Context) error {
+	secret, secretHash, err := swap.NewSecret()
+	if err != nil {
+		return err
+	}
+
+	lockTime := swapLockTime(swap.InitiatorLockDuration)
+	if err = lockSwapContract(secretHash, lockTime); err != nil {
+		return err
+	}
+
+	fmt.Println("secret:", hexutil.BytesToHex(secret))
+	fmt.Println("secret hash:", hexutil.BytesToHex(secretHash))
+	fmt.Println("keep the secret private until redeeming the participant contract")
+	return nil
+}
+
+// participateSwap locks the fund with the secret hash of initiator and the shorter lock time
+func participateSwap(c *cli.Context) error {
+	secretHash, err := hexutil.HexToBytes(secretHashValue)
+	if err != nil || len(secretHash) != swap.SecretSize {
+		return errors.New("invalid secret hash, it should be 32 bytes hex")
+	}
+
+	lockTime := swapLockTime(swap.ParticipantLockDuration)
+	if swapInitiatorLockValue > 0 {
+		if err = swap.ValidateLockTimes(swapInitiatorLockValue, lockTime, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return lockSwapContract(secretHash, lockTime)
+}
+
+// swapLockTime returns the lock time flag, or the default duration after now
+func swapLockTime(duration time.Duration) int64 {
+	if timeLockValue > 0 {
+		return timeLockValue
+	}
+
+	return time.Now().Add(duration).Unix()
+}
+
+// lockSwapContract creates the HTLC of Scdo, or the contract script of btc to be funded
+func lockSwapContract(secretHash []byte, lockTime int64) error {
+	if swapChainValue == swapChainBTC {
+		net, err := swap.BTCNetByName(btcNetValue)
+		if err != nil {
+			return err
+		}
+
+		recipient, err := net.DecodePubKeyHashAddress(btcRecipientValue)
+		if err != nil {
+			return fmt.Errorf("invalid recipient, %s", err)
+		}
+
+		refund, err := net.DecodePubKeyHashAddress(btcRefundAddrValue)
+		if err != nil {
+			return fmt.Errorf("invalid refund address, %s", err)
+		}
+
+		contract := &swap.BTCContract{SecretHash: secretHash, Recipient: recipient, Refund: refund, LockTime: lockTime}
+		script, err := contract.Script()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("contract script:", hex.EncodeToString(script))
+		fmt.Println("contract address:", net.ScriptAddress(script))
+		fmt.Println("lock time:", lockTime, time.Unix(lockTime, 0))
+		fmt.Println("fund the contract address with the swap amount, and share the script and funding tx")
+		return nil
+	}
+
+	if swapChainValue != swapChainScdo {
+		return fmt.Errorf("unknown chain %v, it should be %v or %v", swapChainValue, swapChainScdo, swapChainBTC)
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	to, err := common.HexToAddress(toValue)
+	if err != nil {
+		return fmt.Errorf("invalid recipient, %s", err)
+	}
+
+	payload, err := json.Marshal(system.HashTimeLock{HashLock: secretHash, TimeLock: lockTime, To: to})
+	if err != nil {
+		return err
+	}
+
+	tx, err := sendSystemContractTx(client, system.HashTimeLockContractAddress, system.CmdNewContract, payload)
+	if err != nil {
+		return err
+	}
+
+	if err = sendTx(client, tx); err != nil {
+		return err
+	}
+
+	fmt.Println("HTLC hash:", tx.Hash.Hex())
+	fmt.Println("lock time:", lockTime, time.Unix(lockTime, 0))
+	return nil
+}
+
+// auditSwap prints the contract of counterparty, and checks it with the secret hash and
+// recipient if specified.
+func auditSwap(c *cli.Context) error {
+	var secretHash []byte
+	if len(secretHashValue) > 0 {
+		var err error
+		if secretHash, err = hexutil.HexToBytes(secretHashValue); err != nil {
+			return fmt.Errorf("invalid secret hash, %s", err)
+		}
+	}
+
+	if swapChainValue == swapChainBTC {
+		return auditBTCContract(secretHash)
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	htlcHash, err := common.HexToHash(hashValue)
+	if err != nil {
+		return fmt.Errorf("invalid HTLC hash, %s", err)
+	}
+
+	h, err := getScdoHTLC(client, htlcHash)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(h, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+
+	if secretHash != nil {
+		to, err := common.HexToAddress(toValue)
+		if err != nil {
+			return fmt.Errorf("invalid recipient, %s", err)
+		}
+
+		if err = h.Audit(secretHash, to, time.Now(), swap.MinRedeemWindow); err != nil {
+			return err
+		}
+
+		fmt.Println("the HTLC is valid for the swap")
+	}
+
+	return nil
+}
+
+func auditBTCContract(secretHash []byte) error {
+	net, err := swap.BTCNetByName(btcNetValue)
+	if err != nil {
+		return err
+	}
+
+	script, contract, err := decodeBTCContractScript()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("contract address:", net.ScriptAddress(script))
+	fmt.Println("recipient:", net.PubKeyHashAddress(contract.Recipient))
+	fmt.Println("refund address:", net.PubKeyHashAddress(contract.Refund))
+	fmt.Println("secret hash:", hexutil.BytesToHex(contract.SecretHash))
+	fmt.Println("lock time:", contract.LockTime, time.Unix(contract.LockTime, 0))
+
+	if len(btcContractTxValue) > 0 {
+		contractTx, err := decodeBTCTxFlag(btcContractTxValue)
+		if err != nil {
+			return err
+		}
+
+		index, err := contractTx.ContractOutput(script)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("contract output: %v:%d, amount %d satoshi\n", contractTx.TxID(), index, contractTx.TxOut[index].Value)
+	}
+
+	if secretHash != nil {
+		recipient, err := net.DecodePubKeyHashAddress(btcRecipientValue)
+		if err != nil {
+			return fmt.Errorf("invalid recipient, %s", err)
+		}
+
+		if err = contract.Audit(secretHash, recipient, time.Now(), swap.MinRedeemWindow); err != nil {
+			return err
+		}
+
+		fmt.Println("the contract is valid for the swap")
+	}
+
+	return nil
+}
+
+// redeemSwap redeems the contract of counterparty with the secret
+func redeemSwap(c *cli.Context) error {
+	secret, err := hexutil.HexToBytes(preimageValue)
+	if err != nil {
+		return fmt.Errorf("invalid secret, %s", err)
+	}
+
+	if swapChainValue == swapChainBTC {
+		return spendBTCContract(secret)
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	htlcHash, err := common.HexToHash(hashValue)
+	if err != nil {
+		return fmt.Errorf("invalid HTLC hash, %s", err)
+	}
+
+	payload, err := json.Marshal(system.Withdrawing{Hash: htlcHash, Preimage: secret})
+	if err != nil {
+		return err
+	}
+
+	amountValue = "0"
+	return sendSwapTx(client, system.CmdWithdraw, payload)
+}
+
+// refundSwap refunds the own contract after the lock time
+func refundSwap(c *cli.Context) error {
+	if swapChainValue == swapChainBTC {
+		return spendBTCContract(nil)
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	htlcHash, err := common.HexToHash(hashValue)
+	if err != nil {
+		return fmt.Errorf("invalid HTLC hash, %s", err)
+	}
+
+	amountValue = "0"
+	return sendSwapTx(client, system.CmdRefund, htlcHash.Bytes())
+}
+
+// sendSwapTx sends the tx of HTLC command signed by the sender
+func sendSwapTx(client *rpc.Client, cmd byte, payload []byte) error {
+	tx, err := sendSystemContractTx(client, system.HashTimeLockContractAddress, cmd, payload)
+	if err != nil {
+		return err
+	}
+
+	if err = sendTx(client, tx); err != nil {
+		return err
+	}
+
+	fmt.Println("tx hash:", tx.Hash.Hex())
+	return nil
+}
+
+// spendBTCContract creates the signed tx that redeems the contract with secret, or refunds
+// it if the secret is nil, and broadcasts the tx if bitcoind RPC is specified.
+func spendBTCContract(secret []byte) error {
+	net, err := swap.BTCNetByName(btcNetValue)
+	if err != nil {
+		return err
+	}
+
+	key, err := net.DecodeWIF(btcKeyValue)
+	if err != nil {
+		return err
+	}
+
+	script, _, err := decodeBTCContractScript()
+	if err != nil {
+		return err
+	}
+
+	contractTx, err := decodeBTCTxFlag(btcContractTxValue)
+	if err != nil {
+		return err
+	}
+
+	var tx *swap.BTCTx
+	if secret != nil {
+		tx, err = swap.NewBTCRedeemTx(contractTx, script, secret, key, btcFeeValue)
+	} else {
+		tx, err = swap.NewBTCRefundTx(contractTx, script, key, btcFeeValue)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	raw := hex.EncodeToString(tx.Encode())
+	fmt.Println("tx id:", tx.TxID())
+	fmt.Println("raw tx:", raw)
+
+	if len(btcRPCValue) > 0 {
+		var txid string
+		if err = newBTCRPCClient(btcRPCValue).call(&txid, "sendrawtransaction", raw); err != nil {
+			return err
+		}
+
+		fmt.Println("the tx is sent to bitcoind")
+	}
+
+	return nil
+}
+
+// extractSwapSecret extracts the secret from the redeem tx of btc contract, or from the
+// withdrawn HTLC of Scdo.
+func extractSwapSecret(c *cli.Context) error {
+	if swapChainValue == swapChainBTC {
+		script, _, err := decodeBTCContractScript()
+		if err != nil {
+			return err
+		}
+
+		redeemTx, err := decodeBTCTxFlag(btcRedeemTxValue)
+		if err != nil {
+			return err
+		}
+
+		secret, err := swap.ExtractBTCSecret(redeemTx, script)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("secret:", hexutil.BytesToHex(secret))
+		return nil
+	}
+
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	htlcHash, err := common.HexToHash(hashValue)
+	if err != nil {
+		return fmt.Errorf("invalid HTLC hash, %s", err)
+	}
+
+	h, err := getScdoHTLC(client, htlcHash)
+	if err != nil {
+		return err
+	}
+
+	if !h.Withdrawed {
+		return swap.ErrSecretNotFound
+	}
+
+	fmt.Println("secret:", hexutil.BytesToHex(h.Preimage))
+	return nil
+}
+
+// watchSwap watches the Scdo blocks for the withdraw tx of HTLC, whose receipt reveals the
+// secret, and redeems the btc contract with it if the btc contract is specified. If the HTLC
+// is not withdrawn before its lock time, it is refunded automatically by the sender.
+func watchSwap(c *cli.Context) error {
+	client, err := rpc.DialTCP(context.Background(), addressValue)
+	if err != nil {
+		return err
+	}
+
+	htlcHash, err := common.HexToHash(hashValue)
+	if err != nil {
+		return fmt.Errorf("invalid HTLC hash, %s", err)
+	}
+
+	h, err := getScdoHTLC(client, htlcHash)
+	if err != nil {
+		return err
+	}
+
+	// unlock the key at start, since the refund happens long after
+	pass, err := common.GetPassword()
+	if err != nil {
+		return fmt.Errorf("failed to get password %s", err)
+	}
+
+	key, err := loadSenderKey(pass)
+	if err != nil {
+		return fmt.Errorf("invalid sender key file. it should be a private key: %s", err)
+	}
+
+	height, err := getScdoHeight(client)
+	if err != nil {
+		return err
+	}
+
+	interval := time.Duration(swapIntervalValue) * time.Second
+	fmt.Printf("watching HTLC %v from block %d, lock time %v\n", htlcHash.Hex(), height, time.Unix(h.TimeLock, 0))
+
+	for {
+		if h.Withdrawed {
+			return onSwapSecret(h.Preimage)
+		}
+
+		if h.Refunded {
+			return errors.New("the HTLC is refunded")
+		}
+
+		head, err := getScdoHeight(client)
+		if err != nil {
+			return err
+		}
+
+		var now time.Time
+		for ; height <= head; height++ {
+			block, err := getSwapBlock(client, height)
+			if err != nil {
+				return err
+			}
+
+			now = time.Unix(block.Header.CreateTimestamp.Int64(), 0)
+			for _, tx := range block.Transactions {
+				secret, err := extractSwapBlockSecret(client, tx, htlcHash, h.HashLock)
+				if err == nil {
+					fmt.Printf("the secret is revealed by tx %v in block %d\n", tx.Hash, height)
+					return onSwapSecret(secret)
+				}
+			}
+		}
+
+		if !now.IsZero() && h.Refundable(now) {
+			if !h.Tx.Data.From.Equal(key.Address) {
+				return errors.New("the HTLC expires, and only the sender could refund it")
+			}
+
+			txd, err := checkRefundTxData(client, key)
+			if err != nil {
+				return err
+			}
+
+			tx, err := newSystemContractTx(client, key, txd, system.HashTimeLockContractAddress, system.CmdRefund, htlcHash.Bytes())
+			if err != nil {
+				return err
+			}
+
+			if err = sendTx(client, tx); err != nil {
+				return err
+			}
+
+			fmt.Println("the HTLC expires and is refunded in tx", tx.Hash.Hex())
+			return nil
+		}
+
+		time.Sleep(interval)
+
+		if h, err = getScdoHTLC(client, htlcHash); err != nil {
+			return err
+		}
+	}
+}
+
+// onSwapSecret prints the revealed secret, and redeems the btc contract if specified
+func onSwapSecret(secret []byte) error {
+	fmt.Println("secret:", hexutil.BytesToHex(secret))
+
+	if len(btcScriptValue) == 0 || len(btcContractTxValue) == 0 || len(btcKeyValue) == 0 {
+		return nil
+	}
+
+	return spendBTCContract(secret)
+}
+
+// swapBlock is the block in the RPC output with the fields used by swap
+type swapBlock struct {
+	Header struct {
+		CreateTimestamp *big.Int
+	}
+	Transactions []*swapBlockTx
+}
+
+// swapBlockTx is the tx in the RPC output of block
+type swapBlockTx struct {
+	Hash    string
+	To      string
+	Payload common.Bytes
+}
+
+func getSwapBlock(client *rpc.Client, height int64) (*swapBlock, error) {
+	block := new(swapBlock)
+	if err := client.Call(block, "scdo_getBlockByHeight", height, true); err != nil {
+		return nil, fmt.Errorf("failed to get block %d, %s", height, err)
+	}
+
+	if block.Header.CreateTimestamp == nil {
+		return nil, fmt.Errorf("invalid block %d", height)
+	}
+
+	return block, nil
+}
+
+func getScdoHeight(client *rpc.Client) (int64, error) {
+	var height int64
+	if err := client.Call(&height, "scdo_getHeight"); err != nil {
+		return 0, fmt.Errorf("failed to get height, %s", err)
+	}
+
+	return height, nil
+}
+
+// extractSwapBlockSecret returns the secret of the withdraw tx in block, whose receipt succeeds
+func extractSwapBlockSecret(client *rpc.Client, tx *swapBlockTx, htlcHash common.Hash, secretHash []byte) ([]byte, error) {
+	if !strings.EqualFold(tx.To, system.HashTimeLockContractAddress.Hex()) {
+		return nil, swap.ErrNotWithdrawTx
+	}
+
+	scdoTx := &types.Transaction{Data: types.TransactionData{To: system.HashTimeLockContractAddress, Payload: tx.Payload}}
+	secret, err := swap.ExtractScdoSecret(scdoTx, htlcHash, secretHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt map[string]interface{}
+	if err = client.Call(&receipt, "txpool_getReceiptByTxHash", tx.Hash, ""); err != nil {
+		return nil, err
+	}
+
+	if failed, _ := receipt["failed"].(bool); failed {
+		return nil, fmt.Errorf("the withdraw tx %v failed", tx.Hash)
+	}
+
+	return secret, nil
+}
+
+// checkRefundTxData returns the tx data of refund with the current nonce of sender
+func checkRefundTxData(client *rpc.Client, key *keystore.Key) (*types.TransactionData, error) {
+	amountValue, toValue, nonceValue = "0", "", DefaultNonce
+	return checkParameter(&key.PrivateKey.PublicKey, client)
+}
+
+// getScdoHTLC gets the HTLC of hash from the system contract
+func getScdoHTLC(client *rpc.Client, htlcHash common.Hash) (*swap.ScdoHTLC, error) {
+	payload := append([]byte{system.CmdGetContract}, htlcHash.Bytes()...)
+
+	var result map[string]interface{}
+	if err := client.Call(&result, "scdo_call", system.HashTimeLockContractAddress.Hex(), hexutil.BytesToHex(payload), -1); err != nil {
+		return nil, fmt.Errorf("Failed to get HTLC, %s", err)
+	}
+
+	if failed, _ := result["failed"].(bool); failed {
+		return nil, fmt.Errorf("Failed to get HTLC, %v", result["result"])
+	}
+
+	resultHex, _ := result["result"].(string)
+	data, err := hexutil.HexToBytes(resultHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return swap.DecodeScdoHTLC(data)
+}
+
+// decodeBTCContractScript decodes the contract script flag
+func decodeBTCContractScript() ([]byte, *swap.BTCContract, error) {
+	script, err := hex.DecodeString(strings.TrimPrefix(btcScriptValue, "0x"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid contract script, %s", err)
+	}
+
+	contract, err := swap.ParseBTCContract(script)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return script, contract, nil
+}
+
+// decodeBTCTxFlag decodes the raw tx hex of flag
+func decodeBTCTxFlag(value string) (*swap.BTCTx, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid btc tx, %s", err)
+	}
+
+	return swap.DecodeBTCTx(raw)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/scdoproject/go-stem/cmd/util"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/common/keystore"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/rpc"
 )
@@ -30,13 +31,43 @@ var (
 			"get":      getHTLC,
 		},
 		"domain": map[string]handler{
-			"create":   createDomainName,
-			"getOwner": getDomainNameOwner,
+			"create":     createDomainName,
+			"getOwner":   getDomainNameOwner,
+			"transfer":   transferDomainName,
+			"renew":      renewDomainName,
+			"subdomain":  setSubdomainOwner,
+			"setAddress": setDomainAddress,
+			"setABIHash": setDomainABIHash,
+			"setText":    setDomainText,
+			"setReverse": setReverseName,
+			"resolve":    resolveDomainName,
+			"reverse":    reverseResolve,
+			"getRecord":  getDomainRecord,
+		},
+		"masternode": map[string]handler{
+			"deposit": depositMasternode,
+			"update":  updateMasternode,
+			"quit":    quitMasternode,
+			"recall":  recallMasternode,
+			"prove":   proveMasternodeLiveness,
+			"slash":   slashMasternode,
+		},
+		"btcrelay": map[string]handler{
+			"verify": verifyBTCTx,
+			"best":   getBTCBestHeader,
+			"header": getBTCHeaderInfo,
 		},
 		"subchain": map[string]handler{
 			"register": registerSubChain,
 			"query":    querySubChain,
 		},
+		"confidential": map[string]handler{
+			"create":   createConfidentialAccount,
+			"deposit":  depositConfidential,
+			"transfer": transferConfidential,
+			"withdraw": withdrawConfidential,
+			"apply":    applyConfidential,
+		},
 	}
 
 	// if the method have key-value, use the call method to get receipt
@@ -44,6 +75,16 @@ var (
 		"htlc": map[string]string{
 			"get": "1",
 		},
+		"domain": map[string]string{
+			"getOwner":  "1",
+			"resolve":   "1",
+			"reverse":   "1",
+			"getRecord": "1",
+		},
+		"btcrelay": map[string]string{
+			"best":   "1",
+			"header": "1",
+		},
 	}
 )
 
@@ -54,11 +95,12 @@ func sendSystemContractTx(client *rpc.Client, to common.Address, method byte, pa
 		return nil, err
 	}
 
+	return newSystemContractTx(client, key, txd, to, method, payload)
+}
+
+// newSystemContractTx generates the system contract transaction with the sender key and transaction data
+func newSystemContractTx(client *rpc.Client, key *keystore.Key, txd *types.TransactionData, to common.Address, method byte, payload []byte) (*types.Transaction, error) {
 	txd.To = to
-	err = checkTxCount(client, txd)
-	if err != nil {
-		return nil, err
-	}
 	txd.Payload = append([]byte{method}, payload...)
 	tx, err := util.GenerateTx(key.PrivateKey, txd.To, txd.Amount, txd.GasPrice, txd.GasLimit, txd.AccountNonce, txd.Payload)
 	if err != nil {
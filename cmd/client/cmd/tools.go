@@ -193,3 +193,88 @@ func readABIFile(abiFile string) (string, error) {
 
 	return string(bytes), nil
 }
+
+// GenerateMnemonicAction generates a random mnemonic to derive keys
+func GenerateMnemonicAction(c *cli.Context) error {
+	mnemonic, err := keystore.NewMnemonic(int(entropyBitsValue))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("mnemonic: %s\n", mnemonic)
+	return nil
+}
+
+// DeriveHDKeyAction derives the key from mnemonic in the requested shard, or along the
+// specified derivation path, and stores it into the keystore directory if specified.
+func DeriveHDKeyAction(c *cli.Context) error {
+	if len(mnemonicValue) == 0 {
+		return fmt.Errorf("please specify the mnemonic")
+	}
+
+	master, err := keystore.NewMasterKeyFromMnemonic(mnemonicValue, "")
+	if err != nil {
+		return fmt.Errorf("invalid mnemonic: %s", err)
+	}
+
+	var key *keystore.Key
+	var path keystore.DerivationPath
+	if len(hdPathValue) > 0 {
+		if path, err = keystore.ParseDerivationPath(hdPathValue); err != nil {
+			return err
+		}
+
+		child, err := master.Derive(path)
+		if err != nil {
+			return err
+		}
+
+		if key, err = child.Key(); err != nil {
+			return err
+		}
+	} else {
+		base := keystore.DefaultBaseDerivationPath(uint32(hdAccountValue))
+		if key, path, err = master.DeriveShardKey(base, shardValue, uint32(hdIndexValue)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("path:        %s\n", path)
+	fmt.Printf("public key:  %s\n", key.Address.Hex())
+	fmt.Printf("private key: %s\n", hexutil.BytesToHex(crypto.FromECDSA(key.PrivateKey)))
+
+	if len(keystoreValue) == 0 {
+		return nil
+	}
+
+	pass, err := common.SetPassword()
+	if err != nil {
+		return fmt.Errorf("get password err %s", err)
+	}
+
+	account, err := keystore.NewManager(keystoreValue).Import(key, pass)
+	if err != nil {
+		return fmt.Errorf("failed to store the key, %s", err)
+	}
+
+	fmt.Printf("store key successfully, the key file path is %s\n", account.File)
+	return nil
+}
+
+// ListAccountAction lists the accounts in keystore directory
+func ListAccountAction(c *cli.Context) error {
+	if len(keystoreValue) == 0 {
+		return fmt.Errorf("please specify the keystore directory")
+	}
+
+	accounts, err := keystore.NewManager(keystoreValue).Accounts()
+	if err != nil {
+		return err
+	}
+
+	for i, account := range accounts {
+		fmt.Printf("account #%d: %s %s\n", i, account.Address.Hex(), account.File)
+	}
+
+	return nil
+}
@@ -11,7 +11,6 @@ import (
 	"math/big"
 
 	"github.com/scdoproject/go-stem/cmd/util"
-	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/hexutil"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
@@ -27,8 +26,12 @@ const (
 func checkParameter(publicKey *ecdsa.PublicKey, client *rpc.Client) (*types.TransactionData, error) {
 	info := &types.TransactionData{}
 	var err error
+	fromAddr := crypto.GetAddress(publicKey)
+	info.From = *fromAddr
+
+	// the receiver could be a domain name, which resolves to the address in the shard of sender
 	if len(toValue) > 0 {
-		toAddr, err := common.HexToAddress(toValue)
+		toAddr, err := resolveAddress(client, toValue, fromAddr.Shard())
 		if err != nil {
 			return info, fmt.Errorf("invalid receiver address: %s", err)
 		}
@@ -49,9 +52,6 @@ func checkParameter(publicKey *ecdsa.PublicKey, client *rpc.Client) (*types.Tran
 
 	info.GasLimit = gasLimitValue
 
-	fromAddr := crypto.GetAddress(publicKey)
-	info.From = *fromAddr
-
 	if nonceValue == DefaultNonce && client != nil {
 		// get current nonce
 		nonce, err := util.GetAccountNonce(client, *fromAddr, "", -1)
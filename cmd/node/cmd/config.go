@@ -92,6 +92,12 @@ func LoadConfigFromFile(configFile string, accounts string) (*node.Config, error
 	comm.LogConfiguration.PrintLog = config.LogConfig.PrintLog
 	comm.LogConfiguration.IsDebug = config.LogConfig.IsDebug
 	comm.LogConfiguration.DataDir = config.BasicConfig.DataDir
+	comm.LogConfiguration.Format = config.LogConfig.Format
+	comm.LogConfiguration.Levels = config.LogConfig.Levels
+	if err = config.LogConfig.Validate(); err != nil {
+		return config, err
+	}
+
 	config.BasicConfig.DataDir = filepath.Join(common.GetDefaultDataFolder(), config.BasicConfig.DataDir)
 	config.BasicConfig.DataSetDir = filepath.Join(common.GetTempFolder(), config.BasicConfig.DataDir)
 	fmt.Printf("loadConfigFile %+v", config.ScdoConfig.GenesisConfig)
@@ -119,6 +125,7 @@ func CopyConfig(cmdConfig *util.Config) *node.Config {
 		P2PConfig:      cmdConfig.P2PConfig,
 		ScdoConfig:    node.ScdoConfig{},
 		MetricsConfig:  cmdConfig.MetricsConfig,
+		LightServerConfig: cmdConfig.LightServerConfig,
 	}
 	return config
 }
@@ -0,0 +1,60 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/scdoproject/go-stem/gateway"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gatewayConfigFile *string
+)
+
+// gatewayCmd represents the gateway command
+var gatewayCmd = &cobra.Command{
+	Use:   "gateway",
+	Short: "start the shard-aware RPC gateway",
+	Long: `Start the RPC gateway which forwards the scdo and txpool requests to the nodes of right shard.
+		For example:
+			node.exe gateway -c gateway.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := gateway.LoadConfig(*gatewayConfigFile)
+		if err != nil {
+			fmt.Printf("failed to load the gateway config: %s\n", err)
+			return
+		}
+
+		g, err := gateway.New(config)
+		if err != nil {
+			fmt.Printf("failed to create the gateway: %s\n", err)
+			return
+		}
+
+		if err = g.Start(); err != nil {
+			fmt.Printf("failed to start the gateway: %s\n", err)
+			return
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+
+		g.Stop()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gatewayCmd)
+
+	gatewayConfigFile = gatewayCmd.Flags().StringP("config", "c", "", "gateway config file path (JSON)")
+	gatewayCmd.MustMarkFlagRequired("config")
+}
@@ -95,6 +95,10 @@ var startCmd = &cobra.Command{
 			engine, err = factory.GetBFTSubchainEngine(nCfg.ScdoConfig.CoinbasePrivateKey, nCfg.BasicConfig.DataDir)
 		} else {
 			engine, err = factory.GetConsensusEngine(nCfg.BasicConfig.MinerAlgorithm, nCfg.BasicConfig.DataSetDir)
+			// the finality gadget only votes on the checkpoints after FinalityForkHeight
+			if validators := nCfg.ScdoConfig.GenesisConfig.Validators; err == nil && len(validators) > 0 {
+				engine, err = factory.GetFinalityEngine(engine, nCfg.ScdoConfig.CoinbasePrivateKey, validators, nCfg.BasicConfig.DataDir)
+			}
 		}
 
 		if err != nil {
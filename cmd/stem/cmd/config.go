@@ -89,6 +89,12 @@ func LoadConfigFromFile(configFile string, accounts string) (*node.Config, error
 	comm.LogConfiguration.PrintLog = config.LogConfig.PrintLog
 	comm.LogConfiguration.IsDebug = config.LogConfig.IsDebug
 	comm.LogConfiguration.DataDir = config.BasicConfig.DataDir
+	comm.LogConfiguration.Format = config.LogConfig.Format
+	comm.LogConfiguration.Levels = config.LogConfig.Levels
+	if err = config.LogConfig.Validate(); err != nil {
+		return config, err
+	}
+
 	config.BasicConfig.DataDir = filepath.Join(scdocommon.GetDefaultDataFolder(), config.BasicConfig.DataDir)
 	config.BasicConfig.DataSetDir = filepath.Join(scdocommon.GetTempFolder(), config.BasicConfig.DataDir)
 	fmt.Printf("loadConfigFile %+v", config.ScdoConfig.GenesisConfig)
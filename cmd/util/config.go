@@ -36,6 +36,9 @@ type Config struct {
 	// metrics config info
 	MetricsConfig *metrics.Config `json:"metrics"`
 
+	// light server flow control config info
+	LightServerConfig node.LightServerConfig `json:"lightServer"`
+
 	// genesis config info
 	GenesisConfig core.GenesisInfo `json:"genesis"`
 }
@@ -9,18 +9,23 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/scdoproject/go-stem/cmd/vm/simulator"
 	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/state"
 	"github.com/scdoproject/go-stem/crypto"
 	"github.com/spf13/cobra"
 )
 
 var (
 	balance uint64
+	shard   uint
 )
 
 func init() {
 	// New account
 	newCmd.Flags().Uint64VarP(&balance, "balance", "b", 0, "create a new account with the balance(Default is 0)")
+	newCmd.Flags().UintVarP(&shard, "shard", "s", 1, "the shard of the new account")
 	rootCmd.AddCommand(newCmd)
 
 	// Set account
@@ -39,23 +44,29 @@ func init() {
 var newCmd = &cobra.Command{
 	Use:   "new",
 	Short: "create a new account",
-	Long:  `Create a new account with the balance(Default is 0)`,
+	Long:  `Create a new account with the balance(Default is 0), the private key is printed to sign transactions sent to the simulator RPC`,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, statedb, _, dispose, err := preprocessContract()
+		_, chain, dispose, err := preprocessContract()
 		if err != nil {
 			fmt.Println("Failed to prepare the simulator environment,", err.Error())
 			return
 		}
 		defer dispose()
 
-		// Generate a random address
-		addr := *crypto.MustGenerateRandomAddress()
-		statedb.CreateAccount(addr)
-		statedb.SetBalance(addr, new(big.Int).SetUint64(balance))
-		// statedb.SetNonce(addr, DefaultNonce)
-		statedb.SetNonce2(addr, DefaultNonce, 0)
+		// Generate a random key pair
+		addr, privateKey := crypto.MustGenerateShardKeyPair(shard)
+		_, err = chain.UpdateState(func(statedb *state.Statedb) error {
+			simulator.SetBalance(statedb, *addr, new(big.Int).SetUint64(balance))
+			return nil
+		})
+
+		if err != nil {
+			fmt.Println("Failed to create the account,", err.Error())
+			return
+		}
 
 		fmt.Println("The new account address is ", addr.Hex())
+		fmt.Println("The private key is ", hexutil.BytesToHex(crypto.FromECDSA(privateKey)))
 	},
 }
 
@@ -64,7 +75,7 @@ var setCmd = &cobra.Command{
 	Short: "set the balance of the account",
 	Long:  `Set the balance(Default is 0) of the account`,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, statedb, _, dispose, err := preprocessContract()
+		_, chain, dispose, err := preprocessContract()
 		if err != nil {
 			fmt.Println("Failed to prepare the simulator environment,", err.Error())
 			return
@@ -77,14 +88,21 @@ var setCmd = &cobra.Command{
 			return
 		}
 
-		if !statedb.Exist(addr) {
-			fmt.Println("Input a non-existence account address ", account)
-			return
-		}
-
 		// Update the balance of the account
 		bigIntBalance := new(big.Int).SetUint64(balance)
-		statedb.SetBalance(addr, bigIntBalance)
+		_, err = chain.UpdateState(func(statedb *state.Statedb) error {
+			if !statedb.Exist(addr) {
+				return fmt.Errorf("input a non-existence account address %v", account)
+			}
+
+			statedb.SetBalance(addr, bigIntBalance)
+			return nil
+		})
+
+		if err != nil {
+			fmt.Println("Failed to set the balance,", err.Error())
+			return
+		}
 
 		fmt.Println("Set the balance successfully, the balance of the account is ", common.BigToDecimal(bigIntBalance.Mul(bigIntBalance, common.ScdoToWen)))
 	},
@@ -95,7 +113,7 @@ var getCmd = &cobra.Command{
 	Short: "get the balance of the account",
 	Long:  `Get the balance of the account, if the account is non-existence, return 0`,
 	Run: func(cmd *cobra.Command, args []string) {
-		_, statedb, _, dispose, err := preprocessContract()
+		_, chain, dispose, err := preprocessContract()
 		if err != nil {
 			fmt.Println("Failed to prepare the simulator environment,", err.Error())
 			return
@@ -108,6 +126,12 @@ var getCmd = &cobra.Command{
 			return
 		}
 
+		statedb, err := chain.GetCurrentState()
+		if err != nil {
+			fmt.Println("Failed to get the state,", err.Error())
+			return
+		}
+
 		fmt.Println("The balance of the account is ", common.BigToDecimal(statedb.GetBalance(addr).Mul(statedb.GetBalance(addr), common.ScdoToWen)))
 	},
 }
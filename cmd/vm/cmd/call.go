@@ -8,7 +8,11 @@ package cmd
 import (
 	"fmt"
 	"math/big"
+	"strings"
 
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/accounts/abi/bind"
+	"github.com/scdoproject/go-stem/cmd/vm/simulator"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/hexutil"
 	"github.com/scdoproject/go-stem/core/types"
@@ -20,7 +24,8 @@ var (
 	contractHexAddr string
 	input           string
 	methodName      string
-	fee 	uint64
+	fee             uint64
+	static          bool
 )
 
 func init() {
@@ -28,34 +33,31 @@ func init() {
 	callCmd.Flags().StringVarP(&methodName, "method", "m", "", "call function method name")
 	callCmd.Flags().StringVarP(&contractHexAddr, "contractAddr", "c", "", "the contract address")
 	callCmd.Flags().StringVarP(&account, "account", "a", "", "invoking the address of calling the smart contract(Default is random and has 1 scdo)")
-	callCmd.Flags().Uint64VarP(&fee, "fee", "f",100000000 , "call function fee")
+	callCmd.Flags().Uint64VarP(&fee, "fee", "f", 100000000, "call function fee")
+	callCmd.Flags().BoolVar(&static, "static", false, "call the contract without changing the chain, e.g. read-only methods")
+	callCmd.Flags().BoolVar(&profileGas, "profile", false, "print the gas profile of the call")
 
 	rootCmd.AddCommand(callCmd)
 }
 
 var callCmd = &cobra.Command{
-	Use:   "call",
+	Use:   "call [method arguments]",
 	Short: "call a contract",
-	Long:  `All contract could callable. This is Scdo contract simulator's`,
+	Long: `All contract could callable. This is Scdo contract simulator's. The call is packed into a new block,
+unless the static flag is specified. The method arguments are packed with the contract ABI if saved.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		callContract(contractHexAddr)
+		callContract(contractHexAddr, args)
 	},
 }
 
-func callContract(contractHexAddr string) {
-	db, statedb, bcStore, dispose, err := preprocessContract()
+func callContract(contractHexAddr string, args []string) {
+	db, chain, dispose, err := preprocessContract()
 	if err != nil {
 		fmt.Println("failed to prepare the simulator environment,", err.Error())
 		return
 	}
 	defer dispose()
 
-	// Get the invoking address
-	from := getFromAddress(statedb)
-	if from.IsEmpty() {
-		return
-	}
-
 	// Contract address
 	contractAddr := getContractAddress(db)
 	if contractAddr.IsEmpty() {
@@ -63,7 +65,7 @@ func callContract(contractHexAddr string) {
 	}
 
 	// Input message to call contract
-	input := getContractInputMsg(db, contractAddr.Bytes())
+	input := getContractInputMsg(db, chain, contractAddr, args)
 	if len(input) == 0 {
 		return
 	}
@@ -75,41 +77,72 @@ func callContract(contractHexAddr string) {
 		return
 	}
 
+	if static {
+		staticCall(chain, contractAddr, input)
+		return
+	}
+
+	// Get the invoking address
+	from := getFromAddress(chain)
+	if from.IsEmpty() {
+		return
+	}
+
+	nonce, err := getNonce(chain, from)
+	if err != nil {
+		fmt.Println("failed to get the account nonce,", err.Error())
+		return
+	}
+
 	// Create a call message transaction
-	callContractTx, err := types.NewMessageTransaction(from, contractAddr, big.NewInt(0), big.NewInt(1), fee, statedb.GetNonce(from)+1, msg)
+	callContractTx, err := types.NewMessageTransaction(from, contractAddr, big.NewInt(0), big.NewInt(1), fee, nonce, msg)
 	if err != nil {
 		fmt.Println("failed to create message tx,", err.Error())
 		return
 	}
 
-	receipt, err := processContract(statedb, bcStore, callContractTx)
+	receipt, err := processContract(chain, callContractTx)
 	if err != nil {
 		fmt.Println("failed to call contract,", err.Error())
 		return
 	}
 
-	// Print the result
 	fmt.Println()
-	fmt.Println("contract called successfully")
+	if receipt.Failed {
+		fmt.Println("contract called failed")
+	} else {
+		fmt.Println("contract called successfully")
+	}
+}
 
-	if len(receipt.Result) > 0 {
-		fmt.Println("Result (raw):", receipt.Result)
-		fmt.Println("Result (hex):", hexutil.BytesToHex(receipt.Result))
-		fmt.Println("Result (big):", new(big.Int).SetBytes(receipt.Result))
+// staticCall calls the contract on the HEAD block without changing the chain
+func staticCall(chain *simulator.Chain, contractAddr common.Address, input string) {
+	tx, err := simulator.NewCallTx(contractAddr.Hex(), input)
+	if err != nil {
+		fmt.Println("failed to create message tx,", err.Error())
+		return
 	}
 
-	for i, log := range receipt.Logs {
-		fmt.Printf("Log[%v]:\n", i)
-		fmt.Println("\taddress:", log.Address.Hex())
-		if len(log.Topics) == 1 {
-			fmt.Println("\ttopics:", log.Topics[0].Hex())
-		} else {
-			fmt.Println("\ttopics:", log.Topics)
-		}
-		dataLen := len(log.Data) / 32
-		for i := 0; i < dataLen; i++ {
-			fmt.Printf("\tdata[%v]: %v\n", i, log.Data[i*32:i*32+32])
-		}
+	var receipt *types.Receipt
+	var profile *simulator.GasProfile
+	if profileGas {
+		receipt, profile, err = chain.ProfileCall(tx, -1)
+	} else {
+		receipt, err = chain.Call(tx, -1, nil)
+	}
+
+	if err != nil {
+		fmt.Println("failed to call contract,", err.Error())
+		return
+	}
+
+	if err = printReceipt(chain, receipt, tx); err != nil {
+		fmt.Println("failed to print receipt,", err.Error())
+		return
+	}
+
+	if profile != nil {
+		printGasProfile(profile)
 	}
 }
 
@@ -132,7 +165,7 @@ func getContractAddress(db database.Database) common.Address {
 	return addr
 }
 
-func getContractInputMsg(db database.Database, contractAddr []byte) string {
+func getContractInputMsg(db database.Database, chain *simulator.Chain, contractAddr common.Address, args []string) string {
 	if len(input) > 0 {
 		return input
 	}
@@ -142,7 +175,12 @@ func getContractInputMsg(db database.Database, contractAddr []byte) string {
 		return ""
 	}
 
-	output := getContractCompilationOutput(db, contractAddr)
+	// pack the method arguments with ABI if saved
+	if abiJSON, ok := chain.ContractABI(contractAddr); ok {
+		return packInput(abiJSON, args)
+	}
+
+	output := getContractCompilationOutput(db, contractAddr.Bytes())
 	if output == nil {
 		fmt.Println("Cannot find the contract info in DB.")
 		return ""
@@ -155,3 +193,35 @@ func getContractInputMsg(db database.Database, contractAddr []byte) string {
 
 	return method.createInput()
 }
+
+// packInput packs the method and arguments with the contract ABI
+func packInput(abiJSON string, args []string) string {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		fmt.Println("Invalid contract ABI,", err.Error())
+		return ""
+	}
+
+	method, ok := parsed.Methods[methodName]
+	if !ok {
+		fmt.Println("Cannot find the specified method name, please call below methods:")
+		for _, m := range parsed.Methods {
+			fmt.Printf("\t%v\n", m.Sig())
+		}
+		return ""
+	}
+
+	values, err := bind.ParseArgs(method.Inputs, args)
+	if err != nil {
+		fmt.Println("Failed to parse the method arguments,", err.Error())
+		return ""
+	}
+
+	data, err := parsed.Pack(methodName, values...)
+	if err != nil {
+		fmt.Println("Failed to pack the method arguments,", err.Error())
+		return ""
+	}
+
+	return hexutil.BytesToHex(data)
+}
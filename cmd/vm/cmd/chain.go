@@ -0,0 +1,164 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-stem/api"
+	"github.com/scdoproject/go-stem/cmd/vm/simulator"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blocks     uint64
+	seconds    int64
+	snapshotID uint64
+	txHash     string
+)
+
+func init() {
+	mineCmd.Flags().Uint64VarP(&blocks, "blocks", "n", 1, "the number of empty blocks to mine")
+	rootCmd.AddCommand(mineCmd)
+
+	timeCmd.Flags().Int64Var(&seconds, "increase", 0, "the seconds to move the timestamp of following blocks forward")
+	rootCmd.AddCommand(timeCmd)
+
+	rootCmd.AddCommand(snapshotCmd)
+
+	revertCmd.Flags().Uint64Var(&snapshotID, "id", 0, "the snapshot id to revert to(Required)")
+	revertCmd.MustMarkFlagRequired("id")
+	rootCmd.AddCommand(revertCmd)
+
+	receiptCmd.Flags().StringVar(&txHash, "hash", "", "the transaction hash(Required)")
+	receiptCmd.MustMarkFlagRequired("hash")
+	rootCmd.AddCommand(receiptCmd)
+}
+
+var mineCmd = &cobra.Command{
+	Use:   "mine",
+	Short: "mine empty blocks",
+	Long:  "Mine the specified number of empty blocks, e.g. to pass the block height required by contract",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, chain, dispose, err := preprocessContract()
+		if err != nil {
+			fmt.Println("Failed to prepare the simulator environment,", err.Error())
+			return
+		}
+		defer dispose()
+
+		if _, err = chain.MineEmpty(blocks); err != nil {
+			fmt.Println("Failed to mine blocks,", err.Error())
+			return
+		}
+
+		fmt.Println("Blocks mined successfully,", chain)
+	},
+}
+
+var timeCmd = &cobra.Command{
+	Use:   "time",
+	Short: "show or increase the time of blocks",
+	Long:  "Show the time offset of blocks, or move the timestamp of following blocks forward with the increase flag",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, chain, dispose, err := preprocessContract()
+		if err != nil {
+			fmt.Println("Failed to prepare the simulator environment,", err.Error())
+			return
+		}
+		defer dispose()
+
+		offset := chain.TimeOffset()
+		if seconds != 0 {
+			if offset, err = chain.IncreaseTime(seconds); err != nil {
+				fmt.Println("Failed to increase time,", err.Error())
+				return
+			}
+		}
+
+		fmt.Println("The time offset of blocks is", offset, "seconds,", chain)
+	},
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "take a snapshot of the chain",
+	Long:  "Take a snapshot of the chain, and the chain could be reverted to the snapshot with the revert command",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, chain, dispose, err := preprocessContract()
+		if err != nil {
+			fmt.Println("Failed to prepare the simulator environment,", err.Error())
+			return
+		}
+		defer dispose()
+
+		id, err := chain.Snapshot()
+		if err != nil {
+			fmt.Println("Failed to take snapshot,", err.Error())
+			return
+		}
+
+		fmt.Println("Snapshot taken successfully, id:", id)
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert",
+	Short: "revert the chain to a snapshot",
+	Long:  "Revert the chain to the specified snapshot, the snapshot and all snapshots taken after it are discarded",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, chain, dispose, err := preprocessContract()
+		if err != nil {
+			fmt.Println("Failed to prepare the simulator environment,", err.Error())
+			return
+		}
+		defer dispose()
+
+		if err = chain.Revert(snapshotID); err != nil {
+			fmt.Println("Failed to revert,", err.Error())
+			return
+		}
+
+		fmt.Println("Reverted successfully,", chain)
+	},
+}
+
+var receiptCmd = &cobra.Command{
+	Use:   "receipt",
+	Short: "get the receipt of a transaction",
+	Long:  "Get the receipt of a transaction, the result and logs are decoded with the contract ABI if saved",
+	Run: func(cmd *cobra.Command, args []string) {
+		_, chain, dispose, err := preprocessContract()
+		if err != nil {
+			fmt.Println("Failed to prepare the simulator environment,", err.Error())
+			return
+		}
+		defer dispose()
+
+		hash, err := common.HexToHash(txHash)
+		if err != nil {
+			fmt.Println("Invalid transaction hash,", err.Error())
+			return
+		}
+
+		receipt, err := chain.GetStore().GetReceiptByTxHash(hash)
+		if err != nil {
+			fmt.Println("Failed to get the receipt,", err.Error())
+			return
+		}
+
+		tx, _, err := api.GetTransaction(simulator.NewBackend(chain).TxPoolBackend(), chain.GetStore(), hash)
+		if err != nil {
+			fmt.Println("Failed to get the transaction,", err.Error())
+			return
+		}
+
+		if err = printReceipt(chain, receipt, tx); err != nil {
+			fmt.Println("Failed to print the receipt,", err.Error())
+		}
+	},
+}
@@ -20,6 +20,7 @@ import (
 
 type solCompileOutput struct {
 	HexByteCodes    string
+	ABI             string // not encoded, the ABI is saved in simulator chain
 	FunctionHashMap map[string]solMethod
 }
 
@@ -84,7 +85,7 @@ func compile(solFilePath string) (*solCompileOutput, func()) {
 	}()
 
 	// run solidity compilation command
-	cmdArgs := fmt.Sprintf("--optimize --bin --hashes -o %v %v", tempDir, solFile)
+	cmdArgs := fmt.Sprintf("--optimize --bin --abi --hashes -o %v %v", tempDir, solFile)
 	cmd := exec.Command("solc", strings.Split(cmdArgs, " ")...)
 	if err = cmd.Run(); err != nil {
 		fmt.Println("Failed to compile the solidity file,", err.Error())
@@ -112,6 +113,8 @@ func compile(solFilePath string) (*solCompileOutput, func()) {
 			output.parseFuncHash(string(content))
 		case ".bin":
 			output.HexByteCodes = ensurePrefix(string(content), "0x")
+		case ".abi":
+			output.ABI = string(content)
 		}
 
 		return nil
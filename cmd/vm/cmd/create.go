@@ -10,7 +10,9 @@ import (
 	"io/ioutil"
 	"math/big"
 	"path/filepath"
+	"strings"
 
+	"github.com/scdoproject/go-stem/accounts/abi"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/hexutil"
 	"github.com/scdoproject/go-stem/core/types"
@@ -18,9 +20,11 @@ import (
 )
 
 var (
-	code    string
-	solFile string
-	account string
+	code       string
+	solFile    string
+	account    string
+	abiJSON    string
+	profileGas bool
 
 	defaultDir = filepath.Join(common.GetDefaultDataFolder(), "simulator")
 )
@@ -29,6 +33,8 @@ func init() {
 	createCmd.Flags().StringVarP(&code, "code", "c", "", "the binary code of the smart contract to create, or the name of a readable file that contains the binary contract code in the local directory(Required)")
 	createCmd.Flags().StringVarP(&solFile, "file", "f", "", "solidity file path")
 	createCmd.Flags().StringVarP(&account, "account", "a", "", "the account address(Default is random and has 1 scdo)")
+	createCmd.Flags().StringVar(&abiJSON, "abi", "", "the ABI of the contract, or the file that contains the ABI, used to decode results and logs")
+	createCmd.Flags().BoolVar(&profileGas, "profile", false, "print the gas profile of the contract creation")
 	rootCmd.AddCommand(createCmd)
 }
 
@@ -57,6 +63,9 @@ func createContract() {
 
 		compileOutput = output
 		code = output.HexByteCodes
+		if len(abiJSON) == 0 {
+			abiJSON = output.ABI
+		}
 		defer dispose()
 	}
 
@@ -71,7 +80,7 @@ func createContract() {
 		return
 	}
 
-	db, statedb, bcStore, dispose, err := preprocessContract()
+	db, chain, dispose, err := preprocessContract()
 	if err != nil {
 		fmt.Println("Failed to prepare the simulator environment,", err.Error())
 		return
@@ -79,26 +88,35 @@ func createContract() {
 	defer dispose()
 
 	// Get an account to create the contract
-	from := getFromAddress(statedb)
+	from := getFromAddress(chain)
 	if from.IsEmpty() {
 		return
 	}
 
 	// Create a contract
-	//createContractTx, err := types.NewContractTransaction(from, big.NewInt(0), big.NewInt(1), math.MaxUint64, DefaultNonce, bytecode)
-	accountNonce := statedb.GetNonce(from)
+	accountNonce, err := getNonce(chain, from)
+	if err != nil {
+		fmt.Println("Failed to get the account nonce,", err.Error())
+		return
+	}
+
 	createContractTx, err := types.NewContractTransaction(from, big.NewInt(0), big.NewInt(1), uint64(3000000), accountNonce, bytecode)
 	if err != nil {
 		fmt.Println("Failed to create contract tx,", err.Error())
 		return
 	}
 
-	receipt, err := processContract(statedb, bcStore, createContractTx)
+	receipt, err := processContract(chain, createContractTx)
 	if err != nil {
 		fmt.Println("Failed to create contract,", err.Error())
 		return
 	}
 
+	if receipt.Failed {
+		fmt.Println("Failed to create contract,", string(receipt.Result))
+		return
+	}
+
 	// Print the contract Address
 	fmt.Println()
 	fmt.Println("contract created successfully")
@@ -110,4 +128,16 @@ func createContract() {
 	if compileOutput != nil {
 		setContractCompilationOutput(db, receipt.ContractAddress, compileOutput)
 	}
+
+	if len(abiJSON) > 0 {
+		if _, err = abi.JSON(strings.NewReader(readABI(abiJSON))); err != nil {
+			fmt.Println("Invalid ABI,", err.Error())
+			return
+		}
+
+		contractAddr := common.BytesToAddress(receipt.ContractAddress)
+		if err = chain.SetContractABI(contractAddr, readABI(abiJSON)); err != nil {
+			fmt.Println("Failed to save the contract ABI,", err.Error())
+		}
+	}
 }
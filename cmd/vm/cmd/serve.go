@@ -0,0 +1,61 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/scdoproject/go-stem/cmd/vm/simulator"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/rpc"
+	"github.com/spf13/cobra"
+)
+
+var serveShard uint
+
+func init() {
+	serveCmd.Flags().StringVar(&rpcAddr, "addr", "127.0.0.1:8027", "the TCP address to serve the node compatible JSON-RPC")
+	serveCmd.Flags().UintVar(&serveShard, "shard", 1, "the shard number of the simulator")
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve the simulator with JSON-RPC",
+	Long: `Serve the simulator with the node compatible JSON-RPC over TCP, so that the client and contract bindings
+could work with the simulator. Every transaction added is mined into a new block immediately, and the simulator
+specific APIs are served in the "sim" namespace.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, chain, dispose, err := preprocessContract()
+		if err != nil {
+			fmt.Println("Failed to prepare the simulator environment,", err.Error())
+			return
+		}
+		defer dispose()
+
+		common.LocalShardNumber = serveShard
+
+		server := rpc.NewServer()
+		for _, api := range simulator.GetAPIs(simulator.NewBackend(chain)) {
+			if err = server.RegisterName(api.Namespace, api.Service); err != nil {
+				fmt.Println("Failed to register RPC service,", err.Error())
+				return
+			}
+		}
+
+		listener, err := net.Listen("tcp", rpcAddr)
+		if err != nil {
+			fmt.Println("Failed to listen,", err.Error())
+			return
+		}
+
+		fmt.Println("Simulator RPC opened at address", rpcAddr, chain)
+		if err = server.ServeListener(listener); err != nil {
+			fmt.Println("RPC server stopped,", err.Error())
+		}
+	},
+}
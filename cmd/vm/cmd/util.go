@@ -6,16 +6,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"math/big"
+	"io/ioutil"
 	"os"
 	"strings"
-	"time"
 
+	"github.com/scdoproject/go-stem/cmd/vm/simulator"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/core/state"
-	"github.com/scdoproject/go-stem/core/store"
-	"github.com/scdoproject/go-stem/core/svm"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
 	"github.com/scdoproject/go-stem/database"
@@ -24,8 +23,6 @@ import (
 
 // const
 const (
-	DefaultNonce             = uint64(1)
-	KeyStateRootHash         = "STATE_ROOT_HASH"
 	keyGlobalContractAddress = "GLOBAL_CONTRACT_ADDRESS"
 )
 
@@ -70,13 +67,20 @@ func getContractCompilationOutput(db database.Database, contractAddress []byte)
 	return &output
 }
 
-func getFromAddress(statedb *state.Statedb) common.Address {
+// getFromAddress returns the specified account, or creates a random account with 1 scdo in a new block.
+func getFromAddress(chain *simulator.Chain) common.Address {
 	if len(account) == 0 {
 		from := *crypto.MustGenerateRandomAddress()
-		statedb.CreateAccount(from)
-		statedb.SetBalance(from, common.ScdoToWen)
-		// statedb.SetNonce(from, DefaultNonce)
-		statedb.SetNonce2(from, DefaultNonce, 0)
+		_, err := chain.UpdateState(func(statedb *state.Statedb) error {
+			simulator.SetBalance(statedb, from, common.ScdoToWen)
+			return nil
+		})
+
+		if err != nil {
+			fmt.Println("Failed to create the account,", err.Error())
+			return common.EmptyAddress
+		}
+
 		return from
 	}
 
@@ -89,6 +93,16 @@ func getFromAddress(statedb *state.Statedb) common.Address {
 	return from
 }
 
+// getNonce returns the nonce of account in the HEAD state
+func getNonce(chain *simulator.Chain, account common.Address) (uint64, error) {
+	statedb, err := chain.GetCurrentState()
+	if err != nil {
+		return 0, err
+	}
+
+	return statedb.GetNonce(account), nil
+}
+
 func ensurePrefix(str, prefix string) string {
 	if strings.HasPrefix(str, prefix) {
 		return str
@@ -97,74 +111,99 @@ func ensurePrefix(str, prefix string) string {
 	return prefix + str
 }
 
-// preprocessContract creates the contract tx dependent state DB, blockchain store
-func preprocessContract() (database.Database, *state.Statedb, store.BlockchainStore, func(), error) {
+// readABI returns the ABI in JSON, the value could be the JSON or a file that contains the JSON
+func readABI(value string) string {
+	if content, err := ioutil.ReadFile(value); err == nil {
+		return string(content)
+	}
+
+	return value
+}
+
+// preprocessContract opens the simulator chain, and returns the dispose method to close the database.
+func preprocessContract() (database.Database, *simulator.Chain, func(), error) {
 	db, err := leveldb.NewLevelDB(defaultDir)
 	if err != nil {
 		os.RemoveAll(defaultDir)
-		return nil, nil, nil, func() {}, err
+		return nil, nil, func() {}, err
 	}
 
-	hash := common.EmptyHash
-	str, err := db.GetString(KeyStateRootHash)
+	chain, err := simulator.NewChain(db)
 	if err != nil {
-		hash = common.EmptyHash
+		db.Close()
+		return nil, nil, func() {}, err
+	}
+
+	return db, chain, func() { db.Close() }, nil
+}
+
+// processContract mines a block with the tx to create or call the contract, and prints the receipt.
+func processContract(chain *simulator.Chain, tx *types.Transaction) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	var profile *simulator.GasProfile
+	var err error
+
+	if profileGas {
+		receipt, profile, err = chain.MineWithProfile(tx)
 	} else {
-		h, err := common.HexToHash(str)
-		if err != nil {
-			db.Close()
-			return nil, nil, nil, func() {}, err
+		var receipts []*types.Receipt
+		if _, receipts, err = chain.Mine([]*types.Transaction{tx}, nil); err == nil {
+			receipt = receipts[0]
 		}
-		hash = h
 	}
 
-	statedb, err := state.NewStatedb(hash, db)
 	if err != nil {
-		db.Close()
-		return nil, nil, nil, func() {}, err
+		return nil, err
 	}
 
-	return db, statedb, store.NewBlockchainDatabase(db), func() {
-		batch := db.NewBatch()
-		hash, err := statedb.Commit(batch)
-		if err != nil {
-			fmt.Println("Failed to commit state DB,", err.Error())
-			return
-		}
+	if err = printReceipt(chain, receipt, tx); err != nil {
+		return nil, err
+	}
+
+	if profile != nil {
+		printGasProfile(profile)
+	}
+
+	return receipt, nil
+}
 
-		if err := batch.Commit(); err != nil {
-			fmt.Println("Failed to commit batch,", err.Error())
-			return
+// printReceipt prints the receipt with result and logs decoded by the contract ABI
+func printReceipt(chain *simulator.Chain, receipt *types.Receipt, tx *types.Transaction) error {
+	readable, err := chain.ReadableReceipt(receipt, tx)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.MarshalIndent(readable, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printGasProfile(profile *simulator.GasProfile) {
+	fmt.Println()
+	fmt.Printf("Gas: total %v, intrinsic %v, execution %v, refund %v\n", profile.Total, profile.Intrinsic, profile.Execution, profile.Refund)
+
+	fmt.Println("Calls:")
+	for _, call := range profile.Calls {
+		name := call.Selector
+		if len(call.Method) > 0 {
+			name = call.Method
 		}
 
-		err = db.PutString(KeyStateRootHash, hash.Hex())
-		db.Close()
-		if err != nil {
-			panic(err)
+		failed := ""
+		if call.Failed {
+			failed = " (failed)"
 		}
-	}, nil
-}
 
-// Create the contract or call the contract
-func processContract(statedb *state.Statedb, bcStore store.BlockchainStore, tx *types.Transaction) (*types.Receipt, error) {
-	// A test block header
-	header := &types.BlockHeader{
-		PreviousBlockHash: crypto.MustHash("block previous hash"),
-		Creator:           *crypto.MustGenerateRandomAddress(),
-		StateHash:         crypto.MustHash("state root hash"),
-		TxHash:            crypto.MustHash("tx root hash"),
-		ReceiptHash:       crypto.MustHash("receipt root hash"),
-		Difficulty:        big.NewInt(38),
-		Height:            666,
-		CreateTimestamp:   big.NewInt(time.Now().Unix()),
-		ExtraData:         make([]byte, 0),
-	}
-
-	ctx := &svm.Context{
-		Tx:          tx,
-		Statedb:     statedb,
-		BlockHeader: header,
-		BcStore:     bcStore,
-	}
-	return svm.Process(ctx, header.Height)
+		fmt.Printf("%v%v %v: %v%v\n", strings.Repeat("\t", call.Depth), call.Address.Hex(), name, call.Gas, failed)
+	}
+
+	fmt.Println("Opcodes:")
+	for _, op := range profile.Ops {
+		fmt.Printf("\t%-16v count %-8v gas %v\n", op.Op, op.Count, op.Gas)
+	}
 }
@@ -0,0 +1,273 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/api"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+// GetAPIs returns the node compatible APIs, and the simulator specific APIs in "sim" namespace
+func GetAPIs(b *Backend) []rpc.API {
+	return append(api.GetAPIs(b), []rpc.API{
+		{
+			Namespace: "scdo",
+			Version:   "1.0",
+			Service:   NewPublicScdoAPI(b.chain),
+			Public:    true,
+		},
+		{
+			Namespace: "sim",
+			Version:   "1.0",
+			Service:   NewPublicSimAPI(b.chain),
+			Public:    true,
+		},
+	}...)
+}
+
+// PublicScdoAPI provides the full node APIs that are not in the common api package
+type PublicScdoAPI struct {
+	chain *Chain
+}
+
+// NewPublicScdoAPI creates a new PublicScdoAPI object for rpc service.
+func NewPublicScdoAPI(chain *Chain) *PublicScdoAPI {
+	return &PublicScdoAPI{chain}
+}
+
+// GetInfo gets the simulator chain info
+func (s *PublicScdoAPI) GetInfo() (api.GetMinerInfo, error) {
+	block := s.chain.CurrentBlock()
+
+	return api.GetMinerInfo{
+		Coinbase:           s.chain.Coinbase(),
+		CurrentBlockHeight: block.Header.Height,
+		HeaderHash:         block.HeaderHash,
+		Shard:              common.LocalShardNumber,
+		MinerStatus:        "Running",
+		Version:            common.ScdoNodeVersion,
+		BlockAge:           new(big.Int).Sub(big.NewInt(time.Now().Unix()+s.chain.TimeOffset()), block.Header.CreateTimestamp),
+		PeerCnt:            "0 (0 0 0 0)",
+	}, nil
+}
+
+// GetHeight returns the height of HEAD block
+func (s *PublicScdoAPI) GetHeight() (int64, error) {
+	return int64(s.chain.CurrentHeader().Height), nil
+}
+
+// Call is to execute a given transaction on a statedb of a given block height.
+// It does not affect the chain and is useful for executing and retrieve values.
+func (s *PublicScdoAPI) Call(contract, payload string, height int64) (map[string]interface{}, error) {
+	tx, err := NewCallTx(contract, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.chain.Call(tx, height, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.chain.ReadableReceipt(receipt, tx)
+}
+
+// EstimateGas returns the gas needed to execute the given transaction against the HEAD block.
+func (s *PublicScdoAPI) EstimateGas(tx *types.Transaction) (uint64, error) {
+	receipt, err := s.chain.Call(tx, -1, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if receipt.Failed {
+		return 0, errors.New(string(receipt.Result))
+	}
+
+	return receipt.UsedGas, nil
+}
+
+// GetLogs Get the logs that satisfies the condition in the block by height and filter
+func (s *PublicScdoAPI) GetLogs(height int64, contractAddress common.Address, abiJSON, eventName string) ([]api.GetLogsResponse, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, errors.NewStackedError(err, "get abi parser failed")
+	}
+
+	event, ok := parsed.Events[eventName]
+	if !ok {
+		return nil, fmt.Errorf("event name %v not found in ABI file", eventName)
+	}
+
+	topic := event.Id()
+
+	block, err := s.chain.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := s.chain.GetStore().GetReceiptsByBlockHash(block.HeaderHash)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]api.GetLogsResponse, 0)
+	for _, receipt := range receipts {
+		for logIndex, log := range receipt.Logs {
+			if !contractAddress.Equal(log.Address) {
+				continue
+			}
+
+			if len(log.Topics) < 1 || !topic.Equal(log.Topics[0]) {
+				continue
+			}
+
+			data, err := event.UnpackLog(log.Topics, log.Data)
+			if err != nil {
+				return nil, errors.NewStackedError(err, "failed to decode event arguments")
+			}
+
+			logs = append(logs, api.GetLogsResponse{Log: log, Txhash: receipt.TxHash, LogIndex: uint(logIndex), Args: data})
+		}
+	}
+
+	return logs, nil
+}
+
+// NewCallTx creates a tx to call the contract from a random account in the same shard
+func NewCallTx(contract, payload string) (*types.Transaction, error) {
+	contractAddr, err := common.HexToAddress(contract)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %s", err)
+	}
+
+	msg, err := hexutil.HexToBytes(payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload, %s", err)
+	}
+
+	from := crypto.MustGenerateShardAddress(contractAddr.Shard())
+	amount, price, nonce := big.NewInt(0), big.NewInt(1), uint64(1)
+	tx, err := types.NewMessageTransaction(*from, contractAddr, amount, price, common.ScdoToWen.Uint64(), nonce, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %s", err)
+	}
+
+	return tx, nil
+}
+
+// PublicSimAPI provides the APIs to control the simulator chain
+type PublicSimAPI struct {
+	chain *Chain
+}
+
+// NewPublicSimAPI creates a new PublicSimAPI object for rpc service.
+func NewPublicSimAPI(chain *Chain) *PublicSimAPI {
+	return &PublicSimAPI{chain}
+}
+
+// Mine produces the specified number of empty blocks, and returns the height of HEAD block
+func (s *PublicSimAPI) Mine(blocks uint64) (uint64, error) {
+	block, err := s.chain.MineEmpty(blocks)
+	if err != nil {
+		return 0, err
+	}
+
+	return block.Header.Height, nil
+}
+
+// IncreaseTime moves the timestamp of the following blocks forward, and returns the total time offset
+func (s *PublicSimAPI) IncreaseTime(seconds int64) (int64, error) {
+	return s.chain.IncreaseTime(seconds)
+}
+
+// Snapshot records the current chain status, and returns the id to revert to
+func (s *PublicSimAPI) Snapshot() (uint64, error) {
+	return s.chain.Snapshot()
+}
+
+// Revert reverts the chain to the specified snapshot
+func (s *PublicSimAPI) Revert(id uint64) (bool, error) {
+	if err := s.chain.Revert(id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetBalance sets the balance of account in a new block
+func (s *PublicSimAPI) SetBalance(account common.Address, balance *big.Int) (bool, error) {
+	_, err := s.chain.UpdateState(func(statedb *state.Statedb) error {
+		SetBalance(statedb, account, balance)
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetABI saves the ABI of contract, which is used to decode the receipts and logs
+func (s *PublicSimAPI) SetABI(contract common.Address, abiJSON string) (bool, error) {
+	if _, err := abi.JSON(strings.NewReader(abiJSON)); err != nil {
+		return false, errors.NewStackedError(err, "invalid ABI")
+	}
+
+	if err := s.chain.SetContractABI(contract, abiJSON); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetReceipt returns the receipt of tx with result and logs decoded by the contract ABI
+func (s *PublicSimAPI) GetReceipt(txHash common.Hash) (map[string]interface{}, error) {
+	receipt, err := s.chain.GetStore().GetReceiptByTxHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, _, err := api.GetTransaction(&autoMinePool{s.chain}, s.chain.GetStore(), txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.chain.ReadableReceipt(receipt, tx)
+}
+
+// ProfileCall executes the contract call on HEAD block without changing the chain, and returns the gas profile
+func (s *PublicSimAPI) ProfileCall(contract, payload string) (*GasProfile, error) {
+	tx, err := NewCallTx(contract, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	_, profile, err := s.chain.ProfileCall(tx, -1)
+	return profile, err
+}
+
+// SetBalance creates the account if not exists and sets its balance
+func SetBalance(statedb *state.Statedb, account common.Address, balance *big.Int) {
+	if !statedb.Exist(account) {
+		statedb.CreateAccount(account)
+		statedb.SetNonce2(account, DefaultNonce, 0)
+	}
+
+	statedb.SetBalance(account, balance)
+}
@@ -0,0 +1,132 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/scdoproject/go-stem/api"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/store"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/log"
+	"github.com/scdoproject/go-stem/p2p"
+)
+
+// Backend implements the api.Backend with the simulator chain, so that the
+// common node APIs could be served by the simulator.
+type Backend struct {
+	chain *Chain
+	pool  *autoMinePool
+	log   *log.ScdoLog
+}
+
+// NewBackend creates a backend of the specified chain
+func NewBackend(chain *Chain) *Backend {
+	return &Backend{
+		chain: chain,
+		pool:  &autoMinePool{chain},
+		log:   log.GetLogger("simulator"),
+	}
+}
+
+// GetP2pServer returns nil since the simulator has no network
+func (b *Backend) GetP2pServer() *p2p.Server { return nil }
+
+// GetNetVersion net version
+func (b *Backend) GetNetVersion() string { return "simulator" }
+
+// GetNetWorkID net id
+func (b *Backend) GetNetWorkID() string { return "simulator" }
+
+// TxPoolBackend tx pool
+func (b *Backend) TxPoolBackend() api.Pool { return b.pool }
+
+// ChainBackend block chain db
+func (b *Backend) ChainBackend() api.Chain { return b.chain }
+
+// ProtocolBackend return protocol
+func (b *Backend) ProtocolBackend() api.Protocol { return b }
+
+// Log return log pointer
+func (b *Backend) Log() *log.ScdoLog { return b.log }
+
+// IsSyncing always returns false
+func (b *Backend) IsSyncing() bool { return false }
+
+// GetBlock returns the requested block by hash or height
+func (b *Backend) GetBlock(hash common.Hash, height int64) (*types.Block, error) {
+	if !hash.IsEmpty() {
+		return b.chain.GetStore().GetBlock(hash)
+	}
+
+	return b.chain.GetBlock(height)
+}
+
+// GetBlockTotalDifficulty return total difficulty
+func (b *Backend) GetBlockTotalDifficulty(hash common.Hash) (*big.Int, error) {
+	return b.chain.GetStore().GetBlockTotalDifficulty(hash)
+}
+
+// GetReceiptByTxHash get receipt by transaction hash
+func (b *Backend) GetReceiptByTxHash(txHash common.Hash) (*types.Receipt, error) {
+	return b.chain.GetStore().GetReceiptByTxHash(txHash)
+}
+
+// GetTransaction return tx
+func (b *Backend) GetTransaction(pool api.PoolCore, bcStore store.BlockchainStore, txHash common.Hash) (*types.Transaction, *api.BlockIndex, error) {
+	return api.GetTransaction(pool, bcStore, txHash)
+}
+
+// SendDifferentShardTx implements api.Protocol, cross shard tx is not supported by simulator
+func (b *Backend) SendDifferentShardTx(tx *types.Transaction, shard uint) {
+	b.log.Warn("cross shard tx is not supported by simulator, tx hash: %v, shard: %v", tx.Hash.Hex(), shard)
+}
+
+// GetProtocolVersion implements api.Protocol
+func (b *Backend) GetProtocolVersion() (uint, error) {
+	return common.ScdoVersion, nil
+}
+
+// autoMinePool is a tx pool that packs every added transaction into a new block immediately
+type autoMinePool struct {
+	chain *Chain
+}
+
+// AddTransaction validates the transaction and mines a block with it
+func (p *autoMinePool) AddTransaction(tx *types.Transaction) error {
+	if err := tx.ValidateWithoutState(true, false); err != nil {
+		return err
+	}
+
+	if shard := tx.Data.From.Shard(); shard != common.LocalShardNumber {
+		return fmt.Errorf("invalid tx shard %v, simulator shard is %v", shard, common.LocalShardNumber)
+	}
+
+	statedb, err := p.chain.GetCurrentState()
+	if err != nil {
+		return err
+	}
+
+	if err = tx.ValidateState(statedb, p.chain.CurrentHeader().Height+1); err != nil {
+		return err
+	}
+
+	_, _, err = p.chain.Mine([]*types.Transaction{tx}, nil)
+	return err
+}
+
+// GetTransaction returns nil since transactions are mined once added
+func (p *autoMinePool) GetTransaction(txHash common.Hash) *types.Transaction { return nil }
+
+// GetTransactions returns empty since transactions are mined once added
+func (p *autoMinePool) GetTransactions(processing, pending bool) []*types.Transaction {
+	return make([]*types.Transaction, 0)
+}
+
+// GetTxCount returns 0 since transactions are mined once added
+func (p *autoMinePool) GetTxCount() int { return 0 }
@@ -0,0 +1,492 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package simulator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	errors2 "github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/store"
+	"github.com/scdoproject/go-stem/core/svm"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/core/vm"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database"
+)
+
+const (
+	// DefaultNonce is the nonce of new accounts
+	DefaultNonce = uint64(1)
+
+	// KeyStateRootHash is the key of state root hash used by the simulator before blocks
+	// are supported, the state is taken as genesis state if exists.
+	KeyStateRootHash = "STATE_ROOT_HASH"
+
+	keyCoinbase   = "SIM_COINBASE"
+	keyTimeOffset = "SIM_TIME_OFFSET"
+	keySnapshotID = "SIM_SNAPSHOT_ID"
+)
+
+var (
+	prefixSnapshot = []byte("SIM-SNAPSHOT-")
+	prefixABI      = []byte("SIM-ABI-")
+
+	errSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// snapshot is the chain status to revert to
+type snapshot struct {
+	BlockHash  common.Hash
+	TimeOffset int64
+}
+
+// Chain is an in-process chain that produces a block for every transaction. The blocks,
+// receipts and states are persisted in the database, so the chain keeps its state
+// between runs of the simulator.
+type Chain struct {
+	db       database.Database
+	bcStore  store.BlockchainStore
+	head     *types.Block
+	coinbase common.Address
+
+	timeOffset int64 // seconds added to the wall clock for the timestamp of new blocks
+	lock       sync.RWMutex
+}
+
+// NewChain loads the chain from the database, or creates the genesis block if not exists
+func NewChain(db database.Database) (*Chain, error) {
+	c := &Chain{
+		db:      db,
+		bcStore: store.NewBlockchainDatabase(db),
+	}
+
+	if hexAddr, err := db.GetString(keyCoinbase); err == nil {
+		if c.coinbase, err = common.HexToAddress(hexAddr); err != nil {
+			return nil, err
+		}
+	} else {
+		c.coinbase = *crypto.MustGenerateRandomAddress()
+		if err = db.PutString(keyCoinbase, c.coinbase.Hex()); err != nil {
+			return nil, err
+		}
+	}
+
+	if value, err := db.Get([]byte(keyTimeOffset)); err == nil {
+		c.timeOffset = int64(binary.BigEndian.Uint64(value))
+	}
+
+	hash, err := c.bcStore.GetHeadBlockHash()
+	if err != nil {
+		return c, c.initGenesis()
+	}
+
+	if c.head, err = c.bcStore.GetBlock(hash); err != nil {
+		return nil, errors2.NewStackedError(err, "failed to get the HEAD block")
+	}
+
+	return c, nil
+}
+
+// initGenesis creates the genesis block with the state of the legacy simulator if any
+func (c *Chain) initGenesis() error {
+	root := common.EmptyHash
+	if str, err := c.db.GetString(KeyStateRootHash); err == nil {
+		if root, err = common.HexToHash(str); err != nil {
+			return err
+		}
+	}
+
+	header := &types.BlockHeader{
+		Creator:         c.coinbase,
+		StateHash:       root,
+		Difficulty:      big.NewInt(1),
+		Height:          0,
+		CreateTimestamp: big.NewInt(time.Now().Unix() + c.timeOffset),
+		ExtraData:       make([]byte, 0),
+	}
+
+	genesis := types.NewBlock(header, nil, nil, nil)
+	if err := c.bcStore.PutBlock(genesis, header.Difficulty, true); err != nil {
+		return errors2.NewStackedError(err, "failed to put genesis block")
+	}
+
+	c.head = genesis
+	return nil
+}
+
+// Coinbase returns the creator of blocks
+func (c *Chain) Coinbase() common.Address {
+	return c.coinbase
+}
+
+// CurrentBlock returns the HEAD block
+func (c *Chain) CurrentBlock() *types.Block {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.head
+}
+
+// CurrentHeader returns the header of HEAD block
+func (c *Chain) CurrentHeader() *types.BlockHeader {
+	return c.CurrentBlock().Header
+}
+
+// GetCurrentState returns the state of HEAD block
+func (c *Chain) GetCurrentState() (*state.Statedb, error) {
+	return state.NewStatedb(c.CurrentHeader().StateHash, c.db)
+}
+
+// GetState returns the state of the specified block
+func (c *Chain) GetState(blockHash common.Hash) (*state.Statedb, error) {
+	header, err := c.bcStore.GetBlockHeader(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return state.NewStatedb(header.StateHash, c.db)
+}
+
+// GetStore returns the store of blocks and receipts
+func (c *Chain) GetStore() store.BlockchainStore {
+	return c.bcStore
+}
+
+// GetBlock returns the block of the specified height, or the HEAD block if height is negative
+func (c *Chain) GetBlock(height int64) (*types.Block, error) {
+	if height < 0 {
+		return c.CurrentBlock(), nil
+	}
+
+	return c.bcStore.GetBlockByHeight(uint64(height))
+}
+
+// newHeader creates the header of the next block, must be called with lock held
+func (c *Chain) newHeader() *types.BlockHeader {
+	parent := c.head.Header
+	timestamp := time.Now().Unix() + c.timeOffset
+	if timestamp <= parent.CreateTimestamp.Int64() {
+		timestamp = parent.CreateTimestamp.Int64() + 1
+	}
+
+	return &types.BlockHeader{
+		PreviousBlockHash: c.head.HeaderHash,
+		Creator:           c.coinbase,
+		StateHash:         parent.StateHash,
+		Difficulty:        big.NewInt(1),
+		Height:            parent.Height + 1,
+		CreateTimestamp:   big.NewInt(timestamp),
+		ExtraData:         make([]byte, 0),
+	}
+}
+
+// commit writes the state and the new block into database, must be called with lock held
+func (c *Chain) commit(header *types.BlockHeader, statedb *state.Statedb, txs []*types.Transaction, receipts []*types.Receipt) (*types.Block, error) {
+	batch := c.db.NewBatch()
+	root, err := statedb.Commit(batch)
+	if err != nil {
+		return nil, errors2.NewStackedError(err, "failed to commit state")
+	}
+
+	if err = batch.Commit(); err != nil {
+		return nil, errors2.NewStackedError(err, "failed to commit batch")
+	}
+
+	header.StateHash = root
+	block := types.NewBlock(header, txs, receipts, nil)
+
+	td, err := c.bcStore.GetBlockTotalDifficulty(c.head.HeaderHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.bcStore.PutReceipts(block.HeaderHash, receipts); err != nil {
+		return nil, errors2.NewStackedError(err, "failed to put receipts")
+	}
+
+	if err = c.bcStore.PutBlock(block, new(big.Int).Add(td, header.Difficulty), true); err != nil {
+		return nil, errors2.NewStackedError(err, "failed to put block")
+	}
+
+	c.head = block
+	return block, nil
+}
+
+// Mine packs the transactions into a new block. If any transaction is invalid, no block is
+// produced and the state is not changed. The optional vmConfig is used to trace the execution.
+func (c *Chain) Mine(txs []*types.Transaction, vmConfig *vm.Config) (*types.Block, []*types.Receipt, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	header := c.newHeader()
+	statedb, err := state.NewStatedb(header.StateHash, c.db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	receipts := make([]*types.Receipt, len(txs))
+	for i, tx := range txs {
+		ctx := &svm.Context{
+			Tx:          tx,
+			TxIndex:     i,
+			Statedb:     statedb,
+			BlockHeader: header,
+			BcStore:     c.bcStore,
+			VMConfig:    vmConfig,
+		}
+
+		if receipts[i], err = svm.Process(ctx, header.Height); err != nil {
+			return nil, nil, errors2.NewStackedErrorf(err, "failed to process tx %v", tx.Hash.Hex())
+		}
+	}
+
+	block, err := c.commit(header, statedb, txs, receipts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block, receipts, nil
+}
+
+// MineEmpty produces the specified number of empty blocks
+func (c *Chain) MineEmpty(count uint64) (*types.Block, error) {
+	for i := uint64(0); i < count; i++ {
+		if _, _, err := c.Mine(nil, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.CurrentBlock(), nil
+}
+
+// UpdateState modifies the state with the specified function and produces a new block with the result state
+func (c *Chain) UpdateState(update func(statedb *state.Statedb) error) (*types.Block, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	header := c.newHeader()
+	statedb, err := state.NewStatedb(header.StateHash, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = update(statedb); err != nil {
+		return nil, err
+	}
+
+	return c.commit(header, statedb, nil, nil)
+}
+
+// Call executes the transaction against the state of the block at the specified height
+// without changing the chain. The HEAD block is used if height is negative. Same as the
+// node, the sender is funded with 1 scdo if not exists.
+func (c *Chain) Call(tx *types.Transaction, height int64, vmConfig *vm.Config) (*types.Receipt, error) {
+	block, err := c.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+
+	statedb, err := state.NewStatedb(block.Header.StateHash, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	if !statedb.Exist(tx.Data.From) {
+		statedb.CreateAccount(tx.Data.From)
+		statedb.SetBalance(tx.Data.From, common.ScdoToWen)
+	}
+
+	ctx := &svm.Context{
+		Tx:          tx,
+		Statedb:     statedb,
+		BlockHeader: block.Header.Clone(),
+		BcStore:     c.bcStore,
+		VMConfig:    vmConfig,
+	}
+
+	return svm.Process(ctx, block.Header.Height)
+}
+
+// TimeOffset returns the seconds added to the wall clock for new blocks
+func (c *Chain) TimeOffset() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.timeOffset
+}
+
+// IncreaseTime moves the timestamp of the following blocks forward, and returns the total time offset
+func (c *Chain) IncreaseTime(seconds int64) (int64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.setTimeOffset(c.timeOffset + seconds); err != nil {
+		return 0, err
+	}
+
+	return c.timeOffset, nil
+}
+
+func (c *Chain) setTimeOffset(offset int64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, uint64(offset))
+	if err := c.db.Put([]byte(keyTimeOffset), value); err != nil {
+		return err
+	}
+
+	c.timeOffset = offset
+	return nil
+}
+
+// encodeSnapshot encodes the snapshot as block hash followed by the time offset
+func encodeSnapshot(snap snapshot) []byte {
+	value := make([]byte, common.HashLength+8)
+	copy(value, snap.BlockHash.Bytes())
+	binary.BigEndian.PutUint64(value[common.HashLength:], uint64(snap.TimeOffset))
+	return value
+}
+
+func decodeSnapshot(value []byte) (snapshot, error) {
+	if len(value) != common.HashLength+8 {
+		return snapshot{}, fmt.Errorf("invalid snapshot length %v", len(value))
+	}
+
+	return snapshot{
+		BlockHash:  common.BytesToHash(value[:common.HashLength]),
+		TimeOffset: int64(binary.BigEndian.Uint64(value[common.HashLength:])),
+	}, nil
+}
+
+func snapshotKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return append(prefixSnapshot, key...)
+}
+
+// nextSnapshotID returns the id of the next snapshot, must be called with lock held
+func (c *Chain) nextSnapshotID() uint64 {
+	value, err := c.db.Get([]byte(keySnapshotID))
+	if err != nil {
+		return 1
+	}
+
+	return binary.BigEndian.Uint64(value)
+}
+
+func (c *Chain) setNextSnapshotID(id uint64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, id)
+	return c.db.Put([]byte(keySnapshotID), value)
+}
+
+// Snapshot records the current chain status, and returns the id to revert to
+func (c *Chain) Snapshot() (uint64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	id := c.nextSnapshotID()
+	snap := snapshot{
+		BlockHash:  c.head.HeaderHash,
+		TimeOffset: c.timeOffset,
+	}
+
+	if err := c.db.Put(snapshotKey(id), encodeSnapshot(snap)); err != nil {
+		return 0, err
+	}
+
+	if err := c.setNextSnapshotID(id + 1); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Revert reverts the chain to the specified snapshot. The blocks after the snapshot are
+// removed, and the snapshot and all the snapshots taken after it are discarded.
+func (c *Chain) Revert(id uint64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	next := c.nextSnapshotID()
+	if id == 0 || id >= next {
+		return errSnapshotNotFound
+	}
+
+	value, err := c.db.Get(snapshotKey(id))
+	if err != nil {
+		return errSnapshotNotFound
+	}
+
+	snap, err := decodeSnapshot(value)
+	if err != nil {
+		return err
+	}
+
+	target, err := c.bcStore.GetBlock(snap.BlockHash)
+	if err != nil {
+		return errors2.NewStackedError(err, "failed to get the snapshot block")
+	}
+
+	for height := c.head.Header.Height; height > target.Header.Height; height-- {
+		hash, err := c.bcStore.GetBlockHash(height)
+		if err != nil {
+			return err
+		}
+
+		if err = c.bcStore.DeleteBlock(hash); err != nil {
+			return errors2.NewStackedErrorf(err, "failed to delete block %v", height)
+		}
+
+		if _, err = c.bcStore.DeleteBlockHash(height); err != nil {
+			return err
+		}
+	}
+
+	if err = c.bcStore.PutHeadBlockHash(target.HeaderHash); err != nil {
+		return err
+	}
+	c.head = target
+
+	if err = c.setTimeOffset(snap.TimeOffset); err != nil {
+		return err
+	}
+
+	for i := id; i < next; i++ {
+		if err = c.db.Delete(snapshotKey(i)); err != nil {
+			return err
+		}
+	}
+
+	return c.setNextSnapshotID(id)
+}
+
+// SetContractABI saves the ABI of contract, which is used to decode the receipts and logs
+func (c *Chain) SetContractABI(contract common.Address, abiJSON string) error {
+	return c.db.Put(append(prefixABI, contract.Bytes()...), []byte(abiJSON))
+}
+
+// ContractABI returns the ABI of contract if saved
+func (c *Chain) ContractABI(contract common.Address) (string, bool) {
+	value, err := c.db.Get(append(prefixABI, contract.Bytes()...))
+	if err != nil {
+		return "", false
+	}
+
+	return string(value), true
+}
+
+// String implements the fmt.Stringer interface
+func (c *Chain) String() string {
+	head := c.CurrentBlock()
+	return fmt.Sprintf("height: %v, hash: %v, time: %v", head.Header.Height, head.HeaderHash.Hex(), head.Header.CreateTimestamp)
+}
@@ -0,0 +1,185 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package simulator
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// code of contract/solidity/simple_storage.sol
+const (
+	simpleStorageBin = "0x608060405234801561001057600080fd5b50600560008190555060df806100276000396000f3006080604052600436106049576000357c0100000000000000000000000000000000000000000000000000000000900463ffffffff16806360fe47b114604e5780636d4ce63c146078575b600080fd5b348015605957600080fd5b5060766004803603810190808035906020019092919050505060a0565b005b348015608357600080fd5b50608a60aa565b6040518082815260200191505060405180910390f35b8060008190555050565b600080549050905600a165627a7a723058207f6dc43a0d648e9f5a0cad5071cde46657de72eb87ab4cded53a7f1090f51e6d0029"
+	simpleStorageABI = `[
+{"constant":false,"inputs":[{"name":"x","type":"uint256"}],"name":"set","outputs":[],"type":"function"},
+{"constant":true,"inputs":[],"name":"get","outputs":[{"name":"","type":"uint256"}],"type":"function"}
+]`
+)
+
+func newTestChain(t *testing.T) (*Chain, common.Address, func()) {
+	db, dispose := leveldb.NewTestDatabase()
+
+	chain, err := NewChain(db)
+	assert.Nil(t, err)
+
+	from := *crypto.MustGenerateShardAddress(1)
+	_, err = chain.UpdateState(func(statedb *state.Statedb) error {
+		SetBalance(statedb, from, common.ScdoToWen)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	return chain, from, dispose
+}
+
+func mineTx(t *testing.T, chain *Chain, from common.Address, to common.Address, payload []byte) *types.Receipt {
+	statedb, err := chain.GetCurrentState()
+	assert.Nil(t, err)
+
+	var tx *types.Transaction
+	if to.IsEmpty() {
+		tx, err = types.NewContractTransaction(from, big.NewInt(0), big.NewInt(1), 3000000, statedb.GetNonce(from), payload)
+	} else {
+		tx, err = types.NewMessageTransaction(from, to, big.NewInt(0), big.NewInt(1), 3000000, statedb.GetNonce(from), payload)
+	}
+	assert.Nil(t, err)
+
+	_, receipts, err := chain.Mine([]*types.Transaction{tx}, nil)
+	assert.Nil(t, err)
+	assert.False(t, receipts[0].Failed)
+
+	return receipts[0]
+}
+
+func getStoredData(t *testing.T, chain *Chain, contract common.Address) interface{} {
+	tx, err := NewCallTx(contract.Hex(), "0x6d4ce63c")
+	assert.Nil(t, err)
+
+	receipt, err := chain.Call(tx, -1, nil)
+	assert.Nil(t, err)
+
+	readable, err := chain.ReadableReceipt(receipt, tx)
+	assert.Nil(t, err)
+	assert.Equal(t, "get", readable["method"])
+
+	return readable["decoded"].([]interface{})[0]
+}
+
+func Test_Chain_Persistent(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	chain, err := NewChain(db)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), chain.CurrentHeader().Height)
+
+	head, err := chain.MineEmpty(3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), head.Header.Height)
+
+	// reload the chain from database
+	chain2, err := NewChain(db)
+	assert.Nil(t, err)
+	assert.Equal(t, head.HeaderHash, chain2.CurrentBlock().HeaderHash)
+	assert.Equal(t, chain.Coinbase(), chain2.Coinbase())
+
+	block, err := chain2.GetBlock(2)
+	assert.Nil(t, err)
+	assert.Equal(t, head.Header.PreviousBlockHash, block.HeaderHash)
+}
+
+func Test_Chain_SnapshotRevert(t *testing.T) {
+	chain, from, dispose := newTestChain(t)
+	defer dispose()
+
+	receipt := mineTx(t, chain, from, common.EmptyAddress, hexutil.MustHexToBytes(simpleStorageBin))
+	contract := common.BytesToAddress(receipt.ContractAddress)
+	assert.Nil(t, chain.SetContractABI(contract, simpleStorageABI))
+	assert.Equal(t, big.NewInt(5), getStoredData(t, chain, contract))
+
+	id, err := chain.Snapshot()
+	assert.Nil(t, err)
+	height := chain.CurrentHeader().Height
+
+	parsed, _ := abi.JSON(strings.NewReader(simpleStorageABI))
+	input, _ := parsed.Pack("set", big.NewInt(7))
+	receipt = mineTx(t, chain, from, contract, input)
+	assert.Equal(t, big.NewInt(7), getStoredData(t, chain, contract))
+
+	// the receipt is persisted with block
+	stored, err := chain.GetStore().GetReceiptByTxHash(receipt.TxHash)
+	assert.Nil(t, err)
+	assert.Equal(t, receipt.TxHash, stored.TxHash)
+
+	assert.Nil(t, chain.Revert(id))
+	assert.Equal(t, height, chain.CurrentHeader().Height)
+	assert.Equal(t, big.NewInt(5), getStoredData(t, chain, contract))
+
+	_, err = chain.GetStore().GetReceiptByTxHash(receipt.TxHash)
+	assert.NotNil(t, err)
+
+	// the snapshot is discarded once reverted
+	assert.Equal(t, errSnapshotNotFound, chain.Revert(id))
+}
+
+func Test_Chain_IncreaseTime(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	chain, err := NewChain(db)
+	assert.Nil(t, err)
+
+	before := chain.CurrentHeader().CreateTimestamp.Int64()
+	offset, err := chain.IncreaseTime(3600)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3600), offset)
+
+	head, err := chain.MineEmpty(1)
+	assert.Nil(t, err)
+	assert.True(t, head.Header.CreateTimestamp.Int64() >= before+3600)
+
+	// the block timestamp always increases
+	head2, err := chain.MineEmpty(1)
+	assert.Nil(t, err)
+	assert.True(t, head2.Header.CreateTimestamp.Int64() > head.Header.CreateTimestamp.Int64())
+}
+
+func Test_Chain_ProfileCall(t *testing.T) {
+	chain, from, dispose := newTestChain(t)
+	defer dispose()
+
+	receipt := mineTx(t, chain, from, common.EmptyAddress, hexutil.MustHexToBytes(simpleStorageBin))
+	contract := common.BytesToAddress(receipt.ContractAddress)
+	assert.Nil(t, chain.SetContractABI(contract, simpleStorageABI))
+
+	parsed, _ := abi.JSON(strings.NewReader(simpleStorageABI))
+	input, _ := parsed.Pack("set", big.NewInt(7))
+	tx, err := NewCallTx(contract.Hex(), hexutil.BytesToHex(input))
+	assert.Nil(t, err)
+
+	height := chain.CurrentHeader().Height
+	receipt, profile, err := chain.ProfileCall(tx, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, height, chain.CurrentHeader().Height)
+
+	assert.Equal(t, receipt.UsedGas, profile.Total)
+	assert.Equal(t, tx.IntrinsicGas(), profile.Intrinsic)
+	assert.True(t, profile.Execution > 0)
+	assert.Equal(t, 1, len(profile.Calls))
+	assert.Equal(t, "set", profile.Calls[0].Method)
+	assert.Equal(t, profile.Execution, profile.Calls[0].Gas)
+	assert.Equal(t, "SSTORE", profile.Ops[0].Op)
+}
@@ -0,0 +1,247 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package simulator
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/core/vm"
+)
+
+// OpGas is the gas consumed by an opcode during execution
+type OpGas struct {
+	Op    string `json:"op"`
+	Count uint64 `json:"count"`
+	Gas   uint64 `json:"gas"`
+}
+
+// CallGas is the gas consumed by a (nested) contract call, including the gas of its sub calls
+type CallGas struct {
+	Depth    int            `json:"depth"`
+	Address  common.Address `json:"address"`
+	Selector string         `json:"selector"` // method id of the call, or "create" for contract creation
+	Method   string         `json:"method,omitempty"`
+	Gas      uint64         `json:"gas"`
+	Failed   bool           `json:"failed,omitempty"`
+
+	startGas uint64
+	lastGas  uint64
+	lastOp   vm.OpCode
+}
+
+// GasProfile is the gas report of a transaction
+type GasProfile struct {
+	Intrinsic uint64     `json:"intrinsic"` // intrinsic gas of the tx
+	Execution uint64     `json:"execution"` // gas used by EVM execution, before refund
+	Refund    uint64     `json:"refund"`    // gas refunded, e.g. storage cleared
+	Total     uint64     `json:"total"`     // gas charged in receipt
+	Ops       []*OpGas   `json:"ops"`       // gas used by opcodes, sorted by gas in descending order
+	Calls     []*CallGas `json:"calls"`     // gas used by calls in execution order
+}
+
+// GasProfiler is a vm.Tracer that collects the gas used by opcodes and calls
+type GasProfiler struct {
+	ops       map[vm.OpCode]*OpGas
+	calls     []*CallGas
+	frames    []*CallGas
+	execution uint64
+	create    bool // whether the tx creates a contract
+}
+
+// NewGasProfiler creates a gas profiler
+func NewGasProfiler() *GasProfiler {
+	return &GasProfiler{
+		ops: make(map[vm.OpCode]*OpGas),
+	}
+}
+
+// isCallOp returns whether the op forwards gas to a sub call, whose gas cost
+// includes the gas forwarded.
+func isCallOp(op vm.OpCode) bool {
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2:
+		return true
+	}
+
+	return false
+}
+
+// CaptureStart implements vm.Tracer, note that the flag is true for contract creation.
+func (p *GasProfiler) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	p.create = create
+	return nil
+}
+
+// CaptureState implements vm.Tracer
+func (p *GasProfiler) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	p.enter(contract, gas, depth)
+
+	stat := p.ops[op]
+	if stat == nil {
+		stat = &OpGas{Op: op.String()}
+		p.ops[op] = stat
+	}
+
+	stat.Count++
+	stat.Gas += cost
+
+	frame := p.frames[len(p.frames)-1]
+	frame.lastOp = op
+	if gas >= cost {
+		frame.lastGas = gas - cost
+	} else {
+		frame.lastGas = 0
+	}
+
+	return nil
+}
+
+// CaptureFault implements vm.Tracer
+func (p *GasProfiler) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	p.enter(contract, gas, depth)
+
+	frame := p.frames[len(p.frames)-1]
+	frame.Failed = true
+	if err == vm.ErrOutOfGas {
+		frame.lastGas = 0
+	}
+
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer
+func (p *GasProfiler) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	p.leave(0)
+	p.execution = gasUsed
+	return nil
+}
+
+// enter updates the call frames according to the depth of the executing contract
+func (p *GasProfiler) enter(contract *vm.Contract, gas uint64, depth int) {
+	p.leave(depth)
+
+	for len(p.frames) < depth {
+		frame := &CallGas{
+			Depth:    len(p.frames) + 1,
+			Address:  contract.Address(),
+			Selector: "fallback",
+			startGas: gas,
+			lastGas:  gas,
+		}
+
+		if len(contract.Input) >= 4 {
+			frame.Selector = hexutil.BytesToHex(contract.Input[:4])
+		}
+
+		if len(p.frames) == 0 {
+			if p.create {
+				frame.Selector = "create"
+			}
+		} else {
+			// the gas forwarded to sub call is accounted in the sub call
+			parent := p.frames[len(p.frames)-1]
+			if parent.lastOp == vm.CREATE || parent.lastOp == vm.CREATE2 {
+				frame.Selector = "create"
+			}
+
+			if stat := p.ops[parent.lastOp]; stat != nil && isCallOp(parent.lastOp) {
+				if stat.Gas > gas {
+					stat.Gas -= gas
+				} else {
+					stat.Gas = 0
+				}
+			}
+		}
+
+		p.frames = append(p.frames, frame)
+		p.calls = append(p.calls, frame)
+	}
+}
+
+// leave finishes the call frames deeper than the specified depth
+func (p *GasProfiler) leave(depth int) {
+	for len(p.frames) > depth {
+		frame := p.frames[len(p.frames)-1]
+		frame.Gas = frame.startGas - frame.lastGas
+		p.frames = p.frames[:len(p.frames)-1]
+	}
+}
+
+// Profile returns the gas profile collected for the transaction and its receipt
+func (p *GasProfiler) Profile(tx *types.Transaction, receipt *types.Receipt) *GasProfile {
+	profile := &GasProfile{
+		Intrinsic: tx.IntrinsicGas(),
+		Execution: p.execution,
+		Total:     receipt.UsedGas,
+		Calls:     p.calls,
+	}
+
+	if used := profile.Intrinsic + profile.Execution; used > profile.Total {
+		profile.Refund = used - profile.Total
+	}
+
+	for _, stat := range p.ops {
+		profile.Ops = append(profile.Ops, stat)
+	}
+
+	sort.Slice(profile.Ops, func(i, j int) bool {
+		if profile.Ops[i].Gas == profile.Ops[j].Gas {
+			return profile.Ops[i].Op < profile.Ops[j].Op
+		}
+		return profile.Ops[i].Gas > profile.Ops[j].Gas
+	})
+
+	return profile
+}
+
+// ProfileCall executes the transaction against the state of the block at the specified
+// height without changing the chain, and returns the receipt with its gas profile.
+func (c *Chain) ProfileCall(tx *types.Transaction, height int64) (*types.Receipt, *GasProfile, error) {
+	profiler := NewGasProfiler()
+	receipt, err := c.Call(tx, height, &vm.Config{Debug: true, Tracer: profiler})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return receipt, c.resolveMethods(profiler.Profile(tx, receipt)), nil
+}
+
+// MineWithProfile packs the transaction into a new block, and returns the receipt with its gas profile.
+func (c *Chain) MineWithProfile(tx *types.Transaction) (*types.Receipt, *GasProfile, error) {
+	profiler := NewGasProfiler()
+	_, receipts, err := c.Mine([]*types.Transaction{tx}, &vm.Config{Debug: true, Tracer: profiler})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return receipts[0], c.resolveMethods(profiler.Profile(tx, receipts[0])), nil
+}
+
+// resolveMethods fills the method names of calls with the ABI saved for the contracts
+func (c *Chain) resolveMethods(profile *GasProfile) *GasProfile {
+	for _, call := range profile.Calls {
+		parsed, ok := c.parsedABI(call.Address)
+		if !ok {
+			continue
+		}
+
+		selector, err := hexutil.HexToBytes(call.Selector)
+		if err != nil || len(selector) != 4 {
+			continue
+		}
+
+		if method, err := parsed.MethodById(selector); err == nil {
+			call.Method = method.Name
+		}
+	}
+
+	return profile
+}
@@ -0,0 +1,100 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package simulator
+
+import (
+	"strings"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/api"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// DecodedLog is a log with the event arguments decoded by the contract ABI
+type DecodedLog struct {
+	Address common.Address `json:"address"`
+	Event   string         `json:"event,omitempty"`
+	Topics  []common.Hash  `json:"topics"`
+	Args    []interface{}  `json:"args,omitempty"`
+	Data    []byte         `json:"data,omitempty"` // raw data if the event is unknown
+}
+
+// parsedABI returns the parsed ABI of contract if saved
+func (c *Chain) parsedABI(contract common.Address) (abi.ABI, bool) {
+	abiJSON, ok := c.ContractABI(contract)
+	if !ok {
+		return abi.ABI{}, false
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return abi.ABI{}, false
+	}
+
+	return parsed, true
+}
+
+// ReadableReceipt converts the receipt into the RPC output, and decodes the result
+// and logs with the ABI saved for the contracts.
+func (c *Chain) ReadableReceipt(receipt *types.Receipt, tx *types.Transaction) (map[string]interface{}, error) {
+	result, err := api.PrintableReceipt(receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	if tx != nil && !receipt.Failed && !tx.Data.To.IsEmpty() && len(tx.Data.Payload) >= 4 {
+		if parsed, ok := c.parsedABI(tx.Data.To); ok {
+			if method, err := parsed.MethodById(tx.Data.Payload); err == nil {
+				result["method"] = method.Name
+				if values, err := method.Outputs.UnpackValues(receipt.Result); err == nil && len(values) > 0 {
+					result["decoded"] = values
+				}
+			}
+		}
+	}
+
+	if len(receipt.Logs) > 0 {
+		result["logs"] = c.DecodeLogs(receipt.Logs)
+	}
+
+	return result, nil
+}
+
+// DecodeLogs decodes the event arguments of logs with the ABI saved for the contracts
+func (c *Chain) DecodeLogs(logs []*types.Log) []*DecodedLog {
+	decoded := make([]*DecodedLog, 0, len(logs))
+
+	for _, log := range logs {
+		out := &DecodedLog{
+			Address: log.Address,
+			Topics:  log.Topics,
+			Data:    log.Data,
+		}
+		decoded = append(decoded, out)
+
+		parsed, ok := c.parsedABI(log.Address)
+		if !ok || len(log.Topics) == 0 {
+			continue
+		}
+
+		for _, event := range parsed.Events {
+			if id := event.Id(); !id.Equal(log.Topics[0]) {
+				continue
+			}
+
+			if args, err := event.UnpackLog(log.Topics, log.Data); err == nil {
+				out.Event = event.Name
+				out.Args = args
+				out.Data = nil
+			}
+
+			break
+		}
+	}
+
+	return decoded
+}
@@ -18,8 +18,8 @@ const (
 	// ScdoProtoName protoName of Scdo service
 	ScdoProtoName = "scdo"
 
-	// ScdoVersion Version number of Scdo protocol
-	ScdoVersion uint = 1
+	// ScdoVersion Version number of Scdo protocol, version 2 adds the finality checkpoint messages
+	ScdoVersion uint = 2
 
 	// ScdoVersion for simpler display
 	ScdoNodeVersion string = "v1.0.0"
@@ -39,6 +39,12 @@ const (
 	// ConfirmedBlockNumber is the block number for confirmed a block, it should be more than 12 in product
 	ConfirmedBlockNumber = 120
 
+	// FinalityCheckpointInterval is the block number between two checkpoints voted by the finality committee
+	FinalityCheckpointInterval = 20
+
+	// FinalityCheckpointDepth is the block number on top of a checkpoint before the committee votes on it
+	FinalityCheckpointDepth = 6
+
 	// ForkHeight after this height we change the content of block: hardFork
 	ForkHeight = 130000
 
@@ -50,6 +56,45 @@ const (
 
 	SmartContractNonceForkHeight = 1100000
 
+	// SystemContractCallForkHeight after this height the system contracts are callable by EVM contracts: hardFork
+	SystemContractCallForkHeight = 1500000
+
+	// SystemContractEventForkHeight after this height the system contracts emit event logs in receipts: hardFork
+	SystemContractEventForkHeight = 1500000
+
+	// ContractDebtForkHeight after this height the value transferred by EVM contracts to other shards becomes debts: hardFork
+	ContractDebtForkHeight = 1500000
+
+	// CrossShardCallForkHeight after this height the EVM contracts could call contracts of other shards asynchronously: hardFork
+	CrossShardCallForkHeight = 1500000
+
+	// DomainNameServiceForkHeight after this height the domain names expire, and could be transferred and resolved: hardFork
+	DomainNameServiceForkHeight = 1500000
+
+	// MasternodeForkHeight after this height the masternodes register endpoints, prove liveness and share block reward: hardFork
+	MasternodeForkHeight = 1500000
+
+	// BTCRelayForkHeight after this height the btc relay validates bitcoin headers and verifies txs with SPV proofs: hardFork
+	BTCRelayForkHeight = 1500000
+
+	// StakingForkHeight after this height the validators bond stake to weight the votes of bft consensus: hardFork
+	StakingForkHeight = 1500000
+
+	// BLSSealForkHeight after this height the bft headers carry the BLS aggregated commit seal and signer bitmap: hardFork
+	BLSSealForkHeight = 1600000
+
+	// SubChainTxLimitForkHeight after this height the sub-chains register the tx limit and relay range: hardFork
+	SubChainTxLimitForkHeight = 1500000
+
+	// EvidenceForkHeight after this height the bft headers carry the double sign evidence that removes the offenders: hardFork
+	EvidenceForkHeight = 1600000
+
+	// FinalityForkHeight after this height the finality committee votes on the checkpoints of PoW shards: hardFork
+	FinalityForkHeight = 1700000
+
+	// ConfidentialTransferForkHeight after this height the accounts could opt in the confidential transfers with hidden amounts: hardFork
+	ConfidentialTransferForkHeight = 1600000
+
 	// LightChainDir lightchain data directory based on config.DataRoot
 	LightChainDir = "/db/lightchain"
 
@@ -68,13 +113,14 @@ const (
 	// BFT mineralgorithm
 	BFTEngine = "bft"
 
-	// subchain bft relay period, roughly 2 days with 2s block interval
+	// subchain bft relay period, roughly 2 days with 2s block interval.
+	// It is the default relay range of subchain registered without it.
 	RelayRange = 84 * 1024
 
 	CheckInterval = 1024
 
-	// TxLimitPerRelay tx limit during each relay period
-	TxLimitPerRelay = 160
+	// DefaultTxLimitPerRelay is the default tx limit of each account during each relay period
+	DefaultTxLimitPerRelay = 160
 
 	// RelayRange = 10
 	// BFTBlockInterval bft consensus block interval
@@ -83,6 +129,9 @@ const (
 	// BFT data folder
 	BFTDataFolder = "bftdata"
 
+	// FinalityDataFolder is the folder of finalized checkpoints
+	FinalityDataFolder = "finalitydata"
+
 	// BFT mineralgorithm
 	BFTSubchainEngine = "bft_subchain"
 
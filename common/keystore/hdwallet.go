@@ -0,0 +1,240 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart uint32 = 0x80000000
+
+	// CoinType is the BIP-44 coin type of Scdo
+	CoinType uint32 = 541
+
+	// maxShardDerivation is the max number of indexes to walk for a key in the requested shard
+	maxShardDerivation = 1000
+)
+
+var (
+	masterKeySecret = []byte("Bitcoin seed")
+
+	errInvalidSeedLength = errors.New("invalid seed length, should be between 16 and 64 bytes")
+	errInvalidChildKey   = errors.New("invalid child key, try next index")
+	errDerivationPath    = errors.New("invalid derivation path")
+	errShardNotFound     = errors.New("no key derived in the requested shard")
+)
+
+// DerivationPath is the BIP-32 path of child key indexes from the master key
+type DerivationPath []uint32
+
+// DefaultBaseDerivationPath returns the BIP-44 base path m/44'/541'/account'/0 of Scdo,
+// and the keys are derived with the address index appended.
+func DefaultBaseDerivationPath(account uint32) DerivationPath {
+	return DerivationPath{44 + HardenedKeyStart, CoinType + HardenedKeyStart, account + HardenedKeyStart, 0}
+}
+
+// ParseDerivationPath parses the path in form of m/44'/541'/0'/0/0
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	elems := strings.Split(strings.TrimSpace(path), "/")
+	if len(elems) == 0 || elems[0] != "m" {
+		return nil, errDerivationPath
+	}
+
+	result := make(DerivationPath, 0, len(elems)-1)
+	for _, elem := range elems[1:] {
+		hardened := strings.HasSuffix(elem, "'") || strings.HasSuffix(elem, "H")
+		if hardened {
+			elem = elem[:len(elem)-1]
+		}
+
+		index, err := strconv.ParseUint(elem, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation path index %v", elem)
+		}
+
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+
+		result = append(result, uint32(index))
+	}
+
+	return result, nil
+}
+
+// String implements the fmt.Stringer interface
+func (path DerivationPath) String() string {
+	result := "m"
+	for _, index := range path {
+		if index >= HardenedKeyStart {
+			result += fmt.Sprintf("/%d'", index-HardenedKeyStart)
+		} else {
+			result += fmt.Sprintf("/%d", index)
+		}
+	}
+
+	return result
+}
+
+// ExtendedKey is the BIP-32 extended private key
+type ExtendedKey struct {
+	key       *big.Int
+	chainCode []byte
+	depth     uint8
+}
+
+// NewMasterKey creates the master key from the seed, e.g. generated from mnemonic
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errInvalidSeedLength
+	}
+
+	mac := hmac.New(sha512.New, masterKeySecret)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(crypto.S256().Params().N) >= 0 {
+		return nil, errInvalidSeedLength
+	}
+
+	return &ExtendedKey{key, sum[32:], 0}, nil
+}
+
+// NewMasterKeyFromMnemonic creates the master key from the mnemonic and optional passphrase
+func NewMasterKeyFromMnemonic(mnemonic, passphrase string) (*ExtendedKey, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMasterKey(seed)
+}
+
+// compressedPubkey returns the compressed public key of the extended key
+func (k *ExtendedKey) compressedPubkey() []byte {
+	x, y := crypto.S256().ScalarBaseMult(math.PaddedBigBytes(k.key, 32))
+
+	pubkey := make([]byte, 33)
+	pubkey[0] = byte(0x2 + y.Bit(0))
+	copy(pubkey[1:], math.PaddedBigBytes(x, 32))
+
+	return pubkey
+}
+
+// Child derives the child key of the specified index, which is hardened if not less than HardenedKeyStart.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedKeyStart {
+		data = append(data, 0)
+		data = append(data, math.PaddedBigBytes(k.key, 32)...)
+	} else {
+		data = append(data, k.compressedPubkey()...)
+	}
+
+	indexBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(indexBytes, index)
+	data = append(data, indexBytes...)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, errInvalidChildKey
+	}
+
+	key := il.Add(il, k.key)
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, errInvalidChildKey
+	}
+
+	return &ExtendedKey{key, sum[32:], k.depth + 1}, nil
+}
+
+// Derive derives the child key along the path
+func (k *ExtendedKey) Derive(path DerivationPath) (*ExtendedKey, error) {
+	var err error
+	key := k
+
+	for _, index := range path {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// PrivateKey returns the ECDSA private key
+func (k *ExtendedKey) PrivateKey() (*ecdsa.PrivateKey, error) {
+	return crypto.ToECDSA(math.PaddedBigBytes(k.key, 32))
+}
+
+// Key returns the key with Scdo address
+func (k *ExtendedKey) Key() (*Key, error) {
+	privateKey, err := k.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		Address:    *crypto.GetAddress(&privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// DeriveShardKey walks the address index under the base path from the start index, until
+// the address of derived key falls in the requested shard. Returns the key and its full
+// path, the next index to walk is the last path index plus 1.
+func (k *ExtendedKey) DeriveShardKey(base DerivationPath, shard uint, start uint32) (*Key, DerivationPath, error) {
+	if shard == 0 || shard > common.ShardCount {
+		return nil, nil, fmt.Errorf("invalid shard number, should be between 1 and %v", common.ShardCount)
+	}
+
+	parent, err := k.Derive(base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for index := start; index < start+maxShardDerivation && index < HardenedKeyStart; index++ {
+		child, err := parent.Child(index)
+		if err == errInvalidChildKey {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		key, err := child.Key()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if key.Address.Shard() == shard {
+			path := append(append(DerivationPath{}, base...), index)
+			return key, path, nil
+		}
+	}
+
+	return nil, nil, errShardNotFound
+}
@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package keystore
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExtendedKey_Vector(t *testing.T) {
+	// test vector 1 of BIP-32
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	assert.Nil(t, err)
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35", hex.EncodeToString(math.PaddedBigBytes(master.key, 32)))
+	assert.Equal(t, "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", hex.EncodeToString(master.chainCode))
+
+	path, err := ParseDerivationPath("m/0'/1")
+	assert.Nil(t, err)
+	assert.Equal(t, "m/0'/1", path.String())
+
+	child, err := master.Derive(path[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea", hex.EncodeToString(math.PaddedBigBytes(child.key, 32)))
+
+	child, err = master.Derive(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368", hex.EncodeToString(math.PaddedBigBytes(child.key, 32)))
+	assert.Equal(t, uint8(2), child.depth)
+}
+
+func Test_ParseDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44'/541'/0'/0/3")
+	assert.Nil(t, err)
+	assert.Equal(t, append(DefaultBaseDerivationPath(0), 3), path)
+
+	_, err = ParseDerivationPath("44'/541'")
+	assert.Equal(t, errDerivationPath, err)
+
+	_, err = ParseDerivationPath("m/a")
+	assert.NotNil(t, err)
+}
+
+func Test_ExtendedKey_DeriveShardKey(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	master, err := NewMasterKeyFromMnemonic(mnemonic, "")
+	assert.Nil(t, err)
+
+	base := DefaultBaseDerivationPath(0)
+	for shard := uint(1); shard <= 4; shard++ {
+		key, path, err := master.DeriveShardKey(base, shard, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, shard, key.Address.Shard())
+
+		// the key is deterministic by path
+		child, err := master.Derive(path)
+		assert.Nil(t, err)
+		expected, err := child.Key()
+		assert.Nil(t, err)
+		assert.Equal(t, expected.Address, key.Address)
+
+		// walk from the next index
+		next, nextPath, err := master.DeriveShardKey(base, shard, path[len(path)-1]+1)
+		assert.Nil(t, err)
+		assert.Equal(t, shard, next.Address.Shard())
+		assert.True(t, nextPath[len(nextPath)-1] > path[len(path)-1])
+	}
+
+	_, _, err = master.DeriveShardKey(base, 5, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,235 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+var (
+	// ErrAccountNotFound is returned if no key file of the account in keystore directory
+	ErrAccountNotFound = errors.New("account not found in keystore")
+
+	// ErrAccountLocked is returned when signing with an account that is not unlocked
+	ErrAccountLocked = errors.New("account is locked")
+
+	errAccountExists = errors.New("account already exists in keystore")
+)
+
+// Account is an account with key file in the keystore directory
+type Account struct {
+	Address common.Address `json:"address"`
+	File    string         `json:"file"`
+}
+
+type unlockedKey struct {
+	key   *Key
+	timer *time.Timer // nil if unlocked until explicitly locked
+}
+
+// Manager manages the key files in a keystore directory, and keeps the unlocked keys in memory to sign transactions.
+type Manager struct {
+	dir      string
+	unlocked map[common.Address]*unlockedKey
+	lock     sync.RWMutex
+}
+
+// NewManager creates a manager of the keystore directory
+func NewManager(dir string) *Manager {
+	return &Manager{
+		dir:      dir,
+		unlocked: make(map[common.Address]*unlockedKey),
+	}
+}
+
+// Dir returns the keystore directory
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Accounts returns the accounts of key files in keystore directory sorted by file name.
+// The files that are not key files are ignored.
+func (m *Manager) Accounts() ([]Account, error) {
+	files, err := ioutil.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return make([]Account, 0), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	accounts := make([]Account, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || file.Name()[0] == '.' {
+			continue
+		}
+
+		path := filepath.Join(m.dir, file.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var key encryptedKey
+		if err = json.Unmarshal(content, &key); err != nil {
+			continue
+		}
+
+		address, err := common.HexToAddress(key.Address)
+		if err != nil {
+			continue
+		}
+
+		accounts = append(accounts, Account{address, path})
+	}
+
+	return accounts, nil
+}
+
+// Find returns the account of the specified address
+func (m *Manager) Find(address common.Address) (Account, error) {
+	accounts, err := m.Accounts()
+	if err != nil {
+		return Account{}, err
+	}
+
+	for _, account := range accounts {
+		if account.Address.Equal(address) {
+			return account, nil
+		}
+	}
+
+	return Account{}, ErrAccountNotFound
+}
+
+// NewAccount generates a random key in the specified shard and stores it encrypted with the passphrase
+func (m *Manager) NewAccount(shard uint, passphrase string) (Account, error) {
+	if shard == 0 || shard > common.ShardCount {
+		return Account{}, fmt.Errorf("invalid shard number, should be between 1 and %v", common.ShardCount)
+	}
+
+	address, privateKey := crypto.MustGenerateShardKeyPair(shard)
+	return m.Import(&Key{*address, privateKey}, passphrase)
+}
+
+// Import stores the key encrypted with the passphrase into keystore directory
+func (m *Manager) Import(key *Key, passphrase string) (Account, error) {
+	if _, err := m.Find(key.Address); err == nil {
+		return Account{}, errAccountExists
+	}
+
+	fileName := fmt.Sprintf("UTC--%v--%v", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"), hex.EncodeToString(key.Address.Bytes()))
+	path := filepath.Join(m.dir, fileName)
+	if err := StoreKey(path, passphrase, key); err != nil {
+		return Account{}, err
+	}
+
+	return Account{key.Address, path}, nil
+}
+
+// GetKey decrypts the key of account with the passphrase
+func (m *Manager) GetKey(address common.Address, passphrase string) (*Key, error) {
+	account, err := m.Find(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetKey(account.File, passphrase)
+}
+
+// Unlock decrypts the key of account and keeps it in memory for the specified duration,
+// or until the account is locked if the duration is 0.
+func (m *Manager) Unlock(address common.Address, passphrase string, duration time.Duration) error {
+	key, err := m.GetKey(address, passphrase)
+	if err != nil {
+		return err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if old, ok := m.unlocked[address]; ok && old.timer != nil {
+		old.timer.Stop()
+	}
+
+	unlocked := &unlockedKey{key: key}
+	if duration > 0 {
+		unlocked.timer = time.AfterFunc(duration, func() {
+			m.lock.Lock()
+			defer m.lock.Unlock()
+
+			// the account may be unlocked again before expired
+			if m.unlocked[address] == unlocked {
+				delete(m.unlocked, address)
+			}
+		})
+	}
+
+	m.unlocked[address] = unlocked
+	return nil
+}
+
+// Lock removes the key of account from memory
+func (m *Manager) Lock(address common.Address) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if unlocked, ok := m.unlocked[address]; ok {
+		if unlocked.timer != nil {
+			unlocked.timer.Stop()
+		}
+
+		delete(m.unlocked, address)
+	}
+}
+
+// IsUnlocked returns whether the account is unlocked
+func (m *Manager) IsUnlocked(address common.Address) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	_, ok := m.unlocked[address]
+	return ok
+}
+
+// SignTx signs the transaction with the unlocked key of sender
+func (m *Manager) SignTx(tx *types.Transaction) error {
+	m.lock.RLock()
+	unlocked, ok := m.unlocked[tx.Data.From]
+	m.lock.RUnlock()
+
+	if !ok {
+		return ErrAccountLocked
+	}
+
+	tx.Sign(unlocked.key.PrivateKey)
+	return nil
+}
+
+// SignTxWithPassphrase signs the transaction with the key of sender decrypted by the passphrase
+func (m *Manager) SignTxWithPassphrase(tx *types.Transaction, passphrase string) error {
+	key, err := m.GetKey(tx.Data.From, passphrase)
+	if err != nil {
+		return err
+	}
+
+	tx.Sign(key.PrivateKey)
+	return nil
+}
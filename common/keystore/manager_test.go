@@ -0,0 +1,72 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package keystore
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Manager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewManager(dir)
+	accounts, err := m.Accounts()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(accounts))
+
+	// files that are not key files are ignored
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "readme"), []byte("keys"), 0600))
+
+	account, err := m.NewAccount(2, "pass")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(2), account.Address.Shard())
+
+	accounts, err = m.Accounts()
+	assert.Nil(t, err)
+	assert.Equal(t, []Account{account}, accounts)
+
+	_, err = m.Import(&Key{Address: account.Address}, "pass")
+	assert.Equal(t, errAccountExists, err)
+
+	_, err = m.Find(*crypto.MustGenerateRandomAddress())
+	assert.Equal(t, ErrAccountNotFound, err)
+
+	to := *crypto.MustGenerateShardAddress(2)
+	tx, err := types.NewTransaction(account.Address, to, big.NewInt(1), big.NewInt(1), 1)
+	assert.Nil(t, err)
+
+	// sign with locked account
+	assert.Equal(t, ErrAccountLocked, m.SignTx(tx))
+	assert.NotNil(t, m.Unlock(account.Address, "bad", 0))
+
+	// unlock with timeout
+	assert.Nil(t, m.Unlock(account.Address, "pass", 100*time.Millisecond))
+	assert.True(t, m.IsUnlocked(account.Address))
+	assert.Nil(t, m.SignTx(tx))
+	assert.Nil(t, tx.ValidateWithoutState(true, false))
+
+	time.Sleep(300 * time.Millisecond)
+	assert.False(t, m.IsUnlocked(account.Address))
+
+	// unlock until locked
+	assert.Nil(t, m.Unlock(account.Address, "pass", 0))
+	assert.True(t, m.IsUnlocked(account.Address))
+	m.Lock(account.Address)
+	assert.False(t, m.IsUnlocked(account.Address))
+}
@@ -92,7 +92,7 @@ func (c *core) handleCommit(msg *message, src bft.Verifier) error {
 	fmt.Printf("commits size %d and state %+v (StateCommitted %d)\n", c.current.Commits.Size(), c.state, StateCommitted)
 
 	// if we already have enough commit and meanwhile not in committed state-> commit!
-	if c.current.Commits.Size() > 2*c.verSet.F() && c.state.Cmp(StateCommitted) < 0 {
+	if c.current.Commits.VotingPower() >= c.verSet.QuorumSize() && c.state.Cmp(StateCommitted) < 0 {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
 		c.log.Info("already got enough commits and not in committed state")
 		c.current.LockHash()
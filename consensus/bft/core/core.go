@@ -11,6 +11,7 @@ import (
 	"github.com/rcrowley/go-metrics"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/consensus/bft"
+	"github.com/scdoproject/go-stem/consensus/utils"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/log"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
@@ -44,6 +45,15 @@ type core struct {
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
+	// the first signed votes of verifiers near the current view
+	votes *utils.VoteSet
+
+	// the write-ahead log of consensus, nil if not supported by server
+	wal       *utils.WAL
+	replaying bool
+	// the hashes of the messages of current sequence in the write-ahead log
+	walMessages map[common.Hash]bool
+
 	consensusTimestamp time.Time
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
@@ -68,12 +78,17 @@ func NewCore(server bft.Server, config *bft.BFTConfig) Engine {
 		backlogsMu:         new(sync.Mutex),
 		pendingRequests:    prque.New(),
 		pendingRequestsMu:  new(sync.Mutex),
+		votes:              utils.NewVoteSet(),
+		walMessages:        make(map[common.Hash]bool),
 		consensusTimestamp: time.Time{},
 		roundMeter:         metrics.GetOrRegisterMeter("consensus/bft/core/round", nil),
 		sequenceMeter:      metrics.GetOrRegisterMeter("consensus/bft/core/sequence", nil),
 		consensusTimer:     metrics.GetOrRegisterTimer("consensus/bft/core/consensus", nil),
 	}
 	c.verifyFn = c.checkVerifierSignature
+	if provider, ok := server.(walProvider); ok {
+		c.wal = provider.WAL()
+	}
 	return c
 }
 
@@ -90,6 +105,11 @@ func (c *core) broadcast(msg *message) {
 		return
 	}
 
+	// persist the message before it is sent
+	if msg.Code != msgEvidence {
+		c.writeWAL(utils.WALMessage, payload)
+	}
+
 	// Broadcast payload
 	if err = c.server.Broadcast(c.verSet, payload); err != nil {
 		c.log.Error("Failed to broadcast message. msg %v. err %s. state %d", msg, err, c.state)
@@ -97,6 +117,11 @@ func (c *core) broadcast(msg *message) {
 	}
 }
 
+// committedSealSigner is the server that signs the committed seals to aggregate
+type committedSealSigner interface {
+	SignCommittedSeal(proposal bft.Proposal, seal []byte) ([]byte, error)
+}
+
 // finalizeMessage prepare the seal with proposal and sign data, return the payload with signature.
 func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 	var err error
@@ -104,7 +129,11 @@ func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 	msg.CommittedSeal = []byte{}
 	if msg.Code == msgCommit && c.current.Proposal() != nil {
 		seal := PrepareCommittedSeal(c.current.Proposal().Hash())
-		msg.CommittedSeal, err = c.server.Sign(seal)
+		if signer, ok := c.server.(committedSealSigner); ok {
+			msg.CommittedSeal, err = signer.SignCommittedSeal(c.current.Proposal(), seal)
+		} else {
+			msg.CommittedSeal, err = c.server.Sign(seal)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -147,7 +176,12 @@ func (c *core) commit() {
 	if proposal != nil {
 		committedSeals := make([][]byte, c.current.Commits.Size())
 		for i, v := range c.current.Commits.Values() {
-			committedSeals[i] = make([]byte, types.BftExtraSeal)
+			// the BLS seal follows the ecdsa seal after fork
+			size := types.BftExtraSeal
+			if len(v.CommittedSeal) > size && proposal.Height() >= common.BLSSealForkHeight {
+				size = len(v.CommittedSeal)
+			}
+			committedSeals[i] = make([]byte, size)
 			copy(committedSeals[i][:], v.CommittedSeal[:])
 		}
 
@@ -223,13 +257,15 @@ func (c *core) startNewRound(round *big.Int) {
 			Round:    new(big.Int),
 		}
 		c.verSet = c.server.Verifiers(lastProposal)
+		c.votes.Prune(newView.Sequence.Uint64())
+		c.truncateWAL(newView.Sequence.Uint64())
 	}
 	//clear up
 	c.roundChangeSet = newRoundChangeSet(c.verSet) //
 	// update roundState
 	c.updateRoundState(newView, c.verSet, rounChanged)
 	// calculate new proposer
-	c.verSet.CalcProposer(lastProposer, newView.Round.Uint64())
+	c.verSet.CalcProposer(lastProposer, newView.Sequence.Uint64(), newView.Round.Uint64())
 	c.waitingForRoundChange = false
 	c.setState(StateAcceptRequest)
 	if rounChanged && c.isProposer() && c.current != nil {
@@ -266,6 +302,7 @@ func (c *core) catchUpRound(view *bft.View) {
 		c.roundMeter.Mark(new(big.Int).Sub(view.Round, c.current.Round()).Int64())
 	}
 	c.waitingForRoundChange = true
+	c.writeWAL(utils.WALRound, view.Round.Bytes())
 
 	// Need to keep block locked for round catching up
 	c.updateRoundState(view, c.verSet, true)
@@ -27,6 +27,8 @@ var (
 	errDecodeCommit = errors.New("failed to decode COMMIT message")
 	// errDecodeMessageSet is returned when the message set is malformed.
 	errDecodeMessageSet = errors.New("failed to decode messageset")
+	// errDecodeEvidence is returned when the EVIDENCE message is malformed.
+	errDecodeEvidence = errors.New("failed to decode EVIDENCE message")
 
 	// ErrAddressUnauthorized is returned when given address cannot be found in
 	// current validator set.
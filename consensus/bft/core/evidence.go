@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package core
+
+import (
+	"github.com/scdoproject/go-stem/consensus/utils"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// detectDoubleSign records the vote of a signed message, and broadcasts the evidence
+// if the signer has voted for another proposal in the same step of round.
+func (c *core) detectDoubleSign(payload []byte) {
+	if c.current == nil {
+		return
+	}
+
+	ev := c.votes.Add(payload, c.current.Sequence().Uint64(), c.current.Round().Uint64())
+	if ev == nil {
+		return
+	}
+
+	if added, err := c.addEvidence(ev); err != nil || !added {
+		return
+	}
+
+	data, err := Encode(ev)
+	if err != nil {
+		c.log.Error("Failed to encode evidence. err %s", err)
+		return
+	}
+
+	c.broadcast(&message{
+		Code: msgEvidence,
+		Msg:  data,
+	})
+}
+
+// handleEvidence handles the evidence gossiped by other verifiers
+func (c *core) handleEvidence(msg *message) error {
+	var ev *types.DoubleSignEvidence
+	if err := msg.Decode(&ev); err != nil {
+		return errDecodeEvidence
+	}
+
+	added, err := c.addEvidence(ev)
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		return errOldMsg
+	}
+
+	return nil
+}
+
+func (c *core) addEvidence(ev *types.DoubleSignEvidence) (bool, error) {
+	handler, ok := c.server.(utils.EvidenceHandler)
+	if !ok {
+		return false, errMsgIgnored
+	}
+
+	added, err := handler.HandleEvidence(ev)
+	if err != nil {
+		c.log.Warn("Invalid double sign evidence. hash %s. err %s", ev.Hash(), err)
+		return false, err
+	}
+
+	if added {
+		c.log.Warn("Double sign evidence detected. hash %s", ev.Hash())
+	}
+
+	return added, nil
+}
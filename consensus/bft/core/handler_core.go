@@ -10,6 +10,8 @@ import (
 func (c *core) Start() error {
 	c.startNewRound(common.Big0)
 	c.subscribeEvents()
+	// resume the round state before restart
+	c.replayWAL()
 	go c.handleEvents()
 	return nil
 }
@@ -83,6 +85,7 @@ func (c *core) handleEvents() {
 						c.log.Warn("failed to get message payload with err %v", err)
 						continue
 					}
+					c.writeMessageWAL(e.msg, p)
 					c.server.Gossip(c.verSet, p)
 				}
 			}
@@ -121,7 +124,18 @@ func (c *core) handleMsg(payload []byte) error {
 		return ErrAddressUnauthorized
 	}
 	c.log.Info("[handleEvents]-2 msg %+v is checked successfully", msg.Code)
-	return c.handleCheckedMsg(msg, src)
+	if msg.Code == msgEvidence {
+		return c.handleEvidence(msg)
+	}
+
+	c.detectDoubleSign(payload)
+	if err := c.handleCheckedMsg(msg, src); err != nil {
+		return err
+	}
+
+	// own messages are persisted before broadcast
+	c.writeMessageWAL(msg, payload)
+	return nil
 }
 
 func (c *core) handleCheckedMsg(msg *message, src bft.Verifier) error {
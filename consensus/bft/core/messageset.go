@@ -35,6 +35,17 @@ func (ms *messageSet) Size() int {
 	return len(ms.messages)
 }
 
+// VotingPower returns the sum of voting powers of the message senders
+func (ms *messageSet) VotingPower() int {
+	ms.messagesMu.Lock()
+	defer ms.messagesMu.Unlock()
+	addrs := make([]common.Address, 0, len(ms.messages))
+	for addr := range ms.messages {
+		addrs = append(addrs, addr)
+	}
+	return int(ms.verSet.VotingPowerOf(addrs))
+}
+
 func (ms *messageSet) Add(msg *message) error {
 	ms.messagesMu.Lock()
 	defer ms.messagesMu.Unlock()
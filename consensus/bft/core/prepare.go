@@ -40,7 +40,7 @@ func (c *core) handlePrepare(msg *message, src bft.Verifier) error {
 	}
 	c.acceptPrepare(msg, src)
 
-	if ((c.current.IsHashLocked() && prepare.Digest == c.current.GetLockedHash()) || c.current.GetPrepareOrCommitSize() > 2*c.verSet.F()) &&
+	if ((c.current.IsHashLocked() && prepare.Digest == c.current.GetLockedHash()) || c.current.GetPrepareOrCommitPower() >= c.verSet.QuorumSize()) &&
 		c.state.Cmp(StatePrepared) < 0 {
 		c.log.Info("[DEBUG] after handle prepre msg, commit it")
 		c.current.LockHash()
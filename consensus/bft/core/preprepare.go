@@ -59,7 +59,7 @@ func (c *core) handlePreprepare(msg *message, src bft.Verifier) error {
 			// get all verifiers for this proposal
 			verSet := c.server.ParentVerifiers(preprepare.Proposal).Copy()
 			previousProposer := c.server.GetProposer(preprepare.Proposal.Height() - 1)
-			verSet.CalcProposer(previousProposer, preprepare.View.Round.Uint64())
+			verSet.CalcProposer(previousProposer, preprepare.View.Sequence.Uint64(), preprepare.View.Round.Uint64())
 			// proposer matches (sequence + round) && given block exists
 			// then broadcast commit
 			if verSet.IsProposer(src.Address()) && c.server.HasPropsal(preprepare.Proposal.Hash()) {
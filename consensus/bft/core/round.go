@@ -35,14 +35,14 @@ func newRoundChangeSet(verSet bft.VerifierSet) *roundChangeSet {
 	}
 }
 
-// MaxRound returns the max round of msgs among which the number of messages is equal or larger than num
-func (rcs *roundChangeSet) MaxRound(num int) *big.Int {
+// MaxRound returns the max round of msgs among which the voting power of messages is equal or larger than power
+func (rcs *roundChangeSet) MaxRound(power int) *big.Int {
 	rcs.mu.Lock()
 	defer rcs.mu.Unlock()
 
 	var maxRound *big.Int
 	for k, rms := range rcs.roundChanges {
-		if rms.Size() < num {
+		if rms.VotingPower() < power {
 			continue
 		}
 		r := big.NewInt(int64(k))
@@ -66,7 +66,7 @@ func (rcs *roundChangeSet) Add(r *big.Int, msg *message) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return rcs.roundChanges[round].Size(), nil
+	return rcs.roundChanges[round].VotingPower(), nil
 }
 
 // Clear deletes the messages with smaller round
@@ -146,25 +146,29 @@ func (c *core) handleRoundChange(msg *message, src bft.Verifier) error {
 	cv := c.currentView()
 	roundView := rc.View
 
-	num, err := c.roundChangeSet.Add(roundView.Round, msg)
+	power, err := c.roundChangeSet.Add(roundView.Round, msg)
 	if err != nil {
 		c.log.Warn("failed to add round change msg %v from %v with err %s", msg, src, err)
 		return err
 	}
-	c.log.Info("[TEST] core status waitingForRoundChange %t, currentView %+v, msgView %+v, roundChange number %d", c.waitingForRoundChange, cv, roundView, num)
-	// Once we received f+1 ROUND CHANGE messages, those messages form a weak certificate.
+	c.log.Info("[TEST] core status waitingForRoundChange %t, currentView %+v, msgView %+v, roundChange power %d", c.waitingForRoundChange, cv, roundView, power)
+	// whether the voting power reaches the threshold with the message of src
+	reached := func(threshold int) bool {
+		return power >= threshold && power-int(src.VotingPower()) < threshold
+	}
+	// Once we received f+1 ROUND CHANGE voting power, those messages form a weak certificate.
 	// If our round number is smaller than the certificate's round number, we would
 	// try to catch up the round number.
-	if c.waitingForRoundChange && num == int(c.verSet.F()+1) {
+	if c.waitingForRoundChange && reached(c.verSet.F()+1) {
 		if cv.Round.Cmp(roundView.Round) < 0 {
 			c.sendRoundChange(roundView.Round)
 			c.log.Warn("s1: receive F+1 round change message, catch up round")
 		}
 		return nil
-	} else if num == int(2*c.verSet.F()+1) && (c.waitingForRoundChange || cv.Round.Cmp(roundView.Round) < 0) {
-		// We've received 2f+1 ROUND CHANGE messages, start a new round immediately.
+	} else if reached(c.verSet.QuorumSize()) && (c.waitingForRoundChange || cv.Round.Cmp(roundView.Round) < 0) {
+		// We've received a quorum of ROUND CHANGE voting power, start a new round immediately.
 		c.startNewRound(roundView.Round)
-		c.log.Warn("s2: receive 2F+1 round change message, start a new round")
+		c.log.Warn("s2: receive a quorum of round change messages, start a new round")
 		return nil
 	} else if cv.Round.Cmp(roundView.Round) < 0 {
 		// Only gossip the message with current round to other verifiers.
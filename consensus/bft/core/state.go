@@ -129,6 +129,22 @@ func (s *roundState) GetPrepareOrCommitSize() int {
 	return result
 }
 
+// GetPrepareOrCommitPower returns the voting power of verifiers that sent PREPARE or COMMIT
+func (s *roundState) GetPrepareOrCommitPower() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := s.Prepares.VotingPower() + s.Commits.VotingPower()
+
+	// find duplicate one
+	for _, m := range s.Prepares.Values() {
+		if s.Commits.Get(m.Address) != nil {
+			result -= int(s.Commits.verSet.VotingPowerOf([]common.Address{m.Address}))
+		}
+	}
+	return result
+}
+
 func (s *roundState) SetPreprepare(preprepare *bft.Preprepare) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -10,6 +10,7 @@ const (
 	msgPrepare
 	msgCommit
 	msgRoundChange
+	msgEvidence
 	msgAll
 )
 
@@ -0,0 +1,122 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/consensus/bft"
+	"github.com/scdoproject/go-stem/consensus/utils"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// walProvider is the server that persists the write-ahead log of consensus
+type walProvider interface {
+	WAL() *utils.WAL
+}
+
+// writeWAL appends the entry of current sequence into the write-ahead log, which must be
+// done before the message is sent or handled.
+func (c *core) writeWAL(kind byte, data []byte) {
+	if c.wal == nil || c.replaying || c.current == nil {
+		return
+	}
+
+	if err := c.wal.Append(c.current.Sequence().Uint64(), kind, data); err != nil {
+		c.log.Error("Failed to write consensus WAL. err %s", err)
+	}
+}
+
+// writeMessageWAL appends the accepted message of other validators into the write-ahead log.
+// Only the messages of current sequence are persisted, and each of them is persisted once.
+func (c *core) writeMessageWAL(msg *message, payload []byte) {
+	if c.wal == nil || c.replaying || c.current == nil || msg.Address == c.address {
+		return
+	}
+
+	// both preprepare and subject messages start with the view
+	var view struct {
+		View *bft.View
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+	if err := msg.Decode(&view); err != nil || view.View == nil || view.View.Sequence.Cmp(c.current.Sequence()) != 0 {
+		return
+	}
+
+	hash := crypto.HashBytes(payload)
+	if c.walMessages[hash] {
+		return
+	}
+
+	c.walMessages[hash] = true
+	c.writeWAL(utils.WALMessage, payload)
+}
+
+// truncateWAL drops the entries of the committed sequences
+func (c *core) truncateWAL(sequence uint64) {
+	c.walMessages = make(map[common.Hash]bool)
+	if c.wal == nil {
+		return
+	}
+
+	if err := c.wal.Truncate(sequence); err != nil {
+		c.log.Error("Failed to truncate consensus WAL. err %s", err)
+	}
+}
+
+// replayWAL replays the write-ahead log of current sequence, so that the verifier resumes
+// the round, lock and votes before restart.
+func (c *core) replayWAL() {
+	if c.wal == nil {
+		return
+	}
+
+	sequence := c.current.Sequence()
+	entries, err := c.wal.Entries(sequence.Uint64())
+	if err != nil {
+		c.log.Error("Failed to read consensus WAL. err %s", err)
+		return
+	}
+
+	c.replaying = true
+	defer func() {
+		c.replaying = false
+	}()
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case utils.WALMessage:
+			c.walMessages[crypto.HashBytes(entry.Data)] = true
+
+			msg := new(message)
+			if err := msg.ValidatePayload(entry.Data, c.verifyFn); err != nil {
+				continue
+			}
+
+			_, src := c.verSet.GetVerByAddress(msg.Address)
+			if src == nil {
+				continue
+			}
+
+			c.detectDoubleSign(entry.Data)
+			c.handleCheckedMsg(msg, src)
+		case utils.WALRound:
+			round := new(big.Int).SetBytes(entry.Data)
+			if round.Cmp(c.current.Round()) > 0 {
+				c.catchUpRound(&bft.View{
+					Sequence: new(big.Int).Set(sequence),
+					Round:    round,
+				})
+			}
+		}
+	}
+
+	if len(entries) > 0 {
+		c.log.Info("Replayed consensus WAL. entries %d. seq %d. round %d. locked %t", len(entries), sequence, c.current.Round(), c.current.IsHashLocked())
+	}
+}
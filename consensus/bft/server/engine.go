@@ -171,6 +171,19 @@ func (s *server) Prepare(chain consensus.ChainReader, header *types.BlockHeader)
 	}
 	header.ExtraData = extra
 
+	// include the double sign evidence of verifiers in snapshot
+	if height >= common.EvidenceForkHeight {
+		evidence := s.evidence.Pending(height, func(address common.Address) bool {
+			_, v := snap.VerSet.GetVerByAddress(address)
+			return v != nil
+		})
+		if len(evidence) > 0 {
+			if err = writeEvidence(header, evidence); err != nil {
+				return err
+			}
+		}
+	}
+
 	// set timeStamp at header
 	header.CreateTimestamp = new(big.Int).Add(parent.CreateTimestamp, new(big.Int).SetUint64(s.config.BlockPeriod))
 	// but if creatTimestamp is smaller than current. set to current!
@@ -15,8 +15,12 @@ import (
 	"github.com/scdoproject/go-stem/consensus/bft"
 	bftCore "github.com/scdoproject/go-stem/consensus/bft/core"
 	"github.com/scdoproject/go-stem/consensus/bft/verifier"
+	"github.com/scdoproject/go-stem/consensus/utils"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/state"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bls"
 )
 
 const (
@@ -51,6 +55,9 @@ var (
 	errEmptyCommittedSeals = errors.New("zero committed seals")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 	errMismatchTxhashes = errors.New("mismatch transcations hashes")
+	// errInvalidEvidence is returned if the double sign evidence in header is invalid or its
+	// offender is not an authorized verifier.
+	errInvalidEvidence = errors.New("invalid double sign evidence")
 	// errProposalInvalid is returned when a prposal is malformed.
 	errProposalInvalid = errors.New("invalid proposal")
 	// errInvalidSignature is returned when given signature is not signed by given
@@ -260,10 +267,38 @@ func (s *server) verifyBFTCore(chain consensus.ChainReader, header *types.BlockH
 	if err := s.verifySigner(chain, header, parents); err != nil {
 		return err
 	}
+	if err := verifyEvidence(header, snap); err != nil {
+		return err
+	}
 	// verify committed seals
 	return s.verifyCommittedSeals(chain, header, parents)
 }
 
+// verifyEvidence checks the double sign evidence in header is valid, and the offenders are
+// distinct verifiers in the parent snapshot. No evidence is allowed before EvidenceForkHeight.
+func verifyEvidence(header *types.BlockHeader, snap *Snapshot) error {
+	evidence, err := types.HeaderEvidence(header)
+	if err != nil {
+		return err
+	}
+	if len(evidence) > utils.MaxBlockEvidence || (len(evidence) > 0 && header.Height < common.EvidenceForkHeight) {
+		return errInvalidEvidence
+	}
+
+	offenders := make(map[common.Address]bool)
+	for _, ev := range evidence {
+		vote, err := ev.Verify()
+		if err != nil || vote.Height >= header.Height || offenders[vote.Signer] {
+			return errInvalidEvidence
+		}
+		if _, v := snap.VerSet.GetVerByAddress(vote.Signer); v == nil {
+			return errInvalidEvidence
+		}
+		offenders[vote.Signer] = true
+	}
+	return nil
+}
+
 // verifyCommittedSeals checks whether every committed seal is signed by one of the parent's validators
 func (s *server) verifyCommittedSeals(chain consensus.ChainReader, header *types.BlockHeader, parents []*types.BlockHeader) error {
 	// check height, if 0 (genesis) return nil
@@ -281,13 +316,33 @@ func (s *server) verifyCommittedSeals(chain consensus.ChainReader, header *types
 	if err != nil {
 		return err
 	}
+	proposalSeal := bftCore.PrepareCommittedSeal(header.Hash())
+	// the committed seals are aggregated into one BLS signature after fork
+	if number >= common.BLSSealForkHeight {
+		if len(extra.CommittedSeal) > 0 || len(extra.AggregatedSeal) == 0 {
+			return errCommittedSealsInvalid
+		}
+
+		sealers, err := utils.VerifyAggregatedSeal(snap.verifiers(), snap.BLSKeys, extra.SignerBitmap, extra.AggregatedSeal, proposalSeal)
+		if err != nil {
+			return err
+		}
+
+		if int(snap.VerSet.VotingPowerOf(sealers)) < sealQuorum(snap.VerSet, number) {
+			return errCommittedSealsInvalid
+		}
+		return nil
+	}
+	if len(extra.AggregatedSeal) > 0 || len(extra.SignerBitmap) > 0 {
+		return errCommittedSealsInvalid
+	}
 	// if extra is empty, return error
 	if len(extra.CommittedSeal) == 0 {
 		return errEmptyCommittedSeals
 	}
 	verifiers := snap.VerSet.Copy()
 	validSealCount := 0
-	proposalSeal := bftCore.PrepareCommittedSeal(header.Hash())
+	var sealers []common.Address
 	// 1. get committed seals from current header
 	for _, seal := range extra.CommittedSeal {
 		addr, err := bft.GetSignatureAddress(proposalSeal, seal)
@@ -297,6 +352,7 @@ func (s *server) verifyCommittedSeals(chain consensus.ChainReader, header *types
 		}
 		if verifiers.RemoveVerifier(addr) {
 			validSealCount++
+			sealers = append(sealers, addr)
 		} else {
 			return errCommittedSealsInvalid
 		}
@@ -309,10 +365,13 @@ func (s *server) verifyCommittedSeals(chain consensus.ChainReader, header *types
 	s.log.Debug("%d verifiers", snap.VerSet.Size())
 	// 2. The length of validSeal should be larger than number of faulty node + 1
 	// if validSealCount <= 2*snap.VerSet.F() { // FIXME <= or <??
-	s.log.Info("Tally: validSealCount: %d require: %d", validSealCount, 2*snap.VerSet.F())
+	// the seals are weighted by the voting power of verifiers
+	sealPower := int(snap.VerSet.VotingPowerOf(sealers))
+	quorum := sealQuorum(snap.VerSet, number)
+	s.log.Info("Tally: validSealCount: %d, power: %d require: %d", validSealCount, sealPower, quorum)
 
-	if validSealCount < 2*snap.VerSet.F() {
-		s.log.Debug("validSealCount ", validSealCount, "require ", 2*snap.VerSet.F())
+	if sealPower < quorum {
+		s.log.Debug("seal power %d, require %d", sealPower, quorum)
 		return errCommittedSealsInvalid
 	}
 	return nil
@@ -510,6 +569,20 @@ func (ser *server) snapshot(chain consensus.ChainReader, height uint64, hash com
 				ser.log.Info("after remove one verifier, snap verset %+v", snap.verifiers())
 			}
 
+			// refresh the voting powers and BLS public keys with the stakes at the last epoch block
+			if stakes := stakes(chain); stakes != nil {
+				epochHeader := lastEpochHeader(chain, hash, height-height%ser.config.Epoch)
+				if epochHeader == nil {
+					return nil, errBlockUnknown
+				}
+				weights, keys, err := stakes(epochHeader, snap.verifiers())
+				if err != nil {
+					return nil, err
+				}
+				snap.VerSet.SetVotingPowers(weights)
+				snap.BLSKeys = keys
+			}
+
 			ser.log.Debug("snap verset %+v", snap.verifiers())
 
 			if err := snap.save(ser.db); err != nil {
@@ -542,7 +615,7 @@ func (ser *server) snapshot(chain consensus.ChainReader, height uint64, hash com
 		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
 	}
 	ser.log.Debug("before applying len=%d headers, snapshot %+v", len(headers), snap)
-	snap, err := snap.applyHeaders(headers)
+	snap, err := snap.applyHeaders(headers, stakes(chain))
 	if err != nil {
 		return nil, err
 	}
@@ -559,6 +632,38 @@ func (ser *server) snapshot(chain consensus.ChainReader, height uint64, hash com
 	return snap, err
 }
 
+// stateReader is the chain that provides the statedb of blocks
+type stateReader interface {
+	GetState(root common.Hash) (*state.Statedb, error)
+}
+
+// stakes returns the function to read the voting powers and BLS public keys of verifiers
+// from the staking contract, or nil if the chain could not provide the statedb.
+func stakes(chain consensus.ChainReader) stakeFunc {
+	reader, ok := chain.(stateReader)
+	if !ok {
+		return nil
+	}
+
+	return func(header *types.BlockHeader, verifiers []common.Address) (map[common.Address]uint64, map[common.Address][]byte, error) {
+		if header.Height < common.StakingForkHeight {
+			return nil, nil, nil
+		}
+
+		statedb, err := reader.GetState(header.StateHash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		powers, err := system.GetVotingPowers(statedb, header.Height, verifiers)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return powers, system.GetBLSPublicKeys(statedb, verifiers), nil
+	}
+}
+
 // prepareExtra returns a extra-data of the given header and validators
 func prepareExtra(header *types.BlockHeader, vers []common.Address) ([]byte, error) {
 	var buf bytes.Buffer
@@ -622,6 +727,23 @@ func writeSeal(h *types.BlockHeader, seal []byte) error {
 	return nil
 }
 
+// writeEvidence writes the extra-data field of the given header with the double sign evidence.
+func writeEvidence(h *types.BlockHeader, evidence []*types.DoubleSignEvidence) error {
+	bftExtra, err := types.ExtractBftExtra(h)
+	if err != nil {
+		return err
+	}
+
+	bftExtra.Evidence = evidence
+	payload, err := rlp.EncodeToBytes(&bftExtra)
+	if err != nil {
+		return err
+	}
+
+	h.ExtraData = append(h.ExtraData[:types.BftExtraVanity], payload...)
+	return nil
+}
+
 func writeCommittedSeals(h *types.BlockHeader, committedSeals [][]byte) error {
 	if len(committedSeals) == 0 {
 		return errCommittedSealsInvalid
@@ -649,3 +771,94 @@ func writeCommittedSeals(h *types.BlockHeader, committedSeals [][]byte) error {
 	h.ExtraData = append(h.ExtraData[:types.BftExtraVanity], payload...)
 	return nil
 }
+
+// lastEpochHeader returns the ancestor at the epoch height of the header of hash, which is
+// walked back by parent hash so that the header on a side fork gets the epoch block of its own.
+func lastEpochHeader(chain consensus.ChainReader, hash common.Hash, epochHeight uint64) *types.BlockHeader {
+	header := chain.GetHeaderByHash(hash)
+	for header != nil && header.Height > epochHeight {
+		header = chain.GetHeaderByHash(header.PreviousBlockHash)
+	}
+	return header
+}
+
+// sealQuorum returns the minimum voting power of the committed seals of the header at height,
+// and the headers before the staking fork keep the legacy rule of at least 2F.
+func sealQuorum(verSet bft.VerifierSet, height uint64) int {
+	if height < common.StakingForkHeight {
+		return 2 * verSet.F()
+	}
+	return verSet.QuorumSize()
+}
+
+// writeAggregatedSeal writes the extra-data field of the given header with the BLS aggregated
+// seal of the committed seals, which are the ecdsa seals followed by the BLS seals. The seals
+// of unknown verifiers or invalid BLS seals are dropped, and it returns an error if the
+// remaining seals are not enough.
+func writeAggregatedSeal(h *types.BlockHeader, committedSeals [][]byte, snap *Snapshot) error {
+	proposalSeal := bftCore.PrepareCommittedSeal(h.Hash())
+	seals := make(map[common.Address][]byte)
+	var sealers []common.Address
+	for _, seal := range committedSeals {
+		if len(seal) != types.BftExtraSeal+bls.SignatureSize {
+			continue
+		}
+
+		addr, err := bft.GetSignatureAddress(proposalSeal, seal[:types.BftExtraSeal])
+		if err != nil {
+			continue
+		}
+
+		if _, v := snap.VerSet.GetVerByAddress(addr); v == nil || seals[addr] != nil {
+			continue
+		}
+
+		blsSeal := seal[types.BftExtraSeal:]
+		if !verifyBLSSeal(snap.BLSKeys[addr], proposalSeal, blsSeal) {
+			continue
+		}
+
+		seals[addr] = blsSeal
+		sealers = append(sealers, addr)
+	}
+
+	if int(snap.VerSet.VotingPowerOf(sealers)) < sealQuorum(snap.VerSet, h.Height) {
+		return errCommittedSealsInvalid
+	}
+
+	aggregated, bitmap, err := utils.AggregateCommittedSeals(snap.verifiers(), seals)
+	if err != nil {
+		return err
+	}
+
+	bftExtra, err := types.ExtractBftExtra(h)
+	if err != nil {
+		return err
+	}
+
+	bftExtra.CommittedSeal = [][]byte{}
+	bftExtra.AggregatedSeal, bftExtra.SignerBitmap = aggregated, bitmap
+
+	payload, err := rlp.EncodeToBytes(&bftExtra)
+	if err != nil {
+		return err
+	}
+
+	h.ExtraData = append(h.ExtraData[:types.BftExtraVanity], payload...)
+	return nil
+}
+
+// verifyBLSSeal returns whether the BLS seal is signed by the registered public key
+func verifyBLSSeal(key, msg, seal []byte) bool {
+	pk, err := bls.PublicKeyFromBytes(key)
+	if err != nil {
+		return false
+	}
+
+	sig, err := bls.SignatureFromBytes(seal)
+	if err != nil {
+		return false
+	}
+
+	return pk.Verify(msg, sig)
+}
@@ -169,7 +169,7 @@ func newBlockWithoutSeal(chain *core.Blockchain, engine *server, parent *types.B
 		panic(err)
 	}
 
-	rewardTxReceipt, err := txs.ApplyRewardTx(rewardTx, state)
+	rewardTxReceipt, err := txs.ApplyRewardTx(rewardTx, state, header)
 
 	// new statehash with rewardtx
 	header.StateHash, err = state.Hash()
@@ -14,8 +14,10 @@ import (
 	"github.com/scdoproject/go-stem/consensus/bft"
 	BFT "github.com/scdoproject/go-stem/consensus/bft"
 	bftCore "github.com/scdoproject/go-stem/consensus/bft/core"
+	"github.com/scdoproject/go-stem/consensus/utils"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bls"
 	"github.com/scdoproject/go-stem/database"
 	"github.com/scdoproject/go-stem/log"
 )
@@ -24,6 +26,7 @@ type server struct {
 	config       *bft.BFTConfig
 	bftEventMux  *event.TypeMux
 	privateKey   *ecdsa.PrivateKey
+	blsKey       *bls.SecretKey // derived from the private key to sign the BLS committed seals
 	address      common.Address
 	core         bftCore.Engine
 	log          *log.ScdoLog
@@ -51,10 +54,15 @@ type server struct {
 
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+
+	evidence *utils.EvidencePool // the double sign evidence to include in blocks
+	wal      *utils.WAL          // the write-ahead log of consensus round state
 }
 
 const (
 	engineTypeID = "bft"
+	// walPrefix is the key prefix of consensus write-ahead log in db
+	walPrefix = "bft-wal"
 )
 
 /*
@@ -114,6 +122,7 @@ func NewServer(config *BFT.BFTConfig, privateKey *ecdsa.PrivateKey, db database.
 		config:         config,
 		bftEventMux:    new(event.TypeMux),
 		privateKey:     privateKey,
+		blsKey:         bls.DeriveKey(crypto.FromECDSA(privateKey)),
 		address:        crypto.PubkeyToAddress(privateKey.PublicKey),
 		log:            log.GetLogger("bft"),
 		db:             db,
@@ -123,7 +132,14 @@ func NewServer(config *BFT.BFTConfig, privateKey *ecdsa.PrivateKey, db database.
 		coreStarted:    false,
 		recentMessages: recentMessages,
 		knownMessages:  knownMessages,
+		evidence:       utils.NewEvidencePool(),
+	}
+
+	wal, err := utils.NewWAL(db, walPrefix)
+	if err != nil {
+		server.log.Error("failed to load consensus WAL, err %s", err)
 	}
+	server.wal = wal
 	server.core = bftCore.NewCore(server, server.config)
 	return server
 }
@@ -257,8 +273,16 @@ func (s *server) Commit(proposal bft.Proposal, seals [][]byte) error {
 
 	h := block.Header
 
-	//2. append seals into extraData
-	errSeal := writeCommittedSeals(h, seals) //
+	//2. append seals into extraData, which are aggregated after fork
+	var errSeal error
+	if h.Height >= common.BLSSealForkHeight {
+		var snap *Snapshot
+		if snap, errSeal = s.snapshot(s.chain, h.Height-1, h.PreviousBlockHash, nil); errSeal == nil {
+			errSeal = writeAggregatedSeal(h, seals, snap)
+		}
+	} else {
+		errSeal = writeCommittedSeals(h, seals)
+	}
 	if errSeal != nil {
 		return errSeal
 	}
@@ -292,6 +316,11 @@ func (s *server) Commit(proposal bft.Proposal, seals [][]byte) error {
 	return nil
 }
 
+// txLimitVerifier is the chain that limits the txs of accounts in each relay range
+type txLimitVerifier interface {
+	VerifyTxLimit(block *types.Block) error
+}
+
 // Verify verifies the proposal. If a consensus.ErrBlockCreateTimeOld error is returned,
 // the time difference of the proposal and current time is also returned.
 func (s *server) Verify(proposal bft.Proposal) (time.Duration, error) {
@@ -309,6 +338,14 @@ func (s *server) Verify(proposal bft.Proposal) (time.Duration, error) {
 		return 0, errMismatchTxhashes
 	}
 
+	// check the txs of accounts in the relay range of subchain
+	if verifier, ok := s.chain.(txLimitVerifier); ok {
+		if err := verifier.VerifyTxLimit(block); err != nil {
+			s.log.Error("proposal exceeds the tx limit, %s", err)
+			return 0, err
+		}
+	}
+
 	// 3.  verify the header of proposed block
 	err := s.VerifyHeader(s.chain, block.Header)
 	// ignore errEmptyCommittedSeals error because we don't have the committed seals yet
@@ -327,6 +364,34 @@ func (s *server) Sign(data []byte) ([]byte, error) {
 	return sign.Sig, err
 }
 
+// SignCommittedSeal signs the committed seal of proposal, which is followed by the BLS seal
+// after fork to be aggregated into the header.
+func (s *server) SignCommittedSeal(proposal bft.Proposal, seal []byte) ([]byte, error) {
+	sig, err := s.Sign(seal)
+	if err != nil || proposal.Height() < common.BLSSealForkHeight {
+		return sig, err
+	}
+
+	return append(sig, s.blsKey.Sign(seal).Bytes()...), nil
+}
+
+// BLSPublicKey returns the BLS public key and the proof of possession to register in the
+// staking contract.
+func (s *server) BLSPublicKey() ([]byte, []byte) {
+	return s.blsKey.PublicKey().Bytes(), s.blsKey.ProvePossession().Bytes()
+}
+
+// WAL returns the write-ahead log of consensus round state
+func (s *server) WAL() *utils.WAL {
+	return s.wal
+}
+
+// HandleEvidence adds the double sign evidence detected by core into pool, and returns
+// false if the evidence is known.
+func (s *server) HandleEvidence(ev *types.DoubleSignEvidence) (bool, error) {
+	return s.evidence.Add(ev)
+}
+
 // CheckSignature verifies the signature by checking if it's signed by
 // the given Verifier
 func (s *server) CheckSignature(data []byte, addr common.Address, sig []byte) error {
@@ -44,8 +44,14 @@ type Snapshot struct {
 	Votes  []*Vote                  // List of votes cast in chronological order
 	Tally  map[common.Address]Tally // Current vote tally to avoid recalculating
 	VerSet bft.VerifierSet          // Set of authorized verifiers at this moment
+
+	BLSKeys map[common.Address][]byte // Registered BLS public keys of verifiers at the last checkpoint
 }
 
+// stakeFunc returns the voting powers and the registered BLS public keys of verifiers in the
+// staking contract at the epoch block. The powers are nil if the verifiers are equally weighted.
+type stakeFunc func(header *types.BlockHeader, verifiers []common.Address) (map[common.Address]uint64, map[common.Address][]byte, error)
+
 // newSnapshot create a new snapshot with the specified startup parameters. This
 // method does not initialize the set of recent verifiers, so only ever use if for
 // the genesis block.
@@ -95,6 +101,13 @@ func (s *Snapshot) copy() *Snapshot {
 		Tally:  make(map[common.Address]Tally),
 	}
 
+	if s.BLSKeys != nil {
+		cpy.BLSKeys = make(map[common.Address][]byte)
+		for address, key := range s.BLSKeys {
+			cpy.BLSKeys[address] = key
+		}
+	}
+
 	for address, tally := range s.Tally {
 		cpy.Tally[address] = tally
 	}
@@ -147,8 +160,9 @@ func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
 }
 
 // applyHeaders creates a new authorization snapshot by applying the given headers to
-// the original one.
-func (s *Snapshot) applyHeaders(headers []*types.BlockHeader) (*Snapshot, error) {
+// the original one. The voting powers and BLS public keys of verifiers are refreshed at
+// the checkpoint blocks if stakes is not nil.
+func (s *Snapshot) applyHeaders(headers []*types.BlockHeader, stakes stakeFunc) (*Snapshot, error) {
 	snap := s.copy()
 	// verTests := []common.Address{
 	// 	common.BytesToAddress(hexutil.MustHexToBytes("0xcee66ad4a1909f6b5170dec230c1a69bfc2b21d1")),
@@ -260,6 +274,31 @@ func (s *Snapshot) applyHeaders(headers []*types.BlockHeader) (*Snapshot, error)
 			delete(snap.Tally, header.Creator)
 		}
 
+		// Remove the verifiers proven to double sign by the evidence in header
+		if number >= common.EvidenceForkHeight {
+			evidence, err := types.HeaderEvidence(header)
+			if err != nil {
+				return nil, err
+			}
+			for _, ev := range evidence {
+				vote, err := ev.Verify()
+				if err != nil {
+					return nil, err
+				}
+				snap.removeOffender(vote.Signer)
+			}
+		}
+
+		// Refresh the voting powers and BLS public keys with the stakes at checkpoint block
+		if number%s.Epoch == 0 && stakes != nil {
+			weights, keys, err := stakes(header, snap.verifiers())
+			if err != nil {
+				return nil, err
+			}
+			snap.VerSet.SetVotingPowers(weights)
+			snap.BLSKeys = keys
+		}
+
 		// here we will check header secondwitness to add or remove verifiers from deposit txs and exit txs (if any)
 		// var swExtra *types.SecondWitnessExtra
 		// if err := rlp.DecodeBytes(header.SecondWitness, &swExtra); err != nil {
@@ -280,6 +319,25 @@ func (s *Snapshot) applyHeaders(headers []*types.BlockHeader) (*Snapshot, error)
 	return snap, nil
 }
 
+// removeOffender removes the verifier from the verifier set, and discards the votes cast
+// by or around it.
+func (s *Snapshot) removeOffender(address common.Address) {
+	if !s.VerSet.RemoveVerifier(address) {
+		return
+	}
+
+	for i := 0; i < len(s.Votes); i++ {
+		if s.Votes[i].Verifier == address {
+			s.uncast(s.Votes[i].Address, s.Votes[i].Authorize)
+		}
+		if s.Votes[i].Verifier == address || s.Votes[i].Address == address {
+			s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+			i--
+		}
+	}
+	delete(s.Tally, address)
+}
+
 // verifiers retrieves the list of authorized verifiers in ascending order.
 func (s *Snapshot) verifiers() []common.Address {
 	verifiers := make([]common.Address, 0, s.VerSet.Size())
@@ -304,19 +362,23 @@ type snapshotJSON struct {
 	Tally  map[common.Address]Tally `json:"tally"`
 
 	// for verifier set
-	Verifiers []common.Address   `json:"verifiers"`
-	Policy    bft.ProposerPolicy `json:"policy"`
+	Verifiers    []common.Address          `json:"verifiers"`
+	Policy       bft.ProposerPolicy        `json:"policy"`
+	VotingPowers map[common.Address]uint64 `json:"votingPowers,omitempty"` // empty if equally weighted
+	BLSKeys      map[common.Address][]byte `json:"blsKeys,omitempty"`
 }
 
 func (s *Snapshot) toJSONStruct() *snapshotJSON {
 	return &snapshotJSON{
-		Epoch:     s.Epoch,
-		Number:    s.Height,
-		Hash:      s.Hash,
-		Votes:     s.Votes,
-		Tally:     s.Tally,
-		Verifiers: s.verifiers(),
-		Policy:    s.VerSet.Policy(),
+		Epoch:        s.Epoch,
+		Number:       s.Height,
+		Hash:         s.Hash,
+		Votes:        s.Votes,
+		Tally:        s.Tally,
+		Verifiers:    s.verifiers(),
+		Policy:       s.VerSet.Policy(),
+		VotingPowers: s.VerSet.VotingPowers(),
+		BLSKeys:      s.BLSKeys,
 	}
 }
 
@@ -333,6 +395,10 @@ func (s *Snapshot) UnmarshalJSON(b []byte) error {
 	s.Votes = j.Votes
 	s.Tally = j.Tally
 	s.VerSet = verifier.NewVerifierSet(j.Verifiers, j.Policy)
+	if len(j.VotingPowers) > 0 {
+		s.VerSet.SetVotingPowers(j.VotingPowers)
+	}
+	s.BLSKeys = j.BLSKeys
 	return nil
 }
 
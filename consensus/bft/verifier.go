@@ -8,7 +8,8 @@ import (
 
 type Verifier interface {
 	Address() common.Address
-	String() string // representation of verifier
+	VotingPower() uint64 // weight of verifier to reach quorum
+	String() string      // representation of verifier
 }
 
 type Verifiers []Verifier
@@ -27,8 +28,8 @@ func (verifiers Verifiers) Swap(i, j int) {
 }
 
 type VerifierSet interface {
-	// Calculate the proposer
-	CalcProposer(lastProposer common.Address, round uint64)
+	// Calculate the proposer of the sequence and round
+	CalcProposer(lastProposer common.Address, sequence uint64, round uint64)
 	// Return the Verifier size
 	Size() int
 	// Return the Verifier array
@@ -47,8 +48,18 @@ type VerifierSet interface {
 	RemoveVerifier(address common.Address) bool
 	// Copy Verifier set
 	Copy() VerifierSet
-	// Get the maximum number of faulty nodes
+	// Set the voting powers of verifiers, the verifiers are equally weighted if powers is empty
+	SetVotingPowers(powers map[common.Address]uint64)
+	// Return the voting powers of verifiers, or nil if equally weighted
+	VotingPowers() map[common.Address]uint64
+	// Return the sum of voting powers
+	TotalVotingPower() uint64
+	// Return the sum of voting powers of the given addresses in the set
+	VotingPowerOf(addrs []common.Address) uint64
+	// Get the maximum voting power of faulty nodes
 	F() int
+	// Get the minimum voting power of a quorum, which is ceil(2T/3) of the total voting power T
+	QuorumSize() int
 	// Get proposer policy
 	Policy() ProposerPolicy
 }
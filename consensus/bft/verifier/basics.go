@@ -1,6 +1,7 @@
 package verifier
 
 import (
+	"math/bits"
 	"reflect"
 	"sort"
 	"sync"
@@ -12,13 +13,18 @@ import (
 //////////////////////////////////////////////////////
 // basicVerifier
 type basicVerifier struct {
-	address common.Address
+	address     common.Address
+	votingPower uint64
 }
 
 func (ver *basicVerifier) Address() common.Address {
 	return ver.address
 }
 
+func (ver *basicVerifier) VotingPower() uint64 {
+	return ver.votingPower
+}
+
 func (ver *basicVerifier) String() string {
 	return ver.Address().String()
 }
@@ -31,6 +37,7 @@ type basicSet struct {
 	proposer   bft.Verifier
 	verifierMu sync.RWMutex
 	selector   bft.ProposalSelector
+	weighted   bool // whether the verifiers are weighted by stake, otherwise the voting power is 1
 }
 
 // newBasicSet create verSet with addresses, policy, selector
@@ -121,6 +128,49 @@ func roundRobinProposer(verSet bft.VerifierSet, proposer common.Address, round u
 	return verSet.GetVerByIndex(pick)
 }
 
+// weightedRoundRobinProposer picks the proposer of slot in the schedule of verifiers weighted by
+// voting power, in which every verifier proposes as many times as its voting power in each cycle
+// of total voting power slots.
+func weightedRoundRobinProposer(verifiers bft.Verifiers, slot uint64) bft.Verifier {
+	total := uint64(0)
+	for _, ver := range verifiers {
+		total += ver.VotingPower()
+	}
+	if total == 0 {
+		return nil
+	}
+	pick := spreadSlot(slot%total, total)
+	for _, ver := range verifiers {
+		if pick < ver.VotingPower() {
+			return ver
+		}
+		pick -= ver.VotingPower()
+	}
+	return nil
+}
+
+// spreadSlot maps the slot in [0, total) to the position in the weighted schedule. The stride is
+// coprime to total so that the mapping is a permutation, and the consecutive slots are spread
+// over the verifiers instead of running through the voting power of one verifier.
+func spreadSlot(slot uint64, total uint64) uint64 {
+	stride := uint64(float64(total) * 0.618)
+	if stride == 0 {
+		stride = 1
+	}
+	for gcd(stride, total) != 1 {
+		stride++
+	}
+	hi, lo := bits.Mul64(slot, stride)
+	return bits.Rem64(hi, lo, total)
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
 func stickyProposer(verSet bft.VerifierSet, proposer common.Address, round uint64) bft.Verifier {
 	if verSet.Size() == 0 {
 		return nil
@@ -139,9 +189,13 @@ func (verSet *basicSet) Policy() bft.ProposerPolicy {
 	return verSet.policy
 }
 
-func (verSet *basicSet) CalcProposer(lastProposer common.Address, round uint64) {
+func (verSet *basicSet) CalcProposer(lastProposer common.Address, sequence uint64, round uint64) {
 	verSet.verifierMu.RLock()
 	defer verSet.verifierMu.RUnlock()
+	if verSet.weighted && verSet.policy == bft.RoundRobin {
+		verSet.proposer = weightedRoundRobinProposer(verSet.verifiers, sequence+round)
+		return
+	}
 	verSet.proposer = verSet.selector(verSet, lastProposer, round)
 }
 
@@ -173,7 +227,12 @@ func (verSet *basicSet) AddVerifier(address common.Address) bool {
 			return false
 		}
 	}
-	verSet.verifiers = append(verSet.verifiers, NewVerifier(address))
+	// the verifier added between epochs has no voting power until the stake is refreshed
+	ver := &basicVerifier{address: address, votingPower: 1}
+	if verSet.weighted {
+		ver.votingPower = 0
+	}
+	verSet.verifiers = append(verSet.verifiers, ver)
 	sort.Sort(verSet.verifiers)
 	return true
 }
@@ -199,13 +258,89 @@ func (verSet *basicSet) Copy() bft.VerifierSet {
 	defer verSet.verifierMu.RUnlock()
 
 	addresses := make([]common.Address, 0, len(verSet.verifiers))
+	powers := make(map[common.Address]uint64)
 	for _, v := range verSet.verifiers {
 		addresses = append(addresses, v.Address())
+		powers[v.Address()] = v.VotingPower()
+	}
+	cpy := newBasicSet(addresses, verSet.policy)
+	if verSet.weighted {
+		cpy.SetVotingPowers(powers)
+	}
+	return cpy
+}
+
+// SetVotingPowers replaces the voting powers of verifiers, the verifiers not in powers have no
+// voting power. The verifiers are equally weighted if none of them has voting power.
+func (verSet *basicSet) SetVotingPowers(powers map[common.Address]uint64) {
+	verSet.verifierMu.Lock()
+	defer verSet.verifierMu.Unlock()
+
+	total := uint64(0)
+	for _, v := range verSet.verifiers {
+		total += powers[v.Address()]
+	}
+	verSet.weighted = total > 0
+
+	verifiers := make([]bft.Verifier, len(verSet.verifiers))
+	for i, v := range verSet.verifiers {
+		ver := &basicVerifier{address: v.Address(), votingPower: 1}
+		if verSet.weighted {
+			ver.votingPower = powers[v.Address()]
+		}
+		verifiers[i] = ver
+		if verSet.proposer != nil && verSet.proposer.Address() == ver.address {
+			verSet.proposer = ver
+		}
 	}
-	return NewVerifierSet(addresses, verSet.policy)
+	verSet.verifiers = verifiers
 }
 
-// failure tolerate
+// VotingPowers returns the voting powers of verifiers, or nil if equally weighted
+func (verSet *basicSet) VotingPowers() map[common.Address]uint64 {
+	verSet.verifierMu.RLock()
+	defer verSet.verifierMu.RUnlock()
+	if !verSet.weighted {
+		return nil
+	}
+	powers := make(map[common.Address]uint64)
+	for _, v := range verSet.verifiers {
+		powers[v.Address()] = v.VotingPower()
+	}
+	return powers
+}
+
+// TotalVotingPower returns the sum of voting powers
+func (verSet *basicSet) TotalVotingPower() uint64 {
+	total := uint64(0)
+	for _, v := range verSet.List() {
+		total += v.VotingPower()
+	}
+	return total
+}
+
+// VotingPowerOf returns the sum of voting powers of the addresses in the set
+func (verSet *basicSet) VotingPowerOf(addrs []common.Address) uint64 {
+	power := uint64(0)
+	for _, addr := range addrs {
+		if _, v := verSet.GetVerByAddress(addr); v != nil {
+			power += v.VotingPower()
+		}
+	}
+	return power
+}
+
+// failure tolerate, which is the maximum voting power of faulty nodes
 func (verSet *basicSet) F() int {
-	return int((float64(verSet.Size()) / 3)) - 1 // 1 stands for self
+	return int((float64(verSet.TotalVotingPower()) / 3)) - 1 // 1 stands for self
+}
+
+// QuorumSize returns the minimum voting power of a quorum, which is ceil(2T/3) of the total
+// voting power T, and at least 1 so that the empty set never reaches a quorum.
+func (verSet *basicSet) QuorumSize() int {
+	total := verSet.TotalVotingPower()
+	if total == 0 {
+		return 1
+	}
+	return int((2*total + 2) / 3)
 }
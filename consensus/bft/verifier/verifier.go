@@ -11,7 +11,8 @@ type verifier struct {
 
 func NewVerifier(addr common.Address) bft.Verifier {
 	return &basicVerifier{
-		address: addr,
+		address:     addr,
+		votingPower: 1,
 	}
 }
 
@@ -97,3 +97,27 @@ type Istanbul interface {
 	// Stop stops the engine
 	Stop() error
 }
+
+// FinalizedChain is the chain whose blocks are finalized by the checkpoints of finality gadget
+type FinalizedChain interface {
+	ChainReader
+
+	// FinalizedCheckpoint returns the latest finalized checkpoint, or nil if none
+	FinalizedCheckpoint() *types.Checkpoint
+
+	// SetFinalizedCheckpoint verifies and sets the finalized checkpoint in canonical chain
+	SetFinalizedCheckpoint(cp *types.Checkpoint) error
+}
+
+// Finality is the gadget that finalizes the checkpoints of PoW chain by a BFT committee
+type Finality interface {
+	Engine
+	// Start starts the gadget to vote on the checkpoints of chain
+	Start(chain FinalizedChain) error
+
+	// Stop stops the gadget
+	Stop() error
+
+	// VerifyCheckpoint verifies the committed seals of checkpoint in chain
+	VerifyCheckpoint(chain ChainReader, cp *types.Checkpoint) error
+}
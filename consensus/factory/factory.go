@@ -16,6 +16,7 @@ import (
 	"github.com/scdoproject/go-stem/consensus/bft"
 	"github.com/scdoproject/go-stem/consensus/bft/server"
 	"github.com/scdoproject/go-stem/consensus/ethash"
+	"github.com/scdoproject/go-stem/consensus/finality"
 	"github.com/scdoproject/go-stem/consensus/istanbul"
 	"github.com/scdoproject/go-stem/consensus/istanbul/backend"
 	"github.com/scdoproject/go-stem/consensus/pow"
@@ -50,6 +51,18 @@ func GetBFTEngine(privateKey *ecdsa.PrivateKey, folder string) (consensus.Engine
 	return backend.New(istanbul.DefaultConfig, privateKey, db), nil
 }
 
+// GetFinalityEngine wraps the PoW engine with the finality gadget, whose committee votes on
+// the checkpoint blocks of each shard.
+func GetFinalityEngine(engine consensus.Engine, privateKey *ecdsa.PrivateKey, committee []common.Address, folder string) (consensus.Engine, error) {
+	path := filepath.Join(folder, common.FinalityDataFolder)
+	db, err := leveldb.NewLevelDB(path)
+	if err != nil {
+		return nil, errors.NewStackedError(err, "create finality folder failed")
+	}
+
+	return finality.New(engine, istanbul.DefaultConfig, committee, privateKey, db), nil
+}
+
 func MustGetConsensusEngine(minerAlgorithm string) consensus.Engine {
 	engine, err := GetConsensusEngine(minerAlgorithm, "temp")
 	if err != nil {
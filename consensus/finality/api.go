@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package finality
+
+import (
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/consensus/istanbul"
+	istanbulCore "github.com/scdoproject/go-stem/consensus/istanbul/core"
+)
+
+// API is a user facing RPC API to query the finalized checkpoints
+type API struct {
+	gadget *Gadget
+}
+
+// GetFinalizedHeight returns the height of the latest finalized checkpoint, which is 0 if
+// no checkpoint is finalized yet.
+func (api *API) GetFinalizedHeight() (uint64, error) {
+	if api.gadget.chain == nil {
+		return 0, errNotStarted
+	}
+
+	if cp := api.gadget.chain.FinalizedCheckpoint(); cp != nil {
+		return cp.Height, nil
+	}
+
+	return 0, nil
+}
+
+// GetFinalizedCheckpoint returns the latest finalized checkpoint and the committee members
+// who signed it, or nil if no checkpoint is finalized yet.
+func (api *API) GetFinalizedCheckpoint() (map[string]interface{}, error) {
+	if api.gadget.chain == nil {
+		return nil, errNotStarted
+	}
+
+	cp := api.gadget.chain.FinalizedCheckpoint()
+	if cp == nil {
+		return nil, nil
+	}
+
+	seal := istanbulCore.PrepareCommittedSeal(newProposal(cp.Index(), cp.Hash).Hash())
+	signers := make([]common.Address, 0, len(cp.Seals))
+	for _, s := range cp.Seals {
+		if addr, err := istanbul.GetSignatureAddress(seal, s); err == nil {
+			signers = append(signers, addr)
+		}
+	}
+
+	return map[string]interface{}{
+		"height":  cp.Height,
+		"hash":    cp.Hash,
+		"signers": signers,
+	}, nil
+}
+
+// GetCommittee returns the finality committee of local shard
+func (api *API) GetCommittee() []common.Address {
+	return api.gadget.committeeOf(common.LocalShardNumber)
+}
@@ -0,0 +1,221 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package finality
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/event"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/consensus/istanbul"
+	"github.com/scdoproject/go-stem/consensus/istanbul/validator"
+	"github.com/scdoproject/go-stem/consensus/utils"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// Address implements istanbul.Backend.Address
+func (g *Gadget) Address() common.Address {
+	return g.address
+}
+
+// Validators implements istanbul.Backend.Validators, which returns the committee of local shard
+func (g *Gadget) Validators(proposal istanbul.Proposal) istanbul.ValidatorSet {
+	return validator.NewSet(g.committeeOf(common.LocalShardNumber), g.config.ProposerPolicy)
+}
+
+// EventMux implements istanbul.Backend.EventMux
+func (g *Gadget) EventMux() *event.TypeMux {
+	return g.eventMux
+}
+
+// Broadcast implements istanbul.Backend.Broadcast
+func (g *Gadget) Broadcast(valSet istanbul.ValidatorSet, payload []byte) error {
+	// send to others
+	g.Gossip(valSet, payload)
+	// send to self
+	go g.eventMux.Post(istanbul.MessageEvent{
+		Payload: payload,
+	})
+	return nil
+}
+
+// Gossip implements istanbul.Backend.Gossip
+func (g *Gadget) Gossip(valSet istanbul.ValidatorSet, payload []byte) error {
+	hash := crypto.HashBytes(payload)
+	g.knownMessages.Add(hash, true)
+
+	targets := make(map[common.Address]bool)
+	for _, val := range valSet.List() {
+		if val.Address() != g.Address() {
+			targets[val.Address()] = true
+		}
+	}
+
+	if g.broadcaster == nil || len(targets) == 0 {
+		return nil
+	}
+
+	for addr, p := range g.broadcaster.FindPeers(targets) {
+		ms, ok := g.recentMessages.Get(addr)
+		var m *lru.ARCCache
+		if ok {
+			m, _ = ms.(*lru.ARCCache)
+			if _, k := m.Get(hash); k {
+				// This peer had this event, skip it
+				continue
+			}
+		} else {
+			m, _ = lru.NewARC(inmemoryMessages)
+		}
+
+		m.Add(hash, true)
+		g.recentMessages.Add(addr, m)
+
+		go p.Send(finalityMsg, payload)
+	}
+
+	return nil
+}
+
+// Commit implements istanbul.Backend.Commit, which stores the checkpoint with the committed
+// seals, finalizes it in chain and broadcasts it to the peers out of committee.
+func (g *Gadget) Commit(proposal istanbul.Proposal, seals [][]byte) error {
+	hash, err := checkpointHash(proposal)
+	if err != nil {
+		return err
+	}
+
+	cp := &types.Checkpoint{
+		Height: proposal.Height() * common.FinalityCheckpointInterval,
+		Hash:   hash,
+		Seals:  seals,
+	}
+
+	if err = writeCheckpoint(g.db, cp); err != nil {
+		g.logger.Error("failed to write checkpoint, err %s", err)
+		return err
+	}
+
+	g.last.Store(cp)
+	g.logger.Info("Committed checkpoint. height %d. hash %s", cp.Height, cp.Hash.String())
+
+	g.finalize(cp)
+	go g.broadcastCheckpoint(cp)
+	go g.eventMux.Post(istanbul.FinalCommittedEvent{})
+	go g.requestCheckpoint()
+
+	return nil
+}
+
+// Verify implements istanbul.Backend.Verify, which verifies the checkpoint block is deep
+// enough in the local canonical chain.
+func (g *Gadget) Verify(proposal istanbul.Proposal) (time.Duration, error) {
+	hash, err := checkpointHash(proposal)
+	if err != nil {
+		return 0, err
+	}
+
+	if proposal.Height() < firstCheckpointIndex {
+		return 0, errInvalidProposal
+	}
+
+	height := proposal.Height() * common.FinalityCheckpointInterval
+	if g.chain.CurrentHeader().Height < height+common.FinalityCheckpointDepth {
+		return 0, errUnknownCheckpoint
+	}
+
+	if header := g.chain.GetHeaderByHeight(height); header == nil || header.Hash() != hash {
+		return 0, errUnknownCheckpoint
+	}
+
+	return 0, nil
+}
+
+// Sign implements istanbul.Backend.Sign
+func (g *Gadget) Sign(data []byte) ([]byte, error) {
+	hashData := crypto.Keccak256(data)
+	sign, err := crypto.Sign(g.privateKey, hashData)
+	return sign.Sig, err
+}
+
+// WAL returns the write-ahead log of consensus round state
+func (g *Gadget) WAL() *utils.WAL {
+	return g.wal
+}
+
+// CheckSignature implements istanbul.Backend.CheckSignature
+func (g *Gadget) CheckSignature(data []byte, address common.Address, sig []byte) error {
+	signer, err := istanbul.GetSignatureAddress(data, sig)
+	if err != nil {
+		g.logger.Error("Failed to get signer address err %s", err)
+		return err
+	}
+
+	if signer != address {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// LastProposal implements istanbul.Backend.LastProposal, which returns the proposal of the
+// latest committed checkpoint, or the genesis block as the checkpoint of index 0.
+func (g *Gadget) LastProposal() (istanbul.Proposal, common.Address) {
+	// the committee starts from the first checkpoint after fork
+	index := firstCheckpointIndex - 1
+	var hash common.Hash
+	if cp := g.lastCheckpoint(); cp != nil {
+		index, hash = cp.Index(), cp.Hash
+	} else if genesis := g.chain.GetHeaderByHeight(0); genesis != nil {
+		hash = genesis.Hash()
+	}
+
+	return newProposal(index, hash), g.GetProposer(index)
+}
+
+// HasPropsal implements istanbul.Backend.HasPropsal
+func (g *Gadget) HasPropsal(hash common.Hash) bool {
+	has, err := hasCheckpoint(g.db, hash)
+	return err == nil && has
+}
+
+// GetProposer implements istanbul.Backend.GetProposer. The proposer of checkpoints rotates in
+// the committee of local shard.
+func (g *Gadget) GetProposer(height uint64) common.Address {
+	members := g.committeeOf(common.LocalShardNumber)
+	if len(members) == 0 {
+		return common.Address{}
+	}
+
+	return members[height%uint64(len(members))]
+}
+
+// ParentValidators implements istanbul.Backend.ParentValidators
+func (g *Gadget) ParentValidators(proposal istanbul.Proposal) istanbul.ValidatorSet {
+	return g.Validators(proposal)
+}
+
+// HasBadProposal implements istanbul.Backend.HasBadProposal
+func (g *Gadget) HasBadProposal(hash common.Hash) bool {
+	return false
+}
+
+// checkpointHash returns the checkpoint block hash of proposal, and validates the proposal
+// is built by newProposal.
+func checkpointHash(proposal istanbul.Proposal) (common.Hash, error) {
+	block, ok := proposal.(*types.Block)
+	if !ok || block.Header == nil || len(block.Header.ExtraData) != common.HashLength {
+		return common.EmptyHash, errInvalidProposal
+	}
+
+	hash := common.BytesToHash(block.Header.ExtraData)
+	if newProposal(block.Height(), hash).Hash() != block.Hash() || block.Header.Hash() != block.Hash() {
+		return common.EmptyHash, errInvalidProposal
+	}
+
+	return hash, nil
+}
@@ -0,0 +1,297 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package finality
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/event"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/consensus"
+	"github.com/scdoproject/go-stem/consensus/istanbul"
+	istanbulCore "github.com/scdoproject/go-stem/consensus/istanbul/core"
+	"github.com/scdoproject/go-stem/consensus/istanbul/validator"
+	"github.com/scdoproject/go-stem/consensus/utils"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database"
+	scdoEvent "github.com/scdoproject/go-stem/event"
+	"github.com/scdoproject/go-stem/log"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+const (
+	// walPrefix is the key prefix of consensus write-ahead log in db
+	walPrefix = "finality-wal"
+
+	inmemoryPeers    = 40
+	inmemoryMessages = 1024
+
+	// firstCheckpointIndex is the index of the first checkpoint at or after the fork height
+	firstCheckpointIndex uint64 = (common.FinalityForkHeight + common.FinalityCheckpointInterval - 1) / common.FinalityCheckpointInterval
+)
+
+var (
+	// errInvalidProposal is returned when the proposal is not a checkpoint proposal
+	errInvalidProposal = errors.New("invalid checkpoint proposal")
+	// errUnknownCheckpoint is returned when the checkpoint block is not in the local canonical chain
+	errUnknownCheckpoint = errors.New("unknown checkpoint block")
+	// errInvalidCheckpoint is returned when the checkpoint height is not a multiple of interval or before fork
+	errInvalidCheckpoint = errors.New("invalid checkpoint height")
+	// errInvalidCommittedSeals is returned when the committed seals are not enough or not signed by committee
+	errInvalidCommittedSeals = errors.New("invalid committed seals of checkpoint")
+	// errInvalidSignature is returned when the message is not signed by the sender
+	errInvalidSignature = errors.New("invalid signature")
+	// errNotStarted is returned when the gadget is not started
+	errNotStarted = errors.New("finality gadget is not started")
+)
+
+// Gadget is the finality gadget on top of the PoW engine. The committee of each shard votes
+// on the checkpoint blocks every FinalityCheckpointInterval heights with the Istanbul core, and
+// the committed checkpoints are finalized in the chain, which never reorganizes past them.
+type Gadget struct {
+	consensus.Engine // the PoW engine to seal and verify blocks
+
+	config     *istanbul.Config
+	committee  []common.Address // the committee of all shards, grouped by the shard of address
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+	logger     *log.ScdoLog
+	db         database.Database
+	wal        *utils.WAL
+
+	core        istanbulCore.Engine
+	eventMux    *event.TypeMux
+	coreStarted bool
+	coreMu      sync.RWMutex
+
+	chain consensus.FinalizedChain
+	last  atomic.Value // the latest committed checkpoint, which may not be canonical in chain yet
+
+	broadcaster    consensus.Broadcaster
+	recentMessages *lru.ARCCache // the cache of peer's messages
+	knownMessages  *lru.ARCCache // the cache of self messages
+}
+
+// New creates the finality gadget on top of the PoW engine with the committee of all shards
+func New(engine consensus.Engine, config *istanbul.Config, committee []common.Address, privateKey *ecdsa.PrivateKey, db database.Database) *Gadget {
+	recentMessages, _ := lru.NewARC(inmemoryPeers)
+	knownMessages, _ := lru.NewARC(inmemoryMessages)
+	g := &Gadget{
+		Engine:         engine,
+		config:         config,
+		committee:      committee,
+		privateKey:     privateKey,
+		address:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		logger:         log.GetLogger("finality"),
+		db:             db,
+		eventMux:       new(event.TypeMux),
+		recentMessages: recentMessages,
+		knownMessages:  knownMessages,
+	}
+
+	cp, err := loadCheckpoint(db)
+	if err != nil {
+		g.logger.Error("failed to load finalized checkpoint, err %s", err)
+	} else if cp != nil {
+		g.last.Store(cp)
+	}
+
+	if g.wal, err = utils.NewWAL(db, walPrefix); err != nil {
+		g.logger.Error("failed to load consensus WAL, err %s", err)
+	}
+
+	g.core = istanbulCore.New(g, config)
+	return g
+}
+
+// Start implements consensus.Finality.Start. The Istanbul core is started only if the node
+// is in the committee of local shard.
+func (g *Gadget) Start(chain consensus.FinalizedChain) error {
+	g.coreMu.Lock()
+	defer g.coreMu.Unlock()
+
+	if g.chain != nil {
+		return istanbul.ErrStartedEngine
+	}
+
+	g.chain = chain
+	if cp := g.lastCheckpoint(); cp != nil {
+		g.finalize(cp)
+	}
+
+	scdoEvent.ChainHeaderChangedEventMananger.AddAsyncListener(g.handleChainHeaderChanged)
+
+	if _, v := g.Validators(nil).GetByAddress(g.address); v == nil {
+		g.logger.Info("not in finality committee of shard %d, follow the checkpoints only", common.LocalShardNumber)
+		return nil
+	}
+
+	if err := g.core.Start(); err != nil {
+		return err
+	}
+
+	g.coreStarted = true
+	go g.requestCheckpoint()
+
+	return nil
+}
+
+// Stop implements consensus.Finality.Stop
+func (g *Gadget) Stop() error {
+	g.coreMu.Lock()
+	defer g.coreMu.Unlock()
+
+	if !g.coreStarted {
+		return nil
+	}
+
+	if err := g.core.Stop(); err != nil {
+		return err
+	}
+
+	g.coreStarted = false
+	return nil
+}
+
+// APIs implements consensus.Engine.APIs, which appends the finality APIs to the PoW engine APIs
+func (g *Gadget) APIs(chain consensus.ChainReader) []rpc.API {
+	return append(g.Engine.APIs(chain), rpc.API{
+		Namespace: "finality",
+		Version:   "1.0",
+		Service:   &API{gadget: g},
+		Public:    true,
+	})
+}
+
+// VerifyCheckpoint implements consensus.Finality.VerifyCheckpoint, which verifies the checkpoint
+// block is in chain and the committed seals are signed by the committee of its shard.
+func (g *Gadget) VerifyCheckpoint(chain consensus.ChainReader, cp *types.Checkpoint) error {
+	if cp == nil || cp.Height%common.FinalityCheckpointInterval != 0 || cp.Index() < firstCheckpointIndex {
+		return errInvalidCheckpoint
+	}
+
+	header := chain.GetHeaderByHash(cp.Hash)
+	if header == nil || header.Height != cp.Height {
+		return errUnknownCheckpoint
+	}
+
+	return verifySeals(cp, g.committeeOf(header.Creator.Shard()))
+}
+
+// committeeOf returns the committee members of shard
+func (g *Gadget) committeeOf(shard uint) []common.Address {
+	var members []common.Address
+	for _, addr := range g.committee {
+		if addr.Shard() == shard {
+			members = append(members, addr)
+		}
+	}
+
+	return members
+}
+
+func (g *Gadget) lastCheckpoint() *types.Checkpoint {
+	cp, _ := g.last.Load().(*types.Checkpoint)
+	return cp
+}
+
+// finalize sets the committed checkpoint in chain, which fails if the checkpoint block is not
+// canonical yet and is retried on the next chain head.
+func (g *Gadget) finalize(cp *types.Checkpoint) {
+	if finalized := g.chain.FinalizedCheckpoint(); finalized != nil && finalized.Height >= cp.Height {
+		return
+	}
+
+	if err := g.chain.SetFinalizedCheckpoint(cp); err != nil {
+		g.logger.Debug("failed to finalize checkpoint, height %d, hash %v, err %s", cp.Height, cp.Hash, err)
+	}
+}
+
+func (g *Gadget) handleChainHeaderChanged(e scdoEvent.Event) {
+	g.HandleNewChainHead()
+}
+
+// requestCheckpoint requests the committee to vote on the next checkpoint, when it is deep
+// enough in the canonical chain.
+func (g *Gadget) requestCheckpoint() {
+	g.coreMu.RLock()
+	defer g.coreMu.RUnlock()
+
+	if !g.coreStarted {
+		return
+	}
+
+	index := firstCheckpointIndex
+	if cp := g.lastCheckpoint(); cp != nil {
+		index = cp.Index() + 1
+	}
+
+	height := index * common.FinalityCheckpointInterval
+	if g.chain.CurrentHeader().Height < height+common.FinalityCheckpointDepth {
+		return
+	}
+
+	header := g.chain.GetHeaderByHeight(height)
+	if header == nil {
+		return
+	}
+
+	go g.eventMux.Post(istanbul.RequestEvent{
+		Proposal: newProposal(index, header.Hash()),
+	})
+}
+
+// newProposal returns the checkpoint proposal voted by committee, whose height is the index
+// of checkpoint so that the sequences of Istanbul core are consecutive, and the extra data is
+// the hash of checkpoint block.
+func newProposal(index uint64, hash common.Hash) *types.Block {
+	header := &types.BlockHeader{
+		Difficulty:      big.NewInt(0),
+		Height:          index,
+		CreateTimestamp: big.NewInt(0),
+		ExtraData:       hash.Bytes(),
+	}
+
+	return types.NewBlock(header, nil, nil, nil)
+}
+
+// verifySeals verifies the committed seals of checkpoint are signed by a quorum of members
+// of committee.
+func verifySeals(cp *types.Checkpoint, committee []common.Address) error {
+	valSet := validator.NewSet(committee, istanbul.RoundRobin)
+	if valSet.Size() == 0 {
+		return errInvalidCommittedSeals
+	}
+
+	members := valSet.Copy()
+	seal := istanbulCore.PrepareCommittedSeal(newProposal(cp.Index(), cp.Hash).Hash())
+	var sealers []common.Address
+	for _, s := range cp.Seals {
+		addr, err := istanbul.GetSignatureAddress(seal, s)
+		if err != nil {
+			return errInvalidCommittedSeals
+		}
+
+		// every member can have only one seal
+		if !members.RemoveValidator(addr) {
+			return errInvalidCommittedSeals
+		}
+
+		sealers = append(sealers, addr)
+	}
+
+	if int(valSet.VotingPowerOf(sealers)) < valSet.QuorumSize() {
+		return errInvalidCommittedSeals
+	}
+
+	return nil
+}
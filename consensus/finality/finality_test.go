@@ -0,0 +1,128 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package finality
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	istanbulCore "github.com/scdoproject/go-stem/consensus/istanbul/core"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCommittee(n int) ([]common.Address, []*ecdsa.PrivateKey) {
+	var addrs []common.Address
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < n; i++ {
+		addr, key := crypto.MustGenerateShardKeyPair(1)
+		addrs = append(addrs, *addr)
+		keys = append(keys, key)
+	}
+
+	return addrs, keys
+}
+
+func newTestCheckpoint(t *testing.T, keys []*ecdsa.PrivateKey) *types.Checkpoint {
+	cp := &types.Checkpoint{
+		Height: 3 * common.FinalityCheckpointInterval,
+		Hash:   common.StringToHash("checkpoint"),
+	}
+
+	seal := istanbulCore.PrepareCommittedSeal(newProposal(cp.Index(), cp.Hash).Hash())
+	for _, key := range keys {
+		sig, err := crypto.Sign(key, crypto.Keccak256(seal))
+		assert.Nil(t, err)
+		cp.Seals = append(cp.Seals, sig.Sig)
+	}
+
+	return cp
+}
+
+func Test_CheckpointHash(t *testing.T) {
+	hash := common.StringToHash("checkpoint")
+	proposal := newProposal(3, hash)
+	assert.Equal(t, uint64(3), proposal.Height())
+
+	got, err := checkpointHash(proposal)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, got)
+
+	// proposal that is not built by newProposal
+	proposal.Header.Witness = []byte{1}
+	proposal.HeaderHash = proposal.Header.Hash()
+	_, err = checkpointHash(proposal)
+	assert.Equal(t, errInvalidProposal, err)
+
+	// extra data is not a hash
+	proposal = newProposal(3, hash)
+	proposal.Header.ExtraData = []byte{1, 2, 3}
+	proposal.HeaderHash = proposal.Header.Hash()
+	_, err = checkpointHash(proposal)
+	assert.Equal(t, errInvalidProposal, err)
+}
+
+func Test_VerifySeals(t *testing.T) {
+	committee, keys := newTestCommittee(4)
+
+	// 3 of 4 members
+	assert.Nil(t, verifySeals(newTestCheckpoint(t, keys[:3]), committee))
+
+	// 2 of 4 members
+	assert.Equal(t, errInvalidCommittedSeals, verifySeals(newTestCheckpoint(t, keys[:2]), committee))
+
+	// duplicated seals
+	assert.Equal(t, errInvalidCommittedSeals, verifySeals(newTestCheckpoint(t, []*ecdsa.PrivateKey{keys[0], keys[1], keys[1]}), committee))
+
+	// seal of non-member
+	_, other := crypto.MustGenerateShardKeyPair(1)
+	assert.Equal(t, errInvalidCommittedSeals, verifySeals(newTestCheckpoint(t, []*ecdsa.PrivateKey{keys[0], keys[1], other}), committee))
+
+	// seals on another checkpoint
+	cp := newTestCheckpoint(t, keys[:3])
+	cp.Hash = common.StringToHash("fork")
+	assert.Equal(t, errInvalidCommittedSeals, verifySeals(cp, committee))
+
+	// empty committee
+	assert.Equal(t, errInvalidCommittedSeals, verifySeals(newTestCheckpoint(t, keys[:3]), nil))
+}
+
+func Test_VerifyCheckpointBeforeFork(t *testing.T) {
+	_, keys := newTestCommittee(4)
+	g := &Gadget{}
+
+	// the checkpoints before fork are never finalized
+	assert.Equal(t, errInvalidCheckpoint, g.VerifyCheckpoint(nil, newTestCheckpoint(t, keys[:3])))
+	assert.Equal(t, errInvalidCheckpoint, g.VerifyCheckpoint(nil, &types.Checkpoint{Height: common.FinalityForkHeight + 1}))
+}
+
+func Test_CheckpointStore(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	cp, err := loadCheckpoint(db)
+	assert.Nil(t, err)
+	assert.Nil(t, cp)
+
+	_, keys := newTestCommittee(4)
+	cp = newTestCheckpoint(t, keys[:3])
+	assert.Nil(t, writeCheckpoint(db, cp))
+
+	loaded, err := loadCheckpoint(db)
+	assert.Nil(t, err)
+	assert.Equal(t, cp, loaded)
+
+	has, err := hasCheckpoint(db, newProposal(cp.Index(), cp.Hash).Hash())
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	has, err = hasCheckpoint(db, newProposal(cp.Index()+1, cp.Hash).Hash())
+	assert.Nil(t, err)
+	assert.False(t, has)
+}
@@ -0,0 +1,165 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package finality
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/consensus"
+	"github.com/scdoproject/go-stem/consensus/istanbul"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/log"
+	"github.com/scdoproject/go-stem/p2p"
+)
+
+// the message codes follow the codes of scdo protocol
+const (
+	// finalityMsg is the consensus message of committee
+	finalityMsg = 14
+	// checkpointMsg is the committed checkpoint broadcast to all peers of local shard
+	checkpointMsg = 15
+)
+
+// peerLister is the broadcaster that lists all peers of local shard
+type peerLister interface {
+	LocalPeers() map[common.Address]consensus.Peer
+}
+
+// HandleMsg implements consensus.Handler.HandleMsg
+func (g *Gadget) HandleMsg(addr common.Address, message interface{}) (bool, error) {
+	var msg *p2p.Message
+	switch m := message.(type) {
+	case p2p.Message:
+		msg = &m
+	case *p2p.Message:
+		msg = m
+	default:
+		return false, nil
+	}
+
+	switch msg.Code {
+	case finalityMsg:
+		g.handleFinalityMsg(addr, msg)
+	case checkpointMsg:
+		g.handleCheckpointMsg(msg)
+	default:
+		return false, nil
+	}
+
+	// the invalid messages are dropped without disconnecting peer
+	return true, nil
+}
+
+// SetBroadcaster implements consensus.Handler.SetBroadcaster
+func (g *Gadget) SetBroadcaster(broadcaster consensus.Broadcaster) {
+	g.broadcaster = broadcaster
+}
+
+// HandleNewChainHead implements consensus.Handler.HandleNewChainHead, which finalizes the
+// committed checkpoint once it is canonical and requests to vote on the next one.
+func (g *Gadget) HandleNewChainHead() error {
+	if g.chain == nil {
+		return errNotStarted
+	}
+
+	if cp := g.lastCheckpoint(); cp != nil {
+		g.finalize(cp)
+	}
+
+	g.requestCheckpoint()
+	return nil
+}
+
+func (g *Gadget) handleFinalityMsg(addr common.Address, msg *p2p.Message) {
+	g.coreMu.RLock()
+	defer g.coreMu.RUnlock()
+
+	if !g.coreStarted {
+		return
+	}
+
+	var data []byte
+	if err := common.Deserialize(msg.Payload, &data); err != nil {
+		g.logger.Debug("failed to decode finality message, err %s", err)
+		return
+	}
+
+	hash := crypto.HashBytes(data)
+
+	// Mark peer's message
+	ms, ok := g.recentMessages.Get(addr)
+	var m *lru.ARCCache
+	if ok {
+		m, _ = ms.(*lru.ARCCache)
+	} else {
+		m, _ = lru.NewARC(inmemoryMessages)
+		g.recentMessages.Add(addr, m)
+	}
+	m.Add(hash, true)
+
+	// Mark self known message
+	if _, ok := g.knownMessages.Get(hash); ok {
+		return
+	}
+	g.knownMessages.Add(hash, true)
+
+	go g.eventMux.Post(istanbul.MessageEvent{
+		Payload: data,
+	})
+}
+
+// handleCheckpointMsg verifies and stores the checkpoint committed by committee, and relays
+// it to other peers.
+func (g *Gadget) handleCheckpointMsg(msg *p2p.Message) {
+	if g.chain == nil {
+		return
+	}
+
+	var cp types.Checkpoint
+	if err := common.Deserialize(msg.Payload, &cp); err != nil {
+		g.logger.Debug("failed to decode checkpoint, err %s", err)
+		return
+	}
+
+	if last := g.lastCheckpoint(); last != nil && last.Height >= cp.Height {
+		return
+	}
+
+	cpLog := g.logger.With(log.Height(cp.Height), log.Hash(cp.Hash))
+	if err := g.VerifyCheckpoint(g.chain, &cp); err != nil {
+		cpLog.Debug("invalid checkpoint, height %d, hash %v, err %s", cp.Height, cp.Hash, err)
+		return
+	}
+
+	if err := writeCheckpoint(g.db, &cp); err != nil {
+		cpLog.Error("failed to write checkpoint, err %s", err)
+		return
+	}
+
+	g.last.Store(&cp)
+	g.finalize(&cp)
+	go g.broadcastCheckpoint(&cp)
+
+	// catch up the sequence of Istanbul core
+	g.coreMu.RLock()
+	if g.coreStarted {
+		go g.eventMux.Post(istanbul.FinalCommittedEvent{})
+	}
+	g.coreMu.RUnlock()
+}
+
+// broadcastCheckpoint sends the checkpoint to all peers of local shard
+func (g *Gadget) broadcastCheckpoint(cp *types.Checkpoint) {
+	lister, ok := g.broadcaster.(peerLister)
+	if !ok {
+		return
+	}
+
+	for _, p := range lister.LocalPeers() {
+		go p.Send(checkpointMsg, cp)
+	}
+}
@@ -0,0 +1,61 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package finality
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/database"
+)
+
+var (
+	// headCheckpointKey is the key of the latest committed checkpoint
+	headCheckpointKey = []byte("finality-head")
+	// checkpointPrefix is the key prefix of committed checkpoints by proposal hash
+	checkpointPrefix = []byte("finality-checkpoint")
+)
+
+// loadCheckpoint returns the latest committed checkpoint in db, or nil if none
+func loadCheckpoint(db database.Database) (*types.Checkpoint, error) {
+	if has, err := db.Has(headCheckpointKey); err != nil || !has {
+		return nil, err
+	}
+
+	value, err := db.Get(headCheckpointKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var cp types.Checkpoint
+	if err = rlp.DecodeBytes(value, &cp); err != nil {
+		return nil, err
+	}
+
+	return &cp, nil
+}
+
+// writeCheckpoint writes the committed checkpoint as the latest one in db
+func writeCheckpoint(db database.Database, cp *types.Checkpoint) error {
+	value, err := rlp.EncodeToBytes(cp)
+	if err != nil {
+		return err
+	}
+
+	batch := db.NewBatch()
+	batch.Put(checkpointKey(newProposal(cp.Index(), cp.Hash).Hash()), value)
+	batch.Put(headCheckpointKey, value)
+	return batch.Commit()
+}
+
+// hasCheckpoint returns whether the checkpoint of proposal hash is committed
+func hasCheckpoint(db database.Database, hash common.Hash) (bool, error) {
+	return db.Has(checkpointKey(hash))
+}
+
+func checkpointKey(hash common.Hash) []byte {
+	return append(common.CopyBytes(checkpointPrefix), hash.Bytes()...)
+}
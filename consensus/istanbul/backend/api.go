@@ -7,7 +7,9 @@ package backend
 
 import (
 	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
 	"github.com/scdoproject/go-stem/consensus"
+	"github.com/scdoproject/go-stem/consensus/utils"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/rpc"
 )
@@ -76,6 +78,49 @@ func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error)
 	return snap.validators(), nil
 }
 
+// GetVotingPowers retrieves the voting powers of authorized validators at the specified block,
+// which are refreshed with the stakes at every epoch boundary.
+func (api *API) GetVotingPowers(number *rpc.BlockNumber) (map[common.Address]uint64, error) {
+	// Retrieve the requested block number (or current if none requested)
+	var header *types.BlockHeader
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByHeight(uint64(number.Int64()))
+	}
+	// Ensure we have an actually valid block and return the voting powers from its snapshot
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.istanbul.snapshot(api.chain, header.Height, header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	powers := make(map[common.Address]uint64)
+	for _, val := range snap.ValSet.List() {
+		powers[val.Address()] = val.VotingPower()
+	}
+	return powers, nil
+}
+
+// BLSKey is the BLS public key of the node with the proof of possession, which is registered in
+// the staking contract to sign the aggregated commit seals.
+type BLSKey struct {
+	PublicKey string
+	Proof     string
+}
+
+// GetBLSKey returns the BLS public key of the node and the proof of possession to register
+func (api *API) GetBLSKey() *BLSKey {
+	pk, proof := api.istanbul.BLSPublicKey()
+	return &BLSKey{hexutil.BytesToHex(pk), hexutil.BytesToHex(proof)}
+}
+
+// GetEvidence returns the double sign evidence detected by the node
+func (api *API) GetEvidence() []*utils.Evidence {
+	return api.istanbul.evidence.List()
+}
+
 // Candidates returns the current candidates the node tries to uphold and vote on.
 func (api *API) Candidates() map[common.Address]bool {
 	api.istanbul.candidatesLock.RLock()
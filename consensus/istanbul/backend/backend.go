@@ -17,8 +17,10 @@ import (
 	"github.com/scdoproject/go-stem/consensus/istanbul"
 	istanbulCore "github.com/scdoproject/go-stem/consensus/istanbul/core"
 	"github.com/scdoproject/go-stem/consensus/istanbul/validator"
+	"github.com/scdoproject/go-stem/consensus/utils"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bls"
 	"github.com/scdoproject/go-stem/database"
 	"github.com/scdoproject/go-stem/log"
 )
@@ -26,6 +28,8 @@ import (
 const (
 	// fetcherID is the ID indicates the block is from Istanbul engine
 	fetcherID = "istanbul"
+	// walPrefix is the key prefix of consensus write-ahead log in db
+	walPrefix = "istanbul-wal"
 )
 
 // New creates an Ethereum backend for Istanbul core engine.
@@ -38,6 +42,7 @@ func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db database.Data
 		config:           config,
 		istanbulEventMux: new(event.TypeMux),
 		privateKey:       privateKey,
+		blsKey:           bls.DeriveKey(crypto.FromECDSA(privateKey)),
 		address:          crypto.PubkeyToAddress(privateKey.PublicKey),
 		logger:           log.GetLogger("ibft"),
 		db:               db,
@@ -47,7 +52,14 @@ func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db database.Data
 		coreStarted:      false,
 		recentMessages:   recentMessages,
 		knownMessages:    knownMessages,
+		evidence:         utils.NewEvidencePool(),
 	}
+
+	wal, err := utils.NewWAL(db, walPrefix)
+	if err != nil {
+		backend.logger.Error("failed to load consensus WAL, err %s", err)
+	}
+	backend.wal = wal
 	backend.core = istanbulCore.New(backend, backend.config)
 	return backend
 }
@@ -58,6 +70,7 @@ type backend struct {
 	config           *istanbul.Config
 	istanbulEventMux *event.TypeMux
 	privateKey       *ecdsa.PrivateKey
+	blsKey           *bls.SecretKey // derived from the private key to sign the BLS committed seals
 	address          common.Address
 	core             istanbulCore.Engine
 	logger           *log.ScdoLog
@@ -85,6 +98,9 @@ type backend struct {
 
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+
+	evidence *utils.EvidencePool // the double sign evidence to include in blocks
+	wal      *utils.WAL          // the write-ahead log of consensus round state
 }
 
 // Address implements istanbul.Backend.Address
@@ -156,8 +172,16 @@ func (sb *backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
 	}
 
 	h := block.Header
-	// Append seals into extra-data
-	err := writeCommittedSeals(h, seals)
+	// Append seals into extra-data, which are aggregated after fork
+	var err error
+	if h.Height >= common.BLSSealForkHeight {
+		var snap *Snapshot
+		if snap, err = sb.snapshot(sb.chain, h.Height-1, h.PreviousBlockHash, nil); err == nil {
+			err = writeAggregatedSeal(h, seals, snap)
+		}
+	} else {
+		err = writeCommittedSeals(h, seals)
+	}
 	if err != nil {
 		return err
 	}
@@ -227,6 +251,34 @@ func (sb *backend) Sign(data []byte) ([]byte, error) {
 	return sign.Sig, err
 }
 
+// SignCommittedSeal signs the committed seal of proposal, which is followed by the BLS seal
+// after fork to be aggregated into the header.
+func (sb *backend) SignCommittedSeal(proposal istanbul.Proposal, seal []byte) ([]byte, error) {
+	sig, err := sb.Sign(seal)
+	if err != nil || proposal.Height() < common.BLSSealForkHeight {
+		return sig, err
+	}
+
+	return append(sig, sb.blsKey.Sign(seal).Bytes()...), nil
+}
+
+// BLSPublicKey returns the BLS public key and the proof of possession to register in the
+// staking contract.
+func (sb *backend) BLSPublicKey() ([]byte, []byte) {
+	return sb.blsKey.PublicKey().Bytes(), sb.blsKey.ProvePossession().Bytes()
+}
+
+// WAL returns the write-ahead log of consensus round state
+func (sb *backend) WAL() *utils.WAL {
+	return sb.wal
+}
+
+// HandleEvidence adds the double sign evidence detected by core into pool, and returns
+// false if the evidence is known.
+func (sb *backend) HandleEvidence(ev *types.DoubleSignEvidence) (bool, error) {
+	return sb.evidence.Add(ev)
+}
+
 // CheckSignature implements istanbul.Backend.CheckSignature
 func (sb *backend) CheckSignature(data []byte, address common.Address, sig []byte) error {
 	signer, err := istanbul.GetSignatureAddress(data, sig)
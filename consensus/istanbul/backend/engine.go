@@ -20,8 +20,12 @@ import (
 	"github.com/scdoproject/go-stem/consensus/istanbul"
 	istanbulCore "github.com/scdoproject/go-stem/consensus/istanbul/core"
 	"github.com/scdoproject/go-stem/consensus/istanbul/validator"
+	"github.com/scdoproject/go-stem/consensus/utils"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/state"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bls"
 	"github.com/scdoproject/go-stem/rpc"
 )
 
@@ -69,6 +73,9 @@ var (
 	errEmptyCommittedSeals = errors.New("zero committed seals")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 	errMismatchTxhashes = errors.New("mismatch transcations hashes")
+	// errInvalidEvidence is returned if the double sign evidence in header is invalid or its
+	// offender is not an authorized validator.
+	errInvalidEvidence = errors.New("invalid double sign evidence")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -163,10 +170,38 @@ func (sb *backend) verifyCascadingFields(chain consensus.ChainReader, header *ty
 	if err := sb.verifySigner(chain, header, parents); err != nil {
 		return err
 	}
+	if err := verifyEvidence(header, snap); err != nil {
+		return err
+	}
 
 	return sb.verifyCommittedSeals(chain, header, parents)
 }
 
+// verifyEvidence checks the double sign evidence in header is valid, and the offenders are
+// distinct validators in the parent snapshot. No evidence is allowed before EvidenceForkHeight.
+func verifyEvidence(header *types.BlockHeader, snap *Snapshot) error {
+	evidence, err := types.HeaderEvidence(header)
+	if err != nil {
+		return err
+	}
+	if len(evidence) > utils.MaxBlockEvidence || (len(evidence) > 0 && header.Height < common.EvidenceForkHeight) {
+		return errInvalidEvidence
+	}
+
+	offenders := make(map[common.Address]bool)
+	for _, ev := range evidence {
+		vote, err := ev.Verify()
+		if err != nil || vote.Height >= header.Height || offenders[vote.Signer] {
+			return errInvalidEvidence
+		}
+		if _, v := snap.ValSet.GetByAddress(vote.Signer); v == nil {
+			return errInvalidEvidence
+		}
+		offenders[vote.Signer] = true
+	}
+	return nil
+}
+
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
 // concurrently. The method returns a quit channel to abort the operations and
 // a results channel to retrieve the async verifications (the order is that of
@@ -233,6 +268,29 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 	if err != nil {
 		return err
 	}
+
+	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+	// The committed seals are aggregated into one BLS signature after fork
+	if number >= common.BLSSealForkHeight {
+		if len(extra.CommittedSeal) > 0 || len(extra.AggregatedSeal) == 0 {
+			return errInvalidCommittedSeals
+		}
+
+		sealers, err := utils.VerifyAggregatedSeal(snap.validators(), snap.BLSKeys, extra.SignerBitmap, extra.AggregatedSeal, proposalSeal)
+		if err != nil {
+			return err
+		}
+
+		if int(snap.ValSet.VotingPowerOf(sealers)) < sealQuorum(snap.ValSet, number) {
+			return errInvalidCommittedSeals
+		}
+
+		return nil
+	}
+
+	if len(extra.AggregatedSeal) > 0 || len(extra.SignerBitmap) > 0 {
+		return errInvalidCommittedSeals
+	}
 	// The length of Committed seals should be larger than 0
 	if len(extra.CommittedSeal) == 0 {
 		return errEmptyCommittedSeals
@@ -240,8 +298,7 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 
 	validators := snap.ValSet.Copy()
 	// Check whether the committed seals are generated by parent's validators
-	validSeal := 0
-	proposalSeal := istanbulCore.PrepareCommittedSeal(header.Hash())
+	var sealers []common.Address
 	// 1. Get committed seals from current header
 	for _, seal := range extra.CommittedSeal {
 		// 2. Get the original address by seal and parent block hash
@@ -253,14 +310,14 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 		// Every validator can have only one seal. If more than one seals are signed by a
 		// validator, the validator cannot be found and errInvalidCommittedSeals is returned.
 		if validators.RemoveValidator(addr) {
-			validSeal += 1
+			sealers = append(sealers, addr)
 		} else {
 			return errInvalidCommittedSeals
 		}
 	}
 
-	// The length of validSeal should be larger than number of faulty node + 1
-	if validSeal <= 2*snap.ValSet.F() {
+	// The voting power of valid seals should reach the quorum
+	if int(snap.ValSet.VotingPowerOf(sealers)) < sealQuorum(snap.ValSet, number) {
 		return errInvalidCommittedSeals
 	}
 
@@ -337,6 +394,19 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.BlockHeade
 	}
 	header.ExtraData = extra
 
+	// include the double sign evidence of validators in snapshot
+	if number >= common.EvidenceForkHeight {
+		evidence := sb.evidence.Pending(number, func(address common.Address) bool {
+			_, v := snap.ValSet.GetByAddress(address)
+			return v != nil
+		})
+		if len(evidence) > 0 {
+			if err = writeEvidence(header, evidence); err != nil {
+				return err
+			}
+		}
+	}
+
 	// set header's timestamp
 	header.CreateTimestamp = new(big.Int).Add(parent.CreateTimestamp, new(big.Int).SetUint64(sb.config.BlockPeriod))
 	if header.CreateTimestamp.Int64() < time.Now().Unix() {
@@ -547,7 +617,7 @@ func (sb *backend) snapshot(chain consensus.ChainReader, height uint64, hash com
 	for i := 0; i < len(headers)/2; i++ {
 		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
 	}
-	snap, err := snap.apply(headers)
+	snap, err := snap.apply(headers, stakes(chain))
 	if err != nil {
 		return nil, err
 	}
@@ -563,6 +633,38 @@ func (sb *backend) snapshot(chain consensus.ChainReader, height uint64, hash com
 	return snap, err
 }
 
+// stateReader is the chain that provides the statedb of blocks
+type stateReader interface {
+	GetState(root common.Hash) (*state.Statedb, error)
+}
+
+// stakes returns the function to read the voting powers and BLS public keys of validators
+// from the staking contract, or nil if the chain could not provide the statedb.
+func stakes(chain consensus.ChainReader) stakeFunc {
+	reader, ok := chain.(stateReader)
+	if !ok {
+		return nil
+	}
+
+	return func(header *types.BlockHeader, validators []common.Address) (map[common.Address]uint64, map[common.Address][]byte, error) {
+		if header.Height < common.StakingForkHeight {
+			return nil, nil, nil
+		}
+
+		statedb, err := reader.GetState(header.StateHash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		powers, err := system.GetVotingPowers(statedb, header.Height, validators)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return powers, system.GetBLSPublicKeys(statedb, validators), nil
+	}
+}
+
 // FIXME: Need to update this for Istanbul
 // sigHash returns the hash which is used as input for the Istanbul
 // signing. It is the hash of the entire header apart from the 65 byte signature
@@ -643,6 +745,23 @@ func writeSeal(h *types.BlockHeader, seal []byte) error {
 	return nil
 }
 
+// writeEvidence writes the extra-data field of the given header with the double sign evidence.
+func writeEvidence(h *types.BlockHeader, evidence []*types.DoubleSignEvidence) error {
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra.Evidence = evidence
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	h.ExtraData = append(h.ExtraData[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
 // writeCommittedSeals writes the extra-data field of a block header with given committed seals.
 func writeCommittedSeals(h *types.BlockHeader, committedSeals [][]byte) error {
 	if len(committedSeals) == 0 {
@@ -671,3 +790,84 @@ func writeCommittedSeals(h *types.BlockHeader, committedSeals [][]byte) error {
 	h.ExtraData = append(h.ExtraData[:types.IstanbulExtraVanity], payload...)
 	return nil
 }
+
+// sealQuorum returns the minimum voting power of the committed seals of the header at height,
+// and the headers before the staking fork keep the legacy rule of more than 2F.
+func sealQuorum(valSet istanbul.ValidatorSet, height uint64) int {
+	if height < common.StakingForkHeight {
+		return 2*valSet.F() + 1
+	}
+	return valSet.QuorumSize()
+}
+
+// writeAggregatedSeal writes the extra-data field of a block header with the BLS aggregated
+// seal of the given committed seals, which are the ecdsa seals followed by the BLS seals.
+// The seals of unknown validators or invalid BLS seals are dropped, and it returns an error
+// if the remaining seals are not enough.
+func writeAggregatedSeal(h *types.BlockHeader, committedSeals [][]byte, snap *Snapshot) error {
+	proposalSeal := istanbulCore.PrepareCommittedSeal(h.Hash())
+	seals := make(map[common.Address][]byte)
+	var sealers []common.Address
+	for _, seal := range committedSeals {
+		if len(seal) != types.IstanbulExtraSeal+bls.SignatureSize {
+			continue
+		}
+
+		addr, err := istanbul.GetSignatureAddress(proposalSeal, seal[:types.IstanbulExtraSeal])
+		if err != nil {
+			continue
+		}
+
+		if _, v := snap.ValSet.GetByAddress(addr); v == nil || seals[addr] != nil {
+			continue
+		}
+
+		blsSeal := seal[types.IstanbulExtraSeal:]
+		if !verifyBLSSeal(snap.BLSKeys[addr], proposalSeal, blsSeal) {
+			continue
+		}
+
+		seals[addr] = blsSeal
+		sealers = append(sealers, addr)
+	}
+
+	if int(snap.ValSet.VotingPowerOf(sealers)) < sealQuorum(snap.ValSet, h.Height) {
+		return errInvalidCommittedSeals
+	}
+
+	aggregated, bitmap, err := utils.AggregateCommittedSeals(snap.validators(), seals)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra, err := types.ExtractIstanbulExtra(h)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra.CommittedSeal = [][]byte{}
+	istanbulExtra.AggregatedSeal, istanbulExtra.SignerBitmap = aggregated, bitmap
+
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	h.ExtraData = append(h.ExtraData[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// verifyBLSSeal returns whether the BLS seal is signed by the registered public key
+func verifyBLSSeal(key, msg, seal []byte) bool {
+	pk, err := bls.PublicKeyFromBytes(key)
+	if err != nil {
+		return false
+	}
+
+	sig, err := bls.SignatureFromBytes(seal)
+	if err != nil {
+		return false
+	}
+
+	return pk.Verify(msg, sig)
+}
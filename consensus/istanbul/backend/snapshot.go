@@ -45,8 +45,14 @@ type Snapshot struct {
 	Votes  []*Vote                  // List of votes cast in chronological order
 	Tally  map[common.Address]Tally // Current vote tally to avoid recalculating
 	ValSet istanbul.ValidatorSet    // Set of authorized validators at this moment
+
+	BLSKeys map[common.Address][]byte // Registered BLS public keys of validators at the last checkpoint
 }
 
+// stakeFunc returns the voting powers and the registered BLS public keys of validators in the
+// staking contract at the epoch block. The powers are nil if the validators are equally weighted.
+type stakeFunc func(header *types.BlockHeader, validators []common.Address) (map[common.Address]uint64, map[common.Address][]byte, error)
+
 // newSnapshot create a new snapshot with the specified startup parameters. This
 // method does not initialize the set of recent validators, so only ever use if for
 // the genesis block.
@@ -96,6 +102,13 @@ func (s *Snapshot) copy() *Snapshot {
 		Tally:  make(map[common.Address]Tally),
 	}
 
+	if s.BLSKeys != nil {
+		cpy.BLSKeys = make(map[common.Address][]byte)
+		for address, key := range s.BLSKeys {
+			cpy.BLSKeys[address] = key
+		}
+	}
+
 	for address, tally := range s.Tally {
 		cpy.Tally[address] = tally
 	}
@@ -148,8 +161,9 @@ func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
 }
 
 // apply creates a new authorization snapshot by applying the given headers to
-// the original one.
-func (s *Snapshot) apply(headers []*types.BlockHeader) (*Snapshot, error) {
+// the original one. The voting powers and BLS public keys of validators are refreshed at
+// the checkpoint blocks if stakes is not nil.
+func (s *Snapshot) apply(headers []*types.BlockHeader, stakes stakeFunc) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -240,6 +254,29 @@ func (s *Snapshot) apply(headers []*types.BlockHeader) (*Snapshot, error) {
 			}
 			delete(snap.Tally, header.Creator)
 		}
+		// Remove the validators proven to double sign by the evidence in header
+		if number >= common.EvidenceForkHeight {
+			evidence, err := types.HeaderEvidence(header)
+			if err != nil {
+				return nil, err
+			}
+			for _, ev := range evidence {
+				vote, err := ev.Verify()
+				if err != nil {
+					return nil, err
+				}
+				snap.removeOffender(vote.Signer)
+			}
+		}
+		// Refresh the voting powers and BLS public keys with the stakes at checkpoint block
+		if number%s.Epoch == 0 && stakes != nil {
+			weights, keys, err := stakes(header, snap.validators())
+			if err != nil {
+				return nil, err
+			}
+			snap.ValSet.SetVotingPowers(weights)
+			snap.BLSKeys = keys
+		}
 	}
 	snap.Height += uint64(len(headers))
 	snap.Hash = headers[len(headers)-1].Hash()
@@ -247,6 +284,25 @@ func (s *Snapshot) apply(headers []*types.BlockHeader) (*Snapshot, error) {
 	return snap, nil
 }
 
+// removeOffender removes the validator from the validator set, and discards the votes cast
+// by or around it.
+func (s *Snapshot) removeOffender(address common.Address) {
+	if !s.ValSet.RemoveValidator(address) {
+		return
+	}
+
+	for i := 0; i < len(s.Votes); i++ {
+		if s.Votes[i].Validator == address {
+			s.uncast(s.Votes[i].Address, s.Votes[i].Authorize)
+		}
+		if s.Votes[i].Validator == address || s.Votes[i].Address == address {
+			s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+			i--
+		}
+	}
+	delete(s.Tally, address)
+}
+
 // validators retrieves the list of authorized validators in ascending order.
 func (s *Snapshot) validators() []common.Address {
 	validators := make([]common.Address, 0, s.ValSet.Size())
@@ -271,19 +327,23 @@ type snapshotJSON struct {
 	Tally  map[common.Address]Tally `json:"tally"`
 
 	// for validator set
-	Validators []common.Address        `json:"validators"`
-	Policy     istanbul.ProposerPolicy `json:"policy"`
+	Validators   []common.Address          `json:"validators"`
+	Policy       istanbul.ProposerPolicy   `json:"policy"`
+	VotingPowers map[common.Address]uint64 `json:"votingPowers,omitempty"` // empty if equally weighted
+	BLSKeys      map[common.Address][]byte `json:"blsKeys,omitempty"`
 }
 
 func (s *Snapshot) toJSONStruct() *snapshotJSON {
 	return &snapshotJSON{
-		Epoch:      s.Epoch,
-		Number:     s.Height,
-		Hash:       s.Hash,
-		Votes:      s.Votes,
-		Tally:      s.Tally,
-		Validators: s.validators(),
-		Policy:     s.ValSet.Policy(),
+		Epoch:        s.Epoch,
+		Number:       s.Height,
+		Hash:         s.Hash,
+		Votes:        s.Votes,
+		Tally:        s.Tally,
+		Validators:   s.validators(),
+		Policy:       s.ValSet.Policy(),
+		VotingPowers: s.ValSet.VotingPowers(),
+		BLSKeys:      s.BLSKeys,
 	}
 }
 
@@ -300,6 +360,10 @@ func (s *Snapshot) UnmarshalJSON(b []byte) error {
 	s.Votes = j.Votes
 	s.Tally = j.Tally
 	s.ValSet = validator.NewSet(j.Validators, j.Policy)
+	if len(j.VotingPowers) > 0 {
+		s.ValSet.SetVotingPowers(j.VotingPowers)
+	}
+	s.BLSKeys = j.BLSKeys
 	return nil
 }
 
@@ -16,6 +16,7 @@ import (
 	"github.com/rcrowley/go-metrics"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/consensus/istanbul"
+	"github.com/scdoproject/go-stem/consensus/utils"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/log"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
@@ -34,12 +35,17 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		backlogsMu:         new(sync.Mutex),
 		pendingRequests:    prque.New(),
 		pendingRequestsMu:  new(sync.Mutex),
+		votes:              utils.NewVoteSet(),
+		walMessages:        make(map[common.Hash]bool),
 		consensusTimestamp: time.Time{},
 		roundMeter:         metrics.GetOrRegisterMeter("consensus/istanbul/core/round", nil),
 		sequenceMeter:      metrics.GetOrRegisterMeter("consensus/istanbul/core/sequence", nil),
 		consensusTimer:     metrics.GetOrRegisterTimer("consensus/istanbul/core/consensus", nil),
 	}
 	c.validateFn = c.checkValidatorSignature
+	if provider, ok := backend.(walProvider); ok {
+		c.wal = provider.WAL()
+	}
 	return c
 }
 
@@ -73,6 +79,15 @@ type core struct {
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
+	// the first signed votes of validators near the current view
+	votes *utils.VoteSet
+
+	// the write-ahead log of consensus, nil if not supported by backend
+	wal       *utils.WAL
+	replaying bool
+	// the hashes of the messages of current sequence in the write-ahead log
+	walMessages map[common.Hash]bool
+
 	consensusTimestamp time.Time
 	// the meter to record the round change rate
 	roundMeter metrics.Meter
@@ -82,6 +97,11 @@ type core struct {
 	consensusTimer metrics.Timer
 }
 
+// committedSealSigner is the backend that signs the committed seals to aggregate
+type committedSealSigner interface {
+	SignCommittedSeal(proposal istanbul.Proposal, seal []byte) ([]byte, error)
+}
+
 func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 	var err error
 	// Add sender address
@@ -92,7 +112,11 @@ func (c *core) finalizeMessage(msg *message) ([]byte, error) {
 	// Assign the CommittedSeal if it's a COMMIT message and proposal is not nil
 	if msg.Code == msgCommit && c.current.Proposal() != nil {
 		seal := PrepareCommittedSeal(c.current.Proposal().Hash())
-		msg.CommittedSeal, err = c.backend.Sign(seal)
+		if signer, ok := c.backend.(committedSealSigner); ok {
+			msg.CommittedSeal, err = signer.SignCommittedSeal(c.current.Proposal(), seal)
+		} else {
+			msg.CommittedSeal, err = c.backend.Sign(seal)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -124,6 +148,11 @@ func (c *core) broadcast(msg *message) {
 		return
 	}
 
+	// Persist the message before it is sent
+	if msg.Code != msgEvidence {
+		c.writeWAL(utils.WALMessage, payload)
+	}
+
 	// Broadcast payload
 	if err = c.backend.Broadcast(c.valSet, payload); err != nil {
 		c.logger.Error("Failed to broadcast message. msg %v. err %s. state %d", msg, err, c.state)
@@ -153,7 +182,12 @@ func (c *core) commit() {
 	if proposal != nil {
 		committedSeals := make([][]byte, c.current.Commits.Size())
 		for i, v := range c.current.Commits.Values() {
-			committedSeals[i] = make([]byte, types.IstanbulExtraSeal)
+			// the BLS seal follows the ecdsa seal after fork
+			size := types.IstanbulExtraSeal
+			if len(v.CommittedSeal) > size && proposal.Height() >= common.BLSSealForkHeight {
+				size = len(v.CommittedSeal)
+			}
+			committedSeals[i] = make([]byte, size)
 			copy(committedSeals[i][:], v.CommittedSeal[:])
 		}
 
@@ -207,6 +241,8 @@ func (c *core) startNewRound(round *big.Int) {
 			Round:    new(big.Int),
 		}
 		c.valSet = c.backend.Validators(lastProposal)
+		c.votes.Prune(newView.Sequence.Uint64())
+		c.truncateWAL(newView.Sequence.Uint64())
 	}
 
 	// Clear invalid ROUND CHANGE messages
@@ -214,7 +250,7 @@ func (c *core) startNewRound(round *big.Int) {
 	// New snapshot for new round
 	c.updateRoundState(newView, c.valSet, roundChange)
 	// Calculate new proposer
-	c.valSet.CalcProposer(lastProposer, newView.Round.Uint64())
+	c.valSet.CalcProposer(lastProposer, newView.Sequence.Uint64(), newView.Round.Uint64())
 	c.waitingForRoundChange = false
 	c.setState(StateAcceptRequest)
 	if roundChange && c.isProposer() && c.current != nil {
@@ -239,6 +275,7 @@ func (c *core) catchUpRound(view *istanbul.View) {
 		c.roundMeter.Mark(new(big.Int).Sub(view.Round, c.current.Round()).Int64())
 	}
 	c.waitingForRoundChange = true
+	c.writeWAL(utils.WALRound, view.Round.Bytes())
 
 	// Need to keep block locked for round catching up
 	c.updateRoundState(view, c.valSet, true)
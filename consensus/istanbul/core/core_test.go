@@ -11,7 +11,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/consensus/istanbul"
+	"github.com/scdoproject/go-stem/consensus/utils"
 	"github.com/scdoproject/go-stem/core/types"
 )
 
@@ -64,3 +66,99 @@ func TestNewRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestReplayWAL(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	backend := sys.backends[0]
+	c := backend.engine.(*core)
+
+	wal, err := utils.NewWAL(backend.db, "wal")
+	if err != nil {
+		t.Fatalf("failed to create WAL: %v", err)
+	}
+	c.wal = wal
+	c.roundChangeSet = newRoundChangeSet(c.valSet)
+
+	// the round moved by timeout before restart
+	c.writeWAL(utils.WALRound, big.NewInt(2).Bytes())
+	c.current = newRoundState(&istanbul.View{
+		Round:    big.NewInt(0),
+		Sequence: big.NewInt(1),
+	}, c.valSet, common.Hash{}, nil, nil, func(hash common.Hash) bool {
+		return false
+	})
+
+	c.replayWAL()
+	defer c.stopTimer()
+
+	if c.current.Round().Cmp(big.NewInt(2)) != 0 || !c.waitingForRoundChange {
+		t.Errorf("round mismatch: have %v, want 2", c.current.Round())
+	}
+
+	// nothing is written during replay
+	entries, _ := wal.Entries(1)
+	if len(entries) != 1 {
+		t.Errorf("entries mismatch: have %d, want 1", len(entries))
+	}
+
+	// truncated after sequence committed
+	c.truncateWAL(2)
+	if entries, _ = wal.Entries(1); len(entries) != 0 {
+		t.Errorf("entries mismatch: have %d, want 0", len(entries))
+	}
+}
+
+func TestWriteMessageWAL(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	backend := sys.backends[0]
+	c := backend.engine.(*core)
+
+	wal, err := utils.NewWAL(backend.db, "wal")
+	if err != nil {
+		t.Fatalf("failed to create WAL: %v", err)
+	}
+	c.wal = wal
+	c.current = newRoundState(&istanbul.View{
+		Round:    big.NewInt(0),
+		Sequence: big.NewInt(1),
+	}, c.valSet, common.Hash{}, nil, nil, func(hash common.Hash) bool {
+		return false
+	})
+
+	newPrepare := func(sequence int64, address common.Address) *message {
+		subject, _ := Encode(&istanbul.Subject{
+			View:   &istanbul.View{Round: big.NewInt(0), Sequence: big.NewInt(sequence)},
+			Digest: common.StringToHash("proposal"),
+		})
+		return &message{Code: msgPrepare, Msg: subject, Address: address}
+	}
+
+	other := sys.backends[1].Address()
+	msg := newPrepare(1, other)
+	payload, _ := msg.Payload()
+	c.writeMessageWAL(msg, payload)
+
+	// duplicated message is skipped
+	c.writeMessageWAL(msg, payload)
+
+	// message of other sequences is skipped
+	future := newPrepare(2, other)
+	futurePayload, _ := future.Payload()
+	c.writeMessageWAL(future, futurePayload)
+
+	// own message is skipped, which is persisted before broadcast
+	own := newPrepare(1, c.address)
+	ownPayload, _ := own.Payload()
+	c.writeMessageWAL(own, ownPayload)
+
+	entries, _ := wal.Entries(1)
+	if len(entries) != 1 {
+		t.Fatalf("entries mismatch: have %d, want 1", len(entries))
+	}
+	if !reflect.DeepEqual(entries[0].Data, payload) {
+		t.Errorf("entry mismatch: have %x, want %x", entries[0].Data, payload)
+	}
+	if entries, _ = wal.Entries(2); len(entries) != 0 {
+		t.Errorf("entries mismatch: have %d, want 0", len(entries))
+	}
+}
@@ -32,4 +32,6 @@ var (
 	errFailedDecodeCommit = errors.New("failed to decode COMMIT")
 	// errFailedDecodeMessageSet is returned when the message set is malformed.
 	errFailedDecodeMessageSet = errors.New("failed to decode message set")
+	// errFailedDecodeEvidence is returned when the EVIDENCE message is malformed.
+	errFailedDecodeEvidence = errors.New("failed to decode EVIDENCE")
 )
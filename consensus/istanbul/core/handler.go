@@ -18,6 +18,8 @@ func (c *core) Start() error {
 	// Tests will handle events itself, so we have to make subscribeEvents()
 	// be able to call in test.
 	c.subscribeEvents()
+	// Resume the round state before restart
+	c.replayWAL()
 	go c.handleEvents()
 
 	return nil
@@ -96,6 +98,7 @@ func (c *core) handleEvents() {
 						c.logger.Warn("Get message payload failed", "err", err)
 						continue
 					}
+					c.writeMessageWAL(ev.msg, p)
 					c.backend.Gossip(c.valSet, p)
 				}
 			}
@@ -137,7 +140,18 @@ func (c *core) handleMsg(payload []byte) error {
 		return istanbul.ErrUnauthorizedAddress
 	}
 
-	return c.handleCheckedMsg(msg, src)
+	if msg.Code == msgEvidence {
+		return c.handleEvidence(msg)
+	}
+
+	c.detectDoubleSign(payload)
+	if err := c.handleCheckedMsg(msg, src); err != nil {
+		return err
+	}
+
+	// Own messages are persisted before broadcast
+	c.writeMessageWAL(msg, payload)
+	return nil
 }
 
 func (c *core) handleCheckedMsg(msg *message, src istanbul.Validator) error {
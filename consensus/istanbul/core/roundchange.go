@@ -67,24 +67,29 @@ func (c *core) handleRoundChange(msg *message, src istanbul.Validator) error {
 	cv := c.currentView()
 	roundView := rc.View
 
-	// Add the ROUND CHANGE message to its message set and return how many
-	// messages we've got with the same round number and sequence number.
-	num, err := c.roundChangeSet.Add(roundView.Round, msg)
+	// Add the ROUND CHANGE message to its message set and return the voting
+	// power we've got with the same round number and sequence number.
+	power, err := c.roundChangeSet.Add(roundView.Round, msg)
 	if err != nil {
 		c.logger.Warn("Failed to add round change message. from %v. msg %v. err %s", src, msg, err)
 		return err
 	}
 
-	// Once we received f+1 ROUND CHANGE messages, those messages form a weak certificate.
+	// whether the voting power reaches the threshold with the message of src
+	reached := func(threshold int) bool {
+		return power >= threshold && power-int(src.VotingPower()) < threshold
+	}
+
+	// Once we received f+1 ROUND CHANGE voting power, those messages form a weak certificate.
 	// If our round number is smaller than the certificate's round number, we would
 	// try to catch up the round number.
-	if c.waitingForRoundChange && num == int(c.valSet.F()+1) {
+	if c.waitingForRoundChange && reached(c.valSet.F()+1) {
 		if cv.Round.Cmp(roundView.Round) < 0 {
 			c.sendRoundChange(roundView.Round)
 		}
 		return nil
-	} else if num == int(2*c.valSet.F()+1) && (c.waitingForRoundChange || cv.Round.Cmp(roundView.Round) < 0) {
-		// We've received 2f+1 ROUND CHANGE messages, start a new round immediately.
+	} else if reached(c.valSet.QuorumSize()) && (c.waitingForRoundChange || cv.Round.Cmp(roundView.Round) < 0) {
+		// We've received a quorum of ROUND CHANGE voting power, start a new round immediately.
 		c.startNewRound(roundView.Round)
 		return nil
 	} else if cv.Round.Cmp(roundView.Round) < 0 {
@@ -123,7 +128,7 @@ func (rcs *roundChangeSet) Add(r *big.Int, msg *message) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return rcs.roundChanges[round].Size(), nil
+	return rcs.roundChanges[round].VotingPower(), nil
 }
 
 // Clear deletes the messages with smaller round
@@ -138,14 +143,14 @@ func (rcs *roundChangeSet) Clear(round *big.Int) {
 	}
 }
 
-// MaxRound returns the max round which the number of messages is equal or larger than num
-func (rcs *roundChangeSet) MaxRound(num int) *big.Int {
+// MaxRound returns the max round which the voting power of messages is equal or larger than power
+func (rcs *roundChangeSet) MaxRound(power int) *big.Int {
 	rcs.mu.Lock()
 	defer rcs.mu.Unlock()
 
 	var maxRound *big.Int
 	for k, rms := range rcs.roundChanges {
-		if rms.Size() < num {
+		if rms.VotingPower() < power {
 			continue
 		}
 		r := big.NewInt(int64(k))
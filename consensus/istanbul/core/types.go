@@ -60,6 +60,7 @@ const (
 	msgPrepare
 	msgCommit
 	msgRoundChange
+	msgEvidence
 	msgAll
 )
 
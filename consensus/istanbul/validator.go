@@ -15,6 +15,9 @@ type Validator interface {
 	// Address returns address
 	Address() common.Address
 
+	// VotingPower returns the weight of validator to reach quorum
+	VotingPower() uint64
+
 	// String representation of Validator
 	String() string
 }
@@ -38,8 +41,8 @@ func (slice Validators) Swap(i, j int) {
 // ----------------------------------------------------------------------------
 
 type ValidatorSet interface {
-	// Calculate the proposer
-	CalcProposer(lastProposer common.Address, round uint64)
+	// Calculate the proposer of the sequence and round
+	CalcProposer(lastProposer common.Address, sequence uint64, round uint64)
 	// Return the validator size
 	Size() int
 	// Return the validator array
@@ -58,8 +61,18 @@ type ValidatorSet interface {
 	RemoveValidator(address common.Address) bool
 	// Copy validator set
 	Copy() ValidatorSet
-	// Get the maximum number of faulty nodes
+	// Set the voting powers of validators, the validators are equally weighted if powers is empty
+	SetVotingPowers(powers map[common.Address]uint64)
+	// Return the voting powers of validators, or nil if equally weighted
+	VotingPowers() map[common.Address]uint64
+	// Return the sum of voting powers
+	TotalVotingPower() uint64
+	// Return the sum of voting powers of the given addresses in the set
+	VotingPowerOf(addrs []common.Address) uint64
+	// Get the maximum voting power of faulty nodes
 	F() int
+	// Get the minimum voting power of a quorum, which is ceil(2T/3) of the total voting power T
+	QuorumSize() int
 	// Get proposer policy
 	Policy() ProposerPolicy
 }
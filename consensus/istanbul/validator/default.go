@@ -7,6 +7,7 @@ package validator
 
 import (
 	"math"
+	"math/bits"
 	"reflect"
 	"sort"
 	"sync"
@@ -16,13 +17,18 @@ import (
 )
 
 type defaultValidator struct {
-	address common.Address
+	address     common.Address
+	votingPower uint64
 }
 
 func (val *defaultValidator) Address() common.Address {
 	return val.address
 }
 
+func (val *defaultValidator) VotingPower() uint64 {
+	return val.votingPower
+}
+
 func (val *defaultValidator) String() string {
 	return val.Address().String()
 }
@@ -36,6 +42,9 @@ type defaultSet struct {
 	proposer    istanbul.Validator
 	validatorMu sync.RWMutex
 	selector    istanbul.ProposalSelector
+
+	// weighted is whether the validators are weighted by stake, otherwise the voting power is 1
+	weighted bool
 }
 
 func newDefaultSet(addrs []common.Address, policy istanbul.ProposerPolicy) *defaultSet {
@@ -100,9 +109,13 @@ func (valSet *defaultSet) IsProposer(address common.Address) bool {
 	return reflect.DeepEqual(valSet.GetProposer(), val)
 }
 
-func (valSet *defaultSet) CalcProposer(lastProposer common.Address, round uint64) {
+func (valSet *defaultSet) CalcProposer(lastProposer common.Address, sequence uint64, round uint64) {
 	valSet.validatorMu.RLock()
 	defer valSet.validatorMu.RUnlock()
+	if valSet.weighted && valSet.policy == istanbul.RoundRobin {
+		valSet.proposer = weightedRoundRobinProposer(valSet.validators, sequence+round)
+		return
+	}
 	valSet.proposer = valSet.selector(valSet, lastProposer, round)
 }
 
@@ -132,6 +145,49 @@ func roundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Address, r
 	return valSet.GetByIndex(pick)
 }
 
+// weightedRoundRobinProposer picks the proposer of slot in the schedule of validators weighted by
+// voting power, in which every validator proposes as many times as its voting power in each cycle
+// of total voting power slots.
+func weightedRoundRobinProposer(validators istanbul.Validators, slot uint64) istanbul.Validator {
+	total := uint64(0)
+	for _, val := range validators {
+		total += val.VotingPower()
+	}
+	if total == 0 {
+		return nil
+	}
+	pick := spreadSlot(slot%total, total)
+	for _, val := range validators {
+		if pick < val.VotingPower() {
+			return val
+		}
+		pick -= val.VotingPower()
+	}
+	return nil
+}
+
+// spreadSlot maps the slot in [0, total) to the position in the weighted schedule. The stride is
+// coprime to total so that the mapping is a permutation, and the consecutive slots are spread
+// over the validators instead of running through the voting power of one validator.
+func spreadSlot(slot uint64, total uint64) uint64 {
+	stride := uint64(float64(total) * 0.618)
+	if stride == 0 {
+		stride = 1
+	}
+	for gcd(stride, total) != 1 {
+		stride++
+	}
+	hi, lo := bits.Mul64(slot, stride)
+	return bits.Rem64(hi, lo, total)
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
 func stickyProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
 	if valSet.Size() == 0 {
 		return nil
@@ -154,7 +210,12 @@ func (valSet *defaultSet) AddValidator(address common.Address) bool {
 			return false
 		}
 	}
-	valSet.validators = append(valSet.validators, New(address))
+	// the validator added between epochs has no voting power until the stake is refreshed
+	val := &defaultValidator{address: address, votingPower: 1}
+	if valSet.weighted {
+		val.votingPower = 0
+	}
+	valSet.validators = append(valSet.validators, val)
 	// TODO: we may not need to re-sort it again
 	// sort validator
 	sort.Sort(valSet.validators)
@@ -179,12 +240,87 @@ func (valSet *defaultSet) Copy() istanbul.ValidatorSet {
 	defer valSet.validatorMu.RUnlock()
 
 	addresses := make([]common.Address, 0, len(valSet.validators))
+	powers := make(map[common.Address]uint64)
 	for _, v := range valSet.validators {
 		addresses = append(addresses, v.Address())
+		powers[v.Address()] = v.VotingPower()
 	}
-	return NewSet(addresses, valSet.policy)
+	cpy := newDefaultSet(addresses, valSet.policy)
+	if valSet.weighted {
+		cpy.SetVotingPowers(powers)
+	}
+	return cpy
 }
 
-func (valSet *defaultSet) F() int { return int(math.Ceil(float64(valSet.Size())/3)) - 1 }
+// SetVotingPowers replaces the voting powers of validators, the validators not in powers have no
+// voting power. The validators are equally weighted if none of them has voting power.
+func (valSet *defaultSet) SetVotingPowers(powers map[common.Address]uint64) {
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+
+	total := uint64(0)
+	for _, v := range valSet.validators {
+		total += powers[v.Address()]
+	}
+	valSet.weighted = total > 0
+
+	validators := make([]istanbul.Validator, len(valSet.validators))
+	for i, v := range valSet.validators {
+		val := &defaultValidator{address: v.Address(), votingPower: 1}
+		if valSet.weighted {
+			val.votingPower = powers[v.Address()]
+		}
+		validators[i] = val
+		if valSet.proposer != nil && valSet.proposer.Address() == val.address {
+			valSet.proposer = val
+		}
+	}
+	valSet.validators = validators
+}
+
+func (valSet *defaultSet) VotingPowers() map[common.Address]uint64 {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	if !valSet.weighted {
+		return nil
+	}
+	powers := make(map[common.Address]uint64)
+	for _, v := range valSet.validators {
+		powers[v.Address()] = v.VotingPower()
+	}
+	return powers
+}
+
+func (valSet *defaultSet) TotalVotingPower() uint64 {
+	total := uint64(0)
+	for _, v := range valSet.List() {
+		total += v.VotingPower()
+	}
+	return total
+}
+
+func (valSet *defaultSet) VotingPowerOf(addrs []common.Address) uint64 {
+	power := uint64(0)
+	for _, addr := range addrs {
+		if _, v := valSet.GetByAddress(addr); v != nil {
+			power += v.VotingPower()
+		}
+	}
+	return power
+}
+
+// F returns the maximum voting power of faulty nodes, which is the number of faulty nodes if
+// the validators are equally weighted.
+func (valSet *defaultSet) F() int { return int(math.Ceil(float64(valSet.TotalVotingPower())/3)) - 1 }
+
+// QuorumSize returns the minimum voting power of a quorum, which is ceil(2T/3) of the total
+// voting power T, and at least 1 so that the empty set never reaches a quorum.
+func (valSet *defaultSet) QuorumSize() int {
+	total := valSet.TotalVotingPower()
+	if total == 0 {
+		return 1
+	}
+	return int((2*total + 2) / 3)
+}
 
 func (valSet *defaultSet) Policy() istanbul.ProposerPolicy { return valSet.policy }
@@ -29,6 +29,7 @@ func TestValidatorSet(t *testing.T) {
 	testEmptyValSet(t)
 	testStickyProposer(t)
 	testAddAndRemoveValidator(t)
+	testWeightedValSet(t)
 }
 
 func testNewValidatorSet(t *testing.T) {
@@ -103,17 +104,17 @@ func testNormalValSet(t *testing.T) {
 	}
 	// test calculate proposer
 	lastProposer := addr1
-	valSet.CalcProposer(lastProposer, uint64(0))
+	valSet.CalcProposer(lastProposer, 1, uint64(0))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
-	valSet.CalcProposer(lastProposer, uint64(3))
+	valSet.CalcProposer(lastProposer, 1, uint64(3))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val1) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val1)
 	}
 	// test empty last proposer
 	lastProposer = common.Address{}
-	valSet.CalcProposer(lastProposer, uint64(3))
+	valSet.CalcProposer(lastProposer, 1, uint64(3))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
@@ -184,19 +185,70 @@ func testStickyProposer(t *testing.T) {
 	}
 	// test calculate proposer
 	lastProposer := addr1
-	valSet.CalcProposer(lastProposer, uint64(0))
+	valSet.CalcProposer(lastProposer, 1, uint64(0))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val1) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val1)
 	}
 
-	valSet.CalcProposer(lastProposer, uint64(1))
+	valSet.CalcProposer(lastProposer, 1, uint64(1))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
 	// test empty last proposer
 	lastProposer = common.Address{}
-	valSet.CalcProposer(lastProposer, uint64(3))
+	valSet.CalcProposer(lastProposer, 1, uint64(3))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
 }
+
+func testWeightedValSet(t *testing.T) {
+	addr1 := common.BigToAddress(big.NewInt(1))
+	addr2 := common.BigToAddress(big.NewInt(2))
+	addr3 := common.BigToAddress(big.NewInt(3))
+	valSet := NewSet([]common.Address{addr1, addr2, addr3}, istanbul.RoundRobin)
+	assert.Equal(t, uint64(3), valSet.TotalVotingPower())
+	assert.Equal(t, 0, valSet.F())
+	assert.Equal(t, 2, valSet.QuorumSize())
+	assert.Nil(t, valSet.VotingPowers())
+
+	// addr3 has no stake
+	valSet.SetVotingPowers(map[common.Address]uint64{addr1: 6, addr2: 2})
+	assert.Equal(t, uint64(8), valSet.TotalVotingPower())
+	assert.Equal(t, 2, valSet.F())
+	assert.Equal(t, 6, valSet.QuorumSize())
+	assert.Equal(t, uint64(6), valSet.VotingPowerOf([]common.Address{addr1, addr3}))
+	assert.Equal(t, map[common.Address]uint64{addr1: 6, addr2: 2, addr3: 0}, valSet.VotingPowers())
+
+	// proposals are in proportion to voting power in each cycle
+	proposals := make(map[common.Address]int)
+	for seq := uint64(0); seq < 8; seq++ {
+		valSet.CalcProposer(common.Address{}, seq, 0)
+		proposals[valSet.GetProposer().Address()]++
+	}
+	assert.Equal(t, map[common.Address]int{addr1: 6, addr2: 2}, proposals)
+
+	// the next round is the next slot
+	valSet.CalcProposer(addr1, 3, 1)
+	proposer := valSet.GetProposer()
+	valSet.CalcProposer(addr2, 4, 0)
+	assert.Equal(t, proposer, valSet.GetProposer())
+
+	// copy keeps the voting powers, and the new validator has no voting power
+	cpy := valSet.Copy()
+	assert.Equal(t, valSet.VotingPowers(), cpy.VotingPowers())
+	addr4 := common.BigToAddress(big.NewInt(4))
+	cpy.AddValidator(addr4)
+	assert.Equal(t, uint64(8), cpy.TotalVotingPower())
+
+	// the quorum is 2/3 of the total voting power if it is a multiple of 3, which is above 2F+1
+	valSet.SetVotingPowers(map[common.Address]uint64{addr1: 100, addr2: 100, addr3: 100})
+	assert.Equal(t, 99, valSet.F())
+	assert.Equal(t, 200, valSet.QuorumSize())
+	assert.True(t, 2*valSet.F()+1 < valSet.QuorumSize())
+
+	// equally weighted without stake
+	valSet.SetVotingPowers(nil)
+	assert.Equal(t, uint64(3), valSet.TotalVotingPower())
+	assert.Nil(t, valSet.VotingPowers())
+}
@@ -12,7 +12,8 @@ import (
 
 func New(addr common.Address) istanbul.Validator {
 	return &defaultValidator{
-		address: addr,
+		address:     addr,
+		votingPower: 1,
 	}
 }
 
@@ -98,6 +98,9 @@ func (engine *Engine) Seal(reader consensus.ChainReader, block *types.Block, sto
 		step = math.MaxUint64 / uint64(threads)
 	}
 
+	// attach the sealing block to the logs of all threads
+	sealLog := engine.log.With(log.Height(block.Header.Height), log.Shard(block.Header.Creator.Shard()))
+
 	var isNonceFound int32
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	once := &sync.Once{}
@@ -119,7 +122,7 @@ func (engine *Engine) Seal(reader consensus.ChainReader, block *types.Block, sto
 		}
 
 		go func(tseed uint64, tmin uint64, tmax uint64) {
-			StartMining(block, tseed, tmin, tmax, results, stop, &isNonceFound, once, engine.hashrate, engine.log)
+			StartMining(block, tseed, tmin, tmax, results, stop, &isNonceFound, once, engine.hashrate, sealLog)
 		}(tSeed, min, max)
 	}
 
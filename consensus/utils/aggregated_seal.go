@@ -0,0 +1,103 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"errors"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto/bls"
+)
+
+var (
+	// ErrInvalidSignerBitmap is returned if the signer bitmap does not match the validators
+	ErrInvalidSignerBitmap = errors.New("invalid signer bitmap")
+	// ErrMissingBLSKey is returned if the signer has not registered the BLS public key
+	ErrMissingBLSKey = errors.New("signer has no registered BLS public key")
+	// ErrInvalidAggregatedSeal is returned if the aggregated seal is not signed by the signers
+	ErrInvalidAggregatedSeal = errors.New("invalid aggregated seal")
+)
+
+// AggregateCommittedSeals aggregates the BLS committed seals of signers into one seal, and
+// returns the bitmap that marks the signers in validators, which is in ascending order of address.
+func AggregateCommittedSeals(validators []common.Address, seals map[common.Address][]byte) ([]byte, []byte, error) {
+	bitmap := make([]byte, (len(validators)+7)/8)
+	sigs := make([]*bls.Signature, 0, len(seals))
+	for i, validator := range validators {
+		seal, ok := seals[validator]
+		if !ok {
+			continue
+		}
+
+		sig, err := bls.SignatureFromBytes(seal)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		bitmap[i/8] |= 1 << uint(i%8)
+		sigs = append(sigs, sig)
+	}
+
+	if len(sigs) != len(seals) {
+		return nil, nil, ErrInvalidSignerBitmap
+	}
+
+	aggregated, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return aggregated.Bytes(), bitmap, nil
+}
+
+// VerifyAggregatedSeal checks the aggregated seal of msg against the BLS public keys of signers
+// marked in the bitmap, and returns the signers.
+func VerifyAggregatedSeal(validators []common.Address, keys map[common.Address][]byte, bitmap, seal, msg []byte) ([]common.Address, error) {
+	if len(bitmap) != (len(validators)+7)/8 {
+		return nil, ErrInvalidSignerBitmap
+	}
+
+	var signers []common.Address
+	var pks []*bls.PublicKey
+	for i := 0; i < len(bitmap)*8; i++ {
+		if bitmap[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+
+		// bits out of the validators must be unset
+		if i >= len(validators) {
+			return nil, ErrInvalidSignerBitmap
+		}
+
+		key, ok := keys[validators[i]]
+		if !ok {
+			return nil, ErrMissingBLSKey
+		}
+
+		pk, err := bls.PublicKeyFromBytes(key)
+		if err != nil {
+			return nil, err
+		}
+
+		signers = append(signers, validators[i])
+		pks = append(pks, pk)
+	}
+
+	if len(signers) == 0 {
+		return nil, ErrInvalidSignerBitmap
+	}
+
+	sig, err := bls.SignatureFromBytes(seal)
+	if err != nil {
+		return nil, ErrInvalidAggregatedSeal
+	}
+
+	if !bls.VerifyAggregate(pks, msg, sig) {
+		return nil, ErrInvalidAggregatedSeal
+	}
+
+	return signers, nil
+}
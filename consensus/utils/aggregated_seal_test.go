@@ -0,0 +1,56 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto/bls"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AggregatedSeal(t *testing.T) {
+	msg := []byte("committed seal")
+	validators := make([]common.Address, 9)
+	keys := make(map[common.Address][]byte)
+	seals := make(map[common.Address][]byte)
+	for i := range validators {
+		validators[i] = common.BytesToAddress([]byte{byte(i + 1)})
+		sk := bls.DeriveKey([]byte{byte(i)})
+		keys[validators[i]] = sk.PublicKey().Bytes()
+
+		if i%2 == 0 {
+			seals[validators[i]] = sk.Sign(msg).Bytes()
+		}
+	}
+
+	seal, bitmap, err := AggregateCommittedSeals(validators, seals)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x55, 0x01}, bitmap)
+
+	signers, err := VerifyAggregatedSeal(validators, keys, bitmap, seal, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{validators[0], validators[2], validators[4], validators[6], validators[8]}, signers)
+
+	// claim an extra signer
+	_, err = VerifyAggregatedSeal(validators, keys, []byte{0x57, 0x01}, seal, msg)
+	assert.Equal(t, ErrInvalidAggregatedSeal, err)
+
+	// bit out of validators
+	_, err = VerifyAggregatedSeal(validators, keys, []byte{0x55, 0x03}, seal, msg)
+	assert.Equal(t, ErrInvalidSignerBitmap, err)
+
+	// signer without BLS key
+	delete(keys, validators[0])
+	_, err = VerifyAggregatedSeal(validators, keys, bitmap, seal, msg)
+	assert.Equal(t, ErrMissingBLSKey, err)
+
+	// seal of unknown signer
+	seals[common.BytesToAddress([]byte{0xff})] = seals[validators[0]]
+	_, _, err = AggregateCommittedSeals(validators, seals)
+	assert.Equal(t, ErrInvalidSignerBitmap, err)
+}
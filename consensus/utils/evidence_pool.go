@@ -0,0 +1,144 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+const (
+	// MaxBlockEvidence is the max number of double sign evidence included in a block
+	MaxBlockEvidence = 8
+
+	// maxPoolEvidence is the max number of evidence kept in pool, the lowest ones are dropped
+	maxPoolEvidence = 1024
+)
+
+// Evidence is the verified double sign evidence in pool
+type Evidence struct {
+	Hash     common.Hash
+	Offender common.Address
+	Code     uint64 // code of the conflicting consensus messages
+	Height   uint64
+	Round    uint64
+	First    string // hex payload of the first signed message
+	Second   string // hex payload of the second signed message
+
+	evidence *types.DoubleSignEvidence
+}
+
+// EvidencePool keeps the double sign evidence detected by the node or gossiped by other
+// validators, which is included in the proposed blocks until the offender is removed.
+type EvidencePool struct {
+	lock     sync.RWMutex
+	evidence map[common.Hash]*Evidence
+}
+
+// NewEvidencePool creates an empty evidence pool
+func NewEvidencePool() *EvidencePool {
+	return &EvidencePool{
+		evidence: make(map[common.Hash]*Evidence),
+	}
+}
+
+// Add verifies the evidence and adds it into pool. It returns false if the evidence exists.
+func (pool *EvidencePool) Add(ev *types.DoubleSignEvidence) (bool, error) {
+	hash := ev.Hash()
+
+	pool.lock.RLock()
+	_, ok := pool.evidence[hash]
+	pool.lock.RUnlock()
+	if ok {
+		return false, nil
+	}
+
+	vote, err := ev.Verify()
+	if err != nil {
+		return false, err
+	}
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if _, ok = pool.evidence[hash]; ok {
+		return false, nil
+	}
+
+	if len(pool.evidence) >= maxPoolEvidence {
+		lowest := pool.sorted()[0]
+		if lowest.Height >= vote.Height {
+			return false, nil
+		}
+		delete(pool.evidence, lowest.Hash)
+	}
+
+	pool.evidence[hash] = &Evidence{
+		Hash:     hash,
+		Offender: vote.Signer,
+		Code:     vote.Code,
+		Height:   vote.Height,
+		Round:    vote.Round,
+		First:    hexutil.BytesToHex(ev.First),
+		Second:   hexutil.BytesToHex(ev.Second),
+		evidence: ev,
+	}
+
+	return true, nil
+}
+
+// List returns all evidence in pool in ascending order of height
+func (pool *EvidencePool) List() []*Evidence {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	return pool.sorted()
+}
+
+// Pending returns at most MaxBlockEvidence evidence lower than height whose offender is still
+// authorized, which should be included in the block of height.
+func (pool *EvidencePool) Pending(height uint64, authorized func(common.Address) bool) []*types.DoubleSignEvidence {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	var pending []*types.DoubleSignEvidence
+	offenders := make(map[common.Address]bool)
+	for _, ev := range pool.sorted() {
+		if ev.Height >= height || len(pending) >= MaxBlockEvidence {
+			break
+		}
+
+		// one evidence is enough to remove the offender
+		if offenders[ev.Offender] || !authorized(ev.Offender) {
+			continue
+		}
+
+		offenders[ev.Offender] = true
+		pending = append(pending, ev.evidence)
+	}
+
+	return pending
+}
+
+func (pool *EvidencePool) sorted() []*Evidence {
+	list := make([]*Evidence, 0, len(pool.evidence))
+	for _, ev := range pool.evidence {
+		list = append(list, ev)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Height != list[j].Height {
+			return list[i].Height < list[j].Height
+		}
+		return list[i].Hash.String() < list[j].Hash.String()
+	})
+
+	return list
+}
@@ -0,0 +1,86 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCommitVote returns the commit message payload that key signed for the digest at height and round
+func newCommitVote(t *testing.T, key *ecdsa.PrivateKey, height, round uint64, digest string) []byte {
+	subject, err := rlp.EncodeToBytes([]interface{}{
+		[]interface{}{new(big.Int).SetUint64(round), new(big.Int).SetUint64(height)},
+		common.StringToHash(digest),
+	})
+	assert.NoError(t, err)
+
+	msg := []interface{}{uint64(2), subject, *crypto.GetAddress(&key.PublicKey), []byte{}, []byte{}}
+	data, err := rlp.EncodeToBytes(msg)
+	assert.NoError(t, err)
+
+	sig, err := crypto.Sign(key, crypto.Keccak256(data))
+	assert.NoError(t, err)
+	msg[3] = sig.Sig
+
+	payload, err := rlp.EncodeToBytes(msg)
+	assert.NoError(t, err)
+	return payload
+}
+
+// newCommitEvidence returns the evidence that key signed commits of two proposals at height
+func newCommitEvidence(t *testing.T, key *ecdsa.PrivateKey, height uint64) *types.DoubleSignEvidence {
+	return types.NewDoubleSignEvidence(newCommitVote(t, key, height, 0, "a"), newCommitVote(t, key, height, 0, "b"))
+}
+
+func Test_EvidencePool(t *testing.T) {
+	pool := NewEvidencePool()
+
+	_, key1 := crypto.MustGenerateShardKeyPair(1)
+	_, key2 := crypto.MustGenerateShardKeyPair(1)
+	offender1, offender2 := *crypto.GetAddress(&key1.PublicKey), *crypto.GetAddress(&key2.PublicKey)
+
+	ev1 := newCommitEvidence(t, key1, 10)
+	added, err := pool.Add(ev1)
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	// known evidence
+	added, err = pool.Add(ev1)
+	assert.NoError(t, err)
+	assert.False(t, added)
+
+	// invalid evidence
+	_, err = pool.Add(types.NewDoubleSignEvidence(ev1.First, ev1.First))
+	assert.Equal(t, types.ErrInvalidEvidence, err)
+
+	ev2 := newCommitEvidence(t, key2, 12)
+	_, err = pool.Add(ev2)
+	assert.NoError(t, err)
+
+	list := pool.List()
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, offender1, list[0].Offender)
+	assert.Equal(t, uint64(10), list[0].Height)
+	assert.Equal(t, uint64(2), list[0].Code)
+	assert.Equal(t, ev1.Hash(), list[0].Hash)
+
+	authorized := func(address common.Address) bool { return true }
+	assert.Equal(t, []*types.DoubleSignEvidence{ev1}, pool.Pending(11, authorized))
+	assert.Equal(t, []*types.DoubleSignEvidence{ev1, ev2}, pool.Pending(13, authorized))
+
+	// offender already removed
+	pending := pool.Pending(13, func(address common.Address) bool { return address != offender1 })
+	assert.Equal(t, []*types.DoubleSignEvidence{ev2}, pending)
+	assert.Equal(t, offender2, list[1].Offender)
+}
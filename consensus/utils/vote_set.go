@@ -0,0 +1,94 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+const (
+	// voteSequenceWindow is the number of sequences after the current one whose votes are recorded
+	voteSequenceWindow = 1
+
+	// voteRoundWindow is the number of rounds after the current one whose votes are recorded
+	voteRoundWindow = 10
+)
+
+// EvidenceHandler is the backend that collects the double sign evidence
+type EvidenceHandler interface {
+	// HandleEvidence verifies and stores the evidence, and returns false if it is known
+	HandleEvidence(ev *types.DoubleSignEvidence) (bool, error)
+}
+
+// voteKey identifies the vote of a validator in a step of round
+type voteKey struct {
+	signer common.Address
+	code   uint64
+	height uint64
+	round  uint64
+}
+
+// signedVote is the first vote received for a voteKey
+type signedVote struct {
+	digest  common.Hash
+	payload []byte
+}
+
+// VoteSet records the first signed vote of validators in each step of round to detect double
+// sign. Only the votes within a small window of sequences and rounds from the current view are
+// recorded, so that it is never filled up by the votes far ahead.
+type VoteSet struct {
+	votes map[voteKey]*signedVote
+}
+
+// NewVoteSet returns an empty vote set
+func NewVoteSet() *VoteSet {
+	return &VoteSet{
+		votes: make(map[voteKey]*signedVote),
+	}
+}
+
+// Add records the vote of the signed consensus message payload received in the current view
+// of sequence and round, and returns the double sign evidence if the signer has voted for
+// another proposal in the same step of round.
+func (s *VoteSet) Add(payload []byte, sequence, round uint64) *types.DoubleSignEvidence {
+	vote, err := types.DecodeConsensusVote(payload)
+	if err != nil {
+		return nil
+	}
+
+	if vote.Height < sequence || vote.Height > sequence+voteSequenceWindow || vote.Round > round+voteRoundWindow {
+		return nil
+	}
+
+	key := voteKey{vote.Signer, vote.Code, vote.Height, vote.Round}
+	prev, ok := s.votes[key]
+	if !ok {
+		s.votes[key] = &signedVote{vote.Digest, payload}
+		return nil
+	}
+
+	if prev.digest == vote.Digest {
+		return nil
+	}
+
+	return types.NewDoubleSignEvidence(prev.payload, payload)
+}
+
+// Prune removes the votes lower than the sequence
+func (s *VoteSet) Prune(sequence uint64) {
+	for key := range s.votes {
+		if key.height < sequence {
+			delete(s.votes, key)
+		}
+	}
+}
+
+// Size returns the number of recorded votes
+func (s *VoteSet) Size() int {
+	return len(s.votes)
+}
@@ -0,0 +1,49 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VoteSet(t *testing.T) {
+	set := NewVoteSet()
+	_, key := crypto.MustGenerateShardKeyPair(1)
+
+	// the first vote and the same vote again
+	first := newCommitVote(t, key, 10, 1, "a")
+	assert.Nil(t, set.Add(first, 10, 1))
+	assert.Nil(t, set.Add(first, 10, 1))
+
+	// the conflicting vote in the same round
+	second := newCommitVote(t, key, 10, 1, "b")
+	ev := set.Add(second, 10, 1)
+	assert.NotNil(t, ev)
+	offense, err := ev.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), offense.Height)
+
+	// the vote in another round is not conflicting
+	assert.Nil(t, set.Add(newCommitVote(t, key, 10, 2, "b"), 10, 1))
+	assert.Equal(t, 2, set.Size())
+
+	// the votes out of window are not recorded
+	assert.Nil(t, set.Add(newCommitVote(t, key, 9, 1, "a"), 10, 1))
+	assert.Nil(t, set.Add(newCommitVote(t, key, 10+voteSequenceWindow+1, 0, "a"), 10, 1))
+	assert.Nil(t, set.Add(newCommitVote(t, key, 10, 1+voteRoundWindow+1, "a"), 10, 1))
+	assert.Equal(t, 2, set.Size())
+
+	// the next sequence is in window
+	assert.Nil(t, set.Add(newCommitVote(t, key, 11, 0, "a"), 10, 1))
+	assert.NotNil(t, set.Add(newCommitVote(t, key, 11, 0, "b"), 10, 1))
+	assert.Equal(t, 3, set.Size())
+
+	set.Prune(11)
+	assert.Equal(t, 1, set.Size())
+}
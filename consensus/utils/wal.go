@@ -0,0 +1,173 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/database"
+)
+
+const (
+	// WALMessage is the entry of a signed consensus message payload
+	WALMessage byte = iota
+	// WALRound is the entry of a round the validator moved to without a message, e.g. timeout
+	WALRound
+)
+
+// WALEntry is an entry of the consensus write-ahead log
+type WALEntry struct {
+	Kind byte
+	Data []byte
+}
+
+// walHead is the height of the log and the number of entries
+type walHead struct {
+	Height uint64
+	Size   uint64
+}
+
+// WAL is the write-ahead log of the consensus messages and round changes of a height, which
+// is replayed after restart to resume the round state. The entries of a height are dropped
+// when the log moves to a higher height after the block is committed.
+type WAL struct {
+	db     database.Database
+	prefix []byte
+	head   walHead
+	lock   sync.Mutex
+}
+
+// NewWAL loads the write-ahead log with the key prefix from db
+func NewWAL(db database.Database, prefix string) (*WAL, error) {
+	wal := &WAL{
+		db:     db,
+		prefix: []byte(prefix),
+	}
+
+	if has, err := db.Has(wal.prefix); err != nil || !has {
+		return wal, err
+	}
+
+	value, err := db.Get(wal.prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = rlp.DecodeBytes(value, &wal.head); err != nil {
+		return nil, err
+	}
+
+	return wal, nil
+}
+
+// Height returns the height of entries in log
+func (wal *WAL) Height() uint64 {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	return wal.head.Height
+}
+
+// Append appends the entry of the height into log. The entries of lower heights are dropped
+// first, and the entry of a height lower than the log is ignored.
+func (wal *WAL) Append(height uint64, kind byte, data []byte) error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	if height < wal.head.Height {
+		return nil
+	}
+
+	entry, err := rlp.EncodeToBytes(&WALEntry{kind, data})
+	if err != nil {
+		return err
+	}
+
+	batch := wal.db.NewBatch()
+	head := wal.truncate(batch, height)
+	batch.Put(wal.entryKey(head.Size), entry)
+	head.Size++
+
+	return wal.commit(batch, head)
+}
+
+// Entries returns the entries of the height in order, or nil if the log is of other height
+func (wal *WAL) Entries(height uint64) ([]*WALEntry, error) {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	if height != wal.head.Height {
+		return nil, nil
+	}
+
+	entries := make([]*WALEntry, 0, wal.head.Size)
+	for i := uint64(0); i < wal.head.Size; i++ {
+		value, err := wal.db.Get(wal.entryKey(i))
+		if err != nil {
+			return nil, err
+		}
+
+		var entry WALEntry
+		if err = rlp.DecodeBytes(value, &entry); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// Truncate drops the entries lower than the height, which is called when the blocks lower
+// than height are committed.
+func (wal *WAL) Truncate(height uint64) error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	if height <= wal.head.Height {
+		return nil
+	}
+
+	batch := wal.db.NewBatch()
+	return wal.commit(batch, wal.truncate(batch, height))
+}
+
+// truncate deletes the entries in batch if the log is lower than height, and returns the new head
+func (wal *WAL) truncate(batch database.Batch, height uint64) walHead {
+	if height == wal.head.Height {
+		return wal.head
+	}
+
+	for i := uint64(0); i < wal.head.Size; i++ {
+		batch.Delete(wal.entryKey(i))
+	}
+
+	return walHead{Height: height}
+}
+
+func (wal *WAL) commit(batch database.Batch, head walHead) error {
+	value, err := rlp.EncodeToBytes(&head)
+	if err != nil {
+		return err
+	}
+
+	batch.Put(wal.prefix, value)
+	if err = batch.Commit(); err != nil {
+		return err
+	}
+
+	wal.head = head
+	return nil
+}
+
+func (wal *WAL) entryKey(index uint64) []byte {
+	key := make([]byte, len(wal.prefix)+8)
+	copy(key, wal.prefix)
+	binary.BigEndian.PutUint64(key[len(wal.prefix):], index)
+	return key
+}
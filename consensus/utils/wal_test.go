@@ -0,0 +1,61 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WAL(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	wal, err := NewWAL(db, "wal")
+	assert.NoError(t, err)
+
+	assert.NoError(t, wal.Append(5, WALMessage, []byte{1}))
+	assert.NoError(t, wal.Append(5, WALRound, []byte{2}))
+
+	// lower height is ignored
+	assert.NoError(t, wal.Append(4, WALMessage, []byte{3}))
+
+	entries, err := wal.Entries(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []*WALEntry{{WALMessage, []byte{1}}, {WALRound, []byte{2}}}, entries)
+
+	// reload after restart
+	wal, err = NewWAL(db, "wal")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), wal.Height())
+
+	entries, err = wal.Entries(5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+
+	entries, err = wal.Entries(6)
+	assert.NoError(t, err)
+	assert.Nil(t, entries)
+
+	// truncate after height 5 is committed
+	assert.NoError(t, wal.Truncate(6))
+	entries, err = wal.Entries(6)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	has, err := db.Has(wal.entryKey(0))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// append to a higher height drops the lower entries
+	assert.NoError(t, wal.Append(6, WALMessage, []byte{4}))
+	assert.NoError(t, wal.Append(7, WALMessage, []byte{5}))
+	entries, err = wal.Entries(7)
+	assert.NoError(t, err)
+	assert.Equal(t, []*WALEntry{{WALMessage, []byte{5}}}, entries)
+}
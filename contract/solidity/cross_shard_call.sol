@@ -0,0 +1,31 @@
+pragma solidity ^0.4.24;
+
+/*
+ * Contracts could call contracts of other shards after the hard fork at CrossShardCallForkHeight.
+ *
+ * The call from a contract to the contract of other shard returns immediately without result,
+ * and is carried by a debt to the target shard, where it is executed when the debt is applied.
+ * All gas forwarded to the call is reserved as the gas allowance in target shard, so the gas
+ * should be specified explicitly. The transaction also pays the delivery of the debt and reply.
+ *
+ * After the call, a reply debt is sent back to call onCrossShardCall of the origin contract
+ * with the left allowance. The value of failed call is refunded by the reply, and is received
+ * even if the origin contract does not implement the callback.
+ */
+contract CrossShardCallback {
+    // callHash is the hash of debt that carries the cross shard call.
+    function onCrossShardCall(bytes32 callHash, bool success, bytes result) public payable;
+}
+
+contract CrossShardCaller is CrossShardCallback {
+    event Called(bytes32 callHash, bool success, bytes result);
+
+    // call sends the call to the contract of other shard with the gas allowance.
+    function call(address target, uint256 gasAllowance, bytes input) public payable {
+        require(target.call.gas(gasAllowance).value(msg.value)(input));
+    }
+
+    function onCrossShardCall(bytes32 callHash, bool success, bytes result) public payable {
+        emit Called(callHash, success, result);
+    }
+}
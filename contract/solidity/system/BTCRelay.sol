@@ -0,0 +1,51 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+/*
+ * BTCRelay is the interface of btc-relay system contract at 0x0105.
+ *
+ * After the hard fork at BTCRelayForkHeight, the relay is a SPV client of bitcoin. Anyone
+ * could relay the raw 80 bytes block headers, which are validated with proof of work and
+ * difficulty retarget, and the chain with most work is the main chain. The tx is verified
+ * with the JSON encoded BTCTxProof, e.g. {"BlockHash":"","TxHex":"","Index":1,"Branch":[],
+ * "Confirmations":6}, where the hashes are hex in the bitcoind display order.
+ */
+library BTCRelay {
+    uint8 constant CMD_VERIFY_TX = 0;
+    uint8 constant CMD_RELAY_TX = 1;
+    uint8 constant CMD_STORE_BLOCK_HEADER = 2;
+    uint8 constant CMD_GET_BLOCK_HEADER = 3;
+    uint8 constant CMD_STORE_BLOCK_HEADERS = 4;
+    uint8 constant CMD_GET_BEST_BLOCK_HEADER = 5;
+    uint8 constant CMD_GET_BLOCK_HEADER_INFO = 6;
+
+    // verifyTx verifies the btc tx with the JSON encoded BTCTxProof, and the fee
+    // is paid to the relayer of the block. The call fails if the block is not on
+    // the main chain or has not enough confirmations.
+    function verifyTx(uint256 fee, bytes request) internal returns (bool) {
+        return SystemContract.toBool(SystemContract.call(SystemContract.BTC_RELAY, fee, CMD_VERIFY_TX, request));
+    }
+
+    // relayTx verifies the btc tx the same as verifyTx, and relays it to the RelayAddress.
+    function relayTx(uint256 fee, bytes request) internal returns (bool) {
+        return SystemContract.toBool(SystemContract.call(SystemContract.BTC_RELAY, fee, CMD_RELAY_TX, request));
+    }
+
+    // storeBlockHeader stores the raw btc block header, and the calling contract is
+    // the relayer of the block.
+    function storeBlockHeader(bytes header) internal returns (bool) {
+        return SystemContract.toBool(SystemContract.call(SystemContract.BTC_RELAY, 0, CMD_STORE_BLOCK_HEADER, header));
+    }
+
+    // storeBlockHeaders stores at most 50 concatenated raw headers, the parent first.
+    function storeBlockHeaders(bytes headers) internal returns (bool) {
+        return SystemContract.toBool(SystemContract.call(SystemContract.BTC_RELAY, 0, CMD_STORE_BLOCK_HEADERS, headers));
+    }
+
+    // hasBlockHeader returns whether the btc block header is stored, and the fee
+    // is paid to the relayer of the block.
+    function hasBlockHeader(uint256 fee, bytes32 blockHash) internal returns (bool) {
+        return SystemContract.toBool(SystemContract.call(SystemContract.BTC_RELAY, fee, CMD_GET_BLOCK_HEADER, abi.encodePacked(blockHash)));
+    }
+}
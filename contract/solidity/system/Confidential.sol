@@ -0,0 +1,28 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+/*
+ * Confidential is the interface of confidential transfer system contract at 0x0107.
+ *
+ * After the hard fork at ConfidentialTransferForkHeight, the accounts could opt in the
+ * confidential transfers, whose amounts are hidden in the Pedersen commitments and each
+ * transfer carries the bulletproof range proofs that no output is negative. The confidential
+ * account is created by the owner with its public key, so EVM contracts could only query
+ * the confidential accounts.
+ */
+library Confidential {
+    uint8 constant CMD_CREATE = 0;
+    uint8 constant CMD_DEPOSIT = 1;
+    uint8 constant CMD_TRANSFER = 2;
+    uint8 constant CMD_WITHDRAW = 3;
+    uint8 constant CMD_APPLY = 4;
+    uint8 constant CMD_GET = 5;
+
+    // getAccount returns the JSON encoded confidential account of the owner, e.g.
+    // {"PublicKey":"","Balance":"","Note":"","Pending":[{"Commitment":"","Note":""}]},
+    // where the balance and pending transfers are the 33 bytes compressed commitments.
+    function getAccount(address owner) internal returns (bytes) {
+        return SystemContract.call(SystemContract.CONFIDENTIAL, 0, CMD_GET, abi.encodePacked(owner));
+    }
+}
@@ -0,0 +1,78 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+/*
+ * DomainName is the interface of domain name system contract at 0x0101.
+ *
+ * After the hard fork at DomainNameServiceForkHeight, the domain name is registered for
+ * periods paid by the callvalue, and expires unless renewed. The owner could transfer the
+ * domain name, create subdomains, e.g. "pay.alice", and set the resolver records.
+ *
+ * The commands with several parameters take JSON encoded parameter, e.g.
+ * {"Name":"alice","Owner":"0x..."}, and the query commands fail if the name is not
+ * registered or expired.
+ */
+library DomainName {
+    uint8 constant CMD_CREATE = 0;
+    uint8 constant CMD_GET_OWNER = 1;
+    uint8 constant CMD_TRANSFER = 2;
+    uint8 constant CMD_RENEW = 3;
+    uint8 constant CMD_SET_SUBDOMAIN_OWNER = 4;
+    uint8 constant CMD_SET_ADDRESS = 5;
+    uint8 constant CMD_SET_ABI_HASH = 6;
+    uint8 constant CMD_SET_TEXT = 7;
+    uint8 constant CMD_SET_REVERSE_NAME = 8;
+    uint8 constant CMD_RESOLVE = 9;
+    uint8 constant CMD_REVERSE_RESOLVE = 10;
+    uint8 constant CMD_GET_RECORD = 11;
+
+    // register registers the domain name, and the calling contract is the owner.
+    // The name contains only numbers, letters and dash lines, and at most 32 bytes.
+    // The fee is a multiple of the fee per period, which is 1 SCDO for about one year.
+    function register(bytes name, uint256 fee) internal returns (address owner) {
+        return SystemContract.toAddress(SystemContract.call(SystemContract.DOMAIN_NAME, fee, CMD_CREATE, name));
+    }
+
+    // ownerOf returns the owner of the domain name, the call fails if not registered.
+    function ownerOf(bytes name) internal returns (address owner) {
+        return SystemContract.toAddress(SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_GET_OWNER, name));
+    }
+
+    // renew extends the registration of top level domain name with the fee.
+    function renew(bytes name, uint256 fee) internal {
+        SystemContract.call(SystemContract.DOMAIN_NAME, fee, CMD_RENEW, name);
+    }
+
+    // transfer transfers the domain name owned by the calling contract, param is {"Name":"","Owner":""}.
+    function transfer(bytes param) internal {
+        SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_TRANSFER, param);
+    }
+
+    // setSubdomainOwner creates the subdomain or changes its owner, param is {"Name":"sub.parent","Owner":""}.
+    function setSubdomainOwner(bytes param) internal {
+        SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_SET_SUBDOMAIN_OWNER, param);
+    }
+
+    // setAddress sets the address in its shard that the domain name resolves to, param is {"Name":"","Address":""}.
+    function setAddress(bytes param) internal {
+        SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_SET_ADDRESS, param);
+    }
+
+    // setReverseName sets the domain name that the calling contract resolves to reversely,
+    // and the domain name should resolve to the calling contract.
+    function setReverseName(bytes name) internal {
+        SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_SET_REVERSE_NAME, name);
+    }
+
+    // resolve returns the address of the domain name in the shard, or the first address
+    // if no address in the shard, param is {"Name":"","Shard":1}.
+    function resolve(bytes param) internal returns (address addr) {
+        return SystemContract.toAddress(SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_RESOLVE, param));
+    }
+
+    // nameOf returns the domain name that the address resolves to reversely.
+    function nameOf(address addr) internal returns (bytes name) {
+        return SystemContract.call(SystemContract.DOMAIN_NAME, 0, CMD_REVERSE_RESOLVE, abi.encodePacked(addr));
+    }
+}
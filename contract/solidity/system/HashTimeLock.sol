@@ -0,0 +1,38 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+// HashTimeLock is the interface of HTLC system contract at 0x0103.
+//
+// The HTLC is keyed by the hash of the message, which is derived from the tx hash,
+// the calling contract and the index of system contract call in the tx. The key is
+// the "Tx.Hash" field of the returned JSON.
+library HashTimeLock {
+    uint8 constant CMD_NEW = 0;
+    uint8 constant CMD_WITHDRAW = 1;
+    uint8 constant CMD_REFUND = 2;
+    uint8 constant CMD_GET = 3;
+
+    // lock locks the value with the JSON encoded HashTimeLock, e.g.
+    // {"HashLock":"0x...","TimeLock":1546272000,"To":"0x..."}, and the calling contract
+    // could refund after the time lock. Returns the JSON encoded HTLC.
+    function lock(uint256 value, bytes hashTimeLock) internal returns (bytes htlc) {
+        return SystemContract.call(SystemContract.HASH_TIME_LOCK, value, CMD_NEW, hashTimeLock);
+    }
+
+    // withdraw withdraws the value to the calling contract with the JSON encoded
+    // Withdrawing, e.g. {"Hash":"0x...","Preimage":"0x..."}.
+    function withdraw(bytes withdrawing) internal returns (bytes htlc) {
+        return SystemContract.call(SystemContract.HASH_TIME_LOCK, 0, CMD_WITHDRAW, withdrawing);
+    }
+
+    // refund refunds the value to the calling contract after the time lock.
+    function refund(bytes32 key) internal returns (bytes htlc) {
+        return SystemContract.call(SystemContract.HASH_TIME_LOCK, 0, CMD_REFUND, abi.encodePacked(key));
+    }
+
+    // get returns the JSON encoded HTLC of the key.
+    function get(bytes32 key) internal returns (bytes htlc) {
+        return SystemContract.call(SystemContract.HASH_TIME_LOCK, 0, CMD_GET, abi.encodePacked(key));
+    }
+}
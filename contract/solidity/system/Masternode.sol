@@ -0,0 +1,57 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+/*
+ * Masternode is the interface of masternode system contract at 0x0104.
+ *
+ * After the hard fork at MasternodeForkHeight, the masternode registers its endpoint
+ * with the deposit, proves liveness once per epoch with a signed block hash, and shares
+ * the block reward. The masternode that signs two block hashes of the same epoch could
+ * be slashed by anyone. Liveness proofs are signed by the private key of the masternode
+ * account, so they are submitted by the node client rather than contracts.
+ */
+library Masternode {
+    uint8 constant CMD_DEPOSIT = 0;
+    uint8 constant CMD_QUERY = 1;
+    uint8 constant CMD_RECALL = 2;
+    uint8 constant CMD_QUIT = 3;
+    uint8 constant CMD_UPDATE_ENDPOINT = 4;
+    uint8 constant CMD_PROVE_LIVENESS = 5;
+    uint8 constant CMD_SLASH = 6;
+    uint8 constant CMD_GET_MASTERNODES = 7;
+    uint8 constant CMD_GET_MASTERNODE = 8;
+
+    // deposit registers the calling contract as masternode, and the value
+    // must be exactly 20000 scdo. After fork, param is {"Endpoint":""}.
+    function deposit(uint256 value, bytes param) internal {
+        SystemContract.call(SystemContract.MASTERNODE, value, CMD_DEPOSIT, param);
+    }
+
+    // updateEndpoint changes the endpoint of the calling masternode.
+    function updateEndpoint(bytes endpoint) internal {
+        SystemContract.call(SystemContract.MASTERNODE, 0, CMD_UPDATE_ENDPOINT, endpoint);
+    }
+
+    // slash removes the masternode that signed two block hashes of the same epoch, and pays
+    // 10 percent of the deposit to the calling contract, param is {"First":{},"Second":{}}.
+    function slash(bytes param) internal {
+        SystemContract.call(SystemContract.MASTERNODE, 0, CMD_SLASH, param);
+    }
+
+    // isMasternode returns whether the address is a masternode.
+    function isMasternode(address node) internal returns (bool) {
+        return SystemContract.toBool(SystemContract.call(SystemContract.MASTERNODE, 0, CMD_QUERY, abi.encodePacked(node)));
+    }
+
+    // quit quits the masternode.
+    function quit(address node) internal {
+        SystemContract.call(SystemContract.MASTERNODE, 0, CMD_QUIT, abi.encodePacked(node));
+    }
+
+    // recall returns the deposit about one day after quit, to the masternode after fork
+    // or to the calling contract before fork.
+    function recall(address node) internal {
+        SystemContract.call(SystemContract.MASTERNODE, 0, CMD_RECALL, abi.encodePacked(node));
+    }
+}
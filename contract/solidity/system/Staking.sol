@@ -0,0 +1,57 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+/*
+ * Staking is the interface of staking system contract at 0x0106.
+ *
+ * After the hard fork at StakingForkHeight, the validators of bft consensus bond stake
+ * to weight their votes. The bonded stake becomes active after the bonding period of 360
+ * blocks, and the unbonded stake is withdrawable after the unbonding period of 8640 blocks.
+ * The voting power is the active stake in units of 1 scdo, and the validator sets are
+ * refreshed at every epoch boundary.
+ *
+ * The validators register the BLS public keys with the proof of possession, which verify
+ * the aggregated commit seals of block headers after the hard fork at BLSSealForkHeight.
+ */
+library Staking {
+    uint8 constant CMD_BOND = 0;
+    uint8 constant CMD_UNBOND = 1;
+    uint8 constant CMD_WITHDRAW = 2;
+    uint8 constant CMD_GET_STAKE = 3;
+    uint8 constant CMD_GET_STAKES = 4;
+    uint8 constant CMD_REGISTER_BLS_KEY = 5;
+    uint8 constant CMD_GET_BLS_KEY = 6;
+
+    // bond bonds the value as stake of the calling contract, at least 1 scdo.
+    function bond(uint256 value) internal {
+        SystemContract.call(SystemContract.STAKING, value, CMD_BOND, "");
+    }
+
+    // unbond unbonds the active stake of the calling contract.
+    function unbond(uint256 amount) internal {
+        SystemContract.call(SystemContract.STAKING, 0, CMD_UNBOND, abi.encodePacked(amount));
+    }
+
+    // withdraw pays the unbonded stake that passed the unbonding period to the calling contract.
+    function withdraw() internal {
+        SystemContract.call(SystemContract.STAKING, 0, CMD_WITHDRAW, "");
+    }
+
+    // getStake returns the JSON encoded stake of the staker, e.g. {"Address":"","Active":0,
+    // "Bonding":0,"Unbonding":0,"VotingPower":0}.
+    function getStake(address staker) internal returns (bytes) {
+        return SystemContract.call(SystemContract.STAKING, 0, CMD_GET_STAKE, abi.encodePacked(staker));
+    }
+
+    // registerBLSKey registers the 128 bytes BLS public key of the calling contract with the
+    // 64 bytes proof of possession.
+    function registerBLSKey(bytes publicKey, bytes proof) internal {
+        SystemContract.call(SystemContract.STAKING, 0, CMD_REGISTER_BLS_KEY, abi.encodePacked(publicKey, proof));
+    }
+
+    // getBLSKey returns the registered BLS public key of the address.
+    function getBLSKey(address validator) internal returns (bytes) {
+        return SystemContract.call(SystemContract.STAKING, 0, CMD_GET_BLS_KEY, abi.encodePacked(validator));
+    }
+}
@@ -0,0 +1,20 @@
+pragma solidity ^0.4.24;
+
+import "./SystemContract.sol";
+
+// SubChain is the interface of sub-chain system contract at 0x0102.
+library SubChain {
+    uint8 constant CMD_REGISTER = 0;
+    uint8 constant CMD_QUERY = 1;
+
+    // register registers the sub-chain with the JSON encoded SubChainInfo, and the
+    // calling contract is the owner.
+    function register(bytes info) internal {
+        SystemContract.call(SystemContract.SUB_CHAIN, 0, CMD_REGISTER, info);
+    }
+
+    // query returns the JSON encoded SubChainInfo of the sub-chain name.
+    function query(bytes name) internal returns (bytes info) {
+        return SystemContract.call(SystemContract.SUB_CHAIN, 0, CMD_QUERY, name);
+    }
+}
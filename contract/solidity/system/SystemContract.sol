@@ -0,0 +1,53 @@
+pragma solidity ^0.4.24;
+
+/*
+ * The system contracts of Scdo are native contracts at reserved addresses, which are
+ * callable by EVM contracts after the hard fork at SystemContractCallForkHeight.
+ *
+ * The input of system contract is the command byte followed by the command parameter,
+ * e.g. JSON encoded request or raw bytes. The system contract should be called directly,
+ * and the callvalue is transferred to the system contract. Both delegatecall and callcode
+ * fail, and the state changes are discarded in a static call.
+ *
+ * The call fails if the command fails, and all gas forwarded to the call is consumed.
+ */
+library SystemContract {
+    address constant DOMAIN_NAME = 0x0000000000000000000000000000000000000101;
+    address constant SUB_CHAIN = 0x0000000000000000000000000000000000000102;
+    address constant HASH_TIME_LOCK = 0x0000000000000000000000000000000000000103;
+    address constant MASTERNODE = 0x0000000000000000000000000000000000000104;
+    address constant BTC_RELAY = 0x0000000000000000000000000000000000000105;
+    address constant STAKING = 0x0000000000000000000000000000000000000106;
+    address constant CONFIDENTIAL = 0x0000000000000000000000000000000000000107;
+
+    // call runs the command of system contract with the value, and returns the result.
+    function call(address target, uint256 value, uint8 cmd, bytes param) internal returns (bytes result) {
+        bytes memory input = abi.encodePacked(cmd, param);
+        bool success;
+
+        assembly {
+            success := call(gas, target, value, add(input, 0x20), mload(input), 0, 0)
+
+            result := mload(0x40)
+            mstore(result, returndatasize)
+            returndatacopy(add(result, 0x20), 0, returndatasize)
+            mstore(0x40, add(add(result, 0x20), and(add(returndatasize, 0x1f), not(0x1f))))
+        }
+
+        require(success, "system contract call failed");
+    }
+
+    // toAddress converts the 20 bytes result to address.
+    function toAddress(bytes b) internal pure returns (address addr) {
+        require(b.length == 20, "invalid address");
+
+        assembly {
+            addr := div(mload(add(b, 0x20)), 0x1000000000000000000000000)
+        }
+    }
+
+    // toBool converts the 1 byte result to bool.
+    function toBool(bytes b) internal pure returns (bool) {
+        return b.length == 1 && b[0] != 0;
+    }
+}
@@ -8,7 +8,13 @@ import "./PriorityQueue.sol";
 
 /// @title A PBFT consensus subchain contract in Scdo root chain
 /// @notice You can use this contract for a PBFT consensus subchain in Scdo.
-/// @dev The contract is based on the fact that the operator is trustworthy.
+/// @dev The contract is based on the fact that the operator is trustworthy. The operators
+/// vote the subchain checkpoint every relay range blocks, which is accepted with the votes
+/// of 2/3 operators. The exit root of checkpoint is the merkle root of the accounts touched
+/// in the range, and the user exits with the merkle proof of its balance. The exit could be
+/// challenged with the proof of a later checkpoint that the account has spent since. The
+/// exited balance is not debited in the subchain, so the user exits again only with a higher
+/// nonce, and only the balance above the exited one.
 /// @author scdodev@scdoproject.pro
 contract PBFTRootchain {
     using SafeMath for uint256;
@@ -31,6 +37,19 @@ contract PBFTRootchain {
         uint256 timestamp;
     }
 
+    /** @dev Checkpoint related */
+    mapping(uint256 => Checkpoint) public checkpoints;
+    struct Checkpoint{
+        bytes32 blockHash;
+        bytes32 stateRoot;
+        bytes32 txRoot;
+        bytes32 exitRoot;
+        bytes32 verifiersHash;
+        uint256 timestamp;
+    }
+    mapping(uint256 => mapping(address => bytes32)) public checkpointVotes;
+    mapping(bytes32 => uint256) public checkpointVoteCounts;
+
     /** @dev User related */
     uint256 public userDepositBond = 1234567890;
     uint256 public userExitBond = 1234567890;
@@ -43,7 +62,13 @@ contract PBFTRootchain {
         uint256 deposit;
         uint256 bond;
         uint256 timestamp;
+        uint256 height;
+        uint256 nonce;
     }
+    mapping(address => bool) public exiting;
+    mapping(address => uint256) public exitedHeights;
+    mapping(address => uint256) public exitedNonces;
+    mapping(address => uint256) public exitedBalances;
 
     address private _owner;
 
@@ -55,6 +80,8 @@ contract PBFTRootchain {
     event FinalizeUserExit(address indexed user, uint256 amount, uint256 exitNonce);
     event SubmitBlock(address indexed operator, bytes32 root, uint256 timestamp);
     event ChallengedUserExit(address indexed user, uint256 exitNonce, address indexed operator);
+    event VoteCheckpoint(address indexed operator, uint256 height, bytes32 hash);
+    event SubmitCheckpoint(uint256 height, bytes32 hash, uint256 timestamp);
 
     /** @dev Reverts if called by any account other than the owner. */
     modifier onlyOwner() {
@@ -180,40 +207,57 @@ contract PBFTRootchain {
     }
 
     /**
-     * @dev The user starts to exit the value of the subchain
-     * @param value User exits value
+     * @dev The user starts to exit the balance of the subchain at the checkpoint, except the
+     * balance exited before. The user must have sent txs since the last exit.
+     * @param height The height of checkpoint
+     * @param balance The balance of user at the checkpoint
+     * @param nonce The nonce of user at the checkpoint
+     * @param index The leaf index of user in the exit merkle tree
+     * @param proof The merkle proof of user from bottom to top
      */
-    function startExit(uint256 value) public payable {
+    function startExit(uint256 height, uint256 balance, uint256 nonce, uint256 index, bytes32[] proof) public payable {
         require(msg.value >= userExitBond, "Insufficient user exit value");
+        require(!exiting[msg.sender], "You have an exit in progress");
+        require(height > exitedHeights[msg.sender], "You have exited at a later checkpoint");
+        require(exitedHeights[msg.sender] == 0 || nonce > exitedNonces[msg.sender], "You have not sent any tx since the last exit");
+        require(balance > exitedBalances[msg.sender], "You have exited the balance");
+        require(isExitLeaf(height, msg.sender, balance, nonce, index, proof), "Invalid exit proof");
 
-        uint256 nonce = exitNonce;
+        uint256 exitID = exitNonce;
         exitNonce = exitNonce.add(1);
 
         Exit memory exit = Exit({
             user: msg.sender,
-            deposit: value,
+            deposit: balance.sub(exitedBalances[msg.sender]),
             bond: msg.value,
-            timestamp: block.timestamp
+            timestamp: block.timestamp,
+            height: height,
+            nonce: nonce
         });
-        userExits[nonce] = exit;
-        userExitQueue.insert(nonce);
+        userExits[exitID] = exit;
+        userExitQueue.insert(exitID);
+        exiting[msg.sender] = true;
 
-        emit StartUserExit(exit.user, exit.deposit, exit.bond, nonce);
+        emit StartUserExit(exit.user, exit.deposit, exit.bond, exitID);
     }
 
     /**
      * @notice Finalizing is an expensive operation if the queue is large
      * @dev finalize All valid exits
      */
-    function finalizeExits() public onlyOperator{
+    function finalizeExits() public {
         require(userExitQueue.currentSize() > 0, "All user exits have been finalized");
 
         uint256 nonce = userExitQueue.getMin();
         Exit memory exit = userExits[nonce];
         while(block.timestamp.sub(exit.timestamp) >= exitTimeLimit){
-            if (exit.deposit > 0){
+            if (exit.user != address(0)){
                 uint256 amount = exit.deposit.add(exit.bond);
                 require(address(this).balance >= amount, "I don't have enough money to pay this finalize amount");
+                exiting[exit.user] = false;
+                exitedHeights[exit.user] = exit.height;
+                exitedNonces[exit.user] = exit.nonce;
+                exitedBalances[exit.user] = exitedBalances[exit.user].add(exit.deposit);
                 exit.user.transfer(amount);
                 emit FinalizeUserExit(exit.user, amount, nonce);
 
@@ -232,23 +276,88 @@ contract PBFTRootchain {
     }
 
     /**
-     * @dev Used to challenge users to exit illegally. If successful, the user
-     * exits with a failure and the bond is confiscated to the challenger.
-     * @param u The user who exits
-     * @param nonce The nonce of user exits
-     * @notice The operator must be reliabale
+     * @dev Used to challenge users to exit illegally with the proof that the user has spent
+     * at a later checkpoint. If successful, the user exits with a failure and the bond is
+     * confiscated to the challenger.
+     * @param exitID The nonce of user exits
+     * @param height The height of later checkpoint
+     * @param balance The balance of user at the later checkpoint
+     * @param nonce The nonce of user at the later checkpoint
+     * @param index The leaf index of user in the exit merkle tree
+     * @param proof The merkle proof of user from bottom to top
      */
-    function challengeUserExit(address u, uint256 nonce) public onlyOperator{
-        Exit memory exit = userExits[nonce];
-        require(exit.user == u && exit.deposit > 0, "This user exit could not be found or has been finalized");
-        require(block.timestamp - exit.timestamp < exitTimeLimit, "This user exit has exceeded the challenge period");
+    function challengeExit(uint256 exitID, uint256 height, uint256 balance, uint256 nonce, uint256 index, bytes32[] proof) public {
+        Exit memory exit = userExits[exitID];
+        require(exit.user != address(0), "This user exit could not be found or has been finalized");
+        require(block.timestamp.sub(exit.timestamp) < exitTimeLimit, "This user exit has exceeded the challenge period");
+        require(height > exit.height && nonce > exit.nonce, "The proof does not show the user has spent");
+        require(isExitLeaf(height, exit.user, balance, nonce, index, proof), "Invalid challenge proof");
 
-        delete userExits[nonce];
+        delete userExits[exitID];
+        exiting[exit.user] = false;
 
-        require(address(this).balance >= userExitBond, "I don't have enough money to pay for this userExitBond challenge");
-        msg.sender.transfer(userExitBond);
+        msg.sender.transfer(exit.bond);
+
+        emit ChallengedUserExit(exit.user, exitID, msg.sender);
+    }
+
+    /**
+     * @dev Vote the subchain checkpoint, which is accepted once voted by 2/3 operators.
+     * @param height The subchain block height of checkpoint
+     * @param blockHash The subchain block hash
+     * @param stateRoot The state root of subchain block
+     * @param txRoot The tx root of subchain block
+     * @param exitRoot The merkle root of accounts touched since the previous checkpoint
+     * @param verifiers The verifiers of subchain block
+     */
+    function submitCheckpoint(uint256 height, bytes32 blockHash, bytes32 stateRoot, bytes32 txRoot, bytes32 exitRoot, address[] verifiers) public onlyOperator {
+        require(height > currentChildBlockNum, "The checkpoint is not after the current one");
+        require(checkpointVotes[height][msg.sender] == bytes32(0), "You have voted the checkpoint");
+
+        bytes32 hash = keccak256(abi.encodePacked(height, blockHash, stateRoot, txRoot, exitRoot, verifiers));
+        checkpointVotes[height][msg.sender] = hash;
+        checkpointVoteCounts[hash] = checkpointVoteCounts[hash].add(1);
+        emit VoteCheckpoint(msg.sender, height, hash);
+
+        if (checkpointVoteCounts[hash].mul(3) >= opslen.mul(2)) {
+            currentChildBlockNum = height;
+            checkpoints[height] = Checkpoint({
+                blockHash: blockHash,
+                stateRoot: stateRoot,
+                txRoot: txRoot,
+                exitRoot: exitRoot,
+                verifiersHash: keccak256(abi.encodePacked(verifiers)),
+                timestamp: block.timestamp
+            });
+
+            emit SubmitCheckpoint(height, hash, block.timestamp);
+        }
+    }
+
+    /**
+     * @dev Verify the merkle proof of user balance in the exit root of checkpoint.
+     * @param height The height of checkpoint
+     * @param user The user address
+     * @param balance The balance of user at the checkpoint
+     * @param nonce The nonce of user at the checkpoint
+     * @param index The leaf index of user in the exit merkle tree
+     * @param proof The merkle proof of user from bottom to top
+     */
+    function isExitLeaf(uint256 height, address user, uint256 balance, uint256 nonce, uint256 index, bytes32[] proof) public view returns(bool){
+        Checkpoint memory checkpoint = checkpoints[height];
+        require(checkpoint.timestamp > 0, "The checkpoint could not be found");
+
+        bytes32 node = keccak256(abi.encodePacked(user, balance, nonce));
+        for (uint256 i = 0; i < proof.length; i++){
+            if (index % 2 == 1) {
+                node = keccak256(abi.encodePacked(proof[i], node));
+            } else {
+                node = keccak256(abi.encodePacked(node, proof[i]));
+            }
+            index = index / 2;
+        }
 
-        emit ChallengedUserExit(exit.user, nonce, msg.sender);
+        return index == 0 && node == checkpoint.exitRoot;
     }
 
     /**
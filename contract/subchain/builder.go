@@ -0,0 +1,187 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package subchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+// Block is the subchain block with the fields to build checkpoint
+type Block struct {
+	Hash         common.Hash
+	Header       *types.BlockHeader
+	Transactions []*BlockTx
+}
+
+// BlockTx is the tx of subchain block
+type BlockTx struct {
+	From common.Address
+	To   common.Address
+}
+
+// ChainReader reads the subchain blocks and account states
+type ChainReader interface {
+	// Height returns the current height of subchain
+	Height() (uint64, error)
+
+	// BlockByHeight returns the block at height of main chain
+	BlockByHeight(height uint64) (*Block, error)
+
+	// Account returns the balance and nonce of account at height
+	Account(account common.Address, height uint64) (*big.Int, uint64, error)
+
+	// RelayRange returns the number of subchain blocks between checkpoints
+	RelayRange() (uint64, error)
+}
+
+// rpcChainReader reads the subchain by RPC
+type rpcChainReader struct {
+	client *rpc.Client
+}
+
+// NewRPCChainReader creates the chain reader of subchain node RPC
+func NewRPCChainReader(client *rpc.Client) ChainReader {
+	return &rpcChainReader{client}
+}
+
+func (r *rpcChainReader) Height() (uint64, error) {
+	var height uint64
+	if err := r.client.Call(&height, "scdo_getBlockHeight"); err != nil {
+		return 0, fmt.Errorf("failed to get subchain height, %s", err)
+	}
+
+	return height, nil
+}
+
+func (r *rpcChainReader) RelayRange() (uint64, error) {
+	var relayRange uint64
+	if err := r.client.Call(&relayRange, "scdo_getRelayRange"); err != nil {
+		return 0, fmt.Errorf("failed to get subchain relay range, %s", err)
+	}
+
+	return relayRange, nil
+}
+
+func (r *rpcChainReader) BlockByHeight(height uint64) (*Block, error) {
+	var result struct {
+		Hash         string
+		Header       *types.BlockHeader
+		Transactions []struct {
+			From string
+			To   string
+		}
+	}
+
+	if err := r.client.Call(&result, "scdo_getBlockByHeight", int64(height), true); err != nil {
+		return nil, fmt.Errorf("failed to get subchain block %d, %s", height, err)
+	}
+
+	hash, err := common.HexToHash(result.Hash)
+	if err != nil || result.Header == nil {
+		return nil, fmt.Errorf("invalid subchain block %d", height)
+	}
+
+	block := &Block{Hash: hash, Header: result.Header}
+	for _, tx := range result.Transactions {
+		var blockTx BlockTx
+		if len(tx.From) > 0 {
+			if blockTx.From, err = common.HexToAddress(tx.From); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(tx.To) > 0 {
+			if blockTx.To, err = common.HexToAddress(tx.To); err != nil {
+				return nil, err
+			}
+		}
+
+		block.Transactions = append(block.Transactions, &blockTx)
+	}
+
+	return block, nil
+}
+
+func (r *rpcChainReader) Account(account common.Address, height uint64) (*big.Int, uint64, error) {
+	var balance struct {
+		Balance *big.Int
+	}
+
+	if err := r.client.Call(&balance, "scdo_getBalance", account, "", int64(height)); err != nil {
+		return nil, 0, fmt.Errorf("failed to get balance of %v, %s", account.Hex(), err)
+	}
+
+	var nonce uint64
+	if err := r.client.Call(&nonce, "scdo_getAccountNonce", account, "", int64(height)); err != nil {
+		return nil, 0, fmt.Errorf("failed to get nonce of %v, %s", account.Hex(), err)
+	}
+
+	if balance.Balance == nil {
+		balance.Balance = big.NewInt(0)
+	}
+
+	return balance.Balance, nonce, nil
+}
+
+// BuildCheckpoint builds the checkpoint at height, whose exit tree contains the accounts
+// touched by the txs after the previous checkpoint.
+func BuildCheckpoint(reader ChainReader, prevHeight, height uint64) (*Checkpoint, *ExitTree, error) {
+	if height <= prevHeight {
+		return nil, nil, fmt.Errorf("the checkpoint height %d is not after the previous one %d", height, prevHeight)
+	}
+
+	touched := make(map[common.Address]bool)
+	var block *Block
+	for h := prevHeight + 1; h <= height; h++ {
+		var err error
+		if block, err = reader.BlockByHeight(h); err != nil {
+			return nil, nil, err
+		}
+
+		for _, tx := range block.Transactions {
+			for _, account := range []common.Address{tx.From, tx.To} {
+				if !account.IsEmpty() {
+					touched[account] = true
+				}
+			}
+		}
+	}
+
+	var leaves []*AccountLeaf
+	for account := range touched {
+		balance, nonce, err := reader.Account(account, height)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		leaves = append(leaves, &AccountLeaf{account, balance, nonce})
+	}
+
+	tree, err := NewExitTree(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkpoint := &Checkpoint{
+		Height:    height,
+		BlockHash: block.Hash,
+		StateRoot: block.Header.StateHash,
+		TxRoot:    block.Header.TxHash,
+		ExitRoot:  tree.Root(),
+	}
+
+	// the verifiers are empty if the subchain is not BFT consensus
+	if extra, err := types.ExtractBftExtra(block.Header); err == nil {
+		checkpoint.Verifiers = extra.Verifiers
+	}
+
+	return checkpoint, tree, nil
+}
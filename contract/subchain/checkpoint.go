@@ -0,0 +1,154 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+// Package subchain implements the relayer of subchain checkpoints to the PBFTRootchain
+// contract on the main chain, and builds the merkle proofs of exits and challenges.
+package subchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/merkle"
+)
+
+var (
+	// ErrAccountNotFound is returned when the account is not touched in the checkpoint range
+	ErrAccountNotFound = errors.New("account not found in the checkpoint")
+)
+
+// Checkpoint is the subchain block committed to the root chain every relay range blocks
+type Checkpoint struct {
+	Height    uint64
+	BlockHash common.Hash
+	StateRoot common.Hash
+	TxRoot    common.Hash
+	ExitRoot  common.Hash // merkle root of the accounts touched since the previous checkpoint
+	Verifiers []common.Address
+}
+
+// Hash returns the hash voted by operators, which is the same as the root chain contract,
+// keccak256(abi.encodePacked(height, blockHash, stateRoot, txRoot, exitRoot, verifiers)).
+// Note that the elements of array are padded to 32 bytes in the packed encoding.
+func (c *Checkpoint) Hash() common.Hash {
+	data := [][]byte{
+		common.LeftPadBytes(new(big.Int).SetUint64(c.Height).Bytes(), 32),
+		c.BlockHash.Bytes(),
+		c.StateRoot.Bytes(),
+		c.TxRoot.Bytes(),
+		c.ExitRoot.Bytes(),
+	}
+
+	for _, v := range c.Verifiers {
+		data = append(data, common.LeftPadBytes(v.Bytes(), 32))
+	}
+
+	return crypto.HashBytes(data...)
+}
+
+func (c *Checkpoint) String() string {
+	return fmt.Sprintf("Checkpoint[Height=%d, BlockHash=%v, ExitRoot=%v, Verifiers=%d]", c.Height, c.BlockHash.Hex(), c.ExitRoot.Hex(), len(c.Verifiers))
+}
+
+// AccountLeaf is the leaf of exit merkle tree, which is the account state at the checkpoint
+type AccountLeaf struct {
+	Account common.Address
+	Balance *big.Int
+	Nonce   uint64
+}
+
+// CalculateHash implements the merkle.Content interface, which is the same as the root
+// chain contract, keccak256(abi.encodePacked(account, balance, nonce)).
+func (leaf *AccountLeaf) CalculateHash() common.Hash {
+	return crypto.HashBytes(
+		leaf.Account.Bytes(),
+		common.LeftPadBytes(leaf.Balance.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(leaf.Nonce).Bytes(), 32),
+	)
+}
+
+// Equals implements the merkle.Content interface
+func (leaf *AccountLeaf) Equals(other merkle.Content) bool {
+	o, ok := other.(*AccountLeaf)
+	return ok && leaf.Account == o.Account
+}
+
+// ExitTree is the merkle tree of accounts touched in the checkpoint range, sorted by address
+type ExitTree struct {
+	leaves []*AccountLeaf
+	tree   *merkle.MerkleTree
+}
+
+// NewExitTree creates the exit tree of account leaves
+func NewExitTree(leaves []*AccountLeaf) (*ExitTree, error) {
+	sorted := make([]*AccountLeaf, len(leaves))
+	copy(sorted, leaves)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Account.Bytes(), sorted[j].Account.Bytes()) < 0
+	})
+
+	t := &ExitTree{leaves: sorted}
+	if len(sorted) == 0 {
+		return t, nil
+	}
+
+	contents := make([]merkle.Content, len(sorted))
+	for i, leaf := range sorted {
+		contents[i] = leaf
+	}
+
+	tree, err := merkle.NewTree(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	t.tree = tree
+	return t, nil
+}
+
+// Root returns the merkle root, or empty hash if no account is touched
+func (t *ExitTree) Root() common.Hash {
+	if t.tree == nil {
+		return common.EmptyHash
+	}
+
+	return t.tree.MerkleRoot()
+}
+
+// Proof returns the merkle proof of the account
+func (t *ExitTree) Proof(account common.Address) (*ExitProof, error) {
+	index := sort.Search(len(t.leaves), func(i int) bool {
+		return bytes.Compare(t.leaves[i].Account.Bytes(), account.Bytes()) >= 0
+	})
+
+	if index == len(t.leaves) || t.leaves[index].Account != account {
+		return nil, ErrAccountNotFound
+	}
+
+	proof, err := t.tree.Proof(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExitProof{AccountLeaf: *t.leaves[index], Index: uint64(index), Proof: proof}, nil
+}
+
+// ExitProof is the merkle proof of account in the exit tree of checkpoint
+type ExitProof struct {
+	Height uint64
+	AccountLeaf
+	Index uint64
+	Proof []common.Hash
+}
+
+// Verify returns whether the proof is valid for the exit root
+func (p *ExitProof) Verify(exitRoot common.Hash) bool {
+	return merkle.VerifyProof(exitRoot, p.AccountLeaf.CalculateHash(), p.Index, p.Proof)
+}
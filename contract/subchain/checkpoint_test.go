@@ -0,0 +1,168 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package subchain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockChainReader struct {
+	blocks   map[uint64]*Block
+	accounts map[common.Address]*AccountLeaf
+}
+
+func (r *mockChainReader) Height() (uint64, error) {
+	return uint64(len(r.blocks)), nil
+}
+
+func (r *mockChainReader) RelayRange() (uint64, error) {
+	return 4, nil
+}
+
+func (r *mockChainReader) BlockByHeight(height uint64) (*Block, error) {
+	if block, ok := r.blocks[height]; ok {
+		return block, nil
+	}
+
+	return nil, errors.New("block not found")
+}
+
+func (r *mockChainReader) Account(account common.Address, height uint64) (*big.Int, uint64, error) {
+	if leaf, ok := r.accounts[account]; ok {
+		return leaf.Balance, leaf.Nonce, nil
+	}
+
+	return big.NewInt(0), 0, nil
+}
+
+func newTestAccount(i byte) common.Address {
+	return common.BytesToAddress([]byte{i, 0, 1})
+}
+
+func newMockChainReader() *mockChainReader {
+	r := &mockChainReader{
+		blocks:   make(map[uint64]*Block),
+		accounts: make(map[common.Address]*AccountLeaf),
+	}
+
+	for h := uint64(1); h <= 4; h++ {
+		from, to := newTestAccount(byte(h)), newTestAccount(byte(h+1))
+		r.blocks[h] = &Block{
+			Hash:   common.BytesToHash([]byte{byte(h)}),
+			Header: &types.BlockHeader{Height: h, StateHash: common.StringToHash("state"), TxHash: common.StringToHash("tx")},
+			Transactions: []*BlockTx{
+				{From: common.EmptyAddress, To: from}, // reward tx
+				{From: from, To: to},
+			},
+		}
+
+		r.accounts[from] = &AccountLeaf{from, big.NewInt(int64(h * 100)), h}
+	}
+
+	return r
+}
+
+func Test_Checkpoint_Hash(t *testing.T) {
+	c := &Checkpoint{
+		Height:    1,
+		BlockHash: common.StringToHash("block"),
+		ExitRoot:  common.StringToHash("exit"),
+		Verifiers: []common.Address{newTestAccount(1)},
+	}
+
+	height := make([]byte, 32)
+	height[31] = 1
+	expected := crypto.HashBytes(height, c.BlockHash.Bytes(), common.EmptyHash.Bytes(), common.EmptyHash.Bytes(),
+		c.ExitRoot.Bytes(), common.LeftPadBytes(c.Verifiers[0].Bytes(), 32))
+	assert.Equal(t, c.Hash(), expected)
+
+	c.Verifiers = nil
+	assert.NotEqual(t, c.Hash(), expected)
+}
+
+func Test_ExitTree_Proof(t *testing.T) {
+	var leaves []*AccountLeaf
+	for i := byte(5); i > 0; i-- {
+		leaves = append(leaves, &AccountLeaf{newTestAccount(i), big.NewInt(int64(i)), uint64(i)})
+	}
+
+	tree, err := NewExitTree(leaves)
+	assert.Equal(t, err, nil)
+
+	for i, leaf := range leaves {
+		proof, err := tree.Proof(leaf.Account)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, proof.Index, uint64(len(leaves)-1-i))
+		assert.Equal(t, proof.Verify(tree.Root()), true)
+
+		// forged balance
+		proof.Balance = big.NewInt(1000)
+		assert.Equal(t, proof.Verify(tree.Root()), false)
+	}
+
+	_, err = tree.Proof(newTestAccount(6))
+	assert.Equal(t, err, ErrAccountNotFound)
+
+	empty, _ := NewExitTree(nil)
+	assert.Equal(t, empty.Root(), common.EmptyHash)
+}
+
+func Test_BuildCheckpoint(t *testing.T) {
+	reader := newMockChainReader()
+
+	checkpoint, tree, err := BuildCheckpoint(reader, 2, 4)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, checkpoint.Height, uint64(4))
+	assert.Equal(t, checkpoint.BlockHash, reader.blocks[4].Hash)
+	assert.Equal(t, checkpoint.StateRoot, common.StringToHash("state"))
+	assert.Equal(t, checkpoint.ExitRoot, tree.Root())
+
+	// accounts touched in blocks 3 and 4
+	for i := byte(3); i <= 5; i++ {
+		proof, err := tree.Proof(newTestAccount(i))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, proof.Verify(checkpoint.ExitRoot), true)
+	}
+
+	_, err = tree.Proof(newTestAccount(2))
+	assert.Equal(t, err, ErrAccountNotFound)
+
+	_, _, err = BuildCheckpoint(reader, 4, 4)
+	assert.NotEqual(t, err, nil)
+
+	_, _, err = BuildCheckpoint(reader, 4, 5)
+	assert.NotEqual(t, err, nil)
+}
+
+func Test_RootChainABI(t *testing.T) {
+	c := &Checkpoint{Height: 8, BlockHash: common.StringToHash("block"), Verifiers: []common.Address{newTestAccount(1)}}
+
+	payload, err := rootChainABI.Pack("submitCheckpoint", new(big.Int).SetUint64(c.Height), [32]byte(c.BlockHash),
+		[32]byte(c.StateRoot), [32]byte(c.TxRoot), [32]byte(c.ExitRoot), c.Verifiers)
+	assert.Equal(t, err, nil)
+
+	id := crypto.HashBytes([]byte("submitCheckpoint(uint256,bytes32,bytes32,bytes32,bytes32,address[])")).Bytes()[:4]
+	assert.Equal(t, payload[:4], id)
+
+	payload, err = rootChainABI.Pack("startExit", big.NewInt(8), big.NewInt(100), big.NewInt(1), big.NewInt(0),
+		proofArgs([]common.Hash{common.StringToHash("sibling")}))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(payload), 4+32*7)
+
+	output := append(common.StringToHash("block").Bytes(), make([]byte, 32*5)...)
+	output[32*6-1] = 10
+	info := new(CheckpointInfo)
+	assert.Equal(t, rootChainABI.Unpack(info, "checkpoints", output), nil)
+	assert.Equal(t, common.Hash(info.BlockHash), common.StringToHash("block"))
+	assert.Equal(t, info.Timestamp.Uint64(), uint64(10))
+}
@@ -0,0 +1,176 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package subchain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/log"
+)
+
+// RelayerConfig is the config of checkpoint relayer
+type RelayerConfig struct {
+	Range          uint64        // number of subchain blocks between checkpoints, 0 for the relay range of subchain
+	Interval       time.Duration // interval to check the next checkpoint
+	MaxRetries     int           // max retries to submit a checkpoint
+	RetryDelay     time.Duration // delay before the first retry, doubled for each retry
+	ReceiptTimeout time.Duration // timeout to wait for the receipt of submitted checkpoint
+}
+
+// DefaultRelayerConfig is the default config of checkpoint relayer
+var DefaultRelayerConfig = RelayerConfig{
+	Interval:       time.Minute,
+	MaxRetries:     5,
+	RetryDelay:     10 * time.Second,
+	ReceiptTimeout: 5 * time.Minute,
+}
+
+// Relayer submits the signed subchain checkpoints to the root chain every relay range blocks
+type Relayer struct {
+	config    RelayerConfig
+	reader    ChainReader
+	rootchain *RootChain
+	log       *log.ScdoLog
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRelayer creates the checkpoint relayer
+func NewRelayer(config RelayerConfig, reader ChainReader, rootchain *RootChain) (*Relayer, error) {
+	if config.Range == 0 {
+		relayRange, err := reader.RelayRange()
+		if err != nil {
+			return nil, err
+		}
+
+		config.Range = relayRange
+	}
+
+	if config.Interval == 0 {
+		config.Interval = DefaultRelayerConfig.Interval
+	}
+
+	if config.RetryDelay == 0 {
+		config.RetryDelay = DefaultRelayerConfig.RetryDelay
+	}
+
+	if config.ReceiptTimeout == 0 {
+		config.ReceiptTimeout = DefaultRelayerConfig.ReceiptTimeout
+	}
+
+	return &Relayer{
+		config:    config,
+		reader:    reader,
+		rootchain: rootchain,
+		log:       log.GetLogger("relayer"),
+		quit:      make(chan struct{}),
+	}, nil
+}
+
+// Start starts the relayer loop
+func (r *Relayer) Start() {
+	r.wg.Add(1)
+	go r.loop()
+}
+
+// Stop stops the relayer loop and waits for it to exit
+func (r *Relayer) Stop() {
+	close(r.quit)
+	r.wg.Wait()
+}
+
+func (r *Relayer) loop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.relay()
+
+		select {
+		case <-ticker.C:
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// relay submits the next checkpoint with retries if the subchain has reached its height
+func (r *Relayer) relay() {
+	for retry := 0; ; {
+		submitted, err := r.relayNext()
+		if err == nil {
+			if !submitted {
+				return
+			}
+
+			// continue to relay if the subchain is ahead of more than one checkpoint
+			retry = 0
+			continue
+		}
+
+		if retry++; retry > r.config.MaxRetries {
+			r.log.Error("failed to relay checkpoint after %d retries, %s", r.config.MaxRetries, err)
+			return
+		}
+
+		delay := r.config.RetryDelay << uint(retry-1)
+		r.log.Warn("failed to relay checkpoint, retry in %v, %s", delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// relayNext submits the checkpoint after the current one, and returns whether it is submitted
+func (r *Relayer) relayNext() (bool, error) {
+	current, err := r.rootchain.CurrentCheckpointHeight()
+	if err != nil {
+		return false, err
+	}
+
+	next := current + r.config.Range
+	height, err := r.reader.Height()
+	if err != nil {
+		return false, err
+	}
+
+	if height < next {
+		return false, nil
+	}
+
+	voted, err := r.rootchain.Voted(next, r.rootchain.From())
+	if err != nil || voted {
+		// wait for the other operators to accept the checkpoint
+		return false, err
+	}
+
+	checkpoint, _, err := BuildCheckpoint(r.reader, current, next)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := r.rootchain.SubmitCheckpoint(checkpoint)
+	if err != nil {
+		return false, err
+	}
+
+	r.log.Info("submitted %v in tx %v", checkpoint, tx.Hash.Hex())
+
+	if err = r.rootchain.WaitReceipt(tx.Hash, r.config.ReceiptTimeout); err != nil {
+		return false, err
+	}
+
+	// the checkpoint is accepted if the current height changed
+	accepted, err := r.rootchain.CurrentCheckpointHeight()
+	return err == nil && accepted >= next, err
+}
@@ -0,0 +1,271 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package subchain
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+// RootChainABI is the ABI of PBFTRootchain contract used by the relayer and exit commands
+const RootChainABI = `[
+{"constant":true,"inputs":[],"name":"currentChildBlockNum","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"exitTimeLimit","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[],"name":"userExitBond","outputs":[{"name":"","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"","type":"uint256"},{"name":"","type":"address"}],"name":"checkpointVotes","outputs":[{"name":"","type":"bytes32"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"","type":"uint256"}],"name":"checkpoints","outputs":[{"name":"blockHash","type":"bytes32"},{"name":"stateRoot","type":"bytes32"},{"name":"txRoot","type":"bytes32"},{"name":"exitRoot","type":"bytes32"},{"name":"verifiersHash","type":"bytes32"},{"name":"timestamp","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":true,"inputs":[{"name":"","type":"uint256"}],"name":"userExits","outputs":[{"name":"user","type":"address"},{"name":"deposit","type":"uint256"},{"name":"bond","type":"uint256"},{"name":"timestamp","type":"uint256"},{"name":"height","type":"uint256"},{"name":"nonce","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},
+{"constant":false,"inputs":[{"name":"height","type":"uint256"},{"name":"blockHash","type":"bytes32"},{"name":"stateRoot","type":"bytes32"},{"name":"txRoot","type":"bytes32"},{"name":"exitRoot","type":"bytes32"},{"name":"verifiers","type":"address[]"}],"name":"submitCheckpoint","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+{"constant":false,"inputs":[{"name":"height","type":"uint256"},{"name":"balance","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"index","type":"uint256"},{"name":"proof","type":"bytes32[]"}],"name":"startExit","outputs":[],"payable":true,"stateMutability":"payable","type":"function"},
+{"constant":false,"inputs":[{"name":"exitID","type":"uint256"},{"name":"height","type":"uint256"},{"name":"balance","type":"uint256"},{"name":"nonce","type":"uint256"},{"name":"index","type":"uint256"},{"name":"proof","type":"bytes32[]"}],"name":"challengeExit","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},
+{"constant":false,"inputs":[],"name":"finalizeExits","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}
+]`
+
+var (
+	// ErrReceiptTimeout is returned when the tx is not packed in time
+	ErrReceiptTimeout = errors.New("timeout to wait for the tx receipt")
+
+	// DefaultGasPrice is the default gas price of root chain txs
+	DefaultGasPrice = big.NewInt(1)
+
+	// DefaultGasLimit is the default gas limit of root chain txs
+	DefaultGasLimit uint64 = 3000000
+
+	rootChainABI abi.ABI
+)
+
+func init() {
+	var err error
+	if rootChainABI, err = abi.JSON(strings.NewReader(RootChainABI)); err != nil {
+		panic(fmt.Sprintf("invalid root chain ABI, %s", err))
+	}
+}
+
+// CheckpointInfo is the checkpoint accepted by the root chain contract
+type CheckpointInfo struct {
+	BlockHash     [32]byte
+	StateRoot     [32]byte
+	TxRoot        [32]byte
+	ExitRoot      [32]byte
+	VerifiersHash [32]byte
+	Timestamp     *big.Int
+}
+
+// ExitInfo is the user exit in the root chain contract
+type ExitInfo struct {
+	User      common.Address
+	Deposit   *big.Int
+	Bond      *big.Int
+	Timestamp *big.Int
+	Height    *big.Int
+	Nonce     *big.Int
+}
+
+// RootChain is the client of PBFTRootchain contract on the main chain
+type RootChain struct {
+	client   *rpc.Client
+	contract common.Address
+	key      *ecdsa.PrivateKey
+	from     common.Address
+
+	GasPrice *big.Int
+	GasLimit uint64
+}
+
+// NewRootChain creates the root chain client, the key is used to sign txs and
+// could be nil for the read only calls.
+func NewRootChain(client *rpc.Client, contract common.Address, key *ecdsa.PrivateKey) *RootChain {
+	r := &RootChain{
+		client:   client,
+		contract: contract,
+		key:      key,
+		GasPrice: DefaultGasPrice,
+		GasLimit: DefaultGasLimit,
+	}
+
+	if key != nil {
+		r.from = *crypto.GetAddress(&key.PublicKey)
+	}
+
+	return r
+}
+
+// From returns the address of the key to sign txs
+func (r *RootChain) From() common.Address {
+	return r.from
+}
+
+// call calls the view method of contract and unpacks the result into v
+func (r *RootChain) call(v interface{}, method string, args ...interface{}) error {
+	payload, err := rootChainABI.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]interface{}
+	if err = r.client.Call(&result, "scdo_call", r.contract.Hex(), hexutil.BytesToHex(payload), -1); err != nil {
+		return err
+	}
+
+	output, _ := result["result"].(string)
+	if failed, _ := result["failed"].(bool); failed {
+		return fmt.Errorf("failed to call %s, %s", method, output)
+	}
+
+	data, err := hexutil.HexToBytes(output)
+	if err != nil {
+		return err
+	}
+
+	return rootChainABI.Unpack(v, method, data)
+}
+
+// CurrentCheckpointHeight returns the height of latest accepted checkpoint
+func (r *RootChain) CurrentCheckpointHeight() (uint64, error) {
+	var height *big.Int
+	if err := r.call(&height, "currentChildBlockNum"); err != nil {
+		return 0, err
+	}
+
+	return height.Uint64(), nil
+}
+
+// ExitBond returns the bond required to start an exit
+func (r *RootChain) ExitBond() (*big.Int, error) {
+	var bond *big.Int
+	if err := r.call(&bond, "userExitBond"); err != nil {
+		return nil, err
+	}
+
+	return bond, nil
+}
+
+// Voted returns whether the operator has voted the checkpoint at height
+func (r *RootChain) Voted(height uint64, operator common.Address) (bool, error) {
+	var hash [32]byte
+	if err := r.call(&hash, "checkpointVotes", new(big.Int).SetUint64(height), operator); err != nil {
+		return false, err
+	}
+
+	return common.BytesToHash(hash[:]) != common.EmptyHash, nil
+}
+
+// Checkpoint returns the accepted checkpoint at height, whose timestamp is 0 if not found
+func (r *RootChain) Checkpoint(height uint64) (*CheckpointInfo, error) {
+	info := new(CheckpointInfo)
+	if err := r.call(info, "checkpoints", new(big.Int).SetUint64(height)); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// Exit returns the user exit of id, whose user is empty if finalized or challenged
+func (r *RootChain) Exit(id uint64) (*ExitInfo, error) {
+	info := new(ExitInfo)
+	if err := r.call(info, "userExits", new(big.Int).SetUint64(id)); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// SubmitCheckpoint votes the checkpoint by the operator key
+func (r *RootChain) SubmitCheckpoint(c *Checkpoint) (*types.Transaction, error) {
+	return r.send(nil, "submitCheckpoint", new(big.Int).SetUint64(c.Height), [32]byte(c.BlockHash), [32]byte(c.StateRoot),
+		[32]byte(c.TxRoot), [32]byte(c.ExitRoot), c.Verifiers)
+}
+
+// StartExit starts to exit the balance of proof with the bond
+func (r *RootChain) StartExit(p *ExitProof, bond *big.Int) (*types.Transaction, error) {
+	return r.send(bond, "startExit", new(big.Int).SetUint64(p.Height), p.Balance, new(big.Int).SetUint64(p.Nonce),
+		new(big.Int).SetUint64(p.Index), proofArgs(p.Proof))
+}
+
+// ChallengeExit challenges the exit of id with the proof at a later checkpoint
+func (r *RootChain) ChallengeExit(id uint64, p *ExitProof) (*types.Transaction, error) {
+	return r.send(nil, "challengeExit", new(big.Int).SetUint64(id), new(big.Int).SetUint64(p.Height), p.Balance,
+		new(big.Int).SetUint64(p.Nonce), new(big.Int).SetUint64(p.Index), proofArgs(p.Proof))
+}
+
+// FinalizeExits finalizes all the exits out of the challenge period
+func (r *RootChain) FinalizeExits() (*types.Transaction, error) {
+	return r.send(nil, "finalizeExits")
+}
+
+func proofArgs(proof []common.Hash) [][32]byte {
+	args := make([][32]byte, len(proof))
+	for i, h := range proof {
+		args[i] = h
+	}
+
+	return args
+}
+
+// send signs and sends the tx to call the contract method
+func (r *RootChain) send(amount *big.Int, method string, args ...interface{}) (*types.Transaction, error) {
+	if r.key == nil {
+		return nil, errors.New("the key is required to send tx")
+	}
+
+	payload, err := rootChainABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce uint64
+	if err = r.client.Call(&nonce, "scdo_getAccountNonce", r.from, "", -1); err != nil {
+		return nil, fmt.Errorf("failed to get the nonce of %v, %s", r.from.Hex(), err)
+	}
+
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+
+	tx, err := types.NewMessageTransaction(r.from, r.contract, amount, r.GasPrice, r.GasLimit, nonce, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Sign(r.key)
+
+	var added bool
+	if err = r.client.Call(&added, "scdo_addTx", *tx); err != nil || !added {
+		return nil, fmt.Errorf("failed to send tx %s, %v", method, err)
+	}
+
+	return tx, nil
+}
+
+// WaitReceipt waits for the receipt of tx until timeout, and returns error if the tx failed
+func (r *RootChain) WaitReceipt(hash common.Hash, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		var receipt map[string]interface{}
+		if err := r.client.Call(&receipt, "txpool_getReceiptByTxHash", hash.Hex(), ""); err == nil {
+			if failed, _ := receipt["failed"].(bool); failed {
+				return fmt.Errorf("tx %v failed, %v", hash.Hex(), receipt["result"])
+			}
+
+			return nil
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+
+	return ErrReceiptTimeout
+}
@@ -0,0 +1,179 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package swap
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/secp256k1"
+	"golang.org/x/crypto/ripemd160"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	// ErrInvalidBTCAddress is returned when the base58 address is invalid or not for the network
+	ErrInvalidBTCAddress = errors.New("invalid btc address")
+
+	// ErrInvalidWIF is returned when the private key is not in valid wallet import format
+	ErrInvalidWIF = errors.New("invalid wallet import format private key")
+
+	bigRadix = big.NewInt(58)
+)
+
+// BTCNet is the version bytes of addresses and keys of bitcoin network
+type BTCNet struct {
+	Name             string
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+	PrivateKeyID     byte
+}
+
+var (
+	// BTCMainNet is the bitcoin main network
+	BTCMainNet = &BTCNet{"mainnet", 0x00, 0x05, 0x80}
+
+	// BTCTestNet is the bitcoin test and regression test network
+	BTCTestNet = &BTCNet{"testnet", 0x6f, 0xc4, 0xef}
+)
+
+// BTCNetByName returns the network by name, which is mainnet, testnet or regtest
+func BTCNetByName(name string) (*BTCNet, error) {
+	switch name {
+	case "mainnet":
+		return BTCMainNet, nil
+	case "testnet", "regtest":
+		return BTCTestNet, nil
+	default:
+		return nil, errors.New("unknown btc network, it should be mainnet, testnet or regtest")
+	}
+}
+
+// Hash160 returns RIPEMD160(SHA256(data)), which is used by addresses
+func Hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+// ScriptAddress returns the P2SH address of script
+func (n *BTCNet) ScriptAddress(script []byte) string {
+	return encodeBase58Check(n.ScriptHashAddrID, Hash160(script))
+}
+
+// PubKeyHashAddress returns the P2PKH address of public key hash
+func (n *BTCNet) PubKeyHashAddress(pubKeyHash []byte) string {
+	return encodeBase58Check(n.PubKeyHashAddrID, pubKeyHash)
+}
+
+// DecodePubKeyHashAddress returns the public key hash of P2PKH address in the network
+func (n *BTCNet) DecodePubKeyHashAddress(address string) ([]byte, error) {
+	version, hash, err := decodeBase58Check(address)
+	if err != nil || version != n.PubKeyHashAddrID || len(hash) != ripemd160.Size {
+		return nil, ErrInvalidBTCAddress
+	}
+
+	return hash, nil
+}
+
+// DecodeWIF returns the private key in wallet import format of the network, and the key
+// must be for the compressed public key.
+func (n *BTCNet) DecodeWIF(wif string) (*ecdsa.PrivateKey, error) {
+	version, payload, err := decodeBase58Check(wif)
+	if err != nil || version != n.PrivateKeyID || len(payload) != 33 || payload[32] != 0x01 {
+		return nil, ErrInvalidWIF
+	}
+
+	return crypto.ToECDSA(payload[:32])
+}
+
+// EncodeWIF returns the private key in wallet import format for the compressed public key
+func (n *BTCNet) EncodeWIF(key *ecdsa.PrivateKey) string {
+	return encodeBase58Check(n.PrivateKeyID, append(crypto.FromECDSA(key), 0x01))
+}
+
+// CompressedPubKey returns the 33 bytes compressed public key
+func CompressedPubKey(key *ecdsa.PublicKey) []byte {
+	return secp256k1.CompressPubkey(key.X, key.Y)
+}
+
+func encodeBase58Check(version byte, payload []byte) string {
+	data := append([]byte{version}, payload...)
+	checksum := doubleSha256(data)
+	return encodeBase58(append(data, checksum[:4]...))
+}
+
+func decodeBase58Check(s string) (byte, []byte, error) {
+	data, err := decodeBase58(s)
+	if err != nil || len(data) < 5 {
+		return 0, nil, ErrInvalidBTCAddress
+	}
+
+	checksum := doubleSha256(data[:len(data)-4])
+	if !bytes.Equal(checksum[:4], data[len(data)-4:]) {
+		return 0, nil, ErrInvalidBTCAddress
+	}
+
+	return data[0], data[1 : len(data)-4], nil
+}
+
+func encodeBase58(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+
+	var encoded []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, bigRadix, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	// leading zero bytes are encoded as the first letter
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+func decodeBase58(s string) ([]byte, error) {
+	n := new(big.Int)
+	for _, c := range []byte(s) {
+		index := bytes.IndexByte([]byte(base58Alphabet), c)
+		if index < 0 {
+			return nil, ErrInvalidBTCAddress
+		}
+
+		n.Mul(n, bigRadix)
+		n.Add(n, big.NewInt(int64(index)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+func doubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
@@ -0,0 +1,266 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package swap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// bitcoin script opcodes used by the atomic swap contract
+const (
+	opFalse               = 0x00
+	opPushData1           = 0x4c
+	opPushData2           = 0x4d
+	opPushData4           = 0x4e
+	opTrue                = 0x51
+	opIf                  = 0x63
+	opElse                = 0x67
+	opEndIf               = 0x68
+	opDrop                = 0x75
+	opDup                 = 0x76
+	opEqual               = 0x87
+	opEqualVerify         = 0x88
+	opSize                = 0x82
+	opSha256              = 0xa8
+	opHash160             = 0xa9
+	opCheckSig            = 0xac
+	opCheckLockTimeVerify = 0xb1
+)
+
+var (
+	// ErrNotSwapContract is returned when the script is not the standard atomic swap contract
+	ErrNotSwapContract = errors.New("not the standard atomic swap contract script")
+
+	// ErrInvalidScript is returned when the script could not be parsed
+	ErrInvalidScript = errors.New("invalid btc script")
+)
+
+// BTCContract is the standard hash time locked contract of bitcoin-like chains. The
+// recipient redeems with the secret of hash before the lock time, and the refund address
+// gets the fund back after the lock time.
+//
+//	OP_IF
+//	    OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <secret hash> OP_EQUALVERIFY
+//	    OP_DUP OP_HASH160 <recipient public key hash>
+//	OP_ELSE
+//	    <lock time> OP_CHECKLOCKTIMEVERIFY OP_DROP
+//	    OP_DUP OP_HASH160 <refund public key hash>
+//	OP_ENDIF
+//	OP_EQUALVERIFY OP_CHECKSIG
+type BTCContract struct {
+	SecretHash []byte // SHA256 hash of the 32 bytes secret
+	Recipient  []byte // public key hash of the recipient
+	Refund     []byte // public key hash of the refund address
+	LockTime   int64  // unix time after which the fund could be refunded
+}
+
+// Script returns the contract script
+func (c *BTCContract) Script() ([]byte, error) {
+	if len(c.SecretHash) != SecretSize || len(c.Recipient) != 20 || len(c.Refund) != 20 || c.LockTime <= 0 {
+		return nil, ErrNotSwapContract
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(opIf)
+	buf.WriteByte(opSize)
+	writePush(&buf, scriptNum(SecretSize))
+	buf.WriteByte(opEqualVerify)
+	buf.WriteByte(opSha256)
+	writePush(&buf, c.SecretHash)
+	buf.WriteByte(opEqualVerify)
+	buf.WriteByte(opDup)
+	buf.WriteByte(opHash160)
+	writePush(&buf, c.Recipient)
+	buf.WriteByte(opElse)
+	writePush(&buf, scriptNum(c.LockTime))
+	buf.WriteByte(opCheckLockTimeVerify)
+	buf.WriteByte(opDrop)
+	buf.WriteByte(opDup)
+	buf.WriteByte(opHash160)
+	writePush(&buf, c.Refund)
+	buf.WriteByte(opEndIf)
+	buf.WriteByte(opEqualVerify)
+	buf.WriteByte(opCheckSig)
+
+	return buf.Bytes(), nil
+}
+
+// ParseBTCContract parses the standard atomic swap contract script
+func ParseBTCContract(script []byte) (*BTCContract, error) {
+	ops, err := parseScript(script)
+	if err != nil {
+		return nil, err
+	}
+
+	template := []byte{opIf, opSize, 0, opEqualVerify, opSha256, 0, opEqualVerify, opDup, opHash160, 0,
+		opElse, 0, opCheckLockTimeVerify, opDrop, opDup, opHash160, 0, opEndIf, opEqualVerify, opCheckSig}
+	if len(ops) != len(template) {
+		return nil, ErrNotSwapContract
+	}
+
+	for i, op := range ops {
+		if template[i] != 0 && (op.opcode != template[i] || op.data != nil) {
+			return nil, ErrNotSwapContract
+		}
+
+		if template[i] == 0 && op.data == nil {
+			return nil, ErrNotSwapContract
+		}
+	}
+
+	contract := &BTCContract{
+		SecretHash: ops[5].data,
+		Recipient:  ops[9].data,
+		Refund:     ops[16].data,
+	}
+
+	size, sizeErr := parseScriptNum(ops[2].data)
+	lockTime, lockErr := parseScriptNum(ops[11].data)
+	if sizeErr != nil || lockErr != nil || size != SecretSize || lockTime <= 0 ||
+		len(contract.SecretHash) != SecretSize || len(contract.Recipient) != 20 || len(contract.Refund) != 20 {
+		return nil, ErrNotSwapContract
+	}
+
+	contract.LockTime = lockTime
+	return contract, nil
+}
+
+// scriptOp is the parsed opcode with its pushed data
+type scriptOp struct {
+	opcode byte
+	data   []byte // nil if the opcode does not push data
+}
+
+func parseScript(script []byte) ([]scriptOp, error) {
+	var ops []scriptOp
+	for i := 0; i < len(script); {
+		opcode := script[i]
+		i++
+
+		var length int
+		switch {
+		case opcode == opFalse:
+			ops = append(ops, scriptOp{opcode, []byte{}})
+			continue
+		case opcode < opPushData1:
+			length = int(opcode)
+		case opcode == opPushData1 && i+1 <= len(script):
+			length, i = int(script[i]), i+1
+		case opcode == opPushData2 && i+2 <= len(script):
+			length, i = int(binary.LittleEndian.Uint16(script[i:])), i+2
+		case opcode == opPushData4 && i+4 <= len(script):
+			length, i = int(binary.LittleEndian.Uint32(script[i:])), i+4
+		case opcode >= opPushData1 && opcode <= opPushData4:
+			return nil, ErrInvalidScript
+		default:
+			ops = append(ops, scriptOp{opcode, nil})
+			continue
+		}
+
+		if length < 0 || i+length > len(script) {
+			return nil, ErrInvalidScript
+		}
+
+		ops = append(ops, scriptOp{opcode, script[i : i+length]})
+		i += length
+	}
+
+	return ops, nil
+}
+
+// writePush writes the data push with the minimal opcode
+func writePush(buf *bytes.Buffer, data []byte) {
+	switch length := len(data); {
+	case length < opPushData1:
+		buf.WriteByte(byte(length))
+	case length <= 0xff:
+		buf.WriteByte(opPushData1)
+		buf.WriteByte(byte(length))
+	case length <= 0xffff:
+		buf.WriteByte(opPushData2)
+		binary.Write(buf, binary.LittleEndian, uint16(length))
+	default:
+		buf.WriteByte(opPushData4)
+		binary.Write(buf, binary.LittleEndian, uint32(length))
+	}
+
+	buf.Write(data)
+}
+
+// scriptNum encodes the number in the minimal little endian form with sign bit
+func scriptNum(n int64) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+
+	var result []byte
+	for n > 0 {
+		result = append(result, byte(n&0xff))
+		n >>= 8
+	}
+
+	if result[len(result)-1]&0x80 != 0 {
+		extra := byte(0x00)
+		if negative {
+			extra = 0x80
+		}
+		result = append(result, extra)
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+
+	return result
+}
+
+// parseScriptNum decodes the minimal encoded number of at most 5 bytes, e.g. lock time
+func parseScriptNum(b []byte) (int64, error) {
+	if len(b) > 5 {
+		return 0, ErrInvalidScript
+	}
+
+	if len(b) > 0 && b[len(b)-1]&0x7f == 0 && (len(b) == 1 || b[len(b)-2]&0x80 == 0) {
+		return 0, ErrInvalidScript
+	}
+
+	var n int64
+	for i, v := range b {
+		n |= int64(v) << uint(8*i)
+	}
+
+	if len(b) > 0 && b[len(b)-1]&0x80 != 0 {
+		n &= ^(int64(0x80) << uint(8*(len(b)-1)))
+		n = -n
+	}
+
+	return n, nil
+}
+
+// payToScriptHashScript returns the output script that pays to the script hash
+func payToScriptHashScript(script []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(opHash160)
+	writePush(&buf, Hash160(script))
+	buf.WriteByte(opEqual)
+	return buf.Bytes()
+}
+
+// payToPubKeyHashScript returns the output script that pays to the public key hash
+func payToPubKeyHashScript(pubKeyHash []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(opDup)
+	buf.WriteByte(opHash160)
+	writePush(&buf, pubKeyHash)
+	buf.WriteByte(opEqualVerify)
+	buf.WriteByte(opCheckSig)
+	return buf.Bytes()
+}
@@ -0,0 +1,113 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package swap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBTCContract() *BTCContract {
+	return &BTCContract{
+		SecretHash: bytes.Repeat([]byte{1}, SecretSize),
+		Recipient:  bytes.Repeat([]byte{2}, 20),
+		Refund:     bytes.Repeat([]byte{3}, 20),
+		LockTime:   1600000000,
+	}
+}
+
+func Test_BTCContract_Script(t *testing.T) {
+	contract := newTestBTCContract()
+	script, err := contract.Script()
+	assert.Equal(t, err, nil)
+
+	expected := "63" + "82" + "0120" + "88" + "a8" + "20" + hex.EncodeToString(contract.SecretHash) + "88" + "76" + "a9" +
+		"14" + hex.EncodeToString(contract.Recipient) + "67" + "04" + "00105e5f" + "b1" + "75" + "76" + "a9" +
+		"14" + hex.EncodeToString(contract.Refund) + "68" + "88" + "ac"
+	assert.Equal(t, hex.EncodeToString(script), expected)
+
+	parsed, err := ParseBTCContract(script)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, parsed, contract)
+
+	// invalid contract
+	contract.Recipient = contract.Recipient[1:]
+	_, err = contract.Script()
+	assert.Equal(t, err, ErrNotSwapContract)
+}
+
+func Test_ParseBTCContract_Invalid(t *testing.T) {
+	script, _ := newTestBTCContract().Script()
+
+	_, err := ParseBTCContract(script[:len(script)-1])
+	assert.Equal(t, err, ErrNotSwapContract)
+
+	// truncated push
+	_, err = ParseBTCContract(script[:10])
+	assert.Equal(t, err, ErrInvalidScript)
+
+	// OP_SHA256 replaced by OP_HASH160
+	modified := append([]byte{}, script...)
+	modified[5] = opHash160
+	_, err = ParseBTCContract(modified)
+	assert.Equal(t, err, ErrNotSwapContract)
+
+	_, err = ParseBTCContract(payToPubKeyHashScript(bytes.Repeat([]byte{2}, 20)))
+	assert.Equal(t, err, ErrNotSwapContract)
+}
+
+func Test_ScriptNum(t *testing.T) {
+	for _, n := range []int64{0, 1, 127, 128, 255, 256, -1, -128, 0x7fffffff, 1600000000, 4294967295} {
+		b := scriptNum(n)
+		parsed, err := parseScriptNum(b)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, parsed, n)
+	}
+
+	assert.Equal(t, scriptNum(128), []byte{0x80, 0x00})
+	assert.Equal(t, scriptNum(-1), []byte{0x81})
+
+	// not minimal encoded
+	_, err := parseScriptNum([]byte{0x01, 0x00})
+	assert.Equal(t, err, ErrInvalidScript)
+}
+
+func Test_BTCNet_Address(t *testing.T) {
+	assert.Equal(t, BTCMainNet.PubKeyHashAddress(make([]byte, 20)), "1111111111111111111114oLvT2")
+
+	hash, err := BTCMainNet.DecodePubKeyHashAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, hex.EncodeToString(hash), "77bff20c60e522dfaa3350c39b030a5d004e839a")
+
+	// checksum error and wrong network
+	_, err = BTCMainNet.DecodePubKeyHashAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3")
+	assert.Equal(t, err, ErrInvalidBTCAddress)
+	_, err = BTCTestNet.DecodePubKeyHashAddress("1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2")
+	assert.Equal(t, err, ErrInvalidBTCAddress)
+
+	script, _ := newTestBTCContract().Script()
+	address := BTCTestNet.ScriptAddress(script)
+	assert.Equal(t, address[0], byte('2'))
+}
+
+func Test_BTCNet_WIF(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.Equal(t, err, nil)
+
+	wif := BTCTestNet.EncodeWIF(key)
+	decoded, err := BTCTestNet.DecodeWIF(wif)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.D, key.D)
+
+	_, err = BTCMainNet.DecodeWIF(wif)
+	assert.Equal(t, err, ErrInvalidWIF)
+
+	assert.Equal(t, len(CompressedPubKey(&key.PublicKey)), 33)
+}
@@ -0,0 +1,448 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package swap
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/secp256k1"
+)
+
+const (
+	// sigHashAll is the hash type that signs all inputs and outputs
+	sigHashAll = 0x01
+
+	// maxTxInputs is the max number of inputs or outputs when decoding tx
+	maxTxInputs = 1 << 16
+
+	// lockTimeSequence is the input sequence that enables the lock time of tx
+	lockTimeSequence = 0xfffffffe
+
+	// finalSequence is the input sequence that disables the lock time of tx
+	finalSequence = 0xffffffff
+)
+
+var (
+	// ErrInvalidBTCTx is returned when the raw tx could not be decoded
+	ErrInvalidBTCTx = errors.New("invalid btc tx")
+
+	// ErrSecretNotFound is returned when the tx does not redeem the contract with secret
+	ErrSecretNotFound = errors.New("no secret found in tx")
+
+	// ErrContractOutputNotFound is returned when the tx does not pay to the contract
+	ErrContractOutputNotFound = errors.New("no output pays to the contract")
+)
+
+// BTCOutPoint is the reference to the output of previous tx
+type BTCOutPoint struct {
+	Hash  system.BTCHash
+	Index uint32
+}
+
+// BTCTxIn is the input of bitcoin tx
+type BTCTxIn struct {
+	PreviousOutPoint BTCOutPoint
+	SignatureScript  []byte
+	Sequence         uint32
+}
+
+// BTCTxOut is the output of bitcoin tx
+type BTCTxOut struct {
+	Value    int64
+	PkScript []byte
+}
+
+// BTCTx is the bitcoin tx, and the witness data is skipped when decoding
+type BTCTx struct {
+	Version  int32
+	TxIn     []*BTCTxIn
+	TxOut    []*BTCTxOut
+	LockTime uint32
+}
+
+// DecodeBTCTx decodes the raw tx in either legacy or segregated witness serialization
+func DecodeBTCTx(raw []byte) (*BTCTx, error) {
+	r := bytes.NewReader(raw)
+	tx := new(BTCTx)
+
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, ErrInvalidBTCTx
+	}
+	tx.Version = int32(version)
+
+	inputs, err := readVarInt(r)
+	if err != nil {
+		return nil, ErrInvalidBTCTx
+	}
+
+	// marker 0x00 and flag 0x01 of witness serialization
+	witness := false
+	if inputs == 0 {
+		if flag, err := r.ReadByte(); err != nil || flag != 0x01 {
+			return nil, ErrInvalidBTCTx
+		}
+
+		if inputs, err = readVarInt(r); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+		witness = true
+	}
+
+	if inputs == 0 || inputs > maxTxInputs {
+		return nil, ErrInvalidBTCTx
+	}
+
+	for i := uint64(0); i < inputs; i++ {
+		in := new(BTCTxIn)
+		if _, err = io.ReadFull(r, in.PreviousOutPoint.Hash[:]); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+
+		if err = binary.Read(r, binary.LittleEndian, &in.PreviousOutPoint.Index); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+
+		if in.SignatureScript, err = readVarBytes(r); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+
+		if err = binary.Read(r, binary.LittleEndian, &in.Sequence); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+
+		tx.TxIn = append(tx.TxIn, in)
+	}
+
+	outputs, err := readVarInt(r)
+	if err != nil || outputs > maxTxInputs {
+		return nil, ErrInvalidBTCTx
+	}
+
+	for i := uint64(0); i < outputs; i++ {
+		out := new(BTCTxOut)
+		if err = binary.Read(r, binary.LittleEndian, &out.Value); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+
+		if out.PkScript, err = readVarBytes(r); err != nil {
+			return nil, ErrInvalidBTCTx
+		}
+
+		tx.TxOut = append(tx.TxOut, out)
+	}
+
+	if witness {
+		for range tx.TxIn {
+			items, err := readVarInt(r)
+			if err != nil || items > maxTxInputs {
+				return nil, ErrInvalidBTCTx
+			}
+
+			for j := uint64(0); j < items; j++ {
+				if _, err = readVarBytes(r); err != nil {
+					return nil, ErrInvalidBTCTx
+				}
+			}
+		}
+	}
+
+	if err = binary.Read(r, binary.LittleEndian, &tx.LockTime); err != nil || r.Len() != 0 {
+		return nil, ErrInvalidBTCTx
+	}
+
+	return tx, nil
+}
+
+// Encode returns the legacy serialization of tx without witness
+func (tx *BTCTx) Encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, uint32(tx.Version))
+
+	writeVarInt(&buf, uint64(len(tx.TxIn)))
+	for _, in := range tx.TxIn {
+		buf.Write(in.PreviousOutPoint.Hash[:])
+		binary.Write(&buf, binary.LittleEndian, in.PreviousOutPoint.Index)
+		writeVarBytes(&buf, in.SignatureScript)
+		binary.Write(&buf, binary.LittleEndian, in.Sequence)
+	}
+
+	writeVarInt(&buf, uint64(len(tx.TxOut)))
+	for _, out := range tx.TxOut {
+		binary.Write(&buf, binary.LittleEndian, out.Value)
+		writeVarBytes(&buf, out.PkScript)
+	}
+
+	binary.Write(&buf, binary.LittleEndian, tx.LockTime)
+	return buf.Bytes()
+}
+
+// TxID returns the id of tx, which is the hash of serialization without witness
+func (tx *BTCTx) TxID() system.BTCHash {
+	return system.BTCDoubleHash(tx.Encode())
+}
+
+// ContractOutput returns the index of output that pays to the P2SH address of contract script
+func (tx *BTCTx) ContractOutput(script []byte) (uint32, error) {
+	pkScript := payToScriptHashScript(script)
+	for i, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, pkScript) {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, ErrContractOutputNotFound
+}
+
+// SignatureHash returns the legacy SIGHASH_ALL hash of the input that spends the output of script
+func (tx *BTCTx) SignatureHash(index int, subScript []byte) []byte {
+	copied := &BTCTx{Version: tx.Version, TxOut: tx.TxOut, LockTime: tx.LockTime}
+	for i, in := range tx.TxIn {
+		script := []byte{}
+		if i == index {
+			script = subScript
+		}
+
+		copied.TxIn = append(copied.TxIn, &BTCTxIn{in.PreviousOutPoint, script, in.Sequence})
+	}
+
+	data := copied.Encode()
+	data = append(data, sigHashAll, 0, 0, 0)
+	hash := system.BTCDoubleHash(data)
+	return hash[:]
+}
+
+// signBTCInput returns the DER encoded signature with hash type of the input
+func signBTCInput(tx *BTCTx, index int, subScript []byte, key *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := secp256k1.Sign(tx.SignatureHash(index, subScript), crypto.FromECDSA(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(derSignature(sig[:32], sig[32:64]), sigHashAll), nil
+}
+
+// derSignature encodes the signature of r and s in DER format
+func derSignature(r, s []byte) []byte {
+	encodeInt := func(b []byte) []byte {
+		b = new(big.Int).SetBytes(b).Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0x00}, b...)
+		}
+
+		return append([]byte{0x02, byte(len(b))}, b...)
+	}
+
+	body := append(encodeInt(r), encodeInt(s)...)
+	return append([]byte{0x30, byte(len(body))}, body...)
+}
+
+// parseDERSignature returns the 64 bytes R || S of DER encoded signature without hash type
+func parseDERSignature(der []byte) ([]byte, error) {
+	if len(der) < 8 || der[0] != 0x30 || int(der[1]) != len(der)-2 {
+		return nil, ErrInvalidScript
+	}
+
+	result := make([]byte, 64)
+	rest := der[2:]
+	for i := 0; i < 2; i++ {
+		if len(rest) < 2 || rest[0] != 0x02 || int(rest[1])+2 > len(rest) {
+			return nil, ErrInvalidScript
+		}
+
+		value := new(big.Int).SetBytes(rest[2 : 2+rest[1]]).Bytes()
+		if len(value) > 32 {
+			return nil, ErrInvalidScript
+		}
+
+		copy(result[32*i+32-len(value):32*(i+1)], value)
+		rest = rest[2+rest[1]:]
+	}
+
+	return result, nil
+}
+
+// NewBTCRedeemTx creates the signed tx that redeems the contract output with secret and pays
+// to the address of recipient key. The fee is deducted from the contract amount.
+func NewBTCRedeemTx(contractTx *BTCTx, script []byte, secret []byte, key *ecdsa.PrivateKey, fee int64) (*BTCTx, error) {
+	contract, err := ParseBTCContract(script)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := CompressedPubKey(&key.PublicKey)
+	if !bytes.Equal(Hash160(pubKey), contract.Recipient) {
+		return nil, errors.New("the key is not the recipient of contract")
+	}
+
+	if !bytes.Equal(system.Sha256Hash(secret), contract.SecretHash) {
+		return nil, ErrSecretMismatch
+	}
+
+	tx, err := newBTCSpendTx(contractTx, script, contract.Recipient, fee, 0, finalSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signBTCInput(tx, 0, script, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writePush(&buf, sig)
+	writePush(&buf, pubKey)
+	writePush(&buf, secret)
+	buf.WriteByte(opTrue)
+	writePush(&buf, script)
+	tx.TxIn[0].SignatureScript = buf.Bytes()
+
+	return tx, nil
+}
+
+// NewBTCRefundTx creates the signed tx that refunds the contract output after the lock time
+// and pays to the address of refund key. The fee is deducted from the contract amount.
+func NewBTCRefundTx(contractTx *BTCTx, script []byte, key *ecdsa.PrivateKey, fee int64) (*BTCTx, error) {
+	contract, err := ParseBTCContract(script)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey := CompressedPubKey(&key.PublicKey)
+	if !bytes.Equal(Hash160(pubKey), contract.Refund) {
+		return nil, errors.New("the key is not the refund address of contract")
+	}
+
+	tx, err := newBTCSpendTx(contractTx, script, contract.Refund, fee, uint32(contract.LockTime), lockTimeSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signBTCInput(tx, 0, script, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writePush(&buf, sig)
+	writePush(&buf, pubKey)
+	buf.WriteByte(opFalse)
+	writePush(&buf, script)
+	tx.TxIn[0].SignatureScript = buf.Bytes()
+
+	return tx, nil
+}
+
+// newBTCSpendTx creates the unsigned tx that spends the contract output to the public key hash
+func newBTCSpendTx(contractTx *BTCTx, script []byte, pubKeyHash []byte, fee int64, lockTime uint32, sequence uint32) (*BTCTx, error) {
+	index, err := contractTx.ContractOutput(script)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := contractTx.TxOut[index].Value
+	if fee < 0 || amount <= fee {
+		return nil, fmt.Errorf("the fee %d is not less than the contract amount %d", fee, amount)
+	}
+
+	return &BTCTx{
+		Version:  1,
+		TxIn:     []*BTCTxIn{{BTCOutPoint{contractTx.TxID(), index}, nil, sequence}},
+		TxOut:    []*BTCTxOut{{amount - fee, payToPubKeyHashScript(pubKeyHash)}},
+		LockTime: lockTime,
+	}, nil
+}
+
+// ExtractBTCSecret returns the secret from the tx that redeems the contract of script
+func ExtractBTCSecret(redeemTx *BTCTx, script []byte) ([]byte, error) {
+	contract, err := ParseBTCContract(script)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, in := range redeemTx.TxIn {
+		ops, err := parseScript(in.SignatureScript)
+		if err != nil || len(ops) != 5 {
+			continue
+		}
+
+		// <sig> <pubkey> <secret> OP_1 <script>
+		if ops[3].opcode != opTrue || !bytes.Equal(ops[4].data, script) {
+			continue
+		}
+
+		secret := ops[2].data
+		if bytes.Equal(system.Sha256Hash(secret), contract.SecretHash) {
+			return secret, nil
+		}
+	}
+
+	return nil, ErrSecretNotFound
+}
+
+func readVarInt(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch prefix {
+	case 0xfd:
+		var v uint16
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case 0xfe:
+		var v uint32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case 0xff:
+		var v uint64
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	default:
+		return uint64(prefix), nil
+	}
+}
+
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	length, err := readVarInt(r)
+	if err != nil || length > uint64(r.Len()) {
+		return nil, ErrInvalidBTCTx
+	}
+
+	b := make([]byte, length)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func writeVarInt(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 0xfd:
+		buf.WriteByte(byte(v))
+	case v <= 0xffff:
+		buf.WriteByte(0xfd)
+		binary.Write(buf, binary.LittleEndian, uint16(v))
+	case v <= 0xffffffff:
+		buf.WriteByte(0xfe)
+		binary.Write(buf, binary.LittleEndian, uint32(v))
+	default:
+		buf.WriteByte(0xff)
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+}
+
+func writeVarBytes(buf *bytes.Buffer, b []byte) {
+	writeVarInt(buf, uint64(len(b)))
+	buf.Write(b)
+}
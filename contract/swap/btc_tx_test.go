@@ -0,0 +1,130 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package swap
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/secp256k1"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestSwapContract returns the contract locked with secret and the tx that funds it
+func newTestSwapContract(t *testing.T) (secret []byte, script []byte, contractTx *BTCTx, recipient, refunder *ecdsa.PrivateKey) {
+	recipient, _ = crypto.GenerateKey()
+	refunder, _ = crypto.GenerateKey()
+	secret, secretHash, err := NewSecret()
+	assert.Equal(t, err, nil)
+
+	contract := &BTCContract{
+		SecretHash: secretHash,
+		Recipient:  Hash160(CompressedPubKey(&recipient.PublicKey)),
+		Refund:     Hash160(CompressedPubKey(&refunder.PublicKey)),
+		LockTime:   1600000000,
+	}
+
+	script, err = contract.Script()
+	assert.Equal(t, err, nil)
+
+	contractTx = &BTCTx{
+		Version: 1,
+		TxIn:    []*BTCTxIn{{BTCOutPoint{Index: 1}, []byte{1, 2, 3}, finalSequence}},
+		TxOut: []*BTCTxOut{
+			{50000, payToPubKeyHashScript(contract.Refund)},
+			{100000, payToScriptHashScript(script)},
+		},
+	}
+
+	return secret, script, contractTx, recipient, refunder
+}
+
+// verifyTestBTCSignature verifies the signature of the first input pushed by scriptSig
+func verifyTestBTCSignature(t *testing.T, tx *BTCTx, script []byte) {
+	ops, err := parseScript(tx.TxIn[0].SignatureScript)
+	assert.Equal(t, err, nil)
+
+	sig, pubKey := ops[0].data, ops[1].data
+	assert.Equal(t, sig[len(sig)-1], byte(sigHashAll))
+
+	rs, err := parseDERSignature(sig[:len(sig)-1])
+	assert.Equal(t, err, nil)
+	assert.Equal(t, secp256k1.VerifySignature(pubKey, tx.SignatureHash(0, script), rs), true)
+}
+
+func Test_BTCTx_EncodeDecode(t *testing.T) {
+	_, _, tx, _, _ := newTestSwapContract(t)
+
+	decoded, err := DecodeBTCTx(tx.Encode())
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded, tx)
+	assert.Equal(t, decoded.TxID(), tx.TxID())
+
+	_, err = DecodeBTCTx(tx.Encode()[1:])
+	assert.Equal(t, err, ErrInvalidBTCTx)
+
+	// witness serialization has marker, flag and witness of each input
+	raw := tx.Encode()
+	witness := append([]byte{}, raw[:4]...)
+	witness = append(witness, 0x00, 0x01)
+	witness = append(witness, raw[4:len(raw)-4]...)
+	witness = append(witness, 0x02, 0x01, 0xaa, 0x00)
+	witness = append(witness, raw[len(raw)-4:]...)
+
+	decoded, err = DecodeBTCTx(witness)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.TxID(), tx.TxID())
+}
+
+func Test_NewBTCRedeemTx(t *testing.T) {
+	secret, script, contractTx, recipient, refunder := newTestSwapContract(t)
+
+	redeemTx, err := NewBTCRedeemTx(contractTx, script, secret, recipient, 1000)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, redeemTx.TxIn[0].PreviousOutPoint, BTCOutPoint{contractTx.TxID(), 1})
+	assert.Equal(t, redeemTx.TxOut[0].Value, int64(99000))
+	assert.Equal(t, redeemTx.TxOut[0].PkScript, payToPubKeyHashScript(Hash160(CompressedPubKey(&recipient.PublicKey))))
+	verifyTestBTCSignature(t, redeemTx, script)
+
+	// the secret is revealed by the redeem tx
+	decoded, err := DecodeBTCTx(redeemTx.Encode())
+	assert.Equal(t, err, nil)
+	extracted, err := ExtractBTCSecret(decoded, script)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, extracted, secret)
+
+	_, err = NewBTCRedeemTx(contractTx, script, secret, refunder, 1000)
+	assert.NotEqual(t, err, nil)
+
+	_, err = NewBTCRedeemTx(contractTx, script, bytes.Repeat([]byte{1}, SecretSize), recipient, 1000)
+	assert.Equal(t, err, ErrSecretMismatch)
+
+	_, err = NewBTCRedeemTx(contractTx, script, secret, recipient, 100000)
+	assert.NotEqual(t, err, nil)
+}
+
+func Test_NewBTCRefundTx(t *testing.T) {
+	_, script, contractTx, recipient, refunder := newTestSwapContract(t)
+
+	refundTx, err := NewBTCRefundTx(contractTx, script, refunder, 1000)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, refundTx.LockTime, uint32(1600000000))
+	assert.Equal(t, refundTx.TxIn[0].Sequence, uint32(lockTimeSequence))
+	verifyTestBTCSignature(t, refundTx, script)
+
+	// no secret in refund tx
+	_, err = ExtractBTCSecret(refundTx, script)
+	assert.Equal(t, err, ErrSecretNotFound)
+
+	_, err = NewBTCRefundTx(contractTx, script, recipient, 1000)
+	assert.NotEqual(t, err, nil)
+
+	contractTx.TxOut = contractTx.TxOut[:1]
+	_, err = NewBTCRefundTx(contractTx, script, refunder, 1000)
+	assert.Equal(t, err, ErrContractOutputNotFound)
+}
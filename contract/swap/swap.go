@@ -0,0 +1,171 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+// Package swap implements the cross-chain atomic swap between Scdo and bitcoin-like chains.
+// Both sides lock the fund with the same secret hash: the initiator locks first with the
+// longer lock time, and the participant locks with the shorter one after auditing it. When
+// the initiator redeems the participant contract, the secret is revealed on chain and the
+// participant uses it to redeem the initiator contract. Either side refunds after its lock
+// time if the swap is not completed.
+package swap
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+const (
+	// SecretSize is the size of secret and its SHA256 hash
+	SecretSize = 32
+
+	// InitiatorLockDuration is the default lock duration of initiator contract
+	InitiatorLockDuration = 48 * time.Hour
+
+	// ParticipantLockDuration is the default lock duration of participant contract
+	ParticipantLockDuration = 24 * time.Hour
+
+	// MinLockTimeGap is the min duration that the participant contract expires before the
+	// initiator contract, so that the participant has time to redeem with the revealed secret.
+	MinLockTimeGap = 12 * time.Hour
+
+	// MinRedeemWindow is the min duration before the lock time to redeem the contract safely
+	MinRedeemWindow = 2 * time.Hour
+)
+
+var (
+	// ErrSecretMismatch is returned when the SHA256 hash of secret does not match
+	ErrSecretMismatch = errors.New("the secret does not match the secret hash")
+
+	// ErrInvalidLockTime is returned when the lock times are not safe for the swap
+	ErrInvalidLockTime = errors.New("invalid lock time")
+
+	// ErrNotWithdrawTx is returned when the tx does not withdraw the Scdo HTLC
+	ErrNotWithdrawTx = errors.New("not the withdraw tx of HTLC")
+)
+
+// NewSecret returns the random secret and its SHA256 hash
+func NewSecret() (secret []byte, secretHash []byte, err error) {
+	secret = make([]byte, SecretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, nil, err
+	}
+
+	return secret, system.Sha256Hash(secret), nil
+}
+
+// VerifySecret returns whether the secret matches the secret hash
+func VerifySecret(secret, secretHash []byte) bool {
+	return len(secret) == SecretSize && bytes.Equal(system.Sha256Hash(secret), secretHash)
+}
+
+// ValidateLockTimes checks the lock times of both contracts at now. The participant contract
+// must expire at least MinLockTimeGap before the initiator contract, and it must leave at
+// least MinRedeemWindow for the initiator to redeem.
+func ValidateLockTimes(initiatorLockTime, participantLockTime int64, now time.Time) error {
+	if participantLockTime-now.Unix() < int64(MinRedeemWindow/time.Second) {
+		return fmt.Errorf("%s, the participant contract expires at %v, which is too soon", ErrInvalidLockTime, time.Unix(participantLockTime, 0))
+	}
+
+	if initiatorLockTime-participantLockTime < int64(MinLockTimeGap/time.Second) {
+		return fmt.Errorf("%s, the initiator contract must expire at least %v after the participant contract", ErrInvalidLockTime, MinLockTimeGap)
+	}
+
+	return nil
+}
+
+// Audit checks the contract is locked with the secret hash for the recipient, and it
+// does not expire within the min duration after now.
+func (c *BTCContract) Audit(secretHash, recipient []byte, now time.Time, min time.Duration) error {
+	if !bytes.Equal(c.SecretHash, secretHash) {
+		return ErrSecretMismatch
+	}
+
+	if !bytes.Equal(c.Recipient, recipient) {
+		return errors.New("the contract is not for the recipient")
+	}
+
+	if c.LockTime-now.Unix() < int64(min/time.Second) {
+		return fmt.Errorf("%s, the contract expires at %v", ErrInvalidLockTime, time.Unix(c.LockTime, 0))
+	}
+
+	return nil
+}
+
+// ScdoHTLC is the HTLC stored in the Scdo hash time lock system contract
+type ScdoHTLC struct {
+	Tx *types.Transaction
+	system.HashTimeLock
+	Refunded   bool
+	Withdrawed bool
+	Preimage   common.Bytes
+}
+
+// DecodeScdoHTLC decodes the result of HTLC system contract
+func DecodeScdoHTLC(data []byte) (*ScdoHTLC, error) {
+	h := new(ScdoHTLC)
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("invalid HTLC, %s", err)
+	}
+
+	if h.Tx == nil {
+		return nil, errors.New("invalid HTLC, no create tx")
+	}
+
+	return h, nil
+}
+
+// Audit checks the HTLC is locked with the secret hash for the recipient, and it is not
+// completed and does not expire within the min duration after now.
+func (h *ScdoHTLC) Audit(secretHash []byte, recipient common.Address, now time.Time, min time.Duration) error {
+	if !bytes.Equal(h.HashLock, secretHash) {
+		return ErrSecretMismatch
+	}
+
+	if h.To != recipient {
+		return errors.New("the HTLC is not for the recipient")
+	}
+
+	if h.Refunded || h.Withdrawed {
+		return errors.New("the HTLC is already completed")
+	}
+
+	if h.TimeLock-now.Unix() < int64(min/time.Second) {
+		return fmt.Errorf("%s, the HTLC expires at %v", ErrInvalidLockTime, time.Unix(h.TimeLock, 0))
+	}
+
+	return nil
+}
+
+// Refundable returns whether the HTLC could be refunded at now
+func (h *ScdoHTLC) Refundable(now time.Time) bool {
+	return !h.Refunded && !h.Withdrawed && now.Unix() >= h.TimeLock
+}
+
+// ExtractScdoSecret returns the secret revealed by the tx that withdraws the HTLC of hash
+func ExtractScdoSecret(tx *types.Transaction, htlcHash common.Hash, secretHash []byte) ([]byte, error) {
+	payload := tx.Data.Payload
+	if tx.Data.To != system.HashTimeLockContractAddress || len(payload) == 0 || payload[0] != system.CmdWithdraw {
+		return nil, ErrNotWithdrawTx
+	}
+
+	var input system.Withdrawing
+	if err := json.Unmarshal(payload[1:], &input); err != nil || !input.Hash.Equal(htlcHash) {
+		return nil, ErrNotWithdrawTx
+	}
+
+	if !VerifySecret(input.Preimage, secretHash) {
+		return nil, ErrSecretMismatch
+	}
+
+	return input.Preimage, nil
+}
@@ -0,0 +1,89 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package swap
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateLockTimes(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	initiator := now.Add(InitiatorLockDuration).Unix()
+	participant := now.Add(ParticipantLockDuration).Unix()
+
+	assert.Equal(t, ValidateLockTimes(initiator, participant, now), nil)
+
+	// the participant contract expires too late
+	assert.NotEqual(t, ValidateLockTimes(participant+1, participant, now), nil)
+
+	// the participant contract expires too soon
+	assert.NotEqual(t, ValidateLockTimes(initiator, now.Add(time.Hour).Unix(), now), nil)
+}
+
+func Test_ScdoHTLC(t *testing.T) {
+	secret, secretHash, err := NewSecret()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, VerifySecret(secret, secretHash), true)
+
+	to := common.BytesToAddress([]byte{1, 2, 3})
+	now := time.Unix(1600000000, 0)
+	data, _ := json.Marshal(map[string]interface{}{
+		"Tx":         &types.Transaction{Hash: common.StringToHash("htlc")},
+		"HashLock":   common.Bytes(secretHash),
+		"TimeLock":   now.Add(ParticipantLockDuration).Unix(),
+		"To":         to,
+		"Refunded":   false,
+		"Withdrawed": false,
+		"Preimage":   common.Bytes{},
+	})
+
+	h, err := DecodeScdoHTLC(data)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, h.Tx.Hash, common.StringToHash("htlc"))
+	assert.Equal(t, h.Audit(secretHash, to, now, MinRedeemWindow), nil)
+	assert.Equal(t, h.Audit(secretHash[1:], to, now, MinRedeemWindow), ErrSecretMismatch)
+	assert.NotEqual(t, h.Audit(secretHash, common.EmptyAddress, now, MinRedeemWindow), nil)
+	assert.NotEqual(t, h.Audit(secretHash, to, now, 2*ParticipantLockDuration), nil)
+
+	assert.Equal(t, h.Refundable(now), false)
+	assert.Equal(t, h.Refundable(now.Add(ParticipantLockDuration)), true)
+	h.Withdrawed = true
+	assert.Equal(t, h.Refundable(now.Add(ParticipantLockDuration)), false)
+}
+
+func Test_ExtractScdoSecret(t *testing.T) {
+	secret, secretHash, _ := NewSecret()
+	htlcHash := common.StringToHash("htlc")
+
+	input, _ := json.Marshal(system.Withdrawing{Hash: htlcHash, Preimage: secret})
+	tx := &types.Transaction{Data: types.TransactionData{
+		To:      system.HashTimeLockContractAddress,
+		Amount:  big.NewInt(0),
+		Payload: append([]byte{system.CmdWithdraw}, input...),
+	}}
+
+	extracted, err := ExtractScdoSecret(tx, htlcHash, secretHash)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, []byte(extracted), secret)
+
+	_, err = ExtractScdoSecret(tx, common.StringToHash("other"), secretHash)
+	assert.Equal(t, err, ErrNotWithdrawTx)
+
+	_, err = ExtractScdoSecret(tx, htlcHash, secret)
+	assert.Equal(t, err, ErrSecretMismatch)
+
+	tx.Data.Payload[0] = system.CmdRefund
+	_, err = ExtractScdoSecret(tx, htlcHash, secretHash)
+	assert.Equal(t, err, ErrNotWithdrawTx)
+}
@@ -0,0 +1,326 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// BTCHeaderLength is the length of serialized bitcoin block header
+	BTCHeaderLength = 80
+
+	// btcBlocksPerRetarget is the number of blocks between difficulty retargets
+	btcBlocksPerRetarget = 2016
+
+	// btcMedianTimeBlocks is the number of previous blocks to calculate the median time past
+	btcMedianTimeBlocks = 11
+
+	// btcMaxFutureTime is the max seconds that the block time could be ahead of the scdo block
+	btcMaxFutureTime = 2 * 60 * 60
+)
+
+var (
+	// ErrBTCInvalidHeader is returned when the serialized bitcoin block header is invalid
+	ErrBTCInvalidHeader = errors.New("invalid bitcoin block header")
+
+	// ErrBTCInvalidHash is returned when the bitcoin hash is not 32 bytes hex
+	ErrBTCInvalidHash = errors.New("invalid bitcoin hash")
+
+	bigOne = big.NewInt(1)
+
+	// oneLsh256 is 1 shifted left 256 bits, which is used to calculate the work of block
+	oneLsh256 = new(big.Int).Lsh(bigOne, 256)
+)
+
+// BTCHash is the double SHA256 hash used by bitcoin in internal byte order, and it is
+// displayed in reversed byte order the same as bitcoind.
+type BTCHash [32]byte
+
+// String returns the hex of hash in display order
+func (h BTCHash) String() string {
+	reversed := h.reverse()
+	return hex.EncodeToString(reversed[:])
+}
+
+// Bytes returns a copy of hash bytes in internal order
+func (h BTCHash) Bytes() []byte {
+	return h[:]
+}
+
+// MarshalText implements the encoding.TextMarshaler interface
+func (h BTCHash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (h *BTCHash) UnmarshalText(text []byte) error {
+	hash, err := NewBTCHashFromString(string(text))
+	if err != nil {
+		return err
+	}
+
+	*h = hash
+	return nil
+}
+
+// Big returns the hash as a big-endian number to compare with the target
+func (h BTCHash) Big() *big.Int {
+	reversed := h.reverse()
+	return new(big.Int).SetBytes(reversed[:])
+}
+
+func (h BTCHash) reverse() BTCHash {
+	for i, j := 0, len(h)-1; i < j; i, j = i+1, j-1 {
+		h[i], h[j] = h[j], h[i]
+	}
+
+	return h
+}
+
+// NewBTCHashFromString parses the hash hex in display order, with or without 0x prefix
+func NewBTCHashFromString(s string) (BTCHash, error) {
+	var hash BTCHash
+
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(hash) {
+		return hash, ErrBTCInvalidHash
+	}
+
+	copy(hash[:], b)
+	return hash.reverse(), nil
+}
+
+// newBTCHashFromDisplayBytes creates the hash from bytes in display order
+func newBTCHashFromDisplayBytes(b []byte) (BTCHash, error) {
+	var hash BTCHash
+	if len(b) != len(hash) {
+		return hash, ErrBTCInvalidHash
+	}
+
+	copy(hash[:], b)
+	return hash.reverse(), nil
+}
+
+// BTCDoubleHash returns the double SHA256 hash of data, e.g. the id of raw tx
+func BTCDoubleHash(data []byte) BTCHash {
+	first := sha256.Sum256(data)
+	return BTCHash(sha256.Sum256(first[:]))
+}
+
+// BTCHeader is the bitcoin block header
+type BTCHeader struct {
+	Version    int32
+	PrevBlock  BTCHash
+	MerkleRoot BTCHash
+	Timestamp  uint32
+	Bits       uint32
+	Nonce      uint32
+}
+
+// DecodeBTCHeader decodes the 80 bytes serialized bitcoin block header
+func DecodeBTCHeader(b []byte) (*BTCHeader, error) {
+	if len(b) != BTCHeaderLength {
+		return nil, ErrBTCInvalidHeader
+	}
+
+	header := &BTCHeader{
+		Version:   int32(binary.LittleEndian.Uint32(b[0:4])),
+		Timestamp: binary.LittleEndian.Uint32(b[68:72]),
+		Bits:      binary.LittleEndian.Uint32(b[72:76]),
+		Nonce:     binary.LittleEndian.Uint32(b[76:80]),
+	}
+	copy(header.PrevBlock[:], b[4:36])
+	copy(header.MerkleRoot[:], b[36:68])
+
+	return header, nil
+}
+
+// Encode returns the 80 bytes serialization of header
+func (h *BTCHeader) Encode() []byte {
+	b := make([]byte, BTCHeaderLength)
+	binary.LittleEndian.PutUint32(b[0:4], uint32(h.Version))
+	copy(b[4:36], h.PrevBlock[:])
+	copy(b[36:68], h.MerkleRoot[:])
+	binary.LittleEndian.PutUint32(b[68:72], h.Timestamp)
+	binary.LittleEndian.PutUint32(b[72:76], h.Bits)
+	binary.LittleEndian.PutUint32(b[76:80], h.Nonce)
+
+	return b
+}
+
+// Hash returns the block hash
+func (h *BTCHeader) Hash() BTCHash {
+	return BTCDoubleHash(h.Encode())
+}
+
+func (h *BTCHeader) String() string {
+	return fmt.Sprintf("BTCHeader[Hash=%v, PrevBlock=%v, Timestamp=%v, Bits=%08x]", h.Hash(), h.PrevBlock, h.Timestamp, h.Bits)
+}
+
+// BTCCompactToBig converts the compact representation of target to big number
+func BTCCompactToBig(compact uint32) *big.Int {
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+
+	var n *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		n = big.NewInt(int64(mantissa))
+	} else {
+		n = big.NewInt(int64(mantissa))
+		n.Lsh(n, 8*(exponent-3))
+	}
+
+	if isNegative {
+		n.Neg(n)
+	}
+
+	return n
+}
+
+// BTCBigToCompact converts the target to its compact representation
+func BTCBigToCompact(n *big.Int) uint32 {
+	if n.Sign() == 0 {
+		return 0
+	}
+
+	var mantissa uint32
+	exponent := uint(len(n.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(n.Uint64())
+		mantissa <<= 8 * (3 - exponent)
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(n, 8*(exponent-3)).Uint64())
+	}
+
+	// the sign bit is set, so use the next byte of exponent
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+
+	compact := uint32(exponent<<24) | mantissa
+	if n.Sign() < 0 {
+		compact |= 0x00800000
+	}
+
+	return compact
+}
+
+// btcWork returns the expected number of hashes to find the block of target, 2^256 / (target+1)
+func btcWork(bits uint32) *big.Int {
+	target := BTCCompactToBig(bits)
+	if target.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	return new(big.Int).Div(oneLsh256, target.Add(target, bigOne))
+}
+
+// VerifyBTCMerkleBranch returns whether the tx at the index of block is proved by the merkle
+// branch from bottom to top. The index must be fully consumed by the branch, so the branch
+// could not prove an inner node of merkle tree.
+func VerifyBTCMerkleBranch(txHash, merkleRoot BTCHash, index uint32, branch []BTCHash) bool {
+	if len(branch) > 32 {
+		return false
+	}
+
+	hash, data := txHash, make([]byte, 64)
+	for _, sibling := range branch {
+		if index&1 == 1 {
+			copy(data[:32], sibling[:])
+			copy(data[32:], hash[:])
+		} else {
+			copy(data[:32], hash[:])
+			copy(data[32:], sibling[:])
+		}
+
+		hash = BTCDoubleHash(data)
+		index >>= 1
+	}
+
+	return index == 0 && hash == merkleRoot
+}
+
+// BTCParams is the bitcoin network parameters for the relay to validate headers
+type BTCParams struct {
+	Name           string
+	PowLimit       *big.Int // highest target of proof of work
+	TargetTimespan uint32   // expected seconds of each retarget period
+	NoRetargeting  bool     // the difficulty never changes, e.g. regression test network
+
+	// the relay starts from the trusted checkpoint at the retarget boundary
+	CheckpointHeader string
+	CheckpointHeight uint64
+}
+
+var (
+	// BTCMainNetParams is the parameters of bitcoin main network, and the relay starts from genesis
+	BTCMainNetParams = BTCParams{
+		Name:             "mainnet",
+		PowLimit:         new(big.Int).Sub(new(big.Int).Lsh(bigOne, 224), bigOne),
+		TargetTimespan:   14 * 24 * 60 * 60,
+		CheckpointHeader: "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c",
+		CheckpointHeight: 0,
+	}
+
+	// BTCRegressionNetParams is the parameters of bitcoin regression test network
+	BTCRegressionNetParams = BTCParams{
+		Name:             "regtest",
+		PowLimit:         new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne),
+		TargetTimespan:   14 * 24 * 60 * 60,
+		NoRetargeting:    true,
+		CheckpointHeader: "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4adae5494dffff7f2002000000",
+		CheckpointHeight: 0,
+	}
+)
+
+// checkpoint returns the decoded checkpoint header
+func (p *BTCParams) checkpoint() *BTCHeader {
+	b, err := hex.DecodeString(p.CheckpointHeader)
+	if err != nil {
+		panic(err)
+	}
+
+	header, err := DecodeBTCHeader(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return header
+}
+
+// nextRequiredBits returns the difficulty of the block at height after the parent, and
+// the timespan is the seconds from the first to the last block of the retarget period.
+func (p *BTCParams) nextRequiredBits(height uint64, parentBits uint32, timespan int64) uint32 {
+	if p.NoRetargeting || height%btcBlocksPerRetarget != 0 {
+		return parentBits
+	}
+
+	minTimespan, maxTimespan := int64(p.TargetTimespan/4), int64(p.TargetTimespan*4)
+	if timespan < minTimespan {
+		timespan = minTimespan
+	} else if timespan > maxTimespan {
+		timespan = maxTimespan
+	}
+
+	target := BTCCompactToBig(parentBits)
+	target.Mul(target, big.NewInt(timespan))
+	target.Div(target, big.NewInt(int64(p.TargetTimespan)))
+	if target.Cmp(p.PowLimit) > 0 {
+		target.Set(p.PowLimit)
+	}
+
+	return BTCBigToCompact(target)
+}
@@ -0,0 +1,130 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BTCHeader_Hash(t *testing.T) {
+	mainnet := BTCMainNetParams.checkpoint()
+	assert.Equal(t, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", mainnet.Hash().String())
+	assert.Equal(t, "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", mainnet.MerkleRoot.String())
+	assert.Equal(t, uint32(0x1d00ffff), mainnet.Bits)
+
+	regtest := BTCRegressionNetParams.checkpoint()
+	assert.Equal(t, "0f9188f13cb7b2c71f2a335e3a4fc328bf5beb436012afca590b1a11466e2206", regtest.Hash().String())
+
+	// encode and decode
+	decoded, err := DecodeBTCHeader(mainnet.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, mainnet, decoded)
+
+	_, err = DecodeBTCHeader(mainnet.Encode()[1:])
+	assert.Equal(t, ErrBTCInvalidHeader, err)
+}
+
+func Test_BTCHash_String(t *testing.T) {
+	hash, err := NewBTCHashFromString("0x000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+	assert.NoError(t, err)
+	assert.Equal(t, BTCMainNetParams.checkpoint().Hash(), hash)
+	assert.Equal(t, byte(0x6f), hash[0])
+
+	text, err := hash.MarshalText()
+	assert.NoError(t, err)
+
+	var unmarshaled BTCHash
+	assert.NoError(t, unmarshaled.UnmarshalText(text))
+	assert.Equal(t, hash, unmarshaled)
+
+	_, err = NewBTCHashFromString("0x1234")
+	assert.Equal(t, ErrBTCInvalidHash, err)
+}
+
+func Test_BTCCompact(t *testing.T) {
+	for _, bits := range []uint32{0x1d00ffff, 0x1b0404cb, 0x207fffff, 0x170d21b9, 0x03123456} {
+		assert.Equal(t, bits, BTCBigToCompact(BTCCompactToBig(bits)))
+	}
+
+	assert.Equal(t, "00000000ffff0000000000000000000000000000000000000000000000000000", hex.EncodeToString(padBytes(BTCCompactToBig(0x1d00ffff).Bytes(), 32)))
+	assert.Equal(t, -1, BTCCompactToBig(0x04923456).Sign())
+	assert.Equal(t, "100010001", btcWork(0x1d00ffff).Text(16))
+}
+
+func Test_BTCParams_Retarget(t *testing.T) {
+	params := &BTCMainNetParams
+
+	// not a retarget boundary
+	assert.Equal(t, uint32(0x1d00ffff), params.nextRequiredBits(2015, 0x1d00ffff, 1))
+
+	// blocks are found twice as fast as expected
+	assert.Equal(t, uint32(0x1c7fff80), params.nextRequiredBits(2016, 0x1d00ffff, int64(params.TargetTimespan/2)))
+
+	// the adjustment is limited to 4 times
+	assert.Equal(t, uint32(0x1c3fffc0), params.nextRequiredBits(4032, 0x1d00ffff, 1))
+
+	// the target is limited by pow limit
+	assert.Equal(t, uint32(0x1d00ffff), params.nextRequiredBits(4032, 0x1d00ffff, int64(params.TargetTimespan*10)))
+
+	// regtest never retargets
+	assert.Equal(t, uint32(0x207fffff), BTCRegressionNetParams.nextRequiredBits(2016, 0x207fffff, 1))
+}
+
+func Test_VerifyBTCMerkleBranch(t *testing.T) {
+	txs := []BTCHash{BTCDoubleHash([]byte("tx0")), BTCDoubleHash([]byte("tx1")), BTCDoubleHash([]byte("tx2"))}
+	root, branches := newTestBTCMerkleTree(txs)
+
+	for i, tx := range txs {
+		assert.True(t, VerifyBTCMerkleBranch(tx, root, uint32(i), branches[i]))
+	}
+
+	// wrong index or tx
+	assert.False(t, VerifyBTCMerkleBranch(txs[0], root, 1, branches[0]))
+	assert.False(t, VerifyBTCMerkleBranch(txs[1], root, 0, branches[0]))
+
+	// index out of the branch
+	assert.False(t, VerifyBTCMerkleBranch(txs[0], root, 4, branches[0]))
+
+	// single tx block
+	assert.True(t, VerifyBTCMerkleBranch(txs[0], txs[0], 0, nil))
+}
+
+// newTestBTCMerkleTree returns the merkle root of txs and the branch of each tx
+func newTestBTCMerkleTree(txs []BTCHash) (BTCHash, [][]BTCHash) {
+	branches := make([][]BTCHash, len(txs))
+	indexes := make([]int, len(txs))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	level := append([]BTCHash(nil), txs...)
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+
+		for i := range txs {
+			branches[i] = append(branches[i], level[indexes[i]^1])
+			indexes[i] /= 2
+		}
+
+		var next []BTCHash
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, BTCDoubleHash(append(append([]byte{}, level[i][:]...), level[i+1][:]...)))
+		}
+
+		level = next
+	}
+
+	return level[0], branches
+}
+
+func padBytes(b []byte, length int) []byte {
+	return append(make([]byte, length-len(b)), b...)
+}
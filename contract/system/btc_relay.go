@@ -6,14 +6,26 @@
 package system
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/hexutil"
 	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/crypto"
 )
 
+// After BTCRelayForkHeight, the btc relay is a SPV client of bitcoin. Anyone could relay
+// the raw bitcoin block headers, which are validated with the proof of work, difficulty
+// retarget, median time past and chain linkage from a trusted checkpoint. The compact
+// headers are stored in the state, and the chain with most work is the main chain. The tx
+// is verified with the merkle branch of its block on the main chain, which is buried by
+// the required confirmations.
+
 const (
 	// CmdVerifyTx is the command byte to verify btc tx
 	CmdVerifyTx = iota
@@ -23,16 +35,34 @@ const (
 	CmdStoreBlockHeader
 	// CmdGetBlockHeader is the command byte to get btc block header
 	CmdGetBlockHeader
+	// CmdStoreBlockHeaders is the command byte to store a batch of btc block headers
+	CmdStoreBlockHeaders
+	// CmdGetBestBlockHeader is the command byte to get the tip of btc main chain
+	CmdGetBestBlockHeader
+	// CmdGetBlockHeaderInfo is the command byte to get the info of btc block header
+	CmdGetBlockHeaderInfo
 )
 
-const btcConfirm = 6
+const (
+	btcConfirm = 6
+
+	// MaxBTCHeadersPerCall is the max number of headers stored in a batch
+	MaxBTCHeadersPerCall = 50
+
+	gasCmdStoreBlockHeaders  = uint64(400000) // gas used to store a batch of headers
+	gasCmdGetBestBlockHeader = uint64(200)    // gas used to get the best header
+	gasCmdGetBlockHeaderInfo = uint64(200)    // gas used to get the header info
+)
 
 var (
 	brCommands = map[byte]*cmdInfo{
-		CmdVerifyTx:         &cmdInfo{1000, verifyTx},
-		CmdRelayTx:          &cmdInfo{3000, relayTx},
-		CmdStoreBlockHeader: &cmdInfo{10000, storeBlockHeader},
-		CmdGetBlockHeader:   &cmdInfo{200, getBlockHeader},
+		CmdVerifyTx:           &cmdInfo{1000, verifyTx},
+		CmdRelayTx:            &cmdInfo{3000, relayTx},
+		CmdStoreBlockHeader:   &cmdInfo{10000, storeBlockHeader},
+		CmdGetBlockHeader:     &cmdInfo{200, getBlockHeader},
+		CmdStoreBlockHeaders:  &cmdInfo{gasCmdStoreBlockHeaders, storeBlockHeaders},
+		CmdGetBestBlockHeader: &cmdInfo{gasCmdGetBestBlockHeader, getBestBlockHeader},
+		CmdGetBlockHeaderInfo: &cmdInfo{gasCmdGetBlockHeaderInfo, getBlockHeaderInfo},
 	}
 
 	// function result
@@ -44,6 +74,36 @@ var (
 	keyBlocksHash  = common.BytesToHash([]byte("BTC-Blocks-Hash"))
 	// @todo will change after the relay chain is successfully established.
 	fee uint64 = 1
+
+	keyBTCBestHash = common.BytesToHash([]byte("BTC-Best-Hash"))
+
+	// btcRelayParams is the bitcoin network that the relay follows
+	btcRelayParams = &BTCMainNetParams
+
+	// ErrBTCHeaderExists is returned when the btc block header is already stored
+	ErrBTCHeaderExists = errors.New("btc block header already exists")
+
+	// ErrBTCUnknownBlock is returned when the btc block or its parent is not stored
+	ErrBTCUnknownBlock = errors.New("unknown btc block")
+
+	// ErrBTCInvalidDifficulty is returned when the bits of header is not the required difficulty
+	ErrBTCInvalidDifficulty = errors.New("invalid btc block difficulty")
+
+	// ErrBTCInvalidPoW is returned when the block hash is higher than its target
+	ErrBTCInvalidPoW = errors.New("invalid btc block proof of work")
+
+	// ErrBTCInvalidTimestamp is returned when the block time is not after the median time
+	// past, or too far in the future
+	ErrBTCInvalidTimestamp = errors.New("invalid btc block timestamp")
+
+	// ErrBTCNotMainChain is returned when the btc block is not on the main chain
+	ErrBTCNotMainChain = errors.New("btc block is not on the main chain")
+
+	// ErrBTCNotConfirmed is returned when the btc block has not enough confirmations
+	ErrBTCNotConfirmed = errors.New("btc block has not enough confirmations")
+
+	// ErrBTCInvalidTx is returned when the raw btc tx is invalid
+	ErrBTCInvalidTx = errors.New("invalid btc tx")
 )
 
 // BTCBlock btc block structure
@@ -71,11 +131,54 @@ func (r *RelayRequest) String() string {
 	return fmt.Sprintf("RelayRequest[BTCBlock=%v, TxHex=%v, RelayAddress=%v]", r.BTCBlock.String(), r.TxHex, r.RelayAddress.Hex())
 }
 
+// BTCTxProof is the SPV proof that the btc tx is included in the block, which is the
+// request to verify tx after fork.
+type BTCTxProof struct {
+	BlockHash BTCHash   // hash of the block that includes the tx
+	TxHex     string    // raw tx serialized without witness
+	Index     uint32    // position of the tx in the block
+	Branch    []BTCHash // merkle branch from the bottom to the top
+	// required confirmations of the block, and at least 6
+	Confirmations uint64
+	// This is used to relay the verification of successful tx to the contract address
+	RelayAddress common.Address
+}
+
+// BTCBlockHeader is the info of stored btc block header
+type BTCBlockHeader struct {
+	Hash          BTCHash
+	PrevBlock     BTCHash
+	MerkleRoot    BTCHash
+	Height        uint64
+	Timestamp     uint32
+	Bits          uint32
+	ChainWork     *big.Int
+	Relayer       common.Address
+	MainChain     bool
+	Confirmations uint64 // confirmations on the main chain, or 0 if on a fork
+}
+
+// btcStoredHeader is the compact btc block header in the state
+type btcStoredHeader struct {
+	PrevBlock   BTCHash
+	MerkleRoot  BTCHash
+	Height      uint64
+	Timestamp   uint32
+	Bits        uint32
+	PeriodStart uint32 // timestamp of the first block in the retarget period
+	ChainWork   *big.Int
+	Relayer     common.Address
+}
+
 // verify that tx exists based on request, the amount will be transferred
 // to BTCRelayContractAddress if it doesn't match the block. When it
 // matches the block, the amount will be transferred to the relayer. If an
 // error occurs, the amount will be reverted to user(from).
 func verifyTx(request []byte, ctx *Context) ([]byte, error) {
+	if ctx.btcRelayEnabled() {
+		return verifyTxProof(request, ctx)
+	}
+
 	var relayRequest RelayRequest
 	if err := json.Unmarshal(request, &relayRequest); err != nil {
 		return failure, fmt.Errorf("Invalid request parameter, %s", err)
@@ -101,12 +204,82 @@ func verifyTx(request []byte, ctx *Context) ([]byte, error) {
 	ctx.statedb.AddBalance(btcBlock.Relayer, amount)
 	ctx.statedb.SubBalance(BTCRelayContractAddress, amount)
 
+	result := failure
 	for _, txHex := range btcBlock.TxHexs {
 		if relayRequest.TxHex == txHex {
-			return success, nil
+			result = success
+			break
 		}
 	}
 
+	if err := ctx.emit("BTCTxVerified", ctx.tx.Data.From, btcBlock.Relayer, relayRequest.TxHex, amount, bytes.Equal(result, success)); err != nil {
+		return failure, err
+	}
+
+	return result, nil
+}
+
+// verifyTxProof verifies the SPV proof of tx, and the fee is paid to the relayer of block
+func verifyTxProof(request []byte, ctx *Context) ([]byte, error) {
+	var proof BTCTxProof
+	if err := json.Unmarshal(request, &proof); err != nil {
+		return failure, fmt.Errorf("Invalid request parameter, %s", err)
+	}
+
+	amount := ctx.tx.Data.Amount
+	if amount.Uint64() < fee {
+		return failure, fmt.Errorf("Verify tx fee is not enough, expected[%d], actual[%d]", fee, amount)
+	}
+
+	rawTx, err := hexutil.HexToBytes(proof.TxHex)
+	// 64 bytes tx could be forged from the inner node of merkle tree
+	if err != nil || len(rawTx) == 0 || len(rawTx) == 64 {
+		return failure, ErrBTCInvalidTx
+	}
+
+	header, err := getBTCHeader(ctx.statedb, proof.BlockHash)
+	if err != nil {
+		return failure, err
+	}
+
+	if header == nil {
+		return failure, ErrBTCUnknownBlock
+	}
+
+	if getBTCMainChainHash(ctx.statedb, header.Height) != proof.BlockHash {
+		return failure, ErrBTCNotMainChain
+	}
+
+	required := proof.Confirmations
+	if required < btcConfirm {
+		required = btcConfirm
+	}
+
+	best, err := getBTCBestHeader(ctx.statedb)
+	if err != nil {
+		return failure, err
+	}
+
+	if best.Height-header.Height+1 < required {
+		return failure, ErrBTCNotConfirmed
+	}
+
+	// Tranfer amount to Relayer
+	ctx.statedb.CreateAccount(header.Relayer)
+	ctx.statedb.AddBalance(header.Relayer, amount)
+	ctx.statedb.SubBalance(BTCRelayContractAddress, amount)
+
+	txHash := BTCDoubleHash(rawTx)
+	verified := VerifyBTCMerkleBranch(txHash, header.MerkleRoot, proof.Index, proof.Branch)
+
+	if err := ctx.emit("BTCTxVerified", ctx.tx.Data.From, header.Relayer, txHash.String(), amount, verified); err != nil {
+		return failure, err
+	}
+
+	if verified {
+		return success, nil
+	}
+
 	return failure, nil
 }
 
@@ -127,6 +300,14 @@ func relayTx(request []byte, ctx *Context) ([]byte, error) {
 
 // storage of Bitcoin block headers
 func storeBlockHeader(request []byte, ctx *Context) ([]byte, error) {
+	if ctx.btcRelayEnabled() {
+		if err := storeBTCHeader(request, ctx); err != nil {
+			return failure, err
+		}
+
+		return success, nil
+	}
+
 	if !isRelayer(ctx.tx.Data.From) {
 		return failure, fmt.Errorf("Invaild block relayer[%s]", ctx.tx.Data.From.Hex())
 	}
@@ -151,9 +332,162 @@ func storeBlockHeader(request []byte, ctx *Context) ([]byte, error) {
 	}
 
 	ctx.statedb.SetData(BTCRelayContractAddress, keyBlocksHash, bytes)
+
+	if err = ctx.emit("BTCBlockHeaderStored", ctx.tx.Data.From, relayRequest.BlockHeaderHex, relayRequest.Height); err != nil {
+		return failure, err
+	}
+
+	return success, nil
+}
+
+// storeBlockHeaders stores the concatenated raw headers in order, the parent first
+func storeBlockHeaders(request []byte, ctx *Context) ([]byte, error) {
+	if !ctx.btcRelayEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	count := len(request) / BTCHeaderLength
+	if count == 0 || count > MaxBTCHeadersPerCall || len(request)%BTCHeaderLength != 0 {
+		return failure, ErrBTCInvalidHeader
+	}
+
+	for i := 0; i < count; i++ {
+		if err := storeBTCHeader(request[i*BTCHeaderLength:(i+1)*BTCHeaderLength], ctx); err != nil {
+			return failure, fmt.Errorf("Failed to store the header %d, %s", i, err)
+		}
+	}
+
 	return success, nil
 }
 
+// storeBTCHeader validates the raw header and stores it, and the main chain is switched
+// to the header if it has the most work.
+func storeBTCHeader(raw []byte, ctx *Context) error {
+	header, err := DecodeBTCHeader(raw)
+	if err != nil {
+		return err
+	}
+
+	hash := header.Hash()
+	stored, err := getBTCHeader(ctx.statedb, hash)
+	if err != nil {
+		return err
+	}
+
+	if stored != nil {
+		return ErrBTCHeaderExists
+	}
+
+	parent, err := getBTCHeader(ctx.statedb, header.PrevBlock)
+	if err != nil {
+		return err
+	}
+
+	if parent == nil {
+		return ErrBTCUnknownBlock
+	}
+
+	height := parent.Height + 1
+	timespan := int64(parent.Timestamp) - int64(parent.PeriodStart)
+	if header.Bits != btcRelayParams.nextRequiredBits(height, parent.Bits, timespan) {
+		return ErrBTCInvalidDifficulty
+	}
+
+	target := BTCCompactToBig(header.Bits)
+	if target.Sign() <= 0 || target.Cmp(btcRelayParams.PowLimit) > 0 || hash.Big().Cmp(target) > 0 {
+		return ErrBTCInvalidPoW
+	}
+
+	medianTime, err := btcMedianTimePast(ctx.statedb, header.PrevBlock, parent)
+	if err != nil {
+		return err
+	}
+
+	if header.Timestamp <= medianTime || int64(header.Timestamp) > ctx.BlockHeader.CreateTimestamp.Int64()+btcMaxFutureTime {
+		return ErrBTCInvalidTimestamp
+	}
+
+	info := &btcStoredHeader{
+		PrevBlock:   header.PrevBlock,
+		MerkleRoot:  header.MerkleRoot,
+		Height:      height,
+		Timestamp:   header.Timestamp,
+		Bits:        header.Bits,
+		PeriodStart: parent.PeriodStart,
+		ChainWork:   new(big.Int).Add(parent.ChainWork, btcWork(header.Bits)),
+		Relayer:     ctx.tx.Data.From,
+	}
+
+	if height%btcBlocksPerRetarget == 0 {
+		info.PeriodStart = header.Timestamp
+	}
+
+	ctx.statedb.CreateAccount(BTCRelayContractAddress)
+	ctx.statedb.SetData(BTCRelayContractAddress, btcHeaderKey(hash), common.SerializePanic(info))
+
+	best, err := getBTCBestHeader(ctx.statedb)
+	if err != nil {
+		return err
+	}
+
+	if info.ChainWork.Cmp(best.ChainWork) > 0 {
+		if err = setBTCBestHeader(ctx.statedb, hash, info, best.Height); err != nil {
+			return err
+		}
+	}
+
+	return ctx.emit("BTCBlockHeaderStored", ctx.tx.Data.From, hash.String(), height)
+}
+
+// setBTCBestHeader switches the main chain to the new tip, and the main chain index is
+// updated from the tip back to the fork point.
+func setBTCBestHeader(statedb *state.Statedb, hash BTCHash, tip *btcStoredHeader, oldHeight uint64) error {
+	statedb.SetData(BTCRelayContractAddress, keyBTCBestHash, hash.Bytes())
+
+	// the new tip may have more work with less blocks
+	for height := tip.Height + 1; height <= oldHeight; height++ {
+		statedb.SetData(BTCRelayContractAddress, btcMainChainKey(height), nil)
+	}
+
+	for header := tip; getBTCMainChainHash(statedb, header.Height) != hash; {
+		statedb.SetData(BTCRelayContractAddress, btcMainChainKey(header.Height), hash.Bytes())
+
+		parent, err := getBTCHeader(statedb, header.PrevBlock)
+		if err != nil {
+			return err
+		}
+
+		if parent == nil {
+			return ErrBTCUnknownBlock
+		}
+
+		hash, header = header.PrevBlock, parent
+	}
+
+	return nil
+}
+
+// btcMedianTimePast returns the median timestamp of the previous blocks
+func btcMedianTimePast(statedb *state.Statedb, hash BTCHash, header *btcStoredHeader) (uint32, error) {
+	var timestamps []uint32
+	for header != nil && len(timestamps) < btcMedianTimeBlocks {
+		timestamps = append(timestamps, header.Timestamp)
+		if hash == btcRelayParams.checkpoint().Hash() {
+			break
+		}
+
+		hash = header.PrevBlock
+
+		var err error
+		if header, err = getBTCHeader(statedb, hash); err != nil {
+			return 0, err
+		}
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	return timestamps[len(timestamps)/2], nil
+}
+
 // check if there is a stored bitcoin block header in the contract
 func getBlockHeader(request []byte, ctx *Context) ([]byte, error) {
 	amount := ctx.tx.Data.Amount
@@ -161,19 +495,108 @@ func getBlockHeader(request []byte, ctx *Context) ([]byte, error) {
 		return failure, fmt.Errorf("getBlockHeader fee is not enough, expected[%d], actual[%d]", fee, amount)
 	}
 
-	blocks, blockHeaderHex := getBTCBlocks(ctx.statedb), hexutil.BytesToHex(request)
-	btcBlock, ok := blocks[blockHeaderHex]
-	if !ok {
-		return failure, nil
+	var relayer common.Address
+	if ctx.btcRelayEnabled() {
+		hash, err := newBTCHashFromDisplayBytes(request)
+		if err != nil {
+			return failure, err
+		}
+
+		header, err := getBTCHeader(ctx.statedb, hash)
+		if err != nil {
+			return failure, err
+		}
+
+		if header == nil {
+			return failure, nil
+		}
+
+		relayer = header.Relayer
+		ctx.statedb.CreateAccount(relayer)
+	} else {
+		blocks, blockHeaderHex := getBTCBlocks(ctx.statedb), hexutil.BytesToHex(request)
+		btcBlock, ok := blocks[blockHeaderHex]
+		if !ok {
+			return failure, nil
+		}
+
+		relayer = btcBlock.Relayer
 	}
 
 	// Tranfer amount to Relayer
-	ctx.statedb.AddBalance(btcBlock.Relayer, amount)
+	ctx.statedb.AddBalance(relayer, amount)
 	ctx.statedb.SubBalance(BTCRelayContractAddress, amount)
 
 	return success, nil
 }
 
+// getBestBlockHeader returns the JSON encoded tip of main chain
+func getBestBlockHeader(request []byte, ctx *Context) ([]byte, error) {
+	if !ctx.btcRelayEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	return getBTCBlockHeaderInfo(ctx.statedb, getBTCBestHash(ctx.statedb))
+}
+
+// getBlockHeaderInfo returns the JSON encoded info of header by the hash bytes in display order
+func getBlockHeaderInfo(request []byte, ctx *Context) ([]byte, error) {
+	if !ctx.btcRelayEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	hash, err := newBTCHashFromDisplayBytes(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return getBTCBlockHeaderInfo(ctx.statedb, hash)
+}
+
+func getBTCBlockHeaderInfo(statedb *state.Statedb, hash BTCHash) ([]byte, error) {
+	info, err := GetBTCBlockHeader(statedb, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, ErrBTCUnknownBlock
+	}
+
+	return json.Marshal(info)
+}
+
+// GetBTCBlockHeader returns the info of stored btc block header, or nil if not stored
+func GetBTCBlockHeader(statedb *state.Statedb, hash BTCHash) (*BTCBlockHeader, error) {
+	header, err := getBTCHeader(statedb, hash)
+	if err != nil || header == nil {
+		return nil, err
+	}
+
+	best, err := getBTCBestHeader(statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &BTCBlockHeader{
+		Hash:       hash,
+		PrevBlock:  header.PrevBlock,
+		MerkleRoot: header.MerkleRoot,
+		Height:     header.Height,
+		Timestamp:  header.Timestamp,
+		Bits:       header.Bits,
+		ChainWork:  header.ChainWork,
+		Relayer:    header.Relayer,
+		MainChain:  getBTCMainChainHash(statedb, header.Height) == hash,
+	}
+
+	if info.MainChain {
+		info.Confirmations = best.Height - header.Height + 1
+	}
+
+	return info, nil
+}
+
 // @todo
 func isRelayer(addr common.Address) bool {
 	return true
@@ -188,3 +611,79 @@ func getBTCBlocks(statedb *state.Statedb) map[string]BTCBlock {
 
 	return blocks
 }
+
+// getBTCHeader returns the stored header, and the checkpoint is stored implicitly
+func getBTCHeader(statedb *state.Statedb, hash BTCHash) (*btcStoredHeader, error) {
+	data := statedb.GetData(BTCRelayContractAddress, btcHeaderKey(hash))
+	if len(data) == 0 {
+		if checkpoint := btcRelayParams.checkpoint(); hash == checkpoint.Hash() {
+			return &btcStoredHeader{
+				PrevBlock:   checkpoint.PrevBlock,
+				MerkleRoot:  checkpoint.MerkleRoot,
+				Height:      btcRelayParams.CheckpointHeight,
+				Timestamp:   checkpoint.Timestamp,
+				Bits:        checkpoint.Bits,
+				PeriodStart: checkpoint.Timestamp,
+				ChainWork:   btcWork(checkpoint.Bits),
+			}, nil
+		}
+
+		return nil, nil
+	}
+
+	header := new(btcStoredHeader)
+	if err := common.Deserialize(data, header); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// getBTCBestHash returns the hash of main chain tip, which is the checkpoint at first
+func getBTCBestHash(statedb *state.Statedb) BTCHash {
+	hash := btcRelayParams.checkpoint().Hash()
+	if data := statedb.GetData(BTCRelayContractAddress, keyBTCBestHash); len(data) > 0 {
+		copy(hash[:], data)
+	}
+
+	return hash
+}
+
+// getBTCBestHeader returns the tip of main chain
+func getBTCBestHeader(statedb *state.Statedb) (*btcStoredHeader, error) {
+	header, err := getBTCHeader(statedb, getBTCBestHash(statedb))
+	if err != nil {
+		return nil, err
+	}
+
+	if header == nil {
+		return nil, ErrBTCUnknownBlock
+	}
+
+	return header, nil
+}
+
+// getBTCMainChainHash returns the hash of block at the height on main chain
+func getBTCMainChainHash(statedb *state.Statedb, height uint64) BTCHash {
+	var hash BTCHash
+	if data := statedb.GetData(BTCRelayContractAddress, btcMainChainKey(height)); len(data) > 0 {
+		copy(hash[:], data)
+	} else if height == btcRelayParams.CheckpointHeight {
+		hash = btcRelayParams.checkpoint().Hash()
+	}
+
+	return hash
+}
+
+func btcHeaderKey(hash BTCHash) common.Hash {
+	return crypto.HashBytes([]byte("BTC-Header"), hash[:])
+}
+
+func btcMainChainKey(height uint64) common.Hash {
+	return crypto.HashBytes([]byte("BTC-Main-Chain"), uint64ToBytes(height))
+}
+
+// btcRelayEnabled returns whether the btc relay validates headers and verifies SPV proofs after fork
+func (ctx *Context) btcRelayEnabled() bool {
+	return ctx.BlockHeader.Height >= common.BTCRelayForkHeight
+}
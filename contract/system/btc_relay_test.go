@@ -6,6 +6,7 @@
 package system
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"math/big"
 	"os"
@@ -217,3 +218,269 @@ func newTestRelayRequest(t *testing.T) (*RelayRequest, []byte) {
 
 	return req, reqBytes
 }
+
+func newTestSPVRelayContext(t *testing.T, params *BTCParams) (*Context, func()) {
+	db, dispose := leveldb.NewTestDatabase()
+	ctx := newTestContext(db, BTCRelayContractAddress)
+	ctx.BlockHeader.Height = common.BTCRelayForkHeight
+
+	oriParams := btcRelayParams
+	btcRelayParams = params
+
+	return ctx, func() {
+		btcRelayParams = oriParams
+		dispose()
+	}
+}
+
+// mineBTCHeader mines the regtest header on top of parent
+func mineBTCHeader(parent *BTCHeader, merkleRoot BTCHash) *BTCHeader {
+	header := &BTCHeader{
+		Version:    4,
+		PrevBlock:  parent.Hash(),
+		MerkleRoot: merkleRoot,
+		Timestamp:  parent.Timestamp + 600,
+		Bits:       parent.Bits,
+	}
+
+	target := BTCCompactToBig(header.Bits)
+	for header.Hash().Big().Cmp(target) > 0 {
+		header.Nonce++
+	}
+
+	return header
+}
+
+func mineBTCChain(parent *BTCHeader, count int) []*BTCHeader {
+	var headers []*BTCHeader
+	for i := 0; i < count; i++ {
+		parent = mineBTCHeader(parent, BTCDoubleHash([]byte{byte(i)}))
+		headers = append(headers, parent)
+	}
+
+	return headers
+}
+
+func storeTestBTCHeaders(ctx *Context, headers []*BTCHeader) ([]byte, error) {
+	var input []byte
+	for _, header := range headers {
+		input = append(input, header.Encode()...)
+	}
+
+	return storeBlockHeaders(input, ctx)
+}
+
+func Test_storeBlockHeader_MainNet(t *testing.T) {
+	ctx, dispose := newTestSPVRelayContext(t, &BTCMainNetParams)
+	defer dispose()
+
+	// the block 1 of bitcoin main network
+	block1, err := hex.DecodeString("010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299")
+	assert.NoError(t, err)
+
+	result, err := storeBlockHeader(block1, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, success, result)
+
+	var best BTCBlockHeader
+	result, err = getBestBlockHeader(nil, ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(result, &best))
+	assert.Equal(t, "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048", best.Hash.String())
+	assert.Equal(t, uint64(1), best.Height)
+	assert.Equal(t, ctx.tx.Data.From, best.Relayer)
+
+	// the proof of work is required
+	header, err := DecodeBTCHeader(block1)
+	assert.NoError(t, err)
+	header.PrevBlock = best.Hash
+	_, err = storeBlockHeader(header.Encode(), ctx)
+	assert.Equal(t, ErrBTCInvalidPoW, err)
+}
+
+func Test_storeBlockHeaders(t *testing.T) {
+	ctx, dispose := newTestSPVRelayContext(t, &BTCRegressionNetParams)
+	defer dispose()
+
+	genesis := BTCRegressionNetParams.checkpoint()
+	headers := mineBTCChain(genesis, 8)
+
+	result, err := storeTestBTCHeaders(ctx, headers)
+	assert.NoError(t, err)
+	assert.Equal(t, success, result)
+
+	best, err := getBTCBestHeader(ctx.statedb)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8), best.Height)
+	for i, header := range headers {
+		assert.Equal(t, header.Hash(), getBTCMainChainHash(ctx.statedb, uint64(i+1)))
+	}
+
+	// already exists
+	_, err = storeBlockHeader(headers[3].Encode(), ctx)
+	assert.Equal(t, ErrBTCHeaderExists, err)
+
+	// unknown parent
+	orphan := mineBTCHeader(mineBTCHeader(headers[7], BTCHash{}), BTCHash{})
+	_, err = storeBlockHeader(orphan.Encode(), ctx)
+	assert.Equal(t, ErrBTCUnknownBlock, err)
+
+	// invalid difficulty
+	invalid := mineBTCHeader(headers[7], BTCHash{})
+	invalid.Bits = 0x1f7fffff
+	_, err = storeBlockHeader(invalid.Encode(), ctx)
+	assert.Equal(t, ErrBTCInvalidDifficulty, err)
+
+	// invalid proof of work
+	invalid = mineBTCHeader(headers[7], BTCHash{})
+	for invalid.Hash().Big().Cmp(BTCCompactToBig(invalid.Bits)) <= 0 {
+		invalid.Nonce++
+	}
+	_, err = storeBlockHeader(invalid.Encode(), ctx)
+	assert.Equal(t, ErrBTCInvalidPoW, err)
+
+	// the timestamp is not after the median time past
+	stale := &BTCHeader{Version: 4, PrevBlock: headers[7].Hash(), Timestamp: headers[3].Timestamp, Bits: headers[7].Bits}
+	for stale.Hash().Big().Cmp(BTCCompactToBig(stale.Bits)) > 0 {
+		stale.Nonce++
+	}
+	_, err = storeBlockHeader(stale.Encode(), ctx)
+	assert.Equal(t, ErrBTCInvalidTimestamp, err)
+
+	// invalid batch
+	_, err = storeBlockHeaders(headers[0].Encode()[1:], ctx)
+	assert.Equal(t, ErrBTCInvalidHeader, err)
+
+	// not available before fork
+	ctx.BlockHeader.Height = common.BTCRelayForkHeight - 1
+	_, err = storeBlockHeaders(headers[0].Encode(), ctx)
+	assert.Equal(t, errInvalidCommand, err)
+}
+
+func Test_storeBlockHeader_Reorg(t *testing.T) {
+	ctx, dispose := newTestSPVRelayContext(t, &BTCRegressionNetParams)
+	defer dispose()
+
+	genesis := BTCRegressionNetParams.checkpoint()
+	headers := mineBTCChain(genesis, 5)
+	_, err := storeTestBTCHeaders(ctx, headers)
+	assert.NoError(t, err)
+
+	// the fork from height 2 with less work does not change the main chain
+	fork := mineBTCChain(headers[1], 3)
+	_, err = storeTestBTCHeaders(ctx, fork)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[4].Hash(), getBTCBestHash(ctx.statedb))
+
+	info, err := GetBTCBlockHeader(ctx.statedb, fork[2].Hash())
+	assert.NoError(t, err)
+	assert.False(t, info.MainChain)
+	assert.Equal(t, uint64(0), info.Confirmations)
+
+	// switch to the fork with most work
+	fork = append(fork, mineBTCHeader(fork[2], BTCHash{}))
+	_, err = storeBlockHeader(fork[3].Encode(), ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fork[3].Hash(), getBTCBestHash(ctx.statedb))
+	assert.Equal(t, headers[1].Hash(), getBTCMainChainHash(ctx.statedb, 2))
+	for i, header := range fork {
+		assert.Equal(t, header.Hash(), getBTCMainChainHash(ctx.statedb, uint64(i+3)))
+	}
+
+	info, err = GetBTCBlockHeader(ctx.statedb, headers[4].Hash())
+	assert.NoError(t, err)
+	assert.False(t, info.MainChain)
+
+	info, err = GetBTCBlockHeader(ctx.statedb, headers[1].Hash())
+	assert.NoError(t, err)
+	assert.True(t, info.MainChain)
+	assert.Equal(t, uint64(5), info.Confirmations)
+}
+
+func Test_verifyTxProof(t *testing.T) {
+	ctx, dispose := newTestSPVRelayContext(t, &BTCRegressionNetParams)
+	defer dispose()
+
+	relayer := ctx.tx.Data.From
+	rawTxs := [][]byte{[]byte("coinbase tx"), []byte("payment tx")}
+	txs := []BTCHash{BTCDoubleHash(rawTxs[0]), BTCDoubleHash(rawTxs[1])}
+	root, branches := newTestBTCMerkleTree(txs)
+
+	genesis := BTCRegressionNetParams.checkpoint()
+	block := mineBTCHeader(genesis, root)
+	headers := append([]*BTCHeader{block}, mineBTCChain(block, 4)...)
+	_, err := storeTestBTCHeaders(ctx, headers)
+	assert.NoError(t, err)
+
+	proof := BTCTxProof{
+		BlockHash: block.Hash(),
+		TxHex:     hexutil.BytesToHex(rawTxs[1]),
+		Index:     1,
+		Branch:    branches[1],
+	}
+	request, err := json.Marshal(proof)
+	assert.NoError(t, err)
+
+	// not enough confirmations
+	ctx.tx.Data.From = *crypto.MustGenerateShardAddress(1)
+	ctx.statedb.AddBalance(BTCRelayContractAddress, ctx.tx.Data.Amount)
+	_, err = verifyTx(request, ctx)
+	assert.Equal(t, ErrBTCNotConfirmed, err)
+
+	_, err = storeTestBTCHeaders(ctx, mineBTCChain(headers[4], 1))
+	assert.NoError(t, err)
+
+	result, err := verifyTx(request, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, success, result)
+	assert.Equal(t, ctx.tx.Data.Amount, ctx.statedb.GetBalance(relayer))
+
+	// more confirmations required by the request
+	proof.Confirmations = 7
+	request, _ = json.Marshal(proof)
+	_, err = verifyTx(request, ctx)
+	assert.Equal(t, ErrBTCNotConfirmed, err)
+	proof.Confirmations = 0
+
+	// wrong position
+	proof.Index = 0
+	request, _ = json.Marshal(proof)
+	result, err = relayTx(request, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, failure, result)
+
+	// unknown block
+	proof.BlockHash = BTCDoubleHash([]byte("unknown"))
+	request, _ = json.Marshal(proof)
+	_, err = verifyTx(request, ctx)
+	assert.Equal(t, ErrBTCUnknownBlock, err)
+
+	// the 64 bytes tx is rejected
+	proof.TxHex = hexutil.BytesToHex(append(txs[0][:], txs[1][:]...))
+	request, _ = json.Marshal(proof)
+	_, err = verifyTx(request, ctx)
+	assert.Equal(t, ErrBTCInvalidTx, err)
+
+	// fork block
+	fork := mineBTCHeader(genesis, BTCHash{1})
+	_, err = storeBlockHeader(fork.Encode(), ctx)
+	assert.NoError(t, err)
+	proof.BlockHash, proof.TxHex = fork.Hash(), hexutil.BytesToHex(rawTxs[1])
+	request, _ = json.Marshal(proof)
+	_, err = verifyTx(request, ctx)
+	assert.Equal(t, ErrBTCNotMainChain, err)
+
+	// query header
+	blockHash := block.Hash().reverse()
+	result, err = getBlockHeader(blockHash[:], ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, success, result)
+
+	result, err = getBlockHeaderInfo(blockHash[:], ctx)
+	assert.NoError(t, err)
+	var info BTCBlockHeader
+	assert.NoError(t, json.Unmarshal(result, &info))
+	assert.Equal(t, block.Hash(), info.Hash)
+	assert.Equal(t, uint64(1), info.Height)
+	assert.Equal(t, uint64(6), info.Confirmations)
+}
@@ -0,0 +1,685 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+	bp "github.com/scdoproject/go-stem/bulletinproof"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/ecies"
+)
+
+// After ConfidentialTransferForkHeight, the accounts could opt in the confidential transfers,
+// whose amounts are hidden in the Pedersen commitments v*G + r*H of bulletproofs. The balance
+// of confidential account is kept as a commitment in the confidential contract, and each
+// transfer carries the range proofs that neither the transferred amount nor the remaining
+// balance of sender is negative, so that no value is created from the hidden amounts.
+//
+// The openings (amount and blinding factor) of commitments are encrypted to the public key
+// of the owner in the notes. The incoming transfers are pending until the recipient applies
+// them to its balance, and the pending transfers that could not be opened are discarded,
+// so that no one could freeze the balance of others with an invalid note.
+//
+// Only the deposits and withdrawals are public, which move the tx amount in and out of the
+// contract.
+
+const (
+	// CmdConfidentialCreate create the confidential account of sender with its public key
+	CmdConfidentialCreate byte = iota
+	// CmdConfidentialDeposit deposit the tx amount into the confidential balance of sender
+	CmdConfidentialDeposit
+	// CmdConfidentialTransfer transfer the hidden amount to the pending transfers of recipient
+	CmdConfidentialTransfer
+	// CmdConfidentialWithdraw withdraw the amount from the confidential balance of sender
+	CmdConfidentialWithdraw
+	// CmdConfidentialApply apply the pending transfers to the confidential balance of sender
+	CmdConfidentialApply
+	// CmdConfidentialGet get the confidential account of address
+	CmdConfidentialGet
+
+	gasConfidentialProof          = uint64(1000000) // gas used to verify a range proof
+	gasCmdConfidentialCreate      = uint64(50000)   // gas used to create confidential account
+	gasCmdConfidentialDeposit     = uint64(50000)   // gas used to deposit
+	gasCmdConfidentialTransfer    = 2*gasConfidentialProof + 50000
+	gasCmdConfidentialWithdraw    = gasConfidentialProof + 50000
+	gasCmdConfidentialApply       = uint64(100000) // gas used to apply pending transfers
+	gasCmdConfidentialGet         = uint64(5000)   // gas used to get confidential account
+	confidentialNotePlaintextSize = 8 + bp.ScalarSize
+)
+
+var (
+	// ConfidentialMaxPending is the max number of pending transfers of confidential account
+	ConfidentialMaxPending = 64
+	// ConfidentialMaxNoteSize is the max size of encrypted note
+	ConfidentialMaxNoteSize = 256
+)
+
+var (
+	ErrConfidentialNoAccount    = errors.New("no confidential account of this address")
+	ErrConfidentialInvalidKey   = errors.New("public key does not match the sender address")
+	ErrConfidentialAmount       = errors.New("invalid amount of confidential command")
+	ErrConfidentialInvalidNote  = errors.New("invalid note of confidential commitment")
+	ErrConfidentialInvalidProof = errors.New("invalid range proof of confidential commitment")
+	ErrConfidentialBalance      = errors.New("range proof does not commit to the remaining balance")
+	ErrConfidentialPendingFull  = errors.New("too many pending transfers of recipient")
+	ErrConfidentialIndexes      = errors.New("pending transfer indexes should be increasing and in range")
+	ErrConfidentialInsufficient = errors.New("insufficient confidential balance")
+
+	confidentialCommands = map[byte]*cmdInfo{
+		CmdConfidentialCreate:   {gasCmdConfidentialCreate, createConfidentialCmd},
+		CmdConfidentialDeposit:  {gasCmdConfidentialDeposit, depositConfidentialCmd},
+		CmdConfidentialTransfer: {gasCmdConfidentialTransfer, transferConfidentialCmd},
+		CmdConfidentialWithdraw: {gasCmdConfidentialWithdraw, withdrawConfidentialCmd},
+		CmdConfidentialApply:    {gasCmdConfidentialApply, applyConfidentialCmd},
+		CmdConfidentialGet:      {gasCmdConfidentialGet, getConfidentialCmd},
+	}
+)
+
+// ConfidentialAccount is the confidential account in the confidential contract
+type ConfidentialAccount struct {
+	PublicKey []byte              // public key of owner to encrypt the notes
+	Balance   []byte              // commitment of balance, empty for zero balance
+	Note      []byte              // opening of balance encrypted to owner
+	Pending   []*ConfidentialNote // incoming transfers that are not applied to balance
+}
+
+// ConfidentialNote is the commitment of amount with its opening encrypted to owner
+type ConfidentialNote struct {
+	Commitment []byte
+	Note       []byte
+}
+
+// ConfidentialTransfer is the input of confidential transfer command
+type ConfidentialTransfer struct {
+	To            common.Address
+	AmountProof   []byte // range proof of the transferred amount
+	BalanceProof  []byte // range proof of the remaining balance of sender
+	RecipientNote []byte // opening of amount encrypted to recipient
+	SenderNote    []byte // opening of remaining balance encrypted to sender
+}
+
+// ConfidentialWithdraw is the input of confidential withdraw command
+type ConfidentialWithdraw struct {
+	Amount       *big.Int
+	BalanceProof []byte // range proof of the remaining balance of sender
+	Note         []byte // opening of remaining balance encrypted to sender
+}
+
+// ConfidentialApply is the input of confidential apply command
+type ConfidentialApply struct {
+	Indexes []uint64 // increasing indexes of the pending transfers to apply, and the others are discarded
+	Note    []byte   // opening of the new balance encrypted to sender
+}
+
+// createConfidentialCmd creates the confidential account of sender, the input is the 65 bytes
+// uncompressed public key of sender.
+func createConfidentialCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.confidentialEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	if context.tx.Data.Amount.Sign() != 0 {
+		return nil, ErrConfidentialAmount
+	}
+
+	owner := context.tx.Data.From
+	pub := crypto.ToECDSAPub(input)
+	if len(input) != 65 || pub == nil || pub.X == nil || !crypto.GetAddress(pub).Equal(owner) {
+		return nil, ErrConfidentialInvalidKey
+	}
+
+	account, err := getConfidentialAccount(owner, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if account != nil {
+		return nil, errExists
+	}
+
+	account = &ConfidentialAccount{PublicKey: common.CopyBytes(input)}
+	if err = saveConfidentialAccount(owner, context.statedb, account); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("ConfidentialAccountCreated", owner, account.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// depositConfidentialCmd adds the tx amount to the confidential balance of sender, the input
+// is the opening of new balance encrypted to sender.
+func depositConfidentialCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.confidentialEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	amount := context.tx.Data.Amount
+	if amount.Sign() <= 0 || !amount.IsUint64() {
+		return nil, ErrConfidentialAmount
+	}
+
+	if len(input) == 0 || !validConfidentialNote(input) {
+		return nil, ErrConfidentialInvalidNote
+	}
+
+	owner := context.tx.Data.From
+	account, err := mustGetConfidentialAccount(owner, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := account.balance()
+	if err != nil {
+		return nil, err
+	}
+
+	account.setBalance(balance.Add(bp.EC.G.Mult(amount)), input)
+	if err = saveConfidentialAccount(owner, context.statedb, account); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("ConfidentialDeposited", owner, amount); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// transferConfidentialCmd transfers the hidden amount from the balance of sender to the
+// pending transfers of recipient, the input is the JSON encoded ConfidentialTransfer.
+func transferConfidentialCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.confidentialEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	if context.tx.Data.Amount.Sign() != 0 {
+		return nil, ErrConfidentialAmount
+	}
+
+	var transfer ConfidentialTransfer
+	if err := json.Unmarshal(input, &transfer); err != nil {
+		return nil, err
+	}
+
+	if len(transfer.RecipientNote) == 0 || !validConfidentialNote(transfer.RecipientNote) || !validConfidentialNote(transfer.SenderNote) {
+		return nil, ErrConfidentialInvalidNote
+	}
+
+	owner := context.tx.Data.From
+	account, err := mustGetConfidentialAccount(owner, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient, err := mustGetConfidentialAccount(transfer.To, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recipient.Pending) >= ConfidentialMaxPending {
+		return nil, ErrConfidentialPendingFull
+	}
+
+	amountProof, err := bp.RangeProofFromBytes(transfer.AmountProof)
+	if err != nil {
+		return nil, errors.Wrap(ErrConfidentialInvalidProof, err.Error())
+	}
+
+	remaining, err := account.verifyRemaining(amountProof.Comm, transfer.BalanceProof)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bp.RPVerify(amountProof) {
+		return nil, ErrConfidentialInvalidProof
+	}
+
+	account.setBalance(remaining, transfer.SenderNote)
+	if err = saveConfidentialAccount(owner, context.statedb, account); err != nil {
+		return nil, err
+	}
+
+	// reload the recipient, which may be the sender
+	if recipient, err = mustGetConfidentialAccount(transfer.To, context.statedb); err != nil {
+		return nil, err
+	}
+
+	commitment := amountProof.Comm.Bytes()
+	recipient.Pending = append(recipient.Pending, &ConfidentialNote{commitment, transfer.RecipientNote})
+	if err = saveConfidentialAccount(transfer.To, context.statedb, recipient); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("ConfidentialTransferred", owner, transfer.To, commitment); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// withdrawConfidentialCmd pays the amount from the confidential balance of sender, the input
+// is the JSON encoded ConfidentialWithdraw.
+func withdrawConfidentialCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.confidentialEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	if context.tx.Data.Amount.Sign() != 0 {
+		return nil, ErrConfidentialAmount
+	}
+
+	var withdraw ConfidentialWithdraw
+	if err := json.Unmarshal(input, &withdraw); err != nil {
+		return nil, err
+	}
+
+	amount := withdraw.Amount
+	if amount == nil || amount.Sign() <= 0 || !amount.IsUint64() {
+		return nil, ErrConfidentialAmount
+	}
+
+	if !validConfidentialNote(withdraw.Note) {
+		return nil, ErrConfidentialInvalidNote
+	}
+
+	owner := context.tx.Data.From
+	account, err := mustGetConfidentialAccount(owner, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := account.verifyRemaining(bp.EC.G.Mult(amount), withdraw.BalanceProof)
+	if err != nil {
+		return nil, err
+	}
+
+	account.setBalance(remaining, withdraw.Note)
+	if err = saveConfidentialAccount(owner, context.statedb, account); err != nil {
+		return nil, err
+	}
+
+	context.statedb.SubBalance(ConfidentialContractAddress, amount)
+	context.statedb.AddBalance(owner, amount)
+
+	if err = context.emit("ConfidentialWithdrawn", owner, amount); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// applyConfidentialCmd adds the selected pending transfers to the confidential balance of
+// sender and discards the others, the input is the JSON encoded ConfidentialApply.
+func applyConfidentialCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.confidentialEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	if context.tx.Data.Amount.Sign() != 0 {
+		return nil, ErrConfidentialAmount
+	}
+
+	var apply ConfidentialApply
+	if err := json.Unmarshal(input, &apply); err != nil {
+		return nil, err
+	}
+
+	owner := context.tx.Data.From
+	account, err := mustGetConfidentialAccount(owner, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := account.balance()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, index := range apply.Indexes {
+		if index >= uint64(len(account.Pending)) || (i > 0 && index <= apply.Indexes[i-1]) {
+			return nil, ErrConfidentialIndexes
+		}
+
+		commitment, err := bp.ECPointFromBytes(account.Pending[index].Commitment)
+		if err != nil {
+			return nil, err
+		}
+
+		balance = balance.Add(commitment)
+	}
+
+	note := account.Note
+	if len(apply.Indexes) > 0 {
+		note = apply.Note
+	}
+
+	if !validConfidentialNote(note) {
+		return nil, ErrConfidentialInvalidNote
+	}
+
+	account.setBalance(balance, note)
+	account.Pending = nil
+	if err = saveConfidentialAccount(owner, context.statedb, account); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("ConfidentialApplied", owner, uint64(len(apply.Indexes))); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// getConfidentialCmd returns the confidential account of address in JSON
+func getConfidentialCmd(address []byte, context *Context) ([]byte, error) {
+	if !context.confidentialEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	account, err := mustGetConfidentialAccount(common.BytesToAddress(address), context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(account)
+}
+
+// balance returns the commitment of balance, which is the point at infinity for zero balance
+func (account *ConfidentialAccount) balance() (bp.ECPoint, error) {
+	if len(account.Balance) == 0 {
+		return bp.EC.Zero(), nil
+	}
+
+	return bp.ECPointFromBytes(account.Balance)
+}
+
+func (account *ConfidentialAccount) setBalance(balance bp.ECPoint, note []byte) {
+	account.Balance, account.Note = nil, nil
+	if !balance.IsZero() {
+		account.Balance, account.Note = balance.Bytes(), common.CopyBytes(note)
+	}
+}
+
+// verifyRemaining verifies the range proof of the remaining balance after the amount of
+// commitment is paid, and returns the commitment of remaining balance.
+func (account *ConfidentialAccount) verifyRemaining(amount bp.ECPoint, proof []byte) (bp.ECPoint, error) {
+	balance, err := account.balance()
+	if err != nil {
+		return bp.ECPoint{}, err
+	}
+
+	remaining := balance.Add(amount.Neg())
+
+	// no proof is required for zero balance, e.g. all the deposits are withdrawn
+	if remaining.IsZero() && len(proof) == 0 {
+		return remaining, nil
+	}
+
+	balanceProof, err := bp.RangeProofFromBytes(proof)
+	if err != nil {
+		return bp.ECPoint{}, errors.Wrap(ErrConfidentialInvalidProof, err.Error())
+	}
+
+	if !remaining.Equal(balanceProof.Comm) {
+		return bp.ECPoint{}, ErrConfidentialBalance
+	}
+
+	if !bp.RPVerify(balanceProof) {
+		return bp.ECPoint{}, ErrConfidentialInvalidProof
+	}
+
+	return remaining, nil
+}
+
+func validConfidentialNote(note []byte) bool {
+	return len(note) <= ConfidentialMaxNoteSize
+}
+
+// GetConfidentialAccount returns the confidential account of address, or nil if not created.
+func GetConfidentialAccount(address common.Address, statedb *state.Statedb) (*ConfidentialAccount, error) {
+	return getConfidentialAccount(address, statedb)
+}
+
+func getConfidentialAccount(address common.Address, statedb *state.Statedb) (*ConfidentialAccount, error) {
+	value := statedb.GetData(ConfidentialContractAddress, crypto.MustHash(address))
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var account ConfidentialAccount
+	if err := common.Deserialize(value, &account); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func mustGetConfidentialAccount(address common.Address, statedb *state.Statedb) (*ConfidentialAccount, error) {
+	account, err := getConfidentialAccount(address, statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return nil, ErrConfidentialNoAccount
+	}
+
+	return account, nil
+}
+
+func saveConfidentialAccount(address common.Address, statedb *state.Statedb, account *ConfidentialAccount) error {
+	value, err := common.Serialize(account)
+	if err != nil {
+		return err
+	}
+
+	statedb.CreateAccount(ConfidentialContractAddress)
+	statedb.SetData(ConfidentialContractAddress, crypto.MustHash(address), value)
+	return nil
+}
+
+// confidentialEnabled returns whether the confidential contract is enabled after fork
+func (ctx *Context) confidentialEnabled() bool {
+	return ctx.BlockHeader.Height >= common.ConfidentialTransferForkHeight
+}
+
+// ConfidentialOpening is the amount and blinding factor of a commitment
+type ConfidentialOpening struct {
+	Amount   uint64
+	Blinding *big.Int
+}
+
+// Commitment returns the Pedersen commitment of the opening
+func (o *ConfidentialOpening) Commitment() bp.ECPoint {
+	return bp.EC.G.Mult(new(big.Int).SetUint64(o.Amount)).Add(bp.EC.H.Mult(o.Blinding))
+}
+
+// EncryptConfidentialNote encrypts the opening to the public key
+func EncryptConfidentialNote(pub *ecdsa.PublicKey, opening *ConfidentialOpening) ([]byte, error) {
+	plaintext := make([]byte, confidentialNotePlaintextSize)
+	binary.BigEndian.PutUint64(plaintext, opening.Amount)
+	new(big.Int).Mod(opening.Blinding, bp.EC.N).FillBytes(plaintext[8:])
+
+	return ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), plaintext, nil, nil)
+}
+
+// DecryptConfidentialNote decrypts the note with the private key of owner, and checks the
+// opening matches the commitment.
+func DecryptConfidentialNote(key *ecdsa.PrivateKey, note, commitment []byte) (*ConfidentialOpening, error) {
+	plaintext, err := ecies.ImportECDSA(key).Decrypt(rand.Reader, note, nil, nil)
+	if err != nil || len(plaintext) != confidentialNotePlaintextSize {
+		return nil, ErrConfidentialInvalidNote
+	}
+
+	opening := &ConfidentialOpening{
+		Amount:   binary.BigEndian.Uint64(plaintext),
+		Blinding: new(big.Int).SetBytes(plaintext[8:]),
+	}
+
+	c, err := bp.ECPointFromBytes(commitment)
+	if err != nil || !opening.Commitment().Equal(c) {
+		return nil, ErrConfidentialInvalidNote
+	}
+
+	return opening, nil
+}
+
+// OpenedConfidentialAccount is the confidential account opened by the owner
+type OpenedConfidentialAccount struct {
+	Balance *ConfidentialOpening
+	Pending []*ConfidentialOpening // nil for the pending transfers that could not be opened
+}
+
+// OpenConfidentialAccount opens the balance and pending transfers of the confidential account
+// with the private key of owner.
+func OpenConfidentialAccount(key *ecdsa.PrivateKey, account *ConfidentialAccount) (*OpenedConfidentialAccount, error) {
+	opened := &OpenedConfidentialAccount{
+		Balance: &ConfidentialOpening{Amount: 0, Blinding: big.NewInt(0)},
+		Pending: make([]*ConfidentialOpening, len(account.Pending)),
+	}
+
+	if len(account.Balance) > 0 {
+		balance, err := DecryptConfidentialNote(key, account.Note, account.Balance)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open balance")
+		}
+
+		opened.Balance = balance
+	}
+
+	for i, pending := range account.Pending {
+		opened.Pending[i], _ = DecryptConfidentialNote(key, pending.Note, pending.Commitment)
+	}
+
+	return opened, nil
+}
+
+// NewConfidentialDeposit returns the note of balance after the amount is deposited
+func NewConfidentialDeposit(key *ecdsa.PrivateKey, account *ConfidentialAccount, amount uint64) ([]byte, error) {
+	opened, err := OpenConfidentialAccount(key, account)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := opened.Balance.Amount + amount
+	if balance < amount {
+		return nil, ErrConfidentialAmount
+	}
+
+	return EncryptConfidentialNote(&key.PublicKey, &ConfidentialOpening{balance, opened.Balance.Blinding})
+}
+
+// NewConfidentialTransfer creates the confidential transfer of amount to the recipient account
+func NewConfidentialTransfer(key *ecdsa.PrivateKey, account *ConfidentialAccount, to common.Address, recipient *ConfidentialAccount, amount uint64) (*ConfidentialTransfer, error) {
+	blinding, err := rand.Int(rand.Reader, bp.EC.N)
+	if err != nil {
+		return nil, err
+	}
+
+	transferred := &ConfidentialOpening{amount, blinding}
+	balanceProof, senderNote, err := newConfidentialRemaining(key, account, transferred)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientNote, err := EncryptConfidentialNote(crypto.ToECDSAPub(recipient.PublicKey), transferred)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfidentialTransfer{
+		To:            to,
+		AmountProof:   bp.RPProveWithGamma(new(big.Int).SetUint64(amount), blinding).Bytes(),
+		BalanceProof:  balanceProof,
+		RecipientNote: recipientNote,
+		SenderNote:    senderNote,
+	}, nil
+}
+
+// NewConfidentialWithdraw creates the confidential withdraw of amount
+func NewConfidentialWithdraw(key *ecdsa.PrivateKey, account *ConfidentialAccount, amount uint64) (*ConfidentialWithdraw, error) {
+	balanceProof, note, err := newConfidentialRemaining(key, account, &ConfidentialOpening{amount, big.NewInt(0)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfidentialWithdraw{
+		Amount:       new(big.Int).SetUint64(amount),
+		BalanceProof: balanceProof,
+		Note:         note,
+	}, nil
+}
+
+// NewConfidentialApply applies all pending transfers that could be opened by the owner
+func NewConfidentialApply(key *ecdsa.PrivateKey, account *ConfidentialAccount) (*ConfidentialApply, error) {
+	opened, err := OpenConfidentialAccount(key, account)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := opened.Balance
+	apply := &ConfidentialApply{}
+	for i, pending := range opened.Pending {
+		if pending == nil {
+			continue
+		}
+
+		amount := balance.Amount + pending.Amount
+		if amount < pending.Amount {
+			return nil, ErrConfidentialAmount
+		}
+
+		blinding := new(big.Int).Add(balance.Blinding, pending.Blinding)
+		balance = &ConfidentialOpening{amount, blinding.Mod(blinding, bp.EC.N)}
+		apply.Indexes = append(apply.Indexes, uint64(i))
+	}
+
+	if len(apply.Indexes) > 0 {
+		if apply.Note, err = EncryptConfidentialNote(&key.PublicKey, balance); err != nil {
+			return nil, err
+		}
+	}
+
+	return apply, nil
+}
+
+// newConfidentialRemaining returns the range proof and note of the remaining balance after
+// the opening is paid, which are empty for zero balance.
+func newConfidentialRemaining(key *ecdsa.PrivateKey, account *ConfidentialAccount, paid *ConfidentialOpening) ([]byte, []byte, error) {
+	opened, err := OpenConfidentialAccount(key, account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opened.Balance.Amount < paid.Amount {
+		return nil, nil, ErrConfidentialInsufficient
+	}
+
+	blinding := new(big.Int).Sub(opened.Balance.Blinding, paid.Blinding)
+	remaining := &ConfidentialOpening{opened.Balance.Amount - paid.Amount, blinding.Mod(blinding, bp.EC.N)}
+	if remaining.Commitment().IsZero() {
+		return nil, nil, nil
+	}
+
+	note, err := EncryptConfidentialNote(&key.PublicKey, remaining)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof := bp.RPProveWithGamma(new(big.Int).SetUint64(remaining.Amount), remaining.Blinding)
+	return proof.Bytes(), note, nil
+}
@@ -0,0 +1,276 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	bp "github.com/scdoproject/go-stem/bulletinproof"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfidentialContext(db database.Database) *Context {
+	context := newTestContext(db, ConfidentialContractAddress)
+	context.BlockHeader.Height = common.ConfidentialTransferForkHeight
+
+	return context
+}
+
+// runConfidentialCmd runs the command sent by the address with the tx amount
+func runConfidentialCmd(context *Context, from common.Address, amount uint64, cmd byte, input interface{}) ([]byte, error) {
+	context.tx.Data.From = from
+	context.tx.Data.Amount = new(big.Int).SetUint64(amount)
+	context.statedb.AddBalance(ConfidentialContractAddress, context.tx.Data.Amount)
+
+	payload, ok := input.([]byte)
+	if !ok {
+		payload, _ = json.Marshal(input)
+	}
+
+	return GetContractByAddress(ConfidentialContractAddress, common.ConfidentialTransferForkHeight).Run(append([]byte{cmd}, payload...), context)
+}
+
+func createConfidentialAccount(t *testing.T, context *Context) (common.Address, *ecdsa.PrivateKey) {
+	addr, key := crypto.MustGenerateShardKeyPair(1)
+	context.statedb.CreateAccount(*addr)
+	_, err := runConfidentialCmd(context, *addr, 0, CmdConfidentialCreate, crypto.FromECDSAPub(&key.PublicKey))
+	assert.NoError(t, err)
+
+	return *addr, key
+}
+
+func mustOpenConfidentialAccount(t *testing.T, context *Context, addr common.Address, key *ecdsa.PrivateKey) (*ConfidentialAccount, *OpenedConfidentialAccount) {
+	account, err := GetConfidentialAccount(addr, context.statedb)
+	assert.NoError(t, err)
+
+	opened, err := OpenConfidentialAccount(key, account)
+	assert.NoError(t, err)
+
+	return account, opened
+}
+
+func Test_Confidential_BeforeFork(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newConfidentialContext(db)
+	context.BlockHeader.Height = common.ConfidentialTransferForkHeight - 1
+
+	addr, key := crypto.MustGenerateShardKeyPair(1)
+	_, err := runConfidentialCmd(context, *addr, 0, CmdConfidentialCreate, crypto.FromECDSAPub(&key.PublicKey))
+	assert.Equal(t, errInvalidCommand, err)
+}
+
+func Test_Confidential_Create(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newConfidentialContext(db)
+
+	// key of another address
+	addr := *crypto.MustGenerateShardAddress(1)
+	_, key := crypto.MustGenerateShardKeyPair(1)
+	_, err := runConfidentialCmd(context, addr, 0, CmdConfidentialCreate, crypto.FromECDSAPub(&key.PublicKey))
+	assert.Equal(t, ErrConfidentialInvalidKey, err)
+
+	// no account
+	_, err = runConfidentialCmd(context, addr, 0, CmdConfidentialGet, addr.Bytes())
+	assert.Equal(t, ErrConfidentialNoAccount, err)
+
+	owner, key := createConfidentialAccount(t, context)
+	_, err = runConfidentialCmd(context, owner, 0, CmdConfidentialCreate, crypto.FromECDSAPub(&key.PublicKey))
+	assert.Equal(t, errExists, err)
+
+	result, err := runConfidentialCmd(context, owner, 0, CmdConfidentialGet, owner.Bytes())
+	assert.NoError(t, err)
+
+	var account ConfidentialAccount
+	assert.NoError(t, json.Unmarshal(result, &account))
+	assert.Equal(t, crypto.FromECDSAPub(&key.PublicKey), account.PublicKey)
+	assert.Empty(t, account.Balance)
+	assert.Empty(t, account.Pending)
+}
+
+func Test_Confidential_TransferAndWithdraw(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newConfidentialContext(db)
+	alice, aliceKey := createConfidentialAccount(t, context)
+	bob, bobKey := createConfidentialAccount(t, context)
+
+	// deposit 100 to alice
+	account, _ := mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	note, err := NewConfidentialDeposit(aliceKey, account, 100)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, alice, 100, CmdConfidentialDeposit, note)
+	assert.NoError(t, err)
+
+	account, opened := mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	assert.Equal(t, uint64(100), opened.Balance.Amount)
+
+	// transfer more than balance
+	recipient, _ := GetConfidentialAccount(bob, context.statedb)
+	_, err = NewConfidentialTransfer(aliceKey, account, bob, recipient, 101)
+	assert.Equal(t, ErrConfidentialInsufficient, err)
+
+	// transfer 30 to bob, which is pending
+	transfer, err := NewConfidentialTransfer(aliceKey, account, bob, recipient, 30)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, alice, 0, CmdConfidentialTransfer, transfer)
+	assert.NoError(t, err)
+
+	_, opened = mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	assert.Equal(t, uint64(70), opened.Balance.Amount)
+
+	account, opened = mustOpenConfidentialAccount(t, context, bob, bobKey)
+	assert.Equal(t, uint64(0), opened.Balance.Amount)
+	assert.Equal(t, 1, len(opened.Pending))
+	assert.Equal(t, uint64(30), opened.Pending[0].Amount)
+
+	// the replayed transfer does not commit to the remaining balance
+	_, err = runConfidentialCmd(context, alice, 0, CmdConfidentialTransfer, transfer)
+	assert.Equal(t, ErrConfidentialBalance, err)
+
+	// apply the pending transfer of bob
+	apply, err := NewConfidentialApply(bobKey, account)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, bob, 0, CmdConfidentialApply, apply)
+	assert.NoError(t, err)
+
+	account, opened = mustOpenConfidentialAccount(t, context, bob, bobKey)
+	assert.Equal(t, uint64(30), opened.Balance.Amount)
+	assert.Empty(t, opened.Pending)
+
+	// withdraw 10 from bob
+	withdraw, err := NewConfidentialWithdraw(bobKey, account, 10)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, bob, 0, CmdConfidentialWithdraw, withdraw)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), context.statedb.GetBalance(bob))
+
+	_, opened = mustOpenConfidentialAccount(t, context, bob, bobKey)
+	assert.Equal(t, uint64(20), opened.Balance.Amount)
+}
+
+func Test_Confidential_InvalidProof(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newConfidentialContext(db)
+	alice, aliceKey := createConfidentialAccount(t, context)
+	bob, _ := createConfidentialAccount(t, context)
+
+	account, _ := mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	note, err := NewConfidentialDeposit(aliceKey, account, 100)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, alice, 100, CmdConfidentialDeposit, note)
+	assert.NoError(t, err)
+
+	account, _ = mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	recipient, _ := GetConfidentialAccount(bob, context.statedb)
+	transfer, err := NewConfidentialTransfer(aliceKey, account, bob, recipient, 30)
+	assert.NoError(t, err)
+
+	// truncated proof
+	invalid := *transfer
+	invalid.AmountProof = transfer.AmountProof[:len(transfer.AmountProof)-1]
+	_, err = runConfidentialCmd(context, alice, 0, CmdConfidentialTransfer, &invalid)
+	assert.Equal(t, ErrConfidentialInvalidProof, errors.Cause(err))
+
+	// tampered proof
+	invalid.AmountProof = common.CopyBytes(transfer.AmountProof)
+	invalid.AmountProof[len(invalid.AmountProof)-1] ^= 1
+	_, err = runConfidentialCmd(context, alice, 0, CmdConfidentialTransfer, &invalid)
+	assert.Equal(t, ErrConfidentialInvalidProof, err)
+
+	// the amount is not paid by the remaining balance
+	withdraw, err := NewConfidentialWithdraw(aliceKey, account, 30)
+	assert.NoError(t, err)
+	withdraw.Amount = big.NewInt(50)
+	_, err = runConfidentialCmd(context, alice, 0, CmdConfidentialWithdraw, withdraw)
+	assert.Equal(t, ErrConfidentialBalance, err)
+
+	_, opened := mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	assert.Equal(t, uint64(100), opened.Balance.Amount)
+}
+
+func Test_Confidential_WithdrawAll(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newConfidentialContext(db)
+	alice, aliceKey := createConfidentialAccount(t, context)
+
+	account, _ := mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	note, err := NewConfidentialDeposit(aliceKey, account, 100)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, alice, 100, CmdConfidentialDeposit, note)
+	assert.NoError(t, err)
+
+	// the deposits have zero blinding, and no proof is required to withdraw all
+	account, _ = mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	withdraw, err := NewConfidentialWithdraw(aliceKey, account, 100)
+	assert.NoError(t, err)
+	assert.Empty(t, withdraw.BalanceProof)
+
+	_, err = runConfidentialCmd(context, alice, 0, CmdConfidentialWithdraw, withdraw)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), context.statedb.GetBalance(alice))
+
+	account, _ = mustOpenConfidentialAccount(t, context, alice, aliceKey)
+	assert.Empty(t, account.Balance)
+	assert.Empty(t, account.Note)
+}
+
+func Test_Confidential_Pending(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newConfidentialContext(db)
+	bob, bobKey := createConfidentialAccount(t, context)
+
+	// the pending transfers with invalid notes are discarded when applied
+	account, _ := GetConfidentialAccount(bob, context.statedb)
+	valid, _ := EncryptConfidentialNote(&bobKey.PublicKey, &ConfidentialOpening{5, big.NewInt(7)})
+	account.Pending = []*ConfidentialNote{
+		{(&ConfidentialOpening{9, big.NewInt(1)}).Commitment().Bytes(), []byte("invalid")},
+		{(&ConfidentialOpening{5, big.NewInt(7)}).Commitment().Bytes(), valid},
+	}
+	assert.NoError(t, saveConfidentialAccount(bob, context.statedb, account))
+
+	_, err := runConfidentialCmd(context, bob, 0, CmdConfidentialApply, &ConfidentialApply{Indexes: []uint64{1, 1}})
+	assert.Equal(t, ErrConfidentialIndexes, err)
+
+	apply, err := NewConfidentialApply(bobKey, account)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{1}, apply.Indexes)
+	_, err = runConfidentialCmd(context, bob, 0, CmdConfidentialApply, apply)
+	assert.NoError(t, err)
+
+	account, opened := mustOpenConfidentialAccount(t, context, bob, bobKey)
+	assert.Equal(t, uint64(5), opened.Balance.Amount)
+	assert.Empty(t, account.Pending)
+
+	// too many pending transfers
+	for i := 0; i < ConfidentialMaxPending; i++ {
+		account.Pending = append(account.Pending, &ConfidentialNote{bp.EC.G.Bytes(), valid})
+	}
+	assert.NoError(t, saveConfidentialAccount(bob, context.statedb, account))
+
+	transfer, err := NewConfidentialTransfer(bobKey, account, bob, account, 1)
+	assert.NoError(t, err)
+	_, err = runConfidentialCmd(context, bob, 0, CmdConfidentialTransfer, transfer)
+	assert.Equal(t, ErrConfidentialPendingFull, err)
+}
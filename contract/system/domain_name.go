@@ -6,11 +6,16 @@
 package system
 
 import (
+	"encoding/binary"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"regexp"
 	"strings"
 
 	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
 )
 
 const (
@@ -18,6 +23,26 @@ const (
 	CmdCreateDomainName byte = iota
 	// CmdGetDomainNameOwner query the registrar of specified domain name
 	CmdGetDomainNameOwner
+	// CmdTransferDomainName transfer the domain name to a new owner
+	CmdTransferDomainName
+	// CmdRenewDomainName extend the registration period of domain name
+	CmdRenewDomainName
+	// CmdSetSubdomainOwner create a subdomain or change its owner by the owner of parent domain
+	CmdSetSubdomainOwner
+	// CmdSetDomainAddress set the address that the domain name resolves to in the shard of address
+	CmdSetDomainAddress
+	// CmdSetDomainABIHash set the contract ABI hash of domain name
+	CmdSetDomainABIHash
+	// CmdSetDomainText set the text record of domain name
+	CmdSetDomainText
+	// CmdSetReverseName set the domain name that the sender address reversely resolves to
+	CmdSetReverseName
+	// CmdResolveDomainName resolve the domain name to address
+	CmdResolveDomainName
+	// CmdReverseResolve resolve the address to domain name
+	CmdReverseResolve
+	// CmdGetDomainRecord query the registration and resolver records of domain name
+	CmdGetDomainRecord
 )
 
 const (
@@ -25,21 +50,135 @@ const (
 	gasCreateDomainName = uint64(50000)
 	// gas used to get the owner of given domain
 	gasGetDomainNameOwner = uint64(100000)
+	// gas used to transfer a domain name
+	gasTransferDomainName = uint64(50000)
+	// gas used to renew a domain name
+	gasRenewDomainName = uint64(50000)
+	// gas used to set the owner of subdomain
+	gasSetSubdomainOwner = uint64(50000)
+	// gas used to update the resolver records
+	gasSetDomainRecord = uint64(50000)
+	// gas used to set the reverse name
+	gasSetReverseName = uint64(50000)
+	// gas used to resolve a domain name or address
+	gasResolveDomainName = uint64(5000)
+	// gas used to get the records of domain name
+	gasGetDomainRecord = uint64(5000)
 )
 
 var (
-	errNameEmpty   = errors.New("name is empty")
-	errNameTooLong = errors.New("name too long")
-	errInvalidName = errors.New("invalid name, only numbers, letters, and dash lines are allowed")
+	errNameEmpty      = errors.New("name is empty")
+	errNameTooLong    = errors.New("name too long")
+	errInvalidName    = errors.New("invalid name, only numbers, letters, and dash lines are allowed")
+	errNotTopLevel    = errors.New("only top level domain name is allowed")
+	errNotSubdomain   = errors.New("not a subdomain name")
+	errNotOwner       = errors.New("only the domain name owner is allowed")
+	errInvalidFee     = errors.New("invalid registration fee, should be multiple of the fee per period")
+	errTextKeyEmpty   = errors.New("text record key is empty")
+	errTextTooLong    = errors.New("text record too long")
+	errNotResolved    = errors.New("domain name does not resolve to the sender")
+	errInvalidAddress = errors.New("invalid address")
 
 	maxDomainNameLength = len(common.EmptyHash)
 
+	// the full domain name with subdomains, e.g. "pay.alice"
+	maxFullDomainNameLength = 255
+	// the key and value of text record
+	maxDomainTextLength = 1024
+
+	// DomainRegistrationPeriod is the number of blocks that the domain name is registered
+	// for each period, which is about one year.
+	DomainRegistrationPeriod = uint64(3153600)
+	// the periods paid at a time, which avoids the expiry overflow
+	maxDomainRegistrationPeriods = big.NewInt(100)
+
+	// DomainRegistrationFee is the fee to register or renew the domain name for one period.
+	DomainRegistrationFee = new(big.Int).Set(common.ScdoToWen)
+
 	domainNameCommands = map[byte]*cmdInfo{
 		CmdCreateDomainName:   &cmdInfo{gasCreateDomainName, createDomainName},
 		CmdGetDomainNameOwner: &cmdInfo{gasGetDomainNameOwner, getDomainNameOwner},
+		CmdTransferDomainName: &cmdInfo{gasTransferDomainName, transferDomainName},
+		CmdRenewDomainName:    &cmdInfo{gasRenewDomainName, renewDomainName},
+		CmdSetSubdomainOwner:  &cmdInfo{gasSetSubdomainOwner, setSubdomainOwner},
+		CmdSetDomainAddress:   &cmdInfo{gasSetDomainRecord, setDomainAddress},
+		CmdSetDomainABIHash:   &cmdInfo{gasSetDomainRecord, setDomainABIHash},
+		CmdSetDomainText:      &cmdInfo{gasSetDomainRecord, setDomainText},
+		CmdSetReverseName:     &cmdInfo{gasSetReverseName, setReverseName},
+		CmdResolveDomainName:  &cmdInfo{gasResolveDomainName, resolveDomainName},
+		CmdReverseResolve:     &cmdInfo{gasResolveDomainName, reverseResolve},
+		CmdGetDomainRecord:    &cmdInfo{gasGetDomainRecord, getDomainRecord},
 	}
 )
 
+// DomainNameOwner is the parameter to transfer a domain name or set the owner of subdomain
+type DomainNameOwner struct {
+	Name  string
+	Owner common.Address
+}
+
+// DomainNameAddress is the parameter to set the resolved address of domain name
+type DomainNameAddress struct {
+	Name    string
+	Address common.Address
+}
+
+// DomainNameABIHash is the parameter to set the contract ABI hash of domain name
+type DomainNameABIHash struct {
+	Name    string
+	ABIHash common.Hash
+}
+
+// DomainNameText is the parameter to set the text record of domain name,
+// and the text record is removed if the value is empty.
+type DomainNameText struct {
+	Name  string
+	Key   string
+	Value string
+}
+
+// DomainNameQuery is the parameter to resolve the domain name in the shard
+type DomainNameQuery struct {
+	Name  string
+	Shard uint
+}
+
+// DomainText is the text record of domain name
+type DomainText struct {
+	Key   string
+	Value string
+}
+
+// DomainRecord is the registration and resolver records of domain name
+type DomainRecord struct {
+	Name      string
+	Owner     common.Address
+	Expiry    uint64
+	Addresses []common.Address
+	ABIHash   common.Hash
+	Texts     []*DomainText
+}
+
+// domainRecord is the records stored along with the owner of domain name. The expiry
+// and registration height are only used for top level names, and the registration
+// height versions the subdomains, so that they are dropped on re-registration.
+type domainRecord struct {
+	Registered uint64
+	Expiry     uint64
+	Addresses  []common.Address
+	ABIHash    common.Hash
+	Texts      []*DomainText
+}
+
+// domain is a loaded domain name, which is registered if the owner is not empty.
+type domain struct {
+	name   string
+	key    common.Hash
+	owner  common.Address
+	record *domainRecord
+	parent *domain
+}
+
 // createDomainName create a domain name
 func createDomainName(domainName []byte, context *Context) ([]byte, error) {
 	key, err := domainNameToKey(domainName)
@@ -50,6 +189,10 @@ func createDomainName(domainName []byte, context *Context) ([]byte, error) {
 	// create account in statedb for the first time.
 	context.statedb.CreateAccount(DomainNameContractAddress)
 
+	if context.dnsEnabled() {
+		return registerDomainName(domainName, context)
+	}
+
 	// ensure not exist
 	if value := context.statedb.GetData(DomainNameContractAddress, key); len(value) > 0 {
 		return nil, errExists
@@ -59,11 +202,55 @@ func createDomainName(domainName []byte, context *Context) ([]byte, error) {
 	value := context.tx.Data.From.Bytes()
 	context.statedb.SetData(DomainNameContractAddress, key, value)
 
+	if err = context.emit("DomainRegistered", context.tx.Data.From, string(domainName)); err != nil {
+		return nil, err
+	}
+
 	return value, nil
 }
 
+// registerDomainName registers the domain name for the periods paid by the tx amount,
+// the expired domain name could be registered again by anyone.
+func registerDomainName(domainName []byte, context *Context) ([]byte, error) {
+	periods, err := registrationPeriods(context.tx.Data.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := loadDomain(domainName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.active(context) {
+		return nil, errExists
+	}
+
+	height := context.BlockHeader.Height
+	d.owner = context.tx.Data.From
+	d.record = &domainRecord{Registered: height, Expiry: height + periods*DomainRegistrationPeriod}
+	if err = d.save(context); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("DomainRegistered", d.owner, d.name); err != nil {
+		return nil, err
+	}
+
+	return d.owner.Bytes(), nil
+}
+
 // getDomainNameOwner get domain name owner
 func getDomainNameOwner(domainName []byte, context *Context) ([]byte, error) {
+	if context.dnsEnabled() {
+		d, err := loadActiveDomain(domainName, context)
+		if err != nil {
+			return nil, err
+		}
+
+		return d.owner.Bytes(), nil
+	}
+
 	key, err := domainNameToKey(domainName)
 	if err != nil {
 		return nil, err
@@ -77,6 +264,316 @@ func getDomainNameOwner(domainName []byte, context *Context) ([]byte, error) {
 	return owner, nil
 }
 
+// transferDomainName transfer the domain name to the new owner by the current owner
+func transferDomainName(input []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var param DomainNameOwner
+	if err := json.Unmarshal(input, &param); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	d, err := loadOwnedDomain([]byte(param.Name), context)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.changeOwner(param.Owner, context)
+}
+
+// renewDomainName extend the expiry of top level domain name for the periods paid
+// by the tx amount. Anyone is allowed to renew before the domain name expires.
+func renewDomainName(domainName []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	periods, err := registrationPeriods(context.tx.Data.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := loadActiveDomain(domainName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.parent != nil {
+		return nil, errNotTopLevel
+	}
+
+	d.record.Expiry += periods * DomainRegistrationPeriod
+	if err = d.save(context); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("DomainRenewed", d.name, d.record.Expiry); err != nil {
+		return nil, err
+	}
+
+	return uint64ToBytes(d.record.Expiry), nil
+}
+
+// setSubdomainOwner create the subdomain or change its owner by the owner of parent domain
+func setSubdomainOwner(input []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var param DomainNameOwner
+	if err := json.Unmarshal(input, &param); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	d, err := loadDomain([]byte(param.Name), context)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.parent == nil {
+		return nil, errNotSubdomain
+	}
+
+	if !d.parent.active(context) {
+		return nil, errNotFound
+	}
+
+	if !d.parent.owner.Equal(context.tx.Data.From) {
+		return nil, errNotOwner
+	}
+
+	return d.changeOwner(param.Owner, context)
+}
+
+// setDomainAddress set the address that the domain name resolves to in the shard of address
+func setDomainAddress(input []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var param DomainNameAddress
+	if err := json.Unmarshal(input, &param); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	if param.Address.IsEmpty() {
+		return nil, errInvalidAddress
+	}
+
+	d, err := loadOwnedDomain([]byte(param.Name), context)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]common.Address, 0, len(d.record.Addresses)+1)
+	for _, addr := range d.record.Addresses {
+		if addr.Shard() != param.Address.Shard() {
+			addresses = append(addresses, addr)
+		}
+	}
+	d.record.Addresses = append(addresses, param.Address)
+
+	if err = d.save(context); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("DomainAddressChanged", d.name, param.Address); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// setDomainABIHash set the contract ABI hash of domain name
+func setDomainABIHash(input []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var param DomainNameABIHash
+	if err := json.Unmarshal(input, &param); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	d, err := loadOwnedDomain([]byte(param.Name), context)
+	if err != nil {
+		return nil, err
+	}
+
+	d.record.ABIHash = param.ABIHash
+	if err = d.save(context); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("DomainABIHashChanged", d.name, [common.HashLength]byte(param.ABIHash)); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// setDomainText set the text record of domain name, or remove it if the value is empty
+func setDomainText(input []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var param DomainNameText
+	if err := json.Unmarshal(input, &param); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	if len(param.Key) == 0 {
+		return nil, errTextKeyEmpty
+	}
+
+	if len(param.Key) > maxDomainTextLength || len(param.Value) > maxDomainTextLength {
+		return nil, errTextTooLong
+	}
+
+	d, err := loadOwnedDomain([]byte(param.Name), context)
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]*DomainText, 0, len(d.record.Texts)+1)
+	for _, text := range d.record.Texts {
+		if text.Key != param.Key {
+			texts = append(texts, text)
+		}
+	}
+
+	if len(param.Value) > 0 {
+		texts = append(texts, &DomainText{param.Key, param.Value})
+	}
+	d.record.Texts = texts
+
+	if err = d.save(context); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("DomainTextChanged", d.name, param.Key, param.Value); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// setReverseName set the domain name that the sender reversely resolves to, the domain
+// name should resolve to the sender. The reverse name is removed if the name is empty.
+func setReverseName(domainName []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	sender := context.tx.Data.From
+	name := ""
+	if len(domainName) > 0 {
+		d, err := loadActiveDomain(domainName, context)
+		if err != nil {
+			return nil, err
+		}
+
+		if !d.resolvesTo(sender) {
+			return nil, errNotResolved
+		}
+
+		name = d.name
+	}
+
+	context.statedb.CreateAccount(DomainNameContractAddress)
+	context.statedb.SetData(DomainNameContractAddress, reverseKey(sender), []byte(name))
+
+	if err := context.emit("ReverseNameChanged", sender, name); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// resolveDomainName resolve the domain name to the address in the specified shard,
+// or the first address set if no address in the shard.
+func resolveDomainName(input []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var param DomainNameQuery
+	if err := json.Unmarshal(input, &param); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	d, err := loadActiveDomain([]byte(param.Name), context)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d.record.Addresses) == 0 {
+		return nil, errNotFound
+	}
+
+	for _, addr := range d.record.Addresses {
+		if addr.Shard() == param.Shard {
+			return addr.Bytes(), nil
+		}
+	}
+
+	return d.record.Addresses[0].Bytes(), nil
+}
+
+// reverseResolve resolve the address to the domain name, which still resolves to the address
+func reverseResolve(address []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	addr, err := common.NewAddress(address)
+	if err != nil {
+		return nil, errInvalidAddress
+	}
+
+	name := context.statedb.GetData(DomainNameContractAddress, reverseKey(addr))
+	if len(name) == 0 {
+		return nil, errNotFound
+	}
+
+	d, err := loadActiveDomain(name, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.resolvesTo(addr) {
+		return nil, errNotFound
+	}
+
+	return name, nil
+}
+
+// getDomainRecord get the registration and resolver records of domain name in JSON
+func getDomainRecord(domainName []byte, context *Context) ([]byte, error) {
+	if !context.dnsEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	d, err := loadActiveDomain(domainName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	record := DomainRecord{
+		Name:      d.name,
+		Owner:     d.owner,
+		Expiry:    d.top().record.Expiry,
+		Addresses: d.record.Addresses,
+		ABIHash:   d.record.ABIHash,
+		Texts:     d.record.Texts,
+	}
+
+	return json.Marshal(record)
+}
+
 // ValidateDomainName validate domain name
 func ValidateDomainName(domainName []byte) error {
 	nameLen := len(domainName)
@@ -99,6 +596,21 @@ func ValidateDomainName(domainName []byte) error {
 	return nil
 }
 
+// ValidateFullDomainName validate the domain name that may contain subdomains, e.g. "pay.alice"
+func ValidateFullDomainName(domainName []byte) error {
+	if len(domainName) > maxFullDomainNameLength {
+		return errNameTooLong
+	}
+
+	for _, label := range strings.Split(string(domainName), ".") {
+		if err := ValidateDomainName([]byte(label)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // domainNameToKey convert domain name to hash and uppercase to lowercase
 func domainNameToKey(domainName []byte) (common.Hash, error) {
 	lowerDomainName := []byte(strings.ToLower(string(domainName)))
@@ -110,3 +622,181 @@ func domainNameToKey(domainName []byte) (common.Hash, error) {
 
 	return common.BytesToHash(domainName), nil
 }
+
+// subdomainKey returns the key of subdomain, which is versioned by the registration height of top level name
+func subdomainKey(parent common.Hash, registered uint64, label string) common.Hash {
+	return crypto.HashBytes(parent.Bytes(), uint64ToBytes(registered), []byte(strings.ToLower(label)))
+}
+
+// recordKey returns the key of records of the domain name with the key
+func recordKey(key common.Hash) common.Hash {
+	return crypto.HashBytes(key.Bytes(), []byte("record"))
+}
+
+// reverseKey returns the key of reverse name of the address
+func reverseKey(address common.Address) common.Hash {
+	return crypto.HashBytes(address.Bytes(), []byte("reverse"))
+}
+
+// loadDomain loads the domain name from the top level name to the subdomain. The records
+// of registered top level name without expiry are created before the fork, which expire
+// one period after the fork.
+func loadDomain(domainName []byte, context *Context) (*domain, error) {
+	if err := ValidateFullDomainName(domainName); err != nil {
+		return nil, err
+	}
+
+	labels := strings.Split(string(domainName), ".")
+	top := labels[len(labels)-1]
+	key, err := domainNameToKey([]byte(top))
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := getDomain(top, key, nil, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.owner.IsEmpty() && d.record.Expiry == 0 {
+		d.record.Expiry = common.DomainNameServiceForkHeight + DomainRegistrationPeriod
+	}
+
+	registered := d.record.Registered
+	for i := len(labels) - 2; i >= 0; i-- {
+		name := strings.Join(labels[i:], ".")
+		if d, err = getDomain(name, subdomainKey(d.key, registered, labels[i]), d, context); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// loadActiveDomain loads the domain name, and returns errNotFound if not active
+func loadActiveDomain(domainName []byte, context *Context) (*domain, error) {
+	d, err := loadDomain(domainName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.active(context) {
+		return nil, errNotFound
+	}
+
+	return d, nil
+}
+
+// loadOwnedDomain loads the active domain name owned by the sender
+func loadOwnedDomain(domainName []byte, context *Context) (*domain, error) {
+	d, err := loadActiveDomain(domainName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.owner.Equal(context.tx.Data.From) {
+		return nil, errNotOwner
+	}
+
+	return d, nil
+}
+
+func getDomain(name string, key common.Hash, parent *domain, context *Context) (*domain, error) {
+	d := &domain{
+		name:   name,
+		key:    key,
+		owner:  common.BytesToAddress(context.statedb.GetData(DomainNameContractAddress, key)),
+		record: &domainRecord{},
+		parent: parent,
+	}
+
+	if value := context.statedb.GetData(DomainNameContractAddress, recordKey(key)); len(value) > 0 {
+		if err := common.Deserialize(value, d.record); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// top returns the top level domain name
+func (d *domain) top() *domain {
+	for d.parent != nil {
+		d = d.parent
+	}
+
+	return d
+}
+
+// active returns whether the domain name and all parents are registered and not expired
+func (d *domain) active(context *Context) bool {
+	for p := d; p != nil; p = p.parent {
+		if p.owner.IsEmpty() {
+			return false
+		}
+	}
+
+	return context.BlockHeader.Height < d.top().record.Expiry
+}
+
+// resolvesTo returns whether the domain name resolves to the address in any shard
+func (d *domain) resolvesTo(address common.Address) bool {
+	for _, addr := range d.record.Addresses {
+		if addr.Equal(address) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *domain) changeOwner(owner common.Address, context *Context) ([]byte, error) {
+	if owner.IsEmpty() {
+		return nil, errInvalidAddress
+	}
+
+	d.owner = owner
+	if err := d.save(context); err != nil {
+		return nil, err
+	}
+
+	if err := context.emit("DomainOwnerChanged", owner, d.name); err != nil {
+		return nil, err
+	}
+
+	return owner.Bytes(), nil
+}
+
+func (d *domain) save(context *Context) error {
+	value, err := common.Serialize(d.record)
+	if err != nil {
+		return err
+	}
+
+	context.statedb.CreateAccount(DomainNameContractAddress)
+	context.statedb.SetData(DomainNameContractAddress, d.key, d.owner.Bytes())
+	context.statedb.SetData(DomainNameContractAddress, recordKey(d.key), value)
+
+	return nil
+}
+
+// registrationPeriods returns the number of periods paid by the amount
+func registrationPeriods(amount *big.Int) (uint64, error) {
+	periods, remainder := new(big.Int).DivMod(amount, DomainRegistrationFee, new(big.Int))
+	if periods.Sign() <= 0 || remainder.Sign() != 0 || periods.Cmp(maxDomainRegistrationPeriods) > 0 {
+		return 0, errInvalidFee
+	}
+
+	return periods.Uint64(), nil
+}
+
+// dnsEnabled returns whether the domain name service is enabled after the fork
+func (ctx *Context) dnsEnabled() bool {
+	return ctx.BlockHeader.Height >= common.DomainNameServiceForkHeight
+}
+
+func uint64ToBytes(v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	return buf
+}
@@ -6,9 +6,13 @@
 package system
 
 import (
+	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database"
 	"github.com/scdoproject/go-stem/database/leveldb"
 	"github.com/stretchr/testify/assert"
 )
@@ -75,3 +79,222 @@ func Test_CreateDomainName(t *testing.T) {
 	assert.Equal(t, result, []byte(nil))
 	assert.Equal(t, err, errNotFound)
 }
+
+func newDomainNameContext(db database.Database, from common.Address) *Context {
+	context := newTestContext(db, DomainNameContractAddress)
+	context.tx.Data.From = from
+	context.tx.Data.Amount = big.NewInt(0)
+	context.BlockHeader.Height = common.DomainNameServiceForkHeight
+
+	return context
+}
+
+func runDomainNameCmd(context *Context, cmd byte, param interface{}) ([]byte, error) {
+	input, ok := param.([]byte)
+	if !ok {
+		var err error
+		if input, err = json.Marshal(param); err != nil {
+			return nil, err
+		}
+	}
+
+	return GetContractByAddress(DomainNameContractAddress, context.BlockHeader.Height).Run(append([]byte{cmd}, input...), context)
+}
+
+func Test_DomainName_Register(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	owner := *crypto.MustGenerateShardAddress(1)
+	context := newDomainNameContext(db, owner)
+
+	// registration fee is required after fork
+	_, err := runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.Equal(t, errInvalidFee, err)
+
+	context.tx.Data.Amount = new(big.Int).Mul(DomainRegistrationFee, big.NewInt(2))
+	result, err := runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Bytes(), result)
+
+	_, err = runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.Equal(t, errExists, err)
+
+	// renew by anyone
+	context.tx.Data.From = *crypto.MustGenerateShardAddress(2)
+	context.tx.Data.Amount = DomainRegistrationFee
+	result, err = runDomainNameCmd(context, CmdRenewDomainName, []byte("alice"))
+	assert.NoError(t, err)
+	expiry := common.DomainNameServiceForkHeight + 3*DomainRegistrationPeriod
+	assert.Equal(t, uint64ToBytes(expiry), result)
+
+	// transfer by owner only
+	newOwner := *crypto.MustGenerateShardAddress(1)
+	_, err = runDomainNameCmd(context, CmdTransferDomainName, DomainNameOwner{"alice", newOwner})
+	assert.Equal(t, errNotOwner, err)
+
+	context.tx.Data.From = owner
+	_, err = runDomainNameCmd(context, CmdTransferDomainName, DomainNameOwner{"alice", newOwner})
+	assert.NoError(t, err)
+
+	result, err = runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, newOwner.Bytes(), result)
+
+	// expired name could be registered by anyone
+	context.BlockHeader.Height = expiry
+	_, err = runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("alice"))
+	assert.Equal(t, errNotFound, err)
+
+	result, err = runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Bytes(), result)
+}
+
+func Test_DomainName_RegisteredBeforeFork(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	owner := *crypto.MustGenerateShardAddress(1)
+	context := newDomainNameContext(db, owner)
+	context.BlockHeader.Height = common.DomainNameServiceForkHeight - 1
+
+	_, err := runDomainNameCmd(context, CmdTransferDomainName, DomainNameOwner{"bob", owner})
+	assert.Equal(t, errInvalidCommand, err)
+
+	_, err = runDomainNameCmd(context, CmdCreateDomainName, []byte("bob"))
+	assert.NoError(t, err)
+
+	// expire one period after fork
+	context.BlockHeader.Height = common.DomainNameServiceForkHeight + DomainRegistrationPeriod - 1
+	result, err := runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("bob"))
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Bytes(), result)
+
+	context.BlockHeader.Height++
+	_, err = runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("bob"))
+	assert.Equal(t, errNotFound, err)
+}
+
+func Test_DomainName_Resolve(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	owner := *crypto.MustGenerateShardAddress(1)
+	context := newDomainNameContext(db, owner)
+	context.tx.Data.Amount = DomainRegistrationFee
+	_, err := runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.NoError(t, err)
+
+	_, err = runDomainNameCmd(context, CmdResolveDomainName, DomainNameQuery{"alice", 1})
+	assert.Equal(t, errNotFound, err)
+
+	// address per shard
+	addr1, addr2 := *crypto.MustGenerateShardAddress(1), *crypto.MustGenerateShardAddress(2)
+	for _, addr := range []common.Address{owner, addr1, addr2} {
+		_, err = runDomainNameCmd(context, CmdSetDomainAddress, DomainNameAddress{"alice", addr})
+		assert.NoError(t, err)
+	}
+
+	result, err := runDomainNameCmd(context, CmdResolveDomainName, DomainNameQuery{"alice", 1})
+	assert.NoError(t, err)
+	assert.Equal(t, addr1.Bytes(), result)
+
+	result, err = runDomainNameCmd(context, CmdResolveDomainName, DomainNameQuery{"alice", 2})
+	assert.NoError(t, err)
+	assert.Equal(t, addr2.Bytes(), result)
+
+	result, err = runDomainNameCmd(context, CmdResolveDomainName, DomainNameQuery{"alice", 3})
+	assert.NoError(t, err)
+	assert.Equal(t, addr1.Bytes(), result)
+
+	// ABI hash and text records
+	abiHash := crypto.MustHash("abi")
+	_, err = runDomainNameCmd(context, CmdSetDomainABIHash, DomainNameABIHash{"alice", abiHash})
+	assert.NoError(t, err)
+	_, err = runDomainNameCmd(context, CmdSetDomainText, DomainNameText{"alice", "url", "https://scdo.pro"})
+	assert.NoError(t, err)
+	_, err = runDomainNameCmd(context, CmdSetDomainText, DomainNameText{"alice", "email", "alice@scdo.pro"})
+	assert.NoError(t, err)
+	_, err = runDomainNameCmd(context, CmdSetDomainText, DomainNameText{"alice", "email", ""})
+	assert.NoError(t, err)
+
+	result, err = runDomainNameCmd(context, CmdGetDomainRecord, []byte("alice"))
+	assert.NoError(t, err)
+	var record DomainRecord
+	assert.NoError(t, json.Unmarshal(result, &record))
+	assert.Equal(t, owner, record.Owner)
+	assert.Equal(t, common.DomainNameServiceForkHeight+DomainRegistrationPeriod, record.Expiry)
+	assert.Equal(t, []common.Address{addr1, addr2}, record.Addresses)
+	assert.Equal(t, abiHash, record.ABIHash)
+	assert.Equal(t, []*DomainText{{"url", "https://scdo.pro"}}, record.Texts)
+
+	// reverse resolution
+	other := *crypto.MustGenerateShardAddress(1)
+	context.tx.Data.From = other
+	_, err = runDomainNameCmd(context, CmdSetReverseName, []byte("alice"))
+	assert.Equal(t, errNotResolved, err)
+
+	context.tx.Data.From = addr1
+	_, err = runDomainNameCmd(context, CmdSetReverseName, []byte("alice"))
+	assert.NoError(t, err)
+
+	result, err = runDomainNameCmd(context, CmdReverseResolve, addr1.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("alice"), result)
+
+	// reverse name is invalid once the name does not resolve to the address
+	context.tx.Data.From = owner
+	_, err = runDomainNameCmd(context, CmdSetDomainAddress, DomainNameAddress{"alice", other})
+	assert.NoError(t, err)
+	_, err = runDomainNameCmd(context, CmdReverseResolve, addr1.Bytes())
+	assert.Equal(t, errNotFound, err)
+}
+
+func Test_DomainName_Subdomain(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	owner := *crypto.MustGenerateShardAddress(1)
+	context := newDomainNameContext(db, owner)
+	context.tx.Data.Amount = DomainRegistrationFee
+	_, err := runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.NoError(t, err)
+
+	// subdomain is controlled by the parent owner
+	subOwner := *crypto.MustGenerateShardAddress(2)
+	_, err = runDomainNameCmd(context, CmdSetSubdomainOwner, DomainNameOwner{"alice", subOwner})
+	assert.Equal(t, errNotSubdomain, err)
+
+	_, err = runDomainNameCmd(context, CmdSetSubdomainOwner, DomainNameOwner{"pay.alice", subOwner})
+	assert.NoError(t, err)
+
+	result, err := runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("pay.alice"))
+	assert.NoError(t, err)
+	assert.Equal(t, subOwner.Bytes(), result)
+
+	context.tx.Data.From = subOwner
+	_, err = runDomainNameCmd(context, CmdSetSubdomainOwner, DomainNameOwner{"pay.alice", subOwner})
+	assert.Equal(t, errNotOwner, err)
+
+	_, err = runDomainNameCmd(context, CmdSetDomainAddress, DomainNameAddress{"pay.alice", subOwner})
+	assert.NoError(t, err)
+
+	result, err = runDomainNameCmd(context, CmdResolveDomainName, DomainNameQuery{"Pay.alice", 2})
+	assert.NoError(t, err)
+	assert.Equal(t, subOwner.Bytes(), result)
+
+	// renew the top level name only
+	_, err = runDomainNameCmd(context, CmdRenewDomainName, []byte("pay.alice"))
+	assert.Equal(t, errNotTopLevel, err)
+
+	// subdomains are dropped once the parent is registered again
+	context.BlockHeader.Height += DomainRegistrationPeriod
+	_, err = runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("pay.alice"))
+	assert.Equal(t, errNotFound, err)
+
+	_, err = runDomainNameCmd(context, CmdCreateDomainName, []byte("alice"))
+	assert.NoError(t, err)
+	_, err = runDomainNameCmd(context, CmdGetDomainNameOwner, []byte("pay.alice"))
+	assert.Equal(t, errNotFound, err)
+}
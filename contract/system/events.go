@@ -0,0 +1,134 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// ABI of the events emitted by system contracts, which are also published
+// in contract/solidity/system along with the Solidity interfaces.
+const (
+	// DomainNameABI is the ABI of domain name contract events
+	DomainNameABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"name","type":"string"}],"name":"DomainRegistered","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"name","type":"string"}],"name":"DomainOwnerChanged","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"name","type":"string"},{"indexed":false,"name":"expiry","type":"uint64"}],"name":"DomainRenewed","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"name","type":"string"},{"indexed":false,"name":"addr","type":"address"}],"name":"DomainAddressChanged","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"name","type":"string"},{"indexed":false,"name":"abiHash","type":"bytes32"}],"name":"DomainABIHashChanged","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":false,"name":"name","type":"string"},{"indexed":false,"name":"key","type":"string"},{"indexed":false,"name":"value","type":"string"}],"name":"DomainTextChanged","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"addr","type":"address"},{"indexed":false,"name":"name","type":"string"}],"name":"ReverseNameChanged","type":"event"}
+]`
+
+	// SubChainABI is the ABI of sub-chain contract events
+	SubChainABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"name","type":"string"},{"indexed":false,"name":"version","type":"string"},{"indexed":false,"name":"tokenShortName","type":"string"},{"indexed":false,"name":"tokenAmount","type":"uint64"}],"name":"SubChainRegistered","type":"event"}
+]`
+
+	// HashTimeLockABI is the ABI of HTLC contract events
+	HashTimeLockABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":true,"name":"receiver","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":false,"name":"hashLock","type":"bytes"},{"indexed":false,"name":"timeLock","type":"int64"}],"name":"HTLCCreated","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":true,"name":"receiver","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":false,"name":"preimage","type":"bytes"}],"name":"HTLCWithdrawn","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"key","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"HTLCRefunded","type":"event"}
+]`
+
+	// MasternodeABI is the ABI of masternode contract events
+	MasternodeABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"node","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"MasternodeDeposited","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"node","type":"address"},{"indexed":false,"name":"height","type":"uint64"}],"name":"MasternodeQuit","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"node","type":"address"},{"indexed":true,"name":"receiver","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"MasternodeRecalled","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"node","type":"address"},{"indexed":false,"name":"endpoint","type":"string"}],"name":"MasternodeEndpointUpdated","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"node","type":"address"},{"indexed":false,"name":"epoch","type":"uint64"}],"name":"MasternodeProved","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"node","type":"address"},{"indexed":true,"name":"reporter","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"MasternodeSlashed","type":"event"}
+]`
+
+	// BTCRelayABI is the ABI of btc-relay contract events
+	BTCRelayABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"relayer","type":"address"},{"indexed":false,"name":"blockHeader","type":"string"},{"indexed":false,"name":"height","type":"uint64"}],"name":"BTCBlockHeaderStored","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":true,"name":"relayer","type":"address"},{"indexed":false,"name":"tx","type":"string"},{"indexed":false,"name":"fee","type":"uint256"},{"indexed":false,"name":"verified","type":"bool"}],"name":"BTCTxVerified","type":"event"}
+]`
+
+	// StakingABI is the ABI of staking contract events
+	StakingABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"staker","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":false,"name":"activeHeight","type":"uint64"}],"name":"StakeBonded","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"staker","type":"address"},{"indexed":false,"name":"amount","type":"uint256"},{"indexed":false,"name":"releaseHeight","type":"uint64"}],"name":"StakeUnbonded","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"staker","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"StakeWithdrawn","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"staker","type":"address"},{"indexed":false,"name":"publicKey","type":"bytes"}],"name":"BLSKeyRegistered","type":"event"}
+]`
+
+	// ConfidentialABI is the ABI of confidential contract events
+	ConfidentialABI = `[
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"publicKey","type":"bytes"}],"name":"ConfidentialAccountCreated","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"ConfidentialDeposited","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"commitment","type":"bytes"}],"name":"ConfidentialTransferred","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"amount","type":"uint256"}],"name":"ConfidentialWithdrawn","type":"event"},
+{"anonymous":false,"inputs":[{"indexed":true,"name":"owner","type":"address"},{"indexed":false,"name":"count","type":"uint64"}],"name":"ConfidentialApplied","type":"event"}
+]`
+)
+
+var (
+	contractABIJSONs = map[common.Address]string{
+		DomainNameContractAddress:   DomainNameABI,
+		SubChainContractAddress:     SubChainABI,
+		HashTimeLockContractAddress: HashTimeLockABI,
+		MasternodeContractAddress:   MasternodeABI,
+		BTCRelayContractAddress:     BTCRelayABI,
+		StakingContractAddress:      StakingABI,
+		ConfidentialContractAddress: ConfidentialABI,
+	}
+
+	contractABIs = make(map[common.Address]abi.ABI)
+)
+
+func init() {
+	for address, abiJSON := range contractABIJSONs {
+		parsed, err := abi.JSON(strings.NewReader(abiJSON))
+		if err != nil {
+			panic(fmt.Sprintf("invalid ABI of system contract %v, %s", address.Hex(), err))
+		}
+
+		contractABIs[address] = parsed
+	}
+}
+
+// GetContractABI returns the ABI JSON of the events emitted by the system contract,
+// or empty string if the address is not a system contract.
+func GetContractABI(address common.Address) string {
+	return contractABIJSONs[address]
+}
+
+// emit packs the event of the called system contract into log, which is added to the
+// statedb only if the command succeeds. The events are emitted after the fork height.
+func (ctx *Context) emit(eventName string, args ...interface{}) error {
+	if ctx.BlockHeader.Height < common.SystemContractEventForkHeight {
+		return nil
+	}
+
+	address := ctx.tx.Data.To
+	event, ok := contractABIs[address].Events[eventName]
+	if !ok {
+		return fmt.Errorf("event %v not found in system contract %v", eventName, address.Hex())
+	}
+
+	topics, data, err := event.PackLog(args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack event %v, %s", eventName, err)
+	}
+
+	ctx.logs = append(ctx.logs, &types.Log{
+		Address:     address,
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: ctx.BlockHeader.Height,
+	})
+
+	return nil
+}
@@ -0,0 +1,119 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ContractABI_Published(t *testing.T) {
+	files := map[common.Address]string{
+		DomainNameContractAddress:   "DomainName.abi",
+		SubChainContractAddress:     "SubChain.abi",
+		HashTimeLockContractAddress: "HashTimeLock.abi",
+		MasternodeContractAddress:   "Masternode.abi",
+		BTCRelayContractAddress:     "BTCRelay.abi",
+		StakingContractAddress:      "Staking.abi",
+		ConfidentialContractAddress: "Confidential.abi",
+	}
+
+	for address, file := range files {
+		content, err := ioutil.ReadFile("../solidity/system/" + file)
+		assert.NoError(t, err)
+		assert.Equal(t, GetContractABI(address), strings.TrimSpace(string(content)))
+	}
+
+	assert.Equal(t, "", GetContractABI(common.BytesToAddress([]byte{1})))
+}
+
+func Test_Event_DomainRegistered(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, DomainNameContractAddress)
+	context.BlockHeader.Height = common.SystemContractEventForkHeight
+	context.tx.Data.Amount = DomainRegistrationFee
+	contract := GetContractByAddress(DomainNameContractAddress, context.BlockHeader.Height)
+
+	input := append([]byte{CmdCreateDomainName}, []byte("scdo")...)
+	_, err := contract.Run(input, context)
+	assert.NoError(t, err)
+
+	logs := context.statedb.GetCurrentLogs()
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, DomainNameContractAddress, logs[0].Address)
+
+	parsed, err := abi.JSON(strings.NewReader(GetContractABI(DomainNameContractAddress)))
+	assert.NoError(t, err)
+	args, err := parsed.Events["DomainRegistered"].UnpackLog(logs[0].Topics, logs[0].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{context.tx.Data.From, "scdo"}, args)
+
+	// no log if the command fails
+	context.statedb.Prepare(1)
+	_, err = contract.Run(input, context)
+	assert.Equal(t, errExists, err)
+	assert.Equal(t, 0, len(context.statedb.GetCurrentLogs()))
+
+	// no log before fork
+	context = newTestContext(db, DomainNameContractAddress)
+	context.BlockHeader.Height = common.SystemContractEventForkHeight - 1
+	_, err = contract.Run(append([]byte{CmdCreateDomainName}, []byte("scdo2")...), context)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(context.statedb.GetCurrentLogs()))
+}
+
+func Test_Event_HTLC(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, HashTimeLockContractAddress)
+	context.BlockHeader.Height = common.SystemContractEventForkHeight
+	context.statedb.CreateAccount(context.tx.Data.From)
+	context.statedb.SetBalance(context.tx.Data.From, big.NewInt(100))
+
+	hashLock, _ := hexutil.HexToBytes(secretehash)
+	lock := HashTimeLock{HashLock: hashLock, TimeLock: time.Now().Unix() + 3600, To: context.tx.Data.From}
+	lockBytes, _ := json.Marshal(lock)
+	contract := GetContractByAddress(HashTimeLockContractAddress, context.BlockHeader.Height)
+	_, err := contract.Run(append([]byte{CmdNewContract}, lockBytes...), context)
+	assert.NoError(t, err)
+
+	// withdraw with the preimage
+	preimage, _ := hexutil.HexToBytes(secret)
+	withdrawing, _ := json.Marshal(Withdrawing{Hash: context.tx.Hash, Preimage: preimage})
+	context.statedb.SetBalance(HashTimeLockContractAddress, big.NewInt(1))
+	_, err = contract.Run(append([]byte{CmdWithdraw}, withdrawing...), context)
+	assert.NoError(t, err)
+
+	parsed, _ := abi.JSON(strings.NewReader(HashTimeLockABI))
+	logs := context.statedb.GetCurrentLogs()
+	assert.Equal(t, 2, len(logs))
+
+	args, err := parsed.Events["HTLCCreated"].UnpackLog(logs[0].Topics, logs[0].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, [32]byte(context.tx.Hash), args[0])
+	assert.Equal(t, context.tx.Data.From, args[1])
+	assert.Equal(t, context.tx.Data.Amount, args[3])
+	assert.Equal(t, []byte(hashLock), args[4])
+	assert.Equal(t, lock.TimeLock, args[5])
+
+	args, err = parsed.Events["HTLCWithdrawn"].UnpackLog(logs[1].Topics, logs[1].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, context.tx.Data.From, args[1])
+	assert.Equal(t, []byte(preimage), args[3])
+}
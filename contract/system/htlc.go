@@ -116,6 +116,10 @@ func newHTLC(lockbytes []byte, context *Context) ([]byte, error) {
 	context.statedb.CreateAccount(HashTimeLockContractAddress)
 	context.statedb.SetData(HashTimeLockContractAddress, data.Tx.Hash, value)
 
+	if err = context.emit("HTLCCreated", data.Tx.Hash, data.Tx.Data.From, data.To, data.Tx.Data.Amount, []byte(data.HashLock), data.TimeLock); err != nil {
+		return nil, err
+	}
+
 	return value, nil
 }
 
@@ -157,6 +161,10 @@ func withdraw(jsonWithdraw []byte, context *Context) ([]byte, error) {
 	// add the amount to the sender account
 	context.statedb.AddBalance(info.To, info.Tx.Data.Amount)
 
+	if err = context.emit("HTLCWithdrawn", info.Tx.Hash, info.To, info.Tx.Data.Amount, []byte(info.Preimage)); err != nil {
+		return nil, err
+	}
+
 	return value, nil
 }
 
@@ -188,6 +196,11 @@ func refund(bytes []byte, context *Context) ([]byte, error) {
 	context.statedb.SubBalance(context.tx.Data.To, info.Tx.Data.Amount)
 	// add the amount to sender account
 	context.statedb.AddBalance(info.Tx.Data.From, info.Tx.Data.Amount)
+
+	if err = context.emit("HTLCRefunded", info.Tx.Hash, info.Tx.Data.From, info.Tx.Data.Amount); err != nil {
+		return nil, err
+	}
+
 	return value, nil
 }
 
@@ -18,11 +18,12 @@ type Context struct {
 	tx          *types.Transaction
 	statedb     *state.Statedb
 	BlockHeader *types.BlockHeader
+	logs        []*types.Log // event logs added to statedb if the command succeeds
 }
 
 // NewContext creates a system contract context.
 func NewContext(tx *types.Transaction, statedb *state.Statedb, BlockHeader *types.BlockHeader) *Context {
-	return &Context{tx, statedb, BlockHeader}
+	return &Context{tx: tx, statedb: statedb, BlockHeader: BlockHeader}
 }
 
 // Contract is the basic interface for native Go contracts in Scdo.
@@ -50,6 +51,10 @@ var (
 	MasternodeContractAddress = common.BytesToAddress([]byte{1, 4})
 	// BTCRelayContractAddress btc-relay contract address
 	BTCRelayContractAddress = common.BytesToAddress([]byte{1, 5})
+	// StakingContractAddress staking contract address
+	StakingContractAddress = common.BytesToAddress([]byte{1, 6})
+	// ConfidentialContractAddress confidential transfer contract address
+	ConfidentialContractAddress = common.BytesToAddress([]byte{1, 7})
 
 	// Contracts are system contracts
 	contracts = map[common.Address]Contract{
@@ -58,6 +63,15 @@ var (
 		HashTimeLockContractAddress: &contract{htlcCommands},
 		MasternodeContractAddress:   &contract{masternodeCommands},
 		BTCRelayContractAddress:     &contract{brCommands},
+		StakingContractAddress:      &contract{stakingCommands},
+		ConfidentialContractAddress: &contract{confidentialCommands},
+	}
+
+	// contractForkHeights are the heights since which the system contracts are deployed, before
+	// which the txs to the contract addresses are plain transfers.
+	contractForkHeights = map[common.Address]uint64{
+		StakingContractAddress:      common.StakingForkHeight,
+		ConfidentialContractAddress: common.ConfidentialTransferForkHeight,
 	}
 )
 
@@ -90,13 +104,28 @@ func (c *contract) Run(input []byte, context *Context) ([]byte, error) {
 	}
 
 	if info, found := c.cmds[input[0]]; found {
-		return info.cmdHandler(input[1:], context)
+		context.logs = nil
+		result, err := info.cmdHandler(input[1:], context)
+		if err != nil {
+			return result, err
+		}
+
+		for _, log := range context.logs {
+			context.statedb.AddLog(log)
+		}
+
+		return result, nil
 	}
 
 	return nil, errInvalidCommand
 }
 
-// GetContractByAddress get system contract by the address
-func GetContractByAddress(address common.Address) Contract {
+// GetContractByAddress get system contract by the address, which is nil if the contract
+// is not deployed at height
+func GetContractByAddress(address common.Address, height uint64) Contract {
+	if forkHeight, ok := contractForkHeights[address]; ok && height < forkHeight {
+		return nil
+	}
+
 	return contracts[address]
 }
@@ -110,10 +110,16 @@ func Test_Run(t *testing.T) {
 }
 
 func Test_GetContractByAddress(t *testing.T) {
-	c := GetContractByAddress(DomainNameContractAddress)
+	c := GetContractByAddress(DomainNameContractAddress, 0)
 	assert.Equal(t, c, &contract{domainNameCommands})
 
 	contractAddress := common.BytesToAddress([]byte{123, 1})
-	c1 := GetContractByAddress(contractAddress)
+	c1 := GetContractByAddress(contractAddress, 0)
 	assert.Equal(t, c1, nil)
+
+	// not deployed before the fork height
+	assert.Nil(t, GetContractByAddress(StakingContractAddress, common.StakingForkHeight-1))
+	assert.Equal(t, &contract{stakingCommands}, GetContractByAddress(StakingContractAddress, common.StakingForkHeight))
+	assert.Nil(t, GetContractByAddress(ConfidentialContractAddress, common.ConfidentialTransferForkHeight-1))
+	assert.Equal(t, &contract{confidentialCommands}, GetContractByAddress(ConfidentialContractAddress, common.ConfidentialTransferForkHeight))
 }
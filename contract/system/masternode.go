@@ -6,14 +6,26 @@
 package system
 
 import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
 	"math/big"
 
 	"github.com/pkg/errors"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
 )
 
+// Masternodes are full nodes that deposit 20000 SCDO, and publish their network endpoints
+// in the registry, so that light clients and new nodes could connect to them. Every epoch,
+// the masternode signs a liveness proof that attests the first block of the epoch, and
+// submits it to the chain. After MasternodeForkHeight, a share of block reward is pooled
+// in the masternode contract, and the pool is paid equally to the masternodes that proved
+// liveness in the last epoch. Signing two different blocks in the same epoch is provable
+// misbehaviour, and anyone could report the evidence to slash the deposit of masternode.
+
 const (
 	// CmdDeposit deposit scdo and register as masternode
 	CmdDeposit byte = iota
@@ -23,11 +35,26 @@ const (
 	CmdRecall
 	// CmdQuit quitCmd masternode
 	CmdQuit
-
-	gasCmdDeposit         = uint64(50000) // gas used to deposit
-	gasCmdQueryMasterNode = uint64(5000)  // gas used to query masternode
-	gasCmdRecall          = uint64(50000) // gas used to recallCmd
-	gasCmdQuit            = uint64(50000) // gas used to quitCmd
+	// CmdUpdateEndpoint update the network endpoint of masternode
+	CmdUpdateEndpoint
+	// CmdProveLiveness submit the liveness proof of masternode in current epoch
+	CmdProveLiveness
+	// CmdSlash slash the deposit of masternode with the evidence of misbehaviour
+	CmdSlash
+	// CmdGetMasternodes list the active masternodes
+	CmdGetMasternodes
+	// CmdGetMasternode get the masternode info
+	CmdGetMasternode
+
+	gasCmdDeposit         = uint64(50000)  // gas used to deposit
+	gasCmdQueryMasterNode = uint64(5000)   // gas used to query masternode
+	gasCmdRecall          = uint64(50000)  // gas used to recallCmd
+	gasCmdQuit            = uint64(50000)  // gas used to quitCmd
+	gasCmdUpdateEndpoint  = uint64(50000)  // gas used to update endpoint
+	gasCmdProveLiveness   = uint64(50000)  // gas used to prove liveness
+	gasCmdSlash           = uint64(100000) // gas used to slash masternode
+	gasCmdGetMasternodes  = uint64(50000)  // gas used to list masternodes
+	gasCmdGetMasternode   = uint64(5000)   // gas used to get masternode info
 )
 
 var (
@@ -36,6 +63,19 @@ var (
 
 	depositLimit        = big.NewInt(0).Mul(common.ScdoToWen, big.NewInt(20000))
 	recallDistanceLimit = uint64(8640) // generate blocks in about one day
+
+	// MasternodeEpochLength is the number of blocks in an epoch, which is about one hour.
+	MasternodeEpochLength = uint64(360)
+	// MasternodeRewardPercent is the percent of block reward shared with masternodes.
+	MasternodeRewardPercent = int64(20)
+	// MasternodeSlashRewardPercent is the percent of slashed deposit paid to the reporter,
+	// and the rest is burned.
+	MasternodeSlashRewardPercent = int64(10)
+
+	maxEndpointLength = 256
+
+	masternodeListKey = crypto.HashBytes([]byte("masternodes"))
+	rewardPoolKey     = crypto.HashBytes([]byte("reward pool"))
 )
 
 var (
@@ -44,18 +84,119 @@ var (
 	ErrNotExist          = errors.New("this address is not masternode")
 	ErrNotQuit           = errors.New("address doesn't quit")
 	ErrNotEnoughDistance = errors.New("not enough distance")
+	ErrNotRecalled       = errors.New("deposit of this address is not recalled")
+	ErrAlreadyQuit       = errors.New("this address already quit")
+	ErrNotNodeOwner      = errors.New("only the masternode itself is allowed")
+	ErrInvalidEndpoint   = errors.New("invalid endpoint")
+	ErrNotRegistered     = errors.New("endpoint of masternode is not registered")
+	ErrInvalidEpoch      = errors.New("proof is not for current epoch")
+	ErrInvalidProof      = errors.New("invalid liveness proof signature")
+	ErrAlreadyProved     = errors.New("liveness already proved in current epoch")
+	ErrInvalidEvidence   = errors.New("invalid evidence, proofs should be of the same node and epoch with different blocks")
 
 	masternodeCommands = map[byte]*cmdInfo{
 		CmdDeposit:         &cmdInfo{gasCmdDeposit, deposit},
 		CmdQueryMasternode: &cmdInfo{gasCmdQueryMasterNode, queryMasternodeCmd},
 		CmdRecall:          {gasCmdRecall, recallCmd},
 		CmdQuit:            {gasCmdQuit, quitCmd},
+		CmdUpdateEndpoint:  {gasCmdUpdateEndpoint, updateEndpointCmd},
+		CmdProveLiveness:   {gasCmdProveLiveness, proveLivenessCmd},
+		CmdSlash:           {gasCmdSlash, slashCmd},
+		CmdGetMasternodes:  {gasCmdGetMasternodes, getMasternodesCmd},
+		CmdGetMasternode:   {gasCmdGetMasternode, getMasternodeCmd},
 	}
 )
 
 type masternodeInfo struct {
 	IsQuit    bool
 	QuitBlock uint64
+
+	// registry info of masternode with endpoint registered after fork
+	Node []*masternodeNode `rlp:"tail"`
+}
+
+type masternodeNode struct {
+	Endpoint       string
+	DepositBlock   uint64
+	LastProofEpoch uint64
+	LastProofHash  common.Hash
+}
+
+// node returns the registry info of masternode, or nil if not registered
+func (info *masternodeInfo) node() *masternodeNode {
+	if len(info.Node) == 0 {
+		return nil
+	}
+
+	return info.Node[0]
+}
+
+// MasternodeDeposit is the parameter to deposit after fork
+type MasternodeDeposit struct {
+	Endpoint string
+}
+
+// MasternodeProof is the liveness proof signed by masternode, which attests the hash
+// of the first block of the epoch.
+type MasternodeProof struct {
+	Node      common.Address
+	Epoch     uint64
+	BlockHash common.Hash
+	Signature crypto.Signature
+}
+
+// MasternodeEvidence is the evidence of misbehaviour, which is two proofs of
+// the same masternode and epoch, but attest different blocks.
+type MasternodeEvidence struct {
+	First  *MasternodeProof
+	Second *MasternodeProof
+}
+
+// Masternode is the registry info of masternode
+type Masternode struct {
+	Address        common.Address
+	Endpoint       string
+	DepositBlock   uint64
+	LastProofEpoch uint64
+	IsQuit         bool
+	QuitBlock      uint64
+}
+
+// NewMasternodeProof creates the liveness proof signed by the masternode key.
+func NewMasternodeProof(key *ecdsa.PrivateKey, epoch uint64, blockHash common.Hash) (*MasternodeProof, error) {
+	proof := &MasternodeProof{
+		Node:      *crypto.GetAddress(&key.PublicKey),
+		Epoch:     epoch,
+		BlockHash: blockHash,
+	}
+
+	sig, err := crypto.Sign(key, proof.Hash().Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	proof.Signature = *sig
+	return proof, nil
+}
+
+// Hash returns the hash of proof that is signed by masternode
+func (p *MasternodeProof) Hash() common.Hash {
+	return crypto.MustHash([]interface{}{p.Node, p.Epoch, p.BlockHash})
+}
+
+// Verify returns whether the proof is signed by the masternode
+func (p *MasternodeProof) Verify() bool {
+	return p.Signature.Verify(p.Node, p.Hash().Bytes())
+}
+
+// MasternodeDepositAmount returns the deposit required to register as masternode
+func MasternodeDepositAmount() *big.Int {
+	return new(big.Int).Set(depositLimit)
+}
+
+// MasternodeEpoch returns the epoch of block height
+func MasternodeEpoch(height uint64) uint64 {
+	return height / MasternodeEpochLength
 }
 
 func deposit(input []byte, context *Context) ([]byte, error) {
@@ -63,6 +204,17 @@ func deposit(input []byte, context *Context) ([]byte, error) {
 		return nil, ErrDepositNotRight
 	}
 
+	var param MasternodeDeposit
+	if context.masternodeEnabled() {
+		if err := json.Unmarshal(input, &param); err != nil {
+			return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+		}
+
+		if err := validateEndpoint(param.Endpoint); err != nil {
+			return nil, err
+		}
+	}
+
 	sender := context.tx.Data.From
 	info, err := QueryAddress(sender, context.statedb)
 	if err != nil {
@@ -73,12 +225,28 @@ func deposit(input []byte, context *Context) ([]byte, error) {
 		return nil, ErrAlreadyExist
 	}
 
+	// the deposit of quit masternode is lost if deposit again before fork
+	if info != nil && context.masternodeEnabled() {
+		return nil, ErrNotRecalled
+	}
+
 	info = &masternodeInfo{
 		IsQuit: false,
 	}
 
+	if context.masternodeEnabled() {
+		info.Node = []*masternodeNode{{Endpoint: param.Endpoint, DepositBlock: context.BlockHeader.Height}}
+		if err = addMasternode(sender, context.statedb); err != nil {
+			return nil, err
+		}
+	}
+
 	context.statedb.SetData(MasternodeContractAddress, crypto.MustHash(sender), common.SerializePanic(info))
 
+	if err = context.emit("MasternodeDeposited", sender, context.tx.Data.Amount); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -88,7 +256,8 @@ func queryMasternodeCmd(address []byte, context *Context) ([]byte, error) {
 		return nil, err
 	}
 
-	if info == nil && !info.IsQuit {
+	// it always answered false before fork
+	if info != nil && !info.IsQuit && context.masternodeEnabled() {
 		return ByteTrue, nil
 	}
 
@@ -99,25 +268,43 @@ func QueryAddress(address common.Address, statedb *state.Statedb) (*masternodeIn
 	return getInfo(address.Bytes(), statedb)
 }
 
+// recallCmd returns the deposit about one day after quit, which is returned to
+// the masternode after fork, or the sender before fork.
 func recallCmd(address []byte, context *Context) ([]byte, error) {
 	info, err := getInfo(address, context.statedb)
 	if err != nil {
 		return nil, err
 	}
 
-	if info == nil && !info.IsQuit {
+	if info == nil {
+		return nil, ErrNotExist
+	}
+
+	if !info.IsQuit && context.masternodeEnabled() {
 		return nil, ErrNotQuit
 	}
 
+	receiver := context.tx.Data.From
+	if context.masternodeEnabled() {
+		receiver = common.BytesToAddress(address)
+	}
+
 	distance := context.BlockHeader.Height - info.QuitBlock
 	if info.IsQuit && distance > recallDistanceLimit {
 		context.statedb.SetData(MasternodeContractAddress, crypto.MustHash(address), nil)
 		context.statedb.SubBalance(MasternodeContractAddress, depositLimit)
-		context.statedb.AddBalance(context.tx.Data.From, depositLimit)
+		if context.masternodeEnabled() {
+			context.statedb.CreateAccount(receiver)
+		}
+		context.statedb.AddBalance(receiver, depositLimit)
 	} else {
 		return nil, ErrNotEnoughDistance
 	}
 
+	if err = context.emit("MasternodeRecalled", common.BytesToAddress(address), receiver, depositLimit); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -146,19 +333,391 @@ func saveInfo(address []byte, statedb *state.Statedb, info *masternodeInfo) erro
 	return nil
 }
 
+// quitCmd quits the masternode, which is only allowed by the masternode itself after fork.
 func quitCmd(address []byte, context *Context) ([]byte, error) {
 	info, err := getInfo(address, context.statedb)
 	if err != nil {
 		return nil, err
 	}
 
+	if context.masternodeEnabled() {
+		if info == nil {
+			return nil, ErrNotExist
+		}
+
+		if info.IsQuit {
+			return nil, ErrAlreadyQuit
+		}
+
+		node := common.BytesToAddress(address)
+		if !node.Equal(context.tx.Data.From) {
+			return nil, ErrNotNodeOwner
+		}
+
+		if err = removeMasternode(node, context.statedb); err != nil {
+			return nil, err
+		}
+	}
+
 	if info != nil && !info.IsQuit {
 		info.IsQuit = true
 		info.QuitBlock = context.BlockHeader.Height
 		if err := saveInfo(address, context.statedb, info); err != nil {
 			return nil, err
 		}
+
+		if err := context.emit("MasternodeQuit", common.BytesToAddress(address), info.QuitBlock); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// updateEndpointCmd updates the endpoint of the sender masternode, and registers
+// the masternode that deposited before fork.
+func updateEndpointCmd(endpoint []byte, context *Context) ([]byte, error) {
+	if !context.masternodeEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	if err := validateEndpoint(string(endpoint)); err != nil {
+		return nil, err
+	}
+
+	sender := context.tx.Data.From
+	info, err := getActiveInfo(sender, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if node := info.node(); node != nil {
+		node.Endpoint = string(endpoint)
+	} else {
+		info.Node = []*masternodeNode{{Endpoint: string(endpoint), DepositBlock: context.BlockHeader.Height}}
+		if err = addMasternode(sender, context.statedb); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = saveInfo(sender.Bytes(), context.statedb, info); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("MasternodeEndpointUpdated", sender, string(endpoint)); err != nil {
+		return nil, err
 	}
 
 	return nil, nil
 }
+
+// proveLivenessCmd accepts the liveness proof of masternode in current epoch,
+// which could be submitted by anyone.
+func proveLivenessCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.masternodeEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var proof MasternodeProof
+	if err := json.Unmarshal(input, &proof); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	if proof.Epoch != MasternodeEpoch(context.BlockHeader.Height) {
+		return nil, ErrInvalidEpoch
+	}
+
+	if !proof.Verify() {
+		return nil, ErrInvalidProof
+	}
+
+	info, err := getActiveInfo(proof.Node, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	node := info.node()
+	if node == nil {
+		return nil, ErrNotRegistered
+	}
+
+	if node.LastProofEpoch == proof.Epoch {
+		return nil, ErrAlreadyProved
+	}
+
+	node.LastProofEpoch = proof.Epoch
+	node.LastProofHash = proof.BlockHash
+	if err = saveInfo(proof.Node.Bytes(), context.statedb, info); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("MasternodeProved", proof.Node, proof.Epoch); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// slashCmd slashes the deposit of masternode with the evidence of misbehaviour, and
+// pays a part of the deposit to the reporter. The masternode is removed at once.
+func slashCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.masternodeEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	var evidence MasternodeEvidence
+	if err := json.Unmarshal(input, &evidence); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal input, %s", err)
+	}
+
+	first, second := evidence.First, evidence.Second
+	if first == nil || second == nil || !first.Node.Equal(second.Node) || first.Epoch != second.Epoch || first.BlockHash == second.BlockHash {
+		return nil, ErrInvalidEvidence
+	}
+
+	if !first.Verify() || !second.Verify() {
+		return nil, ErrInvalidProof
+	}
+
+	address := first.Node
+	info, err := QueryAddress(address, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, ErrNotExist
+	}
+
+	// the proofs before deposit are not accepted
+	if node := info.node(); node != nil && first.Epoch < MasternodeEpoch(node.DepositBlock) {
+		return nil, ErrInvalidEvidence
+	}
+
+	if err = removeMasternode(address, context.statedb); err != nil {
+		return nil, err
+	}
+
+	reporter := context.tx.Data.From
+	reward := new(big.Int).Mul(depositLimit, big.NewInt(MasternodeSlashRewardPercent))
+	reward.Div(reward, big.NewInt(100))
+
+	context.statedb.SetData(MasternodeContractAddress, crypto.MustHash(address), nil)
+	context.statedb.SubBalance(MasternodeContractAddress, depositLimit)
+	context.statedb.CreateAccount(reporter)
+	context.statedb.AddBalance(reporter, reward)
+
+	if err = context.emit("MasternodeSlashed", address, reporter, depositLimit); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// getMasternodesCmd returns the active masternodes in JSON
+func getMasternodesCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.masternodeEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	nodes, err := GetMasternodes(context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(nodes)
+}
+
+// getMasternodeCmd returns the masternode info in JSON
+func getMasternodeCmd(address []byte, context *Context) ([]byte, error) {
+	if !context.masternodeEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	node, err := GetMasternode(common.BytesToAddress(address), context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if node == nil {
+		return nil, ErrNotExist
+	}
+
+	return json.Marshal(node)
+}
+
+// GetMasternode returns the masternode info of address, or nil if not masternode.
+func GetMasternode(address common.Address, statedb *state.Statedb) (*Masternode, error) {
+	info, err := QueryAddress(address, statedb)
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	result := &Masternode{
+		Address:   address,
+		IsQuit:    info.IsQuit,
+		QuitBlock: info.QuitBlock,
+	}
+
+	if node := info.node(); node != nil {
+		result.Endpoint = node.Endpoint
+		result.DepositBlock = node.DepositBlock
+		result.LastProofEpoch = node.LastProofEpoch
+	}
+
+	return result, nil
+}
+
+// GetMasternodes returns the active masternodes in the registry
+func GetMasternodes(statedb *state.Statedb) ([]*Masternode, error) {
+	addresses, err := getMasternodeList(statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*Masternode, 0, len(addresses))
+	for _, address := range addresses {
+		node, err := GetMasternode(address, statedb)
+		if err != nil {
+			return nil, err
+		}
+
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes, nil
+}
+
+// ShareMasternodeReward shares the block reward with masternodes after fork, and returns
+// the reward of miner. The share is pooled in the masternode contract, and the pool is
+// paid equally to the masternodes that proved liveness in the last epoch at the first
+// block of each epoch. The remainder of the division is left in the pool.
+func ShareMasternodeReward(statedb *state.Statedb, header *types.BlockHeader, reward *big.Int) (*big.Int, error) {
+	if header.Height < common.MasternodeForkHeight {
+		return reward, nil
+	}
+
+	addresses, err := getMasternodeList(statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(addresses) == 0 {
+		return reward, nil
+	}
+
+	pool := new(big.Int).SetBytes(statedb.GetData(MasternodeContractAddress, rewardPoolKey))
+
+	// pay the pool to the masternodes alive in the last epoch
+	if epoch := MasternodeEpoch(header.Height); header.Height%MasternodeEpochLength == 0 && epoch > 0 && pool.Sign() > 0 {
+		var alive []common.Address
+		for _, address := range addresses {
+			info, err := QueryAddress(address, statedb)
+			if err != nil {
+				return nil, err
+			}
+
+			// the listed address may have no info, e.g. recalled in the epoch
+			if info == nil {
+				continue
+			}
+
+			if node := info.node(); node != nil && node.LastProofEpoch == epoch-1 {
+				alive = append(alive, address)
+			}
+		}
+
+		if len(alive) > 0 {
+			share := new(big.Int).Div(pool, big.NewInt(int64(len(alive))))
+			for _, address := range alive {
+				statedb.SubBalance(MasternodeContractAddress, share)
+				statedb.CreateAccount(address)
+				statedb.AddBalance(address, share)
+				pool.Sub(pool, share)
+			}
+		}
+	}
+
+	share := new(big.Int).Mul(reward, big.NewInt(MasternodeRewardPercent))
+	share.Div(share, big.NewInt(100))
+	statedb.AddBalance(MasternodeContractAddress, share)
+	pool.Add(pool, share)
+	statedb.SetData(MasternodeContractAddress, rewardPoolKey, pool.Bytes())
+
+	return new(big.Int).Sub(reward, share), nil
+}
+
+// getActiveInfo returns the info of masternode that does not quit
+func getActiveInfo(address common.Address, statedb *state.Statedb) (*masternodeInfo, error) {
+	info, err := QueryAddress(address, statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil || info.IsQuit {
+		return nil, ErrNotExist
+	}
+
+	return info, nil
+}
+
+func getMasternodeList(statedb *state.Statedb) ([]common.Address, error) {
+	var addresses []common.Address
+	if value := statedb.GetData(MasternodeContractAddress, masternodeListKey); len(value) > 0 {
+		if err := common.Deserialize(value, &addresses); err != nil {
+			return nil, err
+		}
+	}
+
+	return addresses, nil
+}
+
+func saveMasternodeList(statedb *state.Statedb, addresses []common.Address) error {
+	value, err := common.Serialize(addresses)
+	if err != nil {
+		return err
+	}
+
+	statedb.SetData(MasternodeContractAddress, masternodeListKey, value)
+	return nil
+}
+
+func addMasternode(address common.Address, statedb *state.Statedb) error {
+	addresses, err := getMasternodeList(statedb)
+	if err != nil {
+		return err
+	}
+
+	statedb.CreateAccount(MasternodeContractAddress)
+	return saveMasternodeList(statedb, append(addresses, address))
+}
+
+func removeMasternode(address common.Address, statedb *state.Statedb) error {
+	addresses, err := getMasternodeList(statedb)
+	if err != nil {
+		return err
+	}
+
+	for i, addr := range addresses {
+		if addr.Equal(address) {
+			return saveMasternodeList(statedb, append(addresses[:i], addresses[i+1:]...))
+		}
+	}
+
+	return nil
+}
+
+func validateEndpoint(endpoint string) error {
+	if len(endpoint) == 0 || len(endpoint) > maxEndpointLength {
+		return ErrInvalidEndpoint
+	}
+
+	return nil
+}
+
+// masternodeEnabled returns whether the masternode registry and rewards are enabled after fork
+func (ctx *Context) masternodeEnabled() bool {
+	return ctx.BlockHeader.Height >= common.MasternodeForkHeight
+}
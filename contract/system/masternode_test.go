@@ -0,0 +1,261 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMasternodeContext(db database.Database, from common.Address, amount *big.Int) *Context {
+	context := newTestContext(db, MasternodeContractAddress)
+	context.tx.Data.From = from
+	context.tx.Data.Amount = amount
+	context.BlockHeader.Height = common.MasternodeForkHeight
+	context.statedb.CreateAccount(from)
+	context.statedb.AddBalance(MasternodeContractAddress, amount)
+
+	return context
+}
+
+func runMasternodeCmd(context *Context, cmd byte, param interface{}) ([]byte, error) {
+	input, ok := param.([]byte)
+	if !ok {
+		var err error
+		if input, err = json.Marshal(param); err != nil {
+			return nil, err
+		}
+	}
+
+	return GetContractByAddress(MasternodeContractAddress, context.BlockHeader.Height).Run(append([]byte{cmd}, input...), context)
+}
+
+// depositTestMasternode deposits the masternode of a new key pair after fork
+func depositTestMasternode(t *testing.T, db database.Database) (*Context, common.Address, *ecdsa.PrivateKey) {
+	node, key := crypto.MustGenerateShardKeyPair(1)
+	context := newMasternodeContext(db, *node, depositLimit)
+
+	_, err := runMasternodeCmd(context, CmdDeposit, MasternodeDeposit{"snode://node@127.0.0.1:8057[1]"})
+	assert.NoError(t, err)
+
+	return context, *node, key
+}
+
+func Test_Masternode_Query(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	// unknown address
+	context := newMasternodeContext(db, *crypto.MustGenerateShardAddress(1), big.NewInt(0))
+	result, err := runMasternodeCmd(context, CmdQueryMasternode, context.tx.Data.From.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, ByteFalse, result)
+
+	_, err = runMasternodeCmd(context, CmdRecall, context.tx.Data.From.Bytes())
+	assert.Equal(t, ErrNotExist, err)
+
+	context, node, _ := depositTestMasternode(t, db)
+	result, err = runMasternodeCmd(context, CmdQueryMasternode, node.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, ByteTrue, result)
+
+	// endpoint is required after fork
+	other := newMasternodeContext(db, *crypto.MustGenerateShardAddress(1), depositLimit)
+	other.statedb = context.statedb
+	_, err = runMasternodeCmd(other, CmdDeposit, MasternodeDeposit{})
+	assert.Equal(t, ErrInvalidEndpoint, err)
+
+	nodes, err := GetMasternodes(context.statedb)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Masternode{{
+		Address:      node,
+		Endpoint:     "snode://node@127.0.0.1:8057[1]",
+		DepositBlock: common.MasternodeForkHeight,
+	}}, nodes)
+}
+
+func Test_Masternode_QuitAndRecall(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context, node, _ := depositTestMasternode(t, db)
+	context.tx.Data.Amount = big.NewInt(0)
+
+	// only the masternode itself could quit
+	sender := *crypto.MustGenerateShardAddress(1)
+	context.statedb.CreateAccount(sender)
+	context.tx.Data.From = sender
+	_, err := runMasternodeCmd(context, CmdQuit, node.Bytes())
+	assert.Equal(t, ErrNotNodeOwner, err)
+
+	context.tx.Data.From = node
+	_, err = runMasternodeCmd(context, CmdQuit, node.Bytes())
+	assert.NoError(t, err)
+
+	nodes, err := GetMasternodes(context.statedb)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(nodes))
+
+	_, err = runMasternodeCmd(context, CmdDeposit, MasternodeDeposit{"snode://node@127.0.0.1:8057[1]"})
+	assert.Equal(t, ErrDepositNotRight, err)
+
+	// the deposit is returned to the masternode whoever recalls
+	context.tx.Data.From = sender
+	context.BlockHeader.Height += recallDistanceLimit + 1
+	_, err = runMasternodeCmd(context, CmdRecall, node.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, depositLimit, context.statedb.GetBalance(node))
+	assert.Equal(t, big.NewInt(0), context.statedb.GetBalance(sender))
+}
+
+func Test_Masternode_ProveLiveness(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context, node, key := depositTestMasternode(t, db)
+	epoch := MasternodeEpoch(context.BlockHeader.Height)
+
+	proof, err := NewMasternodeProof(key, epoch+1, crypto.MustHash("block"))
+	assert.NoError(t, err)
+	_, err = runMasternodeCmd(context, CmdProveLiveness, proof)
+	assert.Equal(t, ErrInvalidEpoch, err)
+
+	// forged proof
+	proof, err = NewMasternodeProof(key, epoch, crypto.MustHash("block"))
+	assert.NoError(t, err)
+	forged := *proof
+	forged.BlockHash = crypto.MustHash("forged")
+	_, err = runMasternodeCmd(context, CmdProveLiveness, forged)
+	assert.Equal(t, ErrInvalidProof, err)
+
+	_, err = runMasternodeCmd(context, CmdProveLiveness, proof)
+	assert.NoError(t, err)
+	_, err = runMasternodeCmd(context, CmdProveLiveness, proof)
+	assert.Equal(t, ErrAlreadyProved, err)
+
+	info, err := GetMasternode(node, context.statedb)
+	assert.NoError(t, err)
+	assert.Equal(t, epoch, info.LastProofEpoch)
+}
+
+func Test_Masternode_ShareReward(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	header := newTestBlockHeader()
+	header.Height = common.MasternodeForkHeight - 1
+	context := newTestContext(db, MasternodeContractAddress)
+	reward := big.NewInt(1000)
+
+	// the miner takes all reward without masternodes or before fork
+	minerReward, err := ShareMasternodeReward(context.statedb, header, reward)
+	assert.NoError(t, err)
+	assert.Equal(t, reward, minerReward)
+
+	context, node, key := depositTestMasternode(t, db)
+	minerReward, err = ShareMasternodeReward(context.statedb, header, reward)
+	assert.NoError(t, err)
+	assert.Equal(t, reward, minerReward)
+
+	// prove liveness in the epoch of fork height, and share the reward
+	proof, err := NewMasternodeProof(key, MasternodeEpoch(context.BlockHeader.Height), crypto.MustHash("block"))
+	assert.NoError(t, err)
+	_, err = runMasternodeCmd(context, CmdProveLiveness, proof)
+	assert.NoError(t, err)
+
+	header.Height = common.MasternodeForkHeight
+	minerReward, err = ShareMasternodeReward(context.statedb, header, reward)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(800), minerReward)
+	assert.Equal(t, big.NewInt(0), context.statedb.GetBalance(node))
+
+	// the pool is paid to the alive masternodes at the first block of next epoch
+	header.Height = (proof.Epoch + 1) * MasternodeEpochLength
+	minerReward, err = ShareMasternodeReward(context.statedb, header, reward)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(800), minerReward)
+	assert.Equal(t, big.NewInt(200), context.statedb.GetBalance(node))
+	assert.Equal(t, new(big.Int).Add(depositLimit, big.NewInt(200)), context.statedb.GetBalance(MasternodeContractAddress))
+
+	// no payment without liveness proof, and the listed address without info is skipped
+	assert.NoError(t, addMasternode(*crypto.MustGenerateShardAddress(1), context.statedb))
+	header.Height += MasternodeEpochLength
+	_, err = ShareMasternodeReward(context.statedb, header, reward)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(200), context.statedb.GetBalance(node))
+}
+
+func Test_Masternode_Slash(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context, node, key := depositTestMasternode(t, db)
+	epoch := MasternodeEpoch(context.BlockHeader.Height)
+	context.tx.Data.Amount = big.NewInt(0)
+	reporter := *crypto.MustGenerateShardAddress(1)
+	context.tx.Data.From = reporter
+
+	first, err := NewMasternodeProof(key, epoch, crypto.MustHash("block"))
+	assert.NoError(t, err)
+	second, err := NewMasternodeProof(key, epoch, crypto.MustHash("block"))
+	assert.NoError(t, err)
+
+	// same block is not misbehaviour
+	_, err = runMasternodeCmd(context, CmdSlash, MasternodeEvidence{first, second})
+	assert.Equal(t, ErrInvalidEvidence, err)
+
+	second, err = NewMasternodeProof(key, epoch, crypto.MustHash("fork block"))
+	assert.NoError(t, err)
+	_, err = runMasternodeCmd(context, CmdSlash, MasternodeEvidence{first, second})
+	assert.NoError(t, err)
+
+	assert.Equal(t, big.NewInt(0).Div(depositLimit, big.NewInt(10)), context.statedb.GetBalance(reporter))
+	assert.Equal(t, big.NewInt(0), context.statedb.GetBalance(MasternodeContractAddress))
+
+	info, err := GetMasternode(node, context.statedb)
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+
+	_, err = runMasternodeCmd(context, CmdSlash, MasternodeEvidence{first, second})
+	assert.Equal(t, ErrNotExist, err)
+}
+
+func Test_Masternode_BeforeFork(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	node := *crypto.MustGenerateShardAddress(1)
+	context := newMasternodeContext(db, node, depositLimit)
+	context.BlockHeader.Height = common.MasternodeForkHeight - 1
+
+	_, err := runMasternodeCmd(context, CmdDeposit, []byte{})
+	assert.NoError(t, err)
+
+	// not listed until the endpoint is registered after fork
+	_, err = runMasternodeCmd(context, CmdUpdateEndpoint, []byte("snode://node@127.0.0.1:8057[1]"))
+	assert.Equal(t, errInvalidCommand, err)
+
+	context.BlockHeader.Height = common.MasternodeForkHeight
+	nodes, err := GetMasternodes(context.statedb)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(nodes))
+
+	_, err = runMasternodeCmd(context, CmdUpdateEndpoint, []byte("snode://node@127.0.0.1:8057[1]"))
+	assert.NoError(t, err)
+
+	nodes, err = GetMasternodes(context.statedb)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nodes))
+	assert.Equal(t, node, nodes[0].Address)
+}
@@ -0,0 +1,579 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bls"
+)
+
+// After StakingForkHeight, the validators of bft consensus bond stake in the staking
+// contract to weight their votes. The bonded stake becomes active after the bonding period,
+// and the unbonded stake is withdrawable after the unbonding period, so that the stake
+// keeps backing the votes cast before unbonding. The voting power of validator is its
+// active stake in units of StakingUnit, and the validator sets are refreshed at every
+// epoch boundary with the stakes of the epoch block.
+//
+// The validators also register the BLS public keys with the proof of possession, which
+// verify the aggregated commit seals of block headers after BLSSealForkHeight.
+//
+// The stake of validator proven to double sign by the evidence in block header is slashed
+// when the block is applied.
+
+const (
+	// CmdBondStake bond the tx amount as stake of sender
+	CmdBondStake byte = iota
+	// CmdUnbondStake unbond the active stake of sender
+	CmdUnbondStake
+	// CmdWithdrawStake withdraw the unbonded stake of sender after unbonding period
+	CmdWithdrawStake
+	// CmdGetStake get the stake of address
+	CmdGetStake
+	// CmdGetStakes list the stakes of all stakers
+	CmdGetStakes
+	// CmdRegisterBLSKey register the BLS public key of sender with the proof of possession
+	CmdRegisterBLSKey
+	// CmdGetBLSKey get the registered BLS public key of address
+	CmdGetBLSKey
+
+	gasCmdBond      = uint64(50000) // gas used to bond stake
+	gasCmdUnbond    = uint64(50000) // gas used to unbond stake
+	gasCmdWithdraw  = uint64(50000) // gas used to withdraw stake
+	gasCmdGetStake  = uint64(5000)  // gas used to get stake
+	gasCmdGetStakes = uint64(50000) // gas used to list stakes
+	gasCmdRegister  = uint64(80000) // gas used to register BLS public key, which verifies the proof with pairings
+	gasCmdGetBLSKey = uint64(5000)  // gas used to get BLS public key
+)
+
+var (
+	// StakingUnit is the stake of one voting power
+	StakingUnit = new(big.Int).Set(common.ScdoToWen)
+	// StakingBondingPeriod is the number of blocks before the bonded stake becomes active, which is about one hour.
+	StakingBondingPeriod = uint64(360)
+	// StakingUnbondingPeriod is the number of blocks before the unbonded stake is withdrawable, which is about one day.
+	StakingUnbondingPeriod = uint64(8640)
+	// StakingSlashRewardPercent is the percent of slashed stake paid to the proposer of the block
+	// that includes the double sign evidence, and the rest is burned.
+	StakingSlashRewardPercent = int64(10)
+
+	stakerListKey = crypto.HashBytes([]byte("stakers"))
+	blsKeyPrefix  = []byte("bls")
+)
+
+var (
+	ErrStakeTooSmall     = errors.New("stake amount should be at least one staking unit")
+	ErrInsufficientStake = errors.New("active stake is not enough to unbond")
+	ErrNothingToWithdraw = errors.New("no unbonded stake is withdrawable")
+	ErrNotStaker         = errors.New("this address has no stake")
+	ErrInvalidBLSKey     = errors.New("invalid BLS public key or proof of possession")
+	ErrNoBLSKey          = errors.New("this address has no registered BLS public key")
+
+	stakingCommands = map[byte]*cmdInfo{
+		CmdBondStake:      {gasCmdBond, bondCmd},
+		CmdUnbondStake:    {gasCmdUnbond, unbondCmd},
+		CmdWithdrawStake:  {gasCmdWithdraw, withdrawCmd},
+		CmdGetStake:       {gasCmdGetStake, getStakeCmd},
+		CmdGetStakes:      {gasCmdGetStakes, getStakesCmd},
+		CmdRegisterBLSKey: {gasCmdRegister, registerBLSKeyCmd},
+		CmdGetBLSKey:      {gasCmdGetBLSKey, getBLSKeyCmd},
+	}
+)
+
+// stakeEntry is the stake that changes state at the height
+type stakeEntry struct {
+	Amount *big.Int
+	Height uint64
+}
+
+type stakeInfo struct {
+	Active    *big.Int
+	Bonding   []*stakeEntry // active at the entry height
+	Unbonding []*stakeEntry // withdrawable at the entry height
+}
+
+// Stake is the stake info of staker at a block height
+type Stake struct {
+	Address     common.Address
+	Active      *big.Int // stake that backs the voting power
+	Bonding     *big.Int // stake that is not active yet
+	Unbonding   *big.Int // stake that is unbonded but not withdrawn
+	VotingPower uint64
+}
+
+// settle moves the bonding entries matured at height into the active stake
+func (info *stakeInfo) settle(height uint64) {
+	var bonding []*stakeEntry
+	for _, entry := range info.Bonding {
+		if entry.Height <= height {
+			info.Active.Add(info.Active, entry.Amount)
+		} else {
+			bonding = append(bonding, entry)
+		}
+	}
+
+	info.Bonding = bonding
+}
+
+func (info *stakeInfo) isEmpty() bool {
+	return info.Active.Sign() == 0 && len(info.Bonding) == 0 && len(info.Unbonding) == 0
+}
+
+// StakingVotingPower returns the voting power of the active stake
+func StakingVotingPower(active *big.Int) uint64 {
+	power := new(big.Int).Div(active, StakingUnit)
+	if !power.IsUint64() {
+		return ^uint64(0)
+	}
+
+	return power.Uint64()
+}
+
+func bondCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	amount := context.tx.Data.Amount
+	if amount.Cmp(StakingUnit) < 0 {
+		return nil, ErrStakeTooSmall
+	}
+
+	staker := context.tx.Data.From
+	info, err := getStakeInfo(staker, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		info = &stakeInfo{Active: big.NewInt(0)}
+		if err = addStaker(staker, context.statedb); err != nil {
+			return nil, err
+		}
+	}
+
+	height := context.BlockHeader.Height + StakingBondingPeriod
+	info.settle(context.BlockHeader.Height)
+	info.Bonding = append(info.Bonding, &stakeEntry{new(big.Int).Set(amount), height})
+	if err = saveStakeInfo(staker, context.statedb, info); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("StakeBonded", staker, amount, height); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// unbondCmd unbonds the active stake of sender, the input is the amount in big-endian bytes.
+func unbondCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	amount := new(big.Int).SetBytes(input)
+	if amount.Sign() == 0 {
+		return nil, ErrStakeTooSmall
+	}
+
+	staker := context.tx.Data.From
+	info, err := getStakeInfo(staker, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, ErrNotStaker
+	}
+
+	info.settle(context.BlockHeader.Height)
+	if info.Active.Cmp(amount) < 0 {
+		return nil, ErrInsufficientStake
+	}
+
+	height := context.BlockHeader.Height + StakingUnbondingPeriod
+	info.Active.Sub(info.Active, amount)
+	info.Unbonding = append(info.Unbonding, &stakeEntry{amount, height})
+	if err = saveStakeInfo(staker, context.statedb, info); err != nil {
+		return nil, err
+	}
+
+	if err = context.emit("StakeUnbonded", staker, amount, height); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// withdrawCmd pays the unbonded stake of sender that passed the unbonding period, and
+// removes the staker if it has no stake left.
+func withdrawCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	staker := context.tx.Data.From
+	info, err := getStakeInfo(staker, context.statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		return nil, ErrNotStaker
+	}
+
+	info.settle(context.BlockHeader.Height)
+	amount := big.NewInt(0)
+	var unbonding []*stakeEntry
+	for _, entry := range info.Unbonding {
+		if entry.Height <= context.BlockHeader.Height {
+			amount.Add(amount, entry.Amount)
+		} else {
+			unbonding = append(unbonding, entry)
+		}
+	}
+
+	if amount.Sign() == 0 {
+		return nil, ErrNothingToWithdraw
+	}
+
+	info.Unbonding = unbonding
+	if info.isEmpty() {
+		context.statedb.SetData(StakingContractAddress, crypto.MustHash(staker), nil)
+		if err = removeStaker(staker, context.statedb); err != nil {
+			return nil, err
+		}
+	} else if err = saveStakeInfo(staker, context.statedb, info); err != nil {
+		return nil, err
+	}
+
+	context.statedb.SubBalance(StakingContractAddress, amount)
+	context.statedb.AddBalance(staker, amount)
+
+	if err = context.emit("StakeWithdrawn", staker, amount); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// getStakeCmd returns the stake of address in JSON
+func getStakeCmd(address []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	stake, err := GetStake(common.BytesToAddress(address), context.statedb, context.BlockHeader.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	if stake == nil {
+		return nil, ErrNotStaker
+	}
+
+	return json.Marshal(stake)
+}
+
+// getStakesCmd returns the stakes of all stakers in JSON
+func getStakesCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	stakes, err := GetStakes(context.statedb, context.BlockHeader.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(stakes)
+}
+
+// GetStake returns the stake of address at block height, or nil if no stake.
+func GetStake(address common.Address, statedb *state.Statedb, height uint64) (*Stake, error) {
+	info, err := getStakeInfo(address, statedb)
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	info.settle(height)
+	stake := &Stake{
+		Address:     address,
+		Active:      info.Active,
+		Bonding:     big.NewInt(0),
+		Unbonding:   big.NewInt(0),
+		VotingPower: StakingVotingPower(info.Active),
+	}
+
+	for _, entry := range info.Bonding {
+		stake.Bonding.Add(stake.Bonding, entry.Amount)
+	}
+
+	for _, entry := range info.Unbonding {
+		stake.Unbonding.Add(stake.Unbonding, entry.Amount)
+	}
+
+	return stake, nil
+}
+
+// GetStakes returns the stakes of all stakers at block height
+func GetStakes(statedb *state.Statedb, height uint64) ([]*Stake, error) {
+	addresses, err := getStakerList(statedb)
+	if err != nil {
+		return nil, err
+	}
+
+	stakes := make([]*Stake, 0, len(addresses))
+	for _, address := range addresses {
+		stake, err := GetStake(address, statedb, height)
+		if err != nil {
+			return nil, err
+		}
+
+		if stake != nil {
+			stakes = append(stakes, stake)
+		}
+	}
+
+	return stakes, nil
+}
+
+// GetVotingPowers returns the voting powers of the validators at block height. The validators
+// without active stake have zero voting power, and nil is returned if none of them has stake.
+func GetVotingPowers(statedb *state.Statedb, height uint64, validators []common.Address) (map[common.Address]uint64, error) {
+	if height < common.StakingForkHeight {
+		return nil, nil
+	}
+
+	powers := make(map[common.Address]uint64)
+	total := uint64(0)
+	for _, validator := range validators {
+		stake, err := GetStake(validator, statedb, height)
+		if err != nil {
+			return nil, err
+		}
+
+		if stake != nil && stake.VotingPower > 0 {
+			powers[validator] = stake.VotingPower
+			total += stake.VotingPower
+		}
+	}
+
+	if total == 0 {
+		return nil, nil
+	}
+
+	return powers, nil
+}
+
+// registerBLSKeyCmd registers the BLS public key of sender, the input is the public key
+// followed by the proof of possession. The registered key is replaced if any.
+func registerBLSKeyCmd(input []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	if len(input) != bls.PublicKeySize+bls.SignatureSize {
+		return nil, ErrInvalidBLSKey
+	}
+
+	pk, err := bls.PublicKeyFromBytes(input[:bls.PublicKeySize])
+	if err != nil {
+		return nil, ErrInvalidBLSKey
+	}
+
+	proof, err := bls.SignatureFromBytes(input[bls.PublicKeySize:])
+	if err != nil || !pk.VerifyPossession(proof) {
+		return nil, ErrInvalidBLSKey
+	}
+
+	staker := context.tx.Data.From
+	key := pk.Bytes()
+	context.statedb.CreateAccount(StakingContractAddress)
+	context.statedb.SetData(StakingContractAddress, blsKeyHash(staker), key)
+
+	if err = context.emit("BLSKeyRegistered", staker, key); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// getBLSKeyCmd returns the registered BLS public key of address
+func getBLSKeyCmd(address []byte, context *Context) ([]byte, error) {
+	if !context.stakingEnabled() {
+		return nil, errInvalidCommand
+	}
+
+	key := GetBLSPublicKey(common.BytesToAddress(address), context.statedb)
+	if len(key) == 0 {
+		return nil, ErrNoBLSKey
+	}
+
+	return key, nil
+}
+
+// GetBLSPublicKey returns the registered BLS public key of address, or nil if not registered.
+func GetBLSPublicKey(address common.Address, statedb *state.Statedb) []byte {
+	return statedb.GetData(StakingContractAddress, blsKeyHash(address))
+}
+
+// GetBLSPublicKeys returns the registered BLS public keys of validators, the validators
+// without registered key are not included.
+func GetBLSPublicKeys(statedb *state.Statedb, validators []common.Address) map[common.Address][]byte {
+	keys := make(map[common.Address][]byte)
+	for _, validator := range validators {
+		if key := GetBLSPublicKey(validator, statedb); len(key) > 0 {
+			keys[validator] = key
+		}
+	}
+
+	return keys
+}
+
+// SlashDoubleSigners slashes all the stake of validators proven to double sign by the evidence
+// in header, pays a part of the slashed stake to the proposer and burns the rest. It returns
+// the total slashed stake.
+func SlashDoubleSigners(statedb *state.Statedb, header *types.BlockHeader, proposer common.Address) (*big.Int, error) {
+	slashed := big.NewInt(0)
+	if header.Height < common.StakingForkHeight {
+		return slashed, nil
+	}
+
+	evidence, err := types.HeaderEvidence(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ev := range evidence {
+		vote, err := ev.Verify()
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := getStakeInfo(vote.Signer, statedb)
+		if err != nil {
+			return nil, err
+		}
+
+		if info == nil {
+			continue
+		}
+
+		slashed.Add(slashed, info.Active)
+		for _, entry := range append(info.Bonding, info.Unbonding...) {
+			slashed.Add(slashed, entry.Amount)
+		}
+
+		statedb.SetData(StakingContractAddress, crypto.MustHash(vote.Signer), nil)
+		if err = removeStaker(vote.Signer, statedb); err != nil {
+			return nil, err
+		}
+	}
+
+	if slashed.Sign() == 0 {
+		return slashed, nil
+	}
+
+	reward := new(big.Int).Mul(slashed, big.NewInt(StakingSlashRewardPercent))
+	reward.Div(reward, big.NewInt(100))
+
+	statedb.SubBalance(StakingContractAddress, slashed)
+	statedb.CreateAccount(proposer)
+	statedb.AddBalance(proposer, reward)
+
+	return slashed, nil
+}
+
+func blsKeyHash(address common.Address) common.Hash {
+	return crypto.HashBytes(blsKeyPrefix, address.Bytes())
+}
+
+func getStakeInfo(address common.Address, statedb *state.Statedb) (*stakeInfo, error) {
+	value := statedb.GetData(StakingContractAddress, crypto.MustHash(address))
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	var info stakeInfo
+	if err := common.Deserialize(value, &info); err != nil {
+		return nil, err
+	}
+
+	if info.Active == nil {
+		info.Active = big.NewInt(0)
+	}
+
+	return &info, nil
+}
+
+func saveStakeInfo(address common.Address, statedb *state.Statedb, info *stakeInfo) error {
+	value, err := common.Serialize(info)
+	if err != nil {
+		return err
+	}
+
+	statedb.SetData(StakingContractAddress, crypto.MustHash(address), value)
+	return nil
+}
+
+func getStakerList(statedb *state.Statedb) ([]common.Address, error) {
+	var addresses []common.Address
+	if value := statedb.GetData(StakingContractAddress, stakerListKey); len(value) > 0 {
+		if err := common.Deserialize(value, &addresses); err != nil {
+			return nil, err
+		}
+	}
+
+	return addresses, nil
+}
+
+func saveStakerList(statedb *state.Statedb, addresses []common.Address) error {
+	value, err := common.Serialize(addresses)
+	if err != nil {
+		return err
+	}
+
+	statedb.SetData(StakingContractAddress, stakerListKey, value)
+	return nil
+}
+
+func addStaker(address common.Address, statedb *state.Statedb) error {
+	addresses, err := getStakerList(statedb)
+	if err != nil {
+		return err
+	}
+
+	statedb.CreateAccount(StakingContractAddress)
+	return saveStakerList(statedb, append(addresses, address))
+}
+
+func removeStaker(address common.Address, statedb *state.Statedb) error {
+	addresses, err := getStakerList(statedb)
+	if err != nil {
+		return err
+	}
+
+	for i, addr := range addresses {
+		if addr.Equal(address) {
+			return saveStakerList(statedb, append(addresses[:i], addresses[i+1:]...))
+		}
+	}
+
+	return nil
+}
+
+// stakingEnabled returns whether the staking contract is enabled after fork
+func (ctx *Context) stakingEnabled() bool {
+	return ctx.BlockHeader.Height >= common.StakingForkHeight
+}
@@ -0,0 +1,235 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package system
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bls"
+	"github.com/scdoproject/go-stem/database"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStakingContext(db database.Database, from common.Address, amount *big.Int, height uint64) *Context {
+	context := newTestContext(db, StakingContractAddress)
+	context.tx.Data.From = from
+	context.tx.Data.Amount = amount
+	context.BlockHeader.Height = height
+	context.statedb.CreateAccount(from)
+	context.statedb.AddBalance(StakingContractAddress, amount)
+
+	return context
+}
+
+func runStakingCmd(context *Context, cmd byte, input []byte) ([]byte, error) {
+	return GetContractByAddress(StakingContractAddress, common.StakingForkHeight).Run(append([]byte{cmd}, input...), context)
+}
+
+func Test_Staking_BeforeFork(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newStakingContext(db, *crypto.MustGenerateShardAddress(1), StakingUnit, common.StakingForkHeight-1)
+	_, err := runStakingCmd(context, CmdBondStake, nil)
+	assert.Equal(t, errInvalidCommand, err)
+}
+
+func Test_Staking_BondAndUnbond(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	staker := *crypto.MustGenerateShardAddress(1)
+	height := uint64(common.StakingForkHeight)
+	amount := new(big.Int).Mul(StakingUnit, big.NewInt(10))
+
+	// too small
+	context := newStakingContext(db, staker, big.NewInt(1), height)
+	_, err := runStakingCmd(context, CmdBondStake, nil)
+	assert.Equal(t, ErrStakeTooSmall, err)
+
+	// bond stake, which is not active in bonding period
+	context = newStakingContext(db, staker, amount, height)
+	_, err = runStakingCmd(context, CmdBondStake, nil)
+	assert.NoError(t, err)
+
+	stake, err := GetStake(staker, context.statedb, height+StakingBondingPeriod-1)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), stake.Active)
+	assert.Equal(t, amount, stake.Bonding)
+	assert.Equal(t, uint64(0), stake.VotingPower)
+
+	powers, err := GetVotingPowers(context.statedb, height+StakingBondingPeriod-1, []common.Address{staker})
+	assert.NoError(t, err)
+	assert.Nil(t, powers)
+
+	// active after bonding period
+	height += StakingBondingPeriod
+	powers, err = GetVotingPowers(context.statedb, height, []common.Address{staker, *crypto.MustGenerateShardAddress(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, map[common.Address]uint64{staker: 10}, powers)
+
+	// unbond more than active stake
+	context.BlockHeader.Height = height
+	_, err = runStakingCmd(context, CmdUnbondStake, new(big.Int).Add(amount, big.NewInt(1)).Bytes())
+	assert.Equal(t, ErrInsufficientStake, err)
+
+	_, err = runStakingCmd(context, CmdUnbondStake, StakingUnit.Bytes())
+	assert.NoError(t, err)
+
+	stake, err = GetStake(staker, context.statedb, height)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), stake.VotingPower)
+	assert.Equal(t, StakingUnit, stake.Unbonding)
+
+	// not withdrawable in unbonding period
+	context.BlockHeader.Height = height + StakingUnbondingPeriod - 1
+	_, err = runStakingCmd(context, CmdWithdrawStake, nil)
+	assert.Equal(t, ErrNothingToWithdraw, err)
+
+	balance := context.statedb.GetBalance(staker)
+	context.BlockHeader.Height = height + StakingUnbondingPeriod
+	_, err = runStakingCmd(context, CmdWithdrawStake, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Add(balance, StakingUnit), context.statedb.GetBalance(staker))
+
+	stakes, err := GetStakes(context.statedb, context.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(stakes))
+	assert.Equal(t, big.NewInt(0), stakes[0].Unbonding)
+}
+
+func Test_Staking_WithdrawAll(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	staker := *crypto.MustGenerateShardAddress(1)
+	height := uint64(common.StakingForkHeight)
+	context := newStakingContext(db, staker, StakingUnit, height)
+	_, err := runStakingCmd(context, CmdBondStake, nil)
+	assert.NoError(t, err)
+
+	// unbond before active
+	_, err = runStakingCmd(context, CmdUnbondStake, StakingUnit.Bytes())
+	assert.Equal(t, ErrInsufficientStake, err)
+
+	context.BlockHeader.Height = height + StakingBondingPeriod
+	_, err = runStakingCmd(context, CmdUnbondStake, StakingUnit.Bytes())
+	assert.NoError(t, err)
+
+	context.BlockHeader.Height += StakingUnbondingPeriod
+	_, err = runStakingCmd(context, CmdWithdrawStake, nil)
+	assert.NoError(t, err)
+
+	// staker is removed
+	stake, err := GetStake(staker, context.statedb, context.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.Nil(t, stake)
+
+	stakes, err := GetStakes(context.statedb, context.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(stakes))
+
+	_, err = runStakingCmd(context, CmdGetStake, staker.Bytes())
+	assert.Equal(t, ErrNotStaker, err)
+}
+
+func Test_Staking_RegisterBLSKey(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	validator := *crypto.MustGenerateShardAddress(1)
+	context := newStakingContext(db, validator, big.NewInt(0), common.StakingForkHeight)
+	sk := bls.DeriveKey(validator.Bytes())
+	pk := sk.PublicKey().Bytes()
+
+	// proof of another key
+	input := append(pk, bls.DeriveKey([]byte("other")).ProvePossession().Bytes()...)
+	_, err := runStakingCmd(context, CmdRegisterBLSKey, input)
+	assert.Equal(t, ErrInvalidBLSKey, err)
+
+	_, err = runStakingCmd(context, CmdGetBLSKey, validator.Bytes())
+	assert.Equal(t, ErrNoBLSKey, err)
+
+	input = append(pk, sk.ProvePossession().Bytes()...)
+	_, err = runStakingCmd(context, CmdRegisterBLSKey, input)
+	assert.NoError(t, err)
+
+	key, err := runStakingCmd(context, CmdGetBLSKey, validator.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, pk, key)
+
+	keys := GetBLSPublicKeys(context.statedb, []common.Address{validator, *crypto.MustGenerateShardAddress(1)})
+	assert.Equal(t, map[common.Address][]byte{validator: pk}, keys)
+}
+
+func Test_Staking_SlashDoubleSigners(t *testing.T) {
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	_, key := crypto.MustGenerateShardKeyPair(1)
+	staker := *crypto.GetAddress(&key.PublicKey)
+	height := uint64(common.StakingForkHeight)
+	amount := new(big.Int).Mul(StakingUnit, big.NewInt(10))
+	context := newStakingContext(db, staker, amount, height)
+	_, err := runStakingCmd(context, CmdBondStake, nil)
+	assert.NoError(t, err)
+
+	// sign commits of two proposals in the same round
+	var payloads [][]byte
+	for _, digest := range []string{"a", "b"} {
+		subject, err := rlp.EncodeToBytes([]interface{}{[]interface{}{big.NewInt(0), new(big.Int).SetUint64(height)}, common.StringToHash(digest)})
+		assert.NoError(t, err)
+
+		msg := []interface{}{uint64(2), subject, staker, []byte{}, []byte{}}
+		data, err := rlp.EncodeToBytes(msg)
+		assert.NoError(t, err)
+		sig, err := crypto.Sign(key, crypto.Keccak256(data))
+		assert.NoError(t, err)
+		msg[3] = sig.Sig
+
+		payload, err := rlp.EncodeToBytes(msg)
+		assert.NoError(t, err)
+		payloads = append(payloads, payload)
+	}
+
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{
+		Validators:    []common.Address{staker},
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+		Evidence:      []*types.DoubleSignEvidence{types.NewDoubleSignEvidence(payloads[0], payloads[1])},
+	})
+	assert.NoError(t, err)
+
+	header := &types.BlockHeader{
+		Height:    height + 1,
+		Consensus: types.IstanbulConsensus,
+		ExtraData: append(make([]byte, types.IstanbulExtraVanity), extra...),
+	}
+
+	proposer := *crypto.MustGenerateShardAddress(1)
+	slashed, err := SlashDoubleSigners(context.statedb, header, proposer)
+	assert.NoError(t, err)
+	assert.Equal(t, amount, slashed)
+
+	// 10% paid to proposer and the rest burned
+	assert.Equal(t, StakingUnit, context.statedb.GetBalance(proposer))
+	assert.Equal(t, big.NewInt(0), context.statedb.GetBalance(StakingContractAddress))
+
+	stake, err := GetStake(staker, context.statedb, header.Height)
+	assert.NoError(t, err)
+	assert.Nil(t, stake)
+
+	// nothing to slash after removed
+	slashed, err = SlashDoubleSigners(context.statedb, header, proposer)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, slashed.Sign())
+}
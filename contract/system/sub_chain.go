@@ -46,6 +46,12 @@ type SubChainInfo struct {
 
 	// SubChain owner publick key
 	Owner common.Address `json:"owner,omitempty"`
+
+	// TxLimitPerRelay is the max number of txs sent or received by an account in each relay range
+	TxLimitPerRelay uint64 `json:"txLimitPerRelay,omitempty"`
+
+	// RelayRange is the number of blocks between relays to the root chain
+	RelayRange uint64 `json:"relayRange,omitempty"`
 }
 
 func registerSubChain(jsonRegInfo []byte, context *Context) ([]byte, error) {
@@ -68,6 +74,20 @@ func registerSubChain(jsonRegInfo []byte, context *Context) ([]byte, error) {
 		return nil, errInvalidSubChainInfo
 	}
 
+	if context.BlockHeader.Height >= common.SubChainTxLimitForkHeight {
+		// use the default tx limit if not specified
+		if info.TxLimitPerRelay == 0 {
+			info.TxLimitPerRelay = common.DefaultTxLimitPerRelay
+		}
+
+		if info.RelayRange == 0 {
+			info.RelayRange = common.RelayRange
+		}
+	} else {
+		// the info is stored as before the fork
+		info.TxLimitPerRelay, info.RelayRange = 0, 0
+	}
+
 	// set transaction sender to subchain owner
 	info.Owner = context.tx.Data.From
 	info.CreateTimestamp = context.BlockHeader.CreateTimestamp
@@ -80,6 +100,10 @@ func registerSubChain(jsonRegInfo []byte, context *Context) ([]byte, error) {
 	context.statedb.CreateAccount(SubChainContractAddress)
 	context.statedb.SetData(SubChainContractAddress, key, value)
 
+	if err = context.emit("SubChainRegistered", info.Owner, info.Name, info.Version, info.TokenShortName, info.TokenAmount); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
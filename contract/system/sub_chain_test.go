@@ -24,6 +24,7 @@ func Test_RegisterSubChain(t *testing.T) {
 		TokenShortName:    "TC",
 		TokenAmount:       1000000,
 		GenesisDifficulty: 8000,
+		TxLimitPerRelay:   100,
 		GenesisAccounts: map[common.Address]*big.Int{
 			*crypto.MustGenerateShardAddress(1): big.NewInt(1000),
 			*crypto.MustGenerateShardAddress(1): big.NewInt(1000),
@@ -34,6 +35,7 @@ func Test_RegisterSubChain(t *testing.T) {
 	defer dispose()
 
 	context := newTestContext(db, SubChainContractAddress)
+	context.BlockHeader.Height = common.SubChainTxLimitForkHeight
 
 	regInfo.Owner = context.tx.Data.From
 	regInfo.CreateTimestamp = context.BlockHeader.CreateTimestamp
@@ -58,6 +60,8 @@ func Test_RegisterSubChain(t *testing.T) {
 	assert.Equal(t, err, nil)
 	var regInfo2 SubChainInfo
 	assert.Equal(t, json.Unmarshal(result, &regInfo2), nil)
+	assert.Equal(t, regInfo2.RelayRange, uint64(common.RelayRange))
+	regInfo.RelayRange = common.RelayRange
 	assert.Equal(t, regInfo2, regInfo)
 
 	// create duplicate reg info
@@ -65,3 +69,35 @@ func Test_RegisterSubChain(t *testing.T) {
 	assert.Equal(t, result, []byte(nil))
 	assert.Equal(t, err, errExists)
 }
+
+func Test_RegisterSubChainBeforeFork(t *testing.T) {
+	regInfo := SubChainInfo{
+		Name:              "test",
+		Version:           "3.8",
+		TokenFullName:     "TestCoin",
+		TokenShortName:    "TC",
+		TokenAmount:       1000000,
+		GenesisDifficulty: 8000,
+		TxLimitPerRelay:   100,
+		RelayRange:        10,
+	}
+
+	db, dispose := leveldb.NewTestDatabase()
+	defer dispose()
+
+	context := newTestContext(db, SubChainContractAddress)
+	context.BlockHeader.Height = common.SubChainTxLimitForkHeight - 1
+
+	encoded, err := json.Marshal(regInfo)
+	assert.Nil(t, err)
+
+	result, err := registerSubChain(encoded, context)
+	assert.Equal(t, result, []byte(nil))
+	assert.Equal(t, err, nil)
+
+	// the tx limit and relay range are not stored before the fork
+	result, err = querySubChain([]byte("test"), context)
+	assert.Equal(t, err, nil)
+	assert.NotContains(t, string(result), "txLimitPerRelay")
+	assert.NotContains(t, string(result), "relayRange")
+}
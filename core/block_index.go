@@ -186,6 +186,42 @@ func (bf *BlockLeaves) PurgeAsync(bcStore store.BlockchainStore, callback func(e
 	}()
 }
 
+// PurgeFinalized purges the forking chains whose leaves are not higher than the finalized
+// height, which can never become the canonical chain again.
+func (bf *BlockLeaves) PurgeFinalized(height uint64, bcStore store.BlockchainStore, callback func(error)) {
+	var hashes []common.Hash
+	for hash, indices := range bf.blockIndexMap {
+		if indices.bestHeaped.blockHeight > height {
+			continue
+		}
+
+		// the leaf in canonical chain is the HEAD block
+		if canonicalHash, err := bcStore.GetBlockHash(indices.bestHeaped.blockHeight); err == nil && hash.Equal(canonicalHash) {
+			continue
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	if len(hashes) == 0 {
+		return
+	}
+
+	for _, hash := range hashes {
+		bf.Remove(hash)
+	}
+
+	// asynchronously purge blocks
+	go func() {
+		for _, hash := range hashes {
+			err := purgeBlock(hash, bcStore)
+			if callback != nil {
+				callback(err)
+			}
+		}
+	}()
+}
+
 // purgeBlock purges the blocks in forking chain util the common ancestor found in canonical chain.
 func purgeBlock(hash common.Hash, bcStore store.BlockchainStore) error {
 	for !hash.IsEmpty() {
@@ -162,3 +162,47 @@ func Test_BlockLeaf_Purge(t *testing.T) {
 		}
 	})
 }
+
+func Test_BlockLeaf_PurgeFinalized(t *testing.T) {
+	bcStore := store.NewMemStore()
+	var canonicalBlocks []*types.Block
+	preHash := common.EmptyHash
+	for height := uint64(0); height <= 3; height++ {
+		header := &types.BlockHeader{PreviousBlockHash: preHash, Difficulty: big.NewInt(1), Height: height, CreateTimestamp: big.NewInt(1)}
+		block := &types.Block{HeaderHash: header.Hash(), Header: header}
+		assert.Nil(t, bcStore.PutBlock(block, big.NewInt(int64(height)), true))
+		canonicalBlocks = append(canonicalBlocks, block)
+		preHash = block.HeaderHash
+	}
+
+	// forking block of height 2
+	header := &types.BlockHeader{PreviousBlockHash: canonicalBlocks[1].HeaderHash, Difficulty: big.NewInt(1), Height: 2, CreateTimestamp: big.NewInt(2)}
+	forkingBlock := &types.Block{HeaderHash: header.Hash(), Header: header}
+	assert.Nil(t, bcStore.PutBlock(forkingBlock, big.NewInt(2), false))
+
+	bf := NewBlockLeaves()
+	bf.Add(NewBlockIndex(canonicalBlocks[3].HeaderHash, 3, big.NewInt(3)))
+	bf.Add(NewBlockIndex(forkingBlock.HeaderHash, 2, big.NewInt(2)))
+
+	// leaves higher than finalized height are kept
+	bf.PurgeFinalized(1, bcStore, nil)
+	assert.Equal(t, 2, bf.Count())
+
+	done := make(chan struct{})
+	bf.PurgeFinalized(2, bcStore, func(err error) {
+		assert.Nil(t, err)
+		close(done)
+	})
+	<-done
+
+	assert.Equal(t, 1, bf.Count())
+	assert.Equal(t, canonicalBlocks[3].HeaderHash, bf.GetBestBlockIndex().blockHash)
+
+	exists, err := bcStore.HasBlock(forkingBlock.HeaderHash)
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	// the HEAD block in canonical chain is never purged
+	bf.PurgeFinalized(3, bcStore, nil)
+	assert.Equal(t, 1, bf.Count())
+}
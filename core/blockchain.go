@@ -86,6 +86,8 @@ type Blockchain struct {
 
 	rp           *recoveryPoint // used to recover blockchain in case of program crashed when write a block
 	debtVerifier types.DebtVerifier
+	txLimit      *TxLimit     // tx limit of accounts in each relay range of subchain
+	finalized    atomic.Value // the latest checkpoint finalized by the finality gadget
 
 	lastBlockTime time.Time // last sucessful written block time.
 }
@@ -495,6 +497,13 @@ func ValidateBlockHeader(header *types.BlockHeader, engine consensus.Engine, bcS
 		return errors.NewStackedError(err, "failed to verify header by consensus engine")
 	}
 
+	// Validate the chain never reorganizes past the finalized checkpoint
+	if chain, ok := chainReader.(consensus.FinalizedChain); ok {
+		if err := validateFinalizedAncestor(header, chain.FinalizedCheckpoint(), bcStore); err != nil {
+			return errors.NewStackedError(err, "failed to validate finalized checkpoint")
+		}
+	}
+
 	return nil
 }
 
@@ -513,6 +522,10 @@ func (bc *Blockchain) applyTxs(block *types.Block, root common.Hash) (*state.Sta
 		return nil, nil, errors.NewStackedErrorf(err, "failed to create statedb by root hash %v", root)
 	}
 
+	if err = bc.verifyTxLimit(statedb, block); err != nil {
+		return nil, nil, errors.NewStackedError(err, "failed to verify tx limit")
+	}
+
 	//validate debts
 	// fix the issue caused by forking from collapse database
 	if block.Height() > common.HeightRoof || block.Height() < common.HeightFloor {
@@ -523,11 +536,16 @@ func (bc *Blockchain) applyTxs(block *types.Block, root common.Hash) (*state.Sta
 	}
 
 	// update debts
-	for _, d := range block.Debts {
-		err = bc.ApplyDebtWithoutVerify(statedb, d, block.Header.Creator)
+	debtReceipts := make([]*types.Receipt, 0)
+	for i, d := range block.Debts {
+		receipt, err := bc.ApplyDebtWithoutVerify(statedb, d, i, block.Header)
 		if err != nil {
 			return nil, nil, errors.NewStackedError(err, "failed to apply debt")
 		}
+
+		if receipt != nil {
+			debtReceipts = append(debtReceipts, receipt)
+		}
 	}
 	auditor.Audit("succeed to validate %v debts", len(block.Debts))
 
@@ -538,7 +556,8 @@ func (bc *Blockchain) applyTxs(block *types.Block, root common.Hash) (*state.Sta
 	}
 	auditor.Audit("succeed to update stateDB for %v txs", len(block.Transactions))
 
-	return statedb, receipts, nil
+	// the receipts of cross shard calls in debts follow the tx receipts
+	return statedb, append(receipts, debtReceipts...), nil
 }
 
 func (bc *Blockchain) applyRewardAndRegularTxs(statedb *state.Statedb, rewardTx *types.Transaction, regularTxs []*types.Transaction, blockHeader *types.BlockHeader) ([]*types.Receipt, error) {
@@ -551,7 +570,7 @@ func (bc *Blockchain) applyRewardAndRegularTxs(statedb *state.Statedb, rewardTx
 		return nil, errors.NewStackedError(err, "failed to validate reward tx")
 	}
 
-	rewardReceipt, err := txs.ApplyRewardTx(rewardTx, statedb)
+	rewardReceipt, err := txs.ApplyRewardTx(rewardTx, statedb, blockHeader)
 	if err != nil {
 		return nil, errors.NewStackedError(err, "failed to apply reward tx")
 	}
@@ -602,38 +621,53 @@ func (bc *Blockchain) ApplyTransaction(tx *types.Transaction, txIndex int, coinb
 	return receipt, nil
 }
 
-// ApplyDebtWithoutVerify applies a debt and update statedb.
-func (bc *Blockchain) ApplyDebtWithoutVerify(statedb *state.Statedb, d *types.Debt, coinbase common.Address) error {
-	debtIndex, _ := bc.bcStore.GetDebtIndex(d.Hash)
-	if debtIndex != nil {
-		return fmt.Errorf("debt already packed, debt hash %s", d.Hash.Hex())
-	}
-
-	if !statedb.Exist(d.Data.Account) {
-		statedb.CreateAccount(d.Data.Account)
+// ApplyDebtWithoutVerify applies a debt and update statedb. If the debt carries a cross shard
+// contract call, the call is executed and its receipt is returned, otherwise the receipt is nil.
+func (bc *Blockchain) ApplyDebtWithoutVerify(statedb *state.Statedb, d *types.Debt, debtIndex int, blockHeader *types.BlockHeader) (*types.Receipt, error) {
+	if index, _ := bc.bcStore.GetDebtIndex(d.Hash); index != nil {
+		return nil, fmt.Errorf("debt already packed, debt hash %s", d.Hash.Hex())
 	}
 
-	// @todo handle contract
 	if d.Data.Amount == nil {
-		return types.ErrAmountNil
+		return nil, types.ErrAmountNil
 	}
 
 	if d.Data.Amount.Sign() < 0 {
-		return types.ErrAmountNegative
+		return nil, types.ErrAmountNegative
 	}
 
 	if d.Fee() == nil {
-		return types.ErrAmountNil
+		return nil, types.ErrAmountNil
 	}
 
 	if d.Fee().Sign() < 0 {
-		return types.ErrAmountNegative
+		return nil, types.ErrAmountNegative
+	}
+
+	if d.GetCall() != nil {
+		ctx := &svm.Context{
+			TxIndex:     debtIndex,
+			Statedb:     statedb,
+			BlockHeader: blockHeader,
+			BcStore:     bc.bcStore,
+		}
+
+		receipt, err := svm.ProcessDebtCall(ctx, d)
+		if err != nil {
+			return nil, errors.NewStackedError(err, "failed to process cross shard call")
+		}
+
+		return receipt, nil
+	}
+
+	if !statedb.Exist(d.Data.Account) {
+		statedb.CreateAccount(d.Data.Account)
 	}
 
 	statedb.AddBalance(d.Data.Account, d.Data.Amount)
-	statedb.AddBalance(coinbase, d.Fee())
+	statedb.AddBalance(blockHeader.Creator, d.Fee())
 
-	return nil
+	return nil, nil
 }
 
 // DeleteLargerHeightBlocks deletes the height-to-hash mappings with larger height in the canonical chain.
@@ -0,0 +1,115 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package core
+
+import (
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/consensus"
+	"github.com/scdoproject/go-stem/core/store"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+var (
+	// ErrBlockBelowCheckpoint is returned when the block is not higher than the finalized checkpoint.
+	ErrBlockBelowCheckpoint = errors.New("block is not higher than the finalized checkpoint")
+
+	// ErrBlockNotDescendCheckpoint is returned when the block is not descended from the finalized checkpoint.
+	ErrBlockNotDescendCheckpoint = errors.New("block is not descended from the finalized checkpoint")
+
+	// ErrCheckpointTooOld is returned when the checkpoint is not higher than the finalized one.
+	ErrCheckpointTooOld = errors.New("checkpoint is not higher than the finalized checkpoint")
+
+	// ErrCheckpointNotCanonical is returned when the checkpoint block is not in the canonical chain.
+	ErrCheckpointNotCanonical = errors.New("checkpoint block is not in the canonical chain")
+
+	// ErrFinalityNotSupported is returned when the consensus engine has no finality gadget.
+	ErrFinalityNotSupported = errors.New("finality is not supported by consensus engine")
+)
+
+// FinalizedCheckpoint returns the latest finalized checkpoint, or nil if none.
+func (bc *Blockchain) FinalizedCheckpoint() *types.Checkpoint {
+	cp, _ := bc.finalized.Load().(*types.Checkpoint)
+	return cp
+}
+
+// SetFinalizedCheckpoint verifies and sets the finalized checkpoint, and purges the forking
+// chains lower than it.
+func (bc *Blockchain) SetFinalizedCheckpoint(cp *types.Checkpoint) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	if err := ValidateCheckpoint(cp, bc.FinalizedCheckpoint(), bc.engine, bc.bcStore, bc); err != nil {
+		return err
+	}
+
+	bc.finalized.Store(cp)
+	bc.blockLeaves.PurgeFinalized(cp.Height, bc.bcStore, func(err error) {
+		if err != nil {
+			bc.log.Error("failed to purge finalized block, %s", err)
+		}
+	})
+
+	bc.log.Info("finalized checkpoint, height %d, hash %v", cp.Height, cp.Hash)
+
+	return nil
+}
+
+// ValidateCheckpoint validates the checkpoint is higher than the finalized one and in the
+// canonical chain, and verifies its committed seals by the finality gadget of engine.
+func ValidateCheckpoint(cp, finalized *types.Checkpoint, engine consensus.Engine, bcStore store.BlockchainStore, chainReader consensus.ChainReader) error {
+	if finalized != nil && cp.Height <= finalized.Height {
+		return ErrCheckpointTooOld
+	}
+
+	finality, ok := engine.(consensus.Finality)
+	if !ok {
+		return ErrFinalityNotSupported
+	}
+
+	hash, err := bcStore.GetBlockHash(cp.Height)
+	if err != nil || !hash.Equal(cp.Hash) {
+		return ErrCheckpointNotCanonical
+	}
+
+	if err = finality.VerifyCheckpoint(chainReader, cp); err != nil {
+		return errors.NewStackedError(err, "failed to verify checkpoint by finality gadget")
+	}
+
+	return nil
+}
+
+// validateFinalizedAncestor validates the header is higher than the finalized checkpoint and
+// descended from it, so that the chain never reorganizes past the checkpoint.
+func validateFinalizedAncestor(header *types.BlockHeader, cp *types.Checkpoint, bcStore store.BlockchainStore) error {
+	if cp == nil {
+		return nil
+	}
+
+	if header.Height <= cp.Height {
+		return ErrBlockBelowCheckpoint
+	}
+
+	hash, height := header.PreviousBlockHash, header.Height-1
+	for height > cp.Height {
+		// the canonical chain always contains the finalized checkpoint
+		if canonical, err := bcStore.GetBlockHash(height); err == nil && canonical.Equal(hash) {
+			return nil
+		}
+
+		parent, err := bcStore.GetBlockHeader(hash)
+		if err != nil {
+			return errors.NewStackedErrorf(err, "failed to get block header by hash %v", hash)
+		}
+
+		hash, height = parent.PreviousBlockHash, height-1
+	}
+
+	if !hash.Equal(cp.Hash) {
+		return ErrBlockNotDescendCheckpoint
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/store"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFinalityHeader(preHash common.Hash, height uint64, createTime int64) *types.BlockHeader {
+	return &types.BlockHeader{
+		PreviousBlockHash: preHash,
+		Difficulty:        big.NewInt(1),
+		Height:            height,
+		CreateTimestamp:   big.NewInt(createTime),
+	}
+}
+
+func Test_ValidateFinalizedAncestor(t *testing.T) {
+	bcStore := store.NewMemStore()
+
+	// canonical chain of height 0 to 4
+	var canonical []*types.BlockHeader
+	preHash := common.EmptyHash
+	for height := uint64(0); height <= 4; height++ {
+		header := newTestFinalityHeader(preHash, height, 1)
+		block := &types.Block{HeaderHash: header.Hash(), Header: header}
+		assert.Nil(t, bcStore.PutBlock(block, big.NewInt(int64(height)), true))
+		canonical = append(canonical, header)
+		preHash = block.HeaderHash
+	}
+
+	// stale chain descended from checkpoint of height 2
+	stale := newTestFinalityHeader(canonical[2].Hash(), 3, 2)
+	assert.Nil(t, bcStore.PutBlock(&types.Block{HeaderHash: stale.Hash(), Header: stale}, big.NewInt(3), false))
+
+	// stale chain forked before checkpoint
+	forked := newTestFinalityHeader(canonical[1].Hash(), 2, 3)
+	assert.Nil(t, bcStore.PutBlock(&types.Block{HeaderHash: forked.Hash(), Header: forked}, big.NewInt(2), false))
+	forked3 := newTestFinalityHeader(forked.Hash(), 3, 3)
+	assert.Nil(t, bcStore.PutBlock(&types.Block{HeaderHash: forked3.Hash(), Header: forked3}, big.NewInt(3), false))
+
+	cp := &types.Checkpoint{Height: 2, Hash: canonical[2].Hash()}
+
+	// no checkpoint
+	assert.Nil(t, validateFinalizedAncestor(newTestFinalityHeader(canonical[0].Hash(), 1, 4), nil, bcStore))
+
+	// not higher than checkpoint
+	assert.Equal(t, ErrBlockBelowCheckpoint, validateFinalizedAncestor(newTestFinalityHeader(canonical[1].Hash(), 2, 4), cp, bcStore))
+
+	// descended from checkpoint in canonical chain, directly or in stale chain
+	assert.Nil(t, validateFinalizedAncestor(newTestFinalityHeader(canonical[4].Hash(), 5, 4), cp, bcStore))
+	assert.Nil(t, validateFinalizedAncestor(newTestFinalityHeader(canonical[2].Hash(), 3, 4), cp, bcStore))
+	assert.Nil(t, validateFinalizedAncestor(newTestFinalityHeader(stale.Hash(), 4, 4), cp, bcStore))
+
+	// forked before checkpoint
+	assert.Equal(t, ErrBlockNotDescendCheckpoint, validateFinalizedAncestor(newTestFinalityHeader(forked.Hash(), 3, 4), cp, bcStore))
+	assert.Equal(t, ErrBlockNotDescendCheckpoint, validateFinalizedAncestor(newTestFinalityHeader(forked3.Hash(), 4, 4), cp, bcStore))
+
+	// unknown parent
+	assert.NotNil(t, validateFinalizedAncestor(newTestFinalityHeader(common.StringToHash("unknown"), 5, 4), cp, bcStore))
+}
@@ -68,6 +68,22 @@ type GenesisInfo struct {
 	Balance *big.Int `json:"balance"`
 	// subchain max supply
 	Supply *big.Int `json:"supply"`
+
+	// TxLimitPerRelay is the max number of txs sent or received by an account in each
+	// relay range of subchain, 0 for no limit
+	TxLimitPerRelay uint64 `json:"txLimitPerRelay,omitempty"`
+
+	// RelayRange is the number of subchain blocks between relays to the root chain
+	RelayRange uint64 `json:"relayRange,omitempty"`
+}
+
+// GetRelayRange returns the relay range of subchain, or the default relay range if not specified
+func (info *GenesisInfo) GetRelayRange() uint64 {
+	if info.RelayRange == 0 {
+		return common.RelayRange
+	}
+
+	return info.RelayRange
 }
 
 // NewGenesisInfo mainchain genesis block info constructor
@@ -0,0 +1,68 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// PendingState returns the header and statedb of the pending block, which applies the processable
+// txs on top of the current block in the order of sender nonce. The txs that could not be packed,
+// e.g. nonce gap or insufficient balance, are skipped.
+func (bc *Blockchain) PendingState(txs []*types.Transaction) (*types.BlockHeader, *state.Statedb, error) {
+	current := bc.CurrentBlock()
+	statedb, err := bc.GetState(current.Header.StateHash)
+	if err != nil {
+		return nil, nil, errors.NewStackedError(err, "failed to get the current statedb")
+	}
+
+	header := current.Header.Clone()
+	header.PreviousBlockHash = current.HeaderHash
+	header.Height++
+	header.CreateTimestamp = big.NewInt(time.Now().Unix())
+
+	counter, err := bc.NewTxCounter(statedb, header.PreviousBlockHash, header.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sorted := make([]*types.Transaction, len(txs))
+	copy(sorted, txs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Data.From != sorted[j].Data.From {
+			return sorted[i].Data.From.Big().Cmp(sorted[j].Data.From.Big()) < 0
+		}
+
+		return sorted[i].Data.AccountNonce < sorted[j].Data.AccountNonce
+	})
+
+	index := 0
+	for _, tx := range sorted {
+		if tx.Data.AccountNonce != statedb.GetNonce(tx.Data.From) {
+			continue
+		}
+
+		if err = counter.Check(tx); err != nil {
+			continue
+		}
+
+		if _, err = bc.ApplyTransaction(tx, index, header.Creator, statedb, header); err != nil {
+			bc.log.Debug("skip the tx %v in pending state, %s", tx.Hash.Hex(), err)
+			continue
+		}
+
+		counter.Count(tx)
+		index++
+	}
+
+	return header, statedb, nil
+}
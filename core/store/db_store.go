@@ -394,6 +394,11 @@ func (store *blockchainDatabase) GetReceiptsByBlockHash(hash common.Hash) ([]*ty
 func (store *blockchainDatabase) GetReceiptByTxHash(txHash common.Hash) (*types.Receipt, error) {
 	txIndex, err := store.GetTxIndex(txHash)
 	if err != nil {
+		// the receipt of cross shard call in debt
+		if debtIndex, debtErr := store.GetDebtIndex(txHash); debtErr == nil {
+			return store.getDebtReceipt(debtIndex.BlockHash, txHash)
+		}
+
 		return nil, err
 	}
 
@@ -409,6 +414,21 @@ func (store *blockchainDatabase) GetReceiptByTxHash(txHash common.Hash) (*types.
 	return receipts[txIndex.Index], nil
 }
 
+// getDebtReceipt retrieves the receipt of cross shard call in debt, which follows the tx receipts.
+func (store *blockchainDatabase) getDebtReceipt(blockHash, debtHash common.Hash) (*types.Receipt, error) {
+	receipts, err := store.GetReceiptsByBlockHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	index := types.GetReceiptIndex(receipts, debtHash)
+	if index < 0 {
+		return nil, fmt.Errorf("receipt not found for debt %v", debtHash.Hex())
+	}
+
+	return receipts[index], nil
+}
+
 // AddIndices adds tx/debt indices for the specified block.
 func (store *blockchainDatabase) AddIndices(block *types.Block) error {
 	batch := store.db.NewBatch()
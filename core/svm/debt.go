@@ -0,0 +1,135 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package svm
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/scdoproject/go-stem/accounts/abi"
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/core/svm/evm"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/core/vm"
+)
+
+// CrossShardCallbackABI is the ABI of the callback method, which is called on the origin
+// contract with the result of cross shard call. It is optional for the origin contract,
+// and the refund of failed call is received even if the callback fails.
+const CrossShardCallbackABI = `[{"constant":false,"inputs":[{"name":"callHash","type":"bytes32"},{"name":"success","type":"bool"},{"name":"result","type":"bytes"}],"name":"onCrossShardCall","outputs":[],"payable":true,"stateMutability":"payable","type":"function"}]`
+
+// CrossShardCallbackMethod is the method name of cross shard call callback
+const CrossShardCallbackMethod = "onCrossShardCall"
+
+var crossShardCallbackABI abi.ABI
+
+func init() {
+	var err error
+	if crossShardCallbackABI, err = abi.JSON(strings.NewReader(CrossShardCallbackABI)); err != nil {
+		panic(err)
+	}
+}
+
+// ProcessDebtCall executes the cross shard contract call carried by the debt in target shard,
+// and returns the receipt of the call. The gas allowance is paid by the debt fee, of which the
+// unused part pays for the reply to the origin contract. The reply is executed the same way,
+// but never replied again, and its value is always credited to the origin contract.
+func ProcessDebtCall(ctx *Context, debt *types.Debt) (*types.Receipt, error) {
+	call := debt.GetCall()
+	if call == nil {
+		return nil, errors.New("debt is not a cross shard call")
+	}
+
+	snapshot := ctx.Statedb.Prepare(ctx.TxIndex)
+	from, to, amount := debt.Data.From, debt.Data.Account, debt.Data.Amount
+
+	// the call is sent by the origin contract, whose value is carried by the debt.
+	if !ctx.Statedb.Exist(from) {
+		ctx.Statedb.CreateAccount(from)
+	}
+	ctx.Statedb.AddBalance(from, amount)
+
+	ctx.Tx = &types.Transaction{
+		Hash: debt.Hash,
+		Data: types.TransactionData{
+			From:     from,
+			To:       to,
+			Amount:   amount,
+			GasPrice: debt.Data.Price,
+			GasLimit: call.GasLimit,
+			Payload:  debt.Data.Code,
+		},
+	}
+
+	statedb := &evm.StateDB{Statedb: ctx.Statedb}
+	e := evm.NewEVMByDefaultConfig(ctx.Tx, statedb, ctx.BlockHeader, ctx.BcStore)
+	result, leftOverGas, err := e.Call(vm.AccountRef(from), to, debt.Data.Code, call.GasLimit, amount)
+
+	// the debts created by the call pay the delivery gas with the allowance.
+	debts := statedb.GetDebts()
+	if err == nil && leftOverGas < deliveryGas(debts) {
+		err = vm.ErrOutOfGas
+	}
+
+	receipt := &types.Receipt{
+		TxHash:  debt.Hash,
+		UsedGas: call.GasLimit - leftOverGas,
+		Result:  result,
+	}
+
+	if err != nil {
+		ctx.Statedb.RevertToSnapshot(snapshot)
+		receipt.Failed = true
+		receipt.Result = []byte(err.Error())
+		debts = nil
+
+		// the value of reply is received anyway
+		if call.Reply {
+			if !ctx.Statedb.Exist(to) {
+				ctx.Statedb.CreateAccount(to)
+			}
+			ctx.Statedb.AddBalance(to, amount)
+		}
+	} else {
+		receipt.UsedGas += deliveryGas(debts)
+	}
+
+	// reply the result and refund of failed call with the left allowance
+	if !call.Reply {
+		refund := new(big.Int)
+		if receipt.Failed {
+			refund.Set(amount)
+		}
+
+		input, err := crossShardCallbackABI.Pack(CrossShardCallbackMethod, debt.Hash, !receipt.Failed, result)
+		if err != nil {
+			return nil, revertStatedb(ctx.Statedb, snapshot, errors.NewStackedError(err, "failed to pack callback"))
+		}
+
+		debts = append(debts, types.NewReplyDebt(debt, uint64(len(debts)), refund, input, call.GasLimit-receipt.UsedGas))
+	}
+
+	// the miner is paid with the debt fee, except the fee of debts created by the call
+	minerFee := debt.Fee()
+	for _, d := range debts {
+		minerFee.Sub(minerFee, d.Fee())
+	}
+	ctx.Statedb.AddBalance(ctx.BlockHeader.Creator, minerFee)
+	receipt.TotalFee = minerFee.Uint64()
+
+	if receipt.PostState, err = ctx.Statedb.Hash(); err != nil {
+		err = errors.NewStackedError(err, "failed to get statedb root hash")
+		return nil, revertStatedb(ctx.Statedb, snapshot, err)
+	}
+
+	receipt.Debts = debts
+	receipt.Logs = ctx.Statedb.GetCurrentLogs()
+	if receipt.Logs == nil {
+		receipt.Logs = make([]*types.Log, 0)
+	}
+
+	return receipt, nil
+}
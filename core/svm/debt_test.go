@@ -0,0 +1,135 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package svm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// crossShardCaller is the runtime code that calls the address in call data with the call value:
+	//
+	//	call(200000, calldataload(0), callvalue, 0, 0, 0, 0)
+	crossShardCaller = "0x60006000600060003460003562030d40f15000"
+
+	storeCallValue = "0x3460005500" // sstore(0, callvalue)
+	revertCall     = "0x60006000fd" // revert(0, 0)
+)
+
+func otherShard(shard uint) uint {
+	if shard == 1 {
+		return 2
+	}
+
+	return 1
+}
+
+// newCrossShardCallDebt calls the contract of other shard via the value forwarder, and returns the call debt.
+func newCrossShardCallDebt(t *testing.T) (*types.Debt, common.Address, common.Address) {
+	ctx, forwarder, _ := newContractDebtContext(t, common.CrossShardCallForkHeight)
+	ctx.Statedb.SetCode(forwarder, mustHexToBytes(crossShardCaller))
+
+	target := crypto.CreateAddress(*crypto.MustGenerateShardAddress(otherShard(forwarder.Shard())), 1)
+	ctx.Tx.Data.Payload = common.BytesToHash(target.Bytes()).Bytes()
+	ctx.Tx.Hash = ctx.Tx.CalculateHash()
+
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, 1, len(receipt.Debts))
+
+	// the gas allowance is paid to the miner of target shard
+	debt := receipt.Debts[0]
+	minerFee := new(big.Int).Sub(new(big.Int).SetUint64(receipt.TotalFee), debt.Fee())
+	assert.Equal(t, minerFee, ctx.Statedb.GetBalance(ctx.BlockHeader.Creator))
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(forwarder))
+
+	return debt, forwarder, target
+}
+
+func newDebtCallContext(t *testing.T, contract common.Address, code string) *Context {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.NoError(t, err)
+
+	ctx.Statedb.CreateAccount(contract)
+	ctx.Statedb.SetCode(contract, mustHexToBytes(code))
+	ctx.BlockHeader.Height = common.CrossShardCallForkHeight
+	ctx.BlockHeader.Creator = *crypto.MustGenerateShardAddress(contract.Shard())
+	ctx.Statedb.CreateAccount(ctx.BlockHeader.Creator)
+
+	return ctx
+}
+
+func Test_CrossShardCall(t *testing.T) {
+	debt, forwarder, target := newCrossShardCallDebt(t)
+
+	call := debt.GetCall()
+	assert.NotNil(t, call)
+	assert.False(t, call.Reply)
+	assert.True(t, call.GasLimit > 0)
+	assert.Equal(t, forwarder, debt.Data.From)
+	assert.Equal(t, target, debt.Data.Account)
+	assert.Equal(t, big.NewInt(100), debt.Data.Amount)
+	assert.Equal(t, 2*types.DebtGas, debt.DeliveryGas())
+
+	// execute the call in target shard
+	ctx := newDebtCallContext(t, target, storeCallValue)
+	receipt, err := ProcessDebtCall(ctx, debt)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, debt.Hash, receipt.TxHash)
+	assert.Equal(t, big.NewInt(100), ctx.Statedb.GetBalance(target))
+	assert.Equal(t, common.BytesToHash([]byte{100}).Bytes(), ctx.Statedb.GetData(target, common.EmptyHash))
+
+	// reply to the origin contract with the left allowance
+	assert.Equal(t, 1, len(receipt.Debts))
+	reply := receipt.Debts[0]
+	assert.True(t, reply.GetCall().Reply)
+	assert.Equal(t, debt.Hash, reply.Data.TxHash)
+	assert.Equal(t, target, reply.Data.From)
+	assert.Equal(t, forwarder, reply.Data.Account)
+	assert.Equal(t, big.NewInt(0), reply.Data.Amount)
+	assert.Equal(t, call.GasLimit-receipt.UsedGas, reply.GetCall().GasLimit)
+
+	callback, err := crossShardCallbackABI.Pack(CrossShardCallbackMethod, debt.Hash, true, []byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, callback, []byte(reply.Data.Code))
+
+	minerFee := new(big.Int).Sub(debt.Fee(), reply.Fee())
+	assert.Equal(t, minerFee, ctx.Statedb.GetBalance(ctx.BlockHeader.Creator))
+	assert.Equal(t, minerFee.Uint64(), receipt.TotalFee)
+}
+
+func Test_CrossShardCall_Failed(t *testing.T) {
+	debt, forwarder, target := newCrossShardCallDebt(t)
+
+	// the value is refunded by reply
+	ctx := newDebtCallContext(t, target, revertCall)
+	receipt, err := ProcessDebtCall(ctx, debt)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed)
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(target))
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(forwarder))
+
+	assert.Equal(t, 1, len(receipt.Debts))
+	reply := receipt.Debts[0]
+	assert.Equal(t, big.NewInt(100), reply.Data.Amount)
+
+	// the refund is received even if the origin contract fails to handle the callback
+	ctx = newDebtCallContext(t, forwarder, revertCall)
+	receipt, err = ProcessDebtCall(ctx, reply)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed)
+	assert.Equal(t, 0, len(receipt.Debts))
+	assert.Equal(t, big.NewInt(100), ctx.Statedb.GetBalance(forwarder))
+	assert.Equal(t, reply.Fee(), ctx.Statedb.GetBalance(ctx.BlockHeader.Creator))
+}
@@ -18,7 +18,21 @@ import (
 // NewEVMByDefaultConfig returns a new EVM. The returned EVM is not thread safe and should
 // only ever be used *once*.
 func NewEVMByDefaultConfig(tx *types.Transaction, statedb *StateDB, blockHeader *types.BlockHeader, bcStore store.BlockchainStore) *vm.EVM {
+	return NewEVMWithConfig(tx, statedb, blockHeader, bcStore, vm.Config{})
+}
+
+// NewEVMWithConfig returns a new EVM with the specified vm config, e.g. a tracer to
+// capture the execution. The returned EVM is not thread safe and should only ever be used *once*.
+func NewEVMWithConfig(tx *types.Transaction, statedb *StateDB, blockHeader *types.BlockHeader, bcStore store.BlockchainStore, vmConfig vm.Config) *vm.EVM {
 	evmContext := newEVMContext(tx, blockHeader, blockHeader.Creator, bcStore)
+	if blockHeader.Height >= common.SystemContractCallForkHeight {
+		evmContext.GetSystemContract = newSystemContractFunc(tx, statedb.Statedb, blockHeader)
+	}
+
+	if blockHeader.Height >= common.CrossShardCallForkHeight {
+		evmContext.CallCrossShard = newCrossShardCallFunc(tx, blockHeader.Creator.Shard())
+	}
+
 	chainConfig := &params.ChainConfig{
 		ChainID:             big.NewInt(1),
 		HomesteadBlock:      big.NewInt(0),
@@ -31,9 +45,24 @@ func NewEVMByDefaultConfig(tx *types.Transaction, statedb *StateDB, blockHeader
 		ConstantinopleBlock: nil,
 		Ethash:              new(params.EthashConfig),
 	}
-	vmConfig := &vm.Config{}
 
-	return vm.NewEVM(*evmContext, statedb, chainConfig, *vmConfig)
+	return vm.NewEVM(*evmContext, statedb, chainConfig, vmConfig)
+}
+
+// newCrossShardCallFunc returns the function to create debt for the call from contract to the
+// contract of other shard, which is executed when the debt is applied in the target shard.
+func newCrossShardCallFunc(tx *types.Transaction, localShard uint) vm.CallCrossShardFunc {
+	return func(db vm.StateDB, caller, addr common.Address, input []byte, gas uint64, value *big.Int) bool {
+		statedb, ok := db.(*StateDB)
+		if !ok || !caller.IsEVMContract() || !addr.IsEVMContract() || addr.Shard() == localShard {
+			return false
+		}
+
+		db.SubBalance(caller, value)
+		statedb.AddCallDebt(tx, caller, addr, value, input, gas)
+
+		return true
+	}
 }
 
 // NewEVMContext creates a new context for use in the EVM.
@@ -42,11 +71,29 @@ func newEVMContext(tx *types.Transaction, header *types.BlockHeader, minerAddres
 		return db.GetBalance(addr).Cmp(amount) >= 0
 	}
 
+	// the system contracts are reserved addresses, which are available in all shards.
+	callSystemContract := header.Height >= common.SystemContractCallForkHeight
+	contractDebt := header.Height >= common.ContractDebtForkHeight
+	crossShardCall := header.Height >= common.CrossShardCallForkHeight
+	localShard := header.Creator.Shard()
 	transferFunc := func(db vm.StateDB, sender, recipient common.Address, amount *big.Int) {
 		db.SubBalance(sender, amount)
 
-		if sender.Shard() == recipient.Shard() {
+		if sender.Shard() == recipient.Shard() || (callSystemContract && recipient.IsReserved()) {
+			db.AddBalance(recipient, amount)
+			return
+		}
+
+		// value of the cross shard call from the contract of other shard
+		if crossShardCall && recipient.Shard() == localShard {
 			db.AddBalance(recipient, amount)
+			return
+		}
+
+		// value transferred by contract to other shard is carried by debt,
+		// whereas the debt of tx to other shard is created from the tx itself.
+		if statedb, ok := db.(*StateDB); ok && contractDebt && sender.IsEVMContract() && amount.Sign() > 0 {
+			statedb.AddDebt(tx, sender, recipient, amount)
 		}
 	}
 
@@ -1,13 +1,57 @@
 package evm
 
 import (
+	"math/big"
+
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
 )
 
 // StateDB for evm
 type StateDB struct {
 	*state.Statedb
+
+	debts         []*types.Debt // debts created by contracts transferring value to other shards
+	debtSnapshots map[int]int   // snapshot id to the number of debts, to revert debts along with the state
+}
+
+// Snapshot returns an identifier for the current revision of the statedb and debts.
+func (s *StateDB) Snapshot() int {
+	id := s.Statedb.Snapshot()
+
+	if s.debtSnapshots == nil {
+		s.debtSnapshots = make(map[int]int)
+	}
+	s.debtSnapshots[id] = len(s.debts)
+
+	return id
+}
+
+// RevertToSnapshot reverts all state changes and debts made since the given revision.
+func (s *StateDB) RevertToSnapshot(revid int) {
+	s.Statedb.RevertToSnapshot(revid)
+
+	if num, ok := s.debtSnapshots[revid]; ok && num < len(s.debts) {
+		s.debts = s.debts[:num]
+	}
+}
+
+// AddDebt creates a debt for the amount transferred by the contract to an account of other shard.
+func (s *StateDB) AddDebt(tx *types.Transaction, contract, account common.Address, amount *big.Int) {
+	debt := types.NewContractDebt(tx, uint64(len(s.debts)), contract, account, amount)
+	s.debts = append(s.debts, debt)
+}
+
+// AddCallDebt creates a debt for the contract to call the contract of other shard.
+func (s *StateDB) AddCallDebt(tx *types.Transaction, contract, target common.Address, amount *big.Int, input []byte, gas uint64) {
+	debt := types.NewCallDebt(tx, uint64(len(s.debts)), contract, target, amount, input, gas)
+	s.debts = append(s.debts, debt)
+}
+
+// GetDebts returns the debts created by contracts in current transaction.
+func (s *StateDB) GetDebts() []*types.Debt {
+	return s.debts
 }
 
 // GetState returns the value of the specified key in account storage if exists.
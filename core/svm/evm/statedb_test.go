@@ -55,7 +55,7 @@ func commitAndNewStateDB(db database.Database, statedb *StateDB) (common.Hash, *
 		panic(err)
 	}
 
-	return rootHash, &StateDB{newStatedb}
+	return rootHash, &StateDB{Statedb: newStatedb}
 }
 
 func newTestEVMStateDB() (database.Database, *StateDB, common.Address, func()) {
@@ -70,5 +70,5 @@ func newTestEVMStateDB() (database.Database, *StateDB, common.Address, func()) {
 	testAddr := *crypto.MustGenerateRandomAddress()
 	statedb.CreateAccount(testAddr)
 
-	return db, &StateDB{statedb}, testAddr, dispose
+	return db, &StateDB{Statedb: statedb}, testAddr, dispose
 }
@@ -0,0 +1,79 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package evm
+
+import (
+	"math/big"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/core/vm"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// systemCallContext is shared by the system contract calls in the execution of a tx.
+type systemCallContext struct {
+	tx          *types.Transaction
+	statedb     *state.Statedb
+	blockHeader *types.BlockHeader
+	calls       uint64 // number of system contract calls, to identify each call in a tx
+}
+
+// systemContract adapts the system contract to be called by EVM contracts.
+type systemContract struct {
+	contract system.Contract
+	address  common.Address
+	ctx      *systemCallContext
+}
+
+// newSystemContractFunc returns the function to get the system contract for EVM of the tx.
+func newSystemContractFunc(tx *types.Transaction, statedb *state.Statedb, blockHeader *types.BlockHeader) vm.GetSystemContractFunc {
+	ctx := &systemCallContext{tx: tx, statedb: statedb, blockHeader: blockHeader}
+
+	return func(address common.Address) vm.SystemContract {
+		contract := system.GetContractByAddress(address, blockHeader.Height)
+		if contract == nil {
+			return nil
+		}
+
+		return &systemContract{contract, address, ctx}
+	}
+}
+
+// RequiredGas returns the gas used by the system contract command
+func (c *systemContract) RequiredGas(input []byte) uint64 {
+	return c.contract.RequiredGas(input)
+}
+
+// Run runs the system contract with a message tx, whose sender and amount are the caller
+// and value of the call. The message tx hash is derived from the tx hash and call index,
+// so that it is unique for the system contracts keyed by tx hash, e.g. HTLC.
+func (c *systemContract) Run(input []byte, caller common.Address, value *big.Int, readOnly bool) ([]byte, error) {
+	c.ctx.calls++
+
+	msg := &types.Transaction{
+		Hash: crypto.MustHash([]interface{}{c.ctx.tx.Hash, caller, c.ctx.calls}),
+		Data: types.TransactionData{
+			Type:         c.ctx.tx.Data.Type,
+			From:         caller,
+			To:           c.address,
+			Amount:       new(big.Int).Set(value),
+			AccountNonce: c.ctx.tx.Data.AccountNonce,
+			GasPrice:     c.ctx.tx.Data.GasPrice,
+			GasLimit:     c.ctx.tx.Data.GasLimit,
+			Payload:      common.CopyBytes(input),
+		},
+	}
+
+	if readOnly {
+		snapshot := c.ctx.statedb.Snapshot()
+		defer c.ctx.statedb.RevertToSnapshot(snapshot)
+	}
+
+	return c.contract.Run(input, system.NewContext(msg, c.ctx.statedb, c.ctx.blockHeader))
+}
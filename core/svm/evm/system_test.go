@@ -0,0 +1,77 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package evm
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/system"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/core/vm"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSystemCallEVM(t *testing.T, height uint64) (*vm.EVM, *StateDB, common.Address, func()) {
+	statedb, bcStore, from, dispose := preprocessContract(common.ScdoToWen.Uint64(), 0)
+
+	header := &types.BlockHeader{
+		PreviousBlockHash: crypto.MustHash("block previous hash"),
+		Creator:           *crypto.MustGenerateRandomAddress(),
+		Difficulty:        big.NewInt(1),
+		Height:            height,
+		CreateTimestamp:   big.NewInt(time.Now().Unix()),
+	}
+
+	tx, err := types.NewMessageTransaction(from, system.DomainNameContractAddress, big.NewInt(0), big.NewInt(1), 100000, 0, []byte{system.CmdGetDomainNameOwner})
+	assert.NoError(t, err)
+
+	db := &StateDB{Statedb: statedb}
+	return NewEVMByDefaultConfig(tx, db, header, bcStore), db, from, dispose
+}
+
+func Test_SystemContract_Call(t *testing.T) {
+	evm, db, from, dispose := newTestSystemCallEVM(t, common.SystemContractCallForkHeight)
+	defer dispose()
+
+	create := append([]byte{system.CmdCreateDomainName}, []byte("scdo")...)
+	query := append([]byte{system.CmdGetDomainNameOwner}, []byte("scdo")...)
+
+	_, _, err := evm.Call(vm.AccountRef(from), system.DomainNameContractAddress, query, 200000, big.NewInt(0))
+	assert.NotNil(t, err)
+
+	// the value is transferred to the system contract
+	_, _, err = evm.Call(vm.AccountRef(from), system.DomainNameContractAddress, create, 100000, system.DomainRegistrationFee)
+	assert.NoError(t, err)
+	assert.Equal(t, system.DomainRegistrationFee, db.GetBalance(system.DomainNameContractAddress))
+
+	ret, _, err := evm.Call(vm.AccountRef(from), system.DomainNameContractAddress, query, 200000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, from.Bytes(), ret)
+
+	// state changes are discarded in static call
+	owner := *crypto.MustGenerateRandomAddress()
+	param, err := json.Marshal(system.DomainNameOwner{Name: "scdo", Owner: owner})
+	assert.NoError(t, err)
+	transfer := append([]byte{system.CmdTransferDomainName}, param...)
+	ret, leftOverGas, err := evm.StaticCall(vm.AccountRef(from), system.DomainNameContractAddress, transfer, 100000)
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Bytes(), ret)
+	assert.Equal(t, uint64(50000), leftOverGas)
+
+	ret, _, err = evm.Call(vm.AccountRef(from), system.DomainNameContractAddress, query, 200000, big.NewInt(0))
+	assert.NoError(t, err)
+	assert.Equal(t, from.Bytes(), ret)
+
+	// out of gas
+	_, leftOverGas, err = evm.Call(vm.AccountRef(from), system.DomainNameContractAddress, query, 1000, big.NewInt(0))
+	assert.Equal(t, vm.ErrOutOfGas, err)
+	assert.Equal(t, uint64(0), leftOverGas)
+}
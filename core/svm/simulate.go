@@ -0,0 +1,269 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package svm
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/store"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/core/vm"
+)
+
+// DefaultCallGas is the gas limit of the simulated message if not specified
+var DefaultCallGas = common.ScdoToWen.Uint64()
+
+// revertSelector is the selector of Error(string), which is returned by the solidity revert and require
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// Message is an unsigned tx to simulate, whose nonce is always the account nonce in statedb
+type Message struct {
+	From     common.Address
+	To       common.Address // empty to create contract
+	Amount   *big.Int
+	GasPrice *big.Int
+	GasLimit uint64
+	Payload  []byte
+}
+
+// AccountOverride is the account states replaced before simulation, nil fields are not changed
+type AccountOverride struct {
+	Balance *big.Int
+	Nonce   *uint64
+	Code    []byte
+	State   map[common.Hash]common.Hash // storage slots to replace
+}
+
+// StateOverride is the account states replaced before simulation
+type StateOverride map[common.Address]*AccountOverride
+
+// Apply replaces the account states in statedb
+func (o StateOverride) Apply(statedb *state.Statedb) {
+	for addr, account := range o {
+		if account == nil {
+			continue
+		}
+
+		statedb.CreateAccount(addr)
+
+		if account.Balance != nil {
+			statedb.SetBalance(addr, account.Balance)
+		}
+
+		if account.Nonce != nil {
+			statedb.SetNonce(addr, *account.Nonce)
+		}
+
+		if account.Code != nil {
+			statedb.SetCode(addr, account.Code)
+		}
+
+		for key, value := range account.State {
+			statedb.SetData(addr, key, value.Bytes())
+		}
+	}
+}
+
+// StateFunc returns a new statedb to simulate on, since the simulation changes the statedb
+type StateFunc func() (*state.Statedb, error)
+
+// CallResult is the result of simulated message
+type CallResult struct {
+	Receipt *types.Receipt
+	Revert  []byte // data returned by the REVERT opcode, nil if not reverted
+}
+
+// Failed returns whether the execution failed
+func (r *CallResult) Failed() bool {
+	return r.Receipt.Failed
+}
+
+// RevertReason returns the message of Error(string) returned by the REVERT opcode. Otherwise,
+// returns the hex of revert data, or the failure message.
+func (r *CallResult) RevertReason() string {
+	if !r.Receipt.Failed {
+		return ""
+	}
+
+	if reason, ok := unpackRevert(r.Revert); ok {
+		return reason
+	}
+
+	if len(r.Revert) > 0 {
+		return hexutil.BytesToHex(r.Revert)
+	}
+
+	return string(r.Receipt.Result)
+}
+
+// unpackRevert unpacks the string of ABI encoded Error(string)
+func unpackRevert(data []byte) (string, bool) {
+	if len(data) < 4+64 || !bytes.Equal(data[:4], revertSelector) {
+		return "", false
+	}
+
+	data = data[4:]
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(data)) {
+		return "", false
+	}
+
+	start := offset.Uint64() + 32
+	size := new(big.Int).SetBytes(data[start-32 : start])
+	if !size.IsUint64() || start+size.Uint64() > uint64(len(data)) {
+		return "", false
+	}
+
+	return string(data[start : start+size.Uint64()]), true
+}
+
+// revertTracer captures the output of the outermost EVM call
+type revertTracer struct {
+	output []byte
+	err    error
+}
+
+func (t *revertTracer) CaptureStart(from common.Address, to common.Address, call bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+func (t *revertTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *revertTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *revertTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.output = common.CopyBytes(output)
+	t.err = err
+	return nil
+}
+
+// Call simulates the message on statedb without signature. The statedb is changed and
+// should not be reused.
+func Call(statedb *state.Statedb, header *types.BlockHeader, bcStore store.BlockchainStore, msg *Message) (*CallResult, error) {
+	amount, price := msg.Amount, msg.GasPrice
+	if amount == nil {
+		amount = big.NewInt(0)
+	}
+
+	if price == nil {
+		price = big.NewInt(1)
+	}
+
+	nonce := statedb.GetNonce(msg.From)
+	tx, err := types.NewMessageTransaction(msg.From, msg.To, amount, price, msg.GasLimit, nonce, msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+	tx.Hash = tx.CalculateHash()
+
+	tracer := &revertTracer{}
+	ctx := &Context{
+		Tx:          tx,
+		Statedb:     statedb,
+		BlockHeader: header,
+		BcStore:     bcStore,
+		VMConfig:    &vm.Config{Debug: true, Tracer: tracer},
+	}
+
+	receipt, err := Process(ctx, header.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CallResult{Receipt: receipt}
+	if receipt.Failed && tracer.err == vm.ErrExecutionReverted {
+		result.Revert = tracer.output
+	}
+
+	return result, nil
+}
+
+// RevertError is returned when the message always fails in gas estimation
+type RevertError struct {
+	Reason string
+	Data   []byte // data returned by the REVERT opcode
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("execution failed: %s", e.Reason)
+}
+
+// EstimateGas returns the smallest gas limit with which the message succeeds, by binary search
+// between the used gas and the gas limit of message, which is capped to the balance of sender.
+func EstimateGas(newState StateFunc, header *types.BlockHeader, bcStore store.BlockchainStore, msg *Message) (uint64, error) {
+	statedb, err := newState()
+	if err != nil {
+		return 0, err
+	}
+
+	hi := msg.GasLimit
+	if hi == 0 {
+		hi = DefaultCallGas
+	}
+
+	// cap the gas limit to the balance of sender
+	if msg.GasPrice != nil && msg.GasPrice.Sign() > 0 {
+		available := statedb.GetBalance(msg.From)
+		if msg.Amount != nil {
+			if available.Cmp(msg.Amount) < 0 {
+				return 0, vm.ErrInsufficientBalance
+			}
+			available = new(big.Int).Sub(available, msg.Amount)
+		}
+
+		if allowance := new(big.Int).Div(available, msg.GasPrice); allowance.IsUint64() && allowance.Uint64() < hi {
+			hi = allowance.Uint64()
+		}
+	}
+
+	run := func(statedb *state.Statedb, gas uint64) (*CallResult, error) {
+		m := *msg
+		m.GasLimit = gas
+		return Call(statedb, header, bcStore, &m)
+	}
+
+	// the message must succeed with the max gas limit
+	result, err := run(statedb, hi)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.Failed() {
+		return 0, &RevertError{Reason: result.RevertReason(), Data: result.Revert}
+	}
+
+	tx := types.Transaction{Data: types.TransactionData{From: msg.From, To: msg.To, Payload: msg.Payload}}
+	lo := tx.IntrinsicGas() - 1
+	if used := result.Receipt.UsedGas; used > 0 && used-1 > lo {
+		// the gas limit is never less than the used gas, e.g. the cross shard tx charges the debt gas
+		lo = used - 1
+	}
+
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		if statedb, err = newState(); err != nil {
+			return 0, err
+		}
+
+		if result, err = run(statedb, mid); err != nil || result.Failed() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return hi, nil
+}
@@ -0,0 +1,134 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package svm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/database/leveldb"
+	"github.com/stretchr/testify/assert"
+)
+
+// revertCode is the runtime code that reverts with Error("nope"):
+//
+//	codecopy(0, 12, 100)
+//	revert(0, 100)
+const revertCode = "0x6064600c60003960646000fd" +
+	"08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000004" +
+	"6e6f706500000000000000000000000000000000000000000000000000000000"
+
+// clearCode is the runtime code that clears the storage slot 0 to get gas refund:
+//
+//	sstore(0, 0)
+const clearCode = "0x6000600055"
+
+func newSimulation(from common.Address, code string, override StateOverride) (StateFunc, *Message, func()) {
+	db, dispose := leveldb.NewTestDatabase()
+	contract := crypto.CreateAddress(from, 1)
+
+	if override == nil {
+		override = make(StateOverride)
+	}
+
+	override[contract] = &AccountOverride{
+		Code:  mustHexToBytes(code),
+		State: map[common.Hash]common.Hash{{}: common.BytesToHash([]byte{1})},
+	}
+
+	newState := func() (*state.Statedb, error) {
+		statedb, err := state.NewStatedb(common.EmptyHash, db)
+		if err != nil {
+			return nil, err
+		}
+
+		override.Apply(statedb)
+		return statedb, nil
+	}
+
+	msg := &Message{
+		From:     from,
+		To:       contract,
+		GasPrice: big.NewInt(1),
+		Payload:  []byte{1},
+	}
+
+	return newState, msg, dispose
+}
+
+func Test_StateOverride(t *testing.T) {
+	nonce := uint64(7)
+	addr := *crypto.MustGenerateRandomAddress()
+	newState, _, dispose := newSimulation(addr, clearCode, StateOverride{
+		addr: {Balance: big.NewInt(100), Nonce: &nonce},
+	})
+	defer dispose()
+
+	statedb, err := newState()
+	assert.NoError(t, err)
+	assert.Equal(t, statedb.GetBalance(addr), big.NewInt(100))
+	assert.Equal(t, statedb.GetNonce(addr), nonce)
+}
+
+func Test_Call_Revert(t *testing.T) {
+	from := *crypto.MustGenerateRandomAddress()
+	header := newTestBlockHeader(*crypto.MustGenerateRandomAddress())
+	newState, msg, dispose := newSimulation(from, revertCode, nil)
+	defer dispose()
+
+	// sender has no balance
+	msg.GasLimit = 100000
+	statedb, _ := newState()
+	_, err := Call(statedb, header, nil, msg)
+	assert.Error(t, err)
+
+	// override the balance of sender
+	newState, msg, dispose2 := newSimulation(from, revertCode, StateOverride{
+		from: {Balance: new(big.Int).SetUint64(fromBalance)},
+	})
+	defer dispose2()
+
+	msg.GasLimit = 100000
+	statedb, _ = newState()
+	result, err := Call(statedb, header, nil, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Failed(), true)
+	assert.Equal(t, result.RevertReason(), "nope")
+
+	_, err = EstimateGas(newState, header, nil, msg)
+	assert.Equal(t, err.(*RevertError).Reason, "nope")
+}
+
+func Test_EstimateGas_Refund(t *testing.T) {
+	from := *crypto.MustGenerateRandomAddress()
+	newState, msg, dispose := newSimulation(from, clearCode, StateOverride{
+		from: {Balance: new(big.Int).SetUint64(fromBalance)},
+	})
+	defer dispose()
+
+	header := newTestBlockHeader(*crypto.MustGenerateRandomAddress())
+	gas, err := EstimateGas(newState, header, nil, msg)
+	assert.NoError(t, err)
+
+	// used gas is less than the required gas limit because of refund
+	msg.GasLimit = gas
+	statedb, _ := newState()
+	result, err := Call(statedb, header, nil, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Failed(), false)
+	assert.Equal(t, result.Receipt.UsedGas < gas, true)
+
+	msg.GasLimit = gas - 1
+	statedb, _ = newState()
+	result, err = Call(statedb, header, nil, msg)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Failed(), true)
+}
@@ -25,6 +25,7 @@ type Context struct {
 	Statedb     *state.Statedb
 	BlockHeader *types.BlockHeader
 	BcStore     store.BlockchainStore
+	VMConfig    *vm.Config // optional EVM config, e.g. the tracer to capture execution
 }
 
 // Process the tx
@@ -48,7 +49,7 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 	snapshot := ctx.Statedb.Prepare(ctx.TxIndex)
 
 	// create or execute contract
-	if contract := system.GetContractByAddress(ctx.Tx.Data.To); contract != nil { // system contract
+	if contract := system.GetContractByAddress(ctx.Tx.Data.To, height); contract != nil { // system contract
 		receipt, err = processSystemContract(ctx, contract, snapshot, leftOverGas)
 	} else if ctx.Tx.IsCrossShardTx() && !ctx.Tx.Data.To.IsEVMContract() { // cross shard tx
 		return processCrossShardTransaction(ctx, snapshot)
@@ -90,9 +91,16 @@ func Process(ctx *Context, height uint64) (*types.Receipt, error) {
 	// include the intrinsic gas
 	receipt.UsedGas += intrGas
 
-	// refund gas, capped to half of the used gas.
+	// refund gas, capped to half of the used gas except the debt gas.
 	refund := ctx.Statedb.GetRefund()
-	if maxRefund := receipt.UsedGas / 2; refund > maxRefund {
+	debtGas := deliveryGas(receipt.Debts)
+	for _, d := range receipt.Debts {
+		if call := d.GetCall(); call != nil {
+			debtGas += call.GasLimit
+		}
+	}
+
+	if maxRefund := (receipt.UsedGas - debtGas) / 2; refund > maxRefund {
 		refund = maxRefund
 	}
 	receipt.UsedGas -= refund
@@ -185,7 +193,12 @@ func processEvmContract(ctx *Context, gas uint64) (*types.Receipt, error) {
 	}
 
 	statedb := &evm.StateDB{Statedb: ctx.Statedb}
-	e := evm.NewEVMByDefaultConfig(ctx.Tx, statedb, ctx.BlockHeader, ctx.BcStore)
+	var e *vm.EVM
+	if ctx.VMConfig != nil {
+		e = evm.NewEVMWithConfig(ctx.Tx, statedb, ctx.BlockHeader, ctx.BcStore, *ctx.VMConfig)
+	} else {
+		e = evm.NewEVMByDefaultConfig(ctx.Tx, statedb, ctx.BlockHeader, ctx.BcStore)
+	}
 	caller := vm.AccountRef(ctx.Tx.Data.From)
 	var leftOverGas uint64
 
@@ -207,6 +220,18 @@ func processEvmContract(ctx *Context, gas uint64) (*types.Receipt, error) {
 	}
 	receipt.UsedGas = gas - leftOverGas
 	// fmt.Println("svm.go-183 processEVMContract [after Create] err: ", err)
+
+	// charge the debt gas for value transferred to other shards, which is paid to the miner of target shard.
+	if debts := statedb.GetDebts(); err == nil && len(debts) > 0 {
+		debtGas := deliveryGas(debts)
+		if leftOverGas < debtGas {
+			return receipt, vm.ErrOutOfGas
+		}
+
+		receipt.UsedGas += debtGas
+		receipt.Debts = debts
+	}
+
 	return receipt, err
 }
 
@@ -217,10 +242,15 @@ func handleFee(ctx *Context, receipt *types.Receipt, snapshot int) (*types.Recei
 	usedGas := new(big.Int).SetUint64(receipt.UsedGas)
 	totalFee := new(big.Int).Mul(usedGas, ctx.Tx.Data.GasPrice)
 
-	// Transfer fee to coinbase
+	// Transfer fee to coinbase, except the debt fee paid in target shard.
 	// Note, the sender should always have enough balance.
+	minerFee := new(big.Int).Set(totalFee)
+	for _, d := range receipt.Debts {
+		minerFee.Sub(minerFee, d.Fee())
+	}
+
 	ctx.Statedb.SubBalance(ctx.Tx.Data.From, totalFee)
-	ctx.Statedb.AddBalance(ctx.BlockHeader.Creator, totalFee)
+	ctx.Statedb.AddBalance(ctx.BlockHeader.Creator, minerFee)
 	receipt.TotalFee = totalFee.Uint64()
 
 	// Record statedb hash
@@ -239,6 +269,16 @@ func handleFee(ctx *Context, receipt *types.Receipt, snapshot int) (*types.Recei
 	return receipt, nil
 }
 
+// deliveryGas returns the total gas to deliver debts to other shards.
+func deliveryGas(debts []*types.Debt) uint64 {
+	gas := uint64(0)
+	for _, d := range debts {
+		gas += d.DeliveryGas()
+	}
+
+	return gas
+}
+
 func revertStatedb(statedb *state.Statedb, snapshot int, err error) error {
 	statedb.RevertToSnapshot(snapshot)
 	return err
@@ -98,6 +98,144 @@ func Test_Process_SysContract(t *testing.T) {
 	assert.Equal(t, toOriginalBalance, toCurrentBalance)
 }
 
+// systemContractProxy is the runtime code that forwards the call data and value to
+// the domain name system contract, and returns the result or reverts on failure:
+//
+//	calldatacopy(0, 0, calldatasize)
+//	success := call(gas, 0x0101, callvalue, 0, calldatasize, 0, 0)
+//	returndatacopy(0, 0, returndatasize)
+//	if success { return(0, returndatasize) } else { revert(0, 0) }
+const systemContractProxy = "0x36600060003760006000366000346101015af13d600060003e60215760006000fd5b3d6000f3"
+
+func newSystemContractCallContext(t *testing.T, height uint64, payload []byte) (*Context, common.Address) {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.NoError(t, err)
+
+	proxy := crypto.CreateAddress(ctx.Tx.Data.From, 1)
+	ctx.Statedb.CreateAccount(proxy)
+	ctx.Statedb.SetCode(proxy, mustHexToBytes(systemContractProxy))
+	ctx.BlockHeader.Height = height
+
+	ctx.Tx, err = types.NewMessageTransaction(ctx.Tx.Data.From, proxy, big.NewInt(0), big.NewInt(1), 5000000, 38, payload)
+	assert.NoError(t, err)
+
+	return ctx, proxy
+}
+
+func Test_Process_SystemContractCall(t *testing.T) {
+	name := []byte("scdo-fan")
+	create := append([]byte{system.CmdCreateDomainName}, name...)
+	ctx, proxy := newSystemContractCallContext(t, common.SystemContractCallForkHeight, create)
+	ctx.Tx, _ = types.NewMessageTransaction(ctx.Tx.Data.From, proxy, system.DomainRegistrationFee, big.NewInt(1), 5000000, 38, create)
+
+	// the proxy contract is the owner
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, proxy.Bytes(), receipt.Result)
+	assert.True(t, receipt.UsedGas > uint64(50000)+ctx.Tx.IntrinsicGas())
+
+	query := append([]byte{system.CmdGetDomainNameOwner}, name...)
+	ctx.Tx, _ = types.NewMessageTransaction(ctx.Tx.Data.From, proxy, big.NewInt(0), big.NewInt(1), 5000000, 39, query)
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, proxy.Bytes(), receipt.Result)
+
+	// the failure of system contract reverts the call
+	ctx.Tx, _ = types.NewMessageTransaction(ctx.Tx.Data.From, proxy, system.DomainRegistrationFee, big.NewInt(1), 5000000, 40, create)
+	receipt, err = Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed)
+}
+
+func Test_Process_SystemContractCallBeforeFork(t *testing.T) {
+	create := append([]byte{system.CmdCreateDomainName}, []byte("scdo-fan")...)
+	ctx, _ := newSystemContractCallContext(t, common.SystemContractCallForkHeight-1, create)
+
+	// the system contract is not callable by EVM contracts before fork
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, 0, len(receipt.Result))
+	assert.False(t, ctx.Statedb.Exist(system.DomainNameContractAddress))
+}
+
+// valueForwarder is the runtime code that transfers the call value to the address in call data:
+//
+//	call(gas, calldataload(0), callvalue, 0, 0, 0, 0)
+const valueForwarder = "0x6000600060006000346000355af15000"
+
+func newContractDebtContext(t *testing.T, height uint64) (*Context, common.Address, common.Address) {
+	ctx, err := newTestContext(big.NewInt(0))
+	assert.NoError(t, err)
+
+	forwarder := crypto.CreateAddress(ctx.Tx.Data.From, 1)
+	ctx.Statedb.CreateAccount(forwarder)
+	ctx.Statedb.SetCode(forwarder, mustHexToBytes(valueForwarder))
+	ctx.BlockHeader.Height = height
+	ctx.BlockHeader.Creator = *crypto.MustGenerateShardAddress(forwarder.Shard())
+	ctx.Statedb.CreateAccount(ctx.BlockHeader.Creator)
+
+	shard := uint(1)
+	if forwarder.Shard() == 1 {
+		shard = 2
+	}
+	recipient := *crypto.MustGenerateShardAddress(shard)
+
+	payload := common.BytesToHash(recipient.Bytes()).Bytes()
+	ctx.Tx, err = types.NewMessageTransaction(ctx.Tx.Data.From, forwarder, big.NewInt(100), big.NewInt(1), 5000000, 38, payload)
+	assert.NoError(t, err)
+
+	return ctx, forwarder, recipient
+}
+
+func Test_Process_ContractDebt(t *testing.T) {
+	ctx, forwarder, recipient := newContractDebtContext(t, common.ContractDebtForkHeight)
+
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, 1, len(receipt.Debts))
+
+	debt := receipt.Debts[0]
+	assert.Equal(t, ctx.Tx.Hash, debt.Data.TxHash)
+	assert.Equal(t, forwarder, debt.Data.From)
+	assert.Equal(t, recipient, debt.Data.Account)
+	assert.Equal(t, big.NewInt(100), debt.Data.Amount)
+	assert.Equal(t, debt.Data.Hash(), debt.Hash)
+	assert.Equal(t, types.NewContractDebt(ctx.Tx, 0, forwarder, recipient, big.NewInt(100)), debt)
+
+	// the value is carried by debt, and the debt fee is paid to the miner of target shard
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(forwarder))
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(recipient))
+	assert.True(t, receipt.UsedGas > types.DebtGas)
+	minerFee := new(big.Int).Sub(new(big.Int).SetUint64(receipt.TotalFee), debt.Fee())
+	assert.Equal(t, minerFee, ctx.Statedb.GetBalance(ctx.BlockHeader.Creator))
+}
+
+func Test_Process_ContractDebtBeforeFork(t *testing.T) {
+	ctx, forwarder, _ := newContractDebtContext(t, common.ContractDebtForkHeight-1)
+
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.False(t, receipt.Failed)
+	assert.Equal(t, 0, len(receipt.Debts))
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(forwarder))
+}
+
+func Test_Process_ContractDebtOutOfGas(t *testing.T) {
+	ctx, forwarder, _ := newContractDebtContext(t, common.ContractDebtForkHeight)
+	ctx.Tx.Data.GasLimit = ctx.Tx.IntrinsicGas() + 40000
+	ctx.Tx.Hash = ctx.Tx.CalculateHash()
+
+	receipt, err := Process(ctx, ctx.BlockHeader.Height)
+	assert.NoError(t, err)
+	assert.True(t, receipt.Failed)
+	assert.Equal(t, 0, len(receipt.Debts))
+	assert.Equal(t, big.NewInt(0), ctx.Statedb.GetBalance(forwarder))
+}
+
 func Test_Process_CrossTransfer(t *testing.T) {
 	ctx, err := newTestContext(big.NewInt(1000))
 	assert.NoError(t, err)
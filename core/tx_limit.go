@@ -0,0 +1,194 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// ErrTxLimitExceeded is returned when an account sends or receives more txs than the limit in a relay range
+var ErrTxLimitExceeded = errors.New("account tx count exceeds the limit per relay range")
+
+// TxLimit limits the number of txs sent or received by each account in every relay range of
+// subchain, so that the checkpoints relayed to the root chain could be challenged in time.
+type TxLimit struct {
+	Limit uint64 // max number of txs of an account in a relay range
+	Range uint64 // number of blocks in a relay range
+}
+
+// NewTxLimit returns the tx limit of the genesis info, or nil if txs are not limited
+func NewTxLimit(info *GenesisInfo) *TxLimit {
+	if info.TxLimitPerRelay == 0 {
+		return nil
+	}
+
+	return &TxLimit{Limit: info.TxLimitPerRelay, Range: info.GetRelayRange()}
+}
+
+// EpochBase returns the height of the last block before the relay range of height
+func (l *TxLimit) EpochBase(height uint64) uint64 {
+	if height == 0 {
+		return 0
+	}
+
+	return (height - 1) / l.Range * l.Range
+}
+
+// TxCounter counts the txs of accounts packed in a block against the tx limit
+type TxCounter struct {
+	limit  uint64
+	base   *state.Statedb
+	parent *state.Statedb
+	used   map[common.Address]uint64
+}
+
+// SetTxLimit sets the tx limit of accounts, which is nil if txs are not limited
+func (bc *Blockchain) SetTxLimit(limit *TxLimit) {
+	bc.txLimit = limit
+}
+
+// NewTxCounter creates the tx counter of the block at height on the parent statedb of parent hash.
+// It returns nil if txs are not limited, which accepts all the txs.
+func (bc *Blockchain) NewTxCounter(parent *state.Statedb, parentHash common.Hash, height uint64) (*TxCounter, error) {
+	if bc.txLimit == nil {
+		return nil, nil
+	}
+
+	baseHeight := bc.txLimit.EpochBase(height)
+	header := bc.ancestorHeader(parentHash, baseHeight)
+	if header == nil {
+		return nil, fmt.Errorf("failed to get the header at relay range base height %d", baseHeight)
+	}
+
+	base, err := bc.GetState(header.StateHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get the statedb at relay range base height %d", baseHeight)
+	}
+
+	return &TxCounter{
+		limit:  bc.txLimit.Limit,
+		base:   base,
+		parent: parent,
+		used:   make(map[common.Address]uint64),
+	}, nil
+}
+
+// ancestorHeader returns the header at height in the ancestry of the block hash, so that the
+// blocks in a fork are counted against their own relay range base. It walks back by parent hash
+// until the canonical chain is reached.
+func (bc *Blockchain) ancestorHeader(hash common.Hash, height uint64) *types.BlockHeader {
+	header := bc.GetHeaderByHash(hash)
+	for header != nil && header.Height > height {
+		if canonicalHash, err := bc.bcStore.GetBlockHash(header.Height); err == nil && canonicalHash.Equal(hash) {
+			return bc.GetHeaderByHeight(height)
+		}
+
+		hash = header.PreviousBlockHash
+		header = bc.GetHeaderByHash(hash)
+	}
+
+	if header == nil || header.Height != height {
+		return nil
+	}
+
+	return header
+}
+
+// VerifyTxLimit verifies that no account exceeds the tx limit with the txs in block
+func (bc *Blockchain) VerifyTxLimit(block *types.Block) error {
+	if bc.txLimit == nil {
+		return nil
+	}
+
+	parent := bc.GetHeaderByHash(block.Header.PreviousBlockHash)
+	if parent == nil {
+		return fmt.Errorf("failed to get the parent header %v", block.Header.PreviousBlockHash.Hex())
+	}
+
+	statedb, err := bc.GetState(parent.StateHash)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to get the parent statedb %v", parent.StateHash)
+	}
+
+	return bc.verifyTxLimit(statedb, block)
+}
+
+func (bc *Blockchain) verifyTxLimit(parent *state.Statedb, block *types.Block) error {
+	counter, err := bc.NewTxCounter(parent, block.Header.PreviousBlockHash, block.Height())
+	if err != nil {
+		return err
+	}
+
+	for i, tx := range block.GetExcludeRewardTransactions() {
+		if err = counter.Add(tx); err != nil {
+			return errors.NewStackedErrorf(err, "failed to add tx[%v]", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Add counts the tx if neither the sender nor the receiver exceeds the tx limit.
+// Otherwise, return ErrTxLimitExceeded.
+func (c *TxCounter) Add(tx *types.Transaction) error {
+	if err := c.Check(tx); err != nil {
+		return err
+	}
+
+	c.Count(tx)
+	return nil
+}
+
+// Check returns ErrTxLimitExceeded if the sender or the receiver of tx exceeds the tx limit,
+// but the tx is not counted.
+func (c *TxCounter) Check(tx *types.Transaction) error {
+	if c == nil {
+		return nil
+	}
+
+	for _, account := range txLimitAccounts(tx) {
+		used, ok := c.used[account]
+		if !ok {
+			// the state of account is read before it is changed by the txs in the block
+			if count, baseCount := c.parent.GetTxCount(account), c.base.GetTxCount(account); count > baseCount {
+				used = count - baseCount
+			}
+			c.used[account] = used
+		}
+
+		if used >= c.limit {
+			return errors.NewStackedErrorf(ErrTxLimitExceeded, "account %v sent or received %v txs", account.Hex(), used)
+		}
+	}
+
+	return nil
+}
+
+// Count counts the tx that has been checked and applied
+func (c *TxCounter) Count(tx *types.Transaction) {
+	if c == nil {
+		return
+	}
+
+	for _, account := range txLimitAccounts(tx) {
+		c.used[account]++
+	}
+}
+
+// txLimitAccounts returns the sender and receiver of tx that are limited
+func txLimitAccounts(tx *types.Transaction) []common.Address {
+	accounts := []common.Address{tx.Data.From}
+	if !tx.Data.To.IsEmpty() && tx.Data.To != tx.Data.From {
+		accounts = append(accounts, tx.Data.To)
+	}
+
+	return accounts
+}
@@ -18,6 +18,12 @@ import (
 
 const transactionTimeoutDuration = 3 * time.Hour
 
+// txLimitChain is the blockchain that limits the txs of accounts in each relay range
+type txLimitChain interface {
+	CurrentHeader() *types.BlockHeader
+	NewTxCounter(parent *state.Statedb, parentHash common.Hash, height uint64) (*TxCounter, error)
+}
+
 // TransactionPool is a thread-safe container for transactions received from the network or submitted locally.
 // A transaction will be removed from the pool once included in a blockchain or pending time too long (> transactionTimeoutDuration).
 type TransactionPool struct {
@@ -60,6 +66,18 @@ func NewTransactionPool(config TransactionPoolConfig, chain blockchain) *Transac
 			return errors.NewStackedError(err, "failed to validate tx")
 		}
 
+		if limitChain, ok := chain.(txLimitChain); ok {
+			current := limitChain.CurrentHeader()
+			counter, err := limitChain.NewTxCounter(state, current.Hash(), current.Height+1)
+			if err != nil {
+				return errors.NewStackedError(err, "failed to create tx counter")
+			}
+
+			if err = counter.Add(tx); err != nil {
+				return errors.NewStackedError(err, "failed to validate tx limit")
+			}
+		}
+
 		return nil
 	}
 
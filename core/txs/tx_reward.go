@@ -12,6 +12,7 @@ import (
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
 	"github.com/scdoproject/go-stem/consensus"
+	"github.com/scdoproject/go-stem/contract/system"
 	"github.com/scdoproject/go-stem/core/state"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
@@ -99,10 +100,20 @@ func ValidateRewardTx(tx *types.Transaction, header *types.BlockHeader) error {
 	return nil
 }
 
-// ApplyRewardTx applies the reward tx with specified statedb.
-func ApplyRewardTx(tx *types.Transaction, statedb *state.Statedb) (*types.Receipt, error) {
+// ApplyRewardTx applies the reward tx with specified statedb. After the masternode fork,
+// a share of the reward is paid to masternodes, and the miner receives the rest.
+func ApplyRewardTx(tx *types.Transaction, statedb *state.Statedb, header *types.BlockHeader) (*types.Receipt, error) {
+	reward, err := system.ShareMasternodeReward(statedb, header, tx.Data.Amount)
+	if err != nil {
+		return nil, errors.NewStackedError(err, "failed to share reward with masternodes")
+	}
+
 	statedb.CreateAccount(tx.Data.To)
-	statedb.AddBalance(tx.Data.To, tx.Data.Amount)
+	statedb.AddBalance(tx.Data.To, reward)
+
+	if _, err = system.SlashDoubleSigners(statedb, header, tx.Data.To); err != nil {
+		return nil, errors.NewStackedError(err, "failed to slash double signers")
+	}
 
 	hash, err := statedb.Hash()
 	if err != nil {
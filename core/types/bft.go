@@ -26,15 +26,34 @@ type BftExtra struct {
 	Verifiers     []common.Address
 	Seal          []byte
 	CommittedSeal [][]byte
+
+	// AggregatedSeal is the BLS aggregated commit seal after BLSSealForkHeight, and the
+	// CommittedSeal is empty in this case. The SignerBitmap marks the signers in the list of
+	// verifiers in ascending order of address.
+	AggregatedSeal []byte
+	SignerBitmap   []byte
+
+	// Evidence is the double sign evidence included by the proposer, which is covered by the
+	// header hash and removes the offenders from the verifiers.
+	Evidence []*DoubleSignEvidence
 }
 
 // EncodeRLP serializes bftExtra into the Ethereum RLP format.
 func (bftExtra *BftExtra) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{
+	fields := []interface{}{
 		bftExtra.Verifiers,
 		bftExtra.Seal,
 		bftExtra.CommittedSeal,
-	})
+	}
+	// keep the encoding of headers without aggregated seal and evidence unchanged
+	if len(bftExtra.AggregatedSeal) > 0 || len(bftExtra.SignerBitmap) > 0 || len(bftExtra.Evidence) > 0 {
+		fields = append(fields, bftExtra.AggregatedSeal, bftExtra.SignerBitmap)
+	}
+	if len(bftExtra.Evidence) > 0 {
+		fields = append(fields, bftExtra.Evidence)
+	}
+
+	return rlp.Encode(w, fields)
 }
 
 // DecodeRLP implements rlp.Decoder, and load the bft fields from a RLP stream.
@@ -43,11 +62,25 @@ func (bftExtra *BftExtra) DecodeRLP(s *rlp.Stream) error {
 		Verifiers     []common.Address
 		Seal          []byte
 		CommittedSeal [][]byte
+		Rest          []rlp.RawValue `rlp:"tail"`
 	}
 	if err := s.Decode(&bftBlockExtra); err != nil {
 		return err
 	}
 	bftExtra.Verifiers, bftExtra.Seal, bftExtra.CommittedSeal = bftBlockExtra.Verifiers, bftBlockExtra.Seal, bftBlockExtra.CommittedSeal
+	if len(bftBlockExtra.Rest) >= 2 {
+		if err := rlp.DecodeBytes(bftBlockExtra.Rest[0], &bftExtra.AggregatedSeal); err != nil {
+			return err
+		}
+		if err := rlp.DecodeBytes(bftBlockExtra.Rest[1], &bftExtra.SignerBitmap); err != nil {
+			return err
+		}
+	}
+	if len(bftBlockExtra.Rest) >= 3 {
+		if err := rlp.DecodeBytes(bftBlockExtra.Rest[2], &bftExtra.Evidence); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -81,6 +114,7 @@ func BftFilteredHeader(h *BlockHeader, keepSeal bool) *BlockHeader {
 		bftExtra.Seal = []byte{}
 	}
 	bftExtra.CommittedSeal = [][]byte{}
+	bftExtra.AggregatedSeal, bftExtra.SignerBitmap = nil, nil
 
 	payload, err := rlp.EncodeToBytes(&bftExtra)
 	if err != nil {
@@ -0,0 +1,23 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package types
+
+import (
+	"github.com/scdoproject/go-stem/common"
+)
+
+// Checkpoint is a PoW block finalized by the committed seals of the finality committee.
+// The blocks lower than the checkpoint or not descended from it will never be accepted.
+type Checkpoint struct {
+	Height uint64      // height of the checkpoint block, which is a multiple of the checkpoint interval
+	Hash   common.Hash // hash of the checkpoint block
+	Seals  [][]byte    // committed seals of the committee on the checkpoint
+}
+
+// Index returns the sequence of the checkpoint that is voted by the committee
+func (cp *Checkpoint) Index() uint64 {
+	return cp.Height / common.FinalityCheckpointInterval
+}
@@ -18,6 +18,9 @@ import (
 // DebtSize debt serialized size
 const DebtSize = 118
 
+// DebtCallSize is the extra serialized size of debt with cross shard contract call
+const DebtCallSize = 12
+
 var (
 	errWrongShardNumber  = errors.New("wrong from shard number")
 	errInvalidAccount    = errors.New("invalid account, unexpected shard number")
@@ -34,7 +37,18 @@ type DebtData struct {
 	Account common.Address // debt for account
 	Amount  *big.Int       // debt amount
 	Price   *big.Int       // debt price
-	Code    common.Bytes   // debt contract code
+	Code    common.Bytes   // debt contract code, or the input of cross shard contract call
+
+	// Call is the cross shard contract call carried by debt, which has at most one element.
+	// It must be the last field, so that the encoding is unchanged for debts without call.
+	Call []*DebtCall `rlp:"tail"`
+}
+
+// DebtCall is the contract call from a contract to another contract of other shard,
+// which is executed asynchronously when the debt is applied in the target shard.
+type DebtCall struct {
+	GasLimit uint64 // gas allowance to execute the call in target shard
+	Reply    bool   // indicates the call is the callback to the origin contract, which is never replied
 }
 
 // Debt debt class
@@ -118,7 +132,26 @@ func (data *DebtData) Hash() common.Hash {
 
 // Size is the bytes of debt
 func (d *Debt) Size() int {
-	return DebtSize + len(d.Data.Code)
+	return DebtSize + len(d.Data.Code) + len(d.Data.Call)*DebtCallSize
+}
+
+// GetCall returns the cross shard contract call carried by debt, or nil if not a call.
+func (d *Debt) GetCall() *DebtCall {
+	if len(d.Data.Call) == 0 {
+		return nil
+	}
+
+	return d.Data.Call[0]
+}
+
+// DeliveryGas returns the gas to deliver the debt to the target shard, excluding the
+// gas allowance of the call. The cross shard call also pays for the delivery of its reply.
+func (d *Debt) DeliveryGas() uint64 {
+	if call := d.GetCall(); call != nil && !call.Reply {
+		return 2 * DebtGas
+	}
+
+	return DebtGas
 }
 
 func (d *Debt) FromAccount() common.Address {
@@ -153,7 +186,12 @@ func GetDebtsSize(debts []*Debt) int {
 
 func (d *Debt) Fee() *big.Int {
 	// @todo for contract case, should use the fee in tx receipt
-	return new(big.Int).Mul(d.Data.Price, new(big.Int).SetUint64(DebtGas))
+	gas := d.DeliveryGas()
+	if call := d.GetCall(); call != nil {
+		gas += call.GasLimit
+	}
+
+	return new(big.Int).Mul(d.Data.Price, new(big.Int).SetUint64(gas))
 }
 
 // NewDebtWithContext new a debt
@@ -194,6 +232,7 @@ func newDebt(tx *Transaction, withContext bool) *Debt {
 		Amount:  big.NewInt(0).Set(tx.Data.Amount),
 		Price:   tx.Data.GasPrice,
 		Code:    make([]byte, 0), // @todo init when its a contract tx
+		Call:    make([]*DebtCall, 0),
 	}
 
 	if tx.Data.To.IsEVMContract() {
@@ -208,6 +247,59 @@ func newDebt(tx *Transaction, withContext bool) *Debt {
 	return debt
 }
 
+// NewContractDebt creates a debt for the value transferred by the contract to an account of other shard.
+// The index is the sequence of debts created in the tx, so that the debt hash is unique.
+func NewContractDebt(tx *Transaction, index uint64, contract, account common.Address, amount *big.Int) *Debt {
+	data := DebtData{
+		TxHash:  tx.Hash,
+		From:    contract,
+		Nonce:   index,
+		Account: account,
+		Amount:  new(big.Int).Set(amount),
+		Price:   tx.Data.GasPrice,
+		Code:    make([]byte, 0),
+		Call:    make([]*DebtCall, 0),
+	}
+
+	return &Debt{
+		Data: data,
+		Hash: data.Hash(),
+	}
+}
+
+// NewCallDebt creates a debt for the contract to call the contract of other shard. The debt fee
+// includes the gas allowance of the call, and the reply is sent back from the target shard.
+func NewCallDebt(tx *Transaction, index uint64, contract, target common.Address, amount *big.Int, input []byte, gas uint64) *Debt {
+	return newCallDebt(tx.Hash, tx.Data.GasPrice, index, contract, target, amount, input, &DebtCall{GasLimit: gas})
+}
+
+// NewReplyDebt creates a debt to reply the result of cross shard call to the origin contract.
+func NewReplyDebt(call *Debt, index uint64, amount *big.Int, input []byte, gas uint64) *Debt {
+	return newCallDebt(call.Hash, call.Data.Price, index, call.Data.Account, call.Data.From, amount, input, &DebtCall{GasLimit: gas, Reply: true})
+}
+
+func newCallDebt(hash common.Hash, price *big.Int, index uint64, from, to common.Address, amount *big.Int, input []byte, call *DebtCall) *Debt {
+	data := DebtData{
+		TxHash:  hash,
+		From:    from,
+		Nonce:   index,
+		Account: to,
+		Amount:  new(big.Int).Set(amount),
+		Price:   price,
+		Code:    common.CopyBytes(input),
+		Call:    []*DebtCall{call},
+	}
+
+	if data.Code == nil {
+		data.Code = make([]byte, 0)
+	}
+
+	return &Debt{
+		Data: data,
+		Hash: data.Hash(),
+	}
+}
+
 // NewDebts new debts
 func NewDebts(txs []*Transaction) []*Debt {
 	debts := make([]*Debt, 0)
@@ -237,6 +329,18 @@ func NewDebtMap(txs []*Transaction) [][]*Debt {
 	return debts
 }
 
+// NewDebtMapWithReceipts new debt map of both txs and the contract debts in receipts
+func NewDebtMapWithReceipts(txs []*Transaction, receipts []*Receipt) [][]*Debt {
+	debts := NewDebtMap(txs)
+
+	for _, d := range GetReceiptsDebts(receipts) {
+		shard := d.Data.Account.Shard()
+		debts[shard] = append(debts[shard], d)
+	}
+
+	return debts
+}
+
 // DebtArrayToMap transfer debt array to debt map
 func DebtArrayToMap(debts []*Debt) [][]*Debt {
 	debtsMap := make([][]*Debt, common.ShardCount+1)
@@ -0,0 +1,217 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// the codes of istanbul and bft consensus messages that are signed once in a round
+const (
+	votePreprepare uint64 = iota
+	votePrepare
+	voteCommit
+)
+
+var (
+	// ErrNotConsensusVote is returned if the consensus message is not a vote of proposal
+	ErrNotConsensusVote = errors.New("not a consensus vote")
+	// ErrInvalidEvidence is returned if the messages of evidence are not conflicting votes
+	ErrInvalidEvidence = errors.New("invalid double sign evidence")
+)
+
+// consensusMessage is the signed message of istanbul and bft consensus
+type consensusMessage struct {
+	Code          uint64
+	Msg           []byte
+	Address       common.Address
+	Signature     []byte
+	CommittedSeal []byte
+}
+
+type consensusView struct {
+	Round    *big.Int
+	Sequence *big.Int
+}
+
+// ConsensusVote is the vote for a proposal in the signed consensus message
+type ConsensusVote struct {
+	Signer common.Address
+	Code   uint64
+	Height uint64
+	Round  uint64
+	Digest common.Hash // hash of the proposal
+}
+
+// DecodeConsensusVote decodes the vote from the signed consensus message payload. It returns
+// ErrNotConsensusVote if the message is not a preprepare, prepare or commit message. The
+// signature is not verified.
+func DecodeConsensusVote(payload []byte) (*ConsensusVote, error) {
+	var msg consensusMessage
+	if err := rlp.DecodeBytes(payload, &msg); err != nil {
+		return nil, err
+	}
+
+	return msg.vote()
+}
+
+func (msg *consensusMessage) vote() (*ConsensusVote, error) {
+	var view *consensusView
+	var digest common.Hash
+	switch msg.Code {
+	case votePreprepare:
+		var preprepare struct {
+			View     *consensusView
+			Proposal *Block
+		}
+		if err := rlp.DecodeBytes(msg.Msg, &preprepare); err != nil {
+			return nil, err
+		}
+		if preprepare.Proposal == nil || preprepare.Proposal.Header == nil {
+			return nil, ErrNotConsensusVote
+		}
+		view, digest = preprepare.View, preprepare.Proposal.Header.Hash()
+	case votePrepare, voteCommit:
+		var subject struct {
+			View   *consensusView
+			Digest common.Hash
+		}
+		if err := rlp.DecodeBytes(msg.Msg, &subject); err != nil {
+			return nil, err
+		}
+		view, digest = subject.View, subject.Digest
+	default:
+		return nil, ErrNotConsensusVote
+	}
+
+	if view == nil || view.Round == nil || view.Sequence == nil || !view.Round.IsUint64() || !view.Sequence.IsUint64() {
+		return nil, ErrNotConsensusVote
+	}
+
+	return &ConsensusVote{
+		Signer: msg.Address,
+		Code:   msg.Code,
+		Height: view.Sequence.Uint64(),
+		Round:  view.Round.Uint64(),
+		Digest: digest,
+	}, nil
+}
+
+// verifySignature checks the message is signed by the address in message
+func (msg *consensusMessage) verifySignature() error {
+	payload, err := rlp.EncodeToBytes(&consensusMessage{
+		Code:          msg.Code,
+		Msg:           msg.Msg,
+		Address:       msg.Address,
+		Signature:     []byte{},
+		CommittedSeal: msg.CommittedSeal,
+	})
+	if err != nil {
+		return err
+	}
+
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(payload), msg.Signature)
+	if err != nil {
+		return err
+	}
+
+	if *crypto.GetAddress(pubkey) != msg.Address {
+		return ErrInvalidEvidence
+	}
+
+	return nil
+}
+
+// Conflicts returns whether the votes are signed by the same signer for different proposals
+// in the same height, round and step.
+func (vote *ConsensusVote) Conflicts(other *ConsensusVote) bool {
+	return vote.Signer == other.Signer && vote.Code == other.Code && vote.Height == other.Height &&
+		vote.Round == other.Round && vote.Digest != other.Digest
+}
+
+// DoubleSignEvidence is the proof that a validator signed conflicting consensus votes, which
+// contains the payloads of both signed messages.
+type DoubleSignEvidence struct {
+	First  []byte
+	Second []byte
+}
+
+// NewDoubleSignEvidence creates the evidence of the conflicting messages, which are ordered
+// so that the evidence of the same messages has the same hash.
+func NewDoubleSignEvidence(first, second []byte) *DoubleSignEvidence {
+	if bytes.Compare(first, second) > 0 {
+		first, second = second, first
+	}
+
+	return &DoubleSignEvidence{
+		First:  common.CopyBytes(first),
+		Second: common.CopyBytes(second),
+	}
+}
+
+// Hash returns the hash of evidence
+func (ev *DoubleSignEvidence) Hash() common.Hash {
+	return crypto.MustHash(ev)
+}
+
+// Verify checks the signatures of both messages and that they are conflicting votes, and
+// returns the vote of the first message, whose signer is the offender.
+func (ev *DoubleSignEvidence) Verify() (*ConsensusVote, error) {
+	if bytes.Compare(ev.First, ev.Second) >= 0 {
+		return nil, ErrInvalidEvidence
+	}
+
+	var votes [2]*ConsensusVote
+	for i, payload := range [][]byte{ev.First, ev.Second} {
+		var msg consensusMessage
+		if err := rlp.DecodeBytes(payload, &msg); err != nil {
+			return nil, err
+		}
+
+		vote, err := msg.vote()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = msg.verifySignature(); err != nil {
+			return nil, ErrInvalidEvidence
+		}
+
+		votes[i] = vote
+	}
+
+	if !votes[0].Conflicts(votes[1]) {
+		return nil, ErrInvalidEvidence
+	}
+
+	return votes[0], nil
+}
+
+// HeaderEvidence returns the double sign evidence included in the istanbul or bft header
+func HeaderEvidence(h *BlockHeader) ([]*DoubleSignEvidence, error) {
+	switch h.Consensus {
+	case IstanbulConsensus:
+		extra, err := ExtractIstanbulExtra(h)
+		if err != nil {
+			return nil, err
+		}
+		return extra.Evidence, nil
+	case BftConsensus:
+		extra, err := ExtractBftExtra(h)
+		if err != nil {
+			return nil, err
+		}
+		return extra.Evidence, nil
+	}
+
+	return nil, nil
+}
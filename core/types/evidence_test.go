@@ -0,0 +1,110 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignedVote(t *testing.T, key *ecdsa.PrivateKey, code, height, round uint64, digest common.Hash) []byte {
+	subject, err := rlp.EncodeToBytes([]interface{}{
+		[]interface{}{new(big.Int).SetUint64(round), new(big.Int).SetUint64(height)},
+		digest,
+	})
+	assert.NoError(t, err)
+
+	msg := &consensusMessage{
+		Code:          code,
+		Msg:           subject,
+		Address:       *crypto.GetAddress(&key.PublicKey),
+		Signature:     []byte{},
+		CommittedSeal: []byte{},
+	}
+
+	payload, err := rlp.EncodeToBytes(msg)
+	assert.NoError(t, err)
+
+	sig, err := crypto.Sign(key, crypto.Keccak256(payload))
+	assert.NoError(t, err)
+	msg.Signature = sig.Sig
+
+	payload, err = rlp.EncodeToBytes(msg)
+	assert.NoError(t, err)
+	return payload
+}
+
+func Test_DoubleSignEvidence(t *testing.T) {
+	_, key := crypto.MustGenerateShardKeyPair(1)
+	first := newSignedVote(t, key, voteCommit, 10, 1, common.StringToHash("a"))
+	second := newSignedVote(t, key, voteCommit, 10, 1, common.StringToHash("b"))
+
+	vote, err := DecodeConsensusVote(first)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), vote.Height)
+	assert.Equal(t, uint64(1), vote.Round)
+
+	ev := NewDoubleSignEvidence(second, first)
+	assert.Equal(t, ev.Hash(), NewDoubleSignEvidence(first, second).Hash())
+
+	offense, err := ev.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, *crypto.GetAddress(&key.PublicKey), offense.Signer)
+	assert.Equal(t, voteCommit, offense.Code)
+
+	// same proposal
+	_, err = NewDoubleSignEvidence(first, first).Verify()
+	assert.Equal(t, ErrInvalidEvidence, err)
+
+	// different round
+	third := newSignedVote(t, key, voteCommit, 10, 2, common.StringToHash("b"))
+	_, err = NewDoubleSignEvidence(first, third).Verify()
+	assert.Equal(t, ErrInvalidEvidence, err)
+
+	// different signers
+	_, other := crypto.MustGenerateShardKeyPair(1)
+	fourth := newSignedVote(t, other, voteCommit, 10, 1, common.StringToHash("b"))
+	_, err = NewDoubleSignEvidence(first, fourth).Verify()
+	assert.Equal(t, ErrInvalidEvidence, err)
+
+	// round change is not a vote
+	_, err = DecodeConsensusVote(newSignedVote(t, key, 3, 10, 1, common.Hash{}))
+	assert.Equal(t, ErrNotConsensusVote, err)
+}
+
+func Test_IstanbulExtraEvidence(t *testing.T) {
+	_, key := crypto.MustGenerateShardKeyPair(1)
+	ev := NewDoubleSignEvidence(
+		newSignedVote(t, key, votePrepare, 5, 0, common.StringToHash("a")),
+		newSignedVote(t, key, votePrepare, 5, 0, common.StringToHash("b")),
+	)
+
+	extra := &IstanbulExtra{
+		Validators:    []common.Address{*crypto.GetAddress(&key.PublicKey)},
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+		Evidence:      []*DoubleSignEvidence{ev},
+	}
+
+	payload, err := rlp.EncodeToBytes(extra)
+	assert.NoError(t, err)
+
+	h := &BlockHeader{Consensus: IstanbulConsensus, ExtraData: append(make([]byte, IstanbulExtraVanity), payload...)}
+	evidence, err := HeaderEvidence(h)
+	assert.NoError(t, err)
+	assert.Equal(t, []*DoubleSignEvidence{ev}, evidence)
+
+	// the evidence is covered by the header hash
+	filtered, err := ExtractIstanbulExtra(IstanbulFilteredHeader(h, true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(filtered.Evidence))
+}
@@ -29,15 +29,34 @@ type IstanbulExtra struct {
 	Validators    []common.Address
 	Seal          []byte
 	CommittedSeal [][]byte
+
+	// AggregatedSeal is the BLS aggregated commit seal after BLSSealForkHeight, and the
+	// CommittedSeal is empty in this case. The SignerBitmap marks the signers in the list of
+	// validators in ascending order of address.
+	AggregatedSeal []byte
+	SignerBitmap   []byte
+
+	// Evidence is the double sign evidence included by the proposer, which is covered by the
+	// header hash and removes the offenders from the validators.
+	Evidence []*DoubleSignEvidence
 }
 
 // EncodeRLP serializes ist into the Ethereum RLP format.
 func (ist *IstanbulExtra) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{
+	fields := []interface{}{
 		ist.Validators,
 		ist.Seal,
 		ist.CommittedSeal,
-	})
+	}
+	// keep the encoding of headers without aggregated seal and evidence unchanged
+	if len(ist.AggregatedSeal) > 0 || len(ist.SignerBitmap) > 0 || len(ist.Evidence) > 0 {
+		fields = append(fields, ist.AggregatedSeal, ist.SignerBitmap)
+	}
+	if len(ist.Evidence) > 0 {
+		fields = append(fields, ist.Evidence)
+	}
+
+	return rlp.Encode(w, fields)
 }
 
 // DecodeRLP implements rlp.Decoder, and load the istanbul fields from a RLP stream.
@@ -46,11 +65,25 @@ func (ist *IstanbulExtra) DecodeRLP(s *rlp.Stream) error {
 		Validators    []common.Address
 		Seal          []byte
 		CommittedSeal [][]byte
+		Rest          []rlp.RawValue `rlp:"tail"`
 	}
 	if err := s.Decode(&istanbulExtra); err != nil {
 		return err
 	}
 	ist.Validators, ist.Seal, ist.CommittedSeal = istanbulExtra.Validators, istanbulExtra.Seal, istanbulExtra.CommittedSeal
+	if len(istanbulExtra.Rest) >= 2 {
+		if err := rlp.DecodeBytes(istanbulExtra.Rest[0], &ist.AggregatedSeal); err != nil {
+			return err
+		}
+		if err := rlp.DecodeBytes(istanbulExtra.Rest[1], &ist.SignerBitmap); err != nil {
+			return err
+		}
+	}
+	if len(istanbulExtra.Rest) >= 3 {
+		if err := rlp.DecodeBytes(istanbulExtra.Rest[2], &ist.Evidence); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -84,6 +117,7 @@ func IstanbulFilteredHeader(h *BlockHeader, keepSeal bool) *BlockHeader {
 		istanbulExtra.Seal = []byte{}
 	}
 	istanbulExtra.CommittedSeal = [][]byte{}
+	istanbulExtra.AggregatedSeal, istanbulExtra.SignerBitmap = nil, nil
 
 	payload, err := rlp.EncodeToBytes(&istanbulExtra)
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/hexutil"
 	//"github.com/scdoproject/go-stem/crypto"
@@ -80,3 +81,47 @@ func TestExtractToIstanbul(t *testing.T) {
 		}
 	}
 }
+
+func TestIstanbulExtraAggregatedSeal(t *testing.T) {
+	extra := &IstanbulExtra{
+		Validators:    []common.Address{common.BytesToAddress([]byte{1})},
+		Seal:          []byte{1, 2},
+		CommittedSeal: [][]byte{{3}},
+	}
+
+	// the encoding without aggregated seal is unchanged
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, _ := rlp.EncodeToBytes([]interface{}{extra.Validators, extra.Seal, extra.CommittedSeal})
+	if !bytes.Equal(payload, legacy) {
+		t.Errorf("expected: %x, but got: %x", legacy, payload)
+	}
+
+	extra.CommittedSeal = [][]byte{}
+	extra.AggregatedSeal, extra.SignerBitmap = []byte{4, 5}, []byte{1}
+	h := &BlockHeader{Consensus: IstanbulConsensus, ExtraData: make([]byte, IstanbulExtraVanity)}
+	payload, err = rlp.EncodeToBytes(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ExtraData = append(h.ExtraData, payload...)
+
+	decoded, err := ExtractIstanbulExtra(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, extra) {
+		t.Errorf("expected: %v, but got: %v", extra, decoded)
+	}
+
+	// the aggregated seal is excluded from the header hash
+	filtered, err := ExtractIstanbulExtra(IstanbulFilteredHeader(h, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filtered.AggregatedSeal != nil || filtered.SignerBitmap != nil {
+		t.Errorf("expected no aggregated seal, but got: %v", filtered)
+	}
+}
@@ -23,6 +23,10 @@ type Receipt struct {
 	TxHash          common.Hash // the hash of the executed transaction
 	ContractAddress []byte      // Used when the tx (nil To address) is to create a contract.
 	TotalFee        uint64      // the full cost of the transaction
+
+	// Debts created by contracts which transfer value to other shards.
+	// It must be the last field, so that the encoding is unchanged when empty.
+	Debts []*Debt `rlp:"tail"`
 }
 
 // ReceiptIndex represents an index that used to query block info by tx hash.
@@ -53,3 +57,27 @@ func ReceiptMerkleRootHash(receipts []*Receipt) common.Hash {
 	emptyTrie := GetReceiptTrie(receipts)
 	return emptyTrie.Hash()
 }
+
+// GetReceiptsDebts returns the debts created by contracts in the specified receipts.
+func GetReceiptsDebts(receipts []*Receipt) []*Debt {
+	debts := make([]*Debt, 0)
+
+	for _, r := range receipts {
+		if r != nil {
+			debts = append(debts, r.Debts...)
+		}
+	}
+
+	return debts
+}
+
+// GetReceiptIndex returns the index of receipt for the specified tx or debt hash, or -1 if not found.
+func GetReceiptIndex(receipts []*Receipt, hash common.Hash) int {
+	for i, r := range receipts {
+		if r != nil && r.TxHash.Equal(hash) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -68,6 +68,31 @@ func RunPrecompiledContract(p PrecompiledContract, input []byte, contract *Contr
 	return nil, ErrOutOfGas
 }
 
+// SystemContract is the native contract that runs against the state, e.g. the Scdo
+// system contracts. Different from PrecompiledContract, it requires the caller and
+// value of the call.
+type SystemContract interface {
+	RequiredGas(input []byte) uint64
+	// Run runs the system contract with the caller as sender. The state changes
+	// must be discarded if readOnly, e.g. in a static call.
+	Run(input []byte, caller common.Address, value *big.Int, readOnly bool) ([]byte, error)
+}
+
+// RunSystemContract runs and evaluates the output of a system contract. The system contract
+// could only be called directly, since the value is transferred to the system contract address,
+// which is required by the system contract to manage the balance.
+func RunSystemContract(p SystemContract, input []byte, contract *Contract, readOnly bool) (ret []byte, err error) {
+	if contract.Address() != *contract.CodeAddr {
+		return nil, ErrSystemContractDelegated
+	}
+
+	gas := p.RequiredGas(input)
+	if contract.UseGas(gas) {
+		return p.Run(input, contract.Caller(), contract.Value(), readOnly)
+	}
+	return nil, ErrOutOfGas
+}
+
 // ECRECOVER implemented as a native contract.
 type ecrecover struct{}
 
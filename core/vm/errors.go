@@ -27,4 +27,8 @@ var (
 	ErrInsufficientBalance      = errors.New("insufficient balance for transfer")
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
+	ErrSystemContractDelegated  = errors.New("system contract must be called directly")
+
+	// ErrExecutionReverted is returned when the execution is stopped by the REVERT opcode
+	ErrExecutionReverted = errExecutionReverted
 )
@@ -38,6 +38,12 @@ type (
 	// GetHashFunc returns the nth block hash in the blockchain
 	// and is used by the BLOCKHASH EVM op code.
 	GetHashFunc func(uint64) common.Hash
+	// GetSystemContractFunc returns the system contract of the address,
+	// or nil if the address is not a system contract.
+	GetSystemContractFunc func(common.Address) SystemContract
+	// CallCrossShardFunc sends the call with value and gas allowance from the caller to the
+	// contract of other shard, and returns false if the call is not cross shard.
+	CallCrossShardFunc func(db StateDB, caller, addr common.Address, input []byte, gas uint64, value *big.Int) bool
 )
 
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
@@ -50,6 +56,9 @@ func run(evm *EVM, contract *Contract, input []byte, readOnly bool) ([]byte, err
 		if p := precompiles[*contract.CodeAddr]; p != nil {
 			return RunPrecompiledContract(p, input, contract)
 		}
+		if p := evm.systemContract(*contract.CodeAddr); p != nil {
+			return RunSystemContract(p, input, contract, readOnly)
+		}
 	}
 	for _, interpreter := range evm.interpreters {
 		if interpreter.CanRun(contract.Code) {
@@ -77,6 +86,12 @@ type Context struct {
 	Transfer TransferFunc
 	// Hash returns the hash corresponding to n
 	GetHash GetHashFunc
+	// GetSystemContract returns the system contract callable by EVM contracts,
+	// nil if system contracts are not callable.
+	GetSystemContract GetSystemContractFunc
+	// CallCrossShard sends the call to the contract of other shard asynchronously,
+	// nil if contracts of other shards are not callable.
+	CallCrossShard CallCrossShardFunc
 
 	// Message information
 	Origin   common.Address // Provides information for ORIGIN
@@ -163,6 +178,16 @@ func NewEVM(ctx Context, statedb StateDB, chainConfig *params.ChainConfig, vmCon
 	return evm
 }
 
+// systemContract returns the system contract of the address, or nil if
+// not a system contract or system contracts are not callable.
+func (evm *EVM) systemContract(addr common.Address) SystemContract {
+	if evm.GetSystemContract == nil {
+		return nil
+	}
+
+	return evm.GetSystemContract(addr)
+}
+
 // Cancel cancels any running EVM operation. This may be called concurrently and
 // it's safe to be called multiple times.
 func (evm *EVM) Cancel() {
@@ -192,6 +217,12 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		return nil, gas, ErrInsufficientBalance
 	}
 
+	// The call to contract of other shard is executed asynchronously in the target shard,
+	// and all the gas is reserved as the allowance of the call.
+	if evm.CallCrossShard != nil && evm.CallCrossShard(evm.StateDB, caller.Address(), addr, input, gas, value) {
+		return nil, 0, nil
+	}
+
 	var (
 		to       = AccountRef(addr)
 		snapshot = evm.StateDB.Snapshot()
@@ -201,7 +232,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		if evm.ChainConfig().IsByzantium(evm.BlockNumber) {
 			precompiles = PrecompiledContractsByzantium
 		}
-		if precompiles[addr] == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
+		if precompiles[addr] == nil && evm.systemContract(addr) == nil && evm.ChainConfig().IsEIP158(evm.BlockNumber) && value.Sign() == 0 {
 			// Calling a non existing account, don't do anything, but ping the tracer
 			if evm.vmConfig.Debug && evm.depth == 0 {
 				evm.vmConfig.Tracer.CaptureStart(caller.Address(), addr, false, input, gas, value)
@@ -0,0 +1,365 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+// Package bls implements the BLS signatures on the bn256 curve. The signatures are in G1
+// and the public keys are in G2, so that the signatures are short and could be aggregated
+// into a single signature of 64 bytes. The public keys should be registered with the proof
+// of possession to prevent the rogue key attack on the aggregated signatures.
+package bls
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/crypto/bn256"
+)
+
+const (
+	// SecretKeySize is the size of marshaled secret key
+	SecretKeySize = 32
+	// PublicKeySize is the size of marshaled public key, a point in G2
+	PublicKeySize = 128
+	// SignatureSize is the size of marshaled signature, a point in G1
+	SignatureSize = 64
+)
+
+var (
+	// order is the number of elements in both G1 and G2
+	order, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+	// fieldP is the prime of the base field of G1
+	fieldP, _ = new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+	// sqrtExp is (p+1)/4 to compute the square root in the base field, since p = 3 mod 4
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(fieldP, big.NewInt(1)), 2)
+	curveB  = big.NewInt(3)
+
+	// domains to separate the hashes of messages and proof of possessions
+	signatureDomain  = []byte("BLS_SIG_BN256G1_")
+	possessionDomain = []byte("BLS_POP_BN256G1_")
+	keyDomain        = []byte("BLS_KEY_BN256___")
+)
+
+var (
+	ErrInvalidSecretKey  = errors.New("invalid bls secret key")
+	ErrInvalidPublicKey  = errors.New("invalid bls public key")
+	ErrInvalidSignature  = errors.New("invalid bls signature")
+	ErrEmptyAggregation  = errors.New("nothing to aggregate")
+	ErrMismatchedBatch   = errors.New("mismatched number of public keys, messages and signatures")
+	errHashToCurveFailed = errors.New("failed to hash message to curve")
+)
+
+// SecretKey is the BLS secret key
+type SecretKey struct {
+	k *big.Int
+}
+
+// PublicKey is the BLS public key in G2
+type PublicKey struct {
+	p *bn256.G2
+}
+
+// Signature is the BLS signature in G1
+type Signature struct {
+	p *bn256.G1
+}
+
+// GenerateKey generates a random secret key
+func GenerateKey() (*SecretKey, error) {
+	return generateKey(rand.Reader)
+}
+
+func generateKey(r io.Reader) (*SecretKey, error) {
+	for {
+		k, err := rand.Int(r, order)
+		if err != nil {
+			return nil, err
+		}
+
+		if k.Sign() > 0 {
+			return &SecretKey{k}, nil
+		}
+	}
+}
+
+// DeriveKey derives the secret key deterministically from the seed, e.g. the bytes of the
+// ecdsa private key of validator, so that no extra key file is needed.
+func DeriveKey(seed []byte) *SecretKey {
+	for counter := byte(0); ; counter++ {
+		k := new(big.Int).SetBytes(crypto.Keccak256(keyDomain, seed, []byte{counter}))
+		if k.Mod(k, order).Sign() > 0 {
+			return &SecretKey{k}
+		}
+	}
+}
+
+// SecretKeyFromBytes returns the secret key of the marshaled bytes
+func SecretKeyFromBytes(b []byte) (*SecretKey, error) {
+	if len(b) != SecretKeySize {
+		return nil, ErrInvalidSecretKey
+	}
+
+	k := new(big.Int).SetBytes(b)
+	if k.Sign() == 0 || k.Cmp(order) >= 0 {
+		return nil, ErrInvalidSecretKey
+	}
+
+	return &SecretKey{k}, nil
+}
+
+// Bytes returns the marshaled secret key
+func (sk *SecretKey) Bytes() []byte {
+	b := make([]byte, SecretKeySize)
+	kb := sk.k.Bytes()
+	copy(b[SecretKeySize-len(kb):], kb)
+	return b
+}
+
+// PublicKey returns the public key of the secret key
+func (sk *SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{new(bn256.G2).ScalarBaseMult(sk.k)}
+}
+
+// Sign signs the message
+func (sk *SecretKey) Sign(msg []byte) *Signature {
+	return sk.sign(signatureDomain, msg)
+}
+
+// ProvePossession returns the proof of possession of the secret key, which is the signature
+// of the public key in a separate domain.
+func (sk *SecretKey) ProvePossession() *Signature {
+	return sk.sign(possessionDomain, sk.PublicKey().Bytes())
+}
+
+func (sk *SecretKey) sign(domain, msg []byte) *Signature {
+	h, err := hashToG1(domain, msg)
+	if err != nil {
+		// hardly happens, since half of the field elements are on curve
+		panic(err)
+	}
+
+	return &Signature{new(bn256.G1).ScalarMult(h, sk.k)}
+}
+
+// PublicKeyFromBytes returns the public key of the marshaled bytes. The point at infinity
+// and the points out of the prime order subgroup are rejected.
+func PublicKeyFromBytes(b []byte) (*PublicKey, error) {
+	if len(b) != PublicKeySize || isZero(b) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	p := new(bn256.G2)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	if !isZero(new(bn256.G2).ScalarMult(p, order).Marshal()) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return &PublicKey{p}, nil
+}
+
+// Bytes returns the marshaled public key
+func (pk *PublicKey) Bytes() []byte {
+	return pk.p.Marshal()
+}
+
+// Equal returns whether the public keys are the same
+func (pk *PublicKey) Equal(other *PublicKey) bool {
+	return bytes.Equal(pk.Bytes(), other.Bytes())
+}
+
+// Verify returns whether the signature of message is signed by the public key
+func (pk *PublicKey) Verify(msg []byte, sig *Signature) bool {
+	return verify(signatureDomain, pk, msg, sig)
+}
+
+// VerifyPossession returns whether the proof of possession is signed by the public key
+func (pk *PublicKey) VerifyPossession(proof *Signature) bool {
+	return verify(possessionDomain, pk, pk.Bytes(), proof)
+}
+
+// verify checks e(sig, g2) == e(H(msg), pk)
+func verify(domain []byte, pk *PublicKey, msg []byte, sig *Signature) bool {
+	h, err := hashToG1(domain, msg)
+	if err != nil {
+		return false
+	}
+
+	return bn256.PairingCheck(
+		[]*bn256.G1{new(bn256.G1).Neg(sig.p), h},
+		[]*bn256.G2{g2Generator(), pk.p},
+	)
+}
+
+// SignatureFromBytes returns the signature of the marshaled bytes
+func SignatureFromBytes(b []byte) (*Signature, error) {
+	if len(b) != SignatureSize || isZero(b) {
+		return nil, ErrInvalidSignature
+	}
+
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(b); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	return &Signature{p}, nil
+}
+
+// Bytes returns the marshaled signature
+func (sig *Signature) Bytes() []byte {
+	return sig.p.Marshal()
+}
+
+// AggregateSignatures aggregates the signatures into one signature
+func AggregateSignatures(sigs []*Signature) (*Signature, error) {
+	if len(sigs) == 0 {
+		return nil, ErrEmptyAggregation
+	}
+
+	p := sigs[0].p
+	for _, sig := range sigs[1:] {
+		p = new(bn256.G1).Add(p, sig.p)
+	}
+
+	return &Signature{p}, nil
+}
+
+// AggregatePublicKeys aggregates the public keys into one public key, which verifies the
+// aggregated signature of the same message. The public keys should have been checked with
+// the proof of possession.
+func AggregatePublicKeys(pks []*PublicKey) (*PublicKey, error) {
+	if len(pks) == 0 {
+		return nil, ErrEmptyAggregation
+	}
+
+	p := pks[0].p
+	for _, pk := range pks[1:] {
+		p = new(bn256.G2).Add(p, pk.p)
+	}
+
+	return &PublicKey{p}, nil
+}
+
+// VerifyAggregate returns whether the aggregated signature of the same message is signed by
+// all of the public keys.
+func VerifyAggregate(pks []*PublicKey, msg []byte, sig *Signature) bool {
+	pk, err := AggregatePublicKeys(pks)
+	if err != nil {
+		return false
+	}
+
+	return pk.Verify(msg, sig)
+}
+
+// BatchVerify verifies the signatures of different messages and public keys at once. The
+// signatures are combined with random coefficients, so that an invalid signature could not
+// be cancelled by another one. It returns true only if all of the signatures are valid.
+func BatchVerify(pks []*PublicKey, msgs [][]byte, sigs []*Signature) (bool, error) {
+	return batchVerify(rand.Reader, pks, msgs, sigs)
+}
+
+// batchVerify checks e(sum(r_i * sig_i), g2) == prod(e(r_i * H(msg_i), pk_i))
+func batchVerify(r io.Reader, pks []*PublicKey, msgs [][]byte, sigs []*Signature) (bool, error) {
+	if len(pks) != len(msgs) || len(pks) != len(sigs) {
+		return false, ErrMismatchedBatch
+	}
+
+	if len(pks) == 0 {
+		return false, ErrEmptyAggregation
+	}
+
+	g1s := make([]*bn256.G1, 0, len(pks)+1)
+	g2s := make([]*bn256.G2, 0, len(pks)+1)
+	var combined *bn256.G1
+	// 128 bits coefficients are enough for the soundness
+	bound := new(big.Int).Lsh(big.NewInt(1), 128)
+	for i := range pks {
+		coefficient, err := rand.Int(r, bound)
+		if err != nil {
+			return false, err
+		}
+		coefficient.Add(coefficient, big.NewInt(1))
+
+		h, err := hashToG1(signatureDomain, msgs[i])
+		if err != nil {
+			return false, nil
+		}
+
+		term := new(bn256.G1).ScalarMult(sigs[i].p, coefficient)
+		if combined == nil {
+			combined = term
+		} else {
+			combined = new(bn256.G1).Add(combined, term)
+		}
+
+		g1s = append(g1s, new(bn256.G1).ScalarMult(h, coefficient))
+		g2s = append(g2s, pks[i].p)
+	}
+
+	g1s = append(g1s, new(bn256.G1).Neg(combined))
+	g2s = append(g2s, g2Generator())
+
+	return bn256.PairingCheck(g1s, g2s), nil
+}
+
+// hashToG1 maps the message to a point in G1 by try-and-increment. The x coordinate is the
+// hash of domain, message and counter, and the smaller root is used as the y coordinate.
+// Since the cofactor of G1 is 1, every point on curve is in G1.
+func hashToG1(domain, msg []byte) (*bn256.G1, error) {
+	digest := crypto.Keccak256(domain, msg)
+	for counter := 0; counter < 256; counter++ {
+		x := new(big.Int).SetBytes(crypto.Keccak256(digest, []byte{byte(counter)}))
+		x.Mod(x, fieldP)
+
+		// y^2 = x^3 + 3
+		y2 := new(big.Int).Exp(x, big.NewInt(3), fieldP)
+		y2.Add(y2, curveB).Mod(y2, fieldP)
+
+		y := new(big.Int).Exp(y2, sqrtExp, fieldP)
+		if new(big.Int).Exp(y, big.NewInt(2), fieldP).Cmp(y2) != 0 {
+			continue
+		}
+
+		if neg := new(big.Int).Sub(fieldP, y); neg.Cmp(y) < 0 {
+			y = neg
+		}
+
+		b := make([]byte, SignatureSize)
+		xb, yb := x.Bytes(), y.Bytes()
+		copy(b[32-len(xb):32], xb)
+		copy(b[SignatureSize-len(yb):], yb)
+
+		if isZero(b) {
+			continue
+		}
+
+		p := new(bn256.G1)
+		if _, err := p.Unmarshal(b); err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	}
+
+	return nil, errHashToCurveFailed
+}
+
+func g2Generator() *bn256.G2 {
+	return new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
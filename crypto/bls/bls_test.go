@@ -0,0 +1,125 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package bls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKeys(t *testing.T, n int) []*SecretKey {
+	keys := make([]*SecretKey, n)
+	for i := range keys {
+		key, err := GenerateKey()
+		assert.NoError(t, err)
+		keys[i] = key
+	}
+
+	return keys
+}
+
+func Test_SignAndVerify(t *testing.T) {
+	sk := newTestKeys(t, 1)[0]
+	pk := sk.PublicKey()
+	msg := []byte("hello")
+
+	sig := sk.Sign(msg)
+	assert.True(t, pk.Verify(msg, sig))
+	assert.False(t, pk.Verify([]byte("world"), sig))
+
+	// another key
+	assert.False(t, newTestKeys(t, 1)[0].PublicKey().Verify(msg, sig))
+
+	// marshal
+	sk2, err := SecretKeyFromBytes(sk.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, pk.Equal(sk2.PublicKey()))
+
+	pk2, err := PublicKeyFromBytes(pk.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, pk.Equal(pk2))
+
+	sig2, err := SignatureFromBytes(sig.Bytes())
+	assert.NoError(t, err)
+	assert.True(t, pk2.Verify(msg, sig2))
+
+	_, err = PublicKeyFromBytes(make([]byte, PublicKeySize))
+	assert.Equal(t, ErrInvalidPublicKey, err)
+	_, err = SignatureFromBytes(make([]byte, SignatureSize))
+	assert.Equal(t, ErrInvalidSignature, err)
+	_, err = SecretKeyFromBytes(make([]byte, SecretKeySize))
+	assert.Equal(t, ErrInvalidSecretKey, err)
+}
+
+func Test_DeriveKey(t *testing.T) {
+	seed := []byte("validator private key")
+	assert.Equal(t, DeriveKey(seed).Bytes(), DeriveKey(seed).Bytes())
+	assert.NotEqual(t, DeriveKey(seed).Bytes(), DeriveKey([]byte("other")).Bytes())
+}
+
+func Test_ProofOfPossession(t *testing.T) {
+	keys := newTestKeys(t, 2)
+	proof := keys[0].ProvePossession()
+	assert.True(t, keys[0].PublicKey().VerifyPossession(proof))
+	assert.False(t, keys[1].PublicKey().VerifyPossession(proof))
+
+	// the proof is not a valid signature of the public key bytes
+	assert.False(t, keys[0].PublicKey().Verify(keys[0].PublicKey().Bytes(), proof))
+}
+
+func Test_Aggregate(t *testing.T) {
+	keys := newTestKeys(t, 4)
+	msg := []byte("block hash")
+
+	pks := make([]*PublicKey, len(keys))
+	sigs := make([]*Signature, len(keys))
+	for i, key := range keys {
+		pks[i] = key.PublicKey()
+		sigs[i] = key.Sign(msg)
+	}
+
+	sig, err := AggregateSignatures(sigs)
+	assert.NoError(t, err)
+	assert.True(t, VerifyAggregate(pks, msg, sig))
+
+	// missing signer
+	assert.False(t, VerifyAggregate(pks[:3], msg, sig))
+
+	sig, err = AggregateSignatures(sigs[:3])
+	assert.NoError(t, err)
+	assert.True(t, VerifyAggregate(pks[:3], msg, sig))
+	assert.False(t, VerifyAggregate(pks, msg, sig))
+
+	_, err = AggregateSignatures(nil)
+	assert.Equal(t, ErrEmptyAggregation, err)
+	assert.False(t, VerifyAggregate(nil, msg, sig))
+}
+
+func Test_BatchVerify(t *testing.T) {
+	keys := newTestKeys(t, 3)
+	pks := make([]*PublicKey, len(keys))
+	msgs := make([][]byte, len(keys))
+	sigs := make([]*Signature, len(keys))
+	for i, key := range keys {
+		pks[i] = key.PublicKey()
+		msgs[i] = []byte{byte(i)}
+		sigs[i] = key.Sign(msgs[i])
+	}
+
+	ok, err := BatchVerify(pks, msgs, sigs)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// swapped signatures
+	sigs[0], sigs[1] = sigs[1], sigs[0]
+	ok, err = BatchVerify(pks, msgs, sigs)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = BatchVerify(pks, msgs[:2], sigs)
+	assert.Equal(t, ErrMismatchedBatch, err)
+}
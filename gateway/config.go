@@ -0,0 +1,98 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+// Package gateway implements the shard-aware RPC gateway, which accepts the scdo and txpool
+// RPC requests once and forwards each of them to the upstream nodes of the right shard.
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+)
+
+// Config is the config of gateway
+type Config struct {
+	// TCPAddr is the listen address of the JSON-RPC over TCP, the same as the node RPC
+	TCPAddr string `json:"tcpAddr"`
+
+	// HTTPAddr is the listen address of the JSON-RPC over HTTP, empty to disable
+	HTTPAddr string `json:"httpAddr"`
+
+	// HTTPCors is the allowed origins of the HTTP requests
+	HTTPCors []string `json:"httpCors"`
+
+	// Upstreams is the RPC addresses of nodes for each shard, in the order of priority.
+	// An address is either host:port of the node RPC, or the http:// url of node HTTP server.
+	Upstreams map[uint][]string `json:"upstreams"`
+
+	// HealthCheckInterval is the interval in seconds to check the health of upstreams
+	HealthCheckInterval int64 `json:"healthCheckInterval"`
+
+	// Timeout is the timeout in seconds of the requests to upstreams
+	Timeout int64 `json:"timeout"`
+}
+
+// DefaultConfig is the default config of gateway
+var DefaultConfig = Config{
+	TCPAddr:             "127.0.0.1:8030",
+	HealthCheckInterval: 10,
+	Timeout:             30,
+}
+
+// LoadConfig loads the config from the JSON file, the unset fields are filled with default values
+func LoadConfig(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid gateway config file %v, %s", file, err)
+	}
+
+	return &config, config.Validate()
+}
+
+// Validate returns error if any shard has no upstream
+func (c *Config) Validate() error {
+	for shard := uint(1); shard <= common.ShardCount; shard++ {
+		if len(c.Upstreams[shard]) == 0 {
+			return fmt.Errorf("no upstream is configured for shard %d", shard)
+		}
+	}
+
+	for shard := range c.Upstreams {
+		if shard == 0 || shard > common.ShardCount {
+			return fmt.Errorf("invalid shard %d of upstreams", shard)
+		}
+	}
+
+	if len(c.TCPAddr) == 0 && len(c.HTTPAddr) == 0 {
+		return fmt.Errorf("neither TCP nor HTTP listen address is configured")
+	}
+
+	return nil
+}
+
+func (c *Config) healthCheckInterval() time.Duration {
+	if c.HealthCheckInterval <= 0 {
+		return time.Duration(DefaultConfig.HealthCheckInterval) * time.Second
+	}
+
+	return time.Duration(c.HealthCheckInterval) * time.Second
+}
+
+func (c *Config) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return time.Duration(DefaultConfig.Timeout) * time.Second
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
@@ -0,0 +1,542 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/log"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+const (
+	jsonrpcVersion = "2.0"
+
+	// error codes of JSON-RPC 2.0
+	parseErrorCode     = -32700
+	invalidRequestCode = -32600
+	methodNotFoundCode = -32601
+	invalidParamsCode  = -32602
+	internalErrorCode  = -32603
+
+	// maxRequestContentLength is the max size of HTTP request body
+	maxRequestContentLength = 1024 * 128
+)
+
+// jsonRequest is the JSON-RPC request
+type jsonRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// jsonError is the error of JSON-RPC response
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// jsonResponse is the JSON-RPC response
+type jsonResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonError      `json:"error,omitempty"`
+}
+
+// requestError is the error of request handled by gateway itself
+type requestError struct {
+	code    int
+	message string
+}
+
+func (e *requestError) Error() string  { return e.message }
+func (e *requestError) ErrorCode() int { return e.code }
+
+// Gateway accepts the scdo and txpool RPC requests and forwards them to the upstreams of shards
+type Gateway struct {
+	config *Config
+	shards map[uint]*shardUpstreams
+	log    *log.ScdoLog
+
+	mutex       sync.Mutex
+	tcpListener net.Listener
+	httpServer  *http.Server
+	quit        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// New creates the gateway with the config
+func New(config *Config) (*Gateway, error) {
+	return newGateway(config, dialUpstream)
+}
+
+func newGateway(config *Config, dial dialFunc) (*Gateway, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &Gateway{
+		config: config,
+		shards: make(map[uint]*shardUpstreams),
+		log:    log.GetLogger("gateway"),
+		quit:   make(chan struct{}),
+	}
+
+	for shard, addrs := range config.Upstreams {
+		s := &shardUpstreams{shard: shard}
+		for _, addr := range addrs {
+			s.upstreams = append(s.upstreams, newUpstream(addr, shard, dial))
+		}
+
+		g.shards[shard] = s
+	}
+
+	return g, nil
+}
+
+// Start starts the health checks and the TCP and HTTP servers
+func (g *Gateway) Start() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if len(g.config.TCPAddr) > 0 {
+		listener, err := net.Listen("tcp", g.config.TCPAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen TCP %v, %s", g.config.TCPAddr, err)
+		}
+
+		g.tcpListener = listener
+		g.wg.Add(1)
+		go g.serveTCP(listener)
+		g.log.Info("gateway TCP server started at %v", listener.Addr())
+	}
+
+	if len(g.config.HTTPAddr) > 0 {
+		listener, err := net.Listen("tcp", g.config.HTTPAddr)
+		if err != nil {
+			g.stopServers()
+			return fmt.Errorf("failed to listen HTTP %v, %s", g.config.HTTPAddr, err)
+		}
+
+		g.httpServer = &http.Server{Handler: g}
+		go g.httpServer.Serve(listener)
+		g.log.Info("gateway HTTP server started at %v", listener.Addr())
+	}
+
+	g.wg.Add(1)
+	go g.healthLoop()
+
+	return nil
+}
+
+// Stop stops the servers and closes the connections to upstreams
+func (g *Gateway) Stop() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	select {
+	case <-g.quit:
+		return
+	default:
+	}
+
+	close(g.quit)
+	g.stopServers()
+	g.wg.Wait()
+
+	for _, s := range g.shards {
+		for _, u := range s.upstreams {
+			u.close()
+		}
+	}
+
+	g.log.Info("gateway stopped")
+}
+
+func (g *Gateway) stopServers() {
+	if g.tcpListener != nil {
+		g.tcpListener.Close()
+		g.tcpListener = nil
+	}
+
+	if g.httpServer != nil {
+		g.httpServer.Close()
+		g.httpServer = nil
+	}
+}
+
+// Status returns the health status of all upstreams
+func (g *Gateway) Status() []UpstreamStatus {
+	var status []UpstreamStatus
+	for shard := uint(1); shard <= common.ShardCount; shard++ {
+		for _, u := range g.shards[shard].upstreams {
+			status = append(status, u.status())
+		}
+	}
+
+	return status
+}
+
+func (g *Gateway) healthLoop() {
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(g.config.healthCheckInterval())
+	defer ticker.Stop()
+
+	g.checkHealth()
+	for {
+		select {
+		case <-ticker.C:
+			g.checkHealth()
+		case <-g.quit:
+			return
+		}
+	}
+}
+
+// checkHealth checks all the upstreams concurrently
+func (g *Gateway) checkHealth() {
+	var wg sync.WaitGroup
+	for _, s := range g.shards {
+		for _, u := range s.upstreams {
+			wg.Add(1)
+			go func(u *upstream) {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), g.config.timeout())
+				defer cancel()
+
+				wasHealthy := u.isHealthy()
+				u.check(ctx)
+				if status := u.status(); wasHealthy != status.Healthy {
+					if status.Healthy {
+						g.log.Info("upstream %v of shard %d is recovered", u.addr, u.shard)
+					} else {
+						g.log.Warn("upstream %v of shard %d is unhealthy, %s", u.addr, u.shard, status.Error)
+					}
+				}
+			}(u)
+		}
+	}
+
+	wg.Wait()
+}
+
+// serveTCP serves the newline delimited JSON-RPC requests on TCP connections
+func (g *Gateway) serveTCP(listener net.Listener) {
+	defer g.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-g.quit:
+			default:
+				g.log.Warn("failed to accept TCP connection, %s", err)
+			}
+			return
+		}
+
+		go g.serveConn(conn)
+	}
+}
+
+func (g *Gateway) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	go func() {
+		<-g.quit
+		conn.Close()
+	}()
+
+	decoder := json.NewDecoder(bufio.NewReader(conn))
+	var writeMutex sync.Mutex
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err != io.EOF {
+				g.log.Debug("failed to read request from %v, %s", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		go func(raw json.RawMessage) {
+			data := g.handleMessage(raw)
+
+			writeMutex.Lock()
+			defer writeMutex.Unlock()
+			conn.Write(append(data, '\n'))
+		}(raw)
+	}
+}
+
+// ServeHTTP serves the JSON-RPC requests over HTTP
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); len(origin) > 0 && g.allowOrigin(origin) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	}
+
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRequestContentLength+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(body) > maxRequestContentLength {
+		http.Error(w, "content length too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(g.handleMessage(body))
+}
+
+func (g *Gateway) allowOrigin(origin string) bool {
+	for _, allowed := range g.config.HTTPCors {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleMessage handles a single or batch request and returns the encoded response
+func (g *Gateway) handleMessage(raw []byte) []byte {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		var requests []jsonRequest
+		if err := json.Unmarshal(raw, &requests); err != nil {
+			return encodeResponse(errorResponse(nil, &requestError{parseErrorCode, err.Error()}))
+		}
+
+		responses := make([]*jsonResponse, len(requests))
+		var wg sync.WaitGroup
+		for i := range requests {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = g.handleRequest(&requests[i])
+			}(i)
+		}
+		wg.Wait()
+
+		return encodeResponse(responses)
+	}
+
+	var request jsonRequest
+	if err := json.Unmarshal(raw, &request); err != nil {
+		return encodeResponse(errorResponse(nil, &requestError{parseErrorCode, err.Error()}))
+	}
+
+	return encodeResponse(g.handleRequest(&request))
+}
+
+func encodeResponse(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse(nil, &requestError{internalErrorCode, err.Error()}))
+	}
+
+	return data
+}
+
+func errorResponse(id json.RawMessage, err error) *jsonResponse {
+	code := internalErrorCode
+	if e, ok := err.(rpc.Error); ok {
+		code = e.ErrorCode()
+	}
+
+	return &jsonResponse{Version: jsonrpcVersion, ID: id, Error: &jsonError{code, err.Error()}}
+}
+
+// handleRequest forwards the request by its route and returns the response
+func (g *Gateway) handleRequest(request *jsonRequest) *jsonResponse {
+	if len(request.Method) == 0 {
+		return errorResponse(request.ID, &requestError{invalidRequestCode, "empty method"})
+	}
+
+	var params []json.RawMessage
+	if len(request.Params) > 0 && string(request.Params) != "null" {
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return errorResponse(request.ID, &requestError{invalidParamsCode, "params must be an array"})
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.config.timeout())
+	defer cancel()
+
+	result, err := g.forward(ctx, request.Method, params)
+	if err != nil {
+		return errorResponse(request.ID, err)
+	}
+
+	if len(result) == 0 {
+		result = json.RawMessage("null")
+	}
+
+	return &jsonResponse{Version: jsonrpcVersion, ID: request.ID, Result: result}
+}
+
+// forward forwards the request to the upstreams by the route of method
+func (g *Gateway) forward(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	if method == "gateway_upstreams" {
+		return json.Marshal(g.Status())
+	}
+
+	r, ok := getRoute(method)
+	if !ok {
+		return nil, &requestError{methodNotFoundCode, fmt.Sprintf("the method %s is not supported by gateway", method)}
+	}
+
+	switch r.kind {
+	case routeAddress:
+		shard, err := addressShard(params, r.param)
+		if err != nil {
+			return nil, &requestError{invalidParamsCode, err.Error()}
+		}
+
+		if shard == 0 {
+			return g.merge(ctx, method, params)
+		}
+
+		return g.shards[shard].call(ctx, method, params)
+	case routeTx:
+		shard, err := txShard(params, r.param)
+		if err != nil {
+			return nil, &requestError{invalidParamsCode, err.Error()}
+		}
+
+		return g.shards[shard].call(ctx, method, params)
+	case routeMessage:
+		shard, err := messageShard(params, r.param)
+		if err != nil {
+			return nil, &requestError{invalidParamsCode, err.Error()}
+		}
+
+		if shard == 0 {
+			return g.first(ctx, method, params)
+		}
+
+		return g.shards[shard].call(ctx, method, params)
+	case routeFirst:
+		return g.first(ctx, method, params)
+	case routeHashOrMerge:
+		if hasHash(params, r.param) {
+			return g.first(ctx, method, params)
+		}
+
+		return g.merge(ctx, method, params)
+	case routeAny:
+		return g.first(ctx, method, params)
+	default:
+		return g.merge(ctx, method, params)
+	}
+}
+
+// shardResult is the result of request forwarded to a shard
+type shardResult struct {
+	shard  uint
+	result json.RawMessage
+	err    error
+}
+
+// fanOut forwards the request to all shards concurrently, the results are in the order of shard
+func (g *Gateway) fanOut(ctx context.Context, method string, params []json.RawMessage) []shardResult {
+	results := make([]shardResult, common.ShardCount)
+	var wg sync.WaitGroup
+	for i := range results {
+		shard := uint(i + 1)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := g.shards[shard].call(ctx, method, params)
+			results[i] = shardResult{shard, result, err}
+		}(i)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// first returns the first non-null result in the order of shard, e.g. the tx found by hash
+func (g *Gateway) first(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	var firstErr error
+	for _, r := range g.fanOut(ctx, method, params) {
+		if r.err == nil && len(r.result) > 0 && string(r.result) != "null" {
+			return r.result, nil
+		}
+
+		if firstErr == nil {
+			firstErr = r.err
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return json.RawMessage("null"), nil
+}
+
+// merge returns the results of all shards keyed by shard number. The failed shards are
+// returned with the error message, unless all shards failed.
+func (g *Gateway) merge(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	merged := make(map[string]interface{})
+	var firstErr error
+	failed := 0
+	for _, r := range g.fanOut(ctx, method, params) {
+		key := strconv.FormatUint(uint64(r.shard), 10)
+		if r.err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = r.err
+			}
+
+			merged[key] = map[string]string{"error": r.err.Error()}
+			continue
+		}
+
+		if len(r.result) == 0 {
+			r.result = json.RawMessage("null")
+		}
+
+		merged[key] = r.result
+	}
+
+	if failed == len(merged) {
+		return nil, firstErr
+	}
+
+	return json.Marshal(merged)
+}
@@ -0,0 +1,245 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNode is a fake node of shard that records the forwarded requests
+type testNode struct {
+	shard uint
+	down  bool
+
+	mutex   sync.Mutex
+	methods []string
+}
+
+func (n *testNode) called() []string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return append([]string(nil), n.methods...)
+}
+
+type testClient struct {
+	node *testNode
+}
+
+func (c *testClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if c.node.down {
+		return errors.New("connection refused")
+	}
+
+	c.node.mutex.Lock()
+	c.node.methods = append(c.node.methods, method)
+	c.node.mutex.Unlock()
+
+	var data string
+	switch method {
+	case "scdo_getInfo":
+		data = fmt.Sprintf(`{"Shard":%d,"CurrentBlockHeight":%d}`, c.node.shard, 100+c.node.shard)
+	case "scdo_getBlockHeight":
+		data = fmt.Sprintf("%d", 100+c.node.shard)
+	case "txpool_getTransactionByHash":
+		if c.node.shard != 3 {
+			return &requestError{-32000, "tx not found"}
+		}
+		data = `{"shard":3}`
+	default:
+		data = fmt.Sprintf(`"shard %d"`, c.node.shard)
+	}
+
+	*result.(*json.RawMessage) = json.RawMessage(data)
+	return nil
+}
+
+func (c *testClient) Close() {}
+
+// newTestGateway creates gateway with 2 upstreams for each shard, the first one is primary
+func newTestGateway(t *testing.T) (*Gateway, map[string]*testNode) {
+	config := DefaultConfig
+	config.Upstreams = make(map[uint][]string)
+	nodes := make(map[string]*testNode)
+	for shard := uint(1); shard <= common.ShardCount; shard++ {
+		for _, name := range []string{"primary", "backup"} {
+			addr := fmt.Sprintf("%s-%d", name, shard)
+			config.Upstreams[shard] = append(config.Upstreams[shard], addr)
+			nodes[addr] = &testNode{shard: shard}
+		}
+	}
+
+	dial := func(ctx context.Context, addr string) (caller, error) {
+		return &testClient{nodes[addr]}, nil
+	}
+
+	g, err := newGateway(&config, dial)
+	assert.Equal(t, err, nil)
+
+	return g, nodes
+}
+
+// shardAddress returns an external account address of shard
+func shardAddress(shard uint) common.Address {
+	var addr common.Address
+	addr[0] = byte(common.ShardCount + shard - 1)
+	addr[common.AddressLen-1] = byte(common.AddressTypeExternal)
+	return addr
+}
+
+func request(t *testing.T, g *Gateway, method string, params ...interface{}) *jsonResponse {
+	data, err := json.Marshal(params)
+	assert.Equal(t, err, nil)
+
+	return g.handleRequest(&jsonRequest{Version: jsonrpcVersion, ID: json.RawMessage("1"), Method: method, Params: data})
+}
+
+func Test_Gateway_RouteAddress(t *testing.T) {
+	g, nodes := newTestGateway(t)
+
+	for shard := uint(1); shard <= common.ShardCount; shard++ {
+		addr := shardAddress(shard)
+		assert.Equal(t, addr.Shard(), shard)
+
+		resp := request(t, g, "scdo_getBalance", addr.Hex(), "", -1)
+		assert.Equal(t, resp.Error == nil, true)
+		assert.Equal(t, string(resp.Result), fmt.Sprintf(`"shard %d"`, shard))
+		assert.Equal(t, nodes[fmt.Sprintf("primary-%d", shard)].called(), []string{"scdo_getBalance"})
+		assert.Equal(t, len(nodes[fmt.Sprintf("backup-%d", shard)].called()), 0)
+	}
+
+	// invalid address
+	resp := request(t, g, "scdo_getBalance", "0x1234", "", -1)
+	assert.Equal(t, resp.Error.Code, invalidParamsCode)
+}
+
+func Test_Gateway_RouteTx(t *testing.T) {
+	g, nodes := newTestGateway(t)
+
+	from := shardAddress(2)
+	tx := map[string]interface{}{
+		"Data": map[string]interface{}{"From": from.Hex(), "To": shardAddress(4).Hex()},
+	}
+
+	resp := request(t, g, "scdo_addTx", tx)
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, string(resp.Result), `"shard 2"`)
+	assert.Equal(t, nodes["primary-2"].called(), []string{"scdo_addTx"})
+	assert.Equal(t, len(nodes["primary-4"].called()), 0)
+
+	// unsigned message is routed by the receiver
+	msg := map[string]interface{}{"From": from.Hex(), "To": shardAddress(4).Hex()}
+	resp = request(t, g, "scdo_simulateCall", msg, -1)
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, string(resp.Result), `"shard 4"`)
+
+	// or the sender to create contract
+	msg = map[string]interface{}{"From": from.Hex()}
+	resp = request(t, g, "scdo_estimateCallGas", msg, -1)
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, string(resp.Result), `"shard 2"`)
+}
+
+func Test_Gateway_Merge(t *testing.T) {
+	g, nodes := newTestGateway(t)
+	nodes["primary-4"].down = true
+	nodes["backup-4"].down = true
+
+	resp := request(t, g, "scdo_getBlockHeight")
+	assert.Equal(t, resp.Error == nil, true)
+
+	var merged map[string]json.RawMessage
+	assert.Equal(t, json.Unmarshal(resp.Result, &merged), nil)
+	assert.Equal(t, len(merged), common.ShardCount)
+	assert.Equal(t, string(merged["1"]), "101")
+	assert.Equal(t, string(merged["3"]), "103")
+
+	var failed map[string]string
+	assert.Equal(t, json.Unmarshal(merged["4"], &failed), nil)
+	assert.Equal(t, len(failed["error"]) > 0, true)
+
+	// block by hash returns the first found
+	resp = request(t, g, "txpool_getTransactionByHash", "0x01")
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, string(resp.Result), `{"shard":3}`)
+
+	// block by hash or height
+	resp = request(t, g, "scdo_getBlock", "", 10, false)
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, json.Unmarshal(resp.Result, &merged), nil)
+	assert.Equal(t, string(merged["2"]), `"shard 2"`)
+}
+
+func Test_Gateway_Failover(t *testing.T) {
+	g, nodes := newTestGateway(t)
+	nodes["primary-1"].down = true
+
+	addr := shardAddress(1)
+	resp := request(t, g, "scdo_getAccountNonce", addr.Hex(), "", -1)
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, string(resp.Result), `"shard 1"`)
+	assert.Equal(t, nodes["backup-1"].called(), []string{"scdo_getAccountNonce"})
+
+	// the failed primary is tried after the healthy backup
+	status := g.Status()
+	assert.Equal(t, status[0].Address, "primary-1")
+	assert.Equal(t, status[0].Healthy, false)
+
+	resp = request(t, g, "scdo_getAccountNonce", addr.Hex(), "", -1)
+	assert.Equal(t, resp.Error == nil, true)
+	assert.Equal(t, len(nodes["backup-1"].called()), 2)
+
+	// recovered by health check
+	nodes["primary-1"].down = false
+	g.checkHealth()
+	status = g.Status()
+	assert.Equal(t, status[0].Healthy, true)
+	assert.Equal(t, status[0].Height, uint64(101))
+
+	// all upstreams of shard are down
+	nodes["primary-1"].down = true
+	nodes["backup-1"].down = true
+	resp = request(t, g, "scdo_getAccountNonce", addr.Hex(), "", -1)
+	assert.Equal(t, resp.Error.Code, internalErrorCode)
+}
+
+func Test_Gateway_UnsupportedMethod(t *testing.T) {
+	g, _ := newTestGateway(t)
+
+	resp := request(t, g, "miner_start")
+	assert.Equal(t, resp.Error.Code, methodNotFoundCode)
+
+	data := g.handleMessage([]byte(`[{"jsonrpc":"2.0","id":1,"method":"scdo_getBlockHeight"},{"jsonrpc":"2.0","id":2,"method":"debug_dumpHeap"}]`))
+	var responses []jsonResponse
+	assert.Equal(t, json.Unmarshal(data, &responses), nil)
+	assert.Equal(t, len(responses), 2)
+	assert.Equal(t, responses[0].Error == nil, true)
+	assert.Equal(t, responses[1].Error.Code, methodNotFoundCode)
+}
+
+func Test_Config_Validate(t *testing.T) {
+	config := DefaultConfig
+	config.Upstreams = map[uint][]string{1: {"a"}, 2: {"b"}, 3: {"c"}}
+	assert.Equal(t, config.Validate() != nil, true)
+
+	config.Upstreams[4] = []string{"d"}
+	assert.Equal(t, config.Validate(), nil)
+
+	config.Upstreams[5] = []string{"e"}
+	assert.Equal(t, config.Validate() != nil, true)
+	delete(config.Upstreams, 5)
+
+	config.TCPAddr = ""
+	assert.Equal(t, config.Validate() != nil, true)
+}
@@ -0,0 +1,184 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+// routeKind is the way to forward the request to shards
+type routeKind int
+
+const (
+	// routeAddress forwards to the shard of the address param
+	routeAddress routeKind = iota
+	// routeTx forwards to the shard of the sender of tx param
+	routeTx
+	// routeMessage forwards to the shard of the receiver of message param, or the sender if
+	// it creates contract or calls system contract
+	routeMessage
+	// routeFirst fans out to all shards and returns the first found result, e.g. lookup by hash
+	routeFirst
+	// routeMerge fans out to all shards and merges the results by shard, e.g. query by height
+	routeMerge
+	// routeHashOrMerge is routeFirst if the hash param is not empty, otherwise routeMerge
+	routeHashOrMerge
+	// routeAny forwards to any shard, for the requests independent of the chain state
+	routeAny
+)
+
+// route is the routing rule of method
+type route struct {
+	kind  routeKind
+	param int // index of the address, tx or hash param
+}
+
+// routes is the routing rules of the scdo and txpool RPC methods
+var routes = map[string]route{
+	"scdo_getBalance":                            {routeAddress, 0},
+	"scdo_getAccountNonce":                       {routeAddress, 0},
+	"scdo_getAccountTxCount":                     {routeAddress, 0},
+	"scdo_getMasternode":                         {routeAddress, 0},
+	"scdo_getShardNum":                           {routeAddress, 0},
+	"scdo_call":                                  {routeAddress, 0},
+	"scdo_getLogs":                               {routeAddress, 1},
+	"scdo_addTx":                                 {routeTx, 0},
+	"scdo_estimateGas":                           {routeTx, 0},
+	"scdo_simulateCall":                          {routeMessage, 0},
+	"scdo_estimateCallGas":                       {routeMessage, 0},
+	"scdo_generatePayload":                       {routeAny, 0},
+	"scdo_getBlockByHash":                        {routeFirst, 0},
+	"scdo_getBlock":                              {routeHashOrMerge, 0},
+	"scdo_getBlockByHeight":                      {routeMerge, 0},
+	"scdo_getBlocks":                             {routeMerge, 0},
+	"scdo_getBlockHeight":                        {routeMerge, 0},
+	"scdo_getHeight":                             {routeMerge, 0},
+	"scdo_getInfo":                               {routeMerge, 0},
+	"scdo_getMasternodes":                        {routeMerge, 0},
+	"scdo_isSyncing":                             {routeMerge, 0},
+	"scdo_isListening":                           {routeMerge, 0},
+	"txpool_getTransactionByHash":                {routeFirst, 0},
+	"txpool_getReceiptByTxHash":                  {routeFirst, 0},
+	"txpool_getDebtByHash":                       {routeFirst, 0},
+	"txpool_getReceiptsByBlockHash":              {routeFirst, 0},
+	"txpool_getBlockTransactionCountByHash":      {routeFirst, 0},
+	"txpool_getBlockDebtCountByHash":             {routeFirst, 0},
+	"txpool_getTransactionByBlockHashAndIndex":   {routeFirst, 0},
+	"txpool_getBlockTransactionCount":            {routeHashOrMerge, 0},
+	"txpool_getBlockDebtCount":                   {routeHashOrMerge, 0},
+	"txpool_getTransactionByBlockIndex":          {routeHashOrMerge, 0},
+	"txpool_getBlockTransactionCountByHeight":    {routeMerge, 0},
+	"txpool_getBlockDebtCountByHeight":           {routeMerge, 0},
+	"txpool_getTransactionByBlockHeightAndIndex": {routeMerge, 0},
+}
+
+// getRoute returns the routing rule of method. The unknown methods of the scdo and txpool
+// namespaces are merged from all shards.
+func getRoute(method string) (route, bool) {
+	if r, ok := routes[method]; ok {
+		return r, true
+	}
+
+	namespace, _ := splitMethod(method)
+	if namespace == "scdo" || namespace == "txpool" {
+		return route{kind: routeMerge}, true
+	}
+
+	return route{}, false
+}
+
+func splitMethod(method string) (string, string) {
+	for i := 0; i < len(method); i++ {
+		if method[i] == '_' {
+			return method[:i], method[i+1:]
+		}
+	}
+
+	return method, ""
+}
+
+// addressShard returns the shard of address param, or 0 for the system contracts which
+// exist in every shard.
+func addressShard(params []json.RawMessage, index int) (uint, error) {
+	if index >= len(params) {
+		return 0, fmt.Errorf("missing address param %d", index)
+	}
+
+	var hex string
+	if err := json.Unmarshal(params[index], &hex); err != nil {
+		return 0, fmt.Errorf("invalid address param %d, %s", index, err)
+	}
+
+	address, err := common.HexToAddress(hex)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address param %d, %s", index, err)
+	}
+
+	if address.IsEmpty() || address.IsReserved() {
+		return 0, nil
+	}
+
+	return address.Shard(), nil
+}
+
+// txShard returns the shard of the sender of tx param
+func txShard(params []json.RawMessage, index int) (uint, error) {
+	if index >= len(params) {
+		return 0, fmt.Errorf("missing tx param %d", index)
+	}
+
+	var tx types.Transaction
+	if err := json.Unmarshal(params[index], &tx); err != nil {
+		return 0, fmt.Errorf("invalid tx param %d, %s", index, err)
+	}
+
+	if tx.Data.From.IsEmpty() {
+		return 0, fmt.Errorf("invalid tx param %d, empty sender", index)
+	}
+
+	return tx.Data.From.Shard(), nil
+}
+
+// messageShard returns the shard of the receiver of message param, or the sender if the receiver
+// is empty or reserved. It returns 0 if both are empty.
+func messageShard(params []json.RawMessage, index int) (uint, error) {
+	if index >= len(params) {
+		return 0, fmt.Errorf("missing message param %d", index)
+	}
+
+	var msg struct {
+		From common.Address
+		To   common.Address
+	}
+
+	if err := json.Unmarshal(params[index], &msg); err != nil {
+		return 0, fmt.Errorf("invalid message param %d, %s", index, err)
+	}
+
+	if !msg.To.IsEmpty() && !msg.To.IsReserved() {
+		return msg.To.Shard(), nil
+	}
+
+	if !msg.From.IsEmpty() {
+		return msg.From.Shard(), nil
+	}
+
+	return 0, nil
+}
+
+// hasHash returns whether the hash param is not empty
+func hasHash(params []json.RawMessage, index int) bool {
+	if index >= len(params) {
+		return false
+	}
+
+	var hash string
+	return json.Unmarshal(params[index], &hash) == nil && len(hash) > 0
+}
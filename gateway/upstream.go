@@ -0,0 +1,227 @@
+/**
+* @file
+* @copyright defined in go-stem/LICENSE
+ */
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/api"
+	"github.com/scdoproject/go-stem/rpc"
+)
+
+var (
+	// errNoUpstream is returned when all the upstreams of shard are failed
+	errNoUpstream = errors.New("no upstream is available")
+)
+
+// caller is the RPC client of upstream
+type caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	Close()
+}
+
+// dialFunc dials the upstream address
+type dialFunc func(ctx context.Context, addr string) (caller, error)
+
+// dialUpstream dials the node RPC by TCP, or the node HTTP server by url
+func dialUpstream(ctx context.Context, addr string) (caller, error) {
+	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return rpc.DialHTTP(addr)
+	}
+
+	return rpc.DialTCP(ctx, addr)
+}
+
+// UpstreamStatus is the health status of upstream
+type UpstreamStatus struct {
+	Address   string
+	Shard     uint
+	Healthy   bool
+	Height    uint64
+	Error     string `json:",omitempty"`
+	LastCheck time.Time
+}
+
+// upstream is a node serving a shard
+type upstream struct {
+	addr  string
+	shard uint
+	dial  dialFunc
+
+	mutex     sync.RWMutex
+	client    caller
+	healthy   bool
+	height    uint64
+	lastErr   error
+	lastCheck time.Time
+}
+
+func newUpstream(addr string, shard uint, dial dialFunc) *upstream {
+	// healthy until the first check fails, so that requests could be served at startup
+	return &upstream{addr: addr, shard: shard, dial: dial, healthy: true}
+}
+
+func (u *upstream) isHealthy() bool {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	return u.healthy
+}
+
+func (u *upstream) getClient(ctx context.Context) (caller, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.client == nil {
+		client, err := u.dial(ctx, u.addr)
+		if err != nil {
+			return nil, err
+		}
+
+		u.client = client
+	}
+
+	return u.client, nil
+}
+
+// setFailed marks the upstream unhealthy and closes the connection to redial
+func (u *upstream) setFailed(err error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.healthy = false
+	u.lastErr = err
+	if u.client != nil {
+		u.client.Close()
+		u.client = nil
+	}
+}
+
+// call forwards the request to upstream. The error returned by the node is an rpc.Error,
+// while the others are the connection errors that fail the upstream.
+func (u *upstream) call(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	client, err := u.getClient(ctx)
+	if err != nil {
+		u.setFailed(err)
+		return nil, err
+	}
+
+	args := make([]interface{}, len(params))
+	for i, p := range params {
+		args[i] = p
+	}
+
+	var result json.RawMessage
+	if err = client.CallContext(ctx, &result, method, args...); err != nil {
+		if _, ok := err.(rpc.Error); !ok {
+			u.setFailed(err)
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// check checks the health of upstream by its miner info
+func (u *upstream) check(ctx context.Context) {
+	var info api.GetMinerInfo
+	result, err := u.call(ctx, "scdo_getInfo", nil)
+	if err == nil {
+		err = json.Unmarshal(result, &info)
+	}
+
+	if err == nil && info.Shard != u.shard {
+		err = fmt.Errorf("the node serves shard %d instead of %d", info.Shard, u.shard)
+	}
+
+	if err != nil {
+		u.setFailed(err)
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	u.lastCheck = time.Now()
+	if err == nil {
+		u.healthy = true
+		u.height = info.CurrentBlockHeight
+		u.lastErr = nil
+	}
+}
+
+func (u *upstream) status() UpstreamStatus {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+
+	s := UpstreamStatus{
+		Address:   u.addr,
+		Shard:     u.shard,
+		Healthy:   u.healthy,
+		Height:    u.height,
+		LastCheck: u.lastCheck,
+	}
+
+	if u.lastErr != nil {
+		s.Error = u.lastErr.Error()
+	}
+
+	return s
+}
+
+func (u *upstream) close() {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.client != nil {
+		u.client.Close()
+		u.client = nil
+	}
+}
+
+// shardUpstreams is the upstreams of a shard in the order of priority
+type shardUpstreams struct {
+	shard     uint
+	upstreams []*upstream
+}
+
+// call forwards the request to the first healthy upstream, and fails over to the next one
+// on connection errors. The unhealthy upstreams are tried at last.
+func (s *shardUpstreams) call(ctx context.Context, method string, params []json.RawMessage) (json.RawMessage, error) {
+	var healthy, unhealthy []*upstream
+	for _, u := range s.upstreams {
+		if u.isHealthy() {
+			healthy = append(healthy, u)
+		} else {
+			unhealthy = append(unhealthy, u)
+		}
+	}
+
+	var lastErr error = errNoUpstream
+	for _, u := range append(healthy, unhealthy...) {
+		result, err := u.call(ctx, method, params)
+		if err == nil {
+			return result, nil
+		}
+
+		if _, ok := err.(rpc.Error); ok {
+			return nil, err
+		}
+
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("shard %d: %s", s.shard, lastErr)
+}
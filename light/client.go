@@ -96,7 +96,7 @@ func NewServiceClient(ctx context.Context, conf *node.Config, log *log.ScdoLog,
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *ServiceClient) Protocols() (protos []p2p.Protocol) {
-	return append(protos, s.scdoProtocol.Protocol)
+	return append(protos, s.scdoProtocol.Protocols()...)
 }
 
 // Start implements node.Service, starting goroutines needed by ServiceClient.
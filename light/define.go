@@ -17,8 +17,12 @@ const (
 	// LightProtoName protoName of Scdo service
 	LightProtoName = "lightScdo"
 
-	// LightScdoVersion version number of Scdo protocol
-	LightScdoVersion uint = 1
+	// LightScdoVersion version number of Scdo protocol, version 2 adds the checkpoint request
+	LightScdoVersion uint = 2
+
+	// lightScdoVersion1 is the version before the checkpoint request, which is still negotiable
+	// with the old peers
+	lightScdoVersion1 uint = 1
 
 	// MaxBlockHashRequest maximum hashes to request per message
 	MaxBlockHashRequest uint64 = 1024
@@ -44,6 +48,11 @@ type statusData struct {
 	CurrentBlock    common.Hash
 	CurrentBlockNum uint64
 	GenesisBlock    common.Hash
+
+	// FlowParams is the flow control buffer limit and recharge rate assigned to client, which is
+	// only set by server since version 2. It is an optional tail so that the status without it
+	// is still compatible with the peers of version 1.
+	FlowParams []uint64 `rlp:"tail"`
 }
 
 // AnnounceQuery header of AnnounceQuery request
@@ -0,0 +1,262 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package light
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/node"
+)
+
+const (
+	// DefaultServeCapacity default total cost units per second served to all clients
+	DefaultServeCapacity uint64 = 1000000
+
+	// DefaultClientCapacity default cost units per second that refill the buffer of a normal client
+	DefaultClientCapacity uint64 = 50000
+
+	// DefaultPriorityCapacity default cost units per second that refill the buffer of a priority client
+	DefaultPriorityCapacity uint64 = 200000
+
+	// bufLimitSeconds is how many seconds of recharge a client buffer could hold at most
+	bufLimitSeconds uint64 = 10
+
+	// maxRetryAfter is the maximum retry hint sent to a throttled client
+	maxRetryAfter = msgWaitTimeout
+)
+
+var (
+	errServerOverloaded = errors.New("light server capacity is full")
+	errRequestThrottled = errors.New("request exceeds the flow control buffer")
+)
+
+// requestCosts is the cost in units of every ODR request code served by light server.
+var requestCosts = map[uint16]uint64{
+	blockRequestCode:      20000,
+	addTxRequestCode:      5000,
+	trieRequestCode:       10000,
+	receiptRequestCode:    10000,
+	txByHashRequestCode:   10000,
+	debtRequestCode:       10000,
+	checkpointRequestCode: 5000,
+}
+
+// requestCost returns the cost of the specified request code
+func requestCost(code uint16) uint64 {
+	return requestCosts[code]
+}
+
+// flowParams is the flow control parameters announced by server in handshake
+type flowParams struct {
+	BufLimit    uint64 // maximum buffer value
+	MinRecharge uint64 // cost units per second that refill the buffer
+}
+
+// clientBuffer tracks the buffer value of a client, it is used by both server and client.
+// Server uses it to decide whether to serve a request, and client uses it to estimate
+// the buffer value of a server before the next response arrives.
+type clientBuffer struct {
+	params     flowParams
+	value      uint64
+	lastUpdate time.Time
+	retryAt    time.Time // do not send requests before this time, only useful in client mode
+	lock       sync.Mutex
+}
+
+func newClientBuffer(params flowParams) *clientBuffer {
+	return &clientBuffer{
+		params:     params,
+		value:      params.BufLimit,
+		lastUpdate: time.Now(),
+	}
+}
+
+// recharge refills the buffer according to the elapsed time, must be called with lock held
+func (b *clientBuffer) recharge(now time.Time) {
+	if now.After(b.lastUpdate) {
+		elapsed := uint64(now.Sub(b.lastUpdate) / time.Millisecond)
+		b.value += elapsed * b.params.MinRecharge / 1000
+		if b.value > b.params.BufLimit {
+			b.value = b.params.BufLimit
+		}
+	}
+
+	b.lastUpdate = now
+}
+
+// accept deducts the cost from buffer if buffer value is enough. Otherwise, returns
+// the duration after which the request could be served.
+func (b *clientBuffer) accept(cost uint64) (uint64, time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.recharge(time.Now())
+	if b.value >= cost {
+		b.value -= cost
+		return b.value, 0, true
+	}
+
+	if b.params.MinRecharge == 0 {
+		return b.value, maxRetryAfter, false
+	}
+
+	retryAfter := time.Duration((cost-b.value)*1000/b.params.MinRecharge+1) * time.Millisecond
+	if retryAfter > maxRetryAfter {
+		retryAfter = maxRetryAfter
+	}
+
+	return b.value, retryAfter, false
+}
+
+// estimate returns the estimated buffer value at present
+func (b *clientBuffer) estimate() uint64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.recharge(time.Now())
+	return b.value
+}
+
+// throttled returns whether the server asked us to wait
+func (b *clientBuffer) throttled() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return time.Now().Before(b.retryAt)
+}
+
+// consume deducts the estimated cost of a request sent to server
+func (b *clientBuffer) consume(cost uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.recharge(time.Now())
+	if b.value > cost {
+		b.value -= cost
+	} else {
+		b.value = 0
+	}
+}
+
+// update syncs the buffer value and retry hint announced in server response
+func (b *clientBuffer) update(value uint64, retryAfter time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	b.value, b.lastUpdate = value, now
+	if b.value > b.params.BufLimit {
+		b.value = b.params.BufLimit
+	}
+
+	if retryAfter > 0 {
+		b.retryAt = now.Add(retryAfter)
+	}
+}
+
+// flowManager assigns serving capacity to connected clients in server mode
+type flowManager struct {
+	totalCapacity    uint64
+	clientCapacity   uint64
+	priorityCapacity uint64
+	priorityClients  map[common.Address]bool
+
+	allocated uint64
+	clients   map[common.Address]*clientBuffer
+	lock      sync.Mutex
+}
+
+func newFlowManager(conf *node.LightServerConfig) (*flowManager, error) {
+	fm := &flowManager{
+		totalCapacity:    DefaultServeCapacity,
+		clientCapacity:   DefaultClientCapacity,
+		priorityCapacity: DefaultPriorityCapacity,
+		priorityClients:  make(map[common.Address]bool),
+		clients:          make(map[common.Address]*clientBuffer),
+	}
+
+	if conf == nil {
+		return fm, nil
+	}
+
+	if conf.TotalCapacity > 0 {
+		fm.totalCapacity = conf.TotalCapacity
+	}
+
+	if conf.ClientCapacity > 0 {
+		fm.clientCapacity = conf.ClientCapacity
+	}
+
+	if conf.PriorityCapacity > 0 {
+		fm.priorityCapacity = conf.PriorityCapacity
+	}
+
+	for _, id := range conf.PriorityClients {
+		addr, err := common.HexToAddress(id)
+		if err != nil {
+			return nil, err
+		}
+
+		fm.priorityClients[addr] = true
+	}
+
+	return fm, nil
+}
+
+// register allocates capacity for a newly connected client. Priority clients are always
+// accepted, while normal clients are refused when the total serving capacity is used up.
+func (fm *flowManager) register(id common.Address) (flowParams, error) {
+	fm.lock.Lock()
+	defer fm.lock.Unlock()
+
+	recharge := fm.clientCapacity
+	if fm.priorityClients[id] {
+		recharge = fm.priorityCapacity
+	} else if fm.allocated+recharge > fm.totalCapacity {
+		return flowParams{}, errServerOverloaded
+	}
+
+	if old := fm.clients[id]; old != nil {
+		fm.allocated -= old.params.MinRecharge
+	}
+
+	params := flowParams{
+		BufLimit:    recharge * bufLimitSeconds,
+		MinRecharge: recharge,
+	}
+
+	fm.clients[id] = newClientBuffer(params)
+	fm.allocated += recharge
+	return params, nil
+}
+
+// unregister releases the capacity of a disconnected client
+func (fm *flowManager) unregister(id common.Address) {
+	fm.lock.Lock()
+	defer fm.lock.Unlock()
+
+	if b := fm.clients[id]; b != nil {
+		fm.allocated -= b.params.MinRecharge
+		delete(fm.clients, id)
+	}
+}
+
+// accept decides whether to serve a request with the specified cost from client, and
+// returns the remaining buffer value and a retry hint if refused.
+func (fm *flowManager) accept(id common.Address, cost uint64) (uint64, time.Duration, bool) {
+	fm.lock.Lock()
+	b := fm.clients[id]
+	fm.lock.Unlock()
+
+	if b == nil {
+		return 0, maxRetryAfter, false
+	}
+
+	return b.accept(cost)
+}
@@ -0,0 +1,110 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package light
+
+import (
+	"testing"
+	"time"
+
+	"github.com/scdoproject/go-stem/crypto"
+	"github.com/scdoproject/go-stem/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClientBuffer_Accept(t *testing.T) {
+	buf := newClientBuffer(flowParams{BufLimit: 100, MinRecharge: 1000})
+
+	value, retryAfter, ok := buf.accept(60)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), retryAfter)
+	assert.True(t, value >= 40 && value <= 100)
+
+	// buffer is not enough
+	buf.lastUpdate = time.Now().Add(time.Hour)
+	buf.value = 10
+	value, retryAfter, ok = buf.accept(60)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(10), value)
+	assert.True(t, retryAfter > 0 && retryAfter <= maxRetryAfter)
+}
+
+func Test_ClientBuffer_Recharge(t *testing.T) {
+	buf := newClientBuffer(flowParams{BufLimit: 100, MinRecharge: 10})
+	buf.value = 0
+
+	now := buf.lastUpdate.Add(2 * time.Second)
+	buf.recharge(now)
+	assert.Equal(t, uint64(20), buf.value)
+
+	// never exceed the buffer limit
+	buf.recharge(now.Add(time.Minute))
+	assert.Equal(t, uint64(100), buf.value)
+}
+
+func Test_ClientBuffer_Update(t *testing.T) {
+	buf := newClientBuffer(flowParams{BufLimit: 100, MinRecharge: 10})
+	assert.False(t, buf.throttled())
+
+	buf.update(1000, time.Minute)
+	assert.Equal(t, uint64(100), buf.value)
+	assert.True(t, buf.throttled())
+
+	buf.consume(200)
+	assert.Equal(t, uint64(0), buf.value)
+}
+
+func Test_FlowManager_Register(t *testing.T) {
+	priority := *crypto.MustGenerateRandomAddress()
+	fm, err := newFlowManager(&node.LightServerConfig{
+		TotalCapacity:    100,
+		ClientCapacity:   50,
+		PriorityCapacity: 80,
+		PriorityClients:  []string{priority.Hex()},
+	})
+	assert.Nil(t, err)
+
+	client1, client2, client3 := *crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress()
+	params, err := fm.register(client1)
+	assert.Nil(t, err)
+	assert.Equal(t, flowParams{BufLimit: 50 * bufLimitSeconds, MinRecharge: 50}, params)
+
+	_, err = fm.register(client2)
+	assert.Nil(t, err)
+
+	// capacity is used up
+	_, err = fm.register(client3)
+	assert.Equal(t, errServerOverloaded, err)
+
+	// priority client is always accepted
+	params, err = fm.register(priority)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(80), params.MinRecharge)
+
+	fm.unregister(priority)
+	fm.unregister(client1)
+	_, err = fm.register(client3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), fm.allocated)
+}
+
+func Test_FlowManager_Accept(t *testing.T) {
+	fm, err := newFlowManager(nil)
+	assert.Nil(t, err)
+
+	client := *crypto.MustGenerateRandomAddress()
+	_, _, ok := fm.accept(client, requestCost(blockRequestCode))
+	assert.False(t, ok)
+
+	params, err := fm.register(client)
+	assert.Nil(t, err)
+
+	value, _, ok := fm.accept(client, requestCost(blockRequestCode))
+	assert.True(t, ok)
+	assert.True(t, value < params.BufLimit)
+
+	_, err = newFlowManager(&node.LightServerConfig{PriorityClients: []string{"invalid"}})
+	assert.NotNil(t, err)
+}
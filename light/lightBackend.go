@@ -6,6 +6,7 @@ import (
 	"github.com/scdoproject/go-stem/api"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/core"
 	"github.com/scdoproject/go-stem/core/store"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/log"
@@ -124,3 +125,24 @@ func (l *LightBackend) GetDebt(debtHash common.Hash) (*types.Debt, *api.BlockInd
 
 	return result.Debt, result.BlockIndex, nil
 }
+
+// GetFinalizedCheckpoint returns the finalized checkpoint of chain, which is retrieved from
+// remote peer if the local one is lower than the specified height.
+func (l *LightBackend) GetFinalizedCheckpoint(height uint64) (*types.Checkpoint, error) {
+	if cp := l.s.chain.FinalizedCheckpoint(); cp != nil && cp.Height >= height {
+		return cp, nil
+	}
+
+	response, err := l.s.odrBackend.retrieveWithFilter(&odrCheckpointRequest{}, peerFilter{minVersion: LightScdoVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	if cp := response.(*odrCheckpointResponse).Checkpoint; cp != nil {
+		if err = l.s.chain.SetFinalizedCheckpoint(cp); err != nil && err != core.ErrCheckpointTooOld {
+			return nil, errors.NewStackedError(err, "failed to set finalized checkpoint")
+		}
+	}
+
+	return l.s.chain.FinalizedCheckpoint(), nil
+}
@@ -8,6 +8,7 @@ package light
 import (
 	"math/big"
 	"sync"
+	"sync/atomic"
 
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
@@ -32,6 +33,7 @@ type LightChain struct {
 	headerChangedEventManager *event.EventManager
 	headRollbackEventManager  *event.EventManager
 	log                       *log.ScdoLog
+	finalized                 atomic.Value // the latest checkpoint finalized by the finality committee of shard
 }
 
 func newLightChain(bcStore store.BlockchainStore, lightDB database.Database, odrBackend *odrBackend, engine consensus.Engine) (*LightChain, error) {
@@ -153,6 +155,26 @@ func (lc *LightChain) WriteHeader(header *types.BlockHeader) error {
 	return nil
 }
 
+// FinalizedCheckpoint returns the latest finalized checkpoint, or nil if none.
+func (lc *LightChain) FinalizedCheckpoint() *types.Checkpoint {
+	cp, _ := lc.finalized.Load().(*types.Checkpoint)
+	return cp
+}
+
+// SetFinalizedCheckpoint verifies and sets the finalized checkpoint retrieved from remote peer.
+func (lc *LightChain) SetFinalizedCheckpoint(cp *types.Checkpoint) error {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	if err := core.ValidateCheckpoint(cp, lc.FinalizedCheckpoint(), lc.engine, lc.bcStore, lc); err != nil {
+		return err
+	}
+
+	lc.finalized.Store(cp)
+
+	return nil
+}
+
 // GetCurrentState get current state
 func (lc *LightChain) GetCurrentState() (*state.Statedb, error) {
 	return lc.GetStateByRootAndBlockHash(lc.currentHeader.StateHash, lc.currentHeader.Hash())
@@ -7,6 +7,7 @@ package light
 
 import (
 	"errors"
+	"time"
 
 	"github.com/scdoproject/go-stem/core/store"
 )
@@ -24,26 +25,33 @@ const (
 	txByHashResponseCode
 	debtRequestCode
 	debtResponseCode
+	checkpointRequestCode // checkpoint codes are added in version 2
+	checkpointResponseCode
 	protocolMsgCodeLength // protocolMsgCodeLength always defined in the end.
+
+	// protocolMsgCodeLengthV1 is the number of message codes of version 1
+	protocolMsgCodeLengthV1 = checkpointRequestCode
 )
 
 var (
 	odrRequestFactories = map[uint16]func() odrRequest{
-		blockRequestCode:    func() odrRequest { return &odrBlock{} },
-		addTxRequestCode:    func() odrRequest { return &odrAddTx{} },
-		trieRequestCode:     func() odrRequest { return &odrTriePoof{} },
-		receiptRequestCode:  func() odrRequest { return &odrReceiptRequest{} },
-		txByHashRequestCode: func() odrRequest { return &odrTxByHashRequest{} },
-		debtRequestCode:     func() odrRequest { return &odrDebtRequest{} },
+		blockRequestCode:      func() odrRequest { return &odrBlock{} },
+		addTxRequestCode:      func() odrRequest { return &odrAddTx{} },
+		trieRequestCode:       func() odrRequest { return &odrTriePoof{} },
+		receiptRequestCode:    func() odrRequest { return &odrReceiptRequest{} },
+		txByHashRequestCode:   func() odrRequest { return &odrTxByHashRequest{} },
+		debtRequestCode:       func() odrRequest { return &odrDebtRequest{} },
+		checkpointRequestCode: func() odrRequest { return &odrCheckpointRequest{} },
 	}
 
 	odrResponseFactories = map[uint16]func() odrResponse{
-		blockResponseCode:    func() odrResponse { return &odrBlock{} },
-		addTxResponseCode:    func() odrResponse { return &odrAddTx{} },
-		trieResponseCode:     func() odrResponse { return &odrTriePoof{} },
-		receiptResponseCode:  func() odrResponse { return &odrReceiptResponse{} },
-		txByHashResponseCode: func() odrResponse { return &odrTxByHashResponse{} },
-		debtResponseCode:     func() odrResponse { return &odrDebtResponse{} },
+		blockResponseCode:      func() odrResponse { return &odrBlock{} },
+		addTxResponseCode:      func() odrResponse { return &odrAddTx{} },
+		trieResponseCode:       func() odrResponse { return &odrTriePoof{} },
+		receiptResponseCode:    func() odrResponse { return &odrReceiptResponse{} },
+		txByHashResponseCode:   func() odrResponse { return &odrTxByHashResponse{} },
+		debtResponseCode:       func() odrResponse { return &odrDebtResponse{} },
+		checkpointResponseCode: func() odrResponse { return &odrCheckpointResponse{} },
 	}
 )
 
@@ -59,6 +67,8 @@ type odrResponse interface {
 	setRequestID(requestID uint32)                                    // set the random request ID.
 	getError() error                                                  // get the response error if any.
 	setError(err error)                                               // set the response error.
+	getFlowStatus() (uint64, time.Duration, bool)                     // get the announced buffer value and retry hint if any.
+	setFlowStatus(bufValue uint64, retryAfter time.Duration)          // set the announced buffer value and retry hint.
 	validate(request odrRequest, bcStore store.BlockchainStore) error // validate the retrieved response.
 }
 
@@ -66,6 +76,11 @@ type odrResponse interface {
 type OdrItem struct {
 	ReqID uint32 // random request ID that generated dynamically
 	Error string // response error
+
+	// FlowStatus is the remaining flow control buffer value of client and the milliseconds to wait
+	// before retry, which is announced by server since version 2. It is an optional tail so that
+	// the items without it are still compatible with the peers of version 1.
+	FlowStatus []uint64 `rlp:"tail"`
 }
 
 func (item *OdrItem) getRequestID() uint32 {
@@ -92,6 +107,18 @@ func (item *OdrItem) setError(err error) {
 	}
 }
 
+func (item *OdrItem) getFlowStatus() (uint64, time.Duration, bool) {
+	if len(item.FlowStatus) < 2 {
+		return 0, 0, false
+	}
+
+	return item.FlowStatus[0], time.Duration(item.FlowStatus[1]) * time.Millisecond, true
+}
+
+func (item *OdrItem) setFlowStatus(bufValue uint64, retryAfter time.Duration) {
+	item.FlowStatus = []uint64{bufValue, uint64(retryAfter / time.Millisecond)}
+}
+
 func newErrorResponse(respCode uint16, reqID uint32, err error) (uint16, odrResponse) {
 	response := odrResponseFactories[respCode]()
 	response.setRequestID(reqID)
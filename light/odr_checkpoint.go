@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package light
+
+import (
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/consensus"
+	"github.com/scdoproject/go-stem/core/store"
+	"github.com/scdoproject/go-stem/core/types"
+)
+
+var errInvalidCheckpoint = errors.New("invalid finalized checkpoint")
+
+// ODR object to get the finalized checkpoint of chain.
+type odrCheckpointRequest struct {
+	OdrItem
+}
+
+type odrCheckpointResponse struct {
+	OdrItem
+	Checkpoint *types.Checkpoint `rlp:"nil"`
+}
+
+func (req *odrCheckpointRequest) code() uint16 {
+	return checkpointRequestCode
+}
+
+func (req *odrCheckpointRequest) handle(lp *LightProtocol) (uint16, odrResponse) {
+	response := &odrCheckpointResponse{
+		OdrItem: OdrItem{
+			ReqID: req.ReqID,
+		},
+	}
+
+	// no checkpoint if finality is not supported by server
+	if chain, ok := lp.chain.(consensus.FinalizedChain); ok {
+		response.Checkpoint = chain.FinalizedCheckpoint()
+	}
+
+	return checkpointResponseCode, response
+}
+
+// validate validates the checkpoint block is known, and the committed seals are
+// verified when the checkpoint is set in light chain.
+func (response *odrCheckpointResponse) validate(request odrRequest, bcStore store.BlockchainStore) error {
+	if response.Checkpoint == nil {
+		return nil
+	}
+
+	header, err := bcStore.GetBlockHeader(response.Checkpoint.Hash)
+	if err != nil {
+		return errors.NewStackedErrorf(err, "failed to get block header by hash %v", response.Checkpoint.Hash)
+	}
+
+	if header.Height != response.Checkpoint.Height {
+		return errInvalidCheckpoint
+	}
+
+	return nil
+}
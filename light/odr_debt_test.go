@@ -24,6 +24,7 @@ func newTestDebt(txHash common.Hash) *types.Debt {
 		Amount:  big.NewInt(38),
 		Price:   big.NewInt(666),
 		Code:    make([]byte, 0),
+		Call:    make([]*types.DebtCall, 0),
 	}
 
 	return &types.Debt{
@@ -34,6 +35,7 @@ func newTestDebt(txHash common.Hash) *types.Debt {
 
 func Test_odrDebtRequest_Serializable(t *testing.T) {
 	request := &odrDebtRequest{
+		OdrItem:  OdrItem{FlowStatus: make([]uint64, 0)},
 		DebtHash: common.StringToHash("debt hash"),
 	}
 
@@ -44,7 +46,8 @@ func Test_odrDebtResponse_Serializable(t *testing.T) {
 	// debt in pool
 	response := &odrDebtResponse{
 		OdrProvableResponse: OdrProvableResponse{
-			Proof: make([]proofNode, 0),
+			OdrItem: OdrItem{FlowStatus: make([]uint64, 0)},
+			Proof:   make([]proofNode, 0),
 		},
 		Debt: newTestDebt(common.StringToHash("tx hash")),
 	}
@@ -54,6 +57,7 @@ func Test_odrDebtResponse_Serializable(t *testing.T) {
 	// debt packed in blockchain
 	response = &odrDebtResponse{
 		OdrProvableResponse: OdrProvableResponse{
+			OdrItem: OdrItem{FlowStatus: make([]uint64, 0)},
 			BlockIndex: &api.BlockIndex{
 				BlockHash:   common.StringToHash("block hash"),
 				BlockHeight: 38,
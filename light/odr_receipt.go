@@ -6,6 +6,8 @@
 package light
 
 import (
+	"fmt"
+
 	"github.com/scdoproject/go-stem/api"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
@@ -31,9 +33,16 @@ func (request *odrReceiptRequest) code() uint16 {
 
 func (request *odrReceiptRequest) handle(lp *LightProtocol) (uint16, odrResponse) {
 	txIndex, err := lp.chain.GetStore().GetTxIndex(request.TxHash)
+	isDebt := false
 	if err != nil {
-		err = errors.NewStackedErrorf(err, "failed to get tx index by hash %v", request.TxHash)
-		return newErrorResponse(receiptResponseCode, request.ReqID, err)
+		// the receipt of cross shard call in debt
+		debtIndex, debtErr := lp.chain.GetStore().GetDebtIndex(request.TxHash)
+		if debtErr != nil {
+			err = errors.NewStackedErrorf(err, "failed to get tx index by hash %v", request.TxHash)
+			return newErrorResponse(receiptResponseCode, request.ReqID, err)
+		}
+
+		txIndex, isDebt = &types.TxIndex{BlockHash: debtIndex.BlockHash}, true
 	}
 
 	header, err := lp.chain.GetStore().GetBlockHeader(txIndex.BlockHash)
@@ -48,6 +57,15 @@ func (request *odrReceiptRequest) handle(lp *LightProtocol) (uint16, odrResponse
 		return newErrorResponse(receiptResponseCode, request.ReqID, err)
 	}
 
+	if isDebt {
+		txIndex.Index = uint(types.GetReceiptIndex(receipts, request.TxHash))
+	}
+
+	if txIndex.Index >= uint(len(receipts)) {
+		err = fmt.Errorf("receipt not found by hash %v", request.TxHash)
+		return newErrorResponse(receiptResponseCode, request.ReqID, err)
+	}
+
 	var result odrReceiptResponse
 	result.ReqID = request.ReqID
 	result.Receipt = receipts[txIndex.Index]
@@ -24,6 +24,7 @@ func newTestReceipt() *types.Receipt {
 		TxHash:          common.EmptyHash,
 		ContractAddress: []byte("test1"),
 		TotalFee:        uint64(0),
+		Debts:           []*types.Debt{},
 	}
 	return &receipt
 }
@@ -31,8 +32,9 @@ func newTestReceipt() *types.Receipt {
 func Test_OdrReceipt_Serializable(t *testing.T) {
 	request := odrReceiptRequest{
 		OdrItem: OdrItem{
-			ReqID: 38,
-			Error: "hello",
+			ReqID:      38,
+			Error:      "hello",
+			FlowStatus: make([]uint64, 0),
 		},
 		TxHash: common.StringToHash("tx hash"),
 	}
@@ -43,8 +45,9 @@ func Test_OdrReceipt_Serializable(t *testing.T) {
 	response := odrReceiptResponse{
 		OdrProvableResponse: OdrProvableResponse{
 			OdrItem: OdrItem{
-				ReqID: 38,
-				Error: "hello",
+				ReqID:      38,
+				Error:      "hello",
+				FlowStatus: make([]uint64, 0),
 			},
 			BlockIndex: &api.BlockIndex{
 				BlockHash:   common.StringToHash("tx hash"),
@@ -61,8 +64,9 @@ func Test_OdrReceipt_Serializable(t *testing.T) {
 	response = odrReceiptResponse{
 		OdrProvableResponse: OdrProvableResponse{
 			OdrItem: OdrItem{
-				ReqID: 38,
-				Error: "hello",
+				ReqID:      38,
+				Error:      "hello",
+				FlowStatus: make([]uint64, 0),
 			},
 			BlockIndex: &api.BlockIndex{
 				BlockHash:   common.StringToHash("tx hash"),
@@ -8,6 +8,7 @@ package light
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/scdoproject/go-stem/common"
 	"github.com/stretchr/testify/assert"
@@ -52,7 +53,17 @@ func Test_OdrItem_Serialize(t *testing.T) {
 		Name: "test name",
 	}
 
+	// the flow status is an optional tail, which is decoded as empty if not announced
+	obj.FlowStatus = make([]uint64, 0)
 	assertSerializable(t, &obj, &testOdrObj{})
+
+	obj.setFlowStatus(100, 2*time.Second)
+	assertSerializable(t, &obj, &testOdrObj{})
+
+	bufValue, retryAfter, ok := obj.getFlowStatus()
+	assert.Equal(t, uint64(100), bufValue)
+	assert.Equal(t, 2*time.Second, retryAfter)
+	assert.True(t, ok)
 }
 
 func assertSerializable(t *testing.T, ptrToEncode interface{}, ptrForDecode interface{}) {
@@ -62,3 +73,21 @@ func assertSerializable(t *testing.T, ptrToEncode interface{}, ptrForDecode inte
 	assert.Nil(t, common.Deserialize(encoded, ptrForDecode))
 	assert.Equal(t, ptrToEncode, ptrForDecode)
 }
+
+func Test_OdrItem_CompatibleWithV1(t *testing.T) {
+	type odrItemV1 struct {
+		ReqID uint32
+		Error string
+	}
+
+	// the item without flow status is decoded by peers of version 1
+	item := &odrItemV1{}
+	assert.Nil(t, common.Deserialize(common.SerializePanic(newOdrItem(38, "hello")), item))
+	assert.Equal(t, &odrItemV1{38, "hello"}, item)
+
+	// and the item of version 1 is decoded without flow status
+	decoded := &OdrItem{}
+	assert.Nil(t, common.Deserialize(common.SerializePanic(item), decoded))
+	_, _, ok := decoded.getFlowStatus()
+	assert.False(t, ok)
+}
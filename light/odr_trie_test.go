@@ -14,9 +14,10 @@ import (
 
 func Test_odrTriePoof_Rlp(t *testing.T) {
 	proof := odrTriePoof{
-		Root:  common.StringToHash("root"),
-		Key:   []byte("trie key"),
-		Proof: make([]proofNode, 0),
+		OdrItem: OdrItem{FlowStatus: make([]uint64, 0)},
+		Root:    common.StringToHash("root"),
+		Key:     []byte("trie key"),
+		Proof:   make([]proofNode, 0),
 	}
 
 	encoded, err := common.Serialize(proof)
@@ -23,9 +23,15 @@ var (
 	errServiceQuited = errors.New("Service has quited")
 )
 
+// odrMsg is the ODR response message along with the peer it comes from.
+type odrMsg struct {
+	peer *peer
+	msg  *p2p.Message
+}
+
 type odrBackend struct {
 	lock       sync.Mutex
-	msgCh      chan *p2p.Message
+	msgCh      chan *odrMsg
 	quitCh     chan struct{}
 	requestMap map[uint32]chan odrResponse
 	wg         sync.WaitGroup
@@ -38,7 +44,7 @@ type odrBackend struct {
 
 func newOdrBackend(bcStore store.BlockchainStore, shard uint) *odrBackend {
 	o := &odrBackend{
-		msgCh:      make(chan *p2p.Message),
+		msgCh:      make(chan *odrMsg),
 		requestMap: make(map[uint32]chan odrResponse),
 		quitCh:     make(chan struct{}),
 		bcStore:    bcStore,
@@ -60,15 +66,15 @@ func (o *odrBackend) run() {
 loopOut:
 	for {
 		select {
-		case msg := <-o.msgCh:
-			o.handleResponse(msg)
+		case m := <-o.msgCh:
+			o.handleResponse(m.peer, m.msg)
 		case <-o.quitCh:
 			break loopOut
 		}
 	}
 }
 
-func (o *odrBackend) handleResponse(msg *p2p.Message) {
+func (o *odrBackend) handleResponse(p *peer, msg *p2p.Message) {
 	factory, ok := odrResponseFactories[msg.Code]
 	if !ok {
 		return
@@ -80,6 +86,10 @@ func (o *odrBackend) handleResponse(msg *p2p.Message) {
 		return
 	}
 
+	if bufValue, retryAfter, ok := response.getFlowStatus(); ok && p != nil {
+		p.updateFlowStatus(bufValue, retryAfter)
+	}
+
 	o.lock.Lock()
 	defer o.lock.Unlock()
 
@@ -115,6 +125,7 @@ func (o *odrBackend) retrieve(request odrRequest) (odrResponse, error) {
 
 // retrieve retrieves the requested ODR object from remote peer with specified peer filter.
 func (o *odrBackend) retrieveWithFilter(request odrRequest, filter peerFilter) (odrResponse, error) {
+	filter.cost = requestCost(request.code())
 	reqID, ch, peerL, err := o.getReqInfo(filter)
 	if err != nil {
 		return nil, err
@@ -129,6 +140,7 @@ func (o *odrBackend) retrieveWithFilter(request odrRequest, filter peerFilter) (
 	request.setRequestID(reqID)
 	code, payload := request.code(), common.SerializePanic(request)
 	for _, p := range peerL {
+		p.consumeRequest(filter.cost)
 		o.log.Debug("peer send request, code = %s, payloadSizeBytes = %v", codeToStr(code), len(payload))
 		if err = p2p.SendMessage(p.rw, code, payload); err != nil {
 			o.log.Info("Failed to send message with peer %s", p.peerStrID)
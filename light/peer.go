@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"time"
 
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/core/types"
@@ -62,6 +63,9 @@ type peer struct {
 
 	lastAnnounceCodeTime int64
 	log             *log.ScdoLog
+
+	fcParams flowParams    // flow control parameters assigned to the client, only useful in server mode.
+	fcServer *clientBuffer // estimated flow control buffer of remote server, only useful in client mode.
 }
 
 func idToStr(id common.Address) string {
@@ -468,7 +472,7 @@ func (p *peer) handleAnnounce(msg *AnnounceBody) error {
 // handShake exchange networkid td etc between two connected peers.
 func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, headBlockNum uint64, genesis common.Hash) error {
 	msg := &statusData{
-		ProtocolVersion: uint32(LightScdoVersion),
+		ProtocolVersion: uint32(p.version),
 		NetworkID:       networkID,
 		IsServer:        p.protocolManager.bServerMode,
 		TD:              td,
@@ -477,6 +481,10 @@ func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, headBl
 		GenesisBlock:    genesis,
 	}
 
+	if p.version >= LightScdoVersion && p.fcParams.BufLimit > 0 {
+		msg.FlowParams = []uint64{p.fcParams.BufLimit, p.fcParams.MinRecharge}
+	}
+
 	if err := p2p.SendMessage(p.rw, statusDataMsgCode, common.SerializePanic(msg)); err != nil {
 		return err
 	}
@@ -507,6 +515,47 @@ func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, headBl
 		return errModeNotMatch
 	}
 
+	// no flow control if the server is of version 1
+	if retStatusMsg.IsServer && len(retStatusMsg.FlowParams) >= 2 {
+		p.fcServer = newClientBuffer(flowParams{
+			BufLimit:    retStatusMsg.FlowParams[0],
+			MinRecharge: retStatusMsg.FlowParams[1],
+		})
+	}
+
 	p.head, p.td, p.headBlockNum = retStatusMsg.CurrentBlock, retStatusMsg.TD, retStatusMsg.CurrentBlockNum
 	return nil
 }
+
+// canRequest returns whether the estimated flow control buffer of remote server
+// is enough for a request with the specified cost, only useful in client mode.
+func (p *peer) canRequest(cost uint64) bool {
+	if p.fcServer == nil {
+		return true
+	}
+
+	return !p.fcServer.throttled() && p.fcServer.estimate() >= cost
+}
+
+// capacity returns the estimated buffer value of remote server, only useful in client mode.
+func (p *peer) capacity() uint64 {
+	if p.fcServer == nil {
+		return 0
+	}
+
+	return p.fcServer.estimate()
+}
+
+// consumeRequest deducts the cost of a request sent to remote server, only useful in client mode.
+func (p *peer) consumeRequest(cost uint64) {
+	if p.fcServer != nil {
+		p.fcServer.consume(cost)
+	}
+}
+
+// updateFlowStatus updates the buffer value announced by remote server, only useful in client mode.
+func (p *peer) updateFlowStatus(bufValue uint64, retryAfter time.Duration) {
+	if p.fcServer != nil {
+		p.fcServer.update(bufValue, retryAfter)
+	}
+}
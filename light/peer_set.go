@@ -7,13 +7,16 @@ package light
 
 import (
 	"math/big"
-	"math/rand"
+	"sort"
 	"sync"
+
 	"github.com/scdoproject/go-stem/common"
 )
 
 type peerFilter struct {
-	blockHash common.Hash
+	blockHash  common.Hash
+	cost       uint64 // flow control cost of the request
+	minVersion uint   // minimum protocol version of peer that supports the request
 }
 
 type peerSet struct {
@@ -131,6 +134,10 @@ func (p *peerSet) choosePeers(filter peerFilter) (choosePeers []*peer) {
 
 	idx := 0
 	for _, v := range p.peerMap {
+		if v.version < filter.minVersion {
+			continue
+		}
+
 		peerL[idx] = v
 		idx++
 
@@ -142,26 +149,37 @@ func (p *peerSet) choosePeers(filter peerFilter) (choosePeers []*peer) {
 	const maxPeers = 3
 
 	// choose filtered peers
-	if len := len(filteredPeers); len > 0 {
-		if len <= maxPeers {
-			return filteredPeers
-		}
+	if len(filteredPeers) > 0 {
+		return choosePeersByCapacity(filteredPeers, filter.cost, maxPeers)
+	}
+
+	return choosePeersByCapacity(peerL[:idx], filter.cost, maxPeers)
+}
 
-		perm := rand.Perm(len)
-		for i := 0; i < maxPeers; i++ {
-			choosePeers = append(choosePeers, filteredPeers[perm[i]])
+// choosePeersByCapacity chooses at most maxPeers peers whose flow control buffer is enough for the cost,
+// and peers with more buffer are preferred. If no peer has enough buffer, peers are chosen randomly.
+func choosePeersByCapacity(peerL []*peer, cost uint64, maxPeers int) (choosePeers []*peer) {
+	common.Shuffle(peerL)
+
+	var available []*peer
+	for _, p := range peerL {
+		if p.canRequest(cost) {
+			available = append(available, p)
 		}
+	}
 
-		return
+	if len(available) == 0 {
+		available = peerL
+	} else {
+		sort.SliceStable(available, func(i, j int) bool {
+			return available[i].capacity() > available[j].capacity()
+		})
 	}
 
-	common.Shuffle(peerL)
-	cnt := 0
-	for _, p := range peerL {
-		cnt++
+	for _, p := range available {
 		choosePeers = append(choosePeers, p)
-		if cnt >= maxPeers {
-			return
+		if len(choosePeers) >= maxPeers {
+			break
 		}
 	}
 
@@ -0,0 +1,113 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package light
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/p2p"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMsgPipe reads the messages written by its pair
+type testMsgPipe struct {
+	in  chan *p2p.Message
+	out chan *p2p.Message
+}
+
+func (p *testMsgPipe) ReadMsg() (*p2p.Message, error) { return <-p.in, nil }
+
+func (p *testMsgPipe) WriteMsg(msg *p2p.Message) error {
+	p.out <- msg
+	return nil
+}
+
+func newTestMsgPipes() (*testMsgPipe, *testMsgPipe) {
+	a, b := make(chan *p2p.Message, 1), make(chan *p2p.Message, 1)
+	return &testMsgPipe{a, b}, &testMsgPipe{b, a}
+}
+
+// statusDataV1 is the status of peers of version 1, which has no flow control parameters
+type statusDataV1 struct {
+	ProtocolVersion uint32
+	NetworkID       string
+	IsServer        bool
+	TD              *big.Int
+	CurrentBlock    common.Hash
+	CurrentBlockNum uint64
+	GenesisBlock    common.Hash
+}
+
+func newTestHandShakePeer(version uint, serverMode bool, rw p2p.MsgReadWriter) *peer {
+	p := getTestPeer(0)
+	p.version, p.rw = version, rw
+	p.protocolManager = &LightProtocol{bServerMode: serverMode}
+	if serverMode {
+		p.fcParams = flowParams{BufLimit: 300, MinRecharge: 10}
+	}
+
+	return p
+}
+
+// handShakeWithV1 shakes hands between the peer and the peer of version 1, and returns the status
+// received by the peer of version 1.
+func handShakeWithV1(t *testing.T, p *peer, genesis common.Hash) statusDataV1 {
+	local, remote := newTestMsgPipes()
+	p.rw = local
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- p.handShake("test", big.NewInt(1), common.EmptyHash, 1, genesis) }()
+
+	msg, _ := remote.ReadMsg()
+	var status statusDataV1
+	assert.Nil(t, common.Deserialize(msg.Payload, &status))
+
+	assert.Nil(t, p2p.SendMessage(remote, statusDataMsgCode, common.SerializePanic(&statusDataV1{
+		ProtocolVersion: uint32(lightScdoVersion1),
+		NetworkID:       "test",
+		IsServer:        !p.protocolManager.bServerMode,
+		TD:              big.NewInt(1),
+		GenesisBlock:    genesis,
+	})))
+	assert.Nil(t, <-errCh)
+
+	return status
+}
+
+func Test_Peer_HandShakeWithV1(t *testing.T) {
+	genesis := common.StringToHash("genesis")
+
+	// server and client of version 1
+	server := newTestHandShakePeer(lightScdoVersion1, true, nil)
+	status := handShakeWithV1(t, server, genesis)
+	assert.Equal(t, uint32(lightScdoVersion1), status.ProtocolVersion)
+	assert.True(t, status.IsServer)
+
+	// client and server of version 1, which has no flow control
+	client := newTestHandShakePeer(lightScdoVersion1, false, nil)
+	handShakeWithV1(t, client, genesis)
+	assert.Nil(t, client.fcServer)
+	assert.True(t, client.canRequest(requestCost(checkpointRequestCode)))
+}
+
+func Test_Peer_HandShakeV2(t *testing.T) {
+	genesis := common.StringToHash("genesis")
+	serverRW, clientRW := newTestMsgPipes()
+	server := newTestHandShakePeer(LightScdoVersion, true, serverRW)
+	client := newTestHandShakePeer(LightScdoVersion, false, clientRW)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.handShake("test", big.NewInt(1), common.EmptyHash, 1, genesis) }()
+
+	assert.Nil(t, client.handShake("test", big.NewInt(1), common.EmptyHash, 1, genesis))
+	assert.Nil(t, <-errCh)
+
+	// the flow control parameters are announced to client
+	assert.NotNil(t, client.fcServer)
+	assert.Equal(t, server.fcParams, client.fcServer.params)
+}
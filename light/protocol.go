@@ -117,6 +117,7 @@ type LightProtocol struct {
 	quitCh              chan struct{}
 	syncCh              chan struct{}
 	chainHeaderChangeCh chan common.Hash
+	flowManager         *flowManager // serving capacity of clients, only useful in server mode
 	log                 *log.ScdoLog
 
 	shard uint
@@ -148,13 +149,27 @@ func NewLightProtocol(networkID string, txPool TransactionPool, debtPool *core.D
 		s.downloader = newDownloader(chain)
 	}
 
-	s.Protocol.AddPeer = s.handleAddPeer
+	s.Protocol.AddPeer = func(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+		return s.handleAddPeer(LightScdoVersion, p2pPeer, rw)
+	}
 	s.Protocol.DeletePeer = s.handleDelPeer
 	s.Protocol.GetPeer = s.handleGetPeer
 	rand2.Seed(time.Now().UnixNano())
 	return s, nil
 }
 
+// Protocols returns all supported versions of the protocol, and the highest one supported
+// by both sides is negotiated with the peer.
+func (lp *LightProtocol) Protocols() []p2p.Protocol {
+	v1 := lp.Protocol
+	v1.Version, v1.Length = lightScdoVersion1, protocolMsgCodeLengthV1
+	v1.AddPeer = func(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+		return lp.handleAddPeer(lightScdoVersion1, p2pPeer, rw)
+	}
+
+	return []p2p.Protocol{lp.Protocol, v1}
+}
+
 // Start starts data syncer
 func (lp *LightProtocol) Start() {
 	lp.log.Debug("LightProtocol.Start called!")
@@ -240,13 +255,39 @@ func (lp *LightProtocol) synchronise(peers []*peer) {
 	}
 }
 
-func (lp *LightProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+func (lp *LightProtocol) handleAddPeer(version uint, p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
 	if lp.peerSet.Find(p2pPeer.Node.ID) != nil {
 		lp.log.Error("handleAddPeer called, but peer of this public-key has already existed, so need quit!")
 		return false
 	}
 
-	newPeer := newPeer(LightScdoVersion, p2pPeer, rw, lp.log, lp)
+	newPeer := newPeer(version, p2pPeer, rw, lp.log, lp)
+	if lp.flowManager != nil {
+		params, err := lp.flowManager.register(p2pPeer.Node.ID)
+		if err != nil {
+			lp.log.Debug("handleAddPeer refused peer %s. %s", newPeer.peerStrID, err)
+			return false
+		}
+
+		newPeer.fcParams = params
+	}
+
+	if !lp.shakeHands(newPeer) {
+		if lp.flowManager != nil {
+			lp.flowManager.unregister(p2pPeer.Node.ID)
+		}
+
+		return false
+	}
+
+	lp.log.Info("add peer %s -> %s to LightProtocol.", p2pPeer.LocalAddr(), p2pPeer.RemoteAddr())
+	lp.peerSet.Add(newPeer)
+	go lp.handleMsg(newPeer)
+	return true
+}
+
+// shakeHands exchanges status with the new peer and announces the local chain in server mode
+func (lp *LightProtocol) shakeHands(newPeer *peer) bool {
 	store := lp.chain.GetStore()
 	hash, err := store.GetHeadBlockHash()
 	if err != nil {
@@ -291,9 +332,6 @@ func (lp *LightProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter)
 		}
 	}
 
-	lp.log.Info("add peer %s -> %s to LightProtocol.", p2pPeer.LocalAddr(), p2pPeer.RemoteAddr())
-	lp.peerSet.Add(newPeer)
-	go lp.handleMsg(newPeer)
 	return true
 }
 
@@ -313,6 +351,9 @@ func (lp *LightProtocol) handleDelPeer(peer *p2p.Peer) {
 	}
 
 	lp.peerSet.Remove(peer.Node.ID)
+	if lp.flowManager != nil {
+		lp.flowManager.unregister(peer.Node.ID)
+	}
 }
 
 func (lp *LightProtocol) handleMsg(peer *peer) {
@@ -409,7 +450,7 @@ handler:
 		}
 
 		if bNeedDeliverOdr {
-			lp.odrBackend.msgCh <- msg
+			lp.odrBackend.msgCh <- &odrMsg{peer, msg}
 		}
 	}
 
@@ -429,8 +470,28 @@ func (lp *LightProtocol) handleOdrRequest(peer *peer, msg *p2p.Message) error {
 		return fmt.Errorf("deserialize request failed with %s", err)
 	}
 
-	lp.log.Debug("begin to handle ODR request, code = %v, payloadLen = %v", codeToStr(msg.Code), len(msg.Payload))
-	respCode, response := request.handle(lp)
+	var respCode uint16
+	var response odrResponse
+	if lp.flowManager == nil {
+		lp.log.Debug("begin to handle ODR request, code = %v, payloadLen = %v", codeToStr(msg.Code), len(msg.Payload))
+		respCode, response = request.handle(lp)
+	} else {
+		bufValue, retryAfter, ok := lp.flowManager.accept(peer.peerID, requestCost(msg.Code))
+		if ok {
+			lp.log.Debug("begin to handle ODR request, code = %v, payloadLen = %v, bufValue = %v", codeToStr(msg.Code), len(msg.Payload), bufValue)
+			respCode, response = request.handle(lp)
+		} else {
+			// response code always follows the request code
+			lp.log.Debug("refuse ODR request, code = %v, bufValue = %v, retryAfter = %v, peerID = %v", codeToStr(msg.Code), bufValue, retryAfter, peer.peerStrID)
+			respCode, response = newErrorResponse(msg.Code+1, request.getRequestID(), errRequestThrottled)
+		}
+
+		// the flow status is only announced to the clients of version 2
+		if peer.version >= LightScdoVersion {
+			response.setFlowStatus(bufValue, retryAfter)
+		}
+	}
+
 	buff := common.SerializePanic(response)
 	lp.log.Debug("peer send response, code = %v, payloadSizeBytes = %v, peerID = %v", codeToStr(respCode), len(buff), peer.peerStrID)
 
@@ -34,6 +34,10 @@ func NewServiceServer(service *scdo.ScdoService, conf *node.Config, log *log.Scd
 		return nil, err
 	}
 
+	if scdoProtocol.flowManager, err = newFlowManager(&conf.LightServerConfig); err != nil {
+		return nil, err
+	}
+
 	s := &ServiceServer{
 		log:           log,
 		scdoProtocol: scdoProtocol,
@@ -47,7 +51,7 @@ func NewServiceServer(service *scdo.ScdoService, conf *node.Config, log *log.Scd
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *ServiceServer) Protocols() (protos []p2p.Protocol) {
-	return append(protos, s.scdoProtocol.Protocol)
+	return append(protos, s.scdoProtocol.Protocols()...)
 }
 
 // Start implements node.Service, starting goroutines needed by ServiceServer.
@@ -13,12 +13,13 @@ import (
 	"github.com/scdoproject/go-stem/accounts/abi"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/contract/system"
 	"github.com/scdoproject/go-stem/core/types"
 )
 
 var (
 	// ErrInvalidArguments is returned when NewContractEventABI arguments are invalid.
-	ErrInvalidArguments = errors.New("the abiPath, eventName and contract address cannot be empty")
+	ErrInvalidArguments = errors.New("the eventName and contract address cannot be empty, and abiPath is required for EVM contract")
 )
 
 // ContractEventABI represents contract event parser.
@@ -34,27 +35,32 @@ type ContractEventABI struct {
 	parser abi.ABI
 }
 
-// NewContractEventABI returns a ContractEventABI instance.
+// NewContractEventABI returns a ContractEventABI instance. The abiPath could be
+// empty for system contract, in which case the published system ABI is used.
 func NewContractEventABI(abiPath string, contract common.Address, eventNames ...string) (*ContractEventABI, error) {
-	if len(abiPath) == 0 || len(eventNames) == 0 {
+	if len(eventNames) == 0 || contract.Equal(common.EmptyAddress) {
 		return nil, ErrInvalidArguments
 	}
 
-	if contract.Equal(common.EmptyAddress) {
-		return nil, ErrInvalidArguments
+	// ensure the contract address is EVM or system contract
+	systemABI := system.GetContractABI(contract)
+	if !contract.IsEVMContract() && len(systemABI) == 0 {
+		return nil, fmt.Errorf("the address is not EVM or system contract, %v", contract)
 	}
 
-	// ensure the contract address is EVM contract
-	if !contract.IsEVMContract() {
-		return nil, fmt.Errorf("the address is not EVM contract, %v", contract)
-	}
+	abiJSON := systemABI
+	if len(abiPath) > 0 {
+		file, err := ioutil.ReadFile(abiPath)
+		if err != nil {
+			return nil, errors.NewStackedError(err, "failed to read abi file")
+		}
 
-	file, err := ioutil.ReadFile(abiPath)
-	if err != nil {
-		return nil, errors.NewStackedError(err, "failed to read abi file")
+		abiJSON = string(file)
+	} else if len(systemABI) == 0 {
+		return nil, ErrInvalidArguments
 	}
 
-	parser, err := abi.JSON(strings.NewReader(string(file)))
+	parser, err := abi.JSON(strings.NewReader(abiJSON))
 	if err != nil {
 		return nil, errors.NewStackedError(err, "failed to parse abi")
 	}
@@ -125,13 +131,11 @@ func (c *ContractEventABI) GetEvent(receipt *types.Receipt) ([]*Event, error) {
 			Topic:     log.Topics[0],
 		}
 
+		// unnecessary to check whether parser.Events has the event name, we have check it before
 		var err error
-		if log.Data != nil {
-			// unnecessary to check whether parser.Events has the event name, we have check it before
-			event.Arguments, err = c.parser.Events[eventName].Inputs.UnpackValues(log.Data)
-			if err != nil {
-				return nil, fmt.Errorf("event name %v not found in ABI file", eventName)
-			}
+		event.Arguments, err = c.parser.Events[eventName].UnpackLog(log.Topics, log.Data)
+		if err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to unpack event %v", eventName)
 		}
 
 		events = append(events, event)
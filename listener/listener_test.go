@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/contract/system"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -63,6 +64,18 @@ func Test_NewContractEventABI(t *testing.T) {
 	}
 	assert.Equal(t, c.contract, contract1)
 	assert.Equal(t, c.topicEventNames, topicEventNames)
+
+	// empty abi path of EVM contract
+	_, err = NewContractEventABI("", contract1, getX)
+	assert.Equal(t, err, ErrInvalidArguments)
+
+	// system contract with published abi
+	c, err = NewContractEventABI("", system.HashTimeLockContractAddress, "HTLCWithdrawn")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(c.topicEventNames))
+
+	_, err = NewContractEventABI("", system.HashTimeLockContractAddress, getX)
+	assert.Error(t, err)
 }
 
 var rs = `[{
@@ -15,7 +15,7 @@ import (
 )
 
 const (
-	depth = 9 // Once log invocation stack has changed, depth needs to change as well.
+	depth = 8 // Once log invocation stack has changed, depth needs to change as well.
 )
 
 // CallerHook a caller hook of logrus
@@ -5,6 +5,20 @@
 
 package comm
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatText is the default log format, which prints the text lines
+	FormatText = "text"
+	// FormatJSON is the log format that prints one JSON object per line
+	FormatJSON = "json"
+)
+
 // LogConfiguration is the Configuration of log
 var LogConfiguration = &LogConfig{PrintLog: true, IsDebug: true, DataDir: "log"}
 
@@ -18,4 +32,26 @@ type LogConfig struct {
 
 	// DataDir default log directory in temp folder
 	DataDir string `json:"-"`
+
+	// Format is the log format, FormatText or FormatJSON. The default is FormatText.
+	Format string `json:"format,omitempty"`
+
+	// Levels is the log level of each module, e.g. {"p2p": "debug"}, which takes precedence over IsDebug.
+	// The module "*" sets the level of all modules.
+	Levels map[string]string `json:"levels,omitempty"`
+}
+
+// Validate checks the log format and the levels of modules
+func (c *LogConfig) Validate() error {
+	if len(c.Format) > 0 && !strings.EqualFold(c.Format, FormatText) && !strings.EqualFold(c.Format, FormatJSON) {
+		return fmt.Errorf("invalid log format %s, should be %s or %s", c.Format, FormatText, FormatJSON)
+	}
+
+	for module, level := range c.Levels {
+		if _, err := logrus.ParseLevel(level); err != nil {
+			return fmt.Errorf("invalid log level %s of module %s", level, module)
+		}
+	}
+
+	return nil
 }
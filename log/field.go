@@ -0,0 +1,42 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package log
+
+import (
+	"github.com/scdoproject/go-stem/common"
+)
+
+// Field is a typed field of log entry, which is a separate key in the JSON output so that
+// it could be filtered in the log pipeline.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Height returns the field of block height
+func Height(height uint64) Field {
+	return Field{"height", height}
+}
+
+// Hash returns the field of block or tx hash in hex
+func Hash(hash common.Hash) Field {
+	return Field{"hash", hash.Hex()}
+}
+
+// Peer returns the field of remote peer id
+func Peer(id string) Field {
+	return Field{"peer", id}
+}
+
+// Shard returns the field of shard number
+func Shard(shard uint) Field {
+	return Field{"shard", shard}
+}
+
+// Any returns the field of key with any value
+func Any(key string, value interface{}) Field {
+	return Field{key, value}
+}
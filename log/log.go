@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lestrrat-go/file-rotatelogs"
@@ -18,8 +20,13 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// logExtension default log file extension
-const logExtension = ".log"
+const (
+	// logExtension default log file extension
+	logExtension = ".log"
+
+	// AllModules is the module name to set the level of all modules
+	AllModules = "*"
+)
 
 var (
 	// LogFolder the default folder to write logs
@@ -28,54 +35,97 @@ var (
 
 // ScdoLog wraps log class
 type ScdoLog struct {
-	log *logrus.Logger
+	log    *logrus.Logger
+	module string
+	fields logrus.Fields // the context fields attached to every entry
 }
 
 var logMap map[string]*ScdoLog
 var getLogMutex sync.Mutex
 
+// levelOverrides the module levels changed at runtime, which take precedence over the configuration
+var levelOverrides = make(map[string]logrus.Level)
+
 // Panic Level, highest level of severity. Panic logs and then calls panic with the
 // message passed to Debug, Info, ...
 func (p *ScdoLog) Panic(format string, args ...interface{}) {
-	p.log.Panicf(format, args...)
+	p.entry().Panicf(format, args...)
 }
 
 // Fatal Level. Fatal logs and then calls `os.Exit(1)`. It will exit even if the
 // logging level is set to Panic.
 func (p *ScdoLog) Fatal(format string, args ...interface{}) {
-	p.log.Fatalf(format, args...)
+	p.entry().Fatalf(format, args...)
 }
 
 // Error Level. Error logs and is used for errors that should be definitely noted.
 // Commonly used for hooks to send errors to an error tracking service.
 func (p *ScdoLog) Error(format string, args ...interface{}) {
-	p.log.Errorf(format, args...)
+	if p.enabled(logrus.ErrorLevel) {
+		p.entry().Errorf(format, args...)
+	}
 }
 
 // Warn Level. Non-critical entries that deserve eyes.
 func (p *ScdoLog) Warn(format string, args ...interface{}) {
-	p.log.Warnf(format, args...)
+	if p.enabled(logrus.WarnLevel) {
+		p.entry().Warnf(format, args...)
+	}
 }
 
 // Info Level. General operational entries about what's going on inside the
 // application.
 func (p *ScdoLog) Info(format string, args ...interface{}) {
-	p.log.Infof(format, args...)
+	if p.enabled(logrus.InfoLevel) {
+		p.entry().Infof(format, args...)
+	}
 }
 
 // Debug Level. Usually only enabled when debugging. Very verbose logging.
 func (p *ScdoLog) Debug(format string, args ...interface{}) {
-	p.log.Debugf(format, args...)
+	if p.enabled(logrus.DebugLevel) {
+		p.entry().Debugf(format, args...)
+	}
 }
 
-// SetLevel set the log level
+// SetLevel set the log level, which is shared by the child loggers of module
 func (p *ScdoLog) SetLevel(level logrus.Level) {
 	p.log.SetLevel(level)
 }
 
 // GetLevel get the log level
 func (p *ScdoLog) GetLevel() logrus.Level {
-	return p.log.Level
+	return logrus.Level(atomic.LoadUint32((*uint32)(&p.log.Level)))
+}
+
+// With returns a child logger of the same module, which attaches the fields to every entry
+// in addition to the fields of p.
+func (p *ScdoLog) With(fields ...Field) *ScdoLog {
+	child := &ScdoLog{
+		log:    p.log,
+		module: p.module,
+		fields: make(logrus.Fields, len(p.fields)+len(fields)),
+	}
+
+	for k, v := range p.fields {
+		child.fields[k] = v
+	}
+
+	for _, f := range fields {
+		child.fields[f.Key] = f.Value
+	}
+
+	return child
+}
+
+func (p *ScdoLog) enabled(level logrus.Level) bool {
+	return p.GetLevel() >= level
+}
+
+// entry returns a new entry with the context fields. It is always a new entry because the
+// caller hook writes to the entry data.
+func (p *ScdoLog) entry() *logrus.Entry {
+	return p.log.WithFields(p.fields)
 }
 
 // GetLogger gets logrus.Logger object according to module name
@@ -91,8 +141,8 @@ func GetLogger(module string) *ScdoLog {
 		return curLog
 	}
 
-	logrus.SetFormatter(&logrus.TextFormatter{})
 	log := logrus.New()
+	log.Formatter = newFormatter(comm.LogConfiguration.Format)
 
 	if comm.LogConfiguration.PrintLog {
 		log.Out = os.Stdout
@@ -118,16 +168,88 @@ func GetLogger(module string) *ScdoLog {
 		log.Out = writer
 	}
 
-	if comm.LogConfiguration.IsDebug {
-		log.SetLevel(logrus.DebugLevel)
-	} else {
-		log.SetLevel(logrus.InfoLevel)
-	}
-
+	log.SetLevel(moduleLevel(module))
 	log.AddHook(&CallerHook{module: module}) // add caller hook to print caller's file and line number
 	curLog = &ScdoLog{
-		log: log,
+		log:    log,
+		module: module,
 	}
 	logMap[module] = curLog
 	return curLog
 }
+
+// newFormatter returns the JSON formatter for the json format, otherwise the text formatter
+func newFormatter(format string) logrus.Formatter {
+	if strings.EqualFold(format, comm.FormatJSON) {
+		return &logrus.JSONFormatter{}
+	}
+
+	return &logrus.TextFormatter{}
+}
+
+// moduleLevel returns the level of module, which is changed at runtime, or configured in
+// the levels of LogConfig, or the debug level if IsDebug is true, otherwise the info level.
+func moduleLevel(module string) logrus.Level {
+	if level, ok := levelOverrides[module]; ok {
+		return level
+	}
+
+	if level, ok := levelOverrides[AllModules]; ok {
+		return level
+	}
+
+	for _, name := range []string{module, AllModules} {
+		if name, ok := comm.LogConfiguration.Levels[name]; ok {
+			if level, err := logrus.ParseLevel(name); err == nil {
+				return level
+			}
+		}
+	}
+
+	if comm.LogConfiguration.IsDebug {
+		return logrus.DebugLevel
+	}
+
+	return logrus.InfoLevel
+}
+
+// SetModuleLevel changes the level of module at runtime, including the loggers created
+// later. The level of all modules is changed if module is AllModules.
+func SetModuleLevel(module string, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	getLogMutex.Lock()
+	defer getLogMutex.Unlock()
+
+	if module == AllModules {
+		levelOverrides = map[string]logrus.Level{AllModules: lvl}
+		for _, l := range logMap {
+			l.SetLevel(lvl)
+		}
+
+		return nil
+	}
+
+	levelOverrides[module] = lvl
+	if l, ok := logMap[module]; ok {
+		l.SetLevel(lvl)
+	}
+
+	return nil
+}
+
+// GetModuleLevels returns the levels of all created loggers by module
+func GetModuleLevels() map[string]string {
+	getLogMutex.Lock()
+	defer getLogMutex.Unlock()
+
+	levels := make(map[string]string, len(logMap))
+	for module, l := range logMap {
+		levels[module] = l.GetLevel().String()
+	}
+
+	return levels
+}
@@ -6,8 +6,11 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,3 +79,61 @@ func Test_LogLevels(t *testing.T) {
 	log = GetLogger("test5")
 	assert.Equal(t, logrus.InfoLevel, log.GetLevel())
 }
+
+func Test_LogJSONWithFields(t *testing.T) {
+	format := comm.LogConfiguration.Format
+	defer func() {
+		comm.LogConfiguration.Format = format
+	}()
+
+	comm.LogConfiguration.Format = comm.FormatJSON
+	lg := GetLogger("test6")
+	buf := new(bytes.Buffer)
+	lg.log.Out = buf
+
+	hash := common.StringToHash("block")
+	child := lg.With(Height(10), Shard(1)).With(Hash(hash), Peer("0x01"))
+	child.Info("block %d", 10)
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "block 10", entry["msg"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "test6", entry["module"])
+	assert.Equal(t, float64(10), entry["height"])
+	assert.Equal(t, float64(1), entry["shard"])
+	assert.Equal(t, hash.Hex(), entry["hash"])
+	assert.Equal(t, "0x01", entry["peer"])
+	assert.True(t, strings.HasPrefix(entry["caller"].(string), "log_test.go:"))
+
+	// parent logger has no fields of child
+	buf.Reset()
+	lg.Info("parent")
+	entry = nil
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Nil(t, entry["height"])
+}
+
+func Test_ModuleLevels(t *testing.T) {
+	levels := comm.LogConfiguration.Levels
+	defer func() {
+		comm.LogConfiguration.Levels = levels
+	}()
+
+	comm.LogConfiguration.Levels = map[string]string{"test7": "warn"}
+	lg := GetLogger("test7")
+	assert.Equal(t, logrus.WarnLevel, lg.GetLevel())
+
+	// the level of module is shared by child loggers
+	assert.NoError(t, SetModuleLevel("test7", "error"))
+	assert.Equal(t, logrus.ErrorLevel, lg.With(Height(1)).GetLevel())
+	assert.Equal(t, "error", GetModuleLevels()["test7"])
+
+	// the logger created later uses the runtime level
+	assert.NoError(t, SetModuleLevel("test8", "error"))
+	assert.Equal(t, logrus.ErrorLevel, GetLogger("test8").GetLevel())
+
+	assert.Error(t, SetModuleLevel("test7", "verbose"))
+	assert.Error(t, (&comm.LogConfig{Format: "xml"}).Validate())
+	assert.Error(t, (&comm.LogConfig{Levels: map[string]string{"p2p": "verbose"}}).Validate())
+}
@@ -208,3 +208,39 @@ func (m *MerkleTree) String() string {
 	}
 	return s
 }
+
+// Proof returns the sibling hashes from the leaf at index to the root, which could be
+// verified with VerifyProof. The sibling of the rightmost node of an odd level is itself.
+func (m *MerkleTree) Proof(index int) ([]common.Hash, error) {
+	if index < 0 || index >= len(m.Leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range", index)
+	}
+
+	var proof []common.Hash
+	for n := m.Leaves[index]; n.Parent != nil; n = n.Parent {
+		if n.Parent.Left == n {
+			proof = append(proof, n.Parent.Right.Hash)
+		} else {
+			proof = append(proof, n.Parent.Left.Hash)
+		}
+	}
+
+	return proof, nil
+}
+
+// VerifyProof returns whether the leaf hash at index is proved by the sibling hashes from
+// bottom to top. The index must be fully consumed by the proof.
+func VerifyProof(root, leaf common.Hash, index uint64, proof []common.Hash) bool {
+	hash := leaf
+	for _, sibling := range proof {
+		if index&1 == 1 {
+			hash = crypto.HashBytes(sibling.Bytes(), hash.Bytes())
+		} else {
+			hash = crypto.HashBytes(hash.Bytes(), sibling.Bytes())
+		}
+
+		index >>= 1
+	}
+
+	return index == 0 && hash == root
+}
@@ -200,6 +200,36 @@ func Test_MerkleTree_VerifyContent(t *testing.T) {
 	}
 }
 
+func Test_MerkleTree_Proof(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		contents := createRandomContent(size)
+		tree, err := NewTree(contents)
+		if err != nil {
+			t.Fatalf("error: unexpected error: %s", err)
+		}
+
+		for i, c := range contents {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("error: unexpected error: %s", err)
+			}
+
+			if !VerifyProof(tree.MerkleRoot(), c.CalculateHash(), uint64(i), proof) {
+				t.Errorf("error: expected valid proof of leaf %d in tree of size %d", i, size)
+			}
+
+			if VerifyProof(tree.MerkleRoot(), c.CalculateHash(), uint64(i+len(tree.Leaves)), proof) {
+				t.Errorf("error: expected invalid proof of leaf %d with wrong index", i)
+			}
+		}
+	}
+
+	tree, _ := NewTree(createRandomContent(3))
+	if _, err := tree.Proof(4); err == nil {
+		t.Error("error: expected error of index out of range")
+	}
+}
+
 func Test_MerkleTree_String(t *testing.T) {
 	for i := 0; i < len(table); i++ {
 		tree, err := NewTree(table[i].contents)
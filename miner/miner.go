@@ -235,7 +235,8 @@ func (miner *Miner) challengedTxCallback(e event.Event) {
 		// panic("WELL DONE!")
 		// if atomic.LoadInt32(&miner.isReverted) == 0 {
 		// revertHeight := miner.current.header.Height / 10
-		revertHeight := miner.current.header.Height / common.RelayRange
+		info := miner.scdo.GenesisInfo()
+		revertHeight := miner.current.header.Height / info.GetRelayRange()
 		if revertHeight <= 0 {
 			revertHeight = 0
 		} else {
@@ -315,10 +316,11 @@ out:
 					break
 				}
 
-				miner.log.Info("found a new mined block, block height:%d, hash:%s, time: %d", result.Header.Height, result.HeaderHash.Hex(), time.Now().UnixNano())
+				blockLog := miner.log.With(log.Height(result.Header.Height), log.Hash(result.HeaderHash))
+				blockLog.Info("found a new mined block, block height:%d, hash:%s, time: %d", result.Header.Height, result.HeaderHash.Hex(), time.Now().UnixNano())
 				ret := miner.saveBlock(result)
 				if ret != nil {
-					miner.log.Error("failed to save the block, for %s", ret.Error())
+					blockLog.Error("failed to save the block, for %s", ret.Error())
 					break
 				}
 				// this will be used for BFT
@@ -326,7 +328,7 @@ out:
 					h.HandleNewChainHead()
 				}
 
-				miner.log.Info("saved mined block successfully")
+				blockLog.Info("saved mined block successfully")
 				event.BlockMinedEventManager.Fire(result) // notify p2p to broadcast the block
 				break
 			}
@@ -389,20 +391,21 @@ func (miner *Miner) prepareNewBlock(recv chan *types.Block) error {
 		return fmt.Errorf("failed to prepare header, %s", err)
 	}
 
+	taskLog := miner.log.With(log.Height(header.Height), log.Shard(header.Creator.Shard()))
 	miner.current = NewTask(header, miner.coinbase, miner.debtVerifier)
 	// here we add the verifierTx, challengeTx and exitTx
 	// before that, once we have detected any challenged tx, we need to revert the blockchain first
 	if miner.current.header.Consensus == types.BftConsensus {
-		err = miner.current.applyTransactionsSubchain(miner.scdo, stateDB, miner.scdo.BlockChain().AccountDB(), miner.log, &miner.revertedTx)
+		err = miner.current.applyTransactionsSubchain(miner.scdo, stateDB, miner.scdo.BlockChain().AccountDB(), taskLog, &miner.revertedTx)
 	} else {
-		err = miner.current.applyTransactionsAndDebts(miner.scdo, stateDB, miner.scdo.BlockChain().AccountDB(), miner.log)
+		err = miner.current.applyTransactionsAndDebts(miner.scdo, stateDB, miner.scdo.BlockChain().AccountDB(), taskLog)
 
 	}
 	if err != nil {
 		return fmt.Errorf("failed to apply transaction %s", err)
 	}
 
-	miner.log.Info("committing a new task to engine, height:%d, difficult:%d", header.Height, header.Difficulty)
+	taskLog.Info("committing a new task to engine, height:%d, difficult:%d", header.Height, header.Difficulty)
 	miner.commitTask(miner.current, recv)
 
 	return nil
@@ -30,6 +30,8 @@ type Task struct {
 	receipts []*types.Receipt
 	debts    []*types.Debt
 
+	debtReceipts []*types.Receipt // receipts of cross shard calls in debts, which follow the tx receipts
+
 	coinbase     common.Address
 	debtVerifier types.DebtVerifier
 	// verifierTxs  []*types.Transaction
@@ -134,7 +136,7 @@ func (task *Task) chooseDebts(scdo ScdoBackend, statedb *state.Statedb, log *log
 		}
 
 		for _, d := range debts {
-			err := scdo.BlockChain().ApplyDebtWithoutVerify(statedb, d, task.coinbase)
+			receipt, err := scdo.BlockChain().ApplyDebtWithoutVerify(statedb, d, len(task.debts), task.header)
 			if err != nil {
 				log.Warn("apply debt error %s", err)
 				scdo.DebtPool().RemoveDebtByHash(d.Hash)
@@ -143,6 +145,9 @@ func (task *Task) chooseDebts(scdo ScdoBackend, statedb *state.Statedb, log *log
 
 			size = size - d.Size()
 			task.debts = append(task.debts, d)
+			if receipt != nil {
+				task.debtReceipts = append(task.debtReceipts, receipt)
+			}
 		}
 	}
 
@@ -160,7 +165,7 @@ func (task *Task) handleMinerRewardTx(statedb *state.Statedb) (*big.Int, error)
 		return nil, err
 	}
 
-	rewardTxReceipt, err := txs.ApplyRewardTx(rewardTx, statedb)
+	rewardTxReceipt, err := txs.ApplyRewardTx(rewardTx, statedb, task.header)
 	if err != nil {
 		return nil, err
 	}
@@ -204,6 +209,12 @@ func (task *Task) chooseTransactions(scdo ScdoBackend, statedb *state.Statedb, l
 
 	txIndex := 1 // the first tx is miner reward
 
+	counter, err := scdo.BlockChain().NewTxCounter(statedb, task.header.PreviousBlockHash, task.header.Height)
+	if err != nil {
+		log.Error("failed to create tx counter, %s", err)
+		return
+	}
+
 	for size > 0 {
 		txs, txsSize := scdo.TxPool().GetProcessableTransactions(size)
 		if len(txs) == 0 {
@@ -218,6 +229,13 @@ func (task *Task) chooseTransactions(scdo ScdoBackend, statedb *state.Statedb, l
 				continue
 			}
 
+			// the tx is kept in pool for the next relay range
+			if err := counter.Check(tx); err != nil {
+				log.Debug("skip tx %s, %s", tx.Hash.Hex(), err)
+				txsSize = txsSize - tx.Size()
+				continue
+			}
+
 			receipt, err := scdo.BlockChain().ApplyTransaction(tx, txIndex, task.coinbase, statedb, task.header)
 			if err != nil {
 				scdo.TxPool().RemoveTransaction(tx.Hash)
@@ -246,6 +264,7 @@ func (task *Task) chooseTransactions(scdo ScdoBackend, statedb *state.Statedb, l
 				}
 			}
 
+			counter.Count(tx)
 			task.txs = append(task.txs, tx)
 			task.receipts = append(task.receipts, receipt)
 			txIndex++
@@ -298,6 +317,12 @@ func (task *Task) chooseTransactionsSubchain(scdo ScdoBackend, statedb *state.St
 	*/
 	txIndex := 1 // the first tx is miner reward
 
+	counter, err := scdo.BlockChain().NewTxCounter(statedb, task.header.PreviousBlockHash, task.header.Height)
+	if err != nil {
+		log.Error("failed to create tx counter, %s", err)
+		return
+	}
+
 	for size > 0 {
 		txs, txsSize := scdo.TxPool().GetProcessableTransactions(size)
 		if len(txs) == 0 {
@@ -316,6 +341,13 @@ func (task *Task) chooseTransactionsSubchain(scdo ScdoBackend, statedb *state.St
 				continue
 			}
 
+			// the tx is kept in pool for the next relay range
+			if err := counter.Check(tx); err != nil {
+				log.Debug("skip tx %s, %s", tx.Hash.Hex(), err)
+				txsSize = txsSize - tx.Size()
+				continue
+			}
+
 			receipt, err := scdo.BlockChain().ApplyTransaction(tx, txIndex, task.coinbase, statedb, task.header)
 			if err != nil {
 				scdo.TxPool().RemoveTransaction(tx.Hash)
@@ -350,6 +382,7 @@ func (task *Task) chooseTransactionsSubchain(scdo ScdoBackend, statedb *state.St
 				}
 			}
 
+			counter.Count(tx)
 			task.txs = append(task.txs, tx)
 			task.receipts = append(task.receipts, receipt)
 			txIndex++
@@ -372,7 +405,7 @@ func (task *Task) chooseTransactionsSubchain(scdo ScdoBackend, statedb *state.St
 
 // generateBlock builds a block from task
 func (task *Task) generateBlock() *types.Block {
-	return types.NewBlock(task.header, task.txs, task.receipts, task.debts)
+	return types.NewBlock(task.header, task.txs, append(task.receipts, task.debtReceipts...), task.debts)
 }
 
 // Result is the result mined by engine. It contains the raw task and mined block.
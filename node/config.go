@@ -40,6 +40,9 @@ type Config struct {
 
 	// metrics config info
 	MetricsConfig *metrics.Config
+
+	// The configuration of light server flow control
+	LightServerConfig LightServerConfig
 }
 
 // IpcConfig config for ipc rpc service
@@ -76,6 +79,9 @@ type BasicConfig struct {
 
 	// MinerAlgorithm miner algorithm
 	MinerAlgorithm string `json:"algorithm"`
+
+	// The file system path of the key files managed by the personal api, <DataDir>/keystore by default
+	KeyStoreDir string `json:"keystoreDir"`
 }
 
 // HTTPServer config for http server
@@ -100,6 +106,21 @@ type WSServerConfig struct {
 	CrossOrigins []string `json:"crossorigins"`
 }
 
+// LightServerConfig config for serving light clients
+type LightServerConfig struct {
+	// TotalCapacity is the total cost units per second served to all light clients, 0 means default.
+	TotalCapacity uint64 `json:"totalCapacity"`
+
+	// ClientCapacity is the cost units per second that refill the buffer of a normal client, 0 means default.
+	ClientCapacity uint64 `json:"clientCapacity"`
+
+	// PriorityClients is the node IDs of clients that are always accepted and served with PriorityCapacity.
+	PriorityClients []string `json:"priorityClients"`
+
+	// PriorityCapacity is the cost units per second that refill the buffer of a priority client, 0 means default.
+	PriorityCapacity uint64 `json:"priorityCapacity"`
+}
+
 // Config is the scdo's configuration to create scdo service
 type ScdoConfig struct {
 	TxConf core.TransactionPoolConfig
@@ -541,6 +541,9 @@ func (srv *Server) setupConn(fd net.Conn, flags int, dialDest *discovery.Node) e
 		peer.Node = peerNode
 	}
 
+	// attach the remote node to the logs of peer
+	peer.log = srv.log.With(log.Peer(peer.Node.ID.Hex()), log.Shard(peer.Node.Shard))
+
 	go func() {
 		srv.loopWG.Add(1)
 		if srv.addPeer(peer) {
@@ -579,13 +582,19 @@ func (srv *Server) peerIsValidate(recvMsg *ProtoHandShake) ([]Cap, bool) {
 		localCapSet.Add(proto.cap())
 	}
 
-	var capNameList []Cap
+	// match the highest version of each protocol supported by both sides
+	matched := make(map[string]Cap)
 	for _, cap := range recvMsg.Caps {
-		if localCapSet.Has(cap) {
-			capNameList = append(capNameList, cap)
+		if old, ok := matched[cap.Name]; localCapSet.Has(cap) && (!ok || cap.Version > old.Version) {
+			matched[cap.Name] = cap
 		}
 	}
 
+	var capNameList []Cap
+	for _, cap := range matched {
+		capNameList = append(capNameList, cap)
+	}
+
 	if len(capNameList) == 0 {
 		return nil, false
 	}
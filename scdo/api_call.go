@@ -0,0 +1,207 @@
+/**
+*  @file
+*  @copyright defined in go-stem/LICENSE
+ */
+
+package scdo
+
+import (
+	"fmt"
+	"math/big"
+
+	api2 "github.com/scdoproject/go-stem/api"
+	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/core/state"
+	"github.com/scdoproject/go-stem/core/svm"
+	"github.com/scdoproject/go-stem/core/types"
+	"github.com/scdoproject/go-stem/crypto"
+)
+
+// CallArgs is the unsigned message to simulate. A random account with enough balance is used
+// if From is empty, and a contract is created if To is empty.
+type CallArgs struct {
+	From     common.Address
+	To       common.Address
+	Value    *big.Int
+	Gas      uint64
+	GasPrice *big.Int
+	Data     string // hex payload
+}
+
+// AccountOverrideArgs is the account states replaced before simulation, empty fields are not changed
+type AccountOverrideArgs struct {
+	Balance *big.Int
+	Nonce   *uint64
+	Code    string // hex code
+	State   map[common.Hash]common.Hash
+}
+
+// StateOverrideArgs is the account states replaced before simulation
+type StateOverrideArgs map[common.Address]*AccountOverrideArgs
+
+// toMessage converts the args to the message and state overrides to simulate
+func (args *CallArgs) toMessage(overrides *StateOverrideArgs) (*svm.Message, svm.StateOverride, error) {
+	payload, err := hexutil.HexToBytes(args.Data)
+	if len(args.Data) > 0 && err != nil {
+		return nil, nil, fmt.Errorf("invalid data, %s", err)
+	}
+
+	msg := &svm.Message{
+		From:     args.From,
+		To:       args.To,
+		Amount:   args.Value,
+		GasPrice: args.GasPrice,
+		GasLimit: args.Gas,
+		Payload:  payload,
+	}
+
+	if msg.Amount == nil {
+		msg.Amount = big.NewInt(0)
+	}
+
+	if msg.GasPrice == nil {
+		msg.GasPrice = big.NewInt(1)
+	}
+
+	override := make(svm.StateOverride)
+	if overrides != nil {
+		for addr, account := range *overrides {
+			if account == nil {
+				continue
+			}
+
+			o := &svm.AccountOverride{
+				Balance: account.Balance,
+				Nonce:   account.Nonce,
+				State:   account.State,
+			}
+
+			if len(account.Code) > 0 {
+				if o.Code, err = hexutil.HexToBytes(account.Code); err != nil {
+					return nil, nil, fmt.Errorf("invalid code of account %v, %s", addr.Hex(), err)
+				}
+			}
+
+			override[addr] = o
+		}
+	}
+
+	// use a random account in local shard with enough balance
+	if msg.From.IsEmpty() {
+		msg.From = *crypto.MustGenerateShardAddress(common.LocalShardNumber)
+
+		gas := msg.GasLimit
+		if gas == 0 {
+			gas = svm.DefaultCallGas
+		}
+
+		balance := new(big.Int).Mul(msg.GasPrice, new(big.Int).SetUint64(gas))
+		override[msg.From] = &svm.AccountOverride{Balance: balance.Add(balance, msg.Amount)}
+	}
+
+	return msg, override, nil
+}
+
+// newStateFunc returns the header and the function to create the statedb with the state overrides
+// of block height. If height is the pending block height, the pending state is rebuilt with the tx
+// pool for each statedb except the first one.
+func (api *PublicScdoAPI) newStateFunc(height int64, override svm.StateOverride) (*types.BlockHeader, svm.StateFunc, error) {
+	if height == api2.PendingBlockHeight {
+		backend := NewScdoBackend(api.s)
+		header, pending, err := api2.PendingState(backend)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return header, func() (*state.Statedb, error) {
+			statedb := pending
+			if statedb == nil {
+				if _, statedb, err = api2.PendingState(backend); err != nil {
+					return nil, err
+				}
+			}
+
+			pending = nil
+			override.Apply(statedb)
+			return statedb, nil
+		}, nil
+	}
+
+	// Get the block by block height, if the height is less than zero, get the current block.
+	block, err := getBlock(api.s.chain, height)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return block.Header, func() (*state.Statedb, error) {
+		statedb, err := state.NewStatedb(block.Header.StateHash, api.s.accountStateDB)
+		if err != nil {
+			return nil, err
+		}
+
+		override.Apply(statedb)
+		return statedb, nil
+	}, nil
+}
+
+// SimulateCall executes the unsigned message on the statedb of block height, or the pending
+// block, with the optional state overrides. It does not affect the statedb and blockchain,
+// and returns the receipt with the revert reason if failed.
+func (api *PublicScdoAPI) SimulateCall(args CallArgs, height int64, overrides *StateOverrideArgs) (map[string]interface{}, error) {
+	msg, override, err := args.toMessage(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.GasLimit == 0 {
+		msg.GasLimit = svm.DefaultCallGas
+	}
+
+	header, newState, err := api.newStateFunc(height, override)
+	if err != nil {
+		return nil, err
+	}
+
+	statedb, err := newState()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := svm.Call(statedb, header, api.s.chain.GetStore(), msg)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := api2.PrintableReceipt(result.Receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Failed() {
+		output["revertReason"] = result.RevertReason()
+		if len(result.Revert) > 0 {
+			output["revert"] = hexutil.BytesToHex(result.Revert)
+		}
+	}
+
+	return output, nil
+}
+
+// EstimateCallGas returns the smallest gas limit with which the unsigned message succeeds on the
+// statedb of block height, or the pending block, with the optional state overrides. The gas limit
+// of message, if specified, is the upper bound to search. It returns the revert reason if the
+// message always fails.
+func (api *PublicScdoAPI) EstimateCallGas(args CallArgs, height int64, overrides *StateOverrideArgs) (uint64, error) {
+	msg, override, err := args.toMessage(overrides)
+	if err != nil {
+		return 0, err
+	}
+
+	header, newState, err := api.newStateFunc(height, override)
+	if err != nil {
+		return 0, err
+	}
+
+	return svm.EstimateGas(newState, header, api.s.chain.GetStore(), msg)
+}
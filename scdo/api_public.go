@@ -16,8 +16,8 @@ import (
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
 	"github.com/scdoproject/go-stem/common/hexutil"
+	"github.com/scdoproject/go-stem/contract/system"
 	"github.com/scdoproject/go-stem/core"
-	"github.com/scdoproject/go-stem/core/state"
 	"github.com/scdoproject/go-stem/core/types"
 	"github.com/scdoproject/go-stem/crypto"
 )
@@ -34,31 +34,26 @@ func NewPublicScdoAPI(s *ScdoService) *PublicScdoAPI {
 	return &PublicScdoAPI{s}
 }
 
-// EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
+// EstimateGas returns the smallest gas limit with which the given transaction succeeds against
+// the current block. The signature and nonce of tx are ignored, and the gas limit of tx is the
+// upper bound to search.
 func (api *PublicScdoAPI) EstimateGas(tx *types.Transaction) (uint64, error) {
-	// Get the block by block height, if the height is less than zero, get the current block.
-	block, err := getBlock(api.s.chain, -1)
-	if err != nil {
-		return 0, err
+	args := CallArgs{
+		From:     tx.Data.From,
+		To:       tx.Data.To,
+		Value:    tx.Data.Amount,
+		Gas:      tx.Data.GasLimit,
+		GasPrice: tx.Data.GasPrice,
+		Data:     hexutil.BytesToHex(tx.Data.Payload),
 	}
 
-	// Get the statedb by the given block height
-	statedb, err := state.NewStatedb(block.Header.StateHash, api.s.accountStateDB)
-	if err != nil {
-		return 0, err
-	}
+	return api.EstimateCallGas(args, -1, nil)
+}
 
-	coinbase := api.s.miner.GetCoinbase()
-	// Get the transaction receipt, and the fee give to the miner coinbase
-	receipt, err := api.s.chain.ApplyTransaction(tx, 0, coinbase, statedb, block.Header)
-	if err != nil {
-		return 0, err
-	}
-	if receipt.Failed {
-		return 0, errors.New(string(receipt.Result))
-	}
-	return receipt.UsedGas, nil
+// GetRelayRange returns the number of blocks between relays of subchain to the root chain
+func (api *PublicScdoAPI) GetRelayRange() uint64 {
+	info := api.s.GenesisInfo()
+	return info.GetRelayRange()
 }
 
 func (api *PublicScdoAPI) GetHeight() (int64, error) {
@@ -94,7 +89,7 @@ func (api *PublicScdoAPI) GetInfo() (api2.GetMinerInfo, error) {
 	}, nil
 }
 
-// Call is to execute a given transaction on a statedb of a given block height.
+// Call is to execute a given transaction on a statedb of a given block height, or the pending block.
 // It does not affect this statedb and blockchain and is useful for executing and retrieve values.
 func (api *PublicScdoAPI) Call(contract, payload string, height int64) (map[string]interface{}, error) {
 	contractAddr, err := common.HexToAddress(contract)
@@ -107,14 +102,13 @@ func (api *PublicScdoAPI) Call(contract, payload string, height int64) (map[stri
 		return nil, fmt.Errorf("invalid payload, %s", err)
 	}
 
-	// Get the block by block height, if the height is less than zero, get the current block.
-	block, err := getBlock(api.s.chain, height)
+	// Get the statedb by the given block height, or the pending block
+	header, newState, err := api.newStateFunc(height, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the statedb by the given block height
-	statedb, err := state.NewStatedb(block.Header.StateHash, api.s.accountStateDB)
+	statedb, err := newState()
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +127,7 @@ func (api *PublicScdoAPI) Call(contract, payload string, height int64) (map[stri
 	}
 
 	// Get the transaction receipt, and the fee give to the miner coinbase
-	receipt, err := api.s.chain.ApplyTransaction(tx, 0, coinbase, statedb, block.Header)
+	receipt, err := api.s.chain.ApplyTransaction(tx, 0, coinbase, statedb, header)
 	if err != nil {
 		return nil, err
 	}
@@ -147,8 +141,16 @@ func (api *PublicScdoAPI) Call(contract, payload string, height int64) (map[stri
 	return result, nil
 }
 
-// GetLogs Get the logs that satisfies the condition in the block by height and filter
+// GetLogs Get the logs that satisfies the condition in the block by height and filter.
+// The abiJSON could be empty for system contracts.
 func (api *PublicScdoAPI) GetLogs(height int64, contractAddress common.Address, abiJSON, eventName string) ([]api2.GetLogsResponse, error) {
+	// use the published ABI of system contract if not specified
+	if len(abiJSON) == 0 {
+		if abiJSON = system.GetContractABI(contractAddress); len(abiJSON) == 0 {
+			return nil, fmt.Errorf("abi is required for contract %v", contractAddress.Hex())
+		}
+	}
+
 	parsed, err := abi.JSON(strings.NewReader(abiJSON))
 	if err != nil {
 		return nil, errors.NewStackedError(err, "get abi parser failed")
@@ -187,7 +189,7 @@ func (api *PublicScdoAPI) GetLogs(height int64, contractAddress common.Address,
 				continue
 			}
 
-			data, err := event.Inputs.UnpackValues(log.Data)
+			data, err := event.UnpackLog(log.Topics, log.Data)
 			if err != nil {
 				return nil, errors.NewStackedError(err, "failed to decode event arguments")
 			}
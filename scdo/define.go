@@ -26,6 +26,9 @@ const (
 	// DebtManagerDir to-be-sent debt directory based on config.DataRoot
 	DebtManagerDir = "/db/debtManager"
 
+	// KeyStoreDir key files directory based on config.DataRoot, used if no keystore directory configured
+	KeyStoreDir = "/keystore"
+
 	// BlockChainRecoveryPointFile is used to store the recovery point info of blockchain.
 	BlockChainRecoveryPointFile = "recoveryPoint.json"
 )
@@ -492,7 +492,7 @@ func (d *Downloader) RegisterPeer(peerID string, peer Peer) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	newConn := newPeerConn(peer, peerID, d.log)
+	newConn := newPeerConn(peer, peerID, d.log.With(log.Peer(peerID)))
 	d.peers[peerID] = newConn
 
 	//if d.syncStatus == statusFetching {
@@ -548,7 +548,8 @@ func (d *Downloader) Terminate() {
 func (d *Downloader) peerDownload(conn *peerConn, tm *taskMgr) {
 	defer d.sessionWG.Done()
 
-	d.log.Debug("Downloader.peerDownload start. peerID=%s masterID=%s", conn.peerID, d.masterPeer)
+	peerLog := d.log.With(log.Peer(conn.peerID))
+	peerLog.Debug("Downloader.peerDownload start. peerID=%s masterID=%s", conn.peerID, d.masterPeer)
 	isMaster := (conn.peerID == d.masterPeer)
 	peerID := conn.peerID
 
@@ -556,17 +557,17 @@ outLoop:
 	for !tm.isDone() {
 		hasReqData := false
 		if startNo, amount := tm.getReqHeaderInfo(conn); amount > 0 {
-			d.log.Debug("tm.getReqHeaderInfo. startNo=%d amount=%d", startNo, amount)
+			peerLog.Debug("tm.getReqHeaderInfo. startNo=%d amount=%d", startNo, amount)
 			hasReqData = true
 
 			magic := rand2.Uint32()
-			d.log.Debug("request header by number. start=%d, amount=%d, magic=%d, id=%s", startNo, amount, magic, conn.peerID)
+			peerLog.Debug("request header by number. start=%d, amount=%d, magic=%d, id=%s", startNo, amount, magic, conn.peerID)
 
 			go conn.peer.RequestHeadersByHashOrNumber(magic, common.Hash{}, startNo, amount, false)
 
 			msg, err := conn.waitMsg(magic, BlockHeadersMsg, d.cancelCh)
 			if err != nil {
-				d.log.Debug("peerDownload waitMsg BlockHeadersMsg err! err=%s, magic=%d, id=%s", err, magic, conn.peerID)
+				peerLog.Debug("peerDownload waitMsg BlockHeadersMsg err! err=%s, magic=%d, id=%s", err, magic, conn.peerID)
 				break
 			}
 
@@ -578,28 +579,28 @@ outLoop:
 				endHeight = headers[len(headers)-1].Height
 			}
 
-			d.log.Debug("got block header msg length= %d. start=%d, end=%d, magic=%d, id=%s", len(headers), startHeight, endHeight, magic, conn.peerID)
+			peerLog.Debug("got block header msg length= %d. start=%d, end=%d, magic=%d, id=%s", len(headers), startHeight, endHeight, magic, conn.peerID)
 
 			if err = tm.deliverHeaderMsg(peerID, headers); err != nil {
-				d.log.Warn("peerDownload deliverHeaderMsg err! %s", err)
+				peerLog.Warn("peerDownload deliverHeaderMsg err! %s", err)
 				break
 			}
 
-			d.log.Debug("get request header info success")
+			peerLog.Debug("get request header info success")
 		}
 
 		if startNo, amount := tm.getReqBlocks(conn); amount > 0 {
-			d.log.Debug("download.peerdown getReqBlocks startNo=%d amount=%d", startNo, amount)
+			peerLog.Debug("download.peerdown getReqBlocks startNo=%d amount=%d", startNo, amount)
 			hasReqData = true
 
 			magic := rand2.Uint32()
-			d.log.Debug("request block by number. start=%d, amount=%d, magic=%d, id=%s", startNo, amount, magic, conn.peerID)
+			peerLog.Debug("request block by number. start=%d, amount=%d, magic=%d, id=%s", startNo, amount, magic, conn.peerID)
 
 			go conn.peer.RequestBlocksByHashOrNumber(magic, common.Hash{}, startNo, amount)
 
 			msg, err := conn.waitMsg(magic, BlocksMsg, d.cancelCh)
 			if err != nil {
-				d.log.Debug("peerDownload waitMsg BlocksMsg err! err=%s", err)
+				peerLog.Debug("peerDownload waitMsg BlocksMsg err! err=%s", err)
 				break
 			}
 
@@ -610,14 +611,14 @@ outLoop:
 				startHeight = blocks[0].Header.Height
 				endHeight = blocks[len(blocks)-1].Header.Height
 			}
-			d.log.Debug("got blocks message length=%d. start=%d, end=%d, magic=%d, id=%s", len(blocks), startHeight, endHeight, magic, conn.peerID)
+			peerLog.Debug("got blocks message length=%d. start=%d, end=%d, magic=%d, id=%s", len(blocks), startHeight, endHeight, magic, conn.peerID)
 
 			tm.deliverBlockMsg(peerID, blocks)
-			d.log.Debug("get request blocks success")
+			peerLog.Debug("get request blocks success")
 		}
 
 		if hasReqData {
-			d.log.Debug("got request data, continue to request")
+			peerLog.Debug("got request data, continue to request")
 			continue
 		}
 
@@ -630,7 +631,7 @@ outLoop:
 			case <-conn.quitCh:
 				break outLoop
 			case <-time.After(peerIdleTime):
-				d.log.Debug("peerDownload peerIdleTime timeout")
+				peerLog.Debug("peerDownload peerIdleTime timeout")
 				break outFor
 			}
 		}
@@ -640,7 +641,7 @@ outLoop:
 	if isMaster || tm.isDone() {
 		d.Cancel()
 	}
-	d.log.Debug("Downloader.peerDownload end. peerID=%s masterID=%s", conn.peerID, d.masterPeer)
+	peerLog.Debug("Downloader.peerDownload end. peerID=%s masterID=%s", conn.peerID, d.masterPeer)
 }
 
 // processBlocks writes blocks to the blockchain.
@@ -650,13 +651,14 @@ func (d *Downloader) processBlocks(headInfos []*downloadInfo, ancestor uint64, l
 	}
 	for _, h := range headInfos {
 		// add it for all received block messages
-		d.log.Info("got block message and save it. height=%d, hash=%s, time=%d", h.block.Header.Height, h.block.HeaderHash.Hex(), time.Now().UnixNano())
+		blockLog := d.log.With(log.Peer(conn.peerID), log.Height(h.block.Header.Height), log.Hash(h.block.HeaderHash))
+		blockLog.Info("got block message and save it. height=%d, hash=%s, time=%d", h.block.Header.Height, h.block.HeaderHash.Hex(), time.Now().UnixNano())
 		// writeblock
 		txPool := d.scdo.TxPool().Pool
 		err := d.chain.WriteBlock(h.block, txPool)
 
 		if err != nil && !errors.IsOrContains(err, core.ErrBlockAlreadyExists) {
-			d.log.Error("failed to write block err=%s", err)
+			blockLog.Error("failed to write block err=%s", err)
 			// recover local blocks if localTotalDifficulty is larger than the synchronized total difficulty
 			// if writeblock fails in the middle (the whole process not successfully completed), then we need to consider write back our localblocks
 			// if localblock totaldifficult is larger than the break point's one. It means this sync attempt should be abonded
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/scdoproject/go-stem/api"
 	"github.com/scdoproject/go-stem/common"
 	"github.com/scdoproject/go-stem/common/errors"
 	"github.com/scdoproject/go-stem/consensus"
@@ -147,38 +148,97 @@ func (manager *LightClientsManager) ValidateDebt(debt *types.Debt) (packed bool,
 		return false, false, errWrongShardDebt
 	}
 
-	// check cache first
+	// check cache first. The debts created by contracts share the same tx hash,
+	// so the confirmed debts of contracts are cached by debt hash.
 	cache := manager.confirmedTxs[fromShard]
-	if _, ok := cache.Get(debt.Data.TxHash); ok {
+	cacheKey := debt.Data.TxHash
+	if debt.Data.From.IsEVMContract() {
+		cacheKey = debt.Hash
+	}
+
+	if _, ok := cache.Get(cacheKey); ok {
 		return true, true, nil
 	}
 
 	// comment out for test only
 	backend := manager.lightClientsBackend[fromShard]
+	var index *api.BlockIndex
+	var err error
+	if debt.Data.From.IsEVMContract() {
+		index, err = validateContractDebt(backend, debt)
+	} else {
+		index, err = validateTxDebt(backend, debt)
+	}
+
+	if err != nil {
+		return false, false, err
+	}
+
+	header := backend.ChainBackend().CurrentHeader()
+	duration := header.Height - index.BlockHeight
+	if duration < common.ConfirmedBlockNumber {
+		return true, false, fmt.Errorf("invalid debt because not enough confirmed block number, wanted is %d, actual is %d", common.ConfirmedBlockNumber, duration)
+	}
+
+	// cache the confirmed tx
+	cache.Add(cacheKey, true)
+
+	return true, true, nil
+}
+
+// validateTxDebt validates the debt of cross shard tx, and returns the index of tx.
+func validateTxDebt(backend *light.LightBackend, debt *types.Debt) (*api.BlockIndex, error) {
 	tx, index, err := backend.GetTransaction(backend.TxPoolBackend(), backend.ChainBackend().GetStore(), debt.Data.TxHash)
 	if err != nil {
-		return false, false, errors.NewStackedErrorf(err, "failed to get tx %v", debt.Data.TxHash)
+		return nil, errors.NewStackedErrorf(err, "failed to get tx %v", debt.Data.TxHash)
 	}
 
 	if index == nil {
-		return false, false, errNotFoundTx
+		return nil, errNotFoundTx
 	}
 
 	checkDebt := types.NewDebtWithoutContext(tx)
 	if checkDebt == nil || !checkDebt.Hash.Equal(debt.Hash) {
-		return false, false, errNotMatchedTx
+		return nil, errNotMatchedTx
 	}
 
-	header := backend.ChainBackend().CurrentHeader()
-	duration := header.Height - index.BlockHeight
-	if duration < common.ConfirmedBlockNumber {
-		return true, false, fmt.Errorf("invalid debt because not enough confirmed block number, wanted is %d, actual is %d", common.ConfirmedBlockNumber, duration)
+	return index, nil
+}
+
+// validateContractDebt validates the debt created by contract, which is proved by the receipt of
+// the tx or the cross shard call in debt that executes the contract, and returns the index of them.
+func validateContractDebt(backend *light.LightBackend, debt *types.Debt) (*api.BlockIndex, error) {
+	_, index, err := backend.GetTransaction(backend.TxPoolBackend(), backend.ChainBackend().GetStore(), debt.Data.TxHash)
+	if err != nil || index == nil {
+		if _, index, err = backend.GetDebt(debt.Data.TxHash); err != nil {
+			return nil, errors.NewStackedErrorf(err, "failed to get tx or debt %v", debt.Data.TxHash)
+		}
 	}
 
-	// cache the confirmed tx
-	cache.Add(debt.Data.TxHash, true)
+	if index == nil {
+		return nil, errNotFoundTx
+	}
 
-	return true, true, nil
+	receipt, err := backend.GetReceiptByTxHash(debt.Data.TxHash)
+	if err != nil {
+		return nil, errors.NewStackedErrorf(err, "failed to get receipt %v", debt.Data.TxHash)
+	}
+
+	if !containsDebt(receipt.Debts, debt.Hash) {
+		return nil, errNotMatchedTx
+	}
+
+	return index, nil
+}
+
+func containsDebt(debts []*types.Debt, hash common.Hash) bool {
+	for _, d := range debts {
+		if d.Hash.Equal(hash) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // GetServices get node service
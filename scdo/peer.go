@@ -298,7 +298,7 @@ func (p *peer) sendHeadStatus(msg *chainHeadStatus) error {
 // handShake exchange networkid td etc between two connected peers.
 func (p *peer) handShake(networkID string, td *big.Int, head common.Hash, genesis common.Hash, difficult uint64) error {
 	msg := &statusData{
-		ProtocolVersion: uint32(common.ScdoVersion),
+		ProtocolVersion: uint32(p.version),
 		NetworkID:       networkID,
 		TD:              td,
 		CurrentBlock:    head,
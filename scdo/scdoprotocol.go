@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/scdoproject/go-stem/common/memory"
@@ -40,7 +41,13 @@ var (
 
 	debtMsgCode uint16 = 13
 
-	protocolMsgCodeLength uint16 = 14
+	// codes 14 and 15 are handled by the finality gadget of consensus engine since version 2
+	protocolMsgCodeLength uint16 = 16
+
+	// scdoVersion1 is the version before finality checkpoints, which is still negotiable with
+	// the old peers and has no message codes of finality gadget
+	scdoVersion1            uint   = 1
+	protocolMsgCodeLengthV1 uint16 = 14
 
 	bftP2PMsg uint16 = 0x12
 )
@@ -86,8 +93,9 @@ type ScdoProtocol struct {
 	syncCh chan struct{}
 	log    *log.ScdoLog
 
-	debtManager *DebtManager
-	engine      consensus.Engine
+	debtManager     *DebtManager
+	engine          consensus.Engine
+	confirmedHeight uint64 // height of the last confirmed block whose debts are propagated
 }
 
 // Downloader return a pointer of the downloader
@@ -116,7 +124,9 @@ func NewScdoProtocol(scdo *ScdoService, log *log.ScdoLog, engine consensus.Engin
 	if handler, ok := s.engine.(consensus.Handler); ok {
 		handler.SetBroadcaster(s)
 	}
-	s.Protocol.AddPeer = s.handleAddPeer
+	s.Protocol.AddPeer = func(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+		return s.handleAddPeer(common.ScdoVersion, p2pPeer, rw)
+	}
 	s.Protocol.DeletePeer = s.handleDelPeer
 	s.Protocol.GetPeer = s.handleGetPeer
 
@@ -129,6 +139,18 @@ func NewScdoProtocol(scdo *ScdoService, log *log.ScdoLog, engine consensus.Engin
 	return s, nil
 }
 
+// Protocols returns all supported versions of the protocol, and the highest one supported
+// by both sides is negotiated with the peer.
+func (sp *ScdoProtocol) Protocols() []p2p.Protocol {
+	v1 := sp.Protocol
+	v1.Version, v1.Length = scdoVersion1, protocolMsgCodeLengthV1
+	v1.AddPeer = func(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+		return sp.handleAddPeer(scdoVersion1, p2pPeer, rw)
+	}
+
+	return []p2p.Protocol{sp.Protocol, v1}
+}
+
 func (sp *ScdoProtocol) Start() {
 	sp.log.Debug("ScdoProtocol.Start called!")
 	go sp.syncer()
@@ -365,28 +387,56 @@ func (p *ScdoProtocol) propagateDebtMap(debtsMap [][]*types.Debt, filter bool) {
 func (p *ScdoProtocol) handleNewBlock(e event.Event) {
 	block := e.(*types.Block)
 
-	// propagate confirmed block
+	var confirmedHeight uint64
 	if block.Header.Height > common.ConfirmedBlockNumber {
-		confirmedHeight := block.Header.Height - common.ConfirmedBlockNumber
-		confirmedBlock, err := p.chain.GetStore().GetBlockByHeight(confirmedHeight)
-
-		if err != nil {
-			p.log.Warn("failed to load confirmed block height %d, err %s", confirmedHeight, err)
-		} else {
-			now := time.Now()
-			// entrance
-			memory.Print(p.log, "ScdoProtocol handleNewBlock entrance", now, false)
+		confirmedHeight = block.Header.Height - common.ConfirmedBlockNumber
+	}
 
-			debts := types.NewDebtMap(confirmedBlock.Transactions)
-			p.debtManager.AddDebtMap(debts, confirmedHeight)
-			go p.propagateDebtMap(debts, true)
+	if confirmedHeight == 0 {
+		return
+	}
 
-			// exit
-			memory.Print(p.log, "ScdoProtocol handleNewBlock exit", now, true)
+	// propagate the confirmed blocks since last propagated one
+	from := confirmedHeight
+	if last := atomic.LoadUint64(&p.confirmedHeight); last > 0 {
+		if last >= confirmedHeight {
+			return
 		}
+		from = last + 1
+	}
+	atomic.StoreUint64(&p.confirmedHeight, confirmedHeight)
+
+	for height := from; height <= confirmedHeight; height++ {
+		p.propagateConfirmedBlock(height)
 	}
 }
 
+// propagateConfirmedBlock propagates the debts of the confirmed block of height
+func (p *ScdoProtocol) propagateConfirmedBlock(confirmedHeight uint64) {
+	confirmedBlock, err := p.chain.GetStore().GetBlockByHeight(confirmedHeight)
+	if err != nil {
+		p.log.Warn("failed to load confirmed block height %d, err %s", confirmedHeight, err)
+		return
+	}
+
+	now := time.Now()
+	// entrance
+	memory.Print(p.log, "ScdoProtocol handleNewBlock entrance", now, false)
+
+	// debts of both cross shard txs and contracts transferring value to other shards
+	receipts, err := p.chain.GetStore().GetReceiptsByBlockHash(confirmedBlock.HeaderHash)
+	if err != nil {
+		p.log.Warn("failed to load receipts of confirmed block height %d, err %s", confirmedHeight, err)
+	}
+
+	debts := types.NewDebtMapWithReceipts(confirmedBlock.Transactions, receipts)
+	p.debtManager.AddDebtMap(debts, confirmedHeight)
+	go p.propagateDebtMap(debts, true)
+
+	// exit
+	memory.Print(p.log, "ScdoProtocol handleNewBlock exit", now, true)
+}
+
 func (p *ScdoProtocol) handleNewMinedBlock(e event.Event) {
 	now := time.Now()
 	// entrance
@@ -402,13 +452,13 @@ func (p *ScdoProtocol) handleNewMinedBlock(e event.Event) {
 	memory.Print(p.log, "ScdoProtocol handleNewMinedBlock exit", now, true)
 }
 
-func (p *ScdoProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
+func (p *ScdoProtocol) handleAddPeer(version uint, p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) bool {
 	if p.peerSet.Find(p2pPeer.Node.ID) != nil {
 		p.log.Error("handleAddPeer called, but peer of this public-key has already existed, so need quit!")
 		return false
 	}
 
-	newPeer := newPeer(common.ScdoVersion, p2pPeer, rw, p.log)
+	newPeer := newPeer(version, p2pPeer, rw, p.log)
 
 	block := p.chain.CurrentBlock()
 	head := block.HeaderHash
@@ -862,3 +912,17 @@ func (sp *ScdoProtocol) FindPeers(targets map[common.Address]bool) map[common.Ad
 
 	return m
 }
+
+// LocalPeers returns the peers of local shard that support the finality checkpoint messages
+func (sp *ScdoProtocol) LocalPeers() map[common.Address]consensus.Peer {
+	m := make(map[common.Address]consensus.Peer)
+	for _, p := range sp.peerSet.getPeerByShard(common.LocalShardNumber) {
+		if p.version < common.ScdoVersion {
+			continue
+		}
+
+		m[p.Node.ID] = p
+	}
+
+	return m
+}
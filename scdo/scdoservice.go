@@ -12,6 +12,8 @@ import (
 
 	"github.com/scdoproject/go-stem/api"
 	"github.com/scdoproject/go-stem/common"
+	"github.com/scdoproject/go-stem/common/errors"
+	"github.com/scdoproject/go-stem/common/keystore"
 	"github.com/scdoproject/go-stem/consensus"
 	"github.com/scdoproject/go-stem/core"
 	"github.com/scdoproject/go-stem/core/store"
@@ -47,6 +49,7 @@ type ScdoService struct {
 	debtManagerDB      database.Database // database used to store debts in debt manager.
 	debtManagerDBPath  string
 	miner              *miner.Miner
+	keystore           *keystore.Manager
 
 	lastHeader               common.Hash
 	chainHeaderChangeChannel chan common.Hash
@@ -128,6 +131,12 @@ func NewScdoService(ctx context.Context, conf *node.Config, log *log.ScdoLog, en
 		return nil, err
 	}
 
+	keystoreDir := conf.BasicConfig.KeyStoreDir
+	if len(keystoreDir) == 0 {
+		keystoreDir = filepath.Join(serviceContext.DataDir, KeyStoreDir)
+	}
+	s.keystore = keystore.NewManager(keystoreDir)
+
 	s.miner = miner.NewMiner(conf.ScdoConfig.Coinbase, s, s.debtVerifier, engine)
 	if err = s.initGenesisAndChain(&serviceContext, conf, startHeight); err != nil {
 		return nil, err
@@ -202,6 +211,9 @@ func (s *ScdoService) initGenesisAndChain(serviceContext *ServiceContext, conf *
 		return err
 	}
 
+	// limit the txs of accounts in each relay range of subchain
+	s.chain.SetTxLimit(core.NewTxLimit(&conf.ScdoConfig.GenesisConfig))
+
 	return nil
 }
 
@@ -254,7 +266,7 @@ func (s *ScdoService) MonitorChainHeaderChange() {
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *ScdoService) Protocols() (protos []p2p.Protocol) {
-	protos = append(protos, s.scdoProtocol.Protocol)
+	protos = append(protos, s.scdoProtocol.Protocols()...)
 	return protos
 }
 
@@ -263,6 +275,12 @@ func (s *ScdoService) Start(srvr *p2p.Server) error {
 	s.p2pServer = srvr
 	s.scdoProtocol.Start()
 
+	if f, ok := s.miner.GetEngine().(consensus.Finality); ok {
+		if err := f.Start(s.chain); err != nil {
+			return errors.NewStackedError(err, "failed to start finality gadget")
+		}
+	}
+
 	return nil
 }
 
@@ -271,6 +289,10 @@ func (s *ScdoService) Stop() error {
 	//TODO
 	// s.txPool.Stop() s.chain.Stop()
 	// retries? leave it to future
+	if f, ok := s.miner.GetEngine().(consensus.Finality); ok {
+		f.Stop()
+	}
+
 	if s.scdoProtocol != nil {
 		s.scdoProtocol.Stop()
 		s.scdoProtocol = nil
@@ -323,6 +345,12 @@ func (s *ScdoService) APIs() (apis []rpc.API) {
 			Service:   NewPrivateMinerAPI(s),
 			Public:    false,
 		},
+		{
+			Namespace: "personal",
+			Version:   "1.0",
+			Service:   api.NewPrivatePersonalAPI(NewScdoBackend(s), s.keystore),
+			Public:    false,
+		},
 		{
 			Namespace: "txpool",
 			Version:   "1.0",
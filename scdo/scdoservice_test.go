@@ -54,7 +54,7 @@ func Test_ScdoService_Protocols(t *testing.T) {
 	defer s.Stop()
 
 	protos := s.Protocols()
-	assert.Equal(t, len(protos), 1)
+	assert.Equal(t, len(protos), 2)
 }
 
 func Test_ScdoService_Start(t *testing.T) {